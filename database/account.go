@@ -0,0 +1,730 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+/*                                       +---------+                                       */
+/* --------------------------------------| ACCOUNT |-------------------------------------- */
+/*                                       +---------+                                       */
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct InsertAccount | Table Account         |
+// +----------------------+-----------------------+
+// |                      | ID INTEGER            |
+// | Username string      | Username TEXT         |
+// | PasswordHash string  | PasswordHash TEXT     |
+// |                      | CreationDate DATETIME |
+//
+// # Note
+// - An account logs in to the HTTP API of the explorer, it has nothing to do with the users of the MQTT-Brokers in table User.
+// - `PasswordHash` is never the password itself, the server hashes it before.
+//
+// # Used in
+// - InsertNewAccount()
+//
+// # Author
+// - Polariusz
+type InsertAccount struct {
+	Username string
+	PasswordHash string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct SelectAccount   | Table Account         |
+// +------------------------+-----------------------+
+// | Id int                 | ID INTEGER            |
+// | Username string        | Username TEXT         |
+// | PasswordHash string    | PasswordHash TEXT     |
+// | CreationDate time.Time | CreationDate DATETIME |
+//
+// # Note
+// - `PasswordHash` is left out of the JSON.
+//
+// # Author
+// - Polariusz
+type SelectAccount struct {
+	Id int
+	Username string
+	PasswordHash string `json:"-"`
+	CreationDate time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB            : It's a connection to the database.
+// - account InsertAccount  : It's inserted into table `Account`
+//
+// # Tables Affected
+// - Account
+//   - INSERT
+//
+// # Returns
+// - int: [Account].[ID], -1 on error
+// - error when:
+//   - Skill Issues
+//   - The username is taken
+//
+// # Author
+// - Polariusz
+func InsertNewAccount(con *sql.DB, account InsertAccount) (int, error) {
+	result, err := con.Exec(`
+		INSERT INTO Account(Username, PasswordHash, CreationDate)
+		VALUES(?, ?, ?)
+	`, account.Username, account.PasswordHash, time.Now())
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	accountId, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return int(accountId), nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+//
+// # Tables Affected
+// - Account
+//   - SELECT
+//
+// # Returns
+// - A list of all struct `SelectAccount`, ordered by the username
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectAccounts(con *sql.DB) ([]SelectAccount, error) {
+	var accountList []SelectAccount
+
+	rows, err := con.Query(`
+		SELECT ID, Username, PasswordHash, CreationDate
+		FROM Account
+		ORDER BY Username
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var account SelectAccount
+		rows.Scan(&account.Id, &account.Username, &account.PasswordHash, &account.CreationDate)
+		accountList = append(accountList, account)
+	}
+
+	return accountList, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB     : It's a connection to the database.
+// - username string : [Account].[Username]
+//
+// # Tables Affected
+// - Account
+//   - SELECT
+//
+// # Returns
+// - SelectAccount of the username
+// - bool: false if there is no such account
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectAccountByUsername(con *sql.DB, username string) (SelectAccount, bool, error) {
+	var account SelectAccount
+
+	err := con.QueryRow(`
+		SELECT ID, Username, PasswordHash, CreationDate
+		FROM Account
+		WHERE Username = ?
+	`, username).Scan(&account.Id, &account.Username, &account.PasswordHash, &account.CreationDate)
+	if err == sql.ErrNoRows {
+		return account, false, nil
+	}
+	if err != nil {
+		return account, false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return account, true, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+// - id int      : [Account].[ID]
+//
+// # Tables Affected
+// - Account
+//   - SELECT
+//
+// # Returns
+// - SelectAccount of the ID
+// - bool: false if there is no such account
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectAccountById(con *sql.DB, id int) (SelectAccount, bool, error) {
+	var account SelectAccount
+
+	err := con.QueryRow(`
+		SELECT ID, Username, PasswordHash, CreationDate
+		FROM Account
+		WHERE ID = ?
+	`, id).Scan(&account.Id, &account.Username, &account.PasswordHash, &account.CreationDate)
+	if err == sql.ErrNoRows {
+		return account, false, nil
+	}
+	if err != nil {
+		return account, false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return account, true, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+//
+// # Tables Affected
+// - Account
+//   - SELECT
+//
+// # Returns
+// - int: The number of accounts
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func CountAccounts(con *sql.DB) (int, error) {
+	var count int
+	if err := con.QueryRow("SELECT COUNT(*) FROM Account").Scan(&count); err != nil {
+		return 0, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	return count, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB         : It's a connection to the database.
+// - id int              : [Account].[ID]
+// - passwordHash string : The new [Account].[PasswordHash]
+//
+// # Tables Affected
+// - Account
+//   - UPDATE
+//
+// # Returns
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func UpdateAccountPassword(con *sql.DB, id int, passwordHash string) error {
+	if _, err := con.Exec("UPDATE Account SET PasswordHash = ? WHERE ID = ?", passwordHash, id); err != nil {
+		return fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	return nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+// - id int      : [Account].[ID]
+//
+// # Description
+// - The function shall delete the account together with its API keys, in one transaction.
+//
+// # Tables Affected
+// - ApiKey
+//   - DELETE
+// - RoleAssignment
+//   - DELETE
+// - Account
+//   - DELETE
+//
+// # Returns
+// - bool: false if there was no such account
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteAccount(con *sql.DB, id int) (bool, error) {
+	tx, err := con.Begin()
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM ApiKey WHERE AccountId = ?", id); err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	if _, err := tx.Exec("DELETE FROM RoleAssignment WHERE AccountId = ?", id); err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	result, err := tx.Exec("DELETE FROM Account WHERE ID = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	return affected > 0, nil
+}
+
+/*                                       +--------+                                       */
+/* --------------------------------------| APIKEY |-------------------------------------- */
+/*                                       +--------+                                       */
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct InsertApiKey | Table ApiKey          |
+// +---------------------+-----------------------+
+// |                     | ID INTEGER            |
+// | AccountId int       | AccountId INTEGER     |
+// | Name string         | Name TEXT             |
+// | Prefix string       | Prefix TEXT           |
+// | KeyHash string      | KeyHash TEXT          |
+// |                     | CreationDate DATETIME |
+// |                     | LastUsed DATETIME     |
+//
+// # Note
+// - The key itself is not stored, only its hash, and its first characters in `Prefix` so that it can be recognised in a list.
+//
+// # Used in
+// - InsertNewApiKey()
+//
+// # Author
+// - Polariusz
+type InsertApiKey struct {
+	AccountId int
+	Name string
+	Prefix string
+	KeyHash string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct SelectApiKey    | Table ApiKey          |
+// +------------------------+-----------------------+
+// | Id int                 | ID INTEGER            |
+// | AccountId int          | AccountId INTEGER     |
+// | Name string            | Name TEXT             |
+// | Prefix string          | Prefix TEXT           |
+// | CreationDate time.Time | CreationDate DATETIME |
+// | LastUsed *time.Time    | LastUsed DATETIME     |
+//
+// # Author
+// - Polariusz
+type SelectApiKey struct {
+	Id int
+	AccountId int
+	Name string
+	Prefix string
+	CreationDate time.Time
+	LastUsed *time.Time
+}
+
+// # Author
+// - Polariusz
+func scanSelectApiKey(scan func(dest ...any) error) (SelectApiKey, error) {
+	var apiKey SelectApiKey
+	var lastUsed sql.NullTime
+	err := scan(&apiKey.Id, &apiKey.AccountId, &apiKey.Name, &apiKey.Prefix, &apiKey.CreationDate, &lastUsed)
+	if lastUsed.Valid {
+		apiKey.LastUsed = &lastUsed.Time
+	}
+	return apiKey, err
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB         : It's a connection to the database.
+// - apiKey InsertApiKey : It's inserted into table `ApiKey`
+//
+// # Tables Affected
+// - ApiKey
+//   - INSERT
+//
+// # Returns
+// - int: [ApiKey].[ID], -1 on error
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func InsertNewApiKey(con *sql.DB, apiKey InsertApiKey) (int, error) {
+	result, err := con.Exec(`
+		INSERT INTO ApiKey(AccountId, Name, Prefix, KeyHash, CreationDate)
+		VALUES(?, ?, ?, ?, ?)
+	`, apiKey.AccountId, apiKey.Name, apiKey.Prefix, apiKey.KeyHash, time.Now())
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	apiKeyId, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return int(apiKeyId), nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB   : It's a connection to the database.
+// - accountId int : [Account].[ID]
+//
+// # Tables Affected
+// - ApiKey
+//   - SELECT
+//
+// # Returns
+// - A list of struct `SelectApiKey` of the account, the oldest first
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectApiKeysByAccountId(con *sql.DB, accountId int) ([]SelectApiKey, error) {
+	var apiKeyList []SelectApiKey
+
+	rows, err := con.Query(`
+		SELECT ID, AccountId, Name, Prefix, CreationDate, LastUsed
+		FROM ApiKey
+		WHERE AccountId = ?
+		ORDER BY ID
+	`, accountId)
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		apiKey, _ := scanSelectApiKey(rows.Scan)
+		apiKeyList = append(apiKeyList, apiKey)
+	}
+
+	return apiKeyList, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB    : It's a connection to the database.
+// - keyHash string : [ApiKey].[KeyHash]
+//
+// # Tables Affected
+// - ApiKey
+//   - SELECT
+//
+// # Returns
+// - SelectApiKey with the hash
+// - bool: false if there is no such key
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectApiKeyByHash(con *sql.DB, keyHash string) (SelectApiKey, bool, error) {
+	apiKey, err := scanSelectApiKey(con.QueryRow(`
+		SELECT ID, AccountId, Name, Prefix, CreationDate, LastUsed
+		FROM ApiKey
+		WHERE KeyHash = ?
+	`, keyHash).Scan)
+	if err == sql.ErrNoRows {
+		return apiKey, false, nil
+	}
+	if err != nil {
+		return apiKey, false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return apiKey, true, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB   : It's a connection to the database.
+// - id int        : [ApiKey].[ID]
+// - used time.Time: The new [ApiKey].[LastUsed]
+//
+// # Tables Affected
+// - ApiKey
+//   - UPDATE
+//
+// # Returns
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func UpdateApiKeyLastUsed(con *sql.DB, id int, used time.Time) error {
+	if _, err := con.Exec("UPDATE ApiKey SET LastUsed = ? WHERE ID = ?", used, id); err != nil {
+		return fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	return nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB   : It's a connection to the database.
+// - id int        : [ApiKey].[ID]
+// - accountId int : [Account].[ID], a key can only be deleted by its own account
+//
+// # Tables Affected
+// - ApiKey
+//   - DELETE
+//
+// # Returns
+// - bool: false if the account has no such key
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteApiKey(con *sql.DB, id int, accountId int) (bool, error) {
+	result, err := con.Exec("DELETE FROM ApiKey WHERE ID = ? AND AccountId = ?", id, accountId)
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+/*                                    +----------------+                                   */
+/* -----------------------------------| ROLEASSIGNMENT |---------------------------------- */
+/*                                    +----------------+                                   */
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct InsertRoleAssignment | Table RoleAssignment  |
+// +-----------------------------+-----------------------+
+// |                             | ID INTEGER            |
+// | AccountId int               | AccountId INTEGER     |
+// | Role string                 | Role TEXT             |
+// | BrokerId int                | BrokerId INTEGER      |
+// | TopicFilter string          | TopicFilter TEXT      |
+// |                             | CreationDate DATETIME |
+//
+// # Note
+// - Role is one of "viewer", "operator" and "admin", the server checks it.
+// - BrokerId 0 means that the role applies to all brokers.
+// - TopicFilter is a MQTT topic filter, "#" for all topics of the broker.
+//
+// # Used in
+// - InsertNewRoleAssignment()
+//
+// # Author
+// - Polariusz
+type InsertRoleAssignment struct {
+	AccountId int
+	Role string
+	BrokerId int
+	TopicFilter string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct SelectRoleAssignment | Table RoleAssignment  |
+// +-----------------------------+-----------------------+
+// | Id int                      | ID INTEGER            |
+// | AccountId int               | AccountId INTEGER     |
+// | Role string                 | Role TEXT             |
+// | BrokerId int                | BrokerId INTEGER      |
+// | TopicFilter string          | TopicFilter TEXT      |
+// | CreationDate time.Time      | CreationDate DATETIME |
+//
+// # Author
+// - Polariusz
+type SelectRoleAssignment struct {
+	Id int
+	AccountId int
+	Role string
+	BrokerId int
+	TopicFilter string
+	CreationDate time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB                     : It's a connection to the database.
+// - assignment InsertRoleAssignment : It's inserted into table `RoleAssignment`
+//
+// # Tables Affected
+// - RoleAssignment
+//   - INSERT
+//
+// # Returns
+// - int: [RoleAssignment].[ID], -1 on error
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func InsertNewRoleAssignment(con *sql.DB, assignment InsertRoleAssignment) (int, error) {
+	result, err := con.Exec(`
+		INSERT INTO RoleAssignment(AccountId, Role, BrokerId, TopicFilter, CreationDate)
+		VALUES(?, ?, ?, ?, ?)
+	`, assignment.AccountId, assignment.Role, assignment.BrokerId, assignment.TopicFilter, time.Now())
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	assignmentId, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return int(assignmentId), nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB   : It's a connection to the database.
+// - accountId int : [Account].[ID], 0 for the assignments of all accounts
+//
+// # Tables Affected
+// - RoleAssignment
+//   - SELECT
+//
+// # Returns
+// - A list of struct `SelectRoleAssignment`, the oldest first
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectRoleAssignments(con *sql.DB, accountId int) ([]SelectRoleAssignment, error) {
+	var assignmentList []SelectRoleAssignment
+
+	rows, err := con.Query(`
+		SELECT ID, AccountId, Role, BrokerId, TopicFilter, CreationDate
+		FROM RoleAssignment
+		WHERE ? = 0 OR AccountId = ?
+		ORDER BY ID
+	`, accountId, accountId)
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var assignment SelectRoleAssignment
+		rows.Scan(&assignment.Id, &assignment.AccountId, &assignment.Role, &assignment.BrokerId, &assignment.TopicFilter, &assignment.CreationDate)
+		assignmentList = append(assignmentList, assignment)
+	}
+
+	return assignmentList, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+// - id int      : [RoleAssignment].[ID]
+//
+// # Tables Affected
+// - RoleAssignment
+//   - DELETE
+//
+// # Returns
+// - bool: false if there was no such assignment
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteRoleAssignment(con *sql.DB, id int) (bool, error) {
+	result, err := con.Exec("DELETE FROM RoleAssignment WHERE ID = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
@@ -0,0 +1,414 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+/*                                      +-----------+                                      */
+/* -------------------------------------| ALERTRULE |------------------------------------- */
+/*                                      +-----------+                                      */
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct InsertAlertRule     | Table AlertRule            |
+// +----------------------------+----------------------------+
+// |                            | ID INTEGER                 |
+// | Name string                | Name TEXT                  |
+// | BrokerId int               | BrokerId INTEGER           |
+// | TopicFilter string         | TopicFilter TEXT           |
+// | ConditionPath string       | ConditionPath TEXT         |
+// | ConditionOperator string   | ConditionOperator TEXT     |
+// | ConditionValue string      | ConditionValue TEXT        |
+// | SilenceSeconds int         | SilenceSeconds INTEGER     |
+// | ForSeconds int             | ForSeconds INTEGER         |
+// | Hysteresis float64         | Hysteresis REAL            |
+// | WebhookId int              | WebhookId INTEGER          |
+// |                            | CreationDate DATETIME      |
+//
+// # Note
+// - BrokerId 0 means that the rule applies to all brokers.
+// - A rule with SilenceSeconds fires when no matching message was received for that long, otherwise it fires on the condition.
+// - ForSeconds is how long the condition must hold before the rule fires.
+// - Hysteresis is how far a value must be back past the threshold before the rule is resolved.
+// - WebhookId 0 means that the events are not sent to a webhook.
+//
+// # Used in
+// - InsertNewAlertRule()
+//
+// # Author
+// - Polariusz
+type InsertAlertRule struct {
+	Name string
+	BrokerId int
+	TopicFilter string
+	ConditionPath string
+	ConditionOperator string
+	ConditionValue string
+	SilenceSeconds int
+	ForSeconds int
+	Hysteresis float64
+	WebhookId int
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct SelectAlertRule     | Table AlertRule            |
+// +----------------------------+----------------------------+
+// | Id int                     | ID INTEGER                 |
+// | Name string                | Name TEXT                  |
+// | BrokerId int               | BrokerId INTEGER           |
+// | TopicFilter string         | TopicFilter TEXT           |
+// | ConditionPath string       | ConditionPath TEXT         |
+// | ConditionOperator string   | ConditionOperator TEXT     |
+// | ConditionValue string      | ConditionValue TEXT        |
+// | SilenceSeconds int         | SilenceSeconds INTEGER     |
+// | ForSeconds int             | ForSeconds INTEGER         |
+// | Hysteresis float64         | Hysteresis REAL            |
+// | WebhookId int              | WebhookId INTEGER          |
+// | CreationDate time.Time     | CreationDate DATETIME      |
+//
+// # Author
+// - Polariusz
+type SelectAlertRule struct {
+	Id int
+	Name string
+	BrokerId int
+	TopicFilter string
+	ConditionPath string
+	ConditionOperator string
+	ConditionValue string
+	SilenceSeconds int
+	ForSeconds int
+	Hysteresis float64
+	WebhookId int
+	CreationDate time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB          : It's a connection to the database.
+// - rule InsertAlertRule : It's inserted into table `AlertRule`
+//
+// # Tables Affected
+// - AlertRule
+//   - INSERT
+//
+// # Returns
+// - int: [AlertRule].[ID], -1 on error
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func InsertNewAlertRule(con *sql.DB, rule InsertAlertRule) (int, error) {
+	result, err := con.Exec(`
+		INSERT INTO AlertRule(Name, BrokerId, TopicFilter, ConditionPath, ConditionOperator, ConditionValue, SilenceSeconds, ForSeconds, Hysteresis, WebhookId, CreationDate)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rule.Name, rule.BrokerId, rule.TopicFilter, rule.ConditionPath, rule.ConditionOperator, rule.ConditionValue, rule.SilenceSeconds, rule.ForSeconds, rule.Hysteresis, rule.WebhookId, time.Now())
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	ruleId, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return int(ruleId), nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+//
+// # Tables Affected
+// - AlertRule
+//   - SELECT
+//
+// # Returns
+// - A list of struct `SelectAlertRule`, the oldest first
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectAlertRules(con *sql.DB) ([]SelectAlertRule, error) {
+	var ruleList []SelectAlertRule
+
+	rows, err := con.Query(`
+		SELECT ID, Name, BrokerId, TopicFilter, ConditionPath, ConditionOperator, ConditionValue, SilenceSeconds, ForSeconds, Hysteresis, WebhookId, CreationDate
+		FROM AlertRule
+		ORDER BY ID
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rule SelectAlertRule
+		rows.Scan(&rule.Id, &rule.Name, &rule.BrokerId, &rule.TopicFilter, &rule.ConditionPath, &rule.ConditionOperator, &rule.ConditionValue, &rule.SilenceSeconds, &rule.ForSeconds, &rule.Hysteresis, &rule.WebhookId, &rule.CreationDate)
+		ruleList = append(ruleList, rule)
+	}
+
+	return ruleList, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+// - id int      : [AlertRule].[ID]
+//
+// # Description
+// - The function shall delete the rule with its events in one transaction.
+//
+// # Tables Affected
+// - AlertEvent, AlertRule
+//   - DELETE
+//
+// # Returns
+// - bool: false if there was no such rule
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteAlertRule(con *sql.DB, id int) (bool, error) {
+	tx, err := con.Begin()
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM AlertEvent WHERE RuleId = ?", id); err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	result, err := tx.Exec("DELETE FROM AlertRule WHERE ID = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+/*                                     +------------+                                     */
+/* ------------------------------------| ALERTEVENT |------------------------------------ */
+/*                                     +------------+                                     */
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The states of an alert event. A rule fires once, and is resolved once, until it fires again.
+const (
+	ALERT_FIRING = "firing"
+	ALERT_RESOLVED = "resolved"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct InsertAlertEvent | Table AlertEvent      |
+// +-------------------------+-----------------------+
+// |                         | ID INTEGER            |
+// | RuleId int              | RuleId INTEGER        |
+// | BrokerId int            | BrokerId INTEGER      |
+// | Topic string            | Topic TEXT            |
+// | State string            | State TEXT            |
+// | Value string            | Value TEXT            |
+// |                         | CreationDate DATETIME |
+//
+// # Note
+// - Value is the value that changed the state, or how long nothing was received for a rule with SilenceSeconds.
+//
+// # Used in
+// - InsertNewAlertEvent()
+//
+// # Author
+// - Polariusz
+type InsertAlertEvent struct {
+	RuleId int
+	BrokerId int
+	Topic string
+	State string
+	Value string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct SelectAlertEvent | Table AlertEvent      |
+// +-------------------------+-----------------------+
+// | Id int                  | ID INTEGER            |
+// | RuleId int              | RuleId INTEGER        |
+// | BrokerId int            | BrokerId INTEGER      |
+// | Topic string            | Topic TEXT            |
+// | State string            | State TEXT            |
+// | Value string            | Value TEXT            |
+// | CreationDate time.Time  | CreationDate DATETIME |
+//
+// # Author
+// - Polariusz
+type SelectAlertEvent struct {
+	Id int
+	RuleId int
+	BrokerId int
+	Topic string
+	State string
+	Value string
+	CreationDate time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - Which events SelectAlertEvents() selects. Every field that is set narrows the selection, an unset field is 0 or empty.
+// - `BeforeId` is the cursor of the pages, only the events older than it are selected.
+//
+// # Used in
+// - SelectAlertEvents()
+//
+// # Author
+// - Polariusz
+type AlertEventFilter struct {
+	RuleId int
+	BrokerId int
+	State string
+	BeforeId int
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB            : It's a connection to the database.
+// - event InsertAlertEvent : It's appended to table `AlertEvent`
+//
+// # Tables Affected
+// - AlertEvent
+//   - INSERT
+//
+// # Returns
+// - SelectAlertEvent: The event as it was inserted
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func InsertNewAlertEvent(con *sql.DB, event InsertAlertEvent) (SelectAlertEvent, error) {
+	inserted := SelectAlertEvent{RuleId: event.RuleId, BrokerId: event.BrokerId, Topic: event.Topic, State: event.State, Value: event.Value, CreationDate: time.Now()}
+
+	result, err := con.Exec(`
+		INSERT INTO AlertEvent(RuleId, BrokerId, Topic, State, Value, CreationDate)
+		VALUES(?, ?, ?, ?, ?, ?)
+	`, inserted.RuleId, inserted.BrokerId, inserted.Topic, inserted.State, inserted.Value, inserted.CreationDate)
+	if err != nil {
+		return inserted, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	eventId, err := result.LastInsertId()
+	if err != nil {
+		return inserted, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	inserted.Id = int(eventId)
+
+	return inserted, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB             : It's a connection to the database.
+// - filter AlertEventFilter : Which events are selected.
+// - limit int               : The most events that are selected.
+//
+// # Tables Affected
+// - AlertEvent
+//   - SELECT
+//
+// # Returns
+// - A list of struct `SelectAlertEvent`, the newest first
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectAlertEvents(con *sql.DB, filter AlertEventFilter, limit int) ([]SelectAlertEvent, error) {
+	var eventList []SelectAlertEvent
+
+	conditions := []string{"1 = 1"}
+	var args []any
+	if filter.RuleId != 0 {
+		conditions = append(conditions, "RuleId = ?")
+		args = append(args, filter.RuleId)
+	}
+	if filter.BrokerId != 0 {
+		conditions = append(conditions, "BrokerId = ?")
+		args = append(args, filter.BrokerId)
+	}
+	if filter.State != "" {
+		conditions = append(conditions, "State = ?")
+		args = append(args, filter.State)
+	}
+	if filter.BeforeId != 0 {
+		conditions = append(conditions, "ID < ?")
+		args = append(args, filter.BeforeId)
+	}
+
+	rows, err := con.Query(`
+		SELECT ID, RuleId, BrokerId, Topic, State, Value, CreationDate
+		FROM AlertEvent
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY ID DESC
+		LIMIT ?
+	`, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var event SelectAlertEvent
+		rows.Scan(&event.Id, &event.RuleId, &event.BrokerId, &event.Topic, &event.State, &event.Value, &event.CreationDate)
+		eventList = append(eventList, event)
+	}
+
+	return eventList, nil
+}
@@ -0,0 +1,256 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+/*                                       +----------+                                       */
+/* --------------------------------------| AUDITLOG |-------------------------------------- */
+/*                                       +----------+                                       */
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct InsertAuditEntry | Table AuditLog        |
+// +-------------------------+-----------------------+
+// |                         | ID INTEGER            |
+// |                         | CreationDate DATETIME |
+// | AccountId int           | AccountId INTEGER     |
+// | Actor string            | Actor TEXT            |
+// | RemoteAddress string    | RemoteAddress TEXT    |
+// | Action string           | Action TEXT           |
+// | BrokerId int            | BrokerId INTEGER      |
+// | UserId int              | UserId INTEGER        |
+// | Topic string            | Topic TEXT            |
+// | PayloadHash string      | PayloadHash TEXT      |
+// | Outcome string          | Outcome TEXT          |
+// | Status int              | Status INTEGER        |
+// | Detail string           | Detail TEXT           |
+//
+// # Note
+// - AccountId is 0 and Actor is "anonymous" while the API has no accounts.
+// - PayloadHash is the hex SHA-256 of the published payload, empty for the other actions.
+// - Status is the HTTP status of the response, Outcome is derived from it by the server.
+//
+// # Used in
+// - InsertNewAuditEntry()
+//
+// # Author
+// - Polariusz
+type InsertAuditEntry struct {
+	AccountId int
+	Actor string
+	RemoteAddress string
+	Action string
+	BrokerId int
+	UserId int
+	Topic string
+	PayloadHash string
+	Outcome string
+	Status int
+	Detail string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct SelectAuditEntry | Table AuditLog        |
+// +-------------------------+-----------------------+
+// | Id int                  | ID INTEGER            |
+// | CreationDate time.Time  | CreationDate DATETIME |
+// | AccountId int           | AccountId INTEGER     |
+// | Actor string            | Actor TEXT            |
+// | RemoteAddress string    | RemoteAddress TEXT    |
+// | Action string           | Action TEXT           |
+// | BrokerId int            | BrokerId INTEGER      |
+// | UserId int              | UserId INTEGER        |
+// | Topic string            | Topic TEXT            |
+// | PayloadHash string      | PayloadHash TEXT      |
+// | Outcome string          | Outcome TEXT          |
+// | Status int              | Status INTEGER        |
+// | Detail string           | Detail TEXT           |
+//
+// # Author
+// - Polariusz
+type SelectAuditEntry struct {
+	Id int
+	CreationDate time.Time
+	AccountId int
+	Actor string
+	RemoteAddress string
+	Action string
+	BrokerId int
+	UserId int
+	Topic string
+	PayloadHash string
+	Outcome string
+	Status int
+	Detail string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - Which entries SelectAuditEntries() selects. Every field that is set narrows the selection, an unset field is not used.
+// - The strings are unset when empty, `BrokerId` and `BeforeId` when 0, `Before` and `After` when zero.
+// - `TopicPrefix` only keeps the entries whose topic starts with it, the server narrows them down to a MQTT topic filter.
+// - `BeforeId` is the cursor of the pages, only the entries older than it are selected.
+//
+// # Used in
+// - SelectAuditEntries()
+//
+// # Author
+// - Polariusz
+type AuditFilter struct {
+	Actor string
+	Action string
+	BrokerId int
+	Topic string
+	TopicPrefix string
+	Outcome string
+	After time.Time
+	Before time.Time
+	BeforeId int
+}
+
+// # Description
+// - The method shall return the WHERE clause of the filter and its arguments.
+//
+// # Author
+// - Polariusz
+func (filter AuditFilter) where() (string, []any) {
+	conditions := []string{"1 = 1"}
+	var args []any
+
+	if filter.Actor != "" {
+		conditions = append(conditions, "Actor = ?")
+		args = append(args, filter.Actor)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "Action = ?")
+		args = append(args, filter.Action)
+	}
+	if filter.BrokerId != 0 {
+		conditions = append(conditions, "BrokerId = ?")
+		args = append(args, filter.BrokerId)
+	}
+	if filter.Topic != "" {
+		conditions = append(conditions, "Topic = ?")
+		args = append(args, filter.Topic)
+	}
+	if filter.TopicPrefix != "" {
+		// The prefix is compared as it is, so `%` and `_` in a topic are no wildcards.
+		conditions = append(conditions, "substr(Topic, 1, ?) = ?")
+		args = append(args, len(filter.TopicPrefix), filter.TopicPrefix)
+	}
+	if filter.Outcome != "" {
+		conditions = append(conditions, "Outcome = ?")
+		args = append(args, filter.Outcome)
+	}
+	if !filter.After.IsZero() {
+		conditions = append(conditions, "CreationDate >= ?")
+		args = append(args, filter.After)
+	}
+	if !filter.Before.IsZero() {
+		conditions = append(conditions, "CreationDate < ?")
+		args = append(args, filter.Before)
+	}
+	if filter.BeforeId != 0 {
+		conditions = append(conditions, "ID < ?")
+		args = append(args, filter.BeforeId)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB            : It's a connection to the database.
+// - entry InsertAuditEntry : It's appended to table `AuditLog`
+//
+// # Tables Affected
+// - AuditLog
+//   - INSERT
+//
+// # Returns
+// - int: [AuditLog].[ID], -1 on error
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func InsertNewAuditEntry(con *sql.DB, entry InsertAuditEntry) (int, error) {
+	result, err := con.Exec(`
+		INSERT INTO AuditLog(CreationDate, AccountId, Actor, RemoteAddress, Action, BrokerId, UserId, Topic, PayloadHash, Outcome, Status, Detail)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, time.Now(), entry.AccountId, entry.Actor, entry.RemoteAddress, entry.Action, entry.BrokerId, entry.UserId, entry.Topic, entry.PayloadHash, entry.Outcome, entry.Status, entry.Detail)
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	entryId, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return int(entryId), nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB        : It's a connection to the database.
+// - filter AuditFilter : Which entries are selected.
+// - limit int          : The most entries that are selected.
+//
+// # Tables Affected
+// - AuditLog
+//   - SELECT
+//
+// # Returns
+// - A list of struct `SelectAuditEntry`, the newest first
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectAuditEntries(con *sql.DB, filter AuditFilter, limit int) ([]SelectAuditEntry, error) {
+	var entryList []SelectAuditEntry
+
+	where, args := filter.where()
+	rows, err := con.Query(`
+		SELECT ID, CreationDate, AccountId, Actor, RemoteAddress, Action, BrokerId, UserId, Topic, PayloadHash, Outcome, Status, Detail
+		FROM AuditLog
+		WHERE `+where+`
+		ORDER BY ID DESC
+		LIMIT ?
+	`, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry SelectAuditEntry
+		rows.Scan(&entry.Id, &entry.CreationDate, &entry.AccountId, &entry.Actor, &entry.RemoteAddress, &entry.Action, &entry.BrokerId, &entry.UserId, &entry.Topic, &entry.PayloadHash, &entry.Outcome, &entry.Status, &entry.Detail)
+		entryList = append(entryList, entry)
+	}
+
+	return entryList, nil
+}
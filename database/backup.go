@@ -0,0 +1,481 @@
+package database
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - Backups whose path ends with it are compressed with gzip.
+//
+// # Author
+// - Polariusz
+const COMPRESSED_BACKUP_SUFFIX = ".gz"
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB  : It's a connection to the database.
+// - path string  : The file that the backup is written to. It must not exist yet.
+//
+// # Description
+// - The function shall write a consistent copy of the database into the file `path` with VACUUM INTO.
+// - VACUUM INTO reads the database in one read transaction, so the ingest queue can keep writing while the backup is taken.
+// - If `path` ends with `COMPRESSED_BACKUP_SUFFIX`, the copy is compressed with gzip.
+//
+// # Returns
+// - int64: Size of the backup file in bytes
+// - error when:
+//   - The file `path` already exists
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func BackupDatabase(con *sql.DB, path string) (int64, error) {
+	if _, err := os.Stat(path); err == nil {
+		return 0, fmt.Errorf("Error: The file '%s' already exists!\n", path)
+	}
+
+	if !strings.HasSuffix(path, COMPRESSED_BACKUP_SUFFIX) {
+		if _, err := con.Exec("VACUUM INTO ?", path); err != nil {
+			return 0, fmt.Errorf("Error while writing the backup!\nErr: %s\n", err)
+		}
+		return fileSize(path)
+	}
+
+	uncompressedPath := strings.TrimSuffix(path, COMPRESSED_BACKUP_SUFFIX) + ".tmp"
+	os.Remove(uncompressedPath)
+	defer os.Remove(uncompressedPath)
+
+	if _, err := con.Exec("VACUUM INTO ?", uncompressedPath); err != nil {
+		return 0, fmt.Errorf("Error while writing the backup!\nErr: %s\n", err)
+	}
+	if err := compressFile(uncompressedPath, path); err != nil {
+		os.Remove(path)
+		return 0, err
+	}
+
+	return fileSize(path)
+}
+
+// # Author
+// - Polariusz
+func fileSize(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	return info.Size(), nil
+}
+
+// # Author
+// - Polariusz
+func compressFile(sourcePath string, destinationPath string) error {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return fmt.Errorf("Error while opening the backup!\nErr: %s\n", err)
+	}
+	defer source.Close()
+
+	destination, err := os.OpenFile(destinationPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("Error while creating the compressed backup!\nErr: %s\n", err)
+	}
+	defer destination.Close()
+
+	writer := gzip.NewWriter(destination)
+	if _, err := io.Copy(writer, source); err != nil {
+		return fmt.Errorf("Error while compressing the backup!\nErr: %s\n", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("Error while compressing the backup!\nErr: %s\n", err)
+	}
+
+	return destination.Sync()
+}
+
+// # Author
+// - Polariusz
+func decompressFile(sourcePath string, destinationPath string) error {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return fmt.Errorf("Error while opening the backup!\nErr: %s\n", err)
+	}
+	defer source.Close()
+
+	reader, err := gzip.NewReader(source)
+	if err != nil {
+		return fmt.Errorf("Error: The backup is not a gzip file!\nErr: %s\n", err)
+	}
+	defer reader.Close()
+
+	destination, err := os.Create(destinationPath)
+	if err != nil {
+		return fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer destination.Close()
+
+	if _, err := io.Copy(destination, reader); err != nil {
+		return fmt.Errorf("Error while decompressing the backup!\nErr: %s\n", err)
+	}
+
+	return nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - What ValidateBackup() has found out about a backup.
+//
+// # Author
+// - Polariusz
+type BackupInfo struct {
+	Path string
+	Compressed bool
+	SchemaVersion int
+	Brokers int
+	Messages int
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - path string : The backup file, it may be compressed.
+//
+// # Description
+// - The function shall check that the file is an intact explorer database that this build can migrate:
+//   - `PRAGMA integrity_check` must return ok.
+//   - The tables of the baseline must exist.
+//   - The schema version must not be newer than `LatestSchemaVersion()`.
+// - A compressed backup is decompressed into a temporary file first.
+//
+// # Returns
+// - BackupInfo with the schema version of the backup
+// - error when the backup is not valid
+//
+// # Author
+// - Polariusz
+func ValidateBackup(path string) (BackupInfo, error) {
+	info := BackupInfo{Path: path, Compressed: strings.HasSuffix(path, COMPRESSED_BACKUP_SUFFIX)}
+
+	err := withUncompressedBackup(path, func(uncompressedPath string) error {
+		backupCon, err := sql.Open("sqlite3", "file:"+uncompressedPath+"?mode=ro")
+		if err != nil {
+			return fmt.Errorf("Error while opening the backup!\nErr: %s\n", err)
+		}
+		defer backupCon.Close()
+
+		return validateBackupCon(backupCon, &info)
+	})
+
+	return info, err
+}
+
+// # Author
+// - Polariusz
+func validateBackupCon(backupCon *sql.DB, info *BackupInfo) error {
+	var integrity string
+	if err := backupCon.QueryRow("PRAGMA integrity_check").Scan(&integrity); err != nil {
+		return fmt.Errorf("Error: The backup is not a SQLite database!\nErr: %s\n", err)
+	}
+	if integrity != "ok" {
+		return fmt.Errorf("Error: The backup is corrupted!\nIntegrity check: %s\n", integrity)
+	}
+
+	for _, table := range []string{"Broker", "User", "Topic", "Message"} {
+		exists, err := tableExists(backupCon, table)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("Error: The backup has no table %s, it is not an explorer database!\n", table)
+		}
+	}
+
+	stateList, err := MigrationStatus(backupCon)
+	if err != nil {
+		return err
+	}
+	for _, state := range stateList {
+		if state.Applied && state.Version > info.SchemaVersion {
+			info.SchemaVersion = state.Version
+		}
+	}
+
+	backupCon.QueryRow("SELECT COUNT(*) FROM Broker").Scan(&info.Brokers)
+	backupCon.QueryRow("SELECT COUNT(*) FROM Message").Scan(&info.Messages)
+
+	return nil
+}
+
+// # Description
+// - The function shall call `use` with the path of the uncompressed backup, decompressing it into a temporary file if needed.
+//
+// # Author
+// - Polariusz
+func withUncompressedBackup(path string, use func(uncompressedPath string) error) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("Error: The backup '%s' does not exist!\n", path)
+	}
+	if !strings.HasSuffix(path, COMPRESSED_BACKUP_SUFFIX) {
+		return use(path)
+	}
+
+	temporary, err := os.CreateTemp("", "explorer-restore-*.db")
+	if err != nil {
+		return fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	temporary.Close()
+	defer os.Remove(temporary.Name())
+
+	if err := decompressFile(path, temporary.Name()); err != nil {
+		return err
+	}
+
+	return use(temporary.Name())
+}
+
+// | Date of change | By        | Comment             |
+// +----------------+-----------+---------------------+
+// | 2026-10-19     | Polariusz | Created             |
+// | 2026-10-19     | Polariusz | Keeps the audit log |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database that is overwritten.
+// - path string : The backup file, it may be compressed.
+//
+// # Description
+// - The function shall validate the backup with ValidateBackup() and then copy it over the database with the SQLite backup API.
+// - The backup API writes through the connection, so every other connection to the database sees the restored content right away.
+// - A backup with an older schema version is migrated afterwards.
+// - Table AuditLog is not taken from the backup, the log of the database is kept as it was, see saveAuditLog() and loadAuditLog().
+// - Nothing else may write into the database while it is restored.
+//
+// # Returns
+// - BackupInfo of the restored backup
+// - error when:
+//   - The backup is not valid
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func RestoreDatabase(con *sql.DB, path string) (BackupInfo, error) {
+	info := BackupInfo{Path: path, Compressed: strings.HasSuffix(path, COMPRESSED_BACKUP_SUFFIX)}
+
+	auditLogPath, err := saveAuditLog(con)
+	if err != nil {
+		return info, err
+	}
+	if auditLogPath != "" {
+		defer os.Remove(auditLogPath)
+	}
+
+	err = withUncompressedBackup(path, func(uncompressedPath string) error {
+		backupCon, err := sql.Open("sqlite3", "file:"+uncompressedPath+"?mode=ro")
+		if err != nil {
+			return fmt.Errorf("Error while opening the backup!\nErr: %s\n", err)
+		}
+		defer backupCon.Close()
+
+		if err := validateBackupCon(backupCon, &info); err != nil {
+			return err
+		}
+
+		return copyDatabase(backupCon, con)
+	})
+	if err != nil {
+		return info, err
+	}
+
+	if _, err := Migrate(con, false); err != nil {
+		return info, err
+	}
+	if auditLogPath != "" {
+		if err := loadAuditLog(con, auditLogPath); err != nil {
+			return info, err
+		}
+	}
+
+	return info, nil
+}
+
+// The columns of table AuditLog that saveAuditLog() and loadAuditLog() copy.
+const auditLogColumns = "ID, CreationDate, AccountId, Actor, RemoteAddress, Action, BrokerId, UserId, Topic, PayloadHash, Outcome, Status, Detail"
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall copy table AuditLog of `con` into a temporary database, so RestoreDatabase() can put it back after the backup overwrote it.
+// - The audit log is append-only, a backup must not bring back an older log.
+//
+// # Returns
+// - string: The path of the temporary database, empty if `con` has no audit log. The caller removes it.
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func saveAuditLog(con *sql.DB) (string, error) {
+	exists, err := tableExists(con, "AuditLog")
+	if err != nil || !exists {
+		return "", err
+	}
+
+	temporary, err := os.CreateTemp("", "explorer-audit-*.db")
+	if err != nil {
+		return "", fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	temporary.Close()
+
+	err = withAttached(con, temporary.Name(), func(conn *sql.Conn) error {
+		_, err := conn.ExecContext(context.Background(), "CREATE TABLE saved.AuditLog AS SELECT "+auditLogColumns+" FROM main.AuditLog")
+		return err
+	})
+	if err != nil {
+		os.Remove(temporary.Name())
+		return "", fmt.Errorf("Error while saving the audit log!\nErr: %s\n", err)
+	}
+
+	return temporary.Name(), nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall replace table AuditLog of `con` with the one saved by saveAuditLog(), in one transaction.
+// - The trigger that refuses deletes is dropped for the transaction and created again with its own statement.
+//
+// # Tables Affected
+// - AuditLog
+//   - DELETE
+//   - INSERT
+//
+// # Returns
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func loadAuditLog(con *sql.DB, path string) error {
+	err := withAttached(con, path, func(conn *sql.Conn) error {
+		ctx := context.Background()
+
+		var trigger sql.NullString
+		err := conn.QueryRowContext(ctx, "SELECT sql FROM main.sqlite_master WHERE type = 'trigger' AND name = 'TR_AuditLog_NoDelete'").Scan(&trigger)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		statements := []string{
+			"DROP TRIGGER IF EXISTS main.TR_AuditLog_NoDelete",
+			"DELETE FROM main.AuditLog",
+			"INSERT INTO main.AuditLog(" + auditLogColumns + ") SELECT " + auditLogColumns + " FROM saved.AuditLog",
+		}
+		if trigger.Valid {
+			statements = append(statements, trigger.String)
+		}
+		for _, statement := range statements {
+			if _, err := tx.ExecContext(ctx, statement); err != nil {
+				return err
+			}
+		}
+
+		return tx.Commit()
+	})
+	if err != nil {
+		return fmt.Errorf("Error while putting back the audit log!\nErr: %s\n", err)
+	}
+
+	return nil
+}
+
+// # Description
+// - The function shall call `use` with a connection of `con` that has the database `path` attached as `saved`.
+//
+// # Author
+// - Polariusz
+func withAttached(con *sql.DB, path string, use func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+
+	conn, err := con.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS saved", path); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, "DETACH DATABASE saved")
+
+	return use(conn)
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall copy the whole database `source` into `destination` with the SQLite online backup API.
+//
+// # Author
+// - Polariusz
+func copyDatabase(source *sql.DB, destination *sql.DB) error {
+	ctx := context.Background()
+
+	sourceConn, err := source.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer sourceConn.Close()
+
+	destinationConn, err := destination.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer destinationConn.Close()
+
+	return destinationConn.Raw(func(destinationDriver any) error {
+		return sourceConn.Raw(func(sourceDriver any) error {
+			backup, err := destinationDriver.(*sqlite3.SQLiteConn).Backup("main", sourceDriver.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return fmt.Errorf("Error while starting the restore!\nErr: %s\n", err)
+			}
+			if _, err := backup.Step(-1); err != nil {
+				backup.Close()
+				return fmt.Errorf("Error while restoring!\nErr: %s\n", err)
+			}
+			if err := backup.Finish(); err != nil {
+				return fmt.Errorf("Error while finishing the restore!\nErr: %s\n", err)
+			}
+			return nil
+		})
+	})
+}
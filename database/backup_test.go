@@ -0,0 +1,67 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+// TestRestoreKeepsAuditLog makes sure that a restore takes everything from
+// the backup but table AuditLog, which stays append-only across it.
+func TestRestoreKeepsAuditLog(t *testing.T) {
+	directory := t.TempDir()
+	con, err := sql.Open("sqlite3", filepath.Join(directory, "explorer.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer con.Close()
+	if _, err := Migrate(con, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := InsertNewAuditEntry(con, InsertAuditEntry{Actor: "admin", Action: "before.backup"}); err != nil {
+		t.Fatal(err)
+	}
+	backupPath := filepath.Join(directory, "backup.db")
+	if _, err := BackupDatabase(con, backupPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := InsertNewBroker(con, InsertBroker{Ip: "127.0.0.1", Port: 1883}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := InsertNewAuditEntry(con, InsertAuditEntry{Actor: "admin", Action: "after.backup"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := RestoreDatabase(con, backupPath); err != nil {
+		t.Fatal(err)
+	}
+
+	var brokers int
+	if err := con.QueryRow("SELECT COUNT(*) FROM Broker").Scan(&brokers); err != nil {
+		t.Fatal(err)
+	}
+	if brokers != 0 {
+		t.Errorf("%d brokers after the restore, want the 0 of the backup", brokers)
+	}
+
+	entryList, err := SelectAuditEntries(con, AuditFilter{}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entryList) != 2 || entryList[0].Action != "after.backup" || entryList[1].Action != "before.backup" {
+		t.Fatalf("audit log after the restore = %+v, want both entries", entryList)
+	}
+
+	if _, err := con.Exec("DELETE FROM AuditLog"); err == nil {
+		t.Error("the audit log could be deleted after the restore")
+	}
+	id, err := InsertNewAuditEntry(con, InsertAuditEntry{Actor: "admin", Action: "after.restore"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id <= entryList[0].Id {
+		t.Errorf("the next entry got Id %d, want more than %d", id, entryList[0].Id)
+	}
+}
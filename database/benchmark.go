@@ -0,0 +1,386 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+/*                                      +-----------+                                      */
+/* -------------------------------------| BENCHMARK |------------------------------------- */
+/*                                      +-----------+                                      */
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The states of a benchmark. A `cancelled` benchmark keeps what it has measured until it was cancelled.
+const (
+	BENCHMARK_RUNNING = "running"
+	BENCHMARK_FINISHED = "finished"
+	BENCHMARK_CANCELLED = "cancelled"
+	BENCHMARK_FAILED = "failed"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct InsertBenchmark     | Table Benchmark             |
+// +----------------------------+-----------------------------+
+// |                            | ID INTEGER                  |
+// | Name string                | Name TEXT                   |
+// | BrokerIp string            | BrokerIp TEXT               |
+// | BrokerPort int             | BrokerPort INTEGER          |
+// | Publishers int             | Publishers INTEGER          |
+// | Subscribers int            | Subscribers INTEGER         |
+// | RatePerSecond float64      | RatePerSecond REAL          |
+// | PayloadBytes int           | PayloadBytes INTEGER        |
+// | QoS int                    | QoS INTEGER                 |
+// | TopicPattern string        | TopicPattern TEXT           |
+// | DurationSeconds int        | DurationSeconds INTEGER     |
+// |                            | Status TEXT                 |
+// |                            | <BenchmarkResult>           |
+// |                            | CreationDate DATETIME       |
+//
+// # Note
+// - The broker is kept by its address, not by a row of Broker, so a benchmark can be run against a broker the explorer has never connected to.
+// - RatePerSecond is per publisher.
+// - A new benchmark is `running`.
+//
+// # Used in
+// - InsertNewBenchmark()
+//
+// # Author
+// - Polariusz
+type InsertBenchmark struct {
+	Name string
+	BrokerIp string
+	BrokerPort int
+	Publishers int
+	Subscribers int
+	RatePerSecond float64
+	PayloadBytes int
+	QoS int
+	TopicPattern string
+	DurationSeconds int
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct BenchmarkResult      | Table Benchmark             |
+// +-----------------------------+-----------------------------+
+// | Status string               | Status TEXT                 |
+// | Error string                | Error TEXT                  |
+// | Sent int                    | Sent INTEGER                |
+// | Received int                | Received INTEGER            |
+// | Lost int                    | Lost INTEGER                |
+// | LossPercent float64         | LossPercent REAL            |
+// | ThroughputPerSecond float64 | ThroughputPerSecond REAL    |
+// | LatencyMinMs float64        | LatencyMinMs REAL           |
+// | LatencyMeanMs float64       | LatencyMeanMs REAL          |
+// | LatencyP50Ms float64        | LatencyP50Ms REAL           |
+// | LatencyP90Ms float64        | LatencyP90Ms REAL           |
+// | LatencyP95Ms float64        | LatencyP95Ms REAL           |
+// | LatencyP99Ms float64        | LatencyP99Ms REAL           |
+// | LatencyMaxMs float64        | LatencyMaxMs REAL           |
+//
+// # Note
+// - Received counts every message once per subscriber, Lost is what the subscribers should have received but did not.
+// - ThroughputPerSecond is the messages received per second of the publishing.
+//
+// # Used in
+// - UpdateBenchmarkResult()
+//
+// # Author
+// - Polariusz
+type BenchmarkResult struct {
+	Status string
+	Error string
+	Sent int
+	Received int
+	Lost int
+	LossPercent float64
+	ThroughputPerSecond float64
+	LatencyMinMs float64
+	LatencyMeanMs float64
+	LatencyP50Ms float64
+	LatencyP90Ms float64
+	LatencyP95Ms float64
+	LatencyP99Ms float64
+	LatencyMaxMs float64
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct SelectBenchmark      | Table Benchmark             |
+// +-----------------------------+-----------------------------+
+// | Id int                      | ID INTEGER                  |
+// | Name string                 | Name TEXT                   |
+// | BrokerIp string             | BrokerIp TEXT               |
+// | BrokerPort int              | BrokerPort INTEGER          |
+// | Publishers int              | Publishers INTEGER          |
+// | Subscribers int             | Subscribers INTEGER         |
+// | RatePerSecond float64       | RatePerSecond REAL          |
+// | PayloadBytes int            | PayloadBytes INTEGER        |
+// | QoS int                     | QoS INTEGER                 |
+// | TopicPattern string         | TopicPattern TEXT           |
+// | DurationSeconds int         | DurationSeconds INTEGER     |
+// | Result BenchmarkResult      | Status TEXT, Error TEXT, .. |
+// | FinishedAt *time.Time       | FinishedAt DATETIME         |
+// | CreationDate time.Time      | CreationDate DATETIME       |
+//
+// # Note
+// - FinishedAt is null while the benchmark is running.
+//
+// # Author
+// - Polariusz
+type SelectBenchmark struct {
+	Id int
+	Name string
+	BrokerIp string
+	BrokerPort int
+	Publishers int
+	Subscribers int
+	RatePerSecond float64
+	PayloadBytes int
+	QoS int
+	TopicPattern string
+	DurationSeconds int
+	Result BenchmarkResult
+	FinishedAt *time.Time
+	CreationDate time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB               : It's a connection to the database.
+// - benchmark InsertBenchmark : It's inserted into table `Benchmark`
+//
+// # Tables Affected
+// - Benchmark
+//   - INSERT
+//
+// # Returns
+// - int: [Benchmark].[ID], -1 on error
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func InsertNewBenchmark(con *sql.DB, benchmark InsertBenchmark) (int, error) {
+	result, err := con.Exec(`
+		INSERT INTO Benchmark(Name, BrokerIp, BrokerPort, Publishers, Subscribers, RatePerSecond, PayloadBytes, QoS, TopicPattern, DurationSeconds, Status, Error,
+			Sent, Received, Lost, LossPercent, ThroughputPerSecond, LatencyMinMs, LatencyMeanMs, LatencyP50Ms, LatencyP90Ms, LatencyP95Ms, LatencyP99Ms, LatencyMaxMs, CreationDate)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, '', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, ?)
+	`, benchmark.Name, benchmark.BrokerIp, benchmark.BrokerPort, benchmark.Publishers, benchmark.Subscribers, benchmark.RatePerSecond, benchmark.PayloadBytes, benchmark.QoS, benchmark.TopicPattern, benchmark.DurationSeconds, BENCHMARK_RUNNING, time.Now())
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	benchmarkId, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return int(benchmarkId), nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB            : It's a connection to the database.
+// - id int                 : [Benchmark].[ID]
+// - result BenchmarkResult : What the benchmark has measured, it ends the benchmark.
+//
+// # Tables Affected
+// - Benchmark
+//   - UPDATE
+//
+// # Returns
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func UpdateBenchmarkResult(con *sql.DB, id int, result BenchmarkResult) error {
+	_, err := con.Exec(`
+		UPDATE Benchmark
+		SET Status = ?, Error = ?, Sent = ?, Received = ?, Lost = ?, LossPercent = ?, ThroughputPerSecond = ?,
+			LatencyMinMs = ?, LatencyMeanMs = ?, LatencyP50Ms = ?, LatencyP90Ms = ?, LatencyP95Ms = ?, LatencyP99Ms = ?, LatencyMaxMs = ?, FinishedAt = ?
+		WHERE ID = ?
+	`, result.Status, result.Error, result.Sent, result.Received, result.Lost, result.LossPercent, result.ThroughputPerSecond,
+		result.LatencyMinMs, result.LatencyMeanMs, result.LatencyP50Ms, result.LatencyP90Ms, result.LatencyP95Ms, result.LatencyP99Ms, result.LatencyMaxMs, time.Now(), id)
+	if err != nil {
+		return fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB    : It's a connection to the database.
+// - message string : The Error of the benchmarks.
+//
+// # Description
+// - The function shall fail the benchmarks that are still running, as nothing runs them after a restart of the server.
+//
+// # Tables Affected
+// - Benchmark
+//   - UPDATE
+//
+// # Returns
+// - int: How many benchmarks were failed
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func FailRunningBenchmarks(con *sql.DB, message string) (int, error) {
+	result, err := con.Exec("UPDATE Benchmark SET Status = ?, Error = ?, FinishedAt = ? WHERE Status = ?", BENCHMARK_FAILED, message, time.Now(), BENCHMARK_RUNNING)
+	if err != nil {
+		return 0, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return int(affected), nil
+}
+
+const selectBenchmarkColumns = `
+	SELECT ID, Name, BrokerIp, BrokerPort, Publishers, Subscribers, RatePerSecond, PayloadBytes, QoS, TopicPattern, DurationSeconds, Status, Error,
+		Sent, Received, Lost, LossPercent, ThroughputPerSecond, LatencyMinMs, LatencyMeanMs, LatencyP50Ms, LatencyP90Ms, LatencyP95Ms, LatencyP99Ms, LatencyMaxMs, FinishedAt, CreationDate
+	FROM Benchmark
+`
+
+// # Author
+// - Polariusz
+func scanSelectBenchmark(scan func(dest ...any) error) (SelectBenchmark, error) {
+	var benchmark SelectBenchmark
+	var finishedAt sql.NullTime
+	result := &benchmark.Result
+	err := scan(&benchmark.Id, &benchmark.Name, &benchmark.BrokerIp, &benchmark.BrokerPort, &benchmark.Publishers, &benchmark.Subscribers, &benchmark.RatePerSecond, &benchmark.PayloadBytes, &benchmark.QoS, &benchmark.TopicPattern, &benchmark.DurationSeconds, &result.Status, &result.Error,
+		&result.Sent, &result.Received, &result.Lost, &result.LossPercent, &result.ThroughputPerSecond, &result.LatencyMinMs, &result.LatencyMeanMs, &result.LatencyP50Ms, &result.LatencyP90Ms, &result.LatencyP95Ms, &result.LatencyP99Ms, &result.LatencyMaxMs, &finishedAt, &benchmark.CreationDate)
+	if finishedAt.Valid {
+		benchmark.FinishedAt = &finishedAt.Time
+	}
+	return benchmark, err
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+//
+// # Tables Affected
+// - Benchmark
+//   - SELECT
+//
+// # Returns
+// - A list of struct `SelectBenchmark`, the newest first
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectBenchmarks(con *sql.DB) ([]SelectBenchmark, error) {
+	var benchmarkList []SelectBenchmark
+
+	rows, err := con.Query(selectBenchmarkColumns + "ORDER BY ID DESC")
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		benchmark, err := scanSelectBenchmark(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+		}
+		benchmarkList = append(benchmarkList, benchmark)
+	}
+
+	return benchmarkList, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+// - id int      : [Benchmark].[ID]
+//
+// # Tables Affected
+// - Benchmark
+//   - SELECT
+//
+// # Returns
+// - SelectBenchmark of the ID
+// - bool: false if there is no such benchmark
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectBenchmarkById(con *sql.DB, id int) (SelectBenchmark, bool, error) {
+	benchmark, err := scanSelectBenchmark(con.QueryRow(selectBenchmarkColumns+"WHERE ID = ?", id).Scan)
+	if err == sql.ErrNoRows {
+		return benchmark, false, nil
+	}
+	if err != nil {
+		return benchmark, false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return benchmark, true, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+// - id int      : [Benchmark].[ID]
+//
+// # Tables Affected
+// - Benchmark
+//   - DELETE
+//
+// # Returns
+// - bool: false if there was no such benchmark
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteBenchmark(con *sql.DB, id int) (bool, error) {
+	result, err := con.Exec("DELETE FROM Benchmark WHERE ID = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
@@ -0,0 +1,300 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+/*                                       +--------+                                       */
+/* --------------------------------------| BRIDGE |-------------------------------------- */
+/*                                       +--------+                                       */
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - What a bridge does with the retain flag of a forwarded message.
+//   - `keep` forwards it as it was received, `never` clears it and `always` sets it.
+const (
+	BRIDGE_RETAIN_KEEP = "keep"
+	BRIDGE_RETAIN_NEVER = "never"
+	BRIDGE_RETAIN_ALWAYS = "always"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A topic rewrite rule of a bridge. A topic that starts with `From` is forwarded with `To` instead of it.
+//
+// # Used in
+// - InsertBridge
+// - SelectBridge
+//
+// # Author
+// - Polariusz
+type BridgeRewrite struct {
+	From string
+	To string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct InsertBridge           | Table Bridge                 |
+// +-------------------------------+------------------------------+
+// |                               | ID INTEGER                   |
+// | Name string                   | Name TEXT                    |
+// | SourceIp string               | SourceIp TEXT                |
+// | SourcePort int                | SourcePort INTEGER           |
+// | SourceClientId string         | SourceClientId TEXT          |
+// | SourceUsername string         | SourceUsername TEXT          |
+// | SourcePassword string         | SourcePassword TEXT          |
+// | TopicFilters []string         | TopicFilters TEXT            |
+// | DestinationIp string          | DestinationIp TEXT           |
+// | DestinationPort int           | DestinationPort INTEGER      |
+// | DestinationClientId string    | DestinationClientId TEXT     |
+// | DestinationUsername string    | DestinationUsername TEXT     |
+// | DestinationPassword string    | DestinationPassword TEXT     |
+// | Rewrites []BridgeRewrite      | Rewrites TEXT                |
+// | QoS int                       | QoS INTEGER                  |
+// | RetainMode string             | RetainMode TEXT              |
+// |                               | Enabled INTEGER              |
+// |                               | CreationDate DATETIME        |
+//
+// # Note
+// - TopicFilters and Rewrites are stored as JSON.
+// - QoS -1 forwards every message with the QoS it was received with.
+// - RetainMode is one of the BRIDGE_RETAIN_* constants.
+// - A new bridge is not Enabled, it has to be started.
+//
+// # Used in
+// - InsertNewBridge()
+//
+// # Author
+// - Polariusz
+type InsertBridge struct {
+	Name string
+	SourceIp string
+	SourcePort int
+	SourceClientId string
+	SourceUsername string
+	SourcePassword string
+	TopicFilters []string
+	DestinationIp string
+	DestinationPort int
+	DestinationClientId string
+	DestinationUsername string
+	DestinationPassword string
+	Rewrites []BridgeRewrite
+	QoS int
+	RetainMode string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct SelectBridge           | Table Bridge                 |
+// +-------------------------------+------------------------------+
+// | Id int                        | ID INTEGER                   |
+// | Name string                   | Name TEXT                    |
+// | SourceIp string               | SourceIp TEXT                |
+// | SourcePort int                | SourcePort INTEGER           |
+// | SourceClientId string         | SourceClientId TEXT          |
+// | SourceUsername string         | SourceUsername TEXT          |
+// | SourcePassword string         | SourcePassword TEXT          |
+// | TopicFilters []string         | TopicFilters TEXT            |
+// | DestinationIp string          | DestinationIp TEXT           |
+// | DestinationPort int           | DestinationPort INTEGER      |
+// | DestinationClientId string    | DestinationClientId TEXT     |
+// | DestinationUsername string    | DestinationUsername TEXT     |
+// | DestinationPassword string    | DestinationPassword TEXT     |
+// | Rewrites []BridgeRewrite      | Rewrites TEXT                |
+// | QoS int                       | QoS INTEGER                  |
+// | RetainMode string             | RetainMode TEXT              |
+// | Enabled bool                  | Enabled INTEGER              |
+// | CreationDate time.Time        | CreationDate DATETIME        |
+//
+// # Note
+// - The passwords are never sent to the client.
+//
+// # Author
+// - Polariusz
+type SelectBridge struct {
+	Id int
+	Name string
+	SourceIp string
+	SourcePort int
+	SourceClientId string
+	SourceUsername string
+	SourcePassword string `json:"-"`
+	TopicFilters []string
+	DestinationIp string
+	DestinationPort int
+	DestinationClientId string
+	DestinationUsername string
+	DestinationPassword string `json:"-"`
+	Rewrites []BridgeRewrite
+	QoS int
+	RetainMode string
+	Enabled bool
+	CreationDate time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB         : It's a connection to the database.
+// - bridge InsertBridge : It's inserted into table `Bridge`
+//
+// # Tables Affected
+// - Bridge
+//   - INSERT
+//
+// # Returns
+// - int: [Bridge].[ID], -1 on error
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func InsertNewBridge(con *sql.DB, bridge InsertBridge) (int, error) {
+	topicFilters, err := json.Marshal(bridge.TopicFilters)
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	rewrites, err := json.Marshal(bridge.Rewrites)
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	result, err := con.Exec(`
+		INSERT INTO Bridge(Name, SourceIp, SourcePort, SourceClientId, SourceUsername, SourcePassword, TopicFilters, DestinationIp, DestinationPort, DestinationClientId, DestinationUsername, DestinationPassword, Rewrites, QoS, RetainMode, Enabled, CreationDate)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 0, ?)
+	`, bridge.Name, bridge.SourceIp, bridge.SourcePort, bridge.SourceClientId, bridge.SourceUsername, bridge.SourcePassword, string(topicFilters), bridge.DestinationIp, bridge.DestinationPort, bridge.DestinationClientId, bridge.DestinationUsername, bridge.DestinationPassword, string(rewrites), bridge.QoS, bridge.RetainMode, time.Now())
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	bridgeId, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return int(bridgeId), nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+//
+// # Tables Affected
+// - Bridge
+//   - SELECT
+//
+// # Returns
+// - A list of struct `SelectBridge`, the oldest first
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectBridges(con *sql.DB) ([]SelectBridge, error) {
+	var bridgeList []SelectBridge
+
+	rows, err := con.Query(`
+		SELECT ID, Name, SourceIp, SourcePort, SourceClientId, SourceUsername, SourcePassword, TopicFilters, DestinationIp, DestinationPort, DestinationClientId, DestinationUsername, DestinationPassword, Rewrites, QoS, RetainMode, Enabled, CreationDate
+		FROM Bridge
+		ORDER BY ID
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bridge SelectBridge
+		var topicFilters string
+		var rewrites string
+		rows.Scan(&bridge.Id, &bridge.Name, &bridge.SourceIp, &bridge.SourcePort, &bridge.SourceClientId, &bridge.SourceUsername, &bridge.SourcePassword, &topicFilters, &bridge.DestinationIp, &bridge.DestinationPort, &bridge.DestinationClientId, &bridge.DestinationUsername, &bridge.DestinationPassword, &rewrites, &bridge.QoS, &bridge.RetainMode, &bridge.Enabled, &bridge.CreationDate)
+		json.Unmarshal([]byte(topicFilters), &bridge.TopicFilters)
+		json.Unmarshal([]byte(rewrites), &bridge.Rewrites)
+		bridgeList = append(bridgeList, bridge)
+	}
+
+	return bridgeList, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB  : It's a connection to the database.
+// - id int       : [Bridge].[ID]
+// - enabled bool : Whether the bridge runs, also after a restart of the server.
+//
+// # Tables Affected
+// - Bridge
+//   - UPDATE
+//
+// # Returns
+// - bool: false if there was no such bridge
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func UpdateBridgeEnabled(con *sql.DB, id int, enabled bool) (bool, error) {
+	result, err := con.Exec("UPDATE Bridge SET Enabled = ? WHERE ID = ?", enabled, id)
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+// - id int      : [Bridge].[ID]
+//
+// # Tables Affected
+// - Bridge
+//   - DELETE
+//
+// # Returns
+// - bool: false if there was no such bridge
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteBridge(con *sql.DB, id int) (bool, error) {
+	result, err := con.Exec("DELETE FROM Bridge WHERE ID = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
@@ -0,0 +1,186 @@
+package database
+
+import (
+	"fmt"
+	"sync"
+)
+
+// # Author
+// - Polariusz
+type topicCacheKey struct {
+	brokerId int
+	topic string
+}
+
+// # Author
+// - Polariusz
+type userCacheKey struct {
+	brokerId int
+	clientId string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The User row as it is remembered by the `IdCache`.
+//
+// # Author
+// - Polariusz
+type CachedUser struct {
+	Id int
+	Outsider bool
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The structure remembers the IDs of the Topic and User rows so that the ingest path does not have to query the database for every received message.
+// - IDs of rows never change, so entries only need to be forgotten when rows are deleted.
+//
+// # Used in
+// - createMessageHandler()
+//
+// # Author
+// - Polariusz
+type IdCache struct {
+	mutex sync.RWMutex
+	topics map[topicCacheKey]int
+	users map[userCacheKey]CachedUser
+}
+
+// # Author
+// - Polariusz
+func NewIdCache() *IdCache {
+	return &IdCache{
+		topics: make(map[topicCacheKey]int),
+		users: make(map[userCacheKey]CachedUser),
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - store Store  : The store that is asked when the ID is not cached yet.
+// - brokerId int : [Broker].[ID]
+// - topic string : [Topic].[Topic]
+//
+// # Description
+// - The function shall return the ID of the Topic row matched to `brokerId` and `topic`.
+// - If the cache does not know it, the Topic is inserted if it isn't in the database yet, which happens for topics received through wildcard subscriptions.
+//
+// # Tables Affected
+// - Topic
+//   - INSERT
+//   - SELECT
+//
+// # Returns
+// - int: [Topic].[ID], -1 on error
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func (cache *IdCache) ResolveTopicId(store Store, brokerId int, topic string) (int, error) {
+	key := topicCacheKey{brokerId, topic}
+
+	cache.mutex.RLock()
+	topicId, ok := cache.topics[key]
+	cache.mutex.RUnlock()
+	if ok {
+		return topicId, nil
+	}
+
+	topicId, err := store.SelectTopicIdByBrokerIdAndTopic(brokerId, topic)
+	if err != nil {
+		return -1, err
+	}
+	if topicId == -1 {
+		topicId, err = store.InsertNewTopic(InsertTopic{BrokerId: brokerId, Topic: topic})
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	cache.mutex.Lock()
+	cache.topics[key] = topicId
+	cache.mutex.Unlock()
+
+	return topicId, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - store Store     : The store that is asked when the User is not cached yet.
+// - brokerId int    : [Broker].[ID]
+// - clientId string : [User].[ClientId]
+//
+// # Description
+// - The function shall return the User row matched to `brokerId` and `clientId`.
+// - If there is no such User, it is inserted as an Outsider.
+//
+// # Tables Affected
+// - User
+//   - INSERT
+//   - SELECT
+//
+// # Returns
+// - CachedUser with the ID of the User row
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func (cache *IdCache) ResolveUser(store Store, brokerId int, clientId string) (CachedUser, error) {
+	key := userCacheKey{brokerId, clientId}
+
+	cache.mutex.RLock()
+	user, ok := cache.users[key]
+	cache.mutex.RUnlock()
+	if ok {
+		return user, nil
+	}
+
+	selectUser, err := store.SelectUserByClientIdAndBrokerId(clientId, brokerId)
+	if err == nil {
+		user = CachedUser{selectUser.Id, selectUser.Outsider}
+	} else {
+		// No user found! Outsider!
+		outsiderUserId, err := store.InsertNewUser(InsertUser{BrokerId: brokerId, ClientId: clientId, Username: "", Password: "", Outsider: true})
+		if err != nil {
+			return user, fmt.Errorf("Error while inserting outsider.\nErr: %s\n", err)
+		}
+		user = CachedUser{outsiderUserId, true}
+	}
+
+	cache.mutex.Lock()
+	cache.users[key] = user
+	cache.mutex.Unlock()
+
+	return user, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall forget all remembered IDs. It must be called after rows of table Topic or User were deleted.
+//
+// # Author
+// - Polariusz
+func (cache *IdCache) Clear() {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	cache.topics = make(map[topicCacheKey]int)
+	cache.users = make(map[userCacheKey]CachedUser)
+}
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const benchmarkTopics = 10000
+
+// setupBenchmarkDatabase creates a migrated database with one broker, one user
+// and `benchmarkTopics` topics, and returns it with the broker ID.
+func setupBenchmarkDatabase(b *testing.B) (*sql.DB, int) {
+	b.Helper()
+
+	con, err := sql.Open("sqlite3", filepath.Join(b.TempDir(), "bench.db"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { con.Close() })
+
+	if err := SetupDatabase(con); err != nil {
+		b.Fatal(err)
+	}
+
+	brokerId, err := InsertNewBroker(con, InsertBroker{Ip: "localhost", Port: 1883})
+	if err != nil {
+		b.Fatal(err)
+	}
+	if _, err := InsertNewUser(con, InsertUser{BrokerId: brokerId, ClientId: "bench", Username: "", Password: "", Outsider: false}); err != nil {
+		b.Fatal(err)
+	}
+
+	tx, err := con.Begin()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < benchmarkTopics; i++ {
+		if _, err := tx.Exec("INSERT INTO Topic(BrokerId, Topic, CreationDate) VALUES(?, ?, ?)", brokerId, benchmarkTopic(i), time.Now()); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		b.Fatal(err)
+	}
+
+	return con, brokerId
+}
+
+func benchmarkTopic(i int) string {
+	return fmt.Sprintf("plant/line-%d/sensor-%d", i%100, i)
+}
+
+// BenchmarkIngestScan10kTopics measures the ingest path as it was before the
+// indexes and the cache: load all topics of the broker, loop over them, then
+// select the user.
+func BenchmarkIngestScan10kTopics(b *testing.B) {
+	con, brokerId := setupBenchmarkDatabase(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		topic := benchmarkTopic(i % benchmarkTopics)
+
+		topicList, err := SelectTopicsByBrokerId(con, brokerId)
+		if err != nil {
+			b.Fatal(err)
+		}
+		topicId := -1
+		for _, dbTopic := range topicList {
+			if dbTopic.Topic == topic {
+				topicId = dbTopic.Id
+			}
+		}
+		user, err := SelectUserByClientIdAndBrokerId(con, "bench", brokerId)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := InsertNewMessage(con, InsertMessage{UserId: user.Id, TopicId: topicId, BrokerId: brokerId, QoS: 0, Message: "21.5"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "msg/s")
+}
+
+// BenchmarkIngestIndexed10kTopics measures the ingest path with the indexed
+// lookups, but without the cache.
+func BenchmarkIngestIndexed10kTopics(b *testing.B) {
+	con, brokerId := setupBenchmarkDatabase(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		topicId, err := SelectTopicIdByBrokerIdAndTopic(con, brokerId, benchmarkTopic(i%benchmarkTopics))
+		if err != nil {
+			b.Fatal(err)
+		}
+		user, err := SelectUserByClientIdAndBrokerId(con, "bench", brokerId)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := InsertNewMessage(con, InsertMessage{UserId: user.Id, TopicId: topicId, BrokerId: brokerId, QoS: 0, Message: "21.5"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "msg/s")
+}
+
+// BenchmarkIngestCached10kTopics measures the ingest path as it is used by
+// createMessageHandler: IDs come from the IdCache.
+func BenchmarkIngestCached10kTopics(b *testing.B) {
+	con, brokerId := setupBenchmarkDatabase(b)
+	store := NewSqliteStore(con)
+	cache := NewIdCache()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		topicId, err := cache.ResolveTopicId(store, brokerId, benchmarkTopic(i%benchmarkTopics))
+		if err != nil {
+			b.Fatal(err)
+		}
+		user, err := cache.ResolveUser(store, brokerId, "bench")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := InsertNewMessage(con, InsertMessage{UserId: user.Id, TopicId: topicId, BrokerId: brokerId, QoS: 0, Message: "21.5"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "msg/s")
+}
+
+// BenchmarkResolveCached10kTopics measures only the cached topic and user
+// resolution, without the message insertion.
+func BenchmarkResolveCached10kTopics(b *testing.B) {
+	con, brokerId := setupBenchmarkDatabase(b)
+	store := NewSqliteStore(con)
+	cache := NewIdCache()
+	for i := 0; i < benchmarkTopics; i++ {
+		if _, err := cache.ResolveTopicId(store, brokerId, benchmarkTopic(i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := cache.ResolveTopicId(store, brokerId, benchmarkTopic(i%benchmarkTopics)); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := cache.ResolveUser(store, brokerId, "bench"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
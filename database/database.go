@@ -8,106 +8,60 @@ import (
 	"strconv"
 )
 
-const databaseName string = "mqtt-client-database.db"
+const DefaultDatabasePath string = "mqtt-client-database.db"
+const InMemoryDatabase string = ":memory:"
 const LIMIT_MESSAGES int = 500
 
-// | Date of change | By        | Comment |
-// +----------------+-----------+---------+
-// |                | Polariusz | Created |
+// | Date of change | By        | Comment                                  |
+// +----------------+-----------+------------------------------------------+
+// |                | Polariusz | Created                                  |
+// | 2026-10-19     | Polariusz | The path is an argument, added in-memory |
+//
+// # Arguments
+// - path string : Path to the database file, or `InMemoryDatabase`.
 //
 // # Description
-// -  It opens a connection to a file `databaseName`. If it doesn't exist, it will be created.
+// -  It opens a connection to the file `path`. If it doesn't exist, it will be created.
+// -  If `path` is `InMemoryDatabase`, the database only lives in memory and is lost when the connection is closed.
+//    - Every connection to ":memory:" opens its own empty database, so the pool is limited to a single connection that is never closed.
+//
 // # Returns
 // - DBcon to the database if everything went okay.
 //
 // # Author
 // - Polariusz
-func OpenDatabase() (*sql.DB, error) {
-	con, err := sql.Open("sqlite3", databaseName)
+func OpenDatabase(path string) (*sql.DB, error) {
+	con, err := sql.Open("sqlite3", path)
 	if err != nil {
 		return nil, fmt.Errorf("Error while opening database, perhaps there is a permission issue?\nErr: '%s'\n", err)
 	}
+
+	if path == InMemoryDatabase {
+		con.SetMaxOpenConns(1)
+		con.SetMaxIdleConns(1)
+		con.SetConnMaxLifetime(0)
+		con.SetConnMaxIdleTime(0)
+	}
+
 	return con, nil
 }
 
-// | Date of change | By        | Comment                   |
-// +----------------+-----------+---------------------------+
-// | 2025-05-21     | Q-uock    | Created                   |
-// | 2025-06-06     | Polariusz | Added UserTopicSubscribed |
+// | Date of change | By        | Comment                               |
+// +----------------+-----------+---------------------------------------+
+// | 2025-05-21     | Q-uock    | Created                               |
+// | 2025-06-06     | Polariusz | Added UserTopicSubscribed             |
+// | 2026-10-19     | Polariusz | The tables are now created by Migrate |
 //
 // # Description
 // - Creates tables in the connected to database connection.
+// - The tables are created and altered by the migrations in `migrationList`, see `Migrate()`.
+// - Databases created before the migrations existed are baselined first.
 //
 // # Author
 // - Q-uock
 func SetupDatabase(con *sql.DB) error {
-	tables := []string{
-		`CREATE TABLE IF NOT EXISTS Broker (
-			ID INTEGER PRIMARY KEY AUTOINCREMENT,
-			Ip TEXT NOT NULL,
-			Port INTEGER NOT NULL,
-			CreationDate DATETIME NOT NULL
-		);`,
-
-		`CREATE TABLE IF NOT EXISTS User (
-			ID INTEGER PRIMARY KEY AUTOINCREMENT,
-			BrokerId INTEGER NOT NULL,
-			ClientId TEXT NOT NULL,
-			Username TEXT NOT NULL,
-			Password TEXT,
-			Outsider BOOLEAN,
-			CreationDate DATETIME NOT NULL,
-			FOREIGN KEY(BrokerId) REFERENCES Broker(ID)
-		);`,
-
-		`CREATE TABLE IF NOT EXISTS Message (
-			ID INTEGER PRIMARY KEY AUTOINCREMENT,
-			UserId INTEGER NOT NULL,
-			TopicId INTEGER NOT NULL,
-			BrokerId INTEGER NOT NULL,
-			QoS TINYINT,
-			Message TEXT,
-			CreationDate DATETIME,
-			FOREIGN KEY(UserId) REFERENCES User(ID),
-			FOREIGN KEY(TopicId) REFERENCES Topic(ID),
-			FOREIGN KEY(BrokerId) REFERENCES Broker(ID)
-		);`,
-
-		`CREATE TABLE IF NOT EXISTS Topic (
-			ID INTEGER PRIMARY KEY AUTOINCREMENT,
-			BrokerId INTEGER NOT NULL,
-			Topic TEXT NOT NULL,
-			CreationDate DATETIME,
-			FOREIGN KEY(BrokerId) REFERENCES Broker(ID)
-		);`,
-
-		`CREATE TABLE IF NOT EXISTS UserTopicSubscribed (
-			ID INTEGER PRIMARY KEY AUTOINCREMENT,
-			BrokerId INTEGER NOT NULL,
-			UserId INTEGER NOT NULL,
-			TopicId INTEGER NOT NULL,
-			CreationDate DATETIME,
-			FOREIGN KEY(BrokerId) REFERENCES Broker(ID),
-			FOREIGN KEY(UserId) REFERENCES User(ID),
-			FOREIGN KEY(TopicId) REFERENCES Topic(ID)
-		);`,
-
-		`CREATE TABLE IF NOT EXISTS UserTopicFavourite (
-			ID INTEGER PRIMARY KEY AUTOINCREMENT,
-			BrokerId INTEGER NOT NULL,
-			UserId INTEGER NOT NULL,
-			TopicId INTEGER NOT NULL,
-			CreationDate DATETIME,
-			FOREIGN KEY(BrokerId) REFERENCES Broker(ID),
-			FOREIGN KEY(UserId) REFERENCES User(ID),
-			FOREIGN KEY(TopicId) REFERENCES Topic(ID)
-		);`,
-	}
-
-	for _, table := range tables {
-		if _, err := con.Exec(table); err != nil {
-			return fmt.Errorf("TABLE:\n%s\nSkill issues\nErr: %s\n", table, err)
-		}
+	if _, err := Migrate(con, false); err != nil {
+		return fmt.Errorf("Error while migrating the database!\nErr: %s\n", err)
 	}
 
 	return nil
@@ -688,6 +642,46 @@ func SelectTopicsByBrokerId(con *sql.DB, brokerId int) ([]SelectTopic, error) {
 	return topicList, nil
 }
 
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB  : It's a connection to the database.
+// - brokerId int : Unique Identifier of table `Broker.ID`
+// - topic string : [Topic].[Topic]
+//
+// # Description
+// - The function shall return the ID of the Topic matched with arguments `brokerId` and `topic`.
+// - The lookup uses the index IX_Topic_BrokerId_Topic, so it doesn't need to load all topics of the broker.
+//
+// # Tables Affected
+// - Topic
+//   - SELECT
+//
+// # Returns
+// - int: [Topic].[ID], or -1 if the topic is not known
+// - error when:
+//   - Skill Issues
+//   - Table Topic does not exists
+//     - Use the `SetupDatabase()` function to set the database up before calling this function.
+//
+// # Author
+// - Polariusz
+func SelectTopicIdByBrokerIdAndTopic(con *sql.DB, brokerId int, topic string) (int, error) {
+	var topicId int
+
+	err := con.QueryRow("SELECT ID FROM Topic WHERE BrokerId = ? AND Topic = ? LIMIT 1", brokerId, topic).Scan(&topicId)
+	if err == sql.ErrNoRows {
+		return -1, nil
+	}
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return topicId, nil
+}
+
 // | Date of change | By        | Comment |
 // +----------------+-----------+---------+
 // | 2025-05-29     | Polariusz | Created |
@@ -1051,24 +1045,42 @@ func DeleteTopic(con *sql.DB, topicId int) error {
 /* --------------------------------------| MESSAGE |-------------------------------------- */
 /*                                       +---------+                                       */
 
-// | Date of change | By        | Comment |
-// +----------------+-----------+---------+
-// | 2025-05-29     | Polariusz | Created |
+// | Date of change | By        | Comment                 |
+// +----------------+-----------+-------------------------+
+// | 2025-05-29     | Polariusz | Created                 |
+// | 2026-10-19     | Polariusz | Added CreationDate      |
+// | 2026-10-19     | Polariusz | Added the MQTT metadata |
+// | 2026-10-19     | Polariusz | Added IsDuplicate       |
 //
 // # Struct to Table Message
 //
-// | Struct InsertMessage   | Table Message         |
-// +------------------------+-----------------------+
-// |                        | ID INTEGER            |
-// | UserId int             | UserId INTEGER        |
-// | TopicId int            | TopicId INTEGER       |
-// | BrokerId int           | BrokerId INTEGER      |
-// | QoS byte               | QoS TINYINT           |
-// | Message string         | Message TEXT          |
-// |                        | CreationDate DateTime |
+// | Struct InsertMessage   | Table Message             |
+// +------------------------+---------------------------+
+// |                        | ID INTEGER                |
+// | UserId int             | UserId INTEGER            |
+// | TopicId int            | TopicId INTEGER           |
+// | BrokerId int           | BrokerId INTEGER          |
+// | QoS byte               | QoS TINYINT               |
+// | Message string         | Message TEXT              |
+// | CreationDate time.Time | CreationDate DateTime     |
+// | Retained bool          | Retained BOOLEAN          |
+// | Duplicate bool         | Duplicate BOOLEAN         |
+// | PacketId int           | PacketId INTEGER          |
+// | PayloadSize int        | PayloadSize INTEGER       |
+// | ReceivedAt time.Time   | ReceivedNanos INTEGER     |
+// | EnvelopeDate time.Time | EnvelopeDate DATETIME     |
+// | IsDuplicate bool       | IsDuplicate BOOLEAN       |
+//
+// # Note
+// - If CreationDate is zero, the current date is used.
+//   - Set it when the message is written later than it was received, for example by the batched ingest queue.
+// - ReceivedAt is the moment the broker's PUBLISH was received, it is stored as nanoseconds since the Unix epoch. If it is zero, CreationDate is used.
+// - EnvelopeDate is a timestamp that the publisher put into the payload. If it is zero, NULL is stored.
+// - Duplicate is the DUP flag of the PUBLISH packet, IsDuplicate is set when the server has recognised the message as a copy of an earlier one, see DedupRule.
 //
 // # Used in
 // - InsertNewMessage()
+// - InsertNewMessages()
 //
 // # Author
 // - Polariusz
@@ -1078,6 +1090,51 @@ type InsertMessage struct {
 	BrokerId int
 	QoS byte
 	Message string
+	CreationDate time.Time
+	Retained bool
+	Duplicate bool
+	PacketId int
+	PayloadSize int
+	ReceivedAt time.Time
+	EnvelopeDate time.Time
+	IsDuplicate bool
+}
+
+// # Author
+// - Polariusz
+func (message InsertMessage) creationDate() time.Time {
+	if message.CreationDate.IsZero() {
+		return time.Now()
+	}
+	return message.CreationDate
+}
+
+// # Author
+// - Polariusz
+func (message InsertMessage) receivedNanos(creationDate time.Time) int64 {
+	if message.ReceivedAt.IsZero() {
+		return creationDate.UnixNano()
+	}
+	return message.ReceivedAt.UnixNano()
+}
+
+// # Author
+// - Polariusz
+func (message InsertMessage) envelopeDate() any {
+	if message.EnvelopeDate.IsZero() {
+		return nil
+	}
+	return message.EnvelopeDate
+}
+
+// # Description
+// - The function shall return the arguments for the INSERT statement of InsertNewMessage() and InsertNewMessages().
+//
+// # Author
+// - Polariusz
+func (message InsertMessage) insertArgs() []any {
+	creationDate := message.creationDate()
+	return []any{message.UserId, message.TopicId, message.BrokerId, message.QoS, message.Message, creationDate, message.Retained, message.Duplicate, message.PacketId, message.PayloadSize, message.receivedNanos(creationDate), message.envelopeDate(), message.IsDuplicate}
 }
 
 // | Date of change | By        | Comment |
@@ -1106,38 +1163,105 @@ type InsertMessage struct {
 // - Polariusz
 func InsertNewMessage(con *sql.DB, message InsertMessage) error {
 	stmt, err := con.Prepare(`
-		INSERT INTO Message(UserId, TopicId, BrokerId, QoS, Message, CreationDate)
-		VALUES(?, ?, ?, ?, ?, ?)
+		INSERT INTO Message(UserId, TopicId, BrokerId, QoS, Message, CreationDate, Retained, Duplicate, PacketId, PayloadSize, ReceivedNanos, EnvelopeDate, IsDuplicate)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return fmt.Errorf("Skill issues\nErr: %s\n", err)
 	}
 	defer stmt.Close()
 
-	if _, err := stmt.Exec(message.UserId, message.TopicId, message.BrokerId, message.QoS, message.Message, time.Now()); err != nil {
+	if _, err := stmt.Exec(message.insertArgs()...); err != nil {
 		return fmt.Errorf("Skill issues\nErr: %s\n", err)
 	}
 
 	return nil
 }
 
-// | Date of change | By        | Comment        |
-// +----------------+-----------+----------------+
-// | 2025-05-29     | Polariusz | Created        |
-// | 2025-06-06     | Polariusz | Added ClientId |
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB                 : It's a connection to the database that is used here to insert stuff in.
+// - messageList []InsertMessage : The structs that will be written into table `Message`.
+//
+// # Description
+// - The function shall insert all messages from the argument `messageList` in one transaction.
+// - Either all messages are inserted or none.
+//
+// # Tables Affected
+// - Message
+//   - INSERT
+//
+// # Returns
+// - error when:
+//   - Skill Issues
+//   - Table Message does not exist
+//     - Run SetupDatabase() before this function.
+//   - Foreign Key issues
+//
+// # Author
+// - Polariusz
+func InsertNewMessages(con *sql.DB, messageList []InsertMessage) error {
+	tx, err := con.Begin()
+	if err != nil {
+		return fmt.Errorf("Error while beginning the transaction!\nErr: %s\n", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO Message(UserId, TopicId, BrokerId, QoS, Message, CreationDate, Retained, Duplicate, PacketId, PayloadSize, ReceivedNanos, EnvelopeDate, IsDuplicate)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer stmt.Close()
+
+	for _, message := range messageList {
+		if _, err := stmt.Exec(message.insertArgs()...); err != nil {
+			return fmt.Errorf("Skill issues\nErr: %s\n", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("Error while committing the transaction!\nErr: %s\n", err)
+	}
+
+	return nil
+}
+
+// | Date of change | By        | Comment                 |
+// +----------------+-----------+-------------------------+
+// | 2025-05-29     | Polariusz | Created                 |
+// | 2025-06-06     | Polariusz | Added ClientId          |
+// | 2026-10-19     | Polariusz | Added the MQTT metadata |
+// | 2026-10-19     | Polariusz | Added IsDuplicate       |
 //
 // # Struct to Table Message
 //
-// | Struct SelectMessage   | Table Message         | Table User    |
-// +------------------------+-----------------------+---------------+
-// | Id int                 | ID INTEGER            |               |
-// | UserId int             | UserId INTEGER        | ID INTEGER    |
-// | ClientId string        |                       | ClientId TEXT |
-// | TopicId int            | TopicId INTEGER       |               |
-// | BrokerId int           | BrokerId INTEGER      |               |
-// | QoS int                | QoS TINYINT           |               |
-// | Message string         | Message TEXT          |               |
-// | CreationDate time.Time | CreationDate DateTime |               |
+// | Struct SelectMessage     | Table Message         | Table User    |
+// +--------------------------+-----------------------+---------------+
+// | Id int                   | ID INTEGER            |               |
+// | UserId int               | UserId INTEGER        | ID INTEGER    |
+// | ClientId string          |                       | ClientId TEXT |
+// | TopicId int              | TopicId INTEGER       |               |
+// | BrokerId int             | BrokerId INTEGER      |               |
+// | QoS int                  | QoS TINYINT           |               |
+// | Message string           | Message TEXT          |               |
+// | CreationDate time.Time   | CreationDate DateTime |               |
+// | Retained bool            | Retained BOOLEAN      |               |
+// | Duplicate bool           | Duplicate BOOLEAN     |               |
+// | PacketId int             | PacketId INTEGER      |               |
+// | PayloadSize int          | PayloadSize INTEGER   |               |
+// | ReceivedAt time.Time     | ReceivedNanos INTEGER |               |
+// | EnvelopeDate *time.Time  | EnvelopeDate DATETIME |               |
+// | IsDuplicate bool         | IsDuplicate BOOLEAN   |               |
+//
+// # Note
+// - EnvelopeDate is nil if the publisher did not put a timestamp into the payload.
+// - Messages stored before the metadata was recorded have their CreationDate as ReceivedAt, to the millisecond, and everything else empty.
 //
 // # Used in
 // - SelectMessagesByTopicIdAndBrokerId()
@@ -1154,17 +1278,54 @@ type SelectMessage struct {
 	QoS int
 	Message string
 	CreationDate time.Time
+	Retained bool
+	Duplicate bool
+	PacketId int
+	PayloadSize int
+	ReceivedAt time.Time
+	EnvelopeDate *time.Time
+	IsDuplicate bool
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The columns of table Message that every query for `SelectMessage` selects after m.CreationDate, in the order of scanSelectMessage().
+//
+// # Author
+// - Polariusz
+const selectMessageMetadataColumns = "m.Retained, m.Duplicate, m.PacketId, m.PayloadSize, m.ReceivedNanos, m.EnvelopeDate, m.IsDuplicate"
+
+// # Description
+// - The function shall scan a row of a query for `SelectMessage`.
+//
+// # Author
+// - Polariusz
+func scanSelectMessage(rows *sql.Rows) SelectMessage {
+	var selectMessage SelectMessage
+	var receivedNanos int64
+	var envelopeDate sql.NullTime
+	rows.Scan(&selectMessage.Id, &selectMessage.UserId, &selectMessage.ClientId, &selectMessage.TopicId, &selectMessage.BrokerId, &selectMessage.QoS, &selectMessage.Message, &selectMessage.CreationDate, &selectMessage.Retained, &selectMessage.Duplicate, &selectMessage.PacketId, &selectMessage.PayloadSize, &receivedNanos, &envelopeDate, &selectMessage.IsDuplicate)
+	selectMessage.ReceivedAt = time.Unix(0, receivedNanos)
+	if envelopeDate.Valid {
+		selectMessage.EnvelopeDate = &envelopeDate.Time
+	}
+	return selectMessage
 }
 
 // | Date of change | By        | Comment                         |
 // +----------------+-----------+---------------------------------+
 // | 2025-05-29     | Polariusz | Created                         |
 // | 2025-05-30     | Polariusz | Fixed references in rows.Scan() |
+// | 2026-10-19     | Polariusz | Added hideDuplicates            |
 //
 // # Arguments
-// - con *sql.DB  : It's a connection to the database.
-// - topicId int  : Unique Identifier of table Topic
-// - brokerId int : Unique Identifier of table Broker
+// - con *sql.DB         : It's a connection to the database.
+// - topicId int         : Unique Identifier of table Topic
+// - brokerId int        : Unique Identifier of table Broker
+// - hideDuplicates bool : Leaves out the messages that are marked as duplicates
 //
 // # Description
 // - Selects a list of messages from table Message matched to arguments `topicId` for messages in a Topic and `brokerId` for messages in a broker.
@@ -1183,11 +1344,11 @@ type SelectMessage struct {
 //
 // # Author
 // - Polariusz
-func SelectMessagesByTopicIdAndBrokerId(con *sql.DB, topicId int, brokerId int) ([]SelectMessage, error) {
+func SelectMessagesByTopicIdAndBrokerId(con *sql.DB, topicId int, brokerId int, hideDuplicates bool) ([]SelectMessage, error) {
 	var selectMessageList []SelectMessage
 
 	stmt, err := con.Prepare(`
-		SELECT m.ID, u.ID, u.ClientId, m.TopicId, m.BrokerId, m.QoS, m.Message, m.CreationDate
+		SELECT m.ID, u.ID, u.ClientId, m.TopicId, m.BrokerId, m.QoS, m.Message, m.CreationDate, `+selectMessageMetadataColumns+`
 		FROM Message m
 		INNER JOIN User u
 		ON u.ID = m.UserId
@@ -1195,6 +1356,8 @@ func SelectMessagesByTopicIdAndBrokerId(con *sql.DB, topicId int, brokerId int)
 			m.TopicId = ?
 		AND
 			m.BrokerId = ?
+		AND
+			(? = 0 OR m.IsDuplicate = 0)
 		ORDER BY m.CreationDate DESC
 	`)
 	if err != nil {
@@ -1202,16 +1365,14 @@ func SelectMessagesByTopicIdAndBrokerId(con *sql.DB, topicId int, brokerId int)
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(topicId, brokerId)
+	rows, err := stmt.Query(topicId, brokerId, hideDuplicates)
 	if err != nil {
 		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var selectMessage SelectMessage
-		rows.Scan(&selectMessage.Id, &selectMessage.UserId, &selectMessage.ClientId, &selectMessage.TopicId, &selectMessage.BrokerId, &selectMessage.QoS, &selectMessage.Message, &selectMessage.CreationDate)
-		selectMessageList = append(selectMessageList, selectMessage)
+		selectMessageList = append(selectMessageList, scanSelectMessage(rows))
 	}
 
 	return selectMessageList, nil
@@ -1222,16 +1383,19 @@ func SelectMessagesByTopicIdAndBrokerId(con *sql.DB, topicId int, brokerId int)
 // | 2025-05-29     | Polariusz | Created                                                                                    |
 // | 2025-05-30     | Polariusz | Fixed references in rows.Scan() and changed the statement to use the ROW_NUMBER() function |
 // | 2025-06-02     | Polariusz | added missing arguments under the description documentation of the function                |
+// | 2026-10-19     | Polariusz | Added hideDuplicates                                                                       |
 //
 // # Arguments
-// - con *sql.DB  : It's a connection to the database.
-// - topicId int  : Unique Identifier of table Topic
-// - brokerId int : Unique Identifier of table Broker
-// - index int    : Select from `LIMIT_MESSAGES*index` to `LIMIT_MESSAGES*(1+index)` messages.
+// - con *sql.DB         : It's a connection to the database.
+// - topicId int         : Unique Identifier of table Topic
+// - brokerId int        : Unique Identifier of table Broker
+// - index int           : Select from `LIMIT_MESSAGES*index` to `LIMIT_MESSAGES*(1+index)` messages.
+// - hideDuplicates bool : Leaves out the messages that are marked as duplicates
 //
 // # Description
 // - The function shall select matched to arguments `topicId` for matching to Topic, `brokerId` for matching to Broker and `index` for limiting messages Messages from table `Message` by a connected to `con` Database.
 // - It selects up to `LIMIT_MESSAGES` Messages
+// - The duplicates are left out before the rows are counted, so a page is only short if it is the last one.
 //
 // # Tables Affected
 // - Message
@@ -1246,12 +1410,12 @@ func SelectMessagesByTopicIdAndBrokerId(con *sql.DB, topicId int, brokerId int)
 //
 // # Author
 // - Polariusz
-func SelectMessagesByTopicIdBrokerIdAndIndex(con *sql.DB, topicId int, brokerId int, index int) ([]SelectMessage, error) {
+func SelectMessagesByTopicIdBrokerIdAndIndex(con *sql.DB, topicId int, brokerId int, index int, hideDuplicates bool) ([]SelectMessage, error) {
 	var selectMessageList []SelectMessage
 	stmtStr := `
-		SELECT ID, UserId, ClientId, TopicId, BrokerId, QoS, Message, CreationDate
+		SELECT ID, UserId, ClientId, TopicId, BrokerId, QoS, Message, CreationDate, Retained, Duplicate, PacketId, PayloadSize, ReceivedNanos, EnvelopeDate, IsDuplicate
 		FROM (
-			SELECT ROW_NUMBER() OVER(ORDER BY m.ID) RowCnt, m.ID, m.UserId, u.ClientId, m.TopicId, m.BrokerId, m.QoS, m.Message, m.CreationDate
+			SELECT ROW_NUMBER() OVER(ORDER BY m.ID) RowCnt, m.ID, m.UserId, u.ClientId, m.TopicId, m.BrokerId, m.QoS, m.Message, m.CreationDate, `+selectMessageMetadataColumns+`
 			FROM Message m
 			LEFT JOIN User u
 			  ON u.ID = m.UserId
@@ -1259,6 +1423,8 @@ func SelectMessagesByTopicIdBrokerIdAndIndex(con *sql.DB, topicId int, brokerId
 				m.TopicId = ?
 			AND
 				m.BrokerId = ?
+			AND
+				(? = 0 OR m.IsDuplicate = 0)
 			ORDER BY m.CreationDate DESC
 		) MsgWithCnt
 		WHERE
@@ -1273,30 +1439,30 @@ func SelectMessagesByTopicIdBrokerIdAndIndex(con *sql.DB, topicId int, brokerId
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(topicId, brokerId, index, LIMIT_MESSAGES, index, LIMIT_MESSAGES)
+	rows, err := stmt.Query(topicId, brokerId, hideDuplicates, index, LIMIT_MESSAGES, index, LIMIT_MESSAGES)
 	if err != nil {
 		return nil, fmt.Errorf("Error while querying the statement!\nStatement:\n%s\nErr: %s\n", stmtStr, err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var selectMessage SelectMessage
-		rows.Scan(&selectMessage.Id, &selectMessage.UserId, &selectMessage.ClientId, &selectMessage.TopicId, &selectMessage.BrokerId, &selectMessage.QoS, &selectMessage.Message, &selectMessage.CreationDate)
-		selectMessageList = append(selectMessageList, selectMessage)
+		selectMessageList = append(selectMessageList, scanSelectMessage(rows))
 	}
 
 	return selectMessageList, nil
 }
 
-// | Date of change | By        | Comment |
-// +----------------+-----------+---------+
-// | 2025-06-07     | Polariusz | Created |
+// | Date of change | By        | Comment              |
+// +----------------+-----------+----------------------+
+// | 2025-06-07     | Polariusz | Created              |
+// | 2026-10-19     | Polariusz | Added hideDuplicates |
 //
 // # Arguments
-// - con *sql.DB        : It's a connection to the database
-// - brokerId int       : Unique Identifier of table Broker
-// - topicId int        : Unique Identifier of table Topic
-// - timeFrom time.Time : Used to query rows that come past this time
+// - con *sql.DB         : It's a connection to the database
+// - brokerId int        : Unique Identifier of table Broker
+// - topicId int         : Unique Identifier of table Topic
+// - timeFrom time.Time  : Used to query rows that come past this time
+// - hideDuplicates bool : Leaves out the messages that are marked as duplicates
 //
 // # Description
 // - Selects a list of messages from table Message matched to arguments `topicId` for messages in a Topic and `brokerId` for messages in a broker.
@@ -1315,11 +1481,11 @@ func SelectMessagesByTopicIdBrokerIdAndIndex(con *sql.DB, topicId int, brokerId
 //
 // # Author
 // - Polariusz
-func SelectMessagesByBrokerIdTopicIdAndDatetime(con *sql.DB, brokerId int, topicId int, timeFrom time.Time) ([]SelectMessage, error) {
+func SelectMessagesByBrokerIdTopicIdAndDatetime(con *sql.DB, brokerId int, topicId int, timeFrom time.Time, hideDuplicates bool) ([]SelectMessage, error) {
 	var selectMessageList []SelectMessage
 
 	stmt, err := con.Prepare(`
-		SELECT m.ID, u.ID, u.ClientId, m.TopicId, m.BrokerId, m.QoS, m.Message, m.CreationDate
+		SELECT m.ID, u.ID, u.ClientId, m.TopicId, m.BrokerId, m.QoS, m.Message, m.CreationDate, `+selectMessageMetadataColumns+`
 		FROM Message m
 		INNER JOIN User u
 		ON u.ID = m.UserId
@@ -1329,6 +1495,8 @@ func SelectMessagesByBrokerIdTopicIdAndDatetime(con *sql.DB, brokerId int, topic
 			m.TopicId = ?
 		AND
 			m.CreationDate > ?
+		AND
+			(? = 0 OR m.IsDuplicate = 0)
 		ORDER BY m.CreationDate DESC
 	`)
 	if err != nil {
@@ -1336,15 +1504,115 @@ func SelectMessagesByBrokerIdTopicIdAndDatetime(con *sql.DB, brokerId int, topic
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(brokerId, topicId, timeFrom)
+	rows, err := stmt.Query(brokerId, topicId, timeFrom, hideDuplicates)
 	if err != nil {
 		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var selectMessage SelectMessage
-		rows.Scan(&selectMessage.Id, &selectMessage.UserId, &selectMessage.ClientId, &selectMessage.TopicId, &selectMessage.BrokerId, &selectMessage.QoS, &selectMessage.Message, &selectMessage.CreationDate)
+		selectMessageList = append(selectMessageList, scanSelectMessage(rows))
+	}
+
+	return selectMessageList, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Message
+//
+// | Struct SelectTopicMessage | Table Message         | Table User    | Table Topic |
+// +---------------------------+-----------------------+---------------+-------------+
+// | Id int                    | ID INTEGER            |               |             |
+// | UserId int                | UserId INTEGER        | ID INTEGER    |             |
+// | ClientId string           |                       | ClientId TEXT |             |
+// | TopicId int               | TopicId INTEGER       |               | ID INTEGER  |
+// | Topic string              |                       |               | Topic TEXT  |
+// | BrokerId int              | BrokerId INTEGER      |               |             |
+// | QoS int                   | QoS TINYINT           |               |             |
+// | Message string            | Message TEXT          |               |             |
+// | CreationDate time.Time    | CreationDate DateTime |               |             |
+//
+// # Used in
+// - SelectMessagesByBrokerIdAndTimeRange()
+//
+// # Author
+// - Polariusz
+type SelectTopicMessage struct {
+	Id int
+	UserId int
+	ClientId string
+	TopicId int
+	Topic string
+	BrokerId int
+	QoS int
+	Message string
+	CreationDate time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB        : It's a connection to the database
+// - brokerId int       : Unique Identifier of table Broker
+// - timeFrom time.Time : Used to query rows that come at or past this time
+// - timeTo time.Time   : Used to query rows that come at or before this time. If it is zero, there is no upper bound.
+//
+// # Description
+// - Selects messages of all topics from table Message matched to argument `brokerId` that were received between `timeFrom` and `timeTo`.
+// - The rows are ordered from the oldest to the newest, so that they can be replayed in the order in which they came.
+//
+// # Tables Affected
+// - Message
+//   - SELECT
+//
+// # Returns
+// - A list of struct `SelectTopicMessage`
+// - error when:
+//   - Skill Issues
+//   - Table Message does not exist
+//     - Run SetupDatabase() before this function.
+//
+// # Author
+// - Polariusz
+func SelectMessagesByBrokerIdAndTimeRange(con *sql.DB, brokerId int, timeFrom time.Time, timeTo time.Time) ([]SelectTopicMessage, error) {
+	var selectMessageList []SelectTopicMessage
+
+	stmtStr := `
+		SELECT m.ID, u.ID, u.ClientId, m.TopicId, t.Topic, m.BrokerId, m.QoS, m.Message, m.CreationDate
+		FROM Message m
+		INNER JOIN User u
+		  ON u.ID = m.UserId
+		INNER JOIN Topic t
+		  ON t.ID = m.TopicId
+		WHERE
+			m.BrokerId = ?
+		AND
+			m.CreationDate >= ?
+		AND
+			(? OR m.CreationDate <= ?)
+		ORDER BY m.CreationDate ASC, m.ID ASC
+	`
+
+	stmt, err := con.Prepare(stmtStr)
+	if err != nil {
+		return nil, fmt.Errorf("Error while preparing the statement!\nStatement:\n%s\nErr: %s\n", stmtStr, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(brokerId, timeFrom, timeTo.IsZero(), timeTo)
+	if err != nil {
+		return nil, fmt.Errorf("Error while querying the statement!\nStatement:\n%s\nErr: %s\n", stmtStr, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var selectMessage SelectTopicMessage
+		rows.Scan(&selectMessage.Id, &selectMessage.UserId, &selectMessage.ClientId, &selectMessage.TopicId, &selectMessage.Topic, &selectMessage.BrokerId, &selectMessage.QoS, &selectMessage.Message, &selectMessage.CreationDate)
 		selectMessageList = append(selectMessageList, selectMessage)
 	}
 
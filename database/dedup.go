@@ -0,0 +1,267 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+/*                                       +-----------+                                       */
+/* --------------------------------------| DEDUPRULE |-------------------------------------- */
+/*                                       +-----------+                                       */
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct InsertDedupRule | Table DedupRule          |
+// +------------------------+--------------------------+
+// |                        | ID INTEGER               |
+// | BrokerId int           | BrokerId INTEGER         |
+// | TopicFilter string     | TopicFilter TEXT         |
+// | WindowSeconds int      | WindowSeconds INTEGER    |
+// | MatchPayloadOnly bool  | MatchPayloadOnly BOOLEAN |
+// |                        | CreationDate DATETIME    |
+//
+// # Note
+// - BrokerId 0 means that the rule applies to all brokers.
+// - A message is a duplicate if an earlier message of the same topic with the same payload was received within the last `WindowSeconds`, and:
+//   - the message has the DUP flag set and the same packet ID as the earlier one, or
+//   - `MatchPayloadOnly` is set, then the equal payload is enough.
+//
+// # Used in
+// - InsertNewDedupRule()
+//
+// # Author
+// - Polariusz
+type InsertDedupRule struct {
+	BrokerId int
+	TopicFilter string
+	WindowSeconds int
+	MatchPayloadOnly bool
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct SelectDedupRule | Table DedupRule          |
+// +------------------------+--------------------------+
+// | Id int                 | ID INTEGER               |
+// | BrokerId int           | BrokerId INTEGER         |
+// | TopicFilter string     | TopicFilter TEXT         |
+// | WindowSeconds int      | WindowSeconds INTEGER    |
+// | MatchPayloadOnly bool  | MatchPayloadOnly BOOLEAN |
+// | CreationDate time.Time | CreationDate DATETIME    |
+//
+// # Used in
+// - SelectDedupRules()
+//
+// # Author
+// - Polariusz
+type SelectDedupRule struct {
+	Id int
+	BrokerId int
+	TopicFilter string
+	WindowSeconds int
+	MatchPayloadOnly bool
+	CreationDate time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB          : It's a connection to the database.
+// - rule InsertDedupRule : It's inserted into table `DedupRule`
+//
+// # Tables Affected
+// - DedupRule
+//   - INSERT
+//
+// # Returns
+// - int: [DedupRule].[ID], -1 on error
+// - error when:
+//   - Skill Issues
+//   - Table DedupRule does not exist
+//     - Run SetupDatabase() before this function.
+//
+// # Author
+// - Polariusz
+func InsertNewDedupRule(con *sql.DB, rule InsertDedupRule) (int, error) {
+	result, err := con.Exec(`
+		INSERT INTO DedupRule(BrokerId, TopicFilter, WindowSeconds, MatchPayloadOnly, CreationDate)
+		VALUES(?, ?, ?, ?, ?)
+	`, rule.BrokerId, rule.TopicFilter, rule.WindowSeconds, rule.MatchPayloadOnly, time.Now())
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	ruleId, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return int(ruleId), nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+//
+// # Tables Affected
+// - DedupRule
+//   - SELECT
+//
+// # Returns
+// - A list of all struct `SelectDedupRule`, the oldest rule first
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectDedupRules(con *sql.DB) ([]SelectDedupRule, error) {
+	var ruleList []SelectDedupRule
+
+	rows, err := con.Query(`
+		SELECT ID, BrokerId, TopicFilter, WindowSeconds, MatchPayloadOnly, CreationDate
+		FROM DedupRule
+		ORDER BY ID
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rule SelectDedupRule
+		rows.Scan(&rule.Id, &rule.BrokerId, &rule.TopicFilter, &rule.WindowSeconds, &rule.MatchPayloadOnly, &rule.CreationDate)
+		ruleList = append(ruleList, rule)
+	}
+
+	return ruleList, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+// - id int      : [DedupRule].[ID]
+//
+// # Description
+// - The function shall delete the rule. Messages that were already marked as duplicates stay marked.
+//
+// # Tables Affected
+// - DedupRule
+//   - DELETE
+//
+// # Returns
+// - bool: false if there was no such rule
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteDedupRule(con *sql.DB, id int) (bool, error) {
+	result, err := con.Exec("DELETE FROM DedupRule WHERE ID = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+/*                                       +------------+                                       */
+/* --------------------------------------| DUPLICATES |-------------------------------------- */
+/*                                       +------------+                                       */
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct SelectDuplicateCount | Table Message, Topic         |
+// +-----------------------------+------------------------------+
+// | BrokerId int                | [Message].[BrokerId]         |
+// | TopicId int                 | [Message].[TopicId]          |
+// | Topic string                | [Topic].[Topic]              |
+// | Duplicates int              | COUNT(*) WHERE IsDuplicate   |
+// | LastDuplicate time.Time     | MAX(CreationDate)            |
+//
+// # Used in
+// - SelectDuplicateCounts()
+//
+// # Author
+// - Polariusz
+type SelectDuplicateCount struct {
+	BrokerId int
+	TopicId int
+	Topic string
+	Duplicates int
+	LastDuplicate time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB  : It's a connection to the database.
+// - brokerId int : [Broker].[ID], 0 for all brokers
+//
+// # Description
+// - The function shall count the messages that are marked as duplicates, per topic.
+// - Topics without duplicates are left out.
+//
+// # Tables Affected
+// - Message
+//   - SELECT
+// - Topic
+//   - SELECT
+//
+// # Returns
+// - A list of struct `SelectDuplicateCount`, the topic with the most duplicates first
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectDuplicateCounts(con *sql.DB, brokerId int) ([]SelectDuplicateCount, error) {
+	var countList []SelectDuplicateCount
+
+	rows, err := con.Query(`
+		SELECT m.BrokerId, m.TopicId, t.Topic, COUNT(*), MAX(m.CreationDate)
+		FROM Message m
+		JOIN Topic t ON t.ID = m.TopicId
+		WHERE m.IsDuplicate = 1
+		  AND (? = 0 OR m.BrokerId = ?)
+		GROUP BY m.BrokerId, m.TopicId
+		ORDER BY COUNT(*) DESC, m.TopicId
+	`, brokerId, brokerId)
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var count SelectDuplicateCount
+		var lastDuplicate string
+		rows.Scan(&count.BrokerId, &count.TopicId, &count.Topic, &count.Duplicates, &lastDuplicate)
+		count.LastDuplicate = parseSqliteTime(lastDuplicate)
+		countList = append(countList, count)
+	}
+
+	return countList, nil
+}
@@ -0,0 +1,528 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+/*                                       +---------+                                       */
+/* --------------------------------------| CASCADE |-------------------------------------- */
+/*                                       +---------+                                       */
+
+// | Date of change | By        | Comment   |
+// +----------------+-----------+-----------+
+// | 2026-10-19     | Polariusz | Created   |
+// | 2026-10-19     | Polariusz | Webhooks  |
+// | 2026-10-19     | Polariusz | Alerts    |
+// | 2026-10-19     | Polariusz | Schedules |
+//
+// # Description
+// - How many rows of each table a cascading delete has deleted, or would delete on a dry run.
+//
+// # Used in
+// - DeleteBrokerCascade()
+// - DeleteUserCascade()
+// - DeleteTopicsCascade()
+// - DeleteMessagesByFilter()
+// - DeleteBrokerRulesCascade()
+//
+// # Author
+// - Polariusz
+type DeleteCounts struct {
+	Brokers int64
+	Users int64
+	Topics int64
+	Messages int64
+	Subscriptions int64
+	Favourites int64
+	RetentionRules int64
+	RetentionDeletions int64
+	DedupRules int64
+	RoleAssignments int64
+	Webhooks int64
+	WebhookDeliveries int64
+	AlertRules int64
+	AlertEvents int64
+	Schedules int64
+	ScheduleRuns int64
+}
+
+// # Description
+// - The method shall add up two counts, field by field.
+//
+// # Author
+// - Polariusz
+func (counts DeleteCounts) Plus(other DeleteCounts) DeleteCounts {
+	counts.Brokers += other.Brokers
+	counts.Users += other.Users
+	counts.Topics += other.Topics
+	counts.Messages += other.Messages
+	counts.Subscriptions += other.Subscriptions
+	counts.Favourites += other.Favourites
+	counts.RetentionRules += other.RetentionRules
+	counts.RetentionDeletions += other.RetentionDeletions
+	counts.DedupRules += other.DedupRules
+	counts.RoleAssignments += other.RoleAssignments
+	counts.Webhooks += other.Webhooks
+	counts.WebhookDeliveries += other.WebhookDeliveries
+	counts.AlertRules += other.AlertRules
+	counts.AlertEvents += other.AlertEvents
+	counts.Schedules += other.Schedules
+	counts.ScheduleRuns += other.ScheduleRuns
+	return counts
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall delete the rows of `table` that match `where`, or only count them if `dryRun` is set.
+//
+// # Returns
+// - int64: How many rows were (or would be) deleted
+//
+// # Author
+// - Polariusz
+func deleteWhere(tx *sql.Tx, dryRun bool, table string, where string, args ...any) (int64, error) {
+	if dryRun {
+		var count int64
+		if err := tx.QueryRow("SELECT COUNT(*) FROM "+table+" WHERE "+where, args...).Scan(&count); err != nil {
+			return 0, fmt.Errorf("Error while counting the rows of %s!\nErr: %s\n", table, err)
+		}
+		return count, nil
+	}
+
+	result, err := tx.Exec("DELETE FROM "+table+" WHERE "+where, args...)
+	if err != nil {
+		return 0, fmt.Errorf("Error while deleting from %s!\nErr: %s\n", table, err)
+	}
+	affected, _ := result.RowsAffected()
+	return affected, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall run `cascade` in one transaction. On a dry run, or if `cascade` fails, the transaction is rolled back.
+//
+// # Author
+// - Polariusz
+func inDeleteTransaction(con *sql.DB, dryRun bool, cascade func(tx *sql.Tx, counts *DeleteCounts) error) (DeleteCounts, error) {
+	var counts DeleteCounts
+
+	tx, err := con.Begin()
+	if err != nil {
+		return counts, fmt.Errorf("Error while beginning the transaction!\nErr: %s\n", err)
+	}
+	defer tx.Rollback()
+
+	if err := cascade(tx, &counts); err != nil {
+		return DeleteCounts{}, err
+	}
+
+	if dryRun {
+		return counts, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return DeleteCounts{}, fmt.Errorf("Error while committing the transaction!\nErr: %s\n", err)
+	}
+
+	return counts, nil
+}
+
+// # Description
+// - The function shall return `?, ?, ?` with one placeholder per id and the ids as arguments.
+//
+// # Author
+// - Polariusz
+func idPlaceholders(idList []int) (string, []any) {
+	args := make([]any, len(idList))
+	for i, id := range idList {
+		args[i] = id
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(idList)), ", "), args
+}
+
+// | Date of change | By        | Comment   |
+// +----------------+-----------+-----------+
+// | 2026-10-19     | Polariusz | Created   |
+// | 2026-10-19     | Polariusz | Webhooks  |
+// | 2026-10-19     | Polariusz | Alerts    |
+// | 2026-10-19     | Polariusz | Schedules |
+//
+// # Arguments
+// - con *sql.DB  : It's a connection to the database.
+// - brokerId int : [Broker].[ID]
+// - dryRun bool  : If true, nothing is deleted, the rows are only counted.
+//
+// # Description
+// - The function shall delete the broker and everything that belongs to it in one transaction:
+//   - its messages, subscriptions, favourites, topics and users,
+//   - the retention and deduplication rules that only apply to this broker, with the record of what the retention rules deleted,
+//   - the roles that were given for this broker only,
+//   - the webhooks of this broker only, with their deliveries,
+//   - the alert rules of this broker only, and the alert events of the broker,
+//   - the schedules of the broker, with their runs.
+//
+// # Tables Affected
+// - Message, UserTopicSubscribed, UserTopicFavourite, Topic, User, RetentionDeletion, RetentionRule, DedupRule, RoleAssignment, WebhookDelivery, Webhook, AlertEvent, AlertRule, ScheduleRun, Schedule, Broker
+//   - DELETE
+//
+// # Returns
+// - DeleteCounts: `Brokers` is 0 if there was no such broker
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteBrokerCascade(con *sql.DB, brokerId int, dryRun bool) (DeleteCounts, error) {
+	return inDeleteTransaction(con, dryRun, func(tx *sql.Tx, counts *DeleteCounts) error {
+		var err error
+		if counts.Messages, err = deleteWhere(tx, dryRun, "Message", "BrokerId = ?", brokerId); err != nil {
+			return err
+		}
+		if counts.Subscriptions, err = deleteWhere(tx, dryRun, "UserTopicSubscribed", "BrokerId = ?", brokerId); err != nil {
+			return err
+		}
+		if counts.Favourites, err = deleteWhere(tx, dryRun, "UserTopicFavourite", "BrokerId = ?", brokerId); err != nil {
+			return err
+		}
+		if counts.Topics, err = deleteWhere(tx, dryRun, "Topic", "BrokerId = ?", brokerId); err != nil {
+			return err
+		}
+		if counts.Users, err = deleteWhere(tx, dryRun, "User", "BrokerId = ?", brokerId); err != nil {
+			return err
+		}
+		if err = deleteBrokerRules(tx, dryRun, brokerId, counts); err != nil {
+			return err
+		}
+		counts.Brokers, err = deleteWhere(tx, dryRun, "Broker", "ID = ?", brokerId)
+		return err
+	})
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall delete the rules of the broker with what belongs to them, see DeleteBrokerCascade().
+// - The tables are not kept by the interface `Store`, so a MemoryStore leaves them to this function.
+//
+// # Used in
+// - DeleteBrokerCascade()
+// - DeleteBrokerRulesCascade()
+//
+// # Author
+// - Polariusz
+func deleteBrokerRules(tx *sql.Tx, dryRun bool, brokerId int, counts *DeleteCounts) error {
+	var err error
+	if counts.RetentionDeletions, err = deleteWhere(tx, dryRun, "RetentionDeletion", "RuleId IN (SELECT ID FROM RetentionRule WHERE BrokerId = ?)", brokerId); err != nil {
+		return err
+	}
+	if counts.RetentionRules, err = deleteWhere(tx, dryRun, "RetentionRule", "BrokerId = ?", brokerId); err != nil {
+		return err
+	}
+	if counts.DedupRules, err = deleteWhere(tx, dryRun, "DedupRule", "BrokerId = ?", brokerId); err != nil {
+		return err
+	}
+	if counts.RoleAssignments, err = deleteWhere(tx, dryRun, "RoleAssignment", "BrokerId = ?", brokerId); err != nil {
+		return err
+	}
+	if counts.WebhookDeliveries, err = deleteWhere(tx, dryRun, "WebhookDelivery", "WebhookId IN (SELECT ID FROM Webhook WHERE BrokerId = ?)", brokerId); err != nil {
+		return err
+	}
+	if counts.Webhooks, err = deleteWhere(tx, dryRun, "Webhook", "BrokerId = ?", brokerId); err != nil {
+		return err
+	}
+	if counts.AlertEvents, err = deleteWhere(tx, dryRun, "AlertEvent", "BrokerId = ? OR RuleId IN (SELECT ID FROM AlertRule WHERE BrokerId = ?)", brokerId, brokerId); err != nil {
+		return err
+	}
+	if counts.AlertRules, err = deleteWhere(tx, dryRun, "AlertRule", "BrokerId = ?", brokerId); err != nil {
+		return err
+	}
+	if counts.ScheduleRuns, err = deleteWhere(tx, dryRun, "ScheduleRun", "ScheduleId IN (SELECT ID FROM Schedule WHERE BrokerId = ?)", brokerId); err != nil {
+		return err
+	}
+	if counts.Schedules, err = deleteWhere(tx, dryRun, "Schedule", "BrokerId = ?", brokerId); err != nil {
+		return err
+	}
+	return nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB  : It's a connection to the database.
+// - brokerId int : [Broker].[ID]
+// - dryRun bool  : If true, nothing is deleted, the rows are only counted.
+//
+// # Description
+// - The function shall only delete what DeleteBrokerCascade() deletes besides the data of the broker: the retention, deduplication, role, webhook, alert and schedule rows.
+// - It is used when the brokers, users, topics and messages are kept by a MemoryStore and only the rules are in the database.
+//
+// # Tables Affected
+// - RetentionDeletion, RetentionRule, DedupRule, RoleAssignment, WebhookDelivery, Webhook, AlertEvent, AlertRule, ScheduleRun, Schedule
+//   - DELETE
+//
+// # Returns
+// - DeleteCounts
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteBrokerRulesCascade(con *sql.DB, brokerId int, dryRun bool) (DeleteCounts, error) {
+	return inDeleteTransaction(con, dryRun, func(tx *sql.Tx, counts *DeleteCounts) error {
+		return deleteBrokerRules(tx, dryRun, brokerId, counts)
+	})
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+// - userId int  : [User].[ID]
+// - dryRun bool : If true, nothing is deleted, the rows are only counted.
+//
+// # Description
+// - The function shall delete the user with the messages it has published, its subscriptions and its favourites in one transaction.
+//
+// # Tables Affected
+// - Message, UserTopicSubscribed, UserTopicFavourite, User
+//   - DELETE
+//
+// # Returns
+// - DeleteCounts: `Users` is 0 if there was no such user
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteUserCascade(con *sql.DB, userId int, dryRun bool) (DeleteCounts, error) {
+	return inDeleteTransaction(con, dryRun, func(tx *sql.Tx, counts *DeleteCounts) error {
+		var err error
+		if counts.Messages, err = deleteWhere(tx, dryRun, "Message", "UserId = ?", userId); err != nil {
+			return err
+		}
+		if counts.Subscriptions, err = deleteWhere(tx, dryRun, "UserTopicSubscribed", "UserId = ?", userId); err != nil {
+			return err
+		}
+		if counts.Favourites, err = deleteWhere(tx, dryRun, "UserTopicFavourite", "UserId = ?", userId); err != nil {
+			return err
+		}
+		counts.Users, err = deleteWhere(tx, dryRun, "User", "ID = ?", userId)
+		return err
+	})
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB    : It's a connection to the database.
+// - topicIds []int : [Topic].[ID] of every topic to delete
+// - dryRun bool    : If true, nothing is deleted, the rows are only counted.
+//
+// # Description
+// - The function shall delete the topics with their messages, subscriptions and favourites in one transaction.
+// - Unlike DeleteTopic(), nothing that references the topics is left behind.
+//
+// # Tables Affected
+// - Message, UserTopicSubscribed, UserTopicFavourite, Topic
+//   - DELETE
+//
+// # Returns
+// - DeleteCounts
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteTopicsCascade(con *sql.DB, topicIds []int, dryRun bool) (DeleteCounts, error) {
+	if len(topicIds) == 0 {
+		return DeleteCounts{}, nil
+	}
+	placeholders, args := idPlaceholders(topicIds)
+
+	return inDeleteTransaction(con, dryRun, func(tx *sql.Tx, counts *DeleteCounts) error {
+		var err error
+		if counts.Messages, err = deleteWhere(tx, dryRun, "Message", "TopicId IN ("+placeholders+")", args...); err != nil {
+			return err
+		}
+		if counts.Subscriptions, err = deleteWhere(tx, dryRun, "UserTopicSubscribed", "TopicId IN ("+placeholders+")", args...); err != nil {
+			return err
+		}
+		if counts.Favourites, err = deleteWhere(tx, dryRun, "UserTopicFavourite", "TopicId IN ("+placeholders+")", args...); err != nil {
+			return err
+		}
+		counts.Topics, err = deleteWhere(tx, dryRun, "Topic", "ID IN ("+placeholders+")", args...)
+		return err
+	})
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - Which messages DeleteMessagesByFilter() deletes. Every field that is set narrows the selection, an unset field is not used.
+// - `Ids` and `TopicIds` are unset when empty, `BrokerId` and `UserId` when 0, `Before` and `After` when zero.
+// - `OnlyDuplicates` only selects messages that are marked as duplicates.
+//
+// # Used in
+// - DeleteMessagesByFilter()
+//
+// # Author
+// - Polariusz
+type MessageFilter struct {
+	Ids []int
+	BrokerId int
+	TopicIds []int
+	UserId int
+	After time.Time
+	Before time.Time
+	OnlyDuplicates bool
+}
+
+// # Description
+// - The method shall return the WHERE clause of the filter and its arguments.
+//
+// # Author
+// - Polariusz
+func (filter MessageFilter) where() (string, []any) {
+	var conditions []string
+	var args []any
+
+	if len(filter.Ids) > 0 {
+		placeholders, idArgs := idPlaceholders(filter.Ids)
+		conditions = append(conditions, "ID IN ("+placeholders+")")
+		args = append(args, idArgs...)
+	}
+	if filter.BrokerId != 0 {
+		conditions = append(conditions, "BrokerId = ?")
+		args = append(args, filter.BrokerId)
+	}
+	if len(filter.TopicIds) > 0 {
+		placeholders, idArgs := idPlaceholders(filter.TopicIds)
+		conditions = append(conditions, "TopicId IN ("+placeholders+")")
+		args = append(args, idArgs...)
+	}
+	if filter.UserId != 0 {
+		conditions = append(conditions, "UserId = ?")
+		args = append(args, filter.UserId)
+	}
+	if !filter.After.IsZero() {
+		conditions = append(conditions, "CreationDate >= ?")
+		args = append(args, filter.After)
+	}
+	if !filter.Before.IsZero() {
+		conditions = append(conditions, "CreationDate < ?")
+		args = append(args, filter.Before)
+	}
+	if filter.OnlyDuplicates {
+		conditions = append(conditions, "IsDuplicate = 1")
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// # Description
+// - The method shall report whether the message with the ID `id` matches the filter, like where() does in SQL.
+//
+// # Used in
+// - MemoryStore.DeleteMessagesByFilter()
+//
+// # Author
+// - Polariusz
+func (filter MessageFilter) matches(id int, message InsertMessage) bool {
+	contains := func(idList []int, id int) bool {
+		for _, listed := range idList {
+			if listed == id {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(filter.Ids) > 0 && !contains(filter.Ids, id) {
+		return false
+	}
+	if filter.BrokerId != 0 && message.BrokerId != filter.BrokerId {
+		return false
+	}
+	if len(filter.TopicIds) > 0 && !contains(filter.TopicIds, message.TopicId) {
+		return false
+	}
+	if filter.UserId != 0 && message.UserId != filter.UserId {
+		return false
+	}
+	if !filter.After.IsZero() && message.CreationDate.Before(filter.After) {
+		return false
+	}
+	if !filter.Before.IsZero() && !message.CreationDate.Before(filter.Before) {
+		return false
+	}
+	if filter.OnlyDuplicates && !message.IsDuplicate {
+		return false
+	}
+	return true
+}
+
+// # Description
+// - The method shall report whether no field of the filter is set, such a filter would match every message.
+//
+// # Author
+// - Polariusz
+func (filter MessageFilter) IsEmpty() bool {
+	where, _ := filter.where()
+	return where == ""
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB          : It's a connection to the database.
+// - filter MessageFilter : Which messages are deleted
+// - dryRun bool          : If true, nothing is deleted, the rows are only counted.
+//
+// # Description
+// - The function shall delete the messages that match the filter in one transaction.
+// - Messages are not referenced by any other table, so nothing else is deleted.
+//
+// # Tables Affected
+// - Message
+//   - DELETE
+//
+// # Returns
+// - DeleteCounts
+// - error when:
+//   - The filter is empty, deleting every message must not happen by accident
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteMessagesByFilter(con *sql.DB, filter MessageFilter, dryRun bool) (DeleteCounts, error) {
+	if filter.IsEmpty() {
+		return DeleteCounts{}, fmt.Errorf("Error: The message filter is empty!\n")
+	}
+
+	where, args := filter.where()
+	return inDeleteTransaction(con, dryRun, func(tx *sql.Tx, counts *DeleteCounts) error {
+		var err error
+		counts.Messages, err = deleteWhere(tx, dryRun, "Message", where, args...)
+		return err
+	})
+}
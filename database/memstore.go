@@ -0,0 +1,717 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// # Author
+// - Polariusz
+type memoryUser struct {
+	SelectUser
+	Password string
+}
+
+// # Author
+// - Polariusz
+type memoryRow struct {
+	Id int
+	BrokerId int
+	UserId int
+	TopicId int
+	CreationDate time.Time
+}
+
+// # Author
+// - Polariusz
+type memoryMessage struct {
+	InsertMessage
+	Id int
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Retention, cascading deletes and duplicate counts |
+//
+// # Description
+// - The in-memory implementation of the interface `Store`. It is meant for tests and for sessions that shall not leave anything behind.
+// - Every slice is a table, the IDs are given out in the order of insertion.
+// - The methods return the same results as the SQLite implementation, including which lookups return an error when nothing was found.
+//
+// # Author
+// - Polariusz
+type MemoryStore struct {
+	mutex sync.RWMutex
+	lastIds map[string]int
+	brokers []SelectBroker
+	users []memoryUser
+	topics []SelectTopic
+	subscriptions []memoryRow
+	messages []memoryMessage
+	favourites []memoryRow
+}
+
+// # Author
+// - Polariusz
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{lastIds: make(map[string]int)}
+}
+
+// # Description
+// - Every table counts its own IDs, like AUTOINCREMENT does.
+//
+// # Author
+// - Polariusz
+func (store *MemoryStore) nextId(table string) int {
+	store.lastIds[table]++
+	return store.lastIds[table]
+}
+
+// # Description
+// - The function shall return the rows of `list` that do not match, and how many rows matched.
+// - On a dry run the list is returned as it is, only the matching rows are counted.
+//
+// # Author
+// - Polariusz
+func removeWhere[T any](list []T, dryRun bool, match func(row T) bool) ([]T, int64) {
+	var count int64
+	var kept []T
+	for _, row := range list {
+		if match(row) {
+			count++
+		} else {
+			kept = append(kept, row)
+		}
+	}
+	if dryRun {
+		return list, count
+	}
+	return kept, count
+}
+
+/*                                       +--------+                                       */
+/* --------------------------------------| BROKER |-------------------------------------- */
+/*                                       +--------+                                       */
+
+func (store *MemoryStore) InsertNewBroker(broker InsertBroker) (int, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for _, known := range store.brokers {
+		if known.Ip == broker.Ip && known.Port == broker.Port {
+			return known.Id, nil
+		}
+	}
+
+	id := store.nextId("Broker")
+	store.brokers = append(store.brokers, SelectBroker{id, broker.Ip, broker.Port, time.Now()})
+	return id, nil
+}
+
+func (store *MemoryStore) SelectBrokerList() ([]SelectBroker, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return append([]SelectBroker(nil), store.brokers...), nil
+}
+
+func (store *MemoryStore) SelectBrokerByIpAndPort(ip string, port int) (SelectBroker, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	for _, broker := range store.brokers {
+		if broker.Ip == ip && broker.Port == port {
+			return broker, nil
+		}
+	}
+	return SelectBroker{}, nil
+}
+
+/*                                       +------+                                       */
+/* --------------------------------------| USER |-------------------------------------- */
+/*                                       +------+                                       */
+
+func (store *MemoryStore) InsertNewUser(user InsertUser) (int, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for _, known := range store.users {
+		if known.BrokerId == user.BrokerId && known.ClientId == user.ClientId && known.Username == user.Username && known.Password == user.Password && known.Outsider == user.Outsider {
+			return known.Id, nil
+		}
+	}
+
+	id := store.nextId("User")
+	store.users = append(store.users, memoryUser{SelectUser{id, user.BrokerId, user.ClientId, user.Username, user.Outsider, time.Now()}, user.Password})
+	return id, nil
+}
+
+func (store *MemoryStore) SelectUserById(id int) (SelectUser, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	if user, ok := store.user(id); ok {
+		return user, nil
+	}
+	return SelectUser{}, fmt.Errorf("Table User matched to Id: %d yelded no results.\n", id)
+}
+
+// # Author
+// - Polariusz
+func (store *MemoryStore) user(id int) (SelectUser, bool) {
+	for _, user := range store.users {
+		if user.Id == id {
+			return user.SelectUser, true
+		}
+	}
+	return SelectUser{}, false
+}
+
+func (store *MemoryStore) SelectUsersByClientId(clientId string) ([]SelectUser, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var userList []SelectUser
+	for _, user := range store.users {
+		if user.ClientId == clientId {
+			userList = append(userList, user.SelectUser)
+		}
+	}
+	if userList == nil {
+		return nil, fmt.Errorf("Table User matched to ClientId: %s yelded no results.\n", clientId)
+	}
+	return userList, nil
+}
+
+func (store *MemoryStore) SelectUserByClientIdAndBrokerId(clientId string, brokerId int) (SelectUser, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	for _, user := range store.users {
+		if user.ClientId == clientId && user.BrokerId == brokerId {
+			return user.SelectUser, nil
+		}
+	}
+	return SelectUser{}, fmt.Errorf("Table User matched to ClientId: %s and BrokerId: %d yelded no results.\n", clientId, brokerId)
+}
+
+/*                                       +-------+                                       */
+/* --------------------------------------| TOPIC |-------------------------------------- */
+/*                                       +-------+                                       */
+
+func (store *MemoryStore) InsertNewTopic(topic InsertTopic) (int, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if id := store.topicId(topic.BrokerId, topic.Topic); id != -1 {
+		return id, nil
+	}
+
+	id := store.nextId("Topic")
+	store.topics = append(store.topics, SelectTopic{id, topic.BrokerId, topic.Topic, time.Now()})
+	return id, nil
+}
+
+// # Author
+// - Polariusz
+func (store *MemoryStore) topicId(brokerId int, topic string) int {
+	for _, known := range store.topics {
+		if known.BrokerId == brokerId && known.Topic == topic {
+			return known.Id
+		}
+	}
+	return -1
+}
+
+// # Author
+// - Polariusz
+func (store *MemoryStore) topic(id int) (SelectTopic, bool) {
+	for _, topic := range store.topics {
+		if topic.Id == id {
+			return topic, true
+		}
+	}
+	return SelectTopic{}, false
+}
+
+func (store *MemoryStore) SelectTopicsByBrokerId(brokerId int) ([]SelectTopic, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var topicList []SelectTopic
+	for _, topic := range store.topics {
+		if topic.BrokerId == brokerId {
+			topicList = append(topicList, topic)
+		}
+	}
+	return topicList, nil
+}
+
+func (store *MemoryStore) SelectTopicIdByBrokerIdAndTopic(brokerId int, topic string) (int, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	return store.topicId(brokerId, topic), nil
+}
+
+func (store *MemoryStore) SelectTopicsByBrokerIdAndUserId(brokerId int, userId int) ([]SelectTopicSubscribed, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var topicList []SelectTopicSubscribed
+	for _, topic := range store.topics {
+		if topic.BrokerId != brokerId {
+			continue
+		}
+		subscribed := false
+		for _, subscription := range store.subscriptions {
+			if subscription.BrokerId == brokerId && subscription.UserId == userId && subscription.TopicId == topic.Id {
+				subscribed = true
+			}
+		}
+		topicList = append(topicList, SelectTopicSubscribed{topic.Id, topic.BrokerId, topic.Topic, topic.CreationDate, subscribed})
+	}
+	return topicList, nil
+}
+
+func (store *MemoryStore) DeleteTopic(topicId int) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for index, topic := range store.topics {
+		if topic.Id == topicId {
+			store.topics = append(store.topics[:index], store.topics[index+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+/*                                       +---------------------+                                       */
+/* --------------------------------------| USERTOPICSUBSCRIBED |-------------------------------------- */
+/*                                       +---------------------+                                       */
+
+func (store *MemoryStore) SelectSubscribedTopics(brokerId int, userId int) ([]SelectUserTopicSubscribed, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var topicList []SelectUserTopicSubscribed
+	for _, subscription := range store.subscriptions {
+		if subscription.BrokerId != brokerId || subscription.UserId != userId {
+			continue
+		}
+		topic, ok := store.topic(subscription.TopicId)
+		if !ok {
+			continue
+		}
+		topicList = append(topicList, SelectUserTopicSubscribed{subscription.Id, subscription.BrokerId, subscription.UserId, subscription.TopicId, topic.Topic, subscription.CreationDate})
+	}
+	return topicList, nil
+}
+
+func (store *MemoryStore) SubscribeTopic(brokerId int, userId int, topicId int) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.subscriptions = append(store.subscriptions, memoryRow{store.nextId("UserTopicSubscribed"), brokerId, userId, topicId, time.Now()})
+	return nil
+}
+
+func (store *MemoryStore) UnsubscribeTopic(brokerId int, userId int, topicId int) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	kept := store.subscriptions[:0]
+	for _, subscription := range store.subscriptions {
+		if subscription.BrokerId != brokerId || subscription.UserId != userId || subscription.TopicId != topicId {
+			kept = append(kept, subscription)
+		}
+	}
+	store.subscriptions = kept
+	return nil
+}
+
+/*                                       +---------+                                       */
+/* --------------------------------------| MESSAGE |-------------------------------------- */
+/*                                       +---------+                                       */
+
+func (store *MemoryStore) InsertNewMessage(message InsertMessage) error {
+	return store.InsertNewMessages([]InsertMessage{message})
+}
+
+func (store *MemoryStore) InsertNewMessages(messageList []InsertMessage) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for _, message := range messageList {
+		message.CreationDate = message.creationDate()
+		store.messages = append(store.messages, memoryMessage{message, store.nextId("Message")})
+	}
+	return nil
+}
+
+// # Description
+// - The method shall return the message as it is returned by the SQLite implementation, joined with the User of the message.
+//
+// # Author
+// - Polariusz
+func (store *MemoryStore) selectMessage(message memoryMessage) (SelectMessage, bool) {
+	user, ok := store.user(message.UserId)
+	selectMessage := SelectMessage{
+		Id: message.Id,
+		UserId: user.Id,
+		ClientId: user.ClientId,
+		TopicId: message.TopicId,
+		BrokerId: message.BrokerId,
+		QoS: int(message.QoS),
+		Message: message.Message,
+		CreationDate: message.CreationDate,
+		Retained: message.Retained,
+		Duplicate: message.Duplicate,
+		PacketId: message.PacketId,
+		PayloadSize: message.PayloadSize,
+		ReceivedAt: time.Unix(0, message.receivedNanos(message.CreationDate)),
+		IsDuplicate: message.IsDuplicate,
+	}
+	if !message.EnvelopeDate.IsZero() {
+		envelopeDate := message.EnvelopeDate
+		selectMessage.EnvelopeDate = &envelopeDate
+	}
+	return selectMessage, ok
+}
+
+// # Author
+// - Polariusz
+func sortMessagesNewestFirst(messageList []SelectMessage) {
+	sort.SliceStable(messageList, func(i, j int) bool {
+		return messageList[i].CreationDate.After(messageList[j].CreationDate)
+	})
+}
+
+func (store *MemoryStore) SelectMessagesByTopicIdAndBrokerId(topicId int, brokerId int, hideDuplicates bool) ([]SelectMessage, error) {
+	return store.SelectMessagesByBrokerIdTopicIdAndDatetime(brokerId, topicId, time.Time{}, hideDuplicates)
+}
+
+func (store *MemoryStore) SelectMessagesByTopicIdBrokerIdAndIndex(topicId int, brokerId int, index int, hideDuplicates bool) ([]SelectMessage, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var messageList []SelectMessage
+	rowCount := 0
+	for _, message := range store.messages {
+		if message.TopicId != topicId || message.BrokerId != brokerId || (hideDuplicates && message.IsDuplicate) {
+			continue
+		}
+		rowCount++
+		if rowCount <= index*LIMIT_MESSAGES || rowCount > (1+index)*LIMIT_MESSAGES {
+			continue
+		}
+		// The SQLite implementation uses a LEFT JOIN here, so messages of unknown users are kept.
+		selectMessage, _ := store.selectMessage(message)
+		selectMessage.UserId = message.UserId
+		messageList = append(messageList, selectMessage)
+	}
+	sortMessagesNewestFirst(messageList)
+	return messageList, nil
+}
+
+func (store *MemoryStore) SelectMessagesByBrokerIdTopicIdAndDatetime(brokerId int, topicId int, timeFrom time.Time, hideDuplicates bool) ([]SelectMessage, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var messageList []SelectMessage
+	for _, message := range store.messages {
+		if message.TopicId != topicId || message.BrokerId != brokerId || !message.CreationDate.After(timeFrom) || (hideDuplicates && message.IsDuplicate) {
+			continue
+		}
+		if selectMessage, ok := store.selectMessage(message); ok {
+			messageList = append(messageList, selectMessage)
+		}
+	}
+	sortMessagesNewestFirst(messageList)
+	return messageList, nil
+}
+
+func (store *MemoryStore) SelectMessagesByBrokerIdAndTimeRange(brokerId int, timeFrom time.Time, timeTo time.Time) ([]SelectTopicMessage, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var messageList []SelectTopicMessage
+	for _, message := range store.messages {
+		if message.BrokerId != brokerId || message.CreationDate.Before(timeFrom) || (!timeTo.IsZero() && message.CreationDate.After(timeTo)) {
+			continue
+		}
+		user, userOk := store.user(message.UserId)
+		topic, topicOk := store.topic(message.TopicId)
+		if !userOk || !topicOk {
+			continue
+		}
+		messageList = append(messageList, SelectTopicMessage{message.Id, user.Id, user.ClientId, message.TopicId, topic.Topic, message.BrokerId, int(message.QoS), message.Message, message.CreationDate})
+	}
+	sort.SliceStable(messageList, func(i, j int) bool {
+		return messageList[i].CreationDate.Before(messageList[j].CreationDate)
+	})
+	return messageList, nil
+}
+
+func (store *MemoryStore) SelectDuplicateCounts(brokerId int) ([]SelectDuplicateCount, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var countList []SelectDuplicateCount
+	indexes := make(map[[2]int]int)
+	for _, message := range store.messages {
+		if !message.IsDuplicate || (brokerId != 0 && message.BrokerId != brokerId) {
+			continue
+		}
+		topic, ok := store.topic(message.TopicId)
+		if !ok {
+			continue
+		}
+		key := [2]int{message.BrokerId, message.TopicId}
+		index, seen := indexes[key]
+		if !seen {
+			index = len(countList)
+			indexes[key] = index
+			countList = append(countList, SelectDuplicateCount{BrokerId: message.BrokerId, TopicId: message.TopicId, Topic: topic.Topic})
+		}
+		countList[index].Duplicates++
+		if message.CreationDate.After(countList[index].LastDuplicate) {
+			countList[index].LastDuplicate = message.CreationDate
+		}
+	}
+	sort.SliceStable(countList, func(i, j int) bool {
+		if countList[i].Duplicates != countList[j].Duplicates {
+			return countList[i].Duplicates > countList[j].Duplicates
+		}
+		return countList[i].TopicId < countList[j].TopicId
+	})
+	return countList, nil
+}
+
+// # Description
+// - The method shall delete the messages whose IDs are in `ids`.
+//
+// # Returns
+// - int64: How many messages were deleted
+//
+// # Author
+// - Polariusz
+func (store *MemoryStore) deleteMessageIds(ids map[int]bool) int64 {
+	var count int64
+	store.messages, count = removeWhere(store.messages, false, func(message memoryMessage) bool {
+		return ids[message.Id]
+	})
+	return count
+}
+
+func (store *MemoryStore) DeleteMessagesOlderThan(brokerId int, topicId int, before time.Time, limit int) (int64, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	ids := make(map[int]bool)
+	for _, message := range store.messages {
+		if len(ids) == limit {
+			break
+		}
+		if message.TopicId == topicId && message.BrokerId == brokerId && message.CreationDate.Before(before) {
+			ids[message.Id] = true
+		}
+	}
+	return store.deleteMessageIds(ids), nil
+}
+
+func (store *MemoryStore) DeleteMessagesBeyondCount(brokerId int, topicId int, keep int, limit int) (int64, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	ids := make(map[int]bool)
+	newer := 0
+	// The messages are kept in the order of their IDs, so the newest come last.
+	for index := len(store.messages) - 1; index >= 0 && len(ids) < limit; index-- {
+		message := store.messages[index]
+		if message.TopicId != topicId || message.BrokerId != brokerId {
+			continue
+		}
+		newer++
+		if newer > keep {
+			ids[message.Id] = true
+		}
+	}
+	return store.deleteMessageIds(ids), nil
+}
+
+func (store *MemoryStore) DeleteOldestMessages(brokerId int, topicIds []int, limit int) (int64, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	topics := make(map[int]bool)
+	for _, topicId := range topicIds {
+		topics[topicId] = true
+	}
+	ids := make(map[int]bool)
+	for _, message := range store.messages {
+		if len(ids) == limit {
+			break
+		}
+		if message.BrokerId == brokerId && topics[message.TopicId] {
+			ids[message.Id] = true
+		}
+	}
+	return store.deleteMessageIds(ids), nil
+}
+
+// The bytes a row takes besides its text, it makes up for the columns and the indexes SQLite would keep.
+const MEMORY_ROW_OVERHEAD = 64
+
+// # Description
+// - There is no file, so the method estimates what the rows would take in SQLite: the text of every row and `MEMORY_ROW_OVERHEAD` bytes per row.
+// - The estimate is what the retention rules with a maximum database size are compared against.
+//
+// # Author
+// - Polariusz
+func (store *MemoryStore) SelectDatabaseSize() (int64, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var size int64
+	for _, message := range store.messages {
+		size += int64(len(message.Message)) + MEMORY_ROW_OVERHEAD
+	}
+	for _, topic := range store.topics {
+		size += int64(len(topic.Topic)) + MEMORY_ROW_OVERHEAD
+	}
+	for _, user := range store.users {
+		size += int64(len(user.ClientId)+len(user.Username)+len(user.Password)) + MEMORY_ROW_OVERHEAD
+	}
+	size += int64(len(store.brokers)+len(store.subscriptions)+len(store.favourites)) * MEMORY_ROW_OVERHEAD
+	return size, nil
+}
+
+/*                                       +----------+                                       */
+/* --------------------------------------| FAVTOPIC |-------------------------------------- */
+/*                                       +----------+                                       */
+
+func (store *MemoryStore) SelectFavouriteTopicsByBrokerIdAndUserId(brokerId int, userId int) ([]SelectFavTopic, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	var favTopicList []SelectFavTopic
+	for _, favourite := range store.favourites {
+		if favourite.BrokerId != brokerId || favourite.UserId != userId {
+			continue
+		}
+		topic, ok := store.topic(favourite.TopicId)
+		if !ok {
+			continue
+		}
+		favTopicList = append(favTopicList, SelectFavTopic{favourite.Id, favourite.UserId, favourite.TopicId, topic.Topic, favourite.CreationDate})
+	}
+	return favTopicList, nil
+}
+
+func (store *MemoryStore) SelectFavouriteTopicIdsByBrokerId(brokerId int) (map[int]bool, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+
+	favouriteTopicIds := make(map[int]bool)
+	for _, favourite := range store.favourites {
+		if favourite.BrokerId == brokerId {
+			favouriteTopicIds[favourite.TopicId] = true
+		}
+	}
+	return favouriteTopicIds, nil
+}
+
+func (store *MemoryStore) InsertFavouriteTopic(brokerId int, userId int, topicId int) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.favourites = append(store.favourites, memoryRow{store.nextId("UserTopicFavourite"), brokerId, userId, topicId, time.Now()})
+	return nil
+}
+
+func (store *MemoryStore) DeleteFavouriteTopic(id int) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	for index, favourite := range store.favourites {
+		if favourite.Id == id {
+			store.favourites = append(store.favourites[:index], store.favourites[index+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+/*                                       +---------+                                       */
+/* --------------------------------------| CASCADE |-------------------------------------- */
+/*                                       +---------+                                       */
+
+// # Description
+// - Only the data of the broker is deleted, the rules are not kept by the MemoryStore, see DeleteBrokerRulesCascade().
+//
+// # Author
+// - Polariusz
+func (store *MemoryStore) DeleteBrokerCascade(brokerId int, dryRun bool) (DeleteCounts, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	var counts DeleteCounts
+	store.messages, counts.Messages = removeWhere(store.messages, dryRun, func(message memoryMessage) bool { return message.BrokerId == brokerId })
+	store.subscriptions, counts.Subscriptions = removeWhere(store.subscriptions, dryRun, func(row memoryRow) bool { return row.BrokerId == brokerId })
+	store.favourites, counts.Favourites = removeWhere(store.favourites, dryRun, func(row memoryRow) bool { return row.BrokerId == brokerId })
+	store.topics, counts.Topics = removeWhere(store.topics, dryRun, func(topic SelectTopic) bool { return topic.BrokerId == brokerId })
+	store.users, counts.Users = removeWhere(store.users, dryRun, func(user memoryUser) bool { return user.BrokerId == brokerId })
+	store.brokers, counts.Brokers = removeWhere(store.brokers, dryRun, func(broker SelectBroker) bool { return broker.Id == brokerId })
+	return counts, nil
+}
+
+func (store *MemoryStore) DeleteUserCascade(userId int, dryRun bool) (DeleteCounts, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	var counts DeleteCounts
+	store.messages, counts.Messages = removeWhere(store.messages, dryRun, func(message memoryMessage) bool { return message.UserId == userId })
+	store.subscriptions, counts.Subscriptions = removeWhere(store.subscriptions, dryRun, func(row memoryRow) bool { return row.UserId == userId })
+	store.favourites, counts.Favourites = removeWhere(store.favourites, dryRun, func(row memoryRow) bool { return row.UserId == userId })
+	store.users, counts.Users = removeWhere(store.users, dryRun, func(user memoryUser) bool { return user.Id == userId })
+	return counts, nil
+}
+
+func (store *MemoryStore) DeleteTopicsCascade(topicIds []int, dryRun bool) (DeleteCounts, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	topics := make(map[int]bool)
+	for _, topicId := range topicIds {
+		topics[topicId] = true
+	}
+	var counts DeleteCounts
+	if len(topics) == 0 {
+		return counts, nil
+	}
+	store.messages, counts.Messages = removeWhere(store.messages, dryRun, func(message memoryMessage) bool { return topics[message.TopicId] })
+	store.subscriptions, counts.Subscriptions = removeWhere(store.subscriptions, dryRun, func(row memoryRow) bool { return topics[row.TopicId] })
+	store.favourites, counts.Favourites = removeWhere(store.favourites, dryRun, func(row memoryRow) bool { return topics[row.TopicId] })
+	store.topics, counts.Topics = removeWhere(store.topics, dryRun, func(topic SelectTopic) bool { return topics[topic.Id] })
+	return counts, nil
+}
+
+func (store *MemoryStore) DeleteMessagesByFilter(filter MessageFilter, dryRun bool) (DeleteCounts, error) {
+	if filter.IsEmpty() {
+		return DeleteCounts{}, fmt.Errorf("Error: The message filter is empty!\n")
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	var counts DeleteCounts
+	store.messages, counts.Messages = removeWhere(store.messages, dryRun, func(message memoryMessage) bool {
+		return filter.matches(message.Id, message.InsertMessage)
+	})
+	return counts, nil
+}
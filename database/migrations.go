@@ -0,0 +1,708 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A migration moves the schema from `Version-1` to `Version`.
+// - All statements of a migration are executed in one transaction together with the insertion into table SchemaVersion.
+//
+// # Used in
+// - migrationList
+//
+// # Author
+// - Polariusz
+type Migration struct {
+	Version int
+	Name string
+	Statements []string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The ordered list of all migrations. New migrations shall only ever be appended with the next version, never edited after they were released.
+// - Version 1 is the schema that `SetupDatabase()` created before migrations existed.
+//
+// # Author
+// - Polariusz
+var migrationList = []Migration{
+	{
+		Version: 1,
+		Name: "baseline",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS Broker (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				Ip TEXT NOT NULL,
+				Port INTEGER NOT NULL,
+				CreationDate DATETIME NOT NULL
+			);`,
+
+			`CREATE TABLE IF NOT EXISTS User (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				BrokerId INTEGER NOT NULL,
+				ClientId TEXT NOT NULL,
+				Username TEXT NOT NULL,
+				Password TEXT,
+				Outsider BOOLEAN,
+				CreationDate DATETIME NOT NULL,
+				FOREIGN KEY(BrokerId) REFERENCES Broker(ID)
+			);`,
+
+			`CREATE TABLE IF NOT EXISTS Message (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				UserId INTEGER NOT NULL,
+				TopicId INTEGER NOT NULL,
+				BrokerId INTEGER NOT NULL,
+				QoS TINYINT,
+				Message TEXT,
+				CreationDate DATETIME,
+				FOREIGN KEY(UserId) REFERENCES User(ID),
+				FOREIGN KEY(TopicId) REFERENCES Topic(ID),
+				FOREIGN KEY(BrokerId) REFERENCES Broker(ID)
+			);`,
+
+			`CREATE TABLE IF NOT EXISTS Topic (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				BrokerId INTEGER NOT NULL,
+				Topic TEXT NOT NULL,
+				CreationDate DATETIME,
+				FOREIGN KEY(BrokerId) REFERENCES Broker(ID)
+			);`,
+
+			`CREATE TABLE IF NOT EXISTS UserTopicSubscribed (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				BrokerId INTEGER NOT NULL,
+				UserId INTEGER NOT NULL,
+				TopicId INTEGER NOT NULL,
+				CreationDate DATETIME,
+				FOREIGN KEY(BrokerId) REFERENCES Broker(ID),
+				FOREIGN KEY(UserId) REFERENCES User(ID),
+				FOREIGN KEY(TopicId) REFERENCES Topic(ID)
+			);`,
+
+			`CREATE TABLE IF NOT EXISTS UserTopicFavourite (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				BrokerId INTEGER NOT NULL,
+				UserId INTEGER NOT NULL,
+				TopicId INTEGER NOT NULL,
+				CreationDate DATETIME,
+				FOREIGN KEY(BrokerId) REFERENCES Broker(ID),
+				FOREIGN KEY(UserId) REFERENCES User(ID),
+				FOREIGN KEY(TopicId) REFERENCES Topic(ID)
+			);`,
+		},
+	},
+	{
+		Version: 2,
+		Name: "ingest indexes",
+		Statements: []string{
+			`CREATE INDEX IF NOT EXISTS IX_Topic_BrokerId_Topic ON Topic(BrokerId, Topic);`,
+			`CREATE INDEX IF NOT EXISTS IX_User_BrokerId_ClientId ON User(BrokerId, ClientId);`,
+			`CREATE INDEX IF NOT EXISTS IX_Message_TopicId_BrokerId_ID ON Message(TopicId, BrokerId, ID);`,
+		},
+	},
+	{
+		Version: 3,
+		Name: "retention rules",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS RetentionRule (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				BrokerId INTEGER NOT NULL,
+				TopicFilter TEXT NOT NULL,
+				MaxAgeSeconds INTEGER NOT NULL,
+				MaxMessagesPerTopic INTEGER NOT NULL,
+				MaxDatabaseBytes INTEGER NOT NULL,
+				KeepFavourites BOOLEAN NOT NULL,
+				CreationDate DATETIME NOT NULL
+			);`,
+
+			`CREATE TABLE IF NOT EXISTS RetentionDeletion (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				RuleId INTEGER NOT NULL,
+				Reason TEXT NOT NULL,
+				Deleted INTEGER NOT NULL,
+				CreationDate DATETIME NOT NULL,
+				FOREIGN KEY(RuleId) REFERENCES RetentionRule(ID)
+			);`,
+		},
+	},
+	{
+		Version: 4,
+		Name: "message metadata",
+		Statements: []string{
+			`ALTER TABLE Message ADD COLUMN Retained BOOLEAN NOT NULL DEFAULT 0;`,
+			`ALTER TABLE Message ADD COLUMN Duplicate BOOLEAN NOT NULL DEFAULT 0;`,
+			`ALTER TABLE Message ADD COLUMN PacketId INTEGER NOT NULL DEFAULT 0;`,
+			`ALTER TABLE Message ADD COLUMN PayloadSize INTEGER NOT NULL DEFAULT 0;`,
+			`ALTER TABLE Message ADD COLUMN ReceivedNanos INTEGER NOT NULL DEFAULT 0;`,
+			`ALTER TABLE Message ADD COLUMN EnvelopeDate DATETIME;`,
+			// Messages from before the metadata were received when they were stored, to the millisecond.
+			`UPDATE Message
+			SET ReceivedNanos = CAST(strftime('%s', CreationDate) AS INTEGER) * 1000000000 + CAST(ROUND(strftime('%f', CreationDate) * 1000) AS INTEGER) % 1000 * 1000000
+			WHERE ReceivedNanos = 0 AND strftime('%s', CreationDate) IS NOT NULL;`,
+		},
+	},
+	{
+		Version: 5,
+		Name: "deduplication",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS DedupRule (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				BrokerId INTEGER NOT NULL,
+				TopicFilter TEXT NOT NULL,
+				WindowSeconds INTEGER NOT NULL,
+				MatchPayloadOnly BOOLEAN NOT NULL,
+				CreationDate DATETIME NOT NULL
+			);`,
+			`ALTER TABLE Message ADD COLUMN IsDuplicate BOOLEAN NOT NULL DEFAULT 0;`,
+			`CREATE INDEX IF NOT EXISTS IX_Message_Duplicates ON Message(BrokerId, TopicId) WHERE IsDuplicate = 1;`,
+		},
+	},
+	{
+		Version: 6,
+		Name: "accounts",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS Account (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				Username TEXT NOT NULL UNIQUE,
+				PasswordHash TEXT NOT NULL,
+				CreationDate DATETIME NOT NULL
+			);`,
+			`CREATE TABLE IF NOT EXISTS ApiKey (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				AccountId INTEGER NOT NULL,
+				Name TEXT NOT NULL,
+				Prefix TEXT NOT NULL,
+				KeyHash TEXT NOT NULL UNIQUE,
+				CreationDate DATETIME NOT NULL,
+				LastUsed DATETIME,
+				FOREIGN KEY(AccountId) REFERENCES Account(ID)
+			);`,
+		},
+	},
+	{
+		Version: 7,
+		Name: "roles",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS RoleAssignment (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				AccountId INTEGER NOT NULL,
+				Role TEXT NOT NULL,
+				BrokerId INTEGER NOT NULL,
+				TopicFilter TEXT NOT NULL,
+				CreationDate DATETIME NOT NULL,
+				FOREIGN KEY(AccountId) REFERENCES Account(ID)
+			);`,
+			// The accounts from before could do everything, they stay able to.
+			`INSERT INTO RoleAssignment(AccountId, Role, BrokerId, TopicFilter, CreationDate)
+				SELECT ID, 'admin', 0, '#', CURRENT_TIMESTAMP FROM Account;`,
+		},
+	},
+	{
+		Version: 8,
+		Name: "audit log",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS AuditLog (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				CreationDate DATETIME NOT NULL,
+				AccountId INTEGER NOT NULL,
+				Actor TEXT NOT NULL,
+				RemoteAddress TEXT NOT NULL,
+				Action TEXT NOT NULL,
+				BrokerId INTEGER NOT NULL,
+				UserId INTEGER NOT NULL,
+				Topic TEXT NOT NULL,
+				PayloadHash TEXT NOT NULL,
+				Outcome TEXT NOT NULL,
+				Status INTEGER NOT NULL,
+				Detail TEXT NOT NULL
+			);`,
+			`CREATE INDEX IF NOT EXISTS IX_AuditLog_BrokerId_ID ON AuditLog(BrokerId, ID);`,
+			`CREATE INDEX IF NOT EXISTS IX_AuditLog_Actor_ID ON AuditLog(Actor, ID);`,
+			// The log is append-only, not even the cascading deletes may change what happened.
+			`CREATE TRIGGER IF NOT EXISTS TR_AuditLog_NoUpdate BEFORE UPDATE ON AuditLog
+			BEGIN
+				SELECT RAISE(ABORT, 'The audit log is append-only');
+			END;`,
+			`CREATE TRIGGER IF NOT EXISTS TR_AuditLog_NoDelete BEFORE DELETE ON AuditLog
+			BEGIN
+				SELECT RAISE(ABORT, 'The audit log is append-only');
+			END;`,
+		},
+	},
+	{
+		Version: 9,
+		Name: "webhooks",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS Webhook (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				Name TEXT NOT NULL,
+				BrokerId INTEGER NOT NULL,
+				TopicFilter TEXT NOT NULL,
+				ConditionPath TEXT NOT NULL,
+				ConditionOperator TEXT NOT NULL,
+				ConditionValue TEXT NOT NULL,
+				Url TEXT NOT NULL,
+				Method TEXT NOT NULL,
+				Headers TEXT NOT NULL,
+				BodyTemplate TEXT NOT NULL,
+				MaxAttempts INTEGER NOT NULL,
+				CreationDate DATETIME NOT NULL
+			);`,
+			`CREATE TABLE IF NOT EXISTS WebhookDelivery (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				WebhookId INTEGER NOT NULL,
+				BrokerId INTEGER NOT NULL,
+				Topic TEXT NOT NULL,
+				RequestBody TEXT NOT NULL,
+				Status TEXT NOT NULL,
+				Attempts INTEGER NOT NULL,
+				LastStatusCode INTEGER NOT NULL,
+				LastError TEXT NOT NULL,
+				CreationDate DATETIME NOT NULL,
+				LastAttemptDate DATETIME NOT NULL,
+				NextAttemptDate DATETIME NOT NULL,
+				FOREIGN KEY(WebhookId) REFERENCES Webhook(ID)
+			);`,
+			`CREATE INDEX IF NOT EXISTS IX_WebhookDelivery_WebhookId_ID ON WebhookDelivery(WebhookId, ID);`,
+			`CREATE INDEX IF NOT EXISTS IX_WebhookDelivery_Status_NextAttemptDate ON WebhookDelivery(Status, NextAttemptDate);`,
+		},
+	},
+	{
+		Version: 10,
+		Name: "bridges",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS Bridge (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				Name TEXT NOT NULL,
+				SourceIp TEXT NOT NULL,
+				SourcePort INTEGER NOT NULL,
+				SourceClientId TEXT NOT NULL,
+				SourceUsername TEXT NOT NULL,
+				SourcePassword TEXT NOT NULL,
+				TopicFilters TEXT NOT NULL,
+				DestinationIp TEXT NOT NULL,
+				DestinationPort INTEGER NOT NULL,
+				DestinationClientId TEXT NOT NULL,
+				DestinationUsername TEXT NOT NULL,
+				DestinationPassword TEXT NOT NULL,
+				Rewrites TEXT NOT NULL,
+				QoS INTEGER NOT NULL,
+				RetainMode TEXT NOT NULL,
+				Enabled INTEGER NOT NULL,
+				CreationDate DATETIME NOT NULL
+			);`,
+		},
+	},
+	{
+		Version: 11,
+		Name: "alerts",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS AlertRule (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				Name TEXT NOT NULL,
+				BrokerId INTEGER NOT NULL,
+				TopicFilter TEXT NOT NULL,
+				ConditionPath TEXT NOT NULL,
+				ConditionOperator TEXT NOT NULL,
+				ConditionValue TEXT NOT NULL,
+				SilenceSeconds INTEGER NOT NULL,
+				ForSeconds INTEGER NOT NULL,
+				Hysteresis REAL NOT NULL,
+				WebhookId INTEGER NOT NULL,
+				CreationDate DATETIME NOT NULL
+			);`,
+			`CREATE TABLE IF NOT EXISTS AlertEvent (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				RuleId INTEGER NOT NULL,
+				BrokerId INTEGER NOT NULL,
+				Topic TEXT NOT NULL,
+				State TEXT NOT NULL,
+				Value TEXT NOT NULL,
+				CreationDate DATETIME NOT NULL,
+				FOREIGN KEY(RuleId) REFERENCES AlertRule(ID)
+			);`,
+			`CREATE INDEX IF NOT EXISTS IX_AlertEvent_RuleId_ID ON AlertEvent(RuleId, ID);`,
+		},
+	},
+	{
+		Version: 12,
+		Name: "schedules",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS Schedule (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				Name TEXT NOT NULL,
+				BrokerId INTEGER NOT NULL,
+				Topic TEXT NOT NULL,
+				PayloadTemplate TEXT NOT NULL,
+				QoS INTEGER NOT NULL,
+				Retain INTEGER NOT NULL,
+				Kind TEXT NOT NULL,
+				RunAt DATETIME,
+				IntervalSeconds INTEGER NOT NULL,
+				CronExpression TEXT NOT NULL,
+				Enabled INTEGER NOT NULL,
+				NextRunAt DATETIME,
+				RunCount INTEGER NOT NULL,
+				CreationDate DATETIME NOT NULL,
+				FOREIGN KEY(BrokerId) REFERENCES Broker(ID)
+			);`,
+			`CREATE TABLE IF NOT EXISTS ScheduleRun (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				ScheduleId INTEGER NOT NULL,
+				ScheduledAt DATETIME NOT NULL,
+				Status TEXT NOT NULL,
+				Error TEXT NOT NULL,
+				Payload TEXT NOT NULL,
+				CreationDate DATETIME NOT NULL,
+				FOREIGN KEY(ScheduleId) REFERENCES Schedule(ID)
+			);`,
+			`CREATE INDEX IF NOT EXISTS IX_ScheduleRun_ScheduleId_ID ON ScheduleRun(ScheduleId, ID);`,
+		},
+	},
+	{
+		Version: 13,
+		Name: "benchmarks",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS Benchmark (
+				ID INTEGER PRIMARY KEY AUTOINCREMENT,
+				Name TEXT NOT NULL,
+				BrokerIp TEXT NOT NULL,
+				BrokerPort INTEGER NOT NULL,
+				Publishers INTEGER NOT NULL,
+				Subscribers INTEGER NOT NULL,
+				RatePerSecond REAL NOT NULL,
+				PayloadBytes INTEGER NOT NULL,
+				QoS INTEGER NOT NULL,
+				TopicPattern TEXT NOT NULL,
+				DurationSeconds INTEGER NOT NULL,
+				Status TEXT NOT NULL,
+				Error TEXT NOT NULL,
+				Sent INTEGER NOT NULL,
+				Received INTEGER NOT NULL,
+				Lost INTEGER NOT NULL,
+				LossPercent REAL NOT NULL,
+				ThroughputPerSecond REAL NOT NULL,
+				LatencyMinMs REAL NOT NULL,
+				LatencyMeanMs REAL NOT NULL,
+				LatencyP50Ms REAL NOT NULL,
+				LatencyP90Ms REAL NOT NULL,
+				LatencyP95Ms REAL NOT NULL,
+				LatencyP99Ms REAL NOT NULL,
+				LatencyMaxMs REAL NOT NULL,
+				FinishedAt DATETIME,
+				CreationDate DATETIME NOT NULL
+			);`,
+		},
+	},
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct MigrationState  | Table SchemaVersion  |
+// +------------------------+----------------------+
+// | Version int            | Version INTEGER      |
+// | Name string            | Name TEXT            |
+// | Applied bool           | <If Exists>          |
+// | Baselined bool         | Baselined BOOLEAN    |
+// | AppliedDate time.Time  | AppliedDate DATETIME |
+//
+// # Used in
+// - MigrationStatus()
+// - Migrate()
+//
+// # Author
+// - Polariusz
+type MigrationState struct {
+	Version int
+	Name string
+	Applied bool
+	Baselined bool
+	AppliedDate time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - Returns the newest version that the migrations of this build know about.
+//
+// # Author
+// - Polariusz
+func LatestSchemaVersion() int {
+	return migrationList[len(migrationList)-1].Version
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+// - name string : Name of the table
+//
+// # Returns
+// - true if the table `name` exists in the database.
+//
+// # Author
+// - Polariusz
+func tableExists(con *sql.DB, name string) (bool, error) {
+	var count int
+	if err := con.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count); err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return count > 0, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+//
+// # Description
+// - The function shall create table SchemaVersion if it does not exist.
+// - If table SchemaVersion did not exist, but table Broker does, the database was created before the migrations existed.
+//   In that case the function shall mark version 1 as applied without running it, which is called baselining.
+//
+// # Tables Affected
+// - SchemaVersion
+//   - CREATE
+//   - INSERT
+//
+// # Returns
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func ensureSchemaVersionTable(con *sql.DB) error {
+	versioned, err := tableExists(con, "SchemaVersion")
+	if err != nil {
+		return err
+	}
+	if versioned {
+		return nil
+	}
+
+	legacy, err := tableExists(con, "Broker")
+	if err != nil {
+		return err
+	}
+
+	tx, err := con.Begin()
+	if err != nil {
+		return fmt.Errorf("Error while beginning the transaction!\nErr: %s\n", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		CREATE TABLE SchemaVersion (
+			Version INTEGER PRIMARY KEY,
+			Name TEXT NOT NULL,
+			Baselined BOOLEAN NOT NULL,
+			AppliedDate DATETIME NOT NULL
+		);
+	`); err != nil {
+		return fmt.Errorf("Error while creating table SchemaVersion!\nErr: %s\n", err)
+	}
+
+	if legacy {
+		if _, err := tx.Exec("INSERT INTO SchemaVersion(Version, Name, Baselined, AppliedDate) VALUES(?, ?, ?, ?)", migrationList[0].Version, migrationList[0].Name, true, time.Now()); err != nil {
+			return fmt.Errorf("Error while baselining the database!\nErr: %s\n", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("Error while committing the transaction!\nErr: %s\n", err)
+	}
+
+	return nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+//
+// # Description
+// - The function shall return the state of every known migration, applied or not.
+// - The function does not write anything. An un-versioned database is reported as baselined at version 1, as `Migrate()` would baseline it.
+//
+// # Tables Affected
+// - SchemaVersion
+//   - SELECT
+//
+// # Returns
+// - A list of struct `MigrationState` in the order of the versions
+// - error when:
+//   - Skill Issues
+//   - The database has a version that this build does not know. That means the database was migrated by a newer build.
+//
+// # Author
+// - Polariusz
+func MigrationStatus(con *sql.DB) ([]MigrationState, error) {
+	applied := make(map[int]MigrationState)
+
+	versioned, err := tableExists(con, "SchemaVersion")
+	if err != nil {
+		return nil, err
+	}
+	if !versioned {
+		legacy, err := tableExists(con, "Broker")
+		if err != nil {
+			return nil, err
+		}
+		if legacy {
+			applied[migrationList[0].Version] = MigrationState{Version: migrationList[0].Version, Name: migrationList[0].Name, Applied: true, Baselined: true}
+		}
+	} else if err := selectSchemaVersions(con, applied); err != nil {
+		return nil, err
+	}
+
+	var stateList []MigrationState
+	for _, migration := range migrationList {
+		if state, ok := applied[migration.Version]; ok {
+			stateList = append(stateList, state)
+			delete(applied, migration.Version)
+		} else {
+			stateList = append(stateList, MigrationState{Version: migration.Version, Name: migration.Name})
+		}
+	}
+
+	for version := range applied {
+		return stateList, fmt.Errorf("Error: The database has schema version %d, but this build only knows up to version %d. Please use a newer build.\n", version, LatestSchemaVersion())
+	}
+
+	return stateList, nil
+}
+
+// # Author
+// - Polariusz
+func selectSchemaVersions(con *sql.DB, applied map[int]MigrationState) error {
+	rows, err := con.Query("SELECT Version, Name, Baselined, AppliedDate FROM SchemaVersion")
+	if err != nil {
+		return fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var state MigrationState
+		rows.Scan(&state.Version, &state.Name, &state.Baselined, &state.AppliedDate)
+		state.Applied = true
+		applied[state.Version] = state
+	}
+
+	return nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+// - dryRun bool : If true, nothing is executed and the pending migrations are only returned.
+//
+// # Description
+// - The function shall apply every not yet applied migration in the order of the versions.
+// - An un-versioned database is baselined first, see `ensureSchemaVersionTable()`.
+// - Every migration is applied in its own transaction, so a failing migration leaves the database at the previous version.
+//
+// # Tables Affected
+// - SchemaVersion
+//   - INSERT
+// - Every table touched by the migrations
+//
+// # Returns
+// - A list of struct `MigrationState` of the migrations that were (or with `dryRun`, would be) applied.
+// - error when:
+//   - Skill Issues
+//   - A migration statement has failed. The returned list holds the migrations applied before it.
+//
+// # Author
+// - Polariusz
+func Migrate(con *sql.DB, dryRun bool) ([]MigrationState, error) {
+	if !dryRun {
+		if err := ensureSchemaVersionTable(con); err != nil {
+			return nil, err
+		}
+	}
+
+	stateList, err := MigrationStatus(con)
+	if err != nil {
+		return nil, err
+	}
+
+	var appliedList []MigrationState
+	for i, state := range stateList {
+		if state.Applied {
+			continue
+		}
+		if dryRun {
+			appliedList = append(appliedList, state)
+			continue
+		}
+
+		if err := applyMigration(con, migrationList[i]); err != nil {
+			return appliedList, err
+		}
+		state.Applied = true
+		state.AppliedDate = time.Now()
+		appliedList = append(appliedList, state)
+	}
+
+	return appliedList, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall execute the statements of `migration` and record it in table SchemaVersion within one transaction.
+//
+// # Author
+// - Polariusz
+func applyMigration(con *sql.DB, migration Migration) error {
+	tx, err := con.Begin()
+	if err != nil {
+		return fmt.Errorf("Error while beginning the transaction!\nErr: %s\n", err)
+	}
+	defer tx.Rollback()
+
+	for _, statement := range migration.Statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("Migration %d (%s) failed!\nStatement:\n%s\nErr: %s\n", migration.Version, migration.Name, statement, err)
+		}
+	}
+
+	if _, err := tx.Exec("INSERT INTO SchemaVersion(Version, Name, Baselined, AppliedDate) VALUES(?, ?, ?, ?)", migration.Version, migration.Name, false, time.Now()); err != nil {
+		return fmt.Errorf("Error while recording migration %d!\nErr: %s\n", migration.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("Error while committing migration %d!\nErr: %s\n", migration.Version, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestMigrateMessageMetadata makes sure that messages stored before version 4
+// get their CreationDate as the moment they were received.
+func TestMigrateMessageMetadata(t *testing.T) {
+	con, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "old.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer con.Close()
+
+	if err := ensureSchemaVersionTable(con); err != nil {
+		t.Fatal(err)
+	}
+	for _, migration := range migrationList[:3] {
+		if err := applyMigration(con, migration); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	creationDate := time.Date(2025, 5, 29, 14, 30, 15, 123456789, time.FixedZone("CEST", 2*60*60))
+	if _, err := con.Exec("INSERT INTO Message(UserId, TopicId, BrokerId, QoS, Message, CreationDate) VALUES(1, 1, 1, 0, 'old', ?)", creationDate); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Migrate(con, false); err != nil {
+		t.Fatal(err)
+	}
+
+	var receivedNanos int64
+	if err := con.QueryRow("SELECT ReceivedNanos FROM Message").Scan(&receivedNanos); err != nil {
+		t.Fatal(err)
+	}
+	if want := creationDate.Truncate(time.Millisecond).UnixNano(); receivedNanos != want {
+		t.Errorf("ReceivedNanos = %d (%s), want %d (%s)", receivedNanos, time.Unix(0, receivedNanos).UTC(), want, creationDate.UTC())
+	}
+}
@@ -0,0 +1,554 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+/*                                       +---------------+                                       */
+/* --------------------------------------| RETENTIONRULE |-------------------------------------- */
+/*                                       +---------------+                                       */
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct InsertRetentionRule | Table RetentionRule         |
+// +----------------------------+-----------------------------+
+// |                            | ID INTEGER                  |
+// | BrokerId int               | BrokerId INTEGER            |
+// | TopicFilter string         | TopicFilter TEXT            |
+// | MaxAgeSeconds int          | MaxAgeSeconds INTEGER       |
+// | MaxMessagesPerTopic int    | MaxMessagesPerTopic INTEGER |
+// | MaxDatabaseBytes int64     | MaxDatabaseBytes INTEGER    |
+// | KeepFavourites bool        | KeepFavourites BOOLEAN      |
+// |                            | CreationDate DATETIME       |
+//
+// # Note
+// - BrokerId 0 means that the rule applies to all brokers.
+// - A limit of 0 means that the limit is not used.
+//
+// # Used in
+// - InsertNewRetentionRule()
+//
+// # Author
+// - Polariusz
+type InsertRetentionRule struct {
+	BrokerId int
+	TopicFilter string
+	MaxAgeSeconds int
+	MaxMessagesPerTopic int
+	MaxDatabaseBytes int64
+	KeepFavourites bool
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct SelectRetentionRule | Table RetentionRule         |
+// +----------------------------+-----------------------------+
+// | Id int                     | ID INTEGER                  |
+// | BrokerId int               | BrokerId INTEGER            |
+// | TopicFilter string         | TopicFilter TEXT            |
+// | MaxAgeSeconds int          | MaxAgeSeconds INTEGER       |
+// | MaxMessagesPerTopic int    | MaxMessagesPerTopic INTEGER |
+// | MaxDatabaseBytes int64     | MaxDatabaseBytes INTEGER    |
+// | KeepFavourites bool        | KeepFavourites BOOLEAN      |
+// | CreationDate time.Time     | CreationDate DATETIME       |
+//
+// # Used in
+// - SelectRetentionRules()
+//
+// # Author
+// - Polariusz
+type SelectRetentionRule struct {
+	Id int
+	BrokerId int
+	TopicFilter string
+	MaxAgeSeconds int
+	MaxMessagesPerTopic int
+	MaxDatabaseBytes int64
+	KeepFavourites bool
+	CreationDate time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB               : It's a connection to the database.
+// - rule InsertRetentionRule  : It's inserted into table `RetentionRule`
+//
+// # Tables Affected
+// - RetentionRule
+//   - INSERT
+//
+// # Returns
+// - int: [RetentionRule].[ID], -1 on error
+// - error when:
+//   - Skill Issues
+//   - Table RetentionRule does not exist
+//     - Run SetupDatabase() before this function.
+//
+// # Author
+// - Polariusz
+func InsertNewRetentionRule(con *sql.DB, rule InsertRetentionRule) (int, error) {
+	result, err := con.Exec(`
+		INSERT INTO RetentionRule(BrokerId, TopicFilter, MaxAgeSeconds, MaxMessagesPerTopic, MaxDatabaseBytes, KeepFavourites, CreationDate)
+		VALUES(?, ?, ?, ?, ?, ?, ?)
+	`, rule.BrokerId, rule.TopicFilter, rule.MaxAgeSeconds, rule.MaxMessagesPerTopic, rule.MaxDatabaseBytes, rule.KeepFavourites, time.Now())
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	ruleId, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return int(ruleId), nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+//
+// # Tables Affected
+// - RetentionRule
+//   - SELECT
+//
+// # Returns
+// - A list of all struct `SelectRetentionRule`
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectRetentionRules(con *sql.DB) ([]SelectRetentionRule, error) {
+	var ruleList []SelectRetentionRule
+
+	rows, err := con.Query(`
+		SELECT ID, BrokerId, TopicFilter, MaxAgeSeconds, MaxMessagesPerTopic, MaxDatabaseBytes, KeepFavourites, CreationDate
+		FROM RetentionRule
+		ORDER BY ID
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rule SelectRetentionRule
+		rows.Scan(&rule.Id, &rule.BrokerId, &rule.TopicFilter, &rule.MaxAgeSeconds, &rule.MaxMessagesPerTopic, &rule.MaxDatabaseBytes, &rule.KeepFavourites, &rule.CreationDate)
+		ruleList = append(ruleList, rule)
+	}
+
+	return ruleList, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+// - id int      : [RetentionRule].[ID]
+//
+// # Description
+// - The function shall delete the rule and the record of what it has deleted.
+//
+// # Tables Affected
+// - RetentionDeletion
+//   - DELETE
+// - RetentionRule
+//   - DELETE
+//
+// # Returns
+// - bool: false if there was no such rule
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteRetentionRule(con *sql.DB, id int) (bool, error) {
+	tx, err := con.Begin()
+	if err != nil {
+		return false, fmt.Errorf("Error while beginning the transaction!\nErr: %s\n", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM RetentionDeletion WHERE RuleId = ?", id); err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	result, err := tx.Exec("DELETE FROM RetentionRule WHERE ID = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("Error while committing the transaction!\nErr: %s\n", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+/*                                       +-------------------+                                       */
+/* --------------------------------------| RETENTIONDELETION |-------------------------------------- */
+/*                                       +-------------------+                                       */
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB   : It's a connection to the database.
+// - ruleId int    : [RetentionRule].[ID]
+// - reason string : Which limit of the rule deleted the messages
+// - deleted int64 : How many messages were deleted
+//
+// # Tables Affected
+// - RetentionDeletion
+//   - INSERT
+//
+// # Returns
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func InsertRetentionDeletion(con *sql.DB, ruleId int, reason string, deleted int64) error {
+	if _, err := con.Exec("INSERT INTO RetentionDeletion(RuleId, Reason, Deleted, CreationDate) VALUES(?, ?, ?, ?)", ruleId, reason, deleted, time.Now()); err != nil {
+		return fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct SelectRetentionReport | Table RetentionDeletion         |
+// +------------------------------+---------------------------------+
+// | RuleId int                   | RuleId INTEGER                  |
+// | Reason string                | Reason TEXT                     |
+// | Deleted int64                | SUM(Deleted)                    |
+// | Runs int                     | COUNT(*)                        |
+// | LastDeletion time.Time       | MAX(CreationDate)               |
+//
+// # Used in
+// - SelectRetentionReport()
+//
+// # Author
+// - Polariusz
+type SelectRetentionReport struct {
+	RuleId int
+	Reason string
+	Deleted int64
+	Runs int
+	LastDeletion time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+//
+// # Description
+// - The function shall return, per rule and per reason, how many messages were deleted in total and when the last deletion happened.
+//
+// # Tables Affected
+// - RetentionDeletion
+//   - SELECT
+//
+// # Returns
+// - A list of struct `SelectRetentionReport`
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectRetentionReports(con *sql.DB) ([]SelectRetentionReport, error) {
+	var reportList []SelectRetentionReport
+
+	rows, err := con.Query(`
+		SELECT RuleId, Reason, SUM(Deleted), COUNT(*), MAX(CreationDate)
+		FROM RetentionDeletion
+		GROUP BY RuleId, Reason
+		ORDER BY RuleId, Reason
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var report SelectRetentionReport
+		var lastDeletion string
+		rows.Scan(&report.RuleId, &report.Reason, &report.Deleted, &report.Runs, &lastDeletion)
+		report.LastDeletion = parseSqliteTime(lastDeletion)
+		reportList = append(reportList, report)
+	}
+
+	return reportList, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - Aggregates like MAX() lose the column type, so go-sqlite3 returns the stored text instead of a time.Time.
+//   This function parses the text in the formats that go-sqlite3 writes.
+//
+// # Author
+// - Polariusz
+func parseSqliteTime(value string) time.Time {
+	value = strings.TrimSuffix(value, "Z")
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999-07:00", "2006-01-02T15:04:05.999999999-07:00", "2006-01-02 15:04:05.999999999", "2006-01-02T15:04:05.999999999"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
+
+/*                                       +-------------------+                                       */
+/* --------------------------------------| MESSAGE RETENTION |-------------------------------------- */
+/*                                       +-------------------+                                       */
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB        : It's a connection to the database.
+// - brokerId int       : [Broker].[ID]
+// - topicId int        : [Topic].[ID]
+// - before time.Time   : Messages received before it are deleted
+// - limit int          : Deletes at most this many messages
+//
+// # Tables Affected
+// - Message
+//   - DELETE
+//
+// # Returns
+// - int64: How many messages were deleted
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteMessagesOlderThan(con *sql.DB, brokerId int, topicId int, before time.Time, limit int) (int64, error) {
+	result, err := con.Exec(`
+		DELETE FROM Message
+		WHERE ID IN (
+			SELECT ID
+			FROM Message
+			WHERE
+				TopicId = ?
+			AND
+				BrokerId = ?
+			AND
+				CreationDate < ?
+			ORDER BY ID
+			LIMIT ?
+		)
+	`, topicId, brokerId, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB  : It's a connection to the database.
+// - brokerId int : [Broker].[ID]
+// - topicId int  : [Topic].[ID]
+// - keep int     : How many of the newest messages are kept
+// - limit int    : Deletes at most this many messages
+//
+// # Description
+// - The function shall delete the oldest messages of the topic that come after the newest `keep` messages.
+//
+// # Tables Affected
+// - Message
+//   - DELETE
+//
+// # Returns
+// - int64: How many messages were deleted
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteMessagesBeyondCount(con *sql.DB, brokerId int, topicId int, keep int, limit int) (int64, error) {
+	result, err := con.Exec(`
+		DELETE FROM Message
+		WHERE ID IN (
+			SELECT ID
+			FROM Message
+			WHERE
+				TopicId = ?
+			AND
+				BrokerId = ?
+			ORDER BY ID DESC
+			LIMIT ? OFFSET ?
+		)
+	`, topicId, brokerId, limit, keep)
+	if err != nil {
+		return 0, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB      : It's a connection to the database.
+// - brokerId int     : [Broker].[ID]
+// - topicIds []int   : [Topic].[ID]s whose messages may be deleted
+// - limit int        : Deletes at most this many messages
+//
+// # Description
+// - The function shall delete the oldest messages across all topics from `topicIds`.
+//
+// # Tables Affected
+// - Message
+//   - DELETE
+//
+// # Returns
+// - int64: How many messages were deleted
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteOldestMessages(con *sql.DB, brokerId int, topicIds []int, limit int) (int64, error) {
+	if len(topicIds) == 0 {
+		return 0, nil
+	}
+
+	args := []any{brokerId}
+	for _, topicId := range topicIds {
+		args = append(args, topicId)
+	}
+	args = append(args, limit)
+
+	result, err := con.Exec(`
+		DELETE FROM Message
+		WHERE ID IN (
+			SELECT ID
+			FROM Message
+			WHERE
+				BrokerId = ?
+			AND
+				TopicId IN (?`+strings.Repeat(", ?", len(topicIds)-1)+`)
+			ORDER BY ID
+			LIMIT ?
+		)
+	`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+//
+// # Description
+// - The function shall return how many bytes of the database file are in use.
+// - Deleted rows leave free pages behind that are reused by SQLite, so these are not counted.
+//
+// # Returns
+// - int64: bytes in use
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectDatabaseSize(con *sql.DB) (int64, error) {
+	var pageCount, freelistCount, pageSize int64
+
+	if err := con.QueryRow("PRAGMA page_count").Scan(&pageCount); err != nil {
+		return 0, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	if err := con.QueryRow("PRAGMA freelist_count").Scan(&freelistCount); err != nil {
+		return 0, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	if err := con.QueryRow("PRAGMA page_size").Scan(&pageSize); err != nil {
+		return 0, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return (pageCount - freelistCount) * pageSize, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB  : It's a connection to the database.
+// - brokerId int : [Broker].[ID]
+//
+// # Description
+// - The function shall return the IDs of the topics that at least one user of the broker marked as favourite.
+//
+// # Tables Affected
+// - UserTopicFavourite
+//   - SELECT
+//
+// # Returns
+// - A set of [Topic].[ID]
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectFavouriteTopicIdsByBrokerId(con *sql.DB, brokerId int) (map[int]bool, error) {
+	favouriteTopicIds := make(map[int]bool)
+
+	rows, err := con.Query("SELECT DISTINCT TopicId FROM UserTopicFavourite WHERE BrokerId = ?", brokerId)
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var topicId int
+		rows.Scan(&topicId)
+		favouriteTopicIds[topicId] = true
+	}
+
+	return favouriteTopicIds, nil
+}
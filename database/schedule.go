@@ -0,0 +1,474 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+/*                                      +----------+                                      */
+/* -------------------------------------| SCHEDULE |------------------------------------- */
+/*                                      +----------+                                      */
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The kinds of a schedule. `once` publishes at RunAt, `interval` every IntervalSeconds and `cron` at the times of CronExpression.
+const (
+	SCHEDULE_ONCE = "once"
+	SCHEDULE_INTERVAL = "interval"
+	SCHEDULE_CRON = "cron"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct InsertSchedule      | Table Schedule             |
+// +----------------------------+----------------------------+
+// |                            | ID INTEGER                 |
+// | Name string                | Name TEXT                  |
+// | BrokerId int               | BrokerId INTEGER           |
+// | Topic string               | Topic TEXT                 |
+// | PayloadTemplate string     | PayloadTemplate TEXT       |
+// | QoS int                    | QoS INTEGER                |
+// | Retain bool                | Retain INTEGER             |
+// | Kind string                | Kind TEXT                  |
+// | RunAt *time.Time           | RunAt DATETIME             |
+// | IntervalSeconds int        | IntervalSeconds INTEGER    |
+// | CronExpression string      | CronExpression TEXT        |
+// |                            | Enabled INTEGER            |
+// | NextRunAt *time.Time       | NextRunAt DATETIME         |
+// |                            | RunCount INTEGER           |
+// |                            | CreationDate DATETIME      |
+//
+// # Note
+// - Kind is one of the SCHEDULE_* constants, only the field of its kind is used.
+// - A new schedule is Enabled.
+//
+// # Used in
+// - InsertNewSchedule()
+//
+// # Author
+// - Polariusz
+type InsertSchedule struct {
+	Name string
+	BrokerId int
+	Topic string
+	PayloadTemplate string
+	QoS int
+	Retain bool
+	Kind string
+	RunAt *time.Time
+	IntervalSeconds int
+	CronExpression string
+	NextRunAt *time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct SelectSchedule      | Table Schedule             |
+// +----------------------------+----------------------------+
+// | Id int                     | ID INTEGER                 |
+// | Name string                | Name TEXT                  |
+// | BrokerId int               | BrokerId INTEGER           |
+// | Topic string               | Topic TEXT                 |
+// | PayloadTemplate string     | PayloadTemplate TEXT       |
+// | QoS int                    | QoS INTEGER                |
+// | Retain bool                | Retain INTEGER             |
+// | Kind string                | Kind TEXT                  |
+// | RunAt *time.Time           | RunAt DATETIME             |
+// | IntervalSeconds int        | IntervalSeconds INTEGER    |
+// | CronExpression string      | CronExpression TEXT        |
+// | Enabled bool               | Enabled INTEGER            |
+// | NextRunAt *time.Time       | NextRunAt DATETIME         |
+// | RunCount int               | RunCount INTEGER           |
+// | CreationDate time.Time     | CreationDate DATETIME      |
+//
+// # Note
+// - NextRunAt is null when the schedule is disabled or a one-shot has run.
+//
+// # Author
+// - Polariusz
+type SelectSchedule struct {
+	Id int
+	Name string
+	BrokerId int
+	Topic string
+	PayloadTemplate string
+	QoS int
+	Retain bool
+	Kind string
+	RunAt *time.Time
+	IntervalSeconds int
+	CronExpression string
+	Enabled bool
+	NextRunAt *time.Time
+	RunCount int
+	CreationDate time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB             : It's a connection to the database.
+// - schedule InsertSchedule : It's inserted into table `Schedule`
+//
+// # Tables Affected
+// - Schedule
+//   - INSERT
+//
+// # Returns
+// - int: [Schedule].[ID], -1 on error
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func InsertNewSchedule(con *sql.DB, schedule InsertSchedule) (int, error) {
+	result, err := con.Exec(`
+		INSERT INTO Schedule(Name, BrokerId, Topic, PayloadTemplate, QoS, Retain, Kind, RunAt, IntervalSeconds, CronExpression, Enabled, NextRunAt, RunCount, CreationDate)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1, ?, 0, ?)
+	`, schedule.Name, schedule.BrokerId, schedule.Topic, schedule.PayloadTemplate, schedule.QoS, schedule.Retain, schedule.Kind, schedule.RunAt, schedule.IntervalSeconds, schedule.CronExpression, schedule.NextRunAt, time.Now())
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	scheduleId, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return int(scheduleId), nil
+}
+
+// # Author
+// - Polariusz
+func scanSelectSchedule(scan func(dest ...any) error) (SelectSchedule, error) {
+	var schedule SelectSchedule
+	var runAt sql.NullTime
+	var nextRunAt sql.NullTime
+	err := scan(&schedule.Id, &schedule.Name, &schedule.BrokerId, &schedule.Topic, &schedule.PayloadTemplate, &schedule.QoS, &schedule.Retain, &schedule.Kind, &runAt, &schedule.IntervalSeconds, &schedule.CronExpression, &schedule.Enabled, &nextRunAt, &schedule.RunCount, &schedule.CreationDate)
+	if runAt.Valid {
+		schedule.RunAt = &runAt.Time
+	}
+	if nextRunAt.Valid {
+		schedule.NextRunAt = &nextRunAt.Time
+	}
+	return schedule, err
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+//
+// # Tables Affected
+// - Schedule
+//   - SELECT
+//
+// # Returns
+// - A list of struct `SelectSchedule`, the oldest first
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectSchedules(con *sql.DB) ([]SelectSchedule, error) {
+	var scheduleList []SelectSchedule
+
+	rows, err := con.Query(`
+		SELECT ID, Name, BrokerId, Topic, PayloadTemplate, QoS, Retain, Kind, RunAt, IntervalSeconds, CronExpression, Enabled, NextRunAt, RunCount, CreationDate
+		FROM Schedule
+		ORDER BY ID
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		schedule, err := scanSelectSchedule(rows.Scan)
+		if err != nil {
+			return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+		}
+		scheduleList = append(scheduleList, schedule)
+	}
+
+	return scheduleList, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB          : It's a connection to the database.
+// - id int               : [Schedule].[ID]
+// - enabled bool         : Whether the schedule publishes, also after a restart of the server.
+// - nextRunAt *time.Time : When it publishes next, nil when it is disabled.
+//
+// # Tables Affected
+// - Schedule
+//   - UPDATE
+//
+// # Returns
+// - bool: false if there was no such schedule
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func UpdateScheduleEnabled(con *sql.DB, id int, enabled bool, nextRunAt *time.Time) (bool, error) {
+	result, err := con.Exec("UPDATE Schedule SET Enabled = ?, NextRunAt = ? WHERE ID = ?", enabled, nextRunAt, id)
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+// - id int      : [Schedule].[ID]
+//
+// # Description
+// - The function shall delete the schedule with its runs in one transaction.
+//
+// # Tables Affected
+// - ScheduleRun, Schedule
+//   - DELETE
+//
+// # Returns
+// - bool: false if there was no such schedule
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteSchedule(con *sql.DB, id int) (bool, error) {
+	tx, err := con.Begin()
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM ScheduleRun WHERE ScheduleId = ?", id); err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	result, err := tx.Exec("DELETE FROM Schedule WHERE ID = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+/*                                     +-------------+                                     */
+/* ------------------------------------| SCHEDULERUN |------------------------------------ */
+/*                                     +-------------+                                     */
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The outcomes of a run. `skipped` means that the MQTT-Client was not connected to the broker of the schedule.
+const (
+	SCHEDULE_RUN_PUBLISHED = "published"
+	SCHEDULE_RUN_FAILED = "failed"
+	SCHEDULE_RUN_SKIPPED = "skipped"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct InsertScheduleRun | Table ScheduleRun     |
+// +--------------------------+-----------------------+
+// |                          | ID INTEGER            |
+// | ScheduleId int           | ScheduleId INTEGER    |
+// | ScheduledAt time.Time    | ScheduledAt DATETIME  |
+// | Status string            | Status TEXT           |
+// | Error string             | Error TEXT            |
+// | Payload string           | Payload TEXT          |
+// |                          | CreationDate DATETIME |
+//
+// # Note
+// - ScheduledAt is when the run was due, CreationDate when it happened.
+//
+// # Used in
+// - InsertNewScheduleRun()
+//
+// # Author
+// - Polariusz
+type InsertScheduleRun struct {
+	ScheduleId int
+	ScheduledAt time.Time
+	Status string
+	Error string
+	Payload string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct SelectScheduleRun | Table ScheduleRun     |
+// +--------------------------+-----------------------+
+// | Id int                   | ID INTEGER            |
+// | ScheduleId int           | ScheduleId INTEGER    |
+// | ScheduledAt time.Time    | ScheduledAt DATETIME  |
+// | Status string            | Status TEXT           |
+// | Error string             | Error TEXT            |
+// | Payload string           | Payload TEXT          |
+// | CreationDate time.Time   | CreationDate DATETIME |
+//
+// # Author
+// - Polariusz
+type SelectScheduleRun struct {
+	Id int
+	ScheduleId int
+	ScheduledAt time.Time
+	Status string
+	Error string
+	Payload string
+	CreationDate time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB           : It's a connection to the database.
+// - run InsertScheduleRun : It's appended to table `ScheduleRun`
+// - nextRunAt *time.Time  : When the schedule publishes next, nil when it is done.
+//
+// # Description
+// - The function shall record the run and move the schedule on to its next run in one transaction, so a restart neither repeats nor loses it.
+// - A schedule without a next run is disabled. A schedule that was disabled in the meantime stays disabled.
+// - Nothing is recorded if the schedule was deleted in the meantime.
+//
+// # Tables Affected
+// - ScheduleRun
+//   - INSERT
+// - Schedule
+//   - UPDATE
+//
+// # Returns
+// - int: [ScheduleRun].[ID], -1 on error or if there is no such schedule
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func InsertNewScheduleRun(con *sql.DB, run InsertScheduleRun, nextRunAt *time.Time) (int, error) {
+	tx, err := con.Begin()
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer tx.Rollback()
+
+	updated, err := tx.Exec(`
+		UPDATE Schedule
+		SET RunCount = RunCount + 1, NextRunAt = CASE WHEN Enabled = 1 THEN ? END, Enabled = Enabled AND ?
+		WHERE ID = ?
+	`, nextRunAt, nextRunAt != nil, run.ScheduleId)
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	if affected, _ := updated.RowsAffected(); affected == 0 {
+		return -1, nil
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO ScheduleRun(ScheduleId, ScheduledAt, Status, Error, Payload, CreationDate)
+		VALUES(?, ?, ?, ?, ?, ?)
+	`, run.ScheduleId, run.ScheduledAt, run.Status, run.Error, run.Payload, time.Now())
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	runId, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return int(runId), nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB     : It's a connection to the database.
+// - scheduleId int  : [Schedule].[ID]
+// - status string   : Only the runs with this status, all if empty.
+// - beforeId int    : Only the runs older than this one, all if 0.
+// - limit int       : The most runs that are selected.
+//
+// # Tables Affected
+// - ScheduleRun
+//   - SELECT
+//
+// # Returns
+// - A list of struct `SelectScheduleRun`, the newest first
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectScheduleRuns(con *sql.DB, scheduleId int, status string, beforeId int, limit int) ([]SelectScheduleRun, error) {
+	var runList []SelectScheduleRun
+
+	rows, err := con.Query(`
+		SELECT ID, ScheduleId, ScheduledAt, Status, Error, Payload, CreationDate
+		FROM ScheduleRun
+		WHERE ScheduleId = ? AND (? = '' OR Status = ?) AND (? = 0 OR ID < ?)
+		ORDER BY ID DESC
+		LIMIT ?
+	`, scheduleId, status, status, beforeId, beforeId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var run SelectScheduleRun
+		rows.Scan(&run.Id, &run.ScheduleId, &run.ScheduledAt, &run.Status, &run.Error, &run.Payload, &run.CreationDate)
+		runList = append(runList, run)
+	}
+
+	return runList, nil
+}
@@ -0,0 +1,229 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Retention, cascading deletes and duplicate counts |
+//
+// # Description
+// - The interface covers everything the handlers need from the brokers, users, topics, subscriptions, messages and favourites.
+// - Every method behaves like the function of the same name in database.go, retention.go, delete.go or dedup.go, without the `con` argument.
+// - The rules (retention, deduplication, roles, webhooks, alerts, schedules) are not part of it, they always stay in the SQLite database.
+//   DeleteBrokerCascade() of a MemoryStore therefore only deletes the data of the broker, see DeleteBrokerRulesCascade() for the rest.
+//
+// # Implementations
+// - SqliteStore : Uses the tables from the SQLite database.
+// - MemoryStore : Keeps everything in maps and slices, nothing is written anywhere.
+//
+// # Author
+// - Polariusz
+type Store interface {
+	InsertNewBroker(broker InsertBroker) (int, error)
+	SelectBrokerList() ([]SelectBroker, error)
+	SelectBrokerByIpAndPort(ip string, port int) (SelectBroker, error)
+
+	InsertNewUser(user InsertUser) (int, error)
+	SelectUserById(id int) (SelectUser, error)
+	SelectUsersByClientId(clientId string) ([]SelectUser, error)
+	SelectUserByClientIdAndBrokerId(clientId string, brokerId int) (SelectUser, error)
+
+	InsertNewTopic(topic InsertTopic) (int, error)
+	SelectTopicsByBrokerId(brokerId int) ([]SelectTopic, error)
+	SelectTopicIdByBrokerIdAndTopic(brokerId int, topic string) (int, error)
+	SelectTopicsByBrokerIdAndUserId(brokerId int, userId int) ([]SelectTopicSubscribed, error)
+	DeleteTopic(topicId int) error
+
+	SelectSubscribedTopics(brokerId int, userId int) ([]SelectUserTopicSubscribed, error)
+	SubscribeTopic(brokerId int, userId int, topicId int) error
+	UnsubscribeTopic(brokerId int, userId int, topicId int) error
+
+	InsertNewMessage(message InsertMessage) error
+	InsertNewMessages(messageList []InsertMessage) error
+	SelectMessagesByTopicIdAndBrokerId(topicId int, brokerId int, hideDuplicates bool) ([]SelectMessage, error)
+	SelectMessagesByTopicIdBrokerIdAndIndex(topicId int, brokerId int, index int, hideDuplicates bool) ([]SelectMessage, error)
+	SelectMessagesByBrokerIdTopicIdAndDatetime(brokerId int, topicId int, timeFrom time.Time, hideDuplicates bool) ([]SelectMessage, error)
+	SelectMessagesByBrokerIdAndTimeRange(brokerId int, timeFrom time.Time, timeTo time.Time) ([]SelectTopicMessage, error)
+	SelectDuplicateCounts(brokerId int) ([]SelectDuplicateCount, error)
+
+	SelectFavouriteTopicsByBrokerIdAndUserId(brokerId int, userId int) ([]SelectFavTopic, error)
+	SelectFavouriteTopicIdsByBrokerId(brokerId int) (map[int]bool, error)
+	InsertFavouriteTopic(brokerId int, userId int, topicId int) error
+	DeleteFavouriteTopic(id int) error
+
+	DeleteMessagesOlderThan(brokerId int, topicId int, before time.Time, limit int) (int64, error)
+	DeleteMessagesBeyondCount(brokerId int, topicId int, keep int, limit int) (int64, error)
+	DeleteOldestMessages(brokerId int, topicIds []int, limit int) (int64, error)
+	SelectDatabaseSize() (int64, error)
+
+	DeleteBrokerCascade(brokerId int, dryRun bool) (DeleteCounts, error)
+	DeleteUserCascade(userId int, dryRun bool) (DeleteCounts, error)
+	DeleteTopicsCascade(topicIds []int, dryRun bool) (DeleteCounts, error)
+	DeleteMessagesByFilter(filter MessageFilter, dryRun bool) (DeleteCounts, error)
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The SQLite implementation of the interface `Store`. The methods call the functions from database.go with the connection `con`.
+// - Features that only exist in SQLite, like the migrations and the retention rules, still take the connection directly, see Con().
+//
+// # Author
+// - Polariusz
+type SqliteStore struct {
+	con *sql.DB
+}
+
+// # Author
+// - Polariusz
+func NewSqliteStore(con *sql.DB) *SqliteStore {
+	return &SqliteStore{con: con}
+}
+
+// # Author
+// - Polariusz
+func (store *SqliteStore) Con() *sql.DB {
+	return store.con
+}
+
+func (store *SqliteStore) InsertNewBroker(broker InsertBroker) (int, error) {
+	return InsertNewBroker(store.con, broker)
+}
+
+func (store *SqliteStore) SelectBrokerList() ([]SelectBroker, error) {
+	return SelectBrokerList(store.con)
+}
+
+func (store *SqliteStore) SelectBrokerByIpAndPort(ip string, port int) (SelectBroker, error) {
+	return SelectBrokerByIpAndPort(store.con, ip, port)
+}
+
+func (store *SqliteStore) InsertNewUser(user InsertUser) (int, error) {
+	return InsertNewUser(store.con, user)
+}
+
+func (store *SqliteStore) SelectUserById(id int) (SelectUser, error) {
+	return SelectUserById(store.con, id)
+}
+
+func (store *SqliteStore) SelectUsersByClientId(clientId string) ([]SelectUser, error) {
+	return SelectUsersByClientId(store.con, clientId)
+}
+
+func (store *SqliteStore) SelectUserByClientIdAndBrokerId(clientId string, brokerId int) (SelectUser, error) {
+	return SelectUserByClientIdAndBrokerId(store.con, clientId, brokerId)
+}
+
+func (store *SqliteStore) InsertNewTopic(topic InsertTopic) (int, error) {
+	return InsertNewTopic(store.con, topic)
+}
+
+func (store *SqliteStore) SelectTopicsByBrokerId(brokerId int) ([]SelectTopic, error) {
+	return SelectTopicsByBrokerId(store.con, brokerId)
+}
+
+func (store *SqliteStore) SelectTopicIdByBrokerIdAndTopic(brokerId int, topic string) (int, error) {
+	return SelectTopicIdByBrokerIdAndTopic(store.con, brokerId, topic)
+}
+
+func (store *SqliteStore) SelectTopicsByBrokerIdAndUserId(brokerId int, userId int) ([]SelectTopicSubscribed, error) {
+	return SelectTopicsByBrokerIdAndUserId(store.con, brokerId, userId)
+}
+
+func (store *SqliteStore) DeleteTopic(topicId int) error {
+	return DeleteTopic(store.con, topicId)
+}
+
+func (store *SqliteStore) SelectSubscribedTopics(brokerId int, userId int) ([]SelectUserTopicSubscribed, error) {
+	return SelectSubscribedTopics(store.con, brokerId, userId)
+}
+
+func (store *SqliteStore) SubscribeTopic(brokerId int, userId int, topicId int) error {
+	return SubscribeTopic(store.con, brokerId, userId, topicId)
+}
+
+func (store *SqliteStore) UnsubscribeTopic(brokerId int, userId int, topicId int) error {
+	return UnsubscribeTopic(store.con, brokerId, userId, topicId)
+}
+
+func (store *SqliteStore) InsertNewMessage(message InsertMessage) error {
+	return InsertNewMessage(store.con, message)
+}
+
+func (store *SqliteStore) InsertNewMessages(messageList []InsertMessage) error {
+	return InsertNewMessages(store.con, messageList)
+}
+
+func (store *SqliteStore) SelectMessagesByTopicIdAndBrokerId(topicId int, brokerId int, hideDuplicates bool) ([]SelectMessage, error) {
+	return SelectMessagesByTopicIdAndBrokerId(store.con, topicId, brokerId, hideDuplicates)
+}
+
+func (store *SqliteStore) SelectMessagesByTopicIdBrokerIdAndIndex(topicId int, brokerId int, index int, hideDuplicates bool) ([]SelectMessage, error) {
+	return SelectMessagesByTopicIdBrokerIdAndIndex(store.con, topicId, brokerId, index, hideDuplicates)
+}
+
+func (store *SqliteStore) SelectMessagesByBrokerIdTopicIdAndDatetime(brokerId int, topicId int, timeFrom time.Time, hideDuplicates bool) ([]SelectMessage, error) {
+	return SelectMessagesByBrokerIdTopicIdAndDatetime(store.con, brokerId, topicId, timeFrom, hideDuplicates)
+}
+
+func (store *SqliteStore) SelectMessagesByBrokerIdAndTimeRange(brokerId int, timeFrom time.Time, timeTo time.Time) ([]SelectTopicMessage, error) {
+	return SelectMessagesByBrokerIdAndTimeRange(store.con, brokerId, timeFrom, timeTo)
+}
+
+func (store *SqliteStore) SelectFavouriteTopicsByBrokerIdAndUserId(brokerId int, userId int) ([]SelectFavTopic, error) {
+	return SelectFavouriteTopicsByBrokerIdAndUserId(store.con, brokerId, userId)
+}
+
+func (store *SqliteStore) InsertFavouriteTopic(brokerId int, userId int, topicId int) error {
+	return InsertFavouriteTopic(store.con, brokerId, userId, topicId)
+}
+
+func (store *SqliteStore) DeleteFavouriteTopic(id int) error {
+	return DeleteFavouriteTopic(store.con, id)
+}
+
+func (store *SqliteStore) SelectDuplicateCounts(brokerId int) ([]SelectDuplicateCount, error) {
+	return SelectDuplicateCounts(store.con, brokerId)
+}
+
+func (store *SqliteStore) SelectFavouriteTopicIdsByBrokerId(brokerId int) (map[int]bool, error) {
+	return SelectFavouriteTopicIdsByBrokerId(store.con, brokerId)
+}
+
+func (store *SqliteStore) DeleteMessagesOlderThan(brokerId int, topicId int, before time.Time, limit int) (int64, error) {
+	return DeleteMessagesOlderThan(store.con, brokerId, topicId, before, limit)
+}
+
+func (store *SqliteStore) DeleteMessagesBeyondCount(brokerId int, topicId int, keep int, limit int) (int64, error) {
+	return DeleteMessagesBeyondCount(store.con, brokerId, topicId, keep, limit)
+}
+
+func (store *SqliteStore) DeleteOldestMessages(brokerId int, topicIds []int, limit int) (int64, error) {
+	return DeleteOldestMessages(store.con, brokerId, topicIds, limit)
+}
+
+func (store *SqliteStore) SelectDatabaseSize() (int64, error) {
+	return SelectDatabaseSize(store.con)
+}
+
+func (store *SqliteStore) DeleteBrokerCascade(brokerId int, dryRun bool) (DeleteCounts, error) {
+	return DeleteBrokerCascade(store.con, brokerId, dryRun)
+}
+
+func (store *SqliteStore) DeleteUserCascade(userId int, dryRun bool) (DeleteCounts, error) {
+	return DeleteUserCascade(store.con, userId, dryRun)
+}
+
+func (store *SqliteStore) DeleteTopicsCascade(topicIds []int, dryRun bool) (DeleteCounts, error) {
+	return DeleteTopicsCascade(store.con, topicIds, dryRun)
+}
+
+func (store *SqliteStore) DeleteMessagesByFilter(filter MessageFilter, dryRun bool) (DeleteCounts, error) {
+	return DeleteMessagesByFilter(store.con, filter, dryRun)
+}
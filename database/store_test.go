@@ -0,0 +1,248 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// newTestStores returns a migrated SqliteStore and an empty MemoryStore.
+func newTestStores(t *testing.T) map[string]Store {
+	t.Helper()
+
+	con, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "store.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { con.Close() })
+
+	if err := SetupDatabase(con); err != nil {
+		t.Fatal(err)
+	}
+
+	return map[string]Store{
+		"sqlite": NewSqliteStore(con),
+		"memory": NewMemoryStore(),
+	}
+}
+
+// storeScenario runs the same calls against a store and returns every result
+// with the creation dates removed, so that the stores can be compared.
+func storeScenario(t *testing.T, store Store) []any {
+	t.Helper()
+	var results []any
+	record := func(value any, err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, stripDates(value))
+	}
+	recordError := func(value any, err error) {
+		results = append(results, stripDates(value), err != nil)
+	}
+
+	brokerId, err := store.InsertNewBroker(InsertBroker{Ip: "localhost", Port: 1883})
+	record(brokerId, err)
+	record(store.InsertNewBroker(InsertBroker{Ip: "localhost", Port: 1883}))
+	record(store.InsertNewBroker(InsertBroker{Ip: "example.org", Port: 8883}))
+	record(store.SelectBrokerList())
+	record(store.SelectBrokerByIpAndPort("example.org", 8883))
+
+	userId, err := store.InsertNewUser(InsertUser{BrokerId: brokerId, ClientId: "explorer", Username: "u", Password: "p"})
+	record(userId, err)
+	record(store.InsertNewUser(InsertUser{BrokerId: brokerId, ClientId: "explorer", Username: "u", Password: "p"}))
+	outsiderId, err := store.InsertNewUser(InsertUser{BrokerId: brokerId, ClientId: "sensor", Outsider: true})
+	record(outsiderId, err)
+	record(store.SelectUserById(userId))
+	recordError(store.SelectUserById(99))
+	record(store.SelectUsersByClientId("sensor"))
+	recordError(store.SelectUsersByClientId("nobody"))
+	record(store.SelectUserByClientIdAndBrokerId("explorer", brokerId))
+	recordError(store.SelectUserByClientIdAndBrokerId("explorer", 99))
+
+	temperatureId, err := store.InsertNewTopic(InsertTopic{BrokerId: brokerId, Topic: "plant/temperature"})
+	record(temperatureId, err)
+	record(store.InsertNewTopic(InsertTopic{BrokerId: brokerId, Topic: "plant/temperature"}))
+	humidityId, err := store.InsertNewTopic(InsertTopic{BrokerId: brokerId, Topic: "plant/humidity"})
+	record(humidityId, err)
+	record(store.SelectTopicIdByBrokerIdAndTopic(brokerId, "plant/humidity"))
+	record(store.SelectTopicIdByBrokerIdAndTopic(brokerId, "plant/pressure"))
+
+	record(nil, store.SubscribeTopic(brokerId, userId, temperatureId))
+	record(nil, store.SubscribeTopic(brokerId, userId, humidityId))
+	record(nil, store.UnsubscribeTopic(brokerId, userId, humidityId))
+	record(store.SelectSubscribedTopics(brokerId, userId))
+	// The order of the topics is not defined, SQLite returns them in the order of the index.
+	topicList, err := store.SelectTopicsByBrokerIdAndUserId(brokerId, userId)
+	sort.Slice(topicList, func(i, j int) bool { return topicList[i].Id < topicList[j].Id })
+	record(topicList, err)
+
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	record(nil, store.InsertNewMessage(InsertMessage{UserId: outsiderId, TopicId: temperatureId, BrokerId: brokerId, QoS: 1, Message: "21.5", CreationDate: start}))
+	var messageList []InsertMessage
+	for i := 1; i <= LIMIT_MESSAGES+10; i++ {
+		messageList = append(messageList, InsertMessage{UserId: outsiderId, TopicId: temperatureId, BrokerId: brokerId, Message: "22", CreationDate: start.Add(time.Duration(i) * time.Second)})
+	}
+	messageList = append(messageList, InsertMessage{UserId: userId, TopicId: humidityId, BrokerId: brokerId, Message: "40", CreationDate: start.Add(time.Hour), Retained: true, Duplicate: true, PacketId: 7, PayloadSize: 2, ReceivedAt: start.Add(time.Hour + time.Nanosecond), EnvelopeDate: start.Add(59 * time.Minute), IsDuplicate: true})
+	record(nil, store.InsertNewMessages(messageList))
+
+	record(store.SelectMessagesByTopicIdAndBrokerId(humidityId, brokerId, false))
+	record(store.SelectMessagesByTopicIdAndBrokerId(humidityId, brokerId, true))
+	record(store.SelectMessagesByTopicIdBrokerIdAndIndex(temperatureId, brokerId, 1, false))
+	record(store.SelectMessagesByBrokerIdTopicIdAndDatetime(brokerId, temperatureId, start.Add(505*time.Second), false))
+	record(store.SelectMessagesByBrokerIdAndTimeRange(brokerId, start.Add(509*time.Second), start.Add(time.Hour)))
+	page, err := store.SelectMessagesByTopicIdBrokerIdAndIndex(temperatureId, brokerId, 0, false)
+	record(len(page), err)
+
+	record(nil, store.InsertFavouriteTopic(brokerId, userId, humidityId))
+	favTopicList, err := store.SelectFavouriteTopicsByBrokerIdAndUserId(brokerId, userId)
+	record(favTopicList, err)
+	record(nil, store.DeleteFavouriteTopic(favTopicList[0].Id))
+	record(store.SelectFavouriteTopicsByBrokerIdAndUserId(brokerId, userId))
+
+	record(nil, store.DeleteTopic(humidityId))
+	record(store.SelectTopicsByBrokerId(brokerId))
+
+	record(nil, store.InsertFavouriteTopic(brokerId, outsiderId, temperatureId))
+	record(store.SelectFavouriteTopicIdsByBrokerId(brokerId))
+	record(nil, store.InsertNewMessage(InsertMessage{UserId: outsiderId, TopicId: temperatureId, BrokerId: brokerId, Message: "22", CreationDate: start.Add(2 * time.Hour), IsDuplicate: true}))
+	record(store.SelectDuplicateCounts(0))
+	record(store.SelectDuplicateCounts(99))
+
+	record(store.DeleteMessagesOlderThan(brokerId, temperatureId, start.Add(5*time.Second), 3))
+	record(store.DeleteMessagesBeyondCount(brokerId, temperatureId, 10, 5))
+	record(store.DeleteOldestMessages(brokerId, []int{temperatureId}, 2))
+	record(store.SelectMessagesByTopicIdAndBrokerId(temperatureId, brokerId, false))
+
+	record(store.DeleteMessagesByFilter(MessageFilter{BrokerId: brokerId, After: start.Add(2 * time.Hour)}, true))
+	record(store.DeleteMessagesByFilter(MessageFilter{TopicIds: []int{temperatureId}, OnlyDuplicates: true}, false))
+	recordError(store.DeleteMessagesByFilter(MessageFilter{}, false))
+	record(store.DeleteTopicsCascade([]int{temperatureId}, true))
+	record(store.DeleteUserCascade(outsiderId, true))
+	record(store.DeleteUserCascade(userId, false))
+	record(store.DeleteBrokerCascade(brokerId, true))
+	record(store.DeleteBrokerCascade(brokerId, false))
+	record(store.SelectBrokerList())
+	record(store.SelectMessagesByBrokerIdAndTimeRange(brokerId, time.Time{}, time.Time{}))
+
+	return results
+}
+
+// stripDates zeroes the CreationDate of rows whose date is set by the store.
+// Message dates are given by the scenario and are kept.
+func stripDates(value any) any {
+	strip := func(item reflect.Value) {
+		if _, isMessage := item.Interface().(SelectMessage); isMessage {
+			return
+		}
+		if _, isMessage := item.Interface().(SelectTopicMessage); isMessage {
+			return
+		}
+		if field := item.FieldByName("CreationDate"); field.IsValid() {
+			field.Set(reflect.ValueOf(time.Time{}))
+		}
+	}
+
+	if value == nil {
+		return nil
+	}
+	copied := reflect.New(reflect.TypeOf(value)).Elem()
+	copied.Set(reflect.ValueOf(value))
+	switch copied.Kind() {
+	case reflect.Struct:
+		strip(copied)
+	case reflect.Slice:
+		duplicate := reflect.MakeSlice(copied.Type(), copied.Len(), copied.Len())
+		reflect.Copy(duplicate, copied)
+		for i := 0; i < duplicate.Len(); i++ {
+			if duplicate.Index(i).Kind() == reflect.Struct {
+				strip(duplicate.Index(i))
+			}
+		}
+		copied = duplicate
+	}
+	return copied.Interface()
+}
+
+// TestMemoryStoreMatchesSqliteStore makes sure that the MemoryStore can stand
+// in for the SqliteStore: both must return the same rows for the same calls.
+func TestMemoryStoreMatchesSqliteStore(t *testing.T) {
+	stores := newTestStores(t)
+
+	sqliteResults := storeScenario(t, stores["sqlite"])
+	memoryResults := storeScenario(t, stores["memory"])
+
+	if len(sqliteResults) != len(memoryResults) {
+		t.Fatalf("sqlite returned %d results, memory %d", len(sqliteResults), len(memoryResults))
+	}
+	for i := range sqliteResults {
+		if !reflect.DeepEqual(normaliseTimes(sqliteResults[i]), normaliseTimes(memoryResults[i])) {
+			t.Errorf("result %d differs\nsqlite: %+v\nmemory: %+v", i, sqliteResults[i], memoryResults[i])
+		}
+	}
+}
+
+// normaliseTimes converts the message dates to UTC, SQLite returns them
+// without the monotonic clock and location of the inserted value.
+func normaliseTimes(value any) any {
+	switch list := value.(type) {
+	case []SelectMessage:
+		for i := range list {
+			list[i].CreationDate = list[i].CreationDate.UTC()
+			list[i].ReceivedAt = list[i].ReceivedAt.UTC()
+			if list[i].EnvelopeDate != nil {
+				envelopeDate := list[i].EnvelopeDate.UTC()
+				list[i].EnvelopeDate = &envelopeDate
+			}
+		}
+	case []SelectTopicMessage:
+		for i := range list {
+			list[i].CreationDate = list[i].CreationDate.UTC()
+		}
+	case []SelectDuplicateCount:
+		for i := range list {
+			list[i].LastDuplicate = list[i].LastDuplicate.UTC()
+		}
+	}
+	return value
+}
+
+// TestHideDuplicatesBeforePaging makes sure that the duplicates are left out
+// before a page is cut, so that hiding them does not make the pages short.
+func TestHideDuplicatesBeforePaging(t *testing.T) {
+	for name, store := range newTestStores(t) {
+		brokerId, _ := store.InsertNewBroker(InsertBroker{Ip: "localhost", Port: 1883})
+		userId, _ := store.InsertNewUser(InsertUser{BrokerId: brokerId, ClientId: "sensor", Outsider: true})
+		topicId, _ := store.InsertNewTopic(InsertTopic{BrokerId: brokerId, Topic: "plant/temperature"})
+
+		start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+		var messageList []InsertMessage
+		for i := 0; i < 2*LIMIT_MESSAGES; i++ {
+			messageList = append(messageList, InsertMessage{UserId: userId, TopicId: topicId, BrokerId: brokerId, Message: "22", CreationDate: start.Add(time.Duration(i) * time.Second), IsDuplicate: i%2 == 1})
+		}
+		if err := store.InsertNewMessages(messageList); err != nil {
+			t.Fatal(err)
+		}
+
+		for index, want := range []int{LIMIT_MESSAGES, 0} {
+			page, err := store.SelectMessagesByTopicIdBrokerIdAndIndex(topicId, brokerId, index, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page) != want {
+				t.Errorf("%s: page %d has %d messages, want %d", name, index, len(page), want)
+			}
+			for _, message := range page {
+				if message.IsDuplicate {
+					t.Errorf("%s: page %d holds the duplicate %d", name, index, message.Id)
+				}
+			}
+		}
+		if newMessageList, _ := store.SelectMessagesByBrokerIdTopicIdAndDatetime(brokerId, topicId, time.Time{}, true); len(newMessageList) != LIMIT_MESSAGES {
+			t.Errorf("%s: %d messages without duplicates, want %d", name, len(newMessageList), LIMIT_MESSAGES)
+		}
+	}
+}
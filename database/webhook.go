@@ -0,0 +1,640 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+/*                                       +---------+                                       */
+/* --------------------------------------| WEBHOOK |-------------------------------------- */
+/*                                       +---------+                                       */
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct InsertWebhook       | Table Webhook              |
+// +----------------------------+----------------------------+
+// |                            | ID INTEGER                 |
+// | Name string                | Name TEXT                  |
+// | BrokerId int               | BrokerId INTEGER           |
+// | TopicFilter string         | TopicFilter TEXT           |
+// | ConditionPath string       | ConditionPath TEXT         |
+// | ConditionOperator string   | ConditionOperator TEXT     |
+// | ConditionValue string      | ConditionValue TEXT        |
+// | Url string                 | Url TEXT                   |
+// | Method string              | Method TEXT                |
+// | Headers map[string]string  | Headers TEXT               |
+// | BodyTemplate string        | BodyTemplate TEXT          |
+// | MaxAttempts int            | MaxAttempts INTEGER        |
+// |                            | CreationDate DATETIME      |
+//
+// # Note
+// - BrokerId 0 means that the webhook applies to all brokers.
+// - The condition is checked against the message, an empty ConditionOperator means that every message matches. The server checks them.
+// - Headers are stored as a JSON object.
+//
+// # Used in
+// - InsertNewWebhook()
+//
+// # Author
+// - Polariusz
+type InsertWebhook struct {
+	Name string
+	BrokerId int
+	TopicFilter string
+	ConditionPath string
+	ConditionOperator string
+	ConditionValue string
+	Url string
+	Method string
+	Headers map[string]string
+	BodyTemplate string
+	MaxAttempts int
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct SelectWebhook       | Table Webhook              |
+// +----------------------------+----------------------------+
+// | Id int                     | ID INTEGER                 |
+// | Name string                | Name TEXT                  |
+// | BrokerId int               | BrokerId INTEGER           |
+// | TopicFilter string         | TopicFilter TEXT           |
+// | ConditionPath string       | ConditionPath TEXT         |
+// | ConditionOperator string   | ConditionOperator TEXT     |
+// | ConditionValue string      | ConditionValue TEXT        |
+// | Url string                 | Url TEXT                   |
+// | Method string              | Method TEXT                |
+// | Headers map[string]string  | Headers TEXT               |
+// | BodyTemplate string        | BodyTemplate TEXT          |
+// | MaxAttempts int            | MaxAttempts INTEGER        |
+// | CreationDate time.Time     | CreationDate DATETIME      |
+//
+// # Author
+// - Polariusz
+type SelectWebhook struct {
+	Id int
+	Name string
+	BrokerId int
+	TopicFilter string
+	ConditionPath string
+	ConditionOperator string
+	ConditionValue string
+	Url string
+	Method string
+	Headers map[string]string
+	BodyTemplate string
+	MaxAttempts int
+	CreationDate time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB           : It's a connection to the database.
+// - webhook InsertWebhook : It's inserted into table `Webhook`
+//
+// # Tables Affected
+// - Webhook
+//   - INSERT
+//
+// # Returns
+// - int: [Webhook].[ID], -1 on error
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func InsertNewWebhook(con *sql.DB, webhook InsertWebhook) (int, error) {
+	headers, err := json.Marshal(webhook.Headers)
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	result, err := con.Exec(`
+		INSERT INTO Webhook(Name, BrokerId, TopicFilter, ConditionPath, ConditionOperator, ConditionValue, Url, Method, Headers, BodyTemplate, MaxAttempts, CreationDate)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, webhook.Name, webhook.BrokerId, webhook.TopicFilter, webhook.ConditionPath, webhook.ConditionOperator, webhook.ConditionValue, webhook.Url, webhook.Method, string(headers), webhook.BodyTemplate, webhook.MaxAttempts, time.Now())
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	webhookId, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return int(webhookId), nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+//
+// # Tables Affected
+// - Webhook
+//   - SELECT
+//
+// # Returns
+// - A list of struct `SelectWebhook`, the oldest first
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectWebhooks(con *sql.DB) ([]SelectWebhook, error) {
+	var webhookList []SelectWebhook
+
+	rows, err := con.Query(`
+		SELECT ID, Name, BrokerId, TopicFilter, ConditionPath, ConditionOperator, ConditionValue, Url, Method, Headers, BodyTemplate, MaxAttempts, CreationDate
+		FROM Webhook
+		ORDER BY ID
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var webhook SelectWebhook
+		var headers string
+		rows.Scan(&webhook.Id, &webhook.Name, &webhook.BrokerId, &webhook.TopicFilter, &webhook.ConditionPath, &webhook.ConditionOperator, &webhook.ConditionValue, &webhook.Url, &webhook.Method, &headers, &webhook.BodyTemplate, &webhook.MaxAttempts, &webhook.CreationDate)
+		json.Unmarshal([]byte(headers), &webhook.Headers)
+		webhookList = append(webhookList, webhook)
+	}
+
+	return webhookList, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+// - id int      : [Webhook].[ID]
+//
+// # Description
+// - The function shall delete the webhook with its delivery history in one transaction.
+//
+// # Tables Affected
+// - WebhookDelivery, Webhook
+//   - DELETE
+//
+// # Returns
+// - bool: false if there was no such webhook
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteWebhook(con *sql.DB, id int) (bool, error) {
+	tx, err := con.Begin()
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM WebhookDelivery WHERE WebhookId = ?", id); err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	result, err := tx.Exec("DELETE FROM Webhook WHERE ID = ?", id)
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+/*                                   +-----------------+                                   */
+/* ----------------------------------| WEBHOOKDELIVERY |---------------------------------- */
+/*                                   +-----------------+                                   */
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The states of a delivery. A pending delivery is tried for the first time, a retrying one failed before and waits for NextAttemptDate.
+// - A dead delivery has failed MaxAttempts times of its webhook, it is on the dead-letter list until it is retried or deleted.
+const (
+	WEBHOOK_DELIVERY_PENDING = "pending"
+	WEBHOOK_DELIVERY_RETRYING = "retrying"
+	WEBHOOK_DELIVERY_DELIVERED = "delivered"
+	WEBHOOK_DELIVERY_DEAD = "dead"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct InsertWebhookDelivery | Table WebhookDelivery    |
+// +------------------------------+--------------------------+
+// |                              | ID INTEGER               |
+// | WebhookId int                | WebhookId INTEGER        |
+// | BrokerId int                 | BrokerId INTEGER         |
+// | Topic string                 | Topic TEXT               |
+// | RequestBody string           | RequestBody TEXT         |
+// |                              | Status TEXT              |
+// |                              | Attempts INTEGER         |
+// |                              | LastStatusCode INTEGER   |
+// |                              | LastError TEXT           |
+// |                              | CreationDate DATETIME    |
+// |                              | LastAttemptDate DATETIME |
+// | NextAttemptDate time.Time    | NextAttemptDate DATETIME |
+//
+// # Note
+// - A new delivery is `WEBHOOK_DELIVERY_PENDING` without attempts.
+// - NextAttemptDate is when the delivery is picked up again if its first attempt never finishes, like after a crash.
+//
+// # Used in
+// - InsertNewWebhookDelivery()
+//
+// # Author
+// - Polariusz
+type InsertWebhookDelivery struct {
+	WebhookId int
+	BrokerId int
+	Topic string
+	RequestBody string
+	NextAttemptDate time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Struct to Table Mapping
+//
+// | Struct SelectWebhookDelivery | Table WebhookDelivery    |
+// +------------------------------+--------------------------+
+// | Id int                       | ID INTEGER               |
+// | WebhookId int                | WebhookId INTEGER        |
+// | BrokerId int                 | BrokerId INTEGER         |
+// | Topic string                 | Topic TEXT               |
+// | RequestBody string           | RequestBody TEXT         |
+// | Status string                | Status TEXT              |
+// | Attempts int                 | Attempts INTEGER         |
+// | LastStatusCode int           | LastStatusCode INTEGER   |
+// | LastError string             | LastError TEXT           |
+// | CreationDate time.Time       | CreationDate DATETIME    |
+// | LastAttemptDate time.Time    | LastAttemptDate DATETIME |
+// | NextAttemptDate time.Time    | NextAttemptDate DATETIME |
+//
+// # Author
+// - Polariusz
+type SelectWebhookDelivery struct {
+	Id int
+	WebhookId int
+	BrokerId int
+	Topic string
+	RequestBody string
+	Status string
+	Attempts int
+	LastStatusCode int
+	LastError string
+	CreationDate time.Time
+	LastAttemptDate time.Time
+	NextAttemptDate time.Time
+}
+
+// # Author
+// - Polariusz
+func scanSelectWebhookDelivery(scan func(dest ...any) error) (SelectWebhookDelivery, error) {
+	var delivery SelectWebhookDelivery
+	err := scan(&delivery.Id, &delivery.WebhookId, &delivery.BrokerId, &delivery.Topic, &delivery.RequestBody, &delivery.Status, &delivery.Attempts, &delivery.LastStatusCode, &delivery.LastError, &delivery.CreationDate, &delivery.LastAttemptDate, &delivery.NextAttemptDate)
+	return delivery, err
+}
+
+const selectWebhookDeliveryColumns = "ID, WebhookId, BrokerId, Topic, RequestBody, Status, Attempts, LastStatusCode, LastError, CreationDate, LastAttemptDate, NextAttemptDate"
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - Which deliveries SelectWebhookDeliveries() selects. `WebhookId` and `BeforeId` are unset when 0, `Status` when empty.
+// - `BeforeId` is the cursor of the pages, only the deliveries older than it are selected.
+//
+// # Used in
+// - SelectWebhookDeliveries()
+//
+// # Author
+// - Polariusz
+type WebhookDeliveryFilter struct {
+	WebhookId int
+	Status string
+	BeforeId int
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB                    : It's a connection to the database.
+// - delivery InsertWebhookDelivery : It's inserted into table `WebhookDelivery`
+//
+// # Tables Affected
+// - WebhookDelivery
+//   - INSERT
+//
+// # Returns
+// - int: [WebhookDelivery].[ID], -1 on error
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func InsertNewWebhookDelivery(con *sql.DB, delivery InsertWebhookDelivery) (int, error) {
+	now := time.Now()
+	result, err := con.Exec(`
+		INSERT INTO WebhookDelivery(WebhookId, BrokerId, Topic, RequestBody, Status, Attempts, LastStatusCode, LastError, CreationDate, LastAttemptDate, NextAttemptDate)
+		VALUES(?, ?, ?, ?, ?, 0, 0, '', ?, ?, ?)
+	`, delivery.WebhookId, delivery.BrokerId, delivery.Topic, delivery.RequestBody, WEBHOOK_DELIVERY_PENDING, now, now, delivery.NextAttemptDate)
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	deliveryId, err := result.LastInsertId()
+	if err != nil {
+		return -1, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return int(deliveryId), nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB                    : It's a connection to the database.
+// - delivery SelectWebhookDelivery : Its Status, Attempts, LastStatusCode, LastError, LastAttemptDate and NextAttemptDate are written.
+//
+// # Tables Affected
+// - WebhookDelivery
+//   - UPDATE
+//
+// # Returns
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func UpdateWebhookDelivery(con *sql.DB, delivery SelectWebhookDelivery) error {
+	_, err := con.Exec(`
+		UPDATE WebhookDelivery
+		SET Status = ?, Attempts = ?, LastStatusCode = ?, LastError = ?, LastAttemptDate = ?, NextAttemptDate = ?
+		WHERE ID = ?
+	`, delivery.Status, delivery.Attempts, delivery.LastStatusCode, delivery.LastError, delivery.LastAttemptDate, delivery.NextAttemptDate, delivery.Id)
+	if err != nil {
+		return fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	return nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB                  : It's a connection to the database.
+// - filter WebhookDeliveryFilter : Which deliveries are selected.
+// - limit int                    : The most deliveries that are selected.
+//
+// # Tables Affected
+// - WebhookDelivery
+//   - SELECT
+//
+// # Returns
+// - A list of struct `SelectWebhookDelivery`, the newest first
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectWebhookDeliveries(con *sql.DB, filter WebhookDeliveryFilter, limit int) ([]SelectWebhookDelivery, error) {
+	var deliveryList []SelectWebhookDelivery
+
+	conditions := []string{"1 = 1"}
+	var args []any
+	if filter.WebhookId != 0 {
+		conditions = append(conditions, "WebhookId = ?")
+		args = append(args, filter.WebhookId)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "Status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.BeforeId != 0 {
+		conditions = append(conditions, "ID < ?")
+		args = append(args, filter.BeforeId)
+	}
+
+	rows, err := con.Query(`
+		SELECT `+selectWebhookDeliveryColumns+`
+		FROM WebhookDelivery
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY ID DESC
+		LIMIT ?
+	`, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		delivery, _ := scanSelectWebhookDelivery(rows.Scan)
+		deliveryList = append(deliveryList, delivery)
+	}
+
+	return deliveryList, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+// - id int      : [WebhookDelivery].[ID]
+//
+// # Tables Affected
+// - WebhookDelivery
+//   - SELECT
+//
+// # Returns
+// - SelectWebhookDelivery
+// - bool: false if there is no such delivery
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func SelectWebhookDeliveryById(con *sql.DB, id int) (SelectWebhookDelivery, bool, error) {
+	row := con.QueryRow("SELECT "+selectWebhookDeliveryColumns+" FROM WebhookDelivery WHERE ID = ?", id)
+	delivery, err := scanSelectWebhookDelivery(row.Scan)
+	if err == sql.ErrNoRows {
+		return delivery, false, nil
+	}
+	if err != nil {
+		return delivery, false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return delivery, true, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB     : It's a connection to the database.
+// - now time.Time   : The deliveries whose NextAttemptDate is not after it are due.
+// - lease time.Time : The NextAttemptDate the selected deliveries get, so they are not selected again while they are tried.
+// - limit int       : The most deliveries that are selected.
+//
+// # Description
+// - The function shall select the pending and retrying deliveries that are due and move their NextAttemptDate to `lease`, in one transaction.
+//
+// # Tables Affected
+// - WebhookDelivery
+//   - SELECT
+//   - UPDATE
+//
+// # Returns
+// - A list of struct `SelectWebhookDelivery`, the longest due first
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func ClaimDueWebhookDeliveries(con *sql.DB, now time.Time, lease time.Time, limit int) ([]SelectWebhookDelivery, error) {
+	var deliveryList []SelectWebhookDelivery
+
+	tx, err := con.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT `+selectWebhookDeliveryColumns+`
+		FROM WebhookDelivery
+		WHERE Status IN (?, ?) AND NextAttemptDate <= ?
+		ORDER BY NextAttemptDate
+		LIMIT ?
+	`, WEBHOOK_DELIVERY_PENDING, WEBHOOK_DELIVERY_RETRYING, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+	for rows.Next() {
+		delivery, _ := scanSelectWebhookDelivery(rows.Scan)
+		deliveryList = append(deliveryList, delivery)
+	}
+	rows.Close()
+
+	for i := range deliveryList {
+		if _, err := tx.Exec("UPDATE WebhookDelivery SET NextAttemptDate = ? WHERE ID = ?", lease, deliveryList[i].Id); err != nil {
+			return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+		}
+		deliveryList[i].NextAttemptDate = lease
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	return deliveryList, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+// - id int      : [WebhookDelivery].[ID]
+//
+// # Description
+// - The function shall take a dead delivery off the dead-letter list, it is tried again as soon as possible with all attempts of its webhook.
+//
+// # Tables Affected
+// - WebhookDelivery
+//   - UPDATE
+//
+// # Returns
+// - bool: false if there was no such dead delivery
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func RetryDeadWebhookDelivery(con *sql.DB, id int) (bool, error) {
+	result, err := con.Exec(`
+		UPDATE WebhookDelivery
+		SET Status = ?, Attempts = 0, NextAttemptDate = ?
+		WHERE ID = ? AND Status = ?
+	`, WEBHOOK_DELIVERY_RETRYING, time.Now(), id, WEBHOOK_DELIVERY_DEAD)
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : It's a connection to the database.
+// - id int      : [WebhookDelivery].[ID]
+//
+// # Description
+// - The function shall delete a dead delivery from the dead-letter list.
+//
+// # Tables Affected
+// - WebhookDelivery
+//   - DELETE
+//
+// # Returns
+// - bool: false if there was no such dead delivery
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func DeleteDeadWebhookDelivery(con *sql.DB, id int) (bool, error) {
+	result, err := con.Exec("DELETE FROM WebhookDelivery WHERE ID = ? AND Status = ?", id, WEBHOOK_DELIVERY_DEAD)
+	if err != nil {
+		return false, fmt.Errorf("Skill issues\nErr: %s\n", err)
+	}
+
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
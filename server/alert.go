@@ -0,0 +1,917 @@
+package main
+
+import (
+	"bufio"
+	"database"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// How often the rules with ForSeconds or SilenceSeconds are checked.
+const ALERT_TICK = time.Second
+
+// How many events may wait for a slow stream, more are dropped for it.
+const ALERT_STREAM_BUFFER = 100
+
+// How often a stream without events writes a comment, so proxies keep it open.
+const ALERT_STREAM_KEEPALIVE = 15 * time.Second
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - An alert rule with its parsed regular expression and threshold.
+//
+// # Author
+// - Polariusz
+type compiledAlertRule struct {
+	rule database.SelectAlertRule
+	pattern *regexp.Regexp
+	threshold float64
+}
+
+// # Description
+// - The method shall tell whether a value is back far enough past the threshold to resolve the rule, see Hysteresis.
+//
+// # Author
+// - Polariusz
+func (car *compiledAlertRule) resolves(subject string) bool {
+	if car.rule.Hysteresis == 0 {
+		return true
+	}
+	number, err := strconv.ParseFloat(subject, 64)
+	if err != nil {
+		return true
+	}
+
+	switch car.rule.ConditionOperator {
+	case CONDITION_GT, CONDITION_GE:
+		return number <= car.threshold - car.rule.Hysteresis
+	case CONDITION_LT, CONDITION_LE:
+		return number >= car.threshold + car.rule.Hysteresis
+	}
+	return true
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The state of a rule for one topic of one broker. A rule with SilenceSeconds has a single one for all its topics.
+// - `pendingSince` is when the condition began to hold, the rule fires once it has held for ForSeconds.
+//
+// # Author
+// - Polariusz
+type alertSeries struct {
+	ruleId int
+	brokerId int
+	topic string
+	value string
+	pendingSince time.Time
+	lastSeen time.Time
+	firing bool
+	firingSince time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - An alert that is firing right now.
+//
+// # Author
+// - Polariusz
+type ActiveAlert struct {
+	RuleId int
+	Name string
+	BrokerId int
+	Topic string
+	Value string
+	Since time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The body of the request to the webhook of a rule, for every event of the rule.
+//
+// # Author
+// - Polariusz
+type AlertNotification struct {
+	Rule database.SelectAlertRule
+	Event database.SelectAlertEvent
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - It checks the rules of table AlertRule against every received message, and once a second for the rules that wait for time to pass.
+// - A rule fires once and is resolved once, each change is an event in table AlertEvent, on the streams and at the webhook of the rule.
+// - The state of the rules is only in memory. After a restart nothing is firing, and the time of a rule with SilenceSeconds starts again.
+// - Like the webhooks, it belongs to one database and is replaced with it.
+//
+// # Used in
+// - ServerState
+//
+// # Author
+// - Polariusz
+type AlertEngine struct {
+	con *sql.DB
+	webhooks *WebhookDispatcher
+	mutex sync.Mutex
+	rules []*compiledAlertRule
+	series map[string]*alertSeries
+	subscribers map[chan database.SelectAlertEvent]bool
+	tick time.Duration
+	done chan struct{}
+	stopped chan struct{}
+}
+
+// # Author
+// - Polariusz
+func NewAlertEngine(con *sql.DB, webhooks *WebhookDispatcher) *AlertEngine {
+	return &AlertEngine{
+		con: con,
+		webhooks: webhooks,
+		series: make(map[string]*alertSeries),
+		subscribers: make(map[chan database.SelectAlertEvent]bool),
+		tick: ALERT_TICK,
+		done: make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// # Description
+// - The method shall load the rules and start checking them once a second.
+//
+// # Author
+// - Polariusz
+func (ae *AlertEngine) start() {
+	if err := ae.reload(); err != nil {
+		fmt.Printf("WARN: Running without alert rules\nErr:%s\n", err)
+	}
+
+	go func() {
+		defer close(ae.stopped)
+
+		ticker := time.NewTicker(ae.tick)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ae.done:
+				return
+			case now := <-ticker.C:
+				ae.evaluate(now)
+			}
+		}
+	}()
+}
+
+// # Description
+// - The method shall stop checking the rules and end all streams.
+//
+// # Author
+// - Polariusz
+func (ae *AlertEngine) close() {
+	close(ae.done)
+	<-ae.stopped
+
+	ae.endStreams()
+}
+
+// # Description
+// - The method shall end all streams, the ones that are opened afterwards end right away. The rules are still checked.
+// - It is called before the HTTP server shuts down, which waits for the open streams.
+//
+// # Author
+// - Polariusz
+func (ae *AlertEngine) endStreams() {
+	ae.mutex.Lock()
+	defer ae.mutex.Unlock()
+
+	for subscriber := range ae.subscribers {
+		close(subscriber)
+	}
+	ae.subscribers = nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall read the rules from the database. It is called when they change.
+// - The state of the rules that still exist is kept.
+//
+// # Returns
+// - error when the rules cannot be selected, the old ones stay in use
+//
+// # Author
+// - Polariusz
+func (ae *AlertEngine) reload() error {
+	ruleList, err := database.SelectAlertRules(ae.con)
+	if err != nil {
+		return err
+	}
+
+	var rules []*compiledAlertRule
+	known := make(map[int]bool)
+	for _, rule := range ruleList {
+		compiled := &compiledAlertRule{rule: rule}
+		if rule.ConditionOperator == CONDITION_MATCHES {
+			if compiled.pattern, err = regexp.Compile(rule.ConditionValue); err != nil {
+				fmt.Printf("WARN: The alert rule %d is skipped\nErr: %s\n", rule.Id, err)
+				continue
+			}
+		}
+		compiled.threshold, _ = strconv.ParseFloat(rule.ConditionValue, 64)
+		rules = append(rules, compiled)
+		known[rule.Id] = true
+	}
+
+	ae.mutex.Lock()
+	defer ae.mutex.Unlock()
+
+	ae.rules = rules
+	for key, series := range ae.series {
+		if !known[series.ruleId] {
+			delete(ae.series, key)
+		}
+	}
+	for _, compiled := range rules {
+		key := strconv.Itoa(compiled.rule.Id)
+		if compiled.rule.SilenceSeconds > 0 && ae.series[key] == nil {
+			ae.series[key] = &alertSeries{ruleId: compiled.rule.Id, brokerId: compiled.rule.BrokerId, topic: compiled.rule.TopicFilter, lastSeen: time.Now()}
+		}
+	}
+	return nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall check the rules that match the message. It is called by the MQTT-Client for every message.
+// - A rule with SilenceSeconds is resolved by a message that fulfils its condition.
+// - A rule with a condition fires once the condition has held for ForSeconds, and is resolved by a value back past the threshold by Hysteresis.
+//   - A message without the path of the condition does not change the rule.
+//
+// # Author
+// - Polariusz
+func (ae *AlertEngine) observe(message ReceivedMessage) {
+	if ae == nil {
+		return
+	}
+	var events []alertChange
+
+	ae.mutex.Lock()
+	for _, compiled := range ae.rules {
+		rule := compiled.rule
+		if (rule.BrokerId != 0 && rule.BrokerId != message.BrokerId) || !topicMatchesFilter(rule.TopicFilter, message.Topic) {
+			continue
+		}
+
+		if rule.SilenceSeconds > 0 {
+			if !evaluateCondition(rule.ConditionPath, rule.ConditionOperator, rule.ConditionValue, compiled.pattern, message.Message) {
+				continue
+			}
+			series := ae.series[strconv.Itoa(rule.Id)]
+			silence := message.ReceivedAt.Sub(series.lastSeen)
+			series.lastSeen = message.ReceivedAt
+			series.brokerId = message.BrokerId
+			series.topic = message.Topic
+			if series.firing {
+				series.firing = false
+				events = append(events, alertChange{compiled, series.event(database.ALERT_RESOLVED, fmt.Sprintf("%.0fs", silence.Seconds()))})
+			}
+			continue
+		}
+
+		subject, ok := conditionSubject(rule.ConditionPath, message.Message)
+		if !ok {
+			continue
+		}
+		key := fmt.Sprintf("%d/%d/%s", rule.Id, message.BrokerId, message.Topic)
+		series := ae.series[key]
+
+		if compareCondition(subject, rule.ConditionOperator, rule.ConditionValue, compiled.pattern) {
+			if series == nil {
+				series = &alertSeries{ruleId: rule.Id, brokerId: message.BrokerId, topic: message.Topic}
+				ae.series[key] = series
+			}
+			series.value = subject
+			if series.firing {
+				continue
+			}
+			if series.pendingSince.IsZero() {
+				series.pendingSince = message.ReceivedAt
+			}
+			if message.ReceivedAt.Sub(series.pendingSince) >= time.Duration(rule.ForSeconds) * time.Second {
+				series.firing = true
+				series.firingSince = message.ReceivedAt
+				events = append(events, alertChange{compiled, series.event(database.ALERT_FIRING, subject)})
+			}
+			continue
+		}
+
+		if series == nil {
+			continue
+		}
+		series.value = subject
+		series.pendingSince = time.Time{}
+		if !series.firing {
+			delete(ae.series, key)
+		} else if compiled.resolves(subject) {
+			delete(ae.series, key)
+			events = append(events, alertChange{compiled, series.event(database.ALERT_RESOLVED, subject)})
+		}
+	}
+	ae.mutex.Unlock()
+
+	ae.emit(events)
+}
+
+// # Description
+// - The method shall fire the rules whose time has come: the conditions that have held for ForSeconds, and the rules with SilenceSeconds without a message for that long.
+//
+// # Author
+// - Polariusz
+func (ae *AlertEngine) evaluate(now time.Time) {
+	var events []alertChange
+
+	ae.mutex.Lock()
+	rules := make(map[int]*compiledAlertRule)
+	for _, compiled := range ae.rules {
+		rules[compiled.rule.Id] = compiled
+	}
+	for _, series := range ae.series {
+		compiled := rules[series.ruleId]
+		if compiled == nil || series.firing {
+			continue
+		}
+
+		rule := compiled.rule
+		if rule.SilenceSeconds > 0 {
+			if silence := now.Sub(series.lastSeen); silence >= time.Duration(rule.SilenceSeconds) * time.Second {
+				series.firing = true
+				series.firingSince = now
+				series.value = fmt.Sprintf("%.0fs", silence.Seconds())
+				events = append(events, alertChange{compiled, series.event(database.ALERT_FIRING, series.value)})
+			}
+			continue
+		}
+		if !series.pendingSince.IsZero() && now.Sub(series.pendingSince) >= time.Duration(rule.ForSeconds) * time.Second {
+			series.firing = true
+			series.firingSince = now
+			events = append(events, alertChange{compiled, series.event(database.ALERT_FIRING, series.value)})
+		}
+	}
+	ae.mutex.Unlock()
+
+	ae.emit(events)
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A change of the state of a rule that has not been written yet.
+//
+// # Author
+// - Polariusz
+type alertChange struct {
+	rule *compiledAlertRule
+	event database.InsertAlertEvent
+}
+
+// # Author
+// - Polariusz
+func (series *alertSeries) event(state string, value string) database.InsertAlertEvent {
+	return database.InsertAlertEvent{RuleId: series.ruleId, BrokerId: series.brokerId, Topic: series.topic, State: state, Value: value}
+}
+
+// # Description
+// - The method shall write the events, send them to the streams and to the webhooks of their rules.
+//
+// # Author
+// - Polariusz
+func (ae *AlertEngine) emit(changes []alertChange) {
+	for _, change := range changes {
+		event, err := database.InsertNewAlertEvent(ae.con, change.event)
+		if err != nil {
+			fmt.Printf("ERROR: An alert event of the rule %d was not written!\nErr: %s\n", change.event.RuleId, err)
+		}
+
+		ae.mutex.Lock()
+		for subscriber := range ae.subscribers {
+			select {
+			case subscriber <- event:
+			default:
+			}
+		}
+		ae.mutex.Unlock()
+
+		if change.rule.rule.WebhookId != 0 {
+			body, _ := json.Marshal(AlertNotification{Rule: change.rule.rule, Event: event})
+			ae.webhooks.notify(change.rule.rule.WebhookId, event.BrokerId, event.Topic, string(body))
+		}
+	}
+}
+
+// # Description
+// - The method shall return a channel with every event from now on, and the function that ends it. The channel is closed when the engine is.
+//
+// # Author
+// - Polariusz
+func (ae *AlertEngine) subscribe() (chan database.SelectAlertEvent, func()) {
+	ae.mutex.Lock()
+	defer ae.mutex.Unlock()
+
+	subscriber := make(chan database.SelectAlertEvent, ALERT_STREAM_BUFFER)
+	if ae.subscribers == nil {
+		close(subscriber)
+		return subscriber, func() {}
+	}
+	ae.subscribers[subscriber] = true
+
+	return subscriber, func() {
+		ae.mutex.Lock()
+		defer ae.mutex.Unlock()
+
+		if ae.subscribers[subscriber] {
+			delete(ae.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+// # Description
+// - The method shall return the alerts that are firing, the oldest first.
+//
+// # Author
+// - Polariusz
+func (ae *AlertEngine) active() []ActiveAlert {
+	ae.mutex.Lock()
+	defer ae.mutex.Unlock()
+
+	names := make(map[int]string)
+	for _, compiled := range ae.rules {
+		names[compiled.rule.Id] = compiled.rule.Name
+	}
+	activeList := []ActiveAlert{}
+	for _, series := range ae.series {
+		if series.firing {
+			activeList = append(activeList, ActiveAlert{RuleId: series.ruleId, Name: names[series.ruleId], BrokerId: series.brokerId, Topic: series.topic, Value: series.value, Since: series.firingSince})
+		}
+	}
+	for i := 1; i < len(activeList); i++ {
+		for j := i; j > 0 && activeList[j].Since.Before(activeList[j-1].Since); j-- {
+			activeList[j], activeList[j-1] = activeList[j-1], activeList[j]
+		}
+	}
+	return activeList
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Name":"<N>","BrokerId":<B>,"TopicFilter":"<F>","ConditionPath":"<CP>","ConditionOperator":"<CO>","ConditionValue":"<CV>","SilenceSeconds":<S>,"ForSeconds":<D>,"Hysteresis":<H>,"WebhookId":<W>}
+//   - <B>  : The ID of the Broker ROW, 0 for all brokers
+//   - <F>  : MQTT topic filter, "#" if empty
+//   - <CP> : A dotted path into the message as JSON, like `sensor.temp`, empty for the whole message
+//   - <CO> : One of eq, ne, gt, ge, lt, le, contains, matches and exists. Optional with <S>.
+//   - <S>  : If set, the rule fires when no message that fulfils the condition was received for <S> seconds
+//   - <D>  : How many seconds the condition must hold before the rule fires
+//   - <H>  : How far a value must be back past the threshold of gt, ge, lt and le to resolve the rule
+//   - <W>  : The ID of the webhook that gets the events, 0 for none
+//
+// # Used in
+// - PostAlertRuleHandler()
+//
+// # Author
+// - Polariusz
+type AlertRuleWrapper struct {
+	Name string
+	BrokerId int
+	TopicFilter string
+	ConditionPath string
+	ConditionOperator string
+	ConditionValue string
+	SilenceSeconds int
+	ForSeconds int
+	Hysteresis float64
+	WebhookId int
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Validator
+//
+// # Description
+// - The function shall fill in the defaults of the rule and check it.
+//
+// # Returns
+// - error when the rule is not valid, the message can be shown to the client
+//
+// # Author
+// - Polariusz
+func validateAlertRule(ruleWrapper *AlertRuleWrapper) error {
+	if ruleWrapper.TopicFilter == "" {
+		ruleWrapper.TopicFilter = "#"
+	}
+
+	if ruleWrapper.Name == "" {
+		return fmt.Errorf("Name is required")
+	}
+	if ruleWrapper.BrokerId < 0 || ruleWrapper.WebhookId < 0 {
+		return fmt.Errorf("BrokerId and WebhookId must not be negative")
+	}
+	if !validTopicFilter(ruleWrapper.TopicFilter) {
+		return fmt.Errorf("TopicFilter is not a valid MQTT topic filter")
+	}
+	if !validConditionOperator(ruleWrapper.ConditionOperator) {
+		return fmt.Errorf("ConditionOperator must be one of eq, ne, gt, ge, lt, le, contains, matches and exists")
+	}
+	if ruleWrapper.SilenceSeconds < 0 || ruleWrapper.ForSeconds < 0 || ruleWrapper.Hysteresis < 0 {
+		return fmt.Errorf("SilenceSeconds, ForSeconds and Hysteresis must not be negative")
+	}
+
+	if ruleWrapper.SilenceSeconds > 0 {
+		if ruleWrapper.ForSeconds > 0 || ruleWrapper.Hysteresis > 0 {
+			return fmt.Errorf("ForSeconds and Hysteresis can not be used with SilenceSeconds")
+		}
+	} else if ruleWrapper.ConditionOperator == "" {
+		return fmt.Errorf("ConditionOperator or SilenceSeconds is required")
+	}
+
+	if ruleWrapper.Hysteresis > 0 {
+		switch ruleWrapper.ConditionOperator {
+		case CONDITION_GT, CONDITION_GE, CONDITION_LT, CONDITION_LE:
+		default:
+			return fmt.Errorf("Hysteresis can only be used with gt, ge, lt and le")
+		}
+	}
+	switch ruleWrapper.ConditionOperator {
+	case CONDITION_GT, CONDITION_GE, CONDITION_LT, CONDITION_LE:
+		if _, err := strconv.ParseFloat(ruleWrapper.ConditionValue, 64); err != nil {
+			return fmt.Errorf("ConditionValue must be a number for %s", ruleWrapper.ConditionOperator)
+		}
+	case CONDITION_MATCHES:
+		if _, err := regexp.Compile(ruleWrapper.ConditionValue); err != nil {
+			return fmt.Errorf("ConditionValue is not a valid regular expression: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return the alert rules of the selected project that the account may see, for their broker and topic filter.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"rules":[<database.SelectAlertRule>]}
+// - 500 (Internal Server Error): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func GetAlertRulesHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		permissions, err := permissionsOf(c, serverState)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the roles", map[string]any{"Error": err.Error()})
+		}
+		ruleList, err := database.SelectAlertRules(serverState.con)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the alert rules", map[string]any{"Error": err.Error()})
+		}
+
+		visibleRules := []database.SelectAlertRule{}
+		for _, rule := range ruleList {
+			if permissions.allows(ROLE_VIEWER, rule.BrokerId, rule.TopicFilter) {
+				visibleRules = append(visibleRules, rule)
+			}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"rules": visibleRules,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall create an alert rule, it is checked against every message received afterwards.
+// - The method shall accept a jsonified structure that follows the struct AlertRuleWrapper.
+// - The method shall need the role operator for the broker and the topic filter of the rule, and the role admin for its webhook.
+//
+// # Returns
+// - 201 (Created): JSON
+//   - {"Id":<RULE-ID>}
+// - 400 (Bad Request): `APIErrorEnvelope`
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+//   - There is no webhook with the WebhookId
+// - 500 (Internal Server Error): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func PostAlertRuleHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var ruleWrapper AlertRuleWrapper
+		if err := c.BodyParser(&ruleWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+		if err := validateAlertRule(&ruleWrapper); err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+		if ok, err := authorize(c, serverState, ROLE_OPERATOR, ruleWrapper.BrokerId, ruleWrapper.TopicFilter); !ok {
+			return err
+		}
+		if ruleWrapper.WebhookId != 0 {
+			webhook, ok, err := webhookOfPath(c, serverState, ruleWrapper.WebhookId)
+			if !ok {
+				return err
+			}
+			if ok, err := authorize(c, serverState, ROLE_ADMIN, webhook.BrokerId, webhook.TopicFilter); !ok {
+				return err
+			}
+		}
+
+		ruleId, err := database.InsertNewAlertRule(serverState.con, database.InsertAlertRule{
+			Name: ruleWrapper.Name,
+			BrokerId: ruleWrapper.BrokerId,
+			TopicFilter: ruleWrapper.TopicFilter,
+			ConditionPath: ruleWrapper.ConditionPath,
+			ConditionOperator: ruleWrapper.ConditionOperator,
+			ConditionValue: ruleWrapper.ConditionValue,
+			SilenceSeconds: ruleWrapper.SilenceSeconds,
+			ForSeconds: ruleWrapper.ForSeconds,
+			Hysteresis: ruleWrapper.Hysteresis,
+			WebhookId: ruleWrapper.WebhookId,
+		})
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while inserting in the AlertRule table", map[string]any{"Error": err.Error()})
+		}
+		if err := serverState.alerts.reload(); err != nil {
+			fmt.Printf("WARN: Alert rules were not reloaded!\nErr: %s\n", err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"Id": ruleId,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Audited |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall delete an alert rule with its events. If it is firing, it is not resolved, it is gone.
+// - The method shall need the role operator for the broker and the topic filter of the rule.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"Id":<RULE-ID>}
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func DeleteAlertRuleHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_DELETE_ALERT_RULE}
+		defer serverState.auditLog.record(c, &record)
+
+		ruleId, err := paramId(c, "id")
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+		ruleList, err := database.SelectAlertRules(serverState.con)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the alert rules", map[string]any{"Error": err.Error()})
+		}
+		var rule *database.SelectAlertRule
+		for i := range ruleList {
+			if ruleList[i].Id == ruleId {
+				rule = &ruleList[i]
+			}
+		}
+		if rule == nil {
+			return writeAPIError(c, fiber.StatusNotFound, API_ERROR_NOT_FOUND, "There is no alert rule with this Id", nil)
+		}
+		record.about(BrokerUser{BrokerId: rule.BrokerId}, rule.TopicFilter)
+		if ok, err := authorize(c, serverState, ROLE_OPERATOR, rule.BrokerId, rule.TopicFilter); !ok {
+			return err
+		}
+
+		if _, err := database.DeleteAlertRule(serverState.con, ruleId); err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while deleting the alert rule", map[string]any{"Error": err.Error()})
+		}
+		if err := serverState.alerts.reload(); err != nil {
+			fmt.Printf("WARN: Alert rules were not reloaded!\nErr: %s\n", err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"Id": ruleId,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return the alerts that are firing and that the account may see, the oldest first.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"alerts":[<ActiveAlert>]}
+//
+// # Author
+// - Polariusz
+func GetActiveAlertsHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		permissions, err := permissionsOf(c, serverState)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the roles", map[string]any{"Error": err.Error()})
+		}
+
+		visibleAlerts := []ActiveAlert{}
+		for _, alert := range serverState.alerts.active() {
+			if permissions.allows(ROLE_VIEWER, alert.BrokerId, alert.Topic) {
+				visibleAlerts = append(visibleAlerts, alert)
+			}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"alerts": visibleAlerts,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return a page of the alert events that the account may see, the newest first.
+// - The query `ruleId`, `brokerId` and `state` narrow them down, the next page is requested with `beforeId` set to the `nextBeforeId` of the response.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"events":[<database.SelectAlertEvent>],"nextBeforeId":<N>}
+// - 400 (Bad Request): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func GetAlertEventsHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		limit, beforeId, err := pageFromQuery(c)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+		filter := database.AlertEventFilter{State: c.Query("state"), BeforeId: beforeId}
+		if filter.RuleId, err = queryInt(c, "ruleId", 0); err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+		if filter.BrokerId, err = queryInt(c, "brokerId", 0); err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+		permissions, err := permissionsOf(c, serverState)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the roles", map[string]any{"Error": err.Error()})
+		}
+
+		eventList, err := database.SelectAlertEvents(serverState.con, filter, limit)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the alert events", map[string]any{"Error": err.Error()})
+		}
+		visibleEvents := []database.SelectAlertEvent{}
+		for _, event := range eventList {
+			if permissions.allows(ROLE_VIEWER, event.BrokerId, event.Topic) {
+				visibleEvents = append(visibleEvents, event)
+			}
+		}
+
+		nextBeforeId := 0
+		if len(eventList) == limit {
+			nextBeforeId = eventList[len(eventList)-1].Id
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"events": visibleEvents,
+			"nextBeforeId": nextBeforeId,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall stream the alert events that the account may see as server-sent events, from now on, until the client goes away.
+//   - Every event is `event: firing` or `event: resolved` with the id of the event and a database.SelectAlertEvent as JSON data.
+// - The stream ends when the project is changed, the client shall open it again.
+//
+// # Returns
+// - 200 (Ok): text/event-stream
+//
+// # Author
+// - Polariusz
+func GetAlertStreamHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		permissions, err := permissionsOf(c, serverState)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the roles", map[string]any{"Error": err.Error()})
+		}
+
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		c.Set(fiber.HeaderConnection, "keep-alive")
+
+		events, cancel := serverState.alerts.subscribe()
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer cancel()
+
+			keepalive := time.NewTicker(ALERT_STREAM_KEEPALIVE)
+			defer keepalive.Stop()
+
+			fmt.Fprintf(w, ": connected\n\n")
+			for {
+				// A failed flush means that the client has gone away.
+				if err := w.Flush(); err != nil {
+					return
+				}
+
+				select {
+				case event, ok := <-events:
+					if !ok {
+						return
+					}
+					if !permissions.allows(ROLE_VIEWER, event.BrokerId, event.Topic) {
+						continue
+					}
+					data, _ := json.Marshal(event)
+					fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.State, data)
+				case <-keepalive.C:
+					fmt.Fprintf(w, ": keep-alive\n\n")
+				}
+			}
+		})
+
+		return nil
+	}
+}
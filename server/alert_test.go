@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"database"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAlertRules(t *testing.T) {
+	con, err := database.OpenDatabase(database.InMemoryDatabase)
+	if err != nil {
+		t.Fatalf("OpenDatabase: %s", err)
+	}
+	defer con.Close()
+	if err := database.SetupDatabase(con); err != nil {
+		t.Fatalf("SetupDatabase: %s", err)
+	}
+
+	bodies := make(chan string, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- string(body)
+	}))
+	defer receiver.Close()
+
+	accounts, err := NewAccountManager(con)
+	if err != nil {
+		t.Fatalf("NewAccountManager: %s", err)
+	}
+	webhooks := NewWebhookDispatcher(con)
+	webhooks.start()
+	defer webhooks.close()
+	// The test moves the time itself with evaluate().
+	alerts := NewAlertEngine(con, webhooks)
+	alerts.tick = time.Hour
+	alerts.start()
+	defer alerts.close()
+	serverState := &ServerState{con: con, accounts: accounts, webhooks: webhooks, alerts: alerts}
+	server := fiber.New()
+	addRoutes(server, serverState)
+
+	status, body := request(t, server, "POST", "/api/v1/webhooks", fmt.Sprintf(`{"Name":"pager","Url":%q}`, receiver.URL), nil)
+	if status != fiber.StatusCreated {
+		t.Fatalf("create the webhook: %d %v", status, body)
+	}
+	webhookId := int(body["Id"].(float64))
+
+	for name, rule := range map[string]string{
+		"no condition": `{"Name":"x"}`,
+		"no number": `{"Name":"x","ConditionPath":"temp","ConditionOperator":"gt","ConditionValue":"hot"}`,
+		"hysteresis of eq": `{"Name":"x","ConditionOperator":"eq","ConditionValue":"1","Hysteresis":1}`,
+		"silence with for": `{"Name":"x","SilenceSeconds":10,"ForSeconds":10}`,
+		"bad filter": `{"Name":"x","TopicFilter":"a/#/b","SilenceSeconds":10}`,
+	} {
+		if status, body := request(t, server, "POST", "/api/v1/alerts/rules", rule, nil); status != fiber.StatusBadRequest {
+			t.Errorf("%s: %d %v", name, status, body)
+		}
+	}
+	if status, _ := request(t, server, "POST", "/api/v1/alerts/rules", `{"Name":"x","SilenceSeconds":10,"WebhookId":999}`, nil); status != fiber.StatusNotFound {
+		t.Errorf("a rule with an unknown webhook: %d", status)
+	}
+
+	status, body = request(t, server, "POST", "/api/v1/alerts/rules", fmt.Sprintf(`{"Name":"hot","TopicFilter":"sensors/+","ConditionPath":"temp","ConditionOperator":"gt","ConditionValue":"30","ForSeconds":10,"Hysteresis":2,"WebhookId":%d}`, webhookId), nil)
+	if status != fiber.StatusCreated {
+		t.Fatalf("create the threshold rule: %d %v", status, body)
+	}
+	hotId := int(body["Id"].(float64))
+	status, body = request(t, server, "POST", "/api/v1/alerts/rules", `{"Name":"quiet","TopicFilter":"heartbeat/#","SilenceSeconds":60}`, nil)
+	if status != fiber.StatusCreated {
+		t.Fatalf("create the absence rule: %d %v", status, body)
+	}
+
+	events, cancel := alerts.subscribe()
+	defer cancel()
+	next := func(state string, value string) {
+		t.Helper()
+		select {
+		case event := <-events:
+			if event.State != state || event.Value != value {
+				t.Errorf("the event: %+v, want %s %s", event, state, value)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %s event", state)
+		}
+	}
+	none := func(what string) {
+		t.Helper()
+		select {
+		case event := <-events:
+			t.Errorf("%s: %+v", what, event)
+		default:
+		}
+	}
+
+	start := time.Now()
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+	observe := func(seconds int, topic string, message string) {
+		alerts.observe(ReceivedMessage{BrokerId: 1, Topic: topic, Message: message, ReceivedAt: at(seconds)})
+	}
+
+	// The value has to stay above 30 for 10 seconds.
+	observe(0, "sensors/a", `{"temp":35}`)
+	observe(1, "sensors/b", `{"temp":31}`)
+	observe(2, "sensors/b", `{"temp":20}`)
+	observe(3, "sensors/a", `{"humidity":80}`)
+	alerts.evaluate(at(5))
+	none("fired before ForSeconds")
+	observe(11, "sensors/a", `{"temp":36}`)
+	next(database.ALERT_FIRING, "36")
+	alerts.evaluate(at(20))
+	none("sensors/b was reset by 20")
+	if active := alerts.active(); len(active) != 1 || active[0].Topic != "sensors/a" || active[0].Name != "hot" {
+		t.Errorf("the active alerts: %+v", active)
+	}
+
+	// 29 is below the threshold but not below it by the hysteresis.
+	observe(12, "sensors/a", `{"temp":29}`)
+	none("resolved within the hysteresis")
+	observe(13, "sensors/a", `{"temp":28}`)
+	next(database.ALERT_RESOLVED, "28")
+
+	// The absence rule fires once, and the next heartbeat resolves it.
+	alerts.evaluate(time.Now().Add(59 * time.Second))
+	none("fired before SilenceSeconds")
+	alerts.evaluate(time.Now().Add(61 * time.Second))
+	next(database.ALERT_FIRING, "61s")
+	alerts.evaluate(time.Now().Add(70 * time.Second))
+	none("fired twice")
+	alerts.observe(ReceivedMessage{BrokerId: 1, Topic: "heartbeat/a", Message: "1", ReceivedAt: time.Now().Add(75 * time.Second)})
+	next(database.ALERT_RESOLVED, "75s")
+
+	status, body = request(t, server, "GET", "/api/v1/alerts/events?state=firing", "", nil)
+	if eventList, _ := body["events"].([]any); status != fiber.StatusOK || len(eventList) != 2 {
+		t.Errorf("the firing events: %d %v", status, body)
+	}
+	status, body = request(t, server, "GET", fmt.Sprintf("/api/v1/alerts/events?ruleId=%d&limit=1", hotId), "", nil)
+	if eventList, _ := body["events"].([]any); status != fiber.StatusOK || len(eventList) != 1 || eventList[0].(map[string]any)["State"] != database.ALERT_RESOLVED || body["nextBeforeId"] == float64(0) {
+		t.Errorf("the newest event of the rule: %d %v", status, body)
+	}
+
+	// Only the threshold rule has a webhook. The workers of the webhooks may deliver in any order.
+	states := map[string]bool{}
+	for range 2 {
+		select {
+		case got := <-bodies:
+			var notification AlertNotification
+			if err := json.Unmarshal([]byte(got), &notification); err != nil || notification.Rule.Name != "hot" {
+				t.Errorf("the notification: %s", got)
+			}
+			states[notification.Event.State] = true
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %v were notified", states)
+		}
+	}
+	if !states[database.ALERT_FIRING] || !states[database.ALERT_RESOLVED] {
+		t.Errorf("the notified states: %v", states)
+	}
+
+	if status, _ := request(t, server, "DELETE", fmt.Sprintf("/api/v1/alerts/rules/%d", hotId), "", nil); status != fiber.StatusOK {
+		t.Errorf("delete the rule: %d", status)
+	}
+	status, body = request(t, server, "GET", "/api/v1/alerts/rules", "", nil)
+	if ruleList, _ := body["rules"].([]any); status != fiber.StatusOK || len(ruleList) != 1 {
+		t.Errorf("the rules: %d %v", status, body)
+	}
+	observe(30, "sensors/a", `{"temp":50}`)
+	alerts.evaluate(at(60))
+	none("a deleted rule fired")
+}
+
+// TestShutdownEndsAlertStreams makes sure that an open alert stream does
+// not keep the server from shutting down.
+func TestShutdownEndsAlertStreams(t *testing.T) {
+	con, err := database.OpenDatabase(database.InMemoryDatabase)
+	if err != nil {
+		t.Fatalf("OpenDatabase: %s", err)
+	}
+	defer con.Close()
+	if err := database.SetupDatabase(con); err != nil {
+		t.Fatalf("SetupDatabase: %s", err)
+	}
+
+	accounts, err := NewAccountManager(con)
+	if err != nil {
+		t.Fatalf("NewAccountManager: %s", err)
+	}
+	alerts := NewAlertEngine(con, nil)
+	alerts.start()
+	defer alerts.close()
+	serverState := &ServerState{con: con, accounts: accounts, alerts: alerts}
+	server := fiber.New()
+	addRoutes(server, serverState)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %s", err)
+	}
+	go server.Listener(listener)
+
+	response, err := http.Get("http://" + listener.Addr().String() + "/api/v1/alerts/stream")
+	if err != nil {
+		t.Fatalf("open the stream: %s", err)
+	}
+	defer response.Body.Close()
+	if line, err := bufio.NewReader(response.Body).ReadString('\n'); err != nil || line != ": connected\n" {
+		t.Fatalf("the start of the stream: %q %v", line, err)
+	}
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- shutdown(server, serverState) }()
+	select {
+	case err := <-stopped:
+		if err != nil {
+			t.Errorf("shutdown: %s", err)
+		}
+	case <-time.After(SHUTDOWN_TIMEOUT / 2):
+		t.Fatalf("the open stream kept the server from shutting down")
+	}
+}
@@ -0,0 +1,1294 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"database"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - All routes of the versioned API start with it.
+//
+// # Author
+// - Polariusz
+const API_V1_PREFIX = "/api/v1"
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The machine-readable codes of `APIError`. A client shall switch on them, not on the message.
+//
+// # Author
+// - Polariusz
+const API_ERROR_BAD_JSON = "bad_json"
+const API_ERROR_INVALID_ARGUMENT = "invalid_argument"
+const API_ERROR_NOT_CONNECTED = "not_connected"
+const API_ERROR_UNAUTHENTICATED = "unauthenticated"
+const API_ERROR_FORBIDDEN = "forbidden"
+const API_ERROR_NOT_FOUND = "not_found"
+const API_ERROR_ROUTE_NOT_FOUND = "route_not_found"
+const API_ERROR_CONFLICT = "conflict"
+const API_ERROR_UNPROCESSABLE = "unprocessable"
+const API_ERROR_INTERNAL = "internal"
+const API_ERROR_UNAVAILABLE = "unavailable"
+const API_ERROR_TIMEOUT = "timeout"
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"error":{"code":"<C>","message":"<M>","details":{<D>}}}
+//   - <C> : One of the `API_ERROR_*` codes
+//   - <M> : What went wrong, for humans
+//   - <D> : Optional, further fields, for example the SQL error under "Error"
+//
+// # Used in
+// - Every error response of the routes under `API_V1_PREFIX`
+//
+// # Author
+// - Polariusz
+type APIError struct {
+	Code string `json:"code"`
+	Message string `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// # Author
+// - Polariusz
+type APIErrorEnvelope struct {
+	Error APIError `json:"error"`
+}
+
+// # Description
+// - The function shall write the error envelope with the status.
+//
+// # Author
+// - Polariusz
+func writeAPIError(c *fiber.Ctx, status int, code string, message string, details map[string]any) error {
+	return c.Status(status).JSON(APIErrorEnvelope{APIError{code, message, details}})
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall return the error code of a status, for errors that have no more specific code.
+//
+// # Author
+// - Polariusz
+func apiErrorCode(status int) string {
+	switch status {
+	case fiber.StatusBadRequest:
+		return API_ERROR_INVALID_ARGUMENT
+	case fiber.StatusUnauthorized:
+		return API_ERROR_NOT_CONNECTED
+	case fiber.StatusForbidden:
+		return API_ERROR_FORBIDDEN
+	case fiber.StatusNotFound:
+		return API_ERROR_NOT_FOUND
+	case fiber.StatusConflict:
+		return API_ERROR_CONFLICT
+	case fiber.StatusUnprocessableEntity:
+		return API_ERROR_UNPROCESSABLE
+	case fiber.StatusServiceUnavailable:
+		return API_ERROR_UNAVAILABLE
+	case fiber.StatusGatewayTimeout:
+		return API_ERROR_TIMEOUT
+	}
+	return API_ERROR_INTERNAL
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The keys that the handlers use for the message of an error, the first one that is found becomes `APIError.Message`.
+// - Keys that start with "bad", like "badTopic" or "badBackup", are messages too.
+//
+// # Author
+// - Polariusz
+var legacyErrorMessageKeys = []string{"terribleJson", "badJson", "InternalServerError", "Unauthorized", "Forbidden", "Conflict", "ServiceUnavailable", "BadRequest", "Message"}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall translate the error body of a handler into an `APIError`.
+// - `{"badJson":BADJSON}` becomes `API_ERROR_BAD_JSON`, everything else gets the code of the status. The other fields of the body end up in the details.
+//
+// # Author
+// - Polariusz
+func translateLegacyError(status int, body []byte) APIError {
+	apiError := APIError{Code: apiErrorCode(status), Message: strings.TrimSpace(string(body))}
+
+	var fields map[string]any
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return apiError
+	}
+
+	messageKey := ""
+	for _, key := range legacyErrorMessageKeys {
+		if _, ok := fields[key].(string); ok {
+			messageKey = key
+			break
+		}
+	}
+	if messageKey == "" {
+		for key, value := range fields {
+			if _, ok := value.(string); ok && strings.HasPrefix(key, "bad") {
+				messageKey = key
+				break
+			}
+		}
+	}
+
+	if messageKey != "" {
+		apiError.Message = fields[messageKey].(string)
+		delete(fields, messageKey)
+	}
+	if messageKey == "badJson" && apiError.Message == BADJSON {
+		apiError.Code = API_ERROR_BAD_JSON
+		apiError.Message = "The body is not JSON or does not match the expected structure"
+	}
+	if len(fields) > 0 {
+		apiError.Details = fields
+	}
+
+	return apiError
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Middleware
+//
+// # Description
+// - The middleware shall turn every error response of the routes under `API_V1_PREFIX` into an `APIErrorEnvelope`.
+// - The handlers are shared with the deprecated routes, so they keep writing their own keys, which are translated here.
+//
+// # Author
+// - Polariusz
+func apiErrorEnvelope(c *fiber.Ctx) error {
+	if err := c.Next(); err != nil {
+		status := fiber.StatusInternalServerError
+		if fiberError, ok := err.(*fiber.Error); ok {
+			status = fiberError.Code
+		}
+		return writeAPIError(c, status, apiErrorCode(status), err.Error(), nil)
+	}
+
+	status := c.Response().StatusCode()
+	if status < fiber.StatusBadRequest {
+		return nil
+	}
+
+	body := c.Response().Body()
+	var envelope APIErrorEnvelope
+	if json.Unmarshal(body, &envelope) == nil && envelope.Error.Code != "" {
+		return nil
+	}
+
+	return c.Status(status).JSON(APIErrorEnvelope{translateLegacyError(status, body)})
+}
+
+// | Date of change | By        | Comment                   |
+// +----------------+-----------+---------------------------+
+// | 2026-10-19     | Polariusz | Created                   |
+// | 2026-10-19     | Polariusz | Described for the OpenAPI |
+// | 2026-10-19     | Polariusz | Added Public              |
+// | 2026-10-19     | Polariusz | Added the role routes     |
+//
+// # Description
+// - A route of the versioned API.
+// - `Handler` usually adapts the path and query parameters to the body of a handler that also serves a deprecated route, see withBody().
+// - `Query`, `Request`, `Response` and `Status` describe the route for the OpenAPI document, see buildOpenAPI().
+//   - `Request` and `Response` are zero values of the types, a `fiber.Map` stands for an object with the keys and the types of its values.
+//   - `Status` is the status of a success, 200 if 0. `Partial` adds a 207 (Multi-Status) with the same body.
+//   - `Produces` is the content type of a success, JSON if empty.
+// - `Public` routes can be called without a session, see authenticate().
+// - `Replaces` lists the deprecated routes that this route succeeds.
+//
+// # Used in
+// - apiV1Routes()
+//
+// # Author
+// - Polariusz
+type APIRoute struct {
+	Method string
+	Path string
+	Summary string
+	Query []APIParameter
+	Request any
+	Response any
+	Status int
+	Partial bool
+	Produces string
+	Public bool
+	Replaces []LegacyRoute
+	Handler func(serverState *ServerState) fiber.Handler
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A query parameter of an `APIRoute`.
+// - `Type` is "string", "integer", "boolean" or "date-time". `Repeated` if it may be given more than once.
+//
+// # Author
+// - Polariusz
+type APIParameter struct {
+	Name string
+	Type string
+	Repeated bool
+	Description string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A deprecated route, as "<METHOD> <PATH>", with the JSON body it takes.
+// - `Response` is only set if it differs from the one of the route that replaces it.
+//
+// # Author
+// - Polariusz
+type LegacyRoute struct {
+	Route string
+	Request any
+	Response any
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Topics":["<T>"]}
+//
+// # Used in
+// - `POST /brokers/:brokerId/users/:userId/subscriptions` and `.../favourites`, which add the broker and user of the path to it, see TopicsWrapper.
+//
+// # Author
+// - Polariusz
+type TopicListBody struct {
+	Topics []string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Topic":"<T>","Message":"<M>"}
+//
+// # Used in
+// - `POST /brokers/:brokerId/users/:userId/messages`, which adds the broker and user of the path to it, see MessageWrapper.
+//
+// # Author
+// - Polariusz
+type PublishBody struct {
+	Topic string
+	Message string
+}
+
+// # Description
+// - The function shall return the path parameter as a positive integer.
+//
+// # Author
+// - Polariusz
+func paramId(c *fiber.Ctx, key string) (int, error) {
+	id, err := strconv.Atoi(c.Params(key))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("Path parameter '%s' must be a positive integer", key)
+	}
+	return id, nil
+}
+
+// # Author
+// - Polariusz
+func queryInt(c *fiber.Ctx, key string, fallback int) (int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return fallback, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("Query parameter '%s' must be an integer", key)
+	}
+	return number, nil
+}
+
+// # Author
+// - Polariusz
+func queryBool(c *fiber.Ctx, key string) (bool, error) {
+	value := c.Query(key)
+	if value == "" {
+		return false, nil
+	}
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("Query parameter '%s' must be true or false", key)
+	}
+	return flag, nil
+}
+
+// # Author
+// - Polariusz
+func queryTime(c *fiber.Ctx, key string) (time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Query parameter '%s' must be a RFC 3339 date", key)
+	}
+	return parsed, nil
+}
+
+// # Description
+// - The function shall return every value of a query parameter that may be repeated, like `?topic=a&topic=b`.
+//
+// # Author
+// - Polariusz
+func queryList(c *fiber.Ctx, key string) []string {
+	valueList := []string{}
+	for _, value := range c.Context().QueryArgs().PeekMulti(key) {
+		valueList = append(valueList, string(value))
+	}
+	return valueList
+}
+
+// # Author
+// - Polariusz
+func queryIdList(c *fiber.Ctx, key string) ([]int, error) {
+	idList := []int{}
+	for _, value := range queryList(c, key) {
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("Query parameter '%s' must be a positive integer", key)
+		}
+		idList = append(idList, id)
+	}
+	return idList, nil
+}
+
+// # Description
+// - The function shall return the broker and user of the path `/brokers/:brokerId/users/:userId`.
+//
+// # Author
+// - Polariusz
+func paramBrokerUser(c *fiber.Ctx) (BrokerUser, error) {
+	brokerId, err := paramId(c, "brokerId")
+	if err != nil {
+		return BrokerUser{}, err
+	}
+	userId, err := paramId(c, "userId")
+	if err != nil {
+		return BrokerUser{}, err
+	}
+	return BrokerUser{brokerId, userId}, nil
+}
+
+// # Description
+// - The function shall return the JSON body of the request as a map, or an empty map if there is no body.
+//
+// # Author
+// - Polariusz
+func bodyFields(c *fiber.Ctx) (fiber.Map, error) {
+	fields := fiber.Map{}
+	if len(c.Body()) == 0 {
+		return fields, nil
+	}
+	if err := json.Unmarshal(c.Body(), &fields); err != nil {
+		return nil, fmt.Errorf("The body is not a JSON object")
+	}
+	return fields, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall return a handler that builds the JSON body that `handler` expects from the path, the query and the body of the request, and calls `handler` with it.
+// - If `build` fails, the request is answered with `API_ERROR_INVALID_ARGUMENT`.
+//
+// # Author
+// - Polariusz
+func withBody(handler fiber.Handler, build func(c *fiber.Ctx) (any, error)) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		body, err := build(c)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, err.Error(), nil)
+		}
+		c.Request().SetBody(encoded)
+		c.Request().Header.SetContentType(fiber.MIMEApplicationJSON)
+
+		return handler(c)
+	}
+}
+
+// # Description
+// - The function shall return a `withBody()` handler whose body is the broker and user of the path.
+//
+// # Author
+// - Polariusz
+func withBrokerUser(handler fiber.Handler) fiber.Handler {
+	return withBody(handler, func(c *fiber.Ctx) (any, error) {
+		return paramBrokerUser(c)
+	})
+}
+
+// # Description
+// - The function shall return a `withBody()` handler whose body is the body of the request with the broker and user of the path added as "BrokerUserIDs".
+//
+// # Author
+// - Polariusz
+func withBrokerUserInBody(handler fiber.Handler) fiber.Handler {
+	return withBody(handler, func(c *fiber.Ctx) (any, error) {
+		brokerUser, err := paramBrokerUser(c)
+		if err != nil {
+			return nil, err
+		}
+		fields, err := bodyFields(c)
+		if err != nil {
+			return nil, err
+		}
+		fields["BrokerUserIDs"] = brokerUser
+		return fields, nil
+	})
+}
+
+// # Description
+// - The function shall return a `withBody()` handler whose body is {"Id":<I>} with the path parameter `:id`.
+//
+// # Author
+// - Polariusz
+func withPathId(handler fiber.Handler) fiber.Handler {
+	return withBody(handler, func(c *fiber.Ctx) (any, error) {
+		id, err := paramId(c, "id")
+		return fiber.Map{"Id": id}, err
+	})
+}
+
+// # Description
+// - The function shall return a `withBody()` handler whose body is {"Name":"<N>"} with the path parameter `:name`.
+//
+// # Author
+// - Polariusz
+func withPathName(handler fiber.Handler) fiber.Handler {
+	return withBody(handler, func(c *fiber.Ctx) (any, error) {
+		return fiber.Map{"Name": c.Params("name")}, nil
+	})
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - `GET /brokers/:brokerId/users/:userId/messages`
+// - Query parameters:
+//   - topic          : The topic, required
+//   - index          : The page, see TopicWrapper. 0 by default, -1 returns all messages.
+//   - since          : RFC 3339 date. If given, the messages received after it are returned instead of a page, see GetNewMessages.
+//   - hideDuplicates : true to leave out messages that are marked as duplicates
+//
+// # Author
+// - Polariusz
+func getMessagesV1(serverState *ServerState) fiber.Handler {
+	pageHandler := GetTopicMessagesHandler(serverState)
+	sinceHandler := GetTopicNewMessagesHandler(serverState)
+
+	return func(c *fiber.Ctx) error {
+		if c.Query("since") != "" {
+			return withBody(sinceHandler, func(c *fiber.Ctx) (any, error) {
+				brokerUser, err := paramBrokerUser(c)
+				if err != nil {
+					return nil, err
+				}
+				since, err := queryTime(c, "since")
+				if err != nil {
+					return nil, err
+				}
+				hideDuplicates, err := queryBool(c, "hideDuplicates")
+				return GetNewMessages{brokerUser, c.Query("topic"), since, hideDuplicates}, err
+			})(c)
+		}
+
+		return withBody(pageHandler, func(c *fiber.Ctx) (any, error) {
+			brokerUser, err := paramBrokerUser(c)
+			if err != nil {
+				return nil, err
+			}
+			index, err := queryInt(c, "index", 0)
+			if err != nil {
+				return nil, err
+			}
+			hideDuplicates, err := queryBool(c, "hideDuplicates")
+			return TopicWrapper{brokerUser, c.Query("topic"), index, hideDuplicates}, err
+		})(c)
+	}
+}
+
+// # Description
+// - `DELETE /brokers/:brokerId/users/:userId/subscriptions` and `.../favourites`, the topics are given as `?topic=<T>`, repeated for each topic.
+//
+// # Author
+// - Polariusz
+func withTopicsFromQuery(handler fiber.Handler) fiber.Handler {
+	return withBody(handler, func(c *fiber.Ctx) (any, error) {
+		brokerUser, err := paramBrokerUser(c)
+		return TopicsWrapper{brokerUser, queryList(c, "topic")}, err
+	})
+}
+
+// # Description
+// - `DELETE /brokers/:brokerId` and `DELETE /users/:userId`, with `?dryRun=true`.
+//
+// # Author
+// - Polariusz
+func withDryRunDelete(handler fiber.Handler, key string, field string) fiber.Handler {
+	return withBody(handler, func(c *fiber.Ctx) (any, error) {
+		id, err := paramId(c, key)
+		if err != nil {
+			return nil, err
+		}
+		dryRun, err := queryBool(c, "dryRun")
+		return fiber.Map{field: id, "DryRun": dryRun}, err
+	})
+}
+
+// # Description
+// - `DELETE /brokers/:brokerId/topics?topic=<T>&filter=<F>&dryRun=<D>`
+//
+// # Author
+// - Polariusz
+func deleteTopicsV1(serverState *ServerState) fiber.Handler {
+	return withBody(PostTopicDeleteHandler(serverState), func(c *fiber.Ctx) (any, error) {
+		brokerId, err := paramId(c, "brokerId")
+		if err != nil {
+			return nil, err
+		}
+		dryRun, err := queryBool(c, "dryRun")
+		return TopicDeleteWrapper{brokerId, queryList(c, "topic"), c.Query("filter"), dryRun}, err
+	})
+}
+
+// # Description
+// - `DELETE /messages?id=<I>&brokerId=<B>&topicFilter=<F>&userId=<U>&after=<A>&before=<E>&onlyDuplicates=<O>&dryRun=<D>`, see MessageDeleteWrapper.
+//
+// # Author
+// - Polariusz
+func deleteMessagesV1(serverState *ServerState) fiber.Handler {
+	return withBody(PostMessageDeleteHandler(serverState), func(c *fiber.Ctx) (any, error) {
+		var deleteWrapper MessageDeleteWrapper
+		var err error
+		if deleteWrapper.Ids, err = queryIdList(c, "id"); err != nil {
+			return nil, err
+		}
+		if deleteWrapper.BrokerId, err = queryInt(c, "brokerId", 0); err != nil {
+			return nil, err
+		}
+		if deleteWrapper.UserId, err = queryInt(c, "userId", 0); err != nil {
+			return nil, err
+		}
+		if deleteWrapper.After, err = queryTime(c, "after"); err != nil {
+			return nil, err
+		}
+		if deleteWrapper.Before, err = queryTime(c, "before"); err != nil {
+			return nil, err
+		}
+		if deleteWrapper.OnlyDuplicates, err = queryBool(c, "onlyDuplicates"); err != nil {
+			return nil, err
+		}
+		deleteWrapper.TopicFilter = c.Query("topicFilter")
+		deleteWrapper.DryRun, err = queryBool(c, "dryRun")
+		return deleteWrapper, err
+	})
+}
+
+// | Date of change | By        | Comment                    |
+// +----------------+-----------+----------------------------+
+// | 2026-10-19     | Polariusz | Created                    |
+// | 2026-10-19     | Polariusz | Described for the OpenAPI  |
+// | 2026-10-19     | Polariusz | Added the OpenAPI routes   |
+// | 2026-10-19     | Polariusz | Added the account routes   |
+// | 2026-10-19     | Polariusz | Added the audit routes     |
+// | 2026-10-19     | Polariusz | Added the webhook routes   |
+// | 2026-10-19     | Polariusz | Added the bridge routes    |
+// | 2026-10-19     | Polariusz | Added the alert routes     |
+// | 2026-10-19     | Polariusz | Added the schedule routes  |
+// | 2026-10-19     | Polariusz | Added the request route    |
+// | 2026-10-19     | Polariusz | Added the benchmark routes |
+// | 2026-10-19     | Polariusz | Added the ACL probe route  |
+//
+// # Description
+// - The function shall return every route of the versioned API, relative to `API_V1_PREFIX`.
+// - `/brokers/:brokerId/users/:userId` is the connection from the response of `POST /connections`.
+// - A route that is added here is added to the OpenAPI document too, openapi_test.go fails if a route is added to the server any other way.
+//
+// # Author
+// - Polariusz
+func apiV1Routes() []APIRoute {
+	const user = "/brokers/:brokerId/users/:userId"
+
+	topicResult := fiber.Map{"result": map[string]TopicResult{}}
+	deleted := fiber.Map{"dryRun": false, "deleted": database.DeleteCounts{}}
+	replay := fiber.Map{"replay": ReplayStatus{}}
+	dryRun := APIParameter{"dryRun", "boolean", false, "If true, nothing is deleted, only counted"}
+	topics := APIParameter{"topic", "string", true, "A topic"}
+	auditQuery := []APIParameter{
+		{"actor", "string", false, "Only the entries of this username, anonymous for the ones without accounts"},
+		{"action", "string", false, "Only this action, like publish or delete.topics"},
+		{"brokerId", "integer", false, "Only the entries of this broker"},
+		{"topic", "string", false, "Only the entries of this topic"},
+		{"topicFilter", "string", false, "Only the entries whose topic matches this MQTT topic filter"},
+		{"outcome", "string", false, "success, partial, denied, rejected or failed"},
+		{"after", "date-time", false, "Only the entries from this date on"},
+		{"before", "date-time", false, "Only the entries before this date"},
+	}
+	pageQuery := []APIParameter{
+		{"limit", "integer", false, "The size of the page, 100 by default, at most 1000"},
+		{"beforeId", "integer", false, "The nextBeforeId of the previous page"},
+	}
+
+	return []APIRoute{
+		{
+			Method: "GET", Path: "/openapi.json", Summary: "This OpenAPI document",
+			Response: fiber.Map{}, Public: true,
+			Handler: GetOpenAPIHandler,
+		},
+		{
+			Method: "GET", Path: "/docs", Summary: "A page that shows this OpenAPI document",
+			Produces: fiber.MIMETextHTMLCharsetUTF8, Public: true,
+			Handler: GetOpenAPIDocsHandler,
+		},
+
+		{
+			Method: "POST", Path: "/sessions", Summary: "Log in",
+			Request: AccountWrapper{}, Response: fiber.Map{"token": "", "session": SessionInfo{}}, Status: fiber.StatusCreated, Public: true,
+			Handler: PostSessionHandler,
+		},
+		{
+			Method: "GET", Path: "/sessions/current", Summary: "The session of the request",
+			Response: fiber.Map{"authentication": false, "session": &SessionInfo{}, "roles": []database.SelectRoleAssignment{}},
+			Handler: GetSessionHandler,
+		},
+		{
+			Method: "DELETE", Path: "/sessions/current", Summary: "Log out",
+			Response: fiber.Map{"Fine": ""},
+			Handler: DeleteSessionHandler,
+		},
+		{
+			Method: "GET", Path: "/accounts", Summary: "All accounts, for admins of all brokers",
+			Response: fiber.Map{"accounts": []database.SelectAccount{}},
+			Handler: GetAccountsHandler,
+		},
+		{
+			Method: "POST", Path: "/accounts", Summary: "Create an account, the first one only from the machine of the server, the others only by admins of all brokers",
+			Request: AccountWrapper{}, Response: fiber.Map{"Id": 0}, Status: fiber.StatusCreated,
+			Handler: PostAccountHandler,
+		},
+		{
+			Method: "DELETE", Path: "/accounts/:id", Summary: "Delete an account with its API keys and roles, for admins of all brokers",
+			Response: fiber.Map{"Id": 0},
+			Handler: DeleteAccountHandler,
+		},
+		{
+			Method: "PUT", Path: "/accounts/current/password", Summary: "Change the password of the account of the session",
+			Request: PasswordWrapper{}, Response: fiber.Map{"Fine": ""},
+			Handler: PutAccountPasswordHandler,
+		},
+		{
+			Method: "GET", Path: "/api-keys", Summary: "The API keys of the account of the session",
+			Response: fiber.Map{"apiKeys": []database.SelectApiKey{}},
+			Handler: GetApiKeysHandler,
+		},
+		{
+			Method: "POST", Path: "/api-keys", Summary: "Create an API key, it is only returned once",
+			Request: ApiKeyWrapper{}, Response: fiber.Map{"key": "", "apiKey": database.SelectApiKey{}}, Status: fiber.StatusCreated,
+			Handler: PostApiKeyHandler,
+		},
+		{
+			Method: "DELETE", Path: "/api-keys/:id", Summary: "Delete an API key",
+			Response: fiber.Map{"Id": 0},
+			Handler: DeleteApiKeyHandler,
+		},
+		{
+			Method: "GET", Path: "/roles", Summary: "The role assignments, for admins of all brokers",
+			Query: []APIParameter{{Name: "accountId", Type: "integer", Description: "Only the roles of this account"}},
+			Response: fiber.Map{"roles": []database.SelectRoleAssignment{}},
+			Handler: GetRolesHandler,
+		},
+		{
+			Method: "POST", Path: "/roles", Summary: "Give an account a role on a broker (0 for all) and a topic filter, for admins of all brokers",
+			Request: RoleWrapper{}, Response: fiber.Map{"Id": 0}, Status: fiber.StatusCreated,
+			Handler: PostRoleHandler,
+		},
+		{
+			Method: "DELETE", Path: "/roles/:id", Summary: "Take a role away, the last admin of all brokers stays",
+			Response: fiber.Map{"Id": 0},
+			Handler: DeleteRoleHandler,
+		},
+		{
+			Method: "GET", Path: "/audit", Summary: "A page of the audit log, the newest first, for admins of all brokers",
+			Query: append(auditQuery,
+				APIParameter{"limit", "integer", false, "The size of the page, 100 by default, at most 1000"},
+				APIParameter{"beforeId", "integer", false, "The nextBeforeId of the previous page"},
+			),
+			Response: fiber.Map{"entries": []database.SelectAuditEntry{}, "nextBeforeId": 0},
+			Handler: GetAuditHandler,
+		},
+		{
+			Method: "GET", Path: "/audit/export", Summary: "Download the matching entries of the audit log, for admins of all brokers",
+			Query: append(auditQuery, APIParameter{"format", "string", false, "csv (default) or ndjson"}),
+			Produces: "text/csv",
+			Handler: GetAuditExportHandler,
+		},
+
+		{
+			Method: "POST", Path: "/connections", Summary: "Connect to a broker",
+			Request: MqttCredentials{},
+			Response: fiber.Map{"goodJson": "", "brokerId": 0, "userId": 0, "subscribedTopics": []database.SelectUserTopicSubscribed{}},
+			Replaces: []LegacyRoute{{"POST /credentials", MqttCredentials{}, nil}},
+			Handler: PostCredentialsHandler,
+		},
+		{
+			Method: "GET", Path: "/connections/current", Summary: "Check the connection to the broker",
+			Response: fiber.Map{"Ok": "", "Fine": ""},
+			Replaces: []LegacyRoute{{"GET /ping", nil, nil}},
+			Handler: GetPingHandler,
+		},
+		{
+			Method: "DELETE", Path: "/connections/current", Summary: "Disconnect from the broker",
+			Response: fiber.Map{"Fine": ""},
+			Replaces: []LegacyRoute{{"POST /disconnect", nil, nil}},
+			Handler: PostDisconnectFromBrokerHandler,
+		},
+
+		{
+			Method: "GET", Path: user + "/topics", Summary: "All known topics and whether they are subscribed",
+			Response: fiber.Map{"Topics": []database.SelectTopicSubscribed{}},
+			Replaces: []LegacyRoute{
+				{"POST /topic/all-known", BrokerUser{}, fiber.Map{"Topics": []database.SelectTopic{}}},
+				{"POST /topic/all-known-subscribed", BrokerUser{}, nil},
+			},
+			Handler: func(s *ServerState) fiber.Handler { return withBrokerUser(PostTopicAllKnownSubscribedHandler(s)) },
+		},
+		{
+			Method: "GET", Path: user + "/subscriptions", Summary: "Subscribed topics",
+			Response: fiber.Map{"topics": []database.SelectUserTopicSubscribed{}},
+			Replaces: []LegacyRoute{{"GET /topic/subscribed", BrokerUser{}, nil}},
+			Handler: func(s *ServerState) fiber.Handler { return withBrokerUser(GetTopicSubscribedHandler(s)) },
+		},
+		{
+			Method: "POST", Path: user + "/subscriptions", Summary: "Subscribe to topics",
+			Request: TopicListBody{}, Response: topicResult, Partial: true,
+			Replaces: []LegacyRoute{{"POST /topic/subscribe", TopicsWrapper{}, nil}},
+			Handler: func(s *ServerState) fiber.Handler { return withBrokerUserInBody(PostTopicSubscribeHandler(s)) },
+		},
+		{
+			Method: "DELETE", Path: user + "/subscriptions", Summary: "Unsubscribe from topics",
+			Query: []APIParameter{topics},
+			Response: topicResult, Partial: true,
+			Replaces: []LegacyRoute{{"POST /topic/unsubscribe", TopicsWrapper{}, nil}},
+			Handler: func(s *ServerState) fiber.Handler { return withTopicsFromQuery(PostTopicUnsubscribeHandler(s)) },
+		},
+		{
+			Method: "GET", Path: user + "/favourites", Summary: "Favourite topics",
+			Response: fiber.Map{"Topics": []database.SelectFavTopic{}},
+			Replaces: []LegacyRoute{{"GET /topic/favourites", BrokerUser{}, nil}},
+			Handler: func(s *ServerState) fiber.Handler { return withBrokerUser(GetTopicFavourites(s)) },
+		},
+		{
+			Method: "POST", Path: user + "/favourites", Summary: "Mark topics as favourite",
+			Request: TopicListBody{}, Response: topicResult, Partial: true,
+			Replaces: []LegacyRoute{{"POST /topic/favourites/mark", TopicsWrapper{}, nil}},
+			Handler: func(s *ServerState) fiber.Handler { return withBrokerUserInBody(PostTopicFavouritesMark(s)) },
+		},
+		{
+			Method: "DELETE", Path: user + "/favourites", Summary: "Unmark favourite topics",
+			Query: []APIParameter{topics},
+			Response: topicResult, Partial: true,
+			Replaces: []LegacyRoute{{"POST /topic/favourites/unmark", TopicsWrapper{}, nil}},
+			Handler: func(s *ServerState) fiber.Handler { return withTopicsFromQuery(PostTopicFavouritesUnmark(s)) },
+		},
+		{
+			Method: "GET", Path: user + "/messages", Summary: "Stored messages of a topic",
+			Query: []APIParameter{
+				{"topic", "string", false, "The topic, required"},
+				{"index", "integer", false, "The page, 0 by default, -1 for all messages"},
+				{"since", "date-time", false, "If given, the messages received after it instead of a page"},
+				{"hideDuplicates", "boolean", false, "If true, the messages that are marked as duplicates are left out"},
+			},
+			Response: fiber.Map{"topic": "", "messages": []database.SelectMessage{}},
+			Replaces: []LegacyRoute{{"POST /topic/messages", TopicWrapper{}, nil}, {"GET /topic/new-messages", GetNewMessages{}, nil}},
+			Handler: getMessagesV1,
+		},
+		{
+			Method: "POST", Path: user + "/messages", Summary: "Publish a message",
+			Request: PublishBody{}, Response: fiber.Map{"goodJson": ""},
+			Replaces: []LegacyRoute{{"POST /topic/send-message", MessageWrapper{}, nil}},
+			Handler: func(s *ServerState) fiber.Handler { return withBrokerUserInBody(PostTopicSendMessageHandler(s)) },
+		},
+
+		{
+			Method: "GET", Path: "/brokers/:brokerId/stats", Summary: "Message statistics of a broker",
+			Response: fiber.Map{"topics": []TopicStats{}, "clients": []ClientStats{}},
+			Replaces: []LegacyRoute{{"GET /stats", nil, nil}},
+			Handler: func(s *ServerState) fiber.Handler {
+				handler := GetStatsHandler(s)
+				return func(c *fiber.Ctx) error {
+					brokerId, err := paramId(c, "brokerId")
+					if err != nil {
+						return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+					}
+					c.Context().QueryArgs().Set("BrokerId", strconv.Itoa(brokerId))
+					c.Context().QueryArgs().Del("UserId")
+					return handler(c)
+				}
+			},
+		},
+		{
+			Method: "DELETE", Path: "/brokers/:brokerId", Summary: "Delete a broker and everything of it",
+			Query: []APIParameter{dryRun}, Response: deleted,
+			Replaces: []LegacyRoute{{"POST /broker/delete", BrokerDeleteWrapper{}, nil}},
+			Handler: func(s *ServerState) fiber.Handler { return withDryRunDelete(PostBrokerDeleteHandler(s), "brokerId", "BrokerId") },
+		},
+		{
+			Method: "DELETE", Path: "/brokers/:brokerId/topics", Summary: "Delete topics and everything of them",
+			Query: []APIParameter{topics, {"filter", "string", false, "A topic filter, the matching topics are deleted too"}, dryRun},
+			Response: fiber.Map{"dryRun": false, "topics": []int{}, "deleted": database.DeleteCounts{}},
+			Replaces: []LegacyRoute{{"POST /topic/delete", TopicDeleteWrapper{}, nil}},
+			Handler: deleteTopicsV1,
+		},
+		{
+			Method: "DELETE", Path: "/users/:userId", Summary: "Delete a user and everything of it",
+			Query: []APIParameter{dryRun}, Response: deleted,
+			Replaces: []LegacyRoute{{"POST /user/delete", UserDeleteWrapper{}, nil}},
+			Handler: func(s *ServerState) fiber.Handler { return withDryRunDelete(PostUserDeleteHandler(s), "userId", "UserId") },
+		},
+		{
+			Method: "DELETE", Path: "/messages", Summary: "Delete messages by ID or filter",
+			Query: []APIParameter{
+				{"id", "integer", true, "A message ID"},
+				{"brokerId", "integer", false, "Only messages of the broker"},
+				{"topicFilter", "string", false, "Only messages of the topics that match the filter"},
+				{"userId", "integer", false, "Only messages published by the user"},
+				{"after", "date-time", false, "Only messages received after it"},
+				{"before", "date-time", false, "Only messages received before it"},
+				{"onlyDuplicates", "boolean", false, "Only messages that are marked as duplicates"},
+				dryRun,
+			},
+			Response: deleted,
+			Replaces: []LegacyRoute{{"POST /message/delete", MessageDeleteWrapper{}, nil}},
+			Handler: deleteMessagesV1,
+		},
+
+		{
+			Method: "POST", Path: "/replays", Summary: "Start a replay",
+			Request: ReplayWrapper{}, Response: replay,
+			Replaces: []LegacyRoute{{"POST /replay/start", ReplayWrapper{}, nil}},
+			Handler: PostReplayStartHandler,
+		},
+		{
+			Method: "GET", Path: "/replays", Summary: "All replays",
+			Response: fiber.Map{"replays": []ReplayStatus{}},
+			Replaces: []LegacyRoute{{"GET /replay/jobs", nil, nil}},
+			Handler: GetReplayJobsHandler,
+		},
+		{
+			Method: "GET", Path: "/replays/:id", Summary: "A replay",
+			Response: replay,
+			Replaces: []LegacyRoute{{"POST /replay/status", ReplayJobWrapper{}, nil}},
+			Handler: func(s *ServerState) fiber.Handler { return withPathId(PostReplayStatusHandler(s)) },
+		},
+		{
+			Method: "POST", Path: "/replays/:id/pause", Summary: "Pause a replay",
+			Response: replay,
+			Replaces: []LegacyRoute{{"POST /replay/pause", ReplayJobWrapper{}, nil}},
+			Handler: func(s *ServerState) fiber.Handler { return withPathId(PostReplayPauseHandler(s)) },
+		},
+		{
+			Method: "POST", Path: "/replays/:id/resume", Summary: "Resume a replay",
+			Response: replay,
+			Replaces: []LegacyRoute{{"POST /replay/resume", ReplayJobWrapper{}, nil}},
+			Handler: func(s *ServerState) fiber.Handler { return withPathId(PostReplayResumeHandler(s)) },
+		},
+		{
+			Method: "POST", Path: "/replays/:id/cancel", Summary: "Cancel a replay",
+			Response: replay,
+			Replaces: []LegacyRoute{{"POST /replay/cancel", ReplayJobWrapper{}, nil}},
+			Handler: func(s *ServerState) fiber.Handler { return withPathId(PostReplayCancelHandler(s)) },
+		},
+
+		{
+			Method: "GET", Path: "/ingest/metrics", Summary: "Metrics of the ingest queue",
+			Response: fiber.Map{"ingest": IngestMetrics{}},
+			Replaces: []LegacyRoute{{"GET /ingest/metrics", nil, nil}},
+			Handler: GetIngestMetricsHandler,
+		},
+		{
+			Method: "PUT", Path: "/ingest/overflow", Summary: "Change the overflow behaviour of the ingest queue",
+			Request: IngestOverflowWrapper{}, Response: fiber.Map{"ingest": IngestMetrics{}},
+			Replaces: []LegacyRoute{{"POST /ingest/overflow", IngestOverflowWrapper{}, nil}},
+			Handler: PostIngestOverflowHandler,
+		},
+
+		{
+			Method: "GET", Path: "/retention/rules", Summary: "All retention rules",
+			Response: fiber.Map{"rules": []database.SelectRetentionRule{}},
+			Replaces: []LegacyRoute{{"GET /retention/rules", nil, nil}},
+			Handler: GetRetentionRulesHandler,
+		},
+		{
+			Method: "POST", Path: "/retention/rules", Summary: "Create a retention rule",
+			Request: RetentionRuleWrapper{}, Response: fiber.Map{"Id": 0}, Status: fiber.StatusCreated,
+			Replaces: []LegacyRoute{{"POST /retention/rules", RetentionRuleWrapper{}, nil}},
+			Handler: PostRetentionRuleCreateHandler,
+		},
+		{
+			Method: "DELETE", Path: "/retention/rules/:id", Summary: "Delete a retention rule",
+			Response: fiber.Map{"Id": 0},
+			Replaces: []LegacyRoute{{"POST /retention/rules/delete", RetentionRuleIdWrapper{}, nil}},
+			Handler: func(s *ServerState) fiber.Handler { return withPathId(PostRetentionRuleDeleteHandler(s)) },
+		},
+		{
+			Method: "GET", Path: "/retention/report", Summary: "What the retention rules have deleted",
+			Response: fiber.Map{"rules": []RetentionRuleReport{}, "lastRun": RetentionRun{}},
+			Replaces: []LegacyRoute{{"GET /retention/report", nil, nil}},
+			Handler: GetRetentionReportHandler,
+		},
+		{
+			Method: "POST", Path: "/retention/runs", Summary: "Apply the retention rules now",
+			Response: fiber.Map{"run": RetentionRun{}},
+			Replaces: []LegacyRoute{{"POST /retention/run", nil, nil}},
+			Handler: PostRetentionRunHandler,
+		},
+
+		{
+			Method: "GET", Path: "/dedup/rules", Summary: "All deduplication rules",
+			Response: fiber.Map{"rules": []database.SelectDedupRule{}},
+			Replaces: []LegacyRoute{{"GET /dedup/rules", nil, nil}},
+			Handler: GetDedupRulesHandler,
+		},
+		{
+			Method: "POST", Path: "/dedup/rules", Summary: "Create a deduplication rule",
+			Request: DedupRuleWrapper{}, Response: fiber.Map{"Id": 0}, Status: fiber.StatusCreated,
+			Replaces: []LegacyRoute{{"POST /dedup/rules", DedupRuleWrapper{}, nil}},
+			Handler: PostDedupRuleCreateHandler,
+		},
+		{
+			Method: "DELETE", Path: "/dedup/rules/:id", Summary: "Delete a deduplication rule",
+			Response: fiber.Map{"Id": 0},
+			Replaces: []LegacyRoute{{"POST /dedup/rules/delete", DedupRuleIdWrapper{}, nil}},
+			Handler: func(s *ServerState) fiber.Handler { return withPathId(PostDedupRuleDeleteHandler(s)) },
+		},
+		{
+			Method: "GET", Path: "/dedup/report", Summary: "Duplicates per topic",
+			Response: fiber.Map{"duplicates": []database.SelectDuplicateCount{}, "total": 0},
+			Replaces: []LegacyRoute{{"GET /dedup/report", nil, nil}},
+			Handler: GetDedupReportHandler,
+		},
+
+		{
+			Method: "GET", Path: "/webhooks", Summary: "The webhooks the account is an admin of",
+			Response: fiber.Map{"webhooks": []database.SelectWebhook{}, "overflowed": 0},
+			Handler: GetWebhooksHandler,
+		},
+		{
+			Method: "POST", Path: "/webhooks", Summary: "Create a webhook for the messages received from now on, for admins of its broker and topic filter",
+			Request: WebhookWrapper{}, Response: fiber.Map{"Id": 0}, Status: fiber.StatusCreated,
+			Handler: PostWebhookHandler,
+		},
+		{
+			Method: "DELETE", Path: "/webhooks/:id", Summary: "Delete a webhook with its deliveries",
+			Response: fiber.Map{"Id": 0},
+			Handler: DeleteWebhookHandler,
+		},
+		{
+			Method: "GET", Path: "/webhooks/:id/deliveries", Summary: "A page of the deliveries of a webhook, the newest first",
+			Query: append([]APIParameter{{"status", "string", false, "pending, retrying, delivered or dead"}}, pageQuery...),
+			Response: fiber.Map{"deliveries": []database.SelectWebhookDelivery{}, "nextBeforeId": 0},
+			Handler: GetWebhookDeliveriesHandler,
+		},
+		{
+			Method: "GET", Path: "/webhooks/dead-letters", Summary: "A page of the deliveries that failed every attempt, the newest first",
+			Query: pageQuery,
+			Response: fiber.Map{"deliveries": []database.SelectWebhookDelivery{}, "nextBeforeId": 0},
+			Handler: GetDeadLettersHandler,
+		},
+		{
+			Method: "POST", Path: "/webhooks/dead-letters/:id/retry", Summary: "Try a dead delivery again with all attempts of its webhook",
+			Response: fiber.Map{"Id": 0},
+			Handler: PostDeadLetterRetryHandler,
+		},
+		{
+			Method: "DELETE", Path: "/webhooks/dead-letters/:id", Summary: "Delete a dead delivery",
+			Response: fiber.Map{"Id": 0},
+			Handler: DeleteDeadLetterHandler,
+		},
+
+		{
+			Method: "GET", Path: "/bridges", Summary: "All bridges with their status, for admins of all brokers",
+			Response: fiber.Map{"bridges": []BridgeView{}},
+			Handler: GetBridgesHandler,
+		},
+		{
+			Method: "POST", Path: "/bridges", Summary: "Create a stopped bridge, for admins of all brokers",
+			Request: BridgeWrapper{}, Response: fiber.Map{"Id": 0}, Status: fiber.StatusCreated,
+			Handler: PostBridgeHandler,
+		},
+		{
+			Method: "GET", Path: "/bridges/:id", Summary: "A bridge with its status",
+			Response: BridgeView{},
+			Handler: GetBridgeHandler,
+		},
+		{
+			Method: "DELETE", Path: "/bridges/:id", Summary: "Stop and delete a bridge",
+			Response: fiber.Map{"Id": 0},
+			Handler: DeleteBridgeHandler,
+		},
+		{
+			Method: "POST", Path: "/bridges/:id/start", Summary: "Start a bridge, also after restarts of the server",
+			Response: BridgeView{},
+			Handler: PostBridgeStartHandler,
+		},
+		{
+			Method: "POST", Path: "/bridges/:id/stop", Summary: "Stop a bridge",
+			Response: BridgeView{},
+			Handler: PostBridgeStopHandler,
+		},
+
+		{
+			Method: "GET", Path: "/alerts/rules", Summary: "The alert rules the account may see",
+			Response: fiber.Map{"rules": []database.SelectAlertRule{}},
+			Handler: GetAlertRulesHandler,
+		},
+		{
+			Method: "POST", Path: "/alerts/rules", Summary: "Create an alert rule for the messages received from now on, for operators of its broker and topic filter",
+			Request: AlertRuleWrapper{}, Response: fiber.Map{"Id": 0}, Status: fiber.StatusCreated,
+			Handler: PostAlertRuleHandler,
+		},
+		{
+			Method: "DELETE", Path: "/alerts/rules/:id", Summary: "Delete an alert rule with its events",
+			Response: fiber.Map{"Id": 0},
+			Handler: DeleteAlertRuleHandler,
+		},
+		{
+			Method: "GET", Path: "/alerts/active", Summary: "The alerts that are firing, the oldest first",
+			Response: fiber.Map{"alerts": []ActiveAlert{}},
+			Handler: GetActiveAlertsHandler,
+		},
+		{
+			Method: "GET", Path: "/alerts/events", Summary: "A page of the alert events, the newest first",
+			Query: append([]APIParameter{
+				{"ruleId", "integer", false, "Only the events of this rule"},
+				{"brokerId", "integer", false, "Only the events of this broker"},
+				{"state", "string", false, "firing or resolved"},
+			}, pageQuery...),
+			Response: fiber.Map{"events": []database.SelectAlertEvent{}, "nextBeforeId": 0},
+			Handler: GetAlertEventsHandler,
+		},
+		{
+			Method: "GET", Path: "/alerts/stream", Summary: "The alert events from now on as server-sent events",
+			Produces: "text/event-stream",
+			Handler: GetAlertStreamHandler,
+		},
+
+		{
+			Method: "GET", Path: "/schedules", Summary: "The schedules the account may see",
+			Response: fiber.Map{"schedules": []database.SelectSchedule{}},
+			Handler: GetSchedulesHandler,
+		},
+		{
+			Method: "POST", Path: "/schedules", Summary: "Create an enabled schedule that publishes through the MQTT-Client, for operators of its broker and topic",
+			Request: ScheduleWrapper{}, Response: fiber.Map{"Id": 0, "NextRunAt": time.Time{}}, Status: fiber.StatusCreated,
+			Handler: PostScheduleHandler,
+		},
+		{
+			Method: "GET", Path: "/schedules/:id", Summary: "A schedule with its next run",
+			Response: database.SelectSchedule{},
+			Handler: GetScheduleHandler,
+		},
+		{
+			Method: "DELETE", Path: "/schedules/:id", Summary: "Delete a schedule with its runs",
+			Response: fiber.Map{"Id": 0},
+			Handler: DeleteScheduleHandler,
+		},
+		{
+			Method: "POST", Path: "/schedules/:id/enable", Summary: "Enable a schedule, also after restarts of the server",
+			Response: database.SelectSchedule{},
+			Handler: PostScheduleEnableHandler,
+		},
+		{
+			Method: "POST", Path: "/schedules/:id/disable", Summary: "Disable a schedule",
+			Response: database.SelectSchedule{},
+			Handler: PostScheduleDisableHandler,
+		},
+		{
+			Method: "GET", Path: "/schedules/:id/runs", Summary: "A page of the runs of a schedule, the newest first",
+			Query: append([]APIParameter{{"status", "string", false, "published, failed or skipped"}}, pageQuery...),
+			Response: fiber.Map{"runs": []database.SelectScheduleRun{}, "nextBeforeId": 0},
+			Handler: GetScheduleRunsHandler,
+		},
+
+		{
+			Method: "POST", Path: "/requests", Summary: "Publish a request through the broker of the MQTT-Client and wait for the response with its correlation ID",
+			Request: RequestWrapper{}, Response: RequestResult{},
+			Handler: PostRequestHandler,
+		},
+		{
+			Method: "POST", Path: "/probes/acl", Summary: "Try to subscribe and publish to topic patterns as the MQTT-Client, for an allow/deny matrix of the ACL of the broker",
+			Request: ProbeWrapper{}, Response: fiber.Map{"ClientId": "", "Protocol": "", "PubackReasonCodes": "", "results": []ProbeRow{}},
+			Handler: PostAclProbeHandler,
+		},
+
+		{
+			Method: "GET", Path: "/benchmarks", Summary: "All benchmarks with their results, the newest first, for admins of all brokers",
+			Response: fiber.Map{"benchmarks": []database.SelectBenchmark{}},
+			Handler: GetBenchmarksHandler,
+		},
+		{
+			Method: "POST", Path: "/benchmarks", Summary: "Start a benchmark that loads a broker with simulated clients, for admins of all brokers",
+			Request: BenchmarkWrapper{}, Response: fiber.Map{"Id": 0}, Status: fiber.StatusAccepted,
+			Handler: PostBenchmarkHandler,
+		},
+		{
+			Method: "GET", Path: "/benchmarks/:id", Summary: "A benchmark with its result, or how far it is while it runs",
+			Response: database.SelectBenchmark{},
+			Handler: GetBenchmarkHandler,
+		},
+		{
+			Method: "DELETE", Path: "/benchmarks/:id", Summary: "Delete a benchmark that is not running",
+			Response: fiber.Map{"Id": 0},
+			Handler: DeleteBenchmarkHandler,
+		},
+		{
+			Method: "POST", Path: "/benchmarks/:id/cancel", Summary: "Cancel a running benchmark, what it has measured is kept",
+			Response: fiber.Map{"Id": 0}, Status: fiber.StatusAccepted,
+			Handler: PostBenchmarkCancelHandler,
+		},
+
+		{
+			Method: "GET", Path: "/projects", Summary: "All projects",
+			Response: fiber.Map{"current": "", "inMemory": false, "projects": []string{}},
+			Replaces: []LegacyRoute{{"GET /projects", nil, nil}},
+			Handler: GetProjectsHandler,
+		},
+		{
+			Method: "PUT", Path: "/projects/current", Summary: "Select a project",
+			Request: ProjectWrapper{}, Response: fiber.Map{"current": ""},
+			Replaces: []LegacyRoute{{"POST /projects/select", ProjectWrapper{}, nil}},
+			Handler: PostProjectSelectHandler,
+		},
+
+		{
+			Method: "GET", Path: "/backups", Summary: "All backups",
+			Response: fiber.Map{"backups": []BackupFile{}},
+			Replaces: []LegacyRoute{{"GET /backup", nil, nil}},
+			Handler: GetBackupsHandler,
+		},
+		{
+			Method: "POST", Path: "/backups", Summary: "Create a backup",
+			Request: BackupCreateWrapper{}, Response: fiber.Map{"backup": BackupFile{}}, Status: fiber.StatusCreated,
+			Replaces: []LegacyRoute{{"POST /backup/create", BackupCreateWrapper{}, nil}},
+			Handler: PostBackupCreateHandler,
+		},
+		{
+			Method: "POST", Path: "/backups/:name/validate", Summary: "Validate a backup",
+			Response: fiber.Map{"backup": database.BackupInfo{}},
+			Replaces: []LegacyRoute{{"POST /backup/validate", BackupNameWrapper{}, nil}},
+			Handler: func(s *ServerState) fiber.Handler { return withPathName(PostBackupValidateHandler(s)) },
+		},
+		{
+			Method: "POST", Path: "/backups/:name/restore", Summary: "Restore a backup",
+			Response: fiber.Map{"backup": database.BackupInfo{}},
+			Replaces: []LegacyRoute{{"POST /backup/restore", BackupNameWrapper{}, nil}},
+			Handler: func(s *ServerState) fiber.Handler { return withPathName(PostBackupRestoreHandler(s)) },
+		},
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Routing
+//
+// # Description
+// - The method shall add the routes of apiV1Routes() under `API_V1_PREFIX`, with the error envelope and a `API_ERROR_ROUTE_NOT_FOUND` for unknown routes.
+//
+// # Author
+// - Polariusz
+func addAPIV1Routes(server *fiber.App, serverState *ServerState) {
+	api := server.Group(API_V1_PREFIX, apiErrorEnvelope)
+
+	for _, route := range apiV1Routes() {
+		api.Add(route.Method, route.Path, route.Handler(serverState))
+	}
+
+	api.Use(func(c *fiber.Ctx) error {
+		return writeAPIError(c, fiber.StatusNotFound, API_ERROR_ROUTE_NOT_FOUND, fmt.Sprintf("There is no route %s %s", c.Method(), c.Path()), nil)
+	})
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Middleware
+//
+// # Description
+// - The middleware shall mark the responses of the routes that a route of the versioned API replaces as deprecated.
+//   - `Deprecation: true`
+//   - `Link: <API_V1_PREFIX/...>; rel="successor-version"`
+//
+// # Author
+// - Polariusz
+func deprecatedRoutes() fiber.Handler {
+	successors := make(map[string]string)
+	for _, route := range apiV1Routes() {
+		for _, replaced := range route.Replaces {
+			successors[replaced.Route] = API_V1_PREFIX + route.Path
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		if successor, ok := successors[c.Method()+" "+c.Path()]; ok {
+			c.Set("Deprecation", "true")
+			c.Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+		}
+		return c.Next()
+	}
+}
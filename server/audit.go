@@ -0,0 +1,432 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"database"
+	"database/sql"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// | Date of change | By        | Comment       |
+// +----------------+-----------+---------------+
+// | 2026-10-19     | Polariusz | Created       |
+// | 2026-10-19     | Polariusz | Admin actions |
+//
+// # Description
+// - The actions of the HTTP API that are written to the audit log, see AuditLog.
+const (
+	AUDIT_CONNECT = "connect"
+	AUDIT_DISCONNECT = "disconnect"
+	AUDIT_SUBSCRIBE = "subscribe"
+	AUDIT_UNSUBSCRIBE = "unsubscribe"
+	AUDIT_PUBLISH = "publish"
+	AUDIT_REPLAY = "replay"
+	AUDIT_FAVOURITE_MARK = "favourite.mark"
+	AUDIT_FAVOURITE_UNMARK = "favourite.unmark"
+	AUDIT_DELETE_BROKER = "delete.broker"
+	AUDIT_DELETE_USER = "delete.user"
+	AUDIT_DELETE_TOPICS = "delete.topics"
+	AUDIT_DELETE_MESSAGES = "delete.messages"
+	AUDIT_PROBE = "probe.acl"
+	AUDIT_DELETE_RETENTION_RULE = "delete.retentionRule"
+	AUDIT_DELETE_DEDUP_RULE = "delete.dedupRule"
+	AUDIT_ACCOUNT_CREATE = "account.create"
+	AUDIT_ACCOUNT_DELETE = "account.delete"
+	AUDIT_ROLE_GRANT = "role.grant"
+	AUDIT_ROLE_REVOKE = "role.revoke"
+	AUDIT_BACKUP_RESTORE = "backup.restore"
+	AUDIT_DELETE_WEBHOOK = "delete.webhook"
+	AUDIT_DELETE_BRIDGE = "delete.bridge"
+	AUDIT_DELETE_ALERT_RULE = "delete.alertRule"
+	AUDIT_DELETE_SCHEDULE = "delete.schedule"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - How an audited action ended, derived from the HTTP status of its response, see auditOutcome().
+const (
+	AUDIT_OUTCOME_SUCCESS = "success"
+	AUDIT_OUTCOME_PARTIAL = "partial"
+	AUDIT_OUTCOME_DENIED = "denied"
+	AUDIT_OUTCOME_REJECTED = "rejected"
+	AUDIT_OUTCOME_FAILED = "failed"
+)
+
+// The actor of the entries that were made while the API had no accounts.
+const AUDIT_ANONYMOUS = "anonymous"
+
+// The most bytes of the response that are kept as the detail of an entry.
+const AUDIT_DETAIL_LIMIT = 512
+
+// The most entries of one page of GetAuditHandler().
+const AUDIT_PAGE_LIMIT = 1000
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - It appends the actions of the HTTP API to table AuditLog of the default project, so the log stays the same when another project is selected.
+// - The log is append-only, the database refuses to change or delete its rows.
+// - Without a database, nothing is recorded.
+//
+// # Author
+// - Polariusz
+type AuditLog struct {
+	con *sql.DB
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : The database of the default project, nil to run without an audit log.
+//
+// # Author
+// - Polariusz
+func NewAuditLog(con *sql.DB) *AuditLog {
+	return &AuditLog{con: con}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - What a handler knows about its action, it fills the fields while it goes, see (*AuditLog).record().
+// - Every topic gets an entry of its own, so the log can be filtered by topic. An action without topics gets one entry.
+// - `PayloadHash` is set by hashPayload() for the actions that publish.
+//
+// # Author
+// - Polariusz
+type AuditRecord struct {
+	Action string
+	BrokerId int
+	UserId int
+	Topics []string
+	PayloadHash string
+}
+
+// # Description
+// - The function shall return the hex SHA-256 of the payload, as it is kept in the audit log instead of the payload.
+//
+// # Author
+// - Polariusz
+func hashPayload(payload string) string {
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:])
+}
+
+// # Description
+// - The function shall tell how an action ended from the HTTP status of its response.
+//
+// # Author
+// - Polariusz
+func auditOutcome(status int) string {
+	switch {
+	case status == fiber.StatusMultiStatus:
+		return AUDIT_OUTCOME_PARTIAL
+	case status < 300:
+		return AUDIT_OUTCOME_SUCCESS
+	case status == fiber.StatusUnauthorized || status == fiber.StatusForbidden:
+		return AUDIT_OUTCOME_DENIED
+	case status < 500:
+		return AUDIT_OUTCOME_REJECTED
+	default:
+		return AUDIT_OUTCOME_FAILED
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall append the action of the request to the audit log, with the account of the session, the status of the response and the start of its body.
+// - The handlers defer it right at their start, so it also records the actions that were rejected or failed.
+// - An error is only printed, the response was already made.
+//
+// # Usage
+// - record := AuditRecord{Action: AUDIT_PUBLISH}
+// - defer serverState.auditLog.record(c, &record)
+//
+// # Author
+// - Polariusz
+func (al *AuditLog) record(c *fiber.Ctx, record *AuditRecord) {
+	if al == nil || al.con == nil {
+		return
+	}
+
+	entry := database.InsertAuditEntry{
+		Actor: AUDIT_ANONYMOUS,
+		RemoteAddress: c.IP(),
+		Action: record.Action,
+		BrokerId: record.BrokerId,
+		UserId: record.UserId,
+		PayloadHash: record.PayloadHash,
+		Status: c.Response().StatusCode(),
+	}
+	if session := sessionOf(c); session != nil {
+		entry.AccountId = session.AccountId
+		entry.Actor = session.Username
+	}
+	entry.Outcome = auditOutcome(entry.Status)
+
+	detail := string(c.Response().Body())
+	if len(detail) > AUDIT_DETAIL_LIMIT {
+		detail = detail[:AUDIT_DETAIL_LIMIT]
+	}
+	entry.Detail = strings.TrimSpace(detail)
+
+	topics := record.Topics
+	if len(topics) == 0 {
+		topics = []string{""}
+	}
+	for _, topic := range topics {
+		entry.Topic = topic
+		if _, err := database.InsertNewAuditEntry(al.con, entry); err != nil {
+			fmt.Printf("ERROR: The %s of %s was not written to the audit log!\nErr: %s\n", entry.Action, entry.Actor, err)
+		}
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall select a page of the entries that match `filter` and the MQTT topic filter `topicFilter`, the newest first.
+// - The database only compares the part of `topicFilter` before its first wildcard, the rest is matched here, so it may select a few more batches.
+//
+// # Returns
+// - A list of struct `database.SelectAuditEntry`, at most `limit`
+// - int: The `beforeId` of the next page, 0 if this is the last one
+// - error when:
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func (al *AuditLog) query(filter database.AuditFilter, topicFilter string, limit int) ([]database.SelectAuditEntry, int, error) {
+	entryList := []database.SelectAuditEntry{}
+	if al == nil || al.con == nil {
+		return entryList, 0, nil
+	}
+
+	if topicFilter != "" {
+		filter.TopicPrefix = topicFilter
+		if wildcard := strings.IndexAny(topicFilter, "+#"); wildcard >= 0 {
+			filter.TopicPrefix = topicFilter[:wildcard]
+		}
+	}
+
+	for {
+		batch, err := database.SelectAuditEntries(al.con, filter, limit)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		for _, entry := range batch {
+			if topicFilter != "" && !topicMatchesFilter(topicFilter, entry.Topic) {
+				continue
+			}
+			entryList = append(entryList, entry)
+			if len(entryList) == limit {
+				return entryList, entry.Id, nil
+			}
+		}
+
+		if len(batch) < limit {
+			return entryList, 0, nil
+		}
+		filter.BeforeId = batch[len(batch)-1].Id
+	}
+}
+
+// # Description
+// - The function shall read the filter of GetAuditHandler() and GetAuditExportHandler() from the query of the request.
+//
+// # Returns
+// - The filter for the database and the MQTT topic filter
+// - error when a parameter is not valid, the message can be shown to the client
+//
+// # Author
+// - Polariusz
+func auditFilterFromQuery(c *fiber.Ctx) (database.AuditFilter, string, error) {
+	var filter database.AuditFilter
+	var err error
+
+	filter.Actor = c.Query("actor")
+	filter.Action = c.Query("action")
+	filter.Topic = c.Query("topic")
+	filter.Outcome = c.Query("outcome")
+	if filter.BrokerId, err = queryInt(c, "brokerId", 0); err != nil {
+		return filter, "", err
+	}
+	if filter.After, err = queryTime(c, "after"); err != nil {
+		return filter, "", err
+	}
+	if filter.Before, err = queryTime(c, "before"); err != nil {
+		return filter, "", err
+	}
+	if filter.BeforeId, err = queryInt(c, "beforeId", 0); err != nil {
+		return filter, "", err
+	}
+
+	return filter, c.Query("topicFilter"), nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return a page of the audit log, the newest entries first. Only an admin of all brokers may call it.
+// - The query narrows the entries down, see auditFilterFromQuery(). `limit` is the size of the page, 100 by default.
+// - The next page is requested with `beforeId` set to the `nextBeforeId` of the response.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"entries":[<database.SelectAuditEntry>],"nextBeforeId":<N>}
+//     - <N> : 0 if there are no more entries
+// - 400 (Bad Request): `APIErrorEnvelope`
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 500 (Internal Server Error): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func GetAuditHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+
+		filter, topicFilter, err := auditFilterFromQuery(c)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+		limit, err := queryInt(c, "limit", 100)
+		if err != nil || limit <= 0 || limit > AUDIT_PAGE_LIMIT {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, fmt.Sprintf("Query parameter 'limit' must be between 1 and %d", AUDIT_PAGE_LIMIT), nil)
+		}
+
+		entryList, nextBeforeId, err := serverState.auditLog.query(filter, topicFilter, limit)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the audit log", map[string]any{"Error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"entries": entryList,
+			"nextBeforeId": nextBeforeId,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall download every entry of the audit log that matches the query, the newest first. Only an admin of all brokers may call it.
+// - The query is the one of GetAuditHandler() without `limit`, `format` is `csv` (default) or `ndjson` with one JSON entry per line.
+// - The entries are written while they are selected, page by page, so a large log is not held in memory.
+//
+// # Returns
+// - 200 (Ok): text/csv or application/x-ndjson as an attachment
+// - 400 (Bad Request): `APIErrorEnvelope`
+// - 403 (Forbidden): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func GetAuditExportHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+
+		filter, topicFilter, err := auditFilterFromQuery(c)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+		format := c.Query("format", "csv")
+		if format != "csv" && format != "ndjson" {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, "Query parameter 'format' must be csv or ndjson", nil)
+		}
+
+		// Attachment() guesses the type from the extension, so it is set afterwards.
+		c.Attachment(fmt.Sprintf("audit-%s.%s", time.Now().Format("20060102-150405"), format))
+		if format == "csv" {
+			c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		} else {
+			c.Set(fiber.HeaderContentType, "application/x-ndjson")
+		}
+
+		auditLog := serverState.auditLog
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			csvWriter := csv.NewWriter(w)
+			if format == "csv" {
+				csvWriter.Write([]string{"Id", "CreationDate", "AccountId", "Actor", "RemoteAddress", "Action", "BrokerId", "UserId", "Topic", "PayloadHash", "Outcome", "Status", "Detail"})
+			}
+
+			for {
+				entryList, nextBeforeId, err := auditLog.query(filter, topicFilter, AUDIT_PAGE_LIMIT)
+				if err != nil {
+					fmt.Printf("ERROR: The export of the audit log stopped!\nErr: %s\n", err)
+					break
+				}
+
+				for _, entry := range entryList {
+					if format == "csv" {
+						csvWriter.Write([]string{
+							strconv.Itoa(entry.Id), entry.CreationDate.Format(time.RFC3339Nano), strconv.Itoa(entry.AccountId), entry.Actor, entry.RemoteAddress,
+							entry.Action, strconv.Itoa(entry.BrokerId), strconv.Itoa(entry.UserId), entry.Topic, entry.PayloadHash,
+							entry.Outcome, strconv.Itoa(entry.Status), entry.Detail,
+						})
+						continue
+					}
+					line, _ := json.Marshal(entry)
+					w.Write(line)
+					w.WriteByte('\n')
+				}
+				csvWriter.Flush()
+				w.Flush()
+
+				if nextBeforeId == 0 {
+					break
+				}
+				filter.BeforeId = nextBeforeId
+			}
+		})
+
+		return nil
+	}
+}
+
+// # Description
+// - The method shall remember the broker, the user and the topics of the action.
+//
+// # Author
+// - Polariusz
+func (record *AuditRecord) about(brokerUser BrokerUser, topics ...string) {
+	record.BrokerId = brokerUser.BrokerId
+	record.UserId = brokerUser.UserId
+	record.Topics = topics
+}
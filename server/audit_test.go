@@ -0,0 +1,140 @@
+package main
+
+import (
+	"database"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAuditOutcome(t *testing.T) {
+	for status, want := range map[int]string{
+		fiber.StatusOK: AUDIT_OUTCOME_SUCCESS,
+		fiber.StatusCreated: AUDIT_OUTCOME_SUCCESS,
+		fiber.StatusMultiStatus: AUDIT_OUTCOME_PARTIAL,
+		fiber.StatusBadRequest: AUDIT_OUTCOME_REJECTED,
+		fiber.StatusUnauthorized: AUDIT_OUTCOME_DENIED,
+		fiber.StatusForbidden: AUDIT_OUTCOME_DENIED,
+		fiber.StatusNotFound: AUDIT_OUTCOME_REJECTED,
+		fiber.StatusInternalServerError: AUDIT_OUTCOME_FAILED,
+	} {
+		if got := auditOutcome(status); got != want {
+			t.Errorf("auditOutcome(%d) = %s, want %s", status, got, want)
+		}
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	con, err := database.OpenDatabase(database.InMemoryDatabase)
+	if err != nil {
+		t.Fatalf("OpenDatabase: %s", err)
+	}
+	defer con.Close()
+	if err := database.SetupDatabase(con); err != nil {
+		t.Fatalf("SetupDatabase: %s", err)
+	}
+
+	accounts, err := NewAccountManager(con)
+	if err != nil {
+		t.Fatalf("NewAccountManager: %s", err)
+	}
+	store := database.NewSqliteStore(con)
+	serverState := &ServerState{
+		con: con,
+		store: store,
+		idCache: database.NewIdCache(),
+		ingestQueue: NewIngestQueue(store, DefaultIngestConfig()),
+		accounts: accounts,
+		auditLog: NewAuditLog(con),
+	}
+	defer serverState.ingestQueue.close()
+	server := fiber.New()
+	addRoutes(server, serverState)
+
+	// Before the first account, the actions are anonymous.
+	if status, _ := request(t, server, "POST", "/topic/send-message", `{"Topic":"sensors/a","Message":"on"}`, nil); status != fiber.StatusUnauthorized {
+		t.Errorf("publish without a connection: %d", status)
+	}
+
+	if _, err := accounts.createAccount("admin", "correct horse"); err != nil {
+		t.Fatalf("createAccount: %s", err)
+	}
+	admin := login(t, server, "admin", "correct horse")
+	if status, body := request(t, server, "POST", "/api/v1/accounts", `{"Username":"viewer","Password":"battery staple"}`, admin); status != fiber.StatusCreated {
+		t.Fatalf("create the viewer: %d %v", status, body)
+	}
+	viewer := login(t, server, "viewer", "battery staple")
+
+	if status, _ := request(t, server, "DELETE", "/api/v1/brokers/1/topics?topic=sensors/a&topic=lights/b&dryRun=true", "", admin); status != fiber.StatusOK {
+		t.Errorf("delete topics as the admin: %d", status)
+	}
+	if status, _ := request(t, server, "DELETE", "/api/v1/brokers/1?dryRun=true", "", viewer); status != fiber.StatusForbidden {
+		t.Errorf("delete a broker as the viewer: %d", status)
+	}
+
+	status, body := request(t, server, "GET", "/api/v1/audit", "", admin)
+	entries, _ := body["entries"].([]any)
+	if status != fiber.StatusOK || len(entries) != 5 {
+		t.Fatalf("the audit log: %d %v", status, body)
+	}
+	newest := entries[0].(map[string]any)
+	if newest["Action"] != AUDIT_DELETE_BROKER || newest["Actor"] != "viewer" || newest["Outcome"] != AUDIT_OUTCOME_DENIED || newest["BrokerId"] != float64(1) {
+		t.Errorf("the denied delete: %v", newest)
+	}
+	oldest := entries[4].(map[string]any)
+	if oldest["Action"] != AUDIT_PUBLISH || oldest["Actor"] != AUDIT_ANONYMOUS || oldest["Outcome"] != AUDIT_OUTCOME_DENIED {
+		t.Errorf("the anonymous publish: %v", oldest)
+	}
+
+	// Every topic has an entry of its own.
+	for query, want := range map[string]int{
+		"?action=delete.topics": 2,
+		"?topicFilter=sensors/%2B": 1,
+		"?topic=lights/b": 1,
+		"?actor=admin&outcome=success": 3,
+		"?action=account.create": 1,
+		"?brokerId=2": 0,
+	} {
+		status, body := request(t, server, "GET", "/api/v1/audit"+query, "", admin)
+		if entries, _ := body["entries"].([]any); status != fiber.StatusOK || len(entries) != want {
+			t.Errorf("the audit log %s: %d %v, want %d entries", query, status, body, want)
+		}
+	}
+
+	status, body = request(t, server, "GET", "/api/v1/audit?limit=3", "", admin)
+	if status != fiber.StatusOK || body["nextBeforeId"] == float64(0) {
+		t.Fatalf("the first page: %d %v", status, body)
+	}
+	status, body = request(t, server, "GET", fmt.Sprintf("/api/v1/audit?limit=3&beforeId=%v", body["nextBeforeId"]), "", admin)
+	if entries, _ := body["entries"].([]any); status != fiber.StatusOK || len(entries) != 2 || body["nextBeforeId"] != float64(0) {
+		t.Errorf("the second page: %d %v", status, body)
+	}
+
+	if status, _ := request(t, server, "GET", "/api/v1/audit", "", viewer); status != fiber.StatusForbidden {
+		t.Errorf("the audit log as the viewer: %d", status)
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/audit/export?action=delete.topics", nil)
+	req.Header.Set("Authorization", admin["Authorization"])
+	resp, err := server.Test(req, -1)
+	if err != nil {
+		t.Fatalf("export: %s", err)
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if lines := strings.Split(strings.TrimSpace(string(raw)), "\n"); resp.StatusCode != fiber.StatusOK || len(lines) != 3 || !strings.HasPrefix(lines[0], "Id,CreationDate") {
+		t.Errorf("export: %d %q", resp.StatusCode, raw)
+	}
+
+	// The log is append-only.
+	if _, err := con.Exec("UPDATE AuditLog SET Actor = 'nobody'"); err == nil {
+		t.Errorf("an entry was changed")
+	}
+	if _, err := con.Exec("DELETE FROM AuditLog"); err == nil {
+		t.Errorf("an entry was deleted")
+	}
+}
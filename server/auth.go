@@ -0,0 +1,1338 @@
+package main
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - SESSION_COOKIE       : The cookie that holds the token of a session in the browser.
+// - API_KEY_PREFIX       : Every API key starts with it, that is how a key is told apart from the token of a session.
+// - SESSION_IDLE_TIMEOUT : A session that was not used for this long is logged out.
+//
+// # Author
+// - Polariusz
+const SESSION_COOKIE = "mqx_session"
+const API_KEY_PREFIX = "mqx_"
+const SESSION_IDLE_TIMEOUT = 12 * time.Hour
+const MIN_PASSWORD_LENGTH = 8
+const API_KEY_TOUCH_INTERVAL = time.Minute
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_.@-]{1,64}$`)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The PBKDF2-SHA256 iterations of new password hashes. Old hashes keep theirs, it is stored in the hash.
+// - It is a variable so that the tests can lower it, 600000 iterations take too long under the race detector.
+//
+// # Author
+// - Polariusz
+var passwordIterations = 600000
+
+// | Date of change | By        | Comment                 |
+// +----------------+-----------+-------------------------+
+// | 2026-10-19     | Polariusz | Created                 |
+// | 2026-10-19     | Polariusz | uses passwordIterations |
+//
+// # Description
+// - The function shall hash the password with PBKDF2-SHA256 and a random salt.
+//
+// # Returns
+// - "pbkdf2-sha256$<ITERATIONS>$<SALT>$<HASH>", salt and hash in base64
+//
+// # Author
+// - Polariusz
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	hash, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, 32)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", passwordIterations, base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(hash)), nil
+}
+
+// # Description
+// - The function shall tell whether the password matches the hash of hashPassword(), in constant time.
+//
+// # Author
+// - Polariusz
+func verifyPassword(password string, encoded string) bool {
+	partList := strings.Split(encoded, "$")
+	if len(partList) != 4 || partList[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iterations, err := strconv.Atoi(partList[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(partList[2])
+	if err != nil {
+		return false
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(partList[3])
+	if err != nil {
+		return false
+	}
+
+	hash, err := pbkdf2.Key(sha256.New, password, salt, iterations, len(expected))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(hash, expected) == 1
+}
+
+// # Description
+// - A hash that no password matches, it is checked against when the username is unknown so that the answer takes as long as for a known one.
+// - The iterations are filled in with passwordIterations, a new hash is made with as many.
+//
+// # Author
+// - Polariusz
+const DUMMY_PASSWORD_HASH = "pbkdf2-sha256$%d$imA0fj1Lra5V3xEndPbKMA$O+LiQKtdyc6VlA3P9Skt9OhLyOGEsiXqEm67xSQtWbg"
+
+// # Description
+// - The function shall return a new random token with the prefix.
+//
+// # Author
+// - Polariusz
+func newToken(prefix string) (string, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(random), nil
+}
+
+// # Description
+// - The function shall return the hash under which a token or an API key is kept. The tokens are random enough for a plain SHA-256.
+//
+// # Author
+// - Polariusz
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A logged in account, either by a login or by an API key.
+// - `connection` is the broker and user that the session connected to with PostCredentialsHandler(), the handlers act as them, see resolveBrokerUser().
+// - The fields are guarded by the mutex of the `AccountManager`.
+//
+// # Author
+// - Polariusz
+type Session struct {
+	AccountId int
+	Username string
+	ApiKeyId int
+	CreationDate time.Time
+	lastSeen time.Time
+	connection BrokerUser
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"AccountId":<A>,"Username":"<U>","ApiKeyId":<K>,"Connection":<C>,"ExpiresAt":"<E>"}
+//   - <K> : The API key of the session, 0 if it logged in with the password
+//   - <C> : The BrokerUser that the session is connected as, null if it is not connected
+//   - <E> : When the session ends if it is not used, null for API keys
+//
+// # Used in
+// - GetSessionHandler()
+// - PostSessionHandler()
+//
+// # Author
+// - Polariusz
+type SessionInfo struct {
+	AccountId int
+	Username string
+	ApiKeyId int
+	Connection *BrokerUser
+	ExpiresAt *time.Time
+}
+
+// | Date of change | By        | Comment              |
+// +----------------+-----------+----------------------+
+// | 2026-10-19     | Polariusz | Created              |
+// | 2026-10-19     | Polariusz | Accounts per project |
+//
+// # Description
+// - It keeps the accounts and API keys in the database of the selected project and the sessions in memory.
+// - The HTTP API is only protected once there is an account, until then it is open like before, see enabled().
+// - The accounts belong to a project, like the roles on its brokers. When another project is selected, see attach().
+//
+// # Author
+// - Polariusz
+type AccountManager struct {
+	mutex sync.Mutex
+	con *sql.DB
+	accountCount int
+	sessions map[string]*Session
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - con *sql.DB : The database of the selected project, nil to run without accounts.
+//
+// # Author
+// - Polariusz
+func NewAccountManager(con *sql.DB) (*AccountManager, error) {
+	am := &AccountManager{con: con, sessions: make(map[string]*Session)}
+	if con == nil {
+		return am, nil
+	}
+
+	count, err := database.CountAccounts(con)
+	if err != nil {
+		return am, err
+	}
+	am.accountCount = count
+	return am, nil
+}
+
+// # Description
+// - The method shall tell whether the HTTP API requires a login, which is the case as soon as there is an account.
+//
+// # Author
+// - Polariusz
+func (am *AccountManager) enabled() bool {
+	if am == nil {
+		return false
+	}
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	return am.con != nil && am.accountCount > 0
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall make the accounts of `con` the ones that log in, when another project is selected or a backup is restored.
+// - The sessions stay logged in if their account, by its username, or their API key is in `con`. The others are logged out.
+// - The connections of the sessions are forgotten, the IDs of brokers and users belong to the previous database.
+// - If the accounts can not be counted, all sessions are logged out and the API stays protected as it was.
+//
+// # Author
+// - Polariusz
+func (am *AccountManager) attach(con *sql.DB) error {
+	count, err := database.CountAccounts(con)
+
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	am.con = con
+	if err != nil {
+		am.sessions = make(map[string]*Session)
+		return err
+	}
+	am.accountCount = count
+
+	for key, session := range am.sessions {
+		session.connection = BrokerUser{}
+		accountId := 0
+		if session.ApiKeyId != 0 {
+			if apiKey, exists, err := database.SelectApiKeyByHash(con, key); err == nil && exists {
+				session.ApiKeyId = apiKey.Id
+				accountId = apiKey.AccountId
+			}
+		} else if account, exists, err := database.SelectAccountByUsername(con, session.Username); err == nil && exists {
+			accountId = account.Id
+		}
+		account, exists, err := database.SelectAccountById(con, accountId)
+		if accountId == 0 || err != nil || !exists {
+			delete(am.sessions, key)
+			continue
+		}
+		session.AccountId = account.Id
+		session.Username = account.Username
+	}
+	return nil
+}
+
+// # Description
+// - The method shall return the account of the session, to seed a database without accounts with it, see seedAccount().
+//
+// # Returns
+// - *database.SelectAccount, nil if the API is open or the account is gone
+// - error when Skill Issues
+//
+// # Author
+// - Polariusz
+func (am *AccountManager) carryAccount(session *Session) (*database.SelectAccount, error) {
+	if session == nil || !am.enabled() {
+		return nil, nil
+	}
+	account, exists, err := database.SelectAccountById(am.con, session.AccountId)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall copy `account` with its password into `con` as an admin of all brokers, if `con` has no accounts.
+// - A project that is selected or restored while the API is protected would otherwise be open to everyone.
+//
+// # Author
+// - Polariusz
+func seedAccount(con *sql.DB, account *database.SelectAccount) error {
+	if account == nil {
+		return nil
+	}
+	if count, err := database.CountAccounts(con); err != nil || count > 0 {
+		return err
+	}
+
+	accountId, err := database.InsertNewAccount(con, database.InsertAccount{Username: account.Username, PasswordHash: account.PasswordHash})
+	if err != nil {
+		return err
+	}
+	_, err = database.InsertNewRoleAssignment(con, database.InsertRoleAssignment{AccountId: accountId, Role: ROLE_ADMIN, BrokerId: 0, TopicFilter: "#"})
+	return err
+}
+
+// | Date of change | By        | Comment            |
+// +----------------+-----------+--------------------+
+// | 2026-10-19     | Polariusz | Created            |
+// | 2026-10-19     | Polariusz | First one is admin |
+//
+// # Description
+// - The method shall create an account after validating the username and the password.
+// - The first account becomes an admin of all brokers, see ROLE_ADMIN.
+//
+// # Returns
+// - int: [Account].[ID]
+// - error when:
+//   - The username or the password is not valid, the message can be shown to the client
+//   - The username is taken
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func (am *AccountManager) createAccount(username string, password string) (int, error) {
+	if am.con == nil {
+		return -1, fmt.Errorf("There is no database for the accounts")
+	}
+	if !usernamePattern.MatchString(username) {
+		return -1, fmt.Errorf("Username must be 1 to 64 letters, digits, '_', '.', '@' or '-'")
+	}
+	if len(password) < MIN_PASSWORD_LENGTH {
+		return -1, fmt.Errorf("Password must have at least %d characters", MIN_PASSWORD_LENGTH)
+	}
+	if _, exists, err := database.SelectAccountByUsername(am.con, username); err != nil {
+		return -1, err
+	} else if exists {
+		return -1, fmt.Errorf("The username is taken")
+	}
+
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return -1, err
+	}
+	accountId, err := database.InsertNewAccount(am.con, database.InsertAccount{Username: username, PasswordHash: passwordHash})
+	if err != nil {
+		return -1, err
+	}
+
+	// The first account must be able to give out the roles of the others.
+	if count, err := database.CountAccounts(am.con); err != nil {
+		return accountId, err
+	} else if count == 1 {
+		if _, err := database.InsertNewRoleAssignment(am.con, database.InsertRoleAssignment{AccountId: accountId, Role: ROLE_ADMIN, BrokerId: 0, TopicFilter: "#"}); err != nil {
+			return accountId, err
+		}
+	}
+
+	am.mutex.Lock()
+	am.accountCount++
+	am.mutex.Unlock()
+
+	return accountId, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall delete the account with its API keys and log out all its sessions.
+//
+// # Returns
+// - bool: false if there was no such account
+// - error when Skill Issues
+//
+// # Author
+// - Polariusz
+func (am *AccountManager) deleteAccount(accountId int) (bool, error) {
+	deleted, err := database.DeleteAccount(am.con, accountId)
+	if err != nil || !deleted {
+		return deleted, err
+	}
+
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	am.accountCount--
+	for key, session := range am.sessions {
+		if session.AccountId == accountId {
+			delete(am.sessions, key)
+		}
+	}
+	return true, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall change the password of the account and log out all its other sessions.
+//
+// # Arguments
+// - keep *Session : The session that stays logged in
+//
+// # Author
+// - Polariusz
+func (am *AccountManager) changePassword(keep *Session, password string) error {
+	if len(password) < MIN_PASSWORD_LENGTH {
+		return fmt.Errorf("Password must have at least %d characters", MIN_PASSWORD_LENGTH)
+	}
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := database.UpdateAccountPassword(am.con, keep.AccountId, passwordHash); err != nil {
+		return err
+	}
+
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	for key, session := range am.sessions {
+		if session.AccountId == keep.AccountId && session.ApiKeyId == 0 && session != keep {
+			delete(am.sessions, key)
+		}
+	}
+	return nil
+}
+
+// | Date of change | By        | Comment                            |
+// +----------------+-----------+------------------------------------+
+// | 2026-10-19     | Polariusz | Created                            |
+// | 2026-10-19     | Polariusz | dummy hash uses passwordIterations |
+//
+// # Description
+// - The method shall check the password and start a new session.
+//
+// # Returns
+// - *Session and its token, nil if the username or the password is wrong
+// - error when Skill Issues
+//
+// # Author
+// - Polariusz
+func (am *AccountManager) login(username string, password string) (*Session, string, error) {
+	account, exists, err := database.SelectAccountByUsername(am.con, username)
+	if err != nil {
+		return nil, "", err
+	}
+	if !exists {
+		verifyPassword(password, fmt.Sprintf(DUMMY_PASSWORD_HASH, passwordIterations))
+		return nil, "", nil
+	}
+	if !verifyPassword(password, account.PasswordHash) {
+		return nil, "", nil
+	}
+
+	token, err := newToken("")
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	session := &Session{AccountId: account.Id, Username: account.Username, CreationDate: now, lastSeen: now}
+
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	for key, other := range am.sessions {
+		if other.ApiKeyId == 0 && now.Sub(other.lastSeen) > SESSION_IDLE_TIMEOUT {
+			delete(am.sessions, key)
+		}
+	}
+	am.sessions[hashToken(token)] = session
+
+	return session, token, nil
+}
+
+// # Description
+// - The method shall end the session of the token.
+//
+// # Author
+// - Polariusz
+func (am *AccountManager) logout(token string) {
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	delete(am.sessions, hashToken(token))
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall find the session of a token or an API key.
+// - The session of an API key is made on its first use and lives until the key is deleted.
+//
+// # Returns
+// - *Session, nil if the token is not known or the session has expired
+// - error when Skill Issues
+//
+// # Author
+// - Polariusz
+func (am *AccountManager) session(token string) (*Session, error) {
+	key := hashToken(token)
+	now := time.Now()
+
+	am.mutex.Lock()
+	session, ok := am.sessions[key]
+	if ok && session.ApiKeyId == 0 && now.Sub(session.lastSeen) > SESSION_IDLE_TIMEOUT {
+		delete(am.sessions, key)
+		ok = false
+	}
+	if ok {
+		touch := session.ApiKeyId != 0 && now.Sub(session.lastSeen) > API_KEY_TOUCH_INTERVAL
+		session.lastSeen = now
+		am.mutex.Unlock()
+		if touch {
+			database.UpdateApiKeyLastUsed(am.con, session.ApiKeyId, now)
+		}
+		return session, nil
+	}
+	am.mutex.Unlock()
+
+	if !strings.HasPrefix(token, API_KEY_PREFIX) {
+		return nil, nil
+	}
+
+	apiKey, exists, err := database.SelectApiKeyByHash(am.con, key)
+	if err != nil || !exists {
+		return nil, err
+	}
+	account, exists, err := database.SelectAccountById(am.con, apiKey.AccountId)
+	if err != nil || !exists {
+		return nil, err
+	}
+	database.UpdateApiKeyLastUsed(am.con, apiKey.Id, now)
+
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	if session, ok := am.sessions[key]; ok {
+		return session, nil
+	}
+	session = &Session{AccountId: account.Id, Username: account.Username, ApiKeyId: apiKey.Id, CreationDate: now, lastSeen: now}
+	am.sessions[key] = session
+	return session, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall create an API key for the account.
+//
+// # Returns
+// - The key, it is only known now, the database only keeps its hash
+// - SelectApiKey of the key
+// - error when Skill Issues
+//
+// # Author
+// - Polariusz
+func (am *AccountManager) createApiKey(accountId int, name string) (string, database.SelectApiKey, error) {
+	key, err := newToken(API_KEY_PREFIX)
+	if err != nil {
+		return "", database.SelectApiKey{}, err
+	}
+
+	prefix := key[:len(API_KEY_PREFIX)+6]
+	apiKeyId, err := database.InsertNewApiKey(am.con, database.InsertApiKey{AccountId: accountId, Name: name, Prefix: prefix, KeyHash: hashToken(key)})
+	if err != nil {
+		return "", database.SelectApiKey{}, err
+	}
+
+	return key, database.SelectApiKey{Id: apiKeyId, AccountId: accountId, Name: name, Prefix: prefix, CreationDate: time.Now()}, nil
+}
+
+// # Description
+// - The method shall delete the API key of the account and end its session.
+//
+// # Author
+// - Polariusz
+func (am *AccountManager) deleteApiKey(apiKeyId int, accountId int) (bool, error) {
+	deleted, err := database.DeleteApiKey(am.con, apiKeyId, accountId)
+	if err != nil || !deleted {
+		return deleted, err
+	}
+
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	for key, session := range am.sessions {
+		if session.ApiKeyId == apiKeyId {
+			delete(am.sessions, key)
+		}
+	}
+	return true, nil
+}
+
+// # Description
+// - The method shall remember the broker and user that the session connected as.
+//
+// # Author
+// - Polariusz
+func (am *AccountManager) setConnection(session *Session, brokerUser BrokerUser) {
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	session.connection = brokerUser
+}
+
+// # Description
+// - The method shall describe the session for the client.
+//
+// # Author
+// - Polariusz
+func (am *AccountManager) info(session *Session) SessionInfo {
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	info := SessionInfo{AccountId: session.AccountId, Username: session.Username, ApiKeyId: session.ApiKeyId}
+	if session.connection != (BrokerUser{}) {
+		connection := session.connection
+		info.Connection = &connection
+	}
+	if session.ApiKeyId == 0 {
+		expiresAt := session.lastSeen.Add(SESSION_IDLE_TIMEOUT)
+		info.ExpiresAt = &expiresAt
+	}
+	return info
+}
+
+// # Description
+// - The function shall return the token of the request, from `Authorization: Bearer <T>`, `X-API-Key: <T>` or the session cookie, in that order.
+//
+// # Author
+// - Polariusz
+func requestToken(c *fiber.Ctx) string {
+	if authorization := c.Get(fiber.HeaderAuthorization); strings.HasPrefix(authorization, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+	}
+	if apiKey := c.Get("X-API-Key"); apiKey != "" {
+		return apiKey
+	}
+	return c.Cookies(SESSION_COOKIE)
+}
+
+// # Description
+// - The function shall return the session that authenticate() has found for the request, nil if the API is open.
+//
+// # Author
+// - Polariusz
+func sessionOf(c *fiber.Ctx) *Session {
+	session, _ := c.Locals("session").(*Session)
+	return session
+}
+
+// | Date of change | By        | Comment               |
+// +----------------+-----------+-----------------------+
+// | 2026-10-19     | Polariusz | Created               |
+// | 2026-10-19     | Polariusz | Every route, any case |
+//
+// # Method-Type
+// - Middleware
+//
+// # Description
+// - The middleware shall reject the requests to the HTTP API that have no valid session, as soon as there is an account.
+// - The HTTP API are the routes under `API_V1_PREFIX` and every other route of the server, deprecated or not. Only the routes marked `Public` and the files of the frontend are open.
+// - The routing of fiber ignores the case and a trailing slash, so the path is compared the same way, see routePath().
+// - The session is stored in the locals of the request, see sessionOf().
+//
+// # Returns
+// - 401 (Unauthorized): JSON
+//   - {"Unauthorized":"Log in first, see POST /api/v1/sessions"} for the deprecated routes
+//   - `APIErrorEnvelope` with the code `API_ERROR_UNAUTHENTICATED` under `API_V1_PREFIX`
+//
+// # Author
+// - Polariusz
+func authenticate(serverState *ServerState) fiber.Handler {
+	publicRoutes := make(map[string]bool)
+	for _, route := range apiV1Routes() {
+		if route.Public {
+			publicRoutes[route.Method+" "+routePath(API_V1_PREFIX+route.Path)] = true
+		}
+	}
+
+	// The routes are only complete once the server handles requests, the files of the frontend are under the route "/".
+	var routesOnce sync.Once
+	routePaths := make(map[string]bool)
+
+	return func(c *fiber.Ctx) error {
+		if !serverState.accounts.enabled() {
+			return c.Next()
+		}
+
+		routesOnce.Do(func() {
+			for _, route := range c.App().GetRoutes(true) {
+				if route.Path != "/" && !strings.ContainsAny(route.Path, ":*") {
+					routePaths[routePath(route.Path)] = true
+				}
+			}
+		})
+
+		path := routePath(c.Path())
+		isAPI := path == API_V1_PREFIX || strings.HasPrefix(path, API_V1_PREFIX+"/") || routePaths[path]
+		if !isAPI || publicRoutes[c.Method()+" "+path] || c.Method() == fiber.MethodOptions {
+			return c.Next()
+		}
+
+		session, err := serverState.accounts.session(requestToken(c))
+		if err != nil {
+			if strings.HasPrefix(path, API_V1_PREFIX) {
+				return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while looking up the session", map[string]any{"Error": err.Error()})
+			}
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while looking up the session",
+				"Error": err.Error(),
+			})
+		}
+		if session == nil {
+			if strings.HasPrefix(path, API_V1_PREFIX) {
+				return writeAPIError(c, fiber.StatusUnauthorized, API_ERROR_UNAUTHENTICATED, "Log in first, see POST "+API_V1_PREFIX+"/sessions", nil)
+			}
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"Unauthorized": "Log in first, see POST " + API_V1_PREFIX + "/sessions",
+			})
+		}
+
+		c.Locals("session", session)
+		return c.Next()
+	}
+}
+
+// # Description
+// - The function shall return the path the way fiber routes it: in lower case and without a trailing slash.
+//
+// # Author
+// - Polariusz
+func routePath(path string) string {
+	path = strings.ToLower(path)
+	if len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+	}
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Helper
+//
+// # Description
+// - The function shall return the broker and user that a handler acts as.
+// - Without a session (no accounts yet), it is `claimed`, taken from the body like before.
+// - With a session, it is the connection of the session. `claimed` may be left out (zero), but if a field is given it must match.
+// - `live` is for handlers that use the MQTT-Client, the session must be the one that it is connected as.
+//
+// # Returns
+// - BrokerUser to act as
+// - bool: false if the response was written
+// - 401 (Unauthorized): JSON
+//   - {"Unauthorized":"The session is not connected to a broker, see POST /api/v1/connections"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The session is connected as another broker or user"}
+// - 409 (Conflict): JSON
+//   - {"Conflict":"The MQTT-Client was connected to another broker by another session"}
+//
+// # Author
+// - Polariusz
+func resolveBrokerUser(c *fiber.Ctx, serverState *ServerState, claimed BrokerUser, live bool) (BrokerUser, bool, error) {
+	session := sessionOf(c)
+	if session == nil {
+		return claimed, true, nil
+	}
+
+	serverState.accounts.mutex.Lock()
+	connection := session.connection
+	serverState.accounts.mutex.Unlock()
+
+	if connection == (BrokerUser{}) {
+		return BrokerUser{}, false, c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"Unauthorized": "The session is not connected to a broker, see POST " + API_V1_PREFIX + "/connections",
+		})
+	}
+	if (claimed.BrokerId != 0 && claimed.BrokerId != connection.BrokerId) || (claimed.UserId != 0 && claimed.UserId != connection.UserId) {
+		return BrokerUser{}, false, c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"Forbidden": "The session is connected as another broker or user",
+		})
+	}
+	if live && connection != serverState.connected {
+		return BrokerUser{}, false, c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"Conflict": "The MQTT-Client was connected to another broker by another session",
+		})
+	}
+
+	return connection, true, nil
+}
+
+// # Description
+// - The function shall tell whether the request comes from this machine.
+//
+// # Author
+// - Polariusz
+func isLoopback(c *fiber.Ctx) bool {
+	ip := net.ParseIP(c.IP())
+	return ip != nil && ip.IsLoopback()
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Username":"<U>","Password":"<P>"}
+//
+// # Used in
+// - PostSessionHandler()
+// - PostAccountHandler()
+//
+// # Author
+// - Polariusz
+type AccountWrapper struct {
+	Username string
+	Password string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Password":"<P>"}
+//
+// # Used in
+// - PutAccountPasswordHandler()
+//
+// # Author
+// - Polariusz
+type PasswordWrapper struct {
+	Password string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Name":"<N>"}
+//   - <N> : What the key is for, to recognise it in the list
+//
+// # Used in
+// - PostApiKeyHandler()
+//
+// # Author
+// - Polariusz
+type ApiKeyWrapper struct {
+	Name string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall log in with the username and password and start a session.
+// - The token is returned and set as the cookie `SESSION_COOKIE`. Other clients than browsers send it as `Authorization: Bearer <TOKEN>`.
+//
+// # Usage
+// - Call declared by apiV1Routes() URL with the POST-Method, it is public.
+//
+// # Returns
+// - 201 (Created): JSON
+//   - {"token":"<T>","session":<SessionInfo>}
+// - 400 (Bad Request): `APIErrorEnvelope`
+// - 401 (Unauthorized): `APIErrorEnvelope`, the username or the password is wrong
+//
+// # Author
+// - Polariusz
+func PostSessionHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var accountWrapper AccountWrapper
+		if err := c.BodyParser(&accountWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+		if !serverState.accounts.enabled() {
+			return writeAPIError(c, fiber.StatusConflict, API_ERROR_CONFLICT, "There are no accounts yet, the API is open. Create one with POST "+API_V1_PREFIX+"/accounts", nil)
+		}
+
+		session, token, err := serverState.accounts.login(accountWrapper.Username, accountWrapper.Password)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while logging in", map[string]any{"Error": err.Error()})
+		}
+		if session == nil {
+			return writeAPIError(c, fiber.StatusUnauthorized, API_ERROR_UNAUTHENTICATED, "The username or the password is wrong", nil)
+		}
+
+		c.Cookie(&fiber.Cookie{
+			Name: SESSION_COOKIE,
+			Value: token,
+			Path: "/",
+			HTTPOnly: true,
+			SameSite: fiber.CookieSameSiteStrictMode,
+		})
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"token": token,
+			"session": serverState.accounts.info(session),
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return the session of the request.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"authentication":<A>,"session":<SessionInfo>,"roles":[<database.SelectRoleAssignment>]}
+//     - <A> : false if there are no accounts yet and the API is open, the session is null and the roles are left out then
+//
+// # Author
+// - Polariusz
+func GetSessionHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		session := sessionOf(c)
+		if session == nil {
+			return c.Status(fiber.StatusOK).JSON(fiber.Map{
+				"authentication": false,
+				"session": nil,
+			})
+		}
+
+		assignmentList, err := database.SelectRoleAssignments(serverState.accounts.con, session.AccountId)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the roles", map[string]any{"Error": err.Error()})
+		}
+		if assignmentList == nil {
+			assignmentList = []database.SelectRoleAssignment{}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"authentication": true,
+			"session": serverState.accounts.info(session),
+			"roles": assignmentList,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall log out the session of the request. The session of an API key can not be logged out, delete the key instead.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"Fine":"Logged out"}
+//
+// # Author
+// - Polariusz
+func DeleteSessionHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		session := sessionOf(c)
+		if session != nil && session.ApiKeyId != 0 {
+			return writeAPIError(c, fiber.StatusConflict, API_ERROR_CONFLICT, "The session of an API key ends when the key is deleted", nil)
+		}
+		if session != nil {
+			serverState.accounts.logout(requestToken(c))
+		}
+
+		c.ClearCookie(SESSION_COOKIE)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"Fine": "Logged out",
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+// | 2026-10-19     | Polariusz | Audited |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall create an account.
+// - The first account can only be created from this machine, as anyone may call the API while there is no account.
+// - The other accounts can only be created by an admin of all brokers, they have no role until one is given to them.
+//
+// # Returns
+// - 201 (Created): JSON
+//   - {"Id":<I>}
+// - 400 (Bad Request): `APIErrorEnvelope`, the username or password is not valid or taken
+// - 403 (Forbidden): `APIErrorEnvelope`, the first account was requested from another machine, or the session is no admin
+//
+// # Author
+// - Polariusz
+func PostAccountHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_ACCOUNT_CREATE}
+		defer serverState.auditLog.record(c, &record)
+
+		var accountWrapper AccountWrapper
+		if err := c.BodyParser(&accountWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+		if !serverState.accounts.enabled() && !isLoopback(c) {
+			return writeAPIError(c, fiber.StatusForbidden, API_ERROR_FORBIDDEN, "The first account can only be created from the machine of the server, or with `main account add`", nil)
+		}
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+
+		accountId, err := serverState.accounts.createAccount(accountWrapper.Username, accountWrapper.Password)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"Id": accountId,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall list all accounts. Only an admin of all brokers may call it.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"accounts":[<database.SelectAccount>]}
+// - 403 (Forbidden): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func GetAccountsHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		if serverState.accounts.con == nil {
+			return writeAPIError(c, fiber.StatusServiceUnavailable, API_ERROR_UNAVAILABLE, "There is no database for the accounts", nil)
+		}
+		accountList, err := database.SelectAccounts(serverState.accounts.con)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the accounts", map[string]any{"Error": err.Error()})
+		}
+		if accountList == nil {
+			accountList = []database.SelectAccount{}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"accounts": accountList,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+// | 2026-10-19     | Polariusz | Audited |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall delete the account `:id` with its API keys and roles and log out its sessions.
+// - Only an admin of all brokers may call it, and the last admin of all brokers can not be deleted.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"Id":<I>}
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+// - 409 (Conflict): `APIErrorEnvelope`, it is the last admin
+//
+// # Author
+// - Polariusz
+func DeleteAccountHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_ACCOUNT_DELETE}
+		defer serverState.auditLog.record(c, &record)
+
+		accountId, err := paramId(c, "id")
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+
+		admins, err := countGlobalAdmins(serverState, func(assignment database.SelectRoleAssignment) bool {
+			return assignment.AccountId == accountId
+		})
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the roles", map[string]any{"Error": err.Error()})
+		}
+		if admins == 0 {
+			return writeAPIError(c, fiber.StatusConflict, API_ERROR_CONFLICT, "This is the last admin of all brokers", nil)
+		}
+
+		deleted, err := serverState.accounts.deleteAccount(accountId)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while deleting the account", map[string]any{"Error": err.Error()})
+		}
+		if !deleted {
+			return writeAPIError(c, fiber.StatusNotFound, API_ERROR_NOT_FOUND, "There is no account with this Id", nil)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"Id": accountId,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall change the password of the account of the session. Its other sessions are logged out, its API keys stay.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"Fine":"Password changed"}
+// - 400 (Bad Request): `APIErrorEnvelope`, the password is too short
+// - 401 (Unauthorized): `APIErrorEnvelope`, there is no session
+//
+// # Author
+// - Polariusz
+func PutAccountPasswordHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var passwordWrapper PasswordWrapper
+		if err := c.BodyParser(&passwordWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+		session := sessionOf(c)
+		if session == nil {
+			return writeAPIError(c, fiber.StatusUnauthorized, API_ERROR_UNAUTHENTICATED, "Log in first, see POST "+API_V1_PREFIX+"/sessions", nil)
+		}
+
+		if err := serverState.accounts.changePassword(session, passwordWrapper.Password); err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"Fine": "Password changed",
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall create an API key for the account of the session. The key is only ever returned here.
+//
+// # Returns
+// - 201 (Created): JSON
+//   - {"key":"mqx_<K>","apiKey":<database.SelectApiKey>}
+// - 401 (Unauthorized): `APIErrorEnvelope`, there is no session
+//
+// # Author
+// - Polariusz
+func PostApiKeyHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var apiKeyWrapper ApiKeyWrapper
+		if err := c.BodyParser(&apiKeyWrapper); err != nil || apiKeyWrapper.Name == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+		session := sessionOf(c)
+		if session == nil {
+			return writeAPIError(c, fiber.StatusUnauthorized, API_ERROR_UNAUTHENTICATED, "Log in first, see POST "+API_V1_PREFIX+"/sessions", nil)
+		}
+
+		key, apiKey, err := serverState.accounts.createApiKey(session.AccountId, apiKeyWrapper.Name)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while creating the API key", map[string]any{"Error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"key": key,
+			"apiKey": apiKey,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return the API keys of the account of the session, without the keys themselves.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"apiKeys":[<database.SelectApiKey>]}
+//
+// # Author
+// - Polariusz
+func GetApiKeysHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		session := sessionOf(c)
+		if session == nil {
+			return writeAPIError(c, fiber.StatusUnauthorized, API_ERROR_UNAUTHENTICATED, "Log in first, see POST "+API_V1_PREFIX+"/sessions", nil)
+		}
+
+		apiKeyList, err := database.SelectApiKeysByAccountId(serverState.accounts.con, session.AccountId)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the API keys", map[string]any{"Error": err.Error()})
+		}
+		if apiKeyList == nil {
+			apiKeyList = []database.SelectApiKey{}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"apiKeys": apiKeyList,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall delete the API key `:id` of the account of the session. Its session ends at once.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"Id":<I>}
+// - 404 (Not Found): `APIErrorEnvelope`, the account has no such key
+//
+// # Author
+// - Polariusz
+func DeleteApiKeyHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		apiKeyId, err := paramId(c, "id")
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+		session := sessionOf(c)
+		if session == nil {
+			return writeAPIError(c, fiber.StatusUnauthorized, API_ERROR_UNAUTHENTICATED, "Log in first, see POST "+API_V1_PREFIX+"/sessions", nil)
+		}
+
+		deleted, err := serverState.accounts.deleteApiKey(apiKeyId, session.AccountId)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while deleting the API key", map[string]any{"Error": err.Error()})
+		}
+		if !deleted {
+			return writeAPIError(c, fiber.StatusNotFound, API_ERROR_NOT_FOUND, "There is no API key with this Id", nil)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"Id": apiKeyId,
+		})
+	}
+}
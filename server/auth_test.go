@@ -0,0 +1,172 @@
+package main
+
+import (
+	"database"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// The hashes of the tests are made with few iterations, the real count takes seconds under the race detector.
+func TestMain(m *testing.M) {
+	passwordIterations = 1000
+	os.Exit(m.Run())
+}
+
+func TestPasswordHash(t *testing.T) {
+	encoded, err := hashPassword("correct horse")
+	if err != nil {
+		t.Fatalf("hashPassword: %s", err)
+	}
+	if !verifyPassword("correct horse", encoded) {
+		t.Errorf("the password does not match its own hash %s", encoded)
+	}
+	if verifyPassword("correct horse!", encoded) {
+		t.Errorf("another password matches the hash %s", encoded)
+	}
+	if verifyPassword("correct horse", "not-a-hash") {
+		t.Errorf("a malformed hash matches")
+	}
+}
+
+// Sends the request to the server and returns the status with the decoded JSON body.
+func request(t *testing.T, server *fiber.App, method string, path string, body string, header map[string]string) (int, map[string]any) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	resp, err := server.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %s", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(resp.Body)
+	decoded := map[string]any{}
+	json.Unmarshal(raw, &decoded)
+	return resp.StatusCode, decoded
+}
+
+func TestSessions(t *testing.T) {
+	con, err := database.OpenDatabase(database.InMemoryDatabase)
+	if err != nil {
+		t.Fatalf("OpenDatabase: %s", err)
+	}
+	defer con.Close()
+	if err := database.SetupDatabase(con); err != nil {
+		t.Fatalf("SetupDatabase: %s", err)
+	}
+
+	accounts, err := NewAccountManager(con)
+	if err != nil {
+		t.Fatalf("NewAccountManager: %s", err)
+	}
+	serverState := &ServerState{accounts: accounts}
+	server := fiber.New()
+	addRoutes(server, serverState)
+
+	// Without accounts, the API stays open.
+	if status, body := request(t, server, "GET", "/api/v1/sessions/current", "", nil); status != fiber.StatusOK || body["authentication"] != false {
+		t.Errorf("without accounts: %d %v", status, body)
+	}
+
+	accountId, err := accounts.createAccount("admin", "correct horse")
+	if err != nil {
+		t.Fatalf("createAccount: %s", err)
+	}
+
+	if status, body := request(t, server, "GET", "/api/v1/sessions/current", "", nil); status != fiber.StatusUnauthorized || body["error"].(map[string]any)["code"] != API_ERROR_UNAUTHENTICATED {
+		t.Errorf("v1 without a token: %d %v", status, body)
+	}
+	if status, body := request(t, server, "GET", "/topic/favourites", `{"BrokerId":1,"UserId":1}`, nil); status != fiber.StatusUnauthorized || body["Unauthorized"] == nil {
+		t.Errorf("legacy without a token: %d %v", status, body)
+	}
+	if status, _ := request(t, server, "POST", "/api/v1/sessions", `{"Username":"admin","Password":"wrong password"}`, nil); status != fiber.StatusUnauthorized {
+		t.Errorf("login with a wrong password: %d", status)
+	}
+
+	status, body := request(t, server, "POST", "/api/v1/sessions", `{"Username":"admin","Password":"correct horse"}`, nil)
+	if status != fiber.StatusCreated {
+		t.Fatalf("login: %d %v", status, body)
+	}
+	bearer := map[string]string{"Authorization": "Bearer " + body["token"].(string)}
+
+	if status, body := request(t, server, "GET", "/api/v1/sessions/current", "", bearer); status != fiber.StatusOK || body["session"].(map[string]any)["Username"] != "admin" {
+		t.Errorf("v1 with a token: %d %v", status, body)
+	}
+	// The session is not connected to a broker, so the query can not pick one.
+	if status, body := request(t, server, "GET", "/stats?BrokerId=1", "", bearer); status != fiber.StatusUnauthorized || !strings.Contains(fmt.Sprint(body["Unauthorized"]), "not connected") {
+		t.Errorf("legacy with an unconnected session: %d %v", status, body)
+	}
+
+	token, _, err := accounts.createApiKey(accountId, "ci")
+	if err != nil {
+		t.Fatalf("createApiKey: %s", err)
+	}
+	if status, body := request(t, server, "GET", "/api/v1/sessions/current", "", map[string]string{"X-API-Key": token}); status != fiber.StatusOK || body["session"].(map[string]any)["ApiKeyId"] == float64(0) {
+		t.Errorf("v1 with an API key: %d %v", status, body)
+	}
+
+	if status, _ := request(t, server, "DELETE", "/api/v1/sessions/current", "", bearer); status != fiber.StatusOK {
+		t.Errorf("logout: %d", status)
+	}
+	if status, _ := request(t, server, "GET", "/api/v1/sessions/current", "", bearer); status != fiber.StatusUnauthorized {
+		t.Errorf("after the logout: %d", status)
+	}
+}
+
+func TestAuthenticateEveryPath(t *testing.T) {
+	con, err := database.OpenDatabase(database.InMemoryDatabase)
+	if err != nil {
+		t.Fatalf("OpenDatabase: %s", err)
+	}
+	defer con.Close()
+	if err := database.SetupDatabase(con); err != nil {
+		t.Fatalf("SetupDatabase: %s", err)
+	}
+
+	accounts, err := NewAccountManager(con)
+	if err != nil {
+		t.Fatalf("NewAccountManager: %s", err)
+	}
+	if _, err := accounts.createAccount("admin", "correct horse"); err != nil {
+		t.Fatalf("createAccount: %s", err)
+	}
+	serverState := &ServerState{con: con, store: database.NewSqliteStore(con), accounts: accounts}
+	server := fiber.New()
+	addRoutes(server, serverState)
+
+	// fiber routes these paths to the handlers, whatever the case and with a trailing slash.
+	for _, route := range []string{
+		"GET /API/V1/accounts",
+		"GET /Api/v1/sessions/current/",
+		"GET /api/v1/accounts/",
+		"POST /BACKUP/create",
+		"POST /backup/create/",
+		"POST /Broker/Delete",
+		"GET /Topic/Favourites",
+		"GET /ping",
+	} {
+		method, path, _ := strings.Cut(route, " ")
+		if status, body := request(t, server, method, path, "", nil); status != fiber.StatusUnauthorized {
+			t.Errorf("%s without a token: %d %v", route, status, body)
+		}
+	}
+
+	// Logging in stays open, and so does the frontend.
+	if status, body := request(t, server, "POST", "/API/v1/Sessions/", `{"Username":"admin","Password":"correct horse"}`, nil); status != fiber.StatusCreated {
+		t.Errorf("login: %d %v", status, body)
+	}
+	if status, _ := request(t, server, "GET", "/index.html", "", nil); status == fiber.StatusUnauthorized {
+		t.Errorf("the frontend needs a session")
+	}
+}
@@ -0,0 +1,455 @@
+package main
+
+import (
+	"database"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - Backups made through the API are written into the directory of the flag `-backups`. Only files from there can be restored through the API.
+// - Their names are made by the server, so only these characters can appear in them.
+//
+// # Author
+// - Polariusz
+var backupNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+\.db(\.gz)?$`)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - Why backups cannot be written or restored while the server runs with `-memory`.
+//
+// # Author
+// - Polariusz
+const BACKUP_IN_MEMORY = "The server keeps the messages in memory, backups are only available with a database file"
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A backup file in the backup directory.
+//
+// # Used in
+// - GetBackupsHandler()
+// - PostBackupCreateHandler()
+//
+// # Author
+// - Polariusz
+type BackupFile struct {
+	Name string
+	Size int64
+	CreationDate time.Time
+}
+
+// # Author
+// - Polariusz
+func (pm *ProjectManager) backupPath(name string) (string, bool) {
+	if !backupNamePattern.MatchString(name) || filepath.Base(name) != name {
+		return "", false
+	}
+	return filepath.Join(pm.config.BackupDir, name), true
+}
+
+// | Date of change | By        | Comment       |
+// +----------------+-----------+---------------+
+// | 2026-10-19     | Polariusz | Created       |
+// | 2026-10-19     | Polariusz | Database lock |
+//
+// # Description
+// - The method shall write a backup of the database of the selected project into the backup directory.
+// - The name is `<PROJECT>-<DATETIME>.db`, with `.gz` appended if it is compressed.
+// - The caller holds `databaseLock` of the ServerState for reading, see lockDatabase(), so the project is not switched meanwhile.
+//
+// # Returns
+// - BackupFile of the written backup
+// - error when the backup cannot be written
+//
+// # Author
+// - Polariusz
+func (pm *ProjectManager) backup(serverState *ServerState, compress bool) (BackupFile, error) {
+	if err := os.MkdirAll(pm.config.BackupDir, 0755); err != nil {
+		return BackupFile{}, fmt.Errorf("Error while creating the backup directory!\nErr: %s\n", err)
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s-%s.db", pm.currentName(), now.Format("20060102-150405.000"))
+	if compress {
+		name += database.COMPRESSED_BACKUP_SUFFIX
+	}
+
+	size, err := database.BackupDatabase(serverState.con, filepath.Join(pm.config.BackupDir, name))
+	if err != nil {
+		return BackupFile{}, err
+	}
+
+	return BackupFile{name, size, now}, nil
+}
+
+// | Date of change | By        | Comment        |
+// +----------------+-----------+----------------+
+// | 2026-10-19     | Polariusz | Created        |
+// | 2026-10-19     | Polariusz | Database lock  |
+// | 2026-10-19     | Polariusz | Seeds accounts |
+//
+// # Description
+// - The method shall overwrite the database of the selected project with the backup `path`.
+// - The backup is validated first. If it is not valid, the database is not touched and nothing is stopped.
+// - Everything that writes into the database is stopped while it is restored, see detachDatabase().
+// - The restore holds `databaseLock` of the ServerState, the caller must not hold it, see releaseDatabase().
+// - If the backup has no accounts but the server requires a login, the account of `session` is copied into it as an admin, see seedAccount().
+//
+// # Author
+// - Polariusz
+func (pm *ProjectManager) restore(serverState *ServerState, path string, session *Session) (database.BackupInfo, error) {
+	pm.switchMutex.Lock()
+	defer pm.switchMutex.Unlock()
+
+	if info, err := database.ValidateBackup(path); err != nil {
+		return info, err
+	}
+
+	serverState.databaseLock.Lock()
+	defer serverState.databaseLock.Unlock()
+
+	account, err := serverState.accounts.carryAccount(session)
+	if err != nil {
+		return database.BackupInfo{}, err
+	}
+
+	con, store := serverState.con, serverState.store
+	detachDatabase(serverState)
+	defer attachDatabase(serverState, con, store)
+
+	info, err := database.RestoreDatabase(con, path)
+	if err != nil {
+		return info, err
+	}
+	return info, seedAccount(con, account)
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return all backups in the backup directory, the newest first.
+// - The method shall need the role viewer for all brokers, a backup holds all of them.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the GET-Method.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"backups":[<BackupFile>]}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+//
+// # Author
+// - Polariusz
+func GetBackupsHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_VIEWER, 0); !ok {
+			return err
+		}
+
+		backupList := []BackupFile{}
+
+		entryList, _ := os.ReadDir(serverState.projects.config.BackupDir)
+		for _, entry := range entryList {
+			if entry.IsDir() || !backupNamePattern.MatchString(entry.Name()) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			backupList = append(backupList, BackupFile{entry.Name(), info.Size(), info.ModTime()})
+		}
+		sort.Slice(backupList, func(i, j int) bool {
+			return backupList[i].CreationDate.After(backupList[j].CreationDate)
+		})
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"backups": backupList,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Compress":<C>}
+//   - <C> : If true, the backup is compressed with gzip
+//
+// # Used in
+// - PostBackupCreateHandler()
+//
+// # Author
+// - Polariusz
+type BackupCreateWrapper struct {
+	Compress bool
+}
+
+// | Date of change | By        | Comment     |
+// +----------------+-----------+-------------+
+// | 2026-10-19     | Polariusz | Created     |
+// | 2026-10-19     | Polariusz | Roles       |
+// | 2026-10-19     | Polariusz | Memory mode |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall write a backup of the database of the selected project. Receiving messages continues while the backup is written.
+// - In memory mode the messages are not in the database but in a MemoryStore, a backup would miss them, so none is written.
+// - The method shall need the role admin on all brokers.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data may have a json structure that matches the struct BackupCreateWrapper. Without a body, the backup is not compressed.
+//
+// # Returns
+// - 201 (Created): JSON
+//   - {"backup":<BackupFile>}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 409 (Conflict): JSON
+//   - {"Conflict":"`const BACKUP_IN_MEMORY`"}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while writing the backup","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func PostBackupCreateHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		if serverState.projects.config.InMemory {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"Conflict": BACKUP_IN_MEMORY,
+			})
+		}
+		var createWrapper BackupCreateWrapper
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&createWrapper); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"badJson": BADJSON,
+				})
+			}
+		}
+
+		backupFile, err := serverState.projects.backup(serverState, createWrapper.Compress)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while writing the backup",
+				"Error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"backup": backupFile,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Name":"<N>"}
+//   - <N> : Name of a file in the backup directory, as returned by GetBackupsHandler()
+//
+// # Used in
+// - PostBackupValidateHandler()
+// - PostBackupRestoreHandler()
+//
+// # Author
+// - Polariusz
+type BackupNameWrapper struct {
+	Name string
+}
+
+// # Description
+// - The function shall parse the BackupNameWrapper and return the path of the backup, or write the error response.
+//
+// # Author
+// - Polariusz
+func parseBackupName(c *fiber.Ctx, serverState *ServerState) (string, bool, error) {
+	var nameWrapper BackupNameWrapper
+	if err := c.BodyParser(&nameWrapper); err != nil {
+		return "", false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"badJson": BADJSON,
+		})
+	}
+
+	path, ok := serverState.projects.backupPath(nameWrapper.Name)
+	if !ok {
+		return "", false, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"terribleJson": "Name must be the name of a backup in the backup directory",
+		})
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", false, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"badBackup": "There is no backup with this name",
+		})
+	}
+
+	return path, true, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall check whether a backup could be restored, without restoring it.
+// - The method shall need the role admin on all brokers.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data must have a json structure that matches the struct BackupNameWrapper.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"backup":<database.BackupInfo>}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+//   - {"terribleJson":"Name must be the name of a backup in the backup directory"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 404 (Not Found): JSON
+//   - {"badBackup":"There is no backup with this name"}
+// - 422 (Unprocessable Entity): JSON
+//   - {"badBackup":"<Why it cannot be restored>"}
+//
+// # Author
+// - Polariusz
+func PostBackupValidateHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		path, ok, err := parseBackupName(c, serverState)
+		if !ok {
+			return err
+		}
+
+		info, err := database.ValidateBackup(path)
+		if err != nil {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"badBackup": err.Error(),
+			})
+		}
+
+		info.Path = filepath.Base(path)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"backup": info,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+// | 2026-10-19     | Polariusz | Audited |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall overwrite the database of the selected project with a backup.
+// - The backup is validated first, an invalid backup leaves the database untouched.
+// - The MQTT-Client is disconnected by the restore and running replays are cancelled.
+// - In memory mode the messages are kept in a MemoryStore that a backup cannot be restored into, so nothing is restored.
+// - The method shall need the role admin on all brokers.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data must have a json structure that matches the struct BackupNameWrapper.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"backup":<database.BackupInfo>}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+//   - {"terribleJson":"Name must be the name of a backup in the backup directory"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 404 (Not Found): JSON
+//   - {"badBackup":"There is no backup with this name"}
+// - 409 (Conflict): JSON
+//   - {"Conflict":"`const BACKUP_IN_MEMORY`"}
+// - 422 (Unprocessable Entity): JSON
+//   - {"badBackup":"<Why it cannot be restored>"}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while restoring the backup","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func PostBackupRestoreHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_BACKUP_RESTORE}
+		defer serverState.auditLog.record(c, &record)
+
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		if serverState.projects.config.InMemory {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"Conflict": BACKUP_IN_MEMORY,
+			})
+		}
+		path, ok, err := parseBackupName(c, serverState)
+		if !ok {
+			return err
+		}
+
+		if _, err := database.ValidateBackup(path); err != nil {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"badBackup": err.Error(),
+			})
+		}
+
+		releaseDatabase(c, serverState)
+		info, err := serverState.projects.restore(serverState, path, sessionOf(c))
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while restoring the backup",
+				"Error": err.Error(),
+			})
+		}
+
+		info.Path = filepath.Base(path)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"backup": info,
+		})
+	}
+}
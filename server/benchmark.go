@@ -0,0 +1,795 @@
+package main
+
+import (
+	"database"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gofiber/fiber/v2"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - What a benchmark is limited to, so it loads the broker and not the server:
+//   - BENCHMARK_MAX_PUBLISHERS    : How many clients may publish.
+//   - BENCHMARK_MAX_SUBSCRIBERS   : How many clients may subscribe.
+//   - BENCHMARK_MAX_RATE          : How many messages per second every publisher may send.
+//   - BENCHMARK_MAX_PAYLOAD_BYTES : How big a payload may be.
+//   - BENCHMARK_MAX_DURATION      : How long the publishers may publish.
+//   - BENCHMARK_MAX_MESSAGES      : How many messages a benchmark may receive, every received message keeps its latency until the benchmark ends.
+// - BENCHMARK_HEADER_BYTES       : A payload starts with the time it was sent (8 bytes), the publisher (4 bytes) and its sequence number (8 bytes), the rest is padding.
+// - BENCHMARK_DRAIN              : How long the subscribers wait for the messages still on their way once the publishers have stopped.
+// - BENCHMARK_CONNECT_TIMEOUT    : How long connecting and subscribing a simulated client may take.
+// - BENCHMARK_CLIENT_PLACEHOLDER : The placeholder of the topic pattern that stands for the number of the publisher.
+//
+// # Author
+// - Polariusz
+const BENCHMARK_MAX_PUBLISHERS = 1000
+const BENCHMARK_MAX_SUBSCRIBERS = 100
+const BENCHMARK_MAX_RATE = 10000
+const BENCHMARK_MAX_PAYLOAD_BYTES = 256 * 1024
+const BENCHMARK_MAX_DURATION = time.Hour
+const BENCHMARK_MAX_MESSAGES = 1000000
+const BENCHMARK_HEADER_BYTES = 20
+const BENCHMARK_DRAIN = 2 * time.Second
+const BENCHMARK_CONNECT_TIMEOUT = 10 * time.Second
+const BENCHMARK_CLIENT_PLACEHOLDER = "{client}"
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Name":"<N>","Broker":<MqttCredentials>,"Publishers":<P>,"Subscribers":<S>,"RatePerSecond":<R>,"PayloadBytes":<B>,"QoS":<Q>,"TopicPattern":"<T>","DurationSeconds":<D>}
+//   - <MqttCredentials>: The broker to load, the ClientId is the prefix of the simulated clients. Through the API, the broker of the MQTT-Client if the Ip is left out.
+//   - <P> : How many clients publish, 1 by default
+//   - <S> : How many clients subscribe to all of their topics and measure, 1 by default
+//   - <R> : The messages per second of every publisher, 10 by default
+//   - <B> : The size of a payload, 64 by default, at least BENCHMARK_HEADER_BYTES
+//   - <T> : The topic without wildcards, `{client}` is the number of the publisher, `bench/{client}` by default
+//   - <D> : How long the publishers publish, 10 by default
+//
+// # Used in
+// - PostBenchmarkHandler()
+// - runBenchmarkCommand()
+//
+// # Author
+// - Polariusz
+type BenchmarkWrapper struct {
+	Name string
+	Broker MqttCredentials
+	Publishers int
+	Subscribers int
+	RatePerSecond float64
+	PayloadBytes int
+	QoS int
+	TopicPattern string
+	DurationSeconds int
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall fill in the defaults of the benchmark and check it. The broker is checked with validateCredentials().
+//
+// # Returns
+// - error when the benchmark is not valid, the message can be shown to the client
+//
+// # Author
+// - Polariusz
+func validateBenchmark(benchmarkWrapper *BenchmarkWrapper) error {
+	if benchmarkWrapper.Broker.ClientId == "" {
+		benchmarkWrapper.Broker.ClientId = "bench"
+	}
+	errorMessage := ""
+	if validateCredentials(&errorMessage, &benchmarkWrapper.Broker) != 0 {
+		return fmt.Errorf("Broker: %s", errorMessage)
+	}
+	if benchmarkWrapper.Publishers == 0 {
+		benchmarkWrapper.Publishers = 1
+	}
+	if benchmarkWrapper.Subscribers == 0 {
+		benchmarkWrapper.Subscribers = 1
+	}
+	if benchmarkWrapper.RatePerSecond == 0 {
+		benchmarkWrapper.RatePerSecond = 10
+	}
+	if benchmarkWrapper.PayloadBytes == 0 {
+		benchmarkWrapper.PayloadBytes = 64
+	}
+	if benchmarkWrapper.TopicPattern == "" {
+		benchmarkWrapper.TopicPattern = "bench/" + BENCHMARK_CLIENT_PLACEHOLDER
+	}
+	if benchmarkWrapper.DurationSeconds == 0 {
+		benchmarkWrapper.DurationSeconds = 10
+	}
+	if benchmarkWrapper.Name == "" {
+		benchmarkWrapper.Name = fmt.Sprintf("%d x %g/s, %d B, QoS %d", benchmarkWrapper.Publishers, benchmarkWrapper.RatePerSecond, benchmarkWrapper.PayloadBytes, benchmarkWrapper.QoS)
+	}
+
+	if benchmarkWrapper.Publishers < 1 || benchmarkWrapper.Publishers > BENCHMARK_MAX_PUBLISHERS {
+		return fmt.Errorf("Publishers must be between 1 and %d", BENCHMARK_MAX_PUBLISHERS)
+	}
+	if benchmarkWrapper.Subscribers < 1 || benchmarkWrapper.Subscribers > BENCHMARK_MAX_SUBSCRIBERS {
+		return fmt.Errorf("Subscribers must be between 1 and %d", BENCHMARK_MAX_SUBSCRIBERS)
+	}
+	if benchmarkWrapper.RatePerSecond <= 0 || benchmarkWrapper.RatePerSecond > BENCHMARK_MAX_RATE {
+		return fmt.Errorf("RatePerSecond must be above 0 and at most %d", BENCHMARK_MAX_RATE)
+	}
+	if benchmarkWrapper.PayloadBytes < BENCHMARK_HEADER_BYTES || benchmarkWrapper.PayloadBytes > BENCHMARK_MAX_PAYLOAD_BYTES {
+		return fmt.Errorf("PayloadBytes must be between %d and %d", BENCHMARK_HEADER_BYTES, BENCHMARK_MAX_PAYLOAD_BYTES)
+	}
+	if benchmarkWrapper.QoS < 0 || benchmarkWrapper.QoS > 2 {
+		return fmt.Errorf("QoS must be 0, 1 or 2")
+	}
+	if strings.ContainsAny(benchmarkWrapper.TopicPattern, "+#") {
+		return fmt.Errorf("TopicPattern must not have wildcards")
+	}
+	if !validTopicFilter(benchmarkSubscription(benchmarkWrapper.TopicPattern)) {
+		return fmt.Errorf("TopicPattern must have %s as a whole level, like bench/%s/data", BENCHMARK_CLIENT_PLACEHOLDER, BENCHMARK_CLIENT_PLACEHOLDER)
+	}
+	if benchmarkWrapper.DurationSeconds < 1 || time.Duration(benchmarkWrapper.DurationSeconds)*time.Second > BENCHMARK_MAX_DURATION {
+		return fmt.Errorf("DurationSeconds must be between 1 and %d", BENCHMARK_MAX_DURATION/time.Second)
+	}
+	messages := float64(benchmarkWrapper.Publishers) * benchmarkWrapper.RatePerSecond * float64(benchmarkWrapper.DurationSeconds) * float64(benchmarkWrapper.Subscribers)
+	if messages > BENCHMARK_MAX_MESSAGES {
+		return fmt.Errorf("The subscribers would receive %.0f messages, at most %d are measured in one benchmark", messages, BENCHMARK_MAX_MESSAGES)
+	}
+
+	return nil
+}
+
+// # Description
+// - The function shall return the topic filter that the subscribers use, `{client}` becomes `+`.
+//
+// # Author
+// - Polariusz
+func benchmarkSubscription(topicPattern string) string {
+	return strings.ReplaceAll(topicPattern, BENCHMARK_CLIENT_PLACEHOLDER, "+")
+}
+
+// # Description
+// - The function shall return the row of a benchmark that is about to run.
+//
+// # Author
+// - Polariusz
+func benchmarkRow(benchmarkWrapper BenchmarkWrapper) database.InsertBenchmark {
+	port, _ := strconv.Atoi(benchmarkWrapper.Broker.Port)
+	return database.InsertBenchmark{
+		Name: benchmarkWrapper.Name,
+		BrokerIp: benchmarkWrapper.Broker.Ip,
+		BrokerPort: port,
+		Publishers: benchmarkWrapper.Publishers,
+		Subscribers: benchmarkWrapper.Subscribers,
+		RatePerSecond: benchmarkWrapper.RatePerSecond,
+		PayloadBytes: benchmarkWrapper.PayloadBytes,
+		QoS: benchmarkWrapper.QoS,
+		TopicPattern: benchmarkWrapper.TopicPattern,
+		DurationSeconds: benchmarkWrapper.DurationSeconds,
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - One run of a benchmark, with the counters that can be read while it runs.
+// - `seen` remembers the messages of every subscriber, so a duplicate of QoS 1 is not counted twice.
+//
+// # Used in
+// - BenchmarkManager
+// - runBenchmarkCommand()
+//
+// # Author
+// - Polariusz
+type benchmarkRun struct {
+	config BenchmarkWrapper
+	sent atomic.Int64
+	received atomic.Int64
+	cancelOnce sync.Once
+	cancelled chan struct{}
+	done chan struct{}
+
+	mutex sync.Mutex
+	latencies []float64
+	seen []map[uint64]struct{}
+}
+
+// # Author
+// - Polariusz
+func newBenchmarkRun(config BenchmarkWrapper) *benchmarkRun {
+	run := &benchmarkRun{
+		config: config,
+		cancelled: make(chan struct{}),
+		done: make(chan struct{}),
+		seen: make([]map[uint64]struct{}, config.Subscribers),
+	}
+	for subscriber := range run.seen {
+		run.seen[subscriber] = make(map[uint64]struct{})
+	}
+	return run
+}
+
+// # Description
+// - The method shall stop the publishers, what was measured until then is kept.
+//
+// # Author
+// - Polariusz
+func (br *benchmarkRun) cancel() {
+	br.cancelOnce.Do(func() { close(br.cancelled) })
+}
+
+// # Description
+// - The method shall return the client options of a simulated client, without reconnects, as a lost connection is part of the result.
+//
+// # Author
+// - Polariusz
+func (br *benchmarkRun) clientOptions(role string, number int) *mqtt.ClientOptions {
+	credentials := br.config.Broker
+	credentials.ClientId = fmt.Sprintf("%s-%s-%d", credentials.ClientId, role, number)
+	mqttOpts := mqttClientOptions(credentials)
+	mqttOpts.SetAutoReconnect(false).SetConnectTimeout(BENCHMARK_CONNECT_TIMEOUT)
+	return mqttOpts
+}
+
+// # Description
+// - The method shall return the handler of a subscriber, it measures the latency of every message that it has not seen yet.
+//
+// # Author
+// - Polariusz
+func (br *benchmarkRun) receive(subscriber int) mqtt.MessageHandler {
+	return func(_ mqtt.Client, msg mqtt.Message) {
+		receivedAt := time.Now()
+		payload := msg.Payload()
+		if len(payload) < BENCHMARK_HEADER_BYTES {
+			return
+		}
+		sentAt := time.Unix(0, int64(binary.BigEndian.Uint64(payload[0:8])))
+		key := uint64(binary.BigEndian.Uint32(payload[8:12]))<<40 | binary.BigEndian.Uint64(payload[12:20])
+
+		br.mutex.Lock()
+		defer br.mutex.Unlock()
+		if _, seen := br.seen[subscriber][key]; seen {
+			return
+		}
+		br.seen[subscriber][key] = struct{}{}
+		br.latencies = append(br.latencies, float64(receivedAt.Sub(sentAt))/float64(time.Millisecond))
+		br.received.Add(1)
+	}
+}
+
+// # Description
+// - The method shall publish at the rate of the benchmark until `until` or until it is cancelled.
+//
+// # Author
+// - Polariusz
+func (br *benchmarkRun) publish(client mqtt.Client, publisher int, until time.Time) {
+	topic := strings.ReplaceAll(br.config.TopicPattern, BENCHMARK_CLIENT_PLACEHOLDER, strconv.Itoa(publisher))
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / br.config.RatePerSecond))
+	defer ticker.Stop()
+	stop := time.NewTimer(time.Until(until))
+	defer stop.Stop()
+
+	for sequence := uint64(1); ; sequence++ {
+		payload := make([]byte, br.config.PayloadBytes)
+		binary.BigEndian.PutUint32(payload[8:12], uint32(publisher))
+		binary.BigEndian.PutUint64(payload[12:20], sequence)
+		binary.BigEndian.PutUint64(payload[0:8], uint64(time.Now().UnixNano()))
+		client.Publish(topic, byte(br.config.QoS), false, payload)
+		br.sent.Add(1)
+
+		select {
+		case <-ticker.C:
+		case <-stop.C:
+			return
+		case <-br.cancelled:
+			return
+		}
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall run the benchmark and return what it has measured:
+//   - The subscribers connect and subscribe to the topic pattern, then the publishers connect.
+//   - The publishers publish for DurationSeconds, the subscribers get BENCHMARK_DRAIN more for the messages on their way.
+//   - All simulated clients disconnect.
+// - The latency is from the publish to the arrival at a subscriber, both in this process, so the clocks agree.
+//
+// # Author
+// - Polariusz
+func (br *benchmarkRun) execute() database.BenchmarkResult {
+	var clients []mqtt.Client
+	defer func() {
+		for _, client := range clients {
+			client.Disconnect(250)
+		}
+	}()
+	connect := func(role string, number int, subscribe bool) (mqtt.Client, error) {
+		client := newMqttClient(br.clientOptions(role, number))
+		if token := client.Connect(); !token.WaitTimeout(BENCHMARK_CONNECT_TIMEOUT) || token.Error() != nil {
+			return nil, fmt.Errorf("The %s %d could not connect: %v", role, number, token.Error())
+		}
+		clients = append(clients, client)
+		if subscribe {
+			if token := client.Subscribe(benchmarkSubscription(br.config.TopicPattern), byte(br.config.QoS), br.receive(number)); !token.WaitTimeout(BENCHMARK_CONNECT_TIMEOUT) || token.Error() != nil {
+				return nil, fmt.Errorf("The %s %d could not subscribe: %v", role, number, token.Error())
+			}
+		}
+		return client, nil
+	}
+
+	for subscriber := 0; subscriber < br.config.Subscribers; subscriber++ {
+		if _, err := connect("sub", subscriber, true); err != nil {
+			return database.BenchmarkResult{Status: database.BENCHMARK_FAILED, Error: err.Error()}
+		}
+	}
+	publishers := make([]mqtt.Client, br.config.Publishers)
+	for publisher := range publishers {
+		client, err := connect("pub", publisher, false)
+		if err != nil {
+			return database.BenchmarkResult{Status: database.BENCHMARK_FAILED, Error: err.Error()}
+		}
+		publishers[publisher] = client
+	}
+
+	startedAt := time.Now()
+	until := startedAt.Add(time.Duration(br.config.DurationSeconds) * time.Second)
+	var wait sync.WaitGroup
+	for publisher, client := range publishers {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			br.publish(client, publisher, until)
+		}()
+	}
+	wait.Wait()
+	publishedFor := time.Since(startedAt)
+
+	drain := time.NewTimer(BENCHMARK_DRAIN)
+	defer drain.Stop()
+	poll := time.NewTicker(10 * time.Millisecond)
+	defer poll.Stop()
+	for br.received.Load() < br.sent.Load()*int64(br.config.Subscribers) {
+		select {
+		case <-poll.C:
+			continue
+		case <-drain.C:
+		case <-br.cancelled:
+		}
+		break
+	}
+
+	status := database.BENCHMARK_FINISHED
+	select {
+	case <-br.cancelled:
+		status = database.BENCHMARK_CANCELLED
+	default:
+	}
+	return br.result(status, publishedFor)
+}
+
+// # Description
+// - The method shall sum up the counters and the latencies, the percentiles are of the nearest rank.
+//
+// # Author
+// - Polariusz
+func (br *benchmarkRun) result(status string, publishedFor time.Duration) database.BenchmarkResult {
+	br.mutex.Lock()
+	latencies := append([]float64(nil), br.latencies...)
+	br.mutex.Unlock()
+
+	sent := int(br.sent.Load())
+	expected := sent * br.config.Subscribers
+	result := database.BenchmarkResult{
+		Status: status,
+		Sent: sent,
+		Received: len(latencies),
+		Lost: max(expected-len(latencies), 0),
+	}
+	if expected > 0 {
+		result.LossPercent = 100 * float64(result.Lost) / float64(expected)
+	}
+	if publishedFor > 0 {
+		result.ThroughputPerSecond = float64(len(latencies)) / publishedFor.Seconds()
+	}
+	if len(latencies) == 0 {
+		return result
+	}
+
+	sort.Float64s(latencies)
+	sum := 0.0
+	for _, latency := range latencies {
+		sum += latency
+	}
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p / 100 * float64(len(latencies))))
+		return latencies[max(rank-1, 0)]
+	}
+	result.LatencyMinMs = latencies[0]
+	result.LatencyMeanMs = sum / float64(len(latencies))
+	result.LatencyP50Ms = percentile(50)
+	result.LatencyP90Ms = percentile(90)
+	result.LatencyP95Ms = percentile(95)
+	result.LatencyP99Ms = percentile(99)
+	result.LatencyMaxMs = latencies[len(latencies)-1]
+	return result
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - Runs the benchmarks of the API, one at a time, as two at once would measure each other.
+//
+// # Used in
+// - struct ServerState
+//
+// # Author
+// - Polariusz
+type BenchmarkManager struct {
+	con *sql.DB
+	mutex sync.Mutex
+	runs map[int]*benchmarkRun
+}
+
+// # Author
+// - Polariusz
+func NewBenchmarkManager(con *sql.DB) *BenchmarkManager {
+	return &BenchmarkManager{
+		con: con,
+		runs: make(map[int]*benchmarkRun),
+	}
+}
+
+// # Description
+// - The method shall fail the benchmarks that were running when the server stopped.
+//
+// # Author
+// - Polariusz
+func (bm *BenchmarkManager) start() {
+	if _, err := database.FailRunningBenchmarks(bm.con, "The server stopped while the benchmark was running"); err != nil {
+		fmt.Printf("WARN: Benchmarks that were running are not failed\nErr:%s\n", err)
+	}
+}
+
+// # Description
+// - The method shall cancel the running benchmark and wait until its result is written.
+//
+// # Author
+// - Polariusz
+func (bm *BenchmarkManager) close() {
+	bm.mutex.Lock()
+	runs := make([]*benchmarkRun, 0, len(bm.runs))
+	for _, run := range bm.runs {
+		runs = append(runs, run)
+	}
+	bm.mutex.Unlock()
+
+	for _, run := range runs {
+		run.cancel()
+		<-run.done
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall insert the benchmark and run it in the background, its result is written into its row when it ends.
+//
+// # Returns
+// - int: [Benchmark].[ID]
+// - bool: false if a benchmark is already running
+//
+// # Author
+// - Polariusz
+func (bm *BenchmarkManager) run(config BenchmarkWrapper) (int, bool, error) {
+	bm.mutex.Lock()
+	defer bm.mutex.Unlock()
+	if len(bm.runs) > 0 {
+		return -1, false, nil
+	}
+
+	benchmarkId, err := database.InsertNewBenchmark(bm.con, benchmarkRow(config))
+	if err != nil {
+		return -1, true, err
+	}
+	run := newBenchmarkRun(config)
+	bm.runs[benchmarkId] = run
+
+	go func() {
+		defer close(run.done)
+		result := run.execute()
+		if err := database.UpdateBenchmarkResult(bm.con, benchmarkId, result); err != nil {
+			fmt.Printf("WARN: The result of the benchmark %d is lost\nErr: %s\n", benchmarkId, err)
+		}
+		bm.mutex.Lock()
+		delete(bm.runs, benchmarkId)
+		bm.mutex.Unlock()
+	}()
+
+	return benchmarkId, true, nil
+}
+
+// # Description
+// - The method shall return the run of a benchmark that is running.
+//
+// # Author
+// - Polariusz
+func (bm *BenchmarkManager) running(benchmarkId int) (*benchmarkRun, bool) {
+	bm.mutex.Lock()
+	defer bm.mutex.Unlock()
+	run, ok := bm.runs[benchmarkId]
+	return run, ok
+}
+
+// # Description
+// - The function shall fill in the counters of a benchmark that is still running, so its row shows how far it is.
+//
+// # Author
+// - Polariusz
+func withProgress(serverState *ServerState, benchmark database.SelectBenchmark) database.SelectBenchmark {
+	if run, ok := serverState.benchmarks.running(benchmark.Id); ok && benchmark.FinishedAt == nil {
+		benchmark.Result.Sent = int(run.sent.Load())
+		benchmark.Result.Received = int(run.received.Load())
+	}
+	return benchmark
+}
+
+// # Description
+// - The function shall return the benchmark of the `:id` of the path.
+//
+// # Returns
+// - bool: false if the response was written
+//
+// # Author
+// - Polariusz
+func benchmarkOfPath(c *fiber.Ctx, serverState *ServerState) (database.SelectBenchmark, bool, error) {
+	benchmarkId, err := paramId(c, "id")
+	if err != nil {
+		return database.SelectBenchmark{}, false, writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+	}
+	benchmark, exists, err := database.SelectBenchmarkById(serverState.con, benchmarkId)
+	if err != nil {
+		return benchmark, false, writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting from the Benchmark table", map[string]any{"Error": err.Error()})
+	}
+	if !exists {
+		return benchmark, false, writeAPIError(c, fiber.StatusNotFound, API_ERROR_NOT_FOUND, "There is no benchmark with this Id", nil)
+	}
+	return benchmark, true, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return all benchmarks with their results, the newest first, so they can be compared.
+// - The method shall need the role admin for all brokers.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"benchmarks":[<database.SelectBenchmark>]}
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 500 (Internal Server Error): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func GetBenchmarksHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		benchmarkList, err := database.SelectBenchmarks(serverState.con)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting from the Benchmark table", map[string]any{"Error": err.Error()})
+		}
+		for index, benchmark := range benchmarkList {
+			benchmarkList[index] = withProgress(serverState, benchmark)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"benchmarks": benchmarkList,
+		})
+	}
+}
+
+// | Date of change | By        | Comment                |
+// +----------------+-----------+------------------------+
+// | 2026-10-19     | Polariusz | Created                |
+// | 2026-10-19     | Polariusz | Not after a disconnect |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall start a benchmark in the background.
+// - The method shall accept a jsonified structure that follows the struct BenchmarkWrapper.
+// - Without a Broker Ip, the benchmark loads the broker of the MQTT-Client with its credentials, the ClientId becomes the prefix of the simulated clients.
+// - The method shall need the role admin for all brokers, as a benchmark can load any broker.
+//
+// # Returns
+// - 202 (Accepted): JSON
+//   - {"Id":<BENCHMARK-ID>}
+// - 400 (Bad Request): `APIErrorEnvelope`
+// - 401 (Unauthorized): `APIErrorEnvelope`, without a Broker Ip and a connected MQTT-Client
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 409 (Conflict): `APIErrorEnvelope`, a benchmark is already running
+// - 500 (Internal Server Error): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func PostBenchmarkHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var benchmarkWrapper BenchmarkWrapper
+		if err := c.BodyParser(&benchmarkWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		if benchmarkWrapper.Broker.Ip == "" {
+			if serverState.mqttClient == nil || !serverState.mqttClient.IsConnected() || serverState.userCreds.Ip == "" {
+				return writeAPIError(c, fiber.StatusUnauthorized, API_ERROR_NOT_CONNECTED, "Give the Broker, or connect the MQTT-Client to the broker to load", nil)
+			}
+			clientId := benchmarkWrapper.Broker.ClientId
+			benchmarkWrapper.Broker = serverState.userCreds
+			benchmarkWrapper.Broker.ClientId = clientId
+		}
+		if err := validateBenchmark(&benchmarkWrapper); err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+
+		benchmarkId, started, err := serverState.benchmarks.run(benchmarkWrapper)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while inserting in the Benchmark table", map[string]any{"Error": err.Error()})
+		}
+		if !started {
+			return writeAPIError(c, fiber.StatusConflict, API_ERROR_CONFLICT, "A benchmark is already running, wait for it or cancel it", nil)
+		}
+
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"Id": benchmarkId,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return a benchmark, while it runs with the messages sent and received so far.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - <database.SelectBenchmark>
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func GetBenchmarkHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		benchmark, ok, err := benchmarkOfPath(c, serverState)
+		if !ok {
+			return err
+		}
+
+		return c.Status(fiber.StatusOK).JSON(withProgress(serverState, benchmark))
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall cancel a running benchmark. It ends `cancelled` shortly after, with what it has measured.
+//
+// # Returns
+// - 202 (Accepted): JSON
+//   - {"Id":<BENCHMARK-ID>}
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+// - 409 (Conflict): `APIErrorEnvelope`, the benchmark is not running
+//
+// # Author
+// - Polariusz
+func PostBenchmarkCancelHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		benchmark, ok, err := benchmarkOfPath(c, serverState)
+		if !ok {
+			return err
+		}
+		run, ok := serverState.benchmarks.running(benchmark.Id)
+		if !ok {
+			return writeAPIError(c, fiber.StatusConflict, API_ERROR_CONFLICT, fmt.Sprintf("The benchmark %d is not running", benchmark.Id), nil)
+		}
+		run.cancel()
+
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"Id": benchmark.Id,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall delete a benchmark that is not running.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"Id":<BENCHMARK-ID>}
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+// - 409 (Conflict): `APIErrorEnvelope`, the benchmark is running
+// - 500 (Internal Server Error): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func DeleteBenchmarkHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		benchmark, ok, err := benchmarkOfPath(c, serverState)
+		if !ok {
+			return err
+		}
+		if _, running := serverState.benchmarks.running(benchmark.Id); running {
+			return writeAPIError(c, fiber.StatusConflict, API_ERROR_CONFLICT, fmt.Sprintf("The benchmark %d is running, cancel it first", benchmark.Id), nil)
+		}
+		if _, err := database.DeleteBenchmark(serverState.con, benchmark.Id); err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while deleting from the Benchmark table", map[string]any{"Error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"Id": benchmark.Id,
+		})
+	}
+}
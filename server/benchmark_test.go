@@ -0,0 +1,179 @@
+package main
+
+import (
+	"database"
+	"fmt"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestValidateBenchmark(t *testing.T) {
+	broker := MqttCredentials{Ip: "127.0.0.1", Port: "1883"}
+	for name, benchmark := range map[string]BenchmarkWrapper{
+		"no broker": {},
+		"too many publishers": {Broker: broker, Publishers: BENCHMARK_MAX_PUBLISHERS + 1},
+		"negative rate": {Broker: broker, RatePerSecond: -1},
+		"small payload": {Broker: broker, PayloadBytes: BENCHMARK_HEADER_BYTES - 1},
+		"bad qos": {Broker: broker, QoS: 3},
+		"wildcard": {Broker: broker, TopicPattern: "bench/#"},
+		"placeholder in a level": {Broker: broker, TopicPattern: "bench/c{client}"},
+		"too long": {Broker: broker, DurationSeconds: 3601},
+		"too many messages": {Broker: broker, Publishers: 1000, RatePerSecond: 100, DurationSeconds: 60},
+	} {
+		if err := validateBenchmark(&benchmark); err == nil {
+			t.Errorf("%s was accepted", name)
+		}
+	}
+
+	benchmark := BenchmarkWrapper{Broker: broker}
+	if err := validateBenchmark(&benchmark); err != nil {
+		t.Fatalf("the defaults: %s", err)
+	}
+	if benchmark.Broker.ClientId != "bench" || benchmark.TopicPattern != "bench/{client}" || benchmark.Name == "" || benchmarkSubscription(benchmark.TopicPattern) != "bench/+" {
+		t.Errorf("the defaults: %+v", benchmark)
+	}
+}
+
+func TestBenchmarkResult(t *testing.T) {
+	run := newBenchmarkRun(BenchmarkWrapper{Subscribers: 2})
+	for latency := 100; latency >= 1; latency-- {
+		run.latencies = append(run.latencies, float64(latency))
+	}
+	run.sent.Store(60)
+
+	result := run.result(database.BENCHMARK_FINISHED, 2*time.Second)
+	if result.Received != 100 || result.Lost != 20 || result.LossPercent != 100.0/6 || result.ThroughputPerSecond != 50 {
+		t.Errorf("the counters: %+v", result)
+	}
+	if result.LatencyMinMs != 1 || result.LatencyP50Ms != 50 || result.LatencyP90Ms != 90 || result.LatencyP99Ms != 99 || result.LatencyMaxMs != 100 || result.LatencyMeanMs != 50.5 {
+		t.Errorf("the latencies: %+v", result)
+	}
+}
+
+func TestBenchmarks(t *testing.T) {
+	con, err := database.OpenDatabase(database.InMemoryDatabase)
+	if err != nil {
+		t.Fatalf("OpenDatabase: %s", err)
+	}
+	defer con.Close()
+	if err := database.SetupDatabase(con); err != nil {
+		t.Fatalf("SetupDatabase: %s", err)
+	}
+	accounts, err := NewAccountManager(con)
+	if err != nil {
+		t.Fatalf("NewAccountManager: %s", err)
+	}
+
+	broker := newMemoryBroker()
+	defer func(original func(*mqtt.ClientOptions) mqtt.Client) { newMqttClient = original }(newMqttClient)
+	newMqttClient = broker.newClient
+
+	// A benchmark that was running when the server stopped is failed at the start.
+	leftOverId, _ := database.InsertNewBenchmark(con, database.InsertBenchmark{Name: "left over"})
+	serverState := &ServerState{con: con, store: database.NewSqliteStore(con), accounts: accounts, benchmarks: NewBenchmarkManager(con), replayManager: NewReplayManager()}
+	serverState.benchmarks.start()
+	defer serverState.benchmarks.close()
+	server := fiber.New()
+	addRoutes(server, serverState)
+
+	benchmark := func(benchmarkId int) database.SelectBenchmark {
+		t.Helper()
+		benchmark, exists, err := database.SelectBenchmarkById(con, benchmarkId)
+		if err != nil || !exists {
+			t.Fatalf("the benchmark %d: %v %s", benchmarkId, exists, err)
+		}
+		return benchmark
+	}
+	if leftOver := benchmark(leftOverId); leftOver.Result.Status != database.BENCHMARK_FAILED || leftOver.FinishedAt == nil {
+		t.Errorf("the benchmark that was left running: %+v", leftOver)
+	}
+	finished := func(benchmarkId int) database.SelectBenchmark {
+		t.Helper()
+		for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+			if benchmark := benchmark(benchmarkId); benchmark.FinishedAt != nil {
+				return benchmark
+			}
+		}
+		t.Fatalf("the benchmark %d did not finish", benchmarkId)
+		return database.SelectBenchmark{}
+	}
+
+	if status, body := request(t, server, "POST", "/api/v1/benchmarks", `{}`, nil); status != fiber.StatusUnauthorized {
+		t.Errorf("a benchmark of the MQTT-Client without a connection: %d %v", status, body)
+	}
+
+	status, body := request(t, server, "POST", "/api/v1/benchmarks", `{"Name":"two by two","Broker":{"Ip":"127.0.0.1","Port":"1883"},"Publishers":2,"Subscribers":2,"RatePerSecond":50,"PayloadBytes":100,"QoS":1,"DurationSeconds":1}`, nil)
+	if status != fiber.StatusAccepted {
+		t.Fatalf("start a benchmark: %d %v", status, body)
+	}
+	benchmarkId := int(body["Id"].(float64))
+	if status, body := request(t, server, "POST", "/api/v1/benchmarks", `{"Broker":{"Ip":"127.0.0.1","Port":"1883"}}`, nil); status != fiber.StatusConflict {
+		t.Errorf("a second benchmark at once: %d %v", status, body)
+	}
+	if status, body := request(t, server, "DELETE", fmt.Sprintf("/api/v1/benchmarks/%d", benchmarkId), "", nil); status != fiber.StatusConflict {
+		t.Errorf("delete a running benchmark: %d %v", status, body)
+	}
+
+	result := finished(benchmarkId).Result
+	if result.Status != database.BENCHMARK_FINISHED || result.Sent < 80 || result.Sent > 110 || result.Received != 2*result.Sent || result.Lost != 0 {
+		t.Errorf("the counters: %+v", result)
+	}
+	if result.ThroughputPerSecond <= 0 || result.LatencyP50Ms < result.LatencyMinMs || result.LatencyP99Ms < result.LatencyP50Ms || result.LatencyMaxMs < result.LatencyP99Ms {
+		t.Errorf("the measurements: %+v", result)
+	}
+	broker.mutex.Lock()
+	clientIds := fmt.Sprint(broker.clientIds)
+	broker.mutex.Unlock()
+	if clientIds != "[bench-sub-0 bench-sub-1 bench-pub-0 bench-pub-1]" {
+		t.Errorf("the simulated clients: %s", clientIds)
+	}
+	if status, _ := request(t, server, "POST", fmt.Sprintf("/api/v1/benchmarks/%d/cancel", benchmarkId), "", nil); status != fiber.StatusConflict {
+		t.Errorf("cancel a finished benchmark: %d", status)
+	}
+
+	// A cancelled benchmark keeps what it has measured.
+	status, body = request(t, server, "POST", "/api/v1/benchmarks", `{"Broker":{"Ip":"127.0.0.1","Port":"1883"},"DurationSeconds":60}`, nil)
+	if status != fiber.StatusAccepted {
+		t.Fatalf("start a long benchmark: %d %v", status, body)
+	}
+	longId := int(body["Id"].(float64))
+	time.Sleep(300 * time.Millisecond)
+	if status, body := request(t, server, "GET", fmt.Sprintf("/api/v1/benchmarks/%d", longId), "", nil); status != fiber.StatusOK || body["Result"].(map[string]any)["Sent"] == float64(0) {
+		t.Errorf("the progress of a running benchmark: %d %v", status, body)
+	}
+	if status, body := request(t, server, "POST", fmt.Sprintf("/api/v1/benchmarks/%d/cancel", longId), "", nil); status != fiber.StatusAccepted {
+		t.Fatalf("cancel the benchmark: %d %v", status, body)
+	}
+	if cancelled := finished(longId).Result; cancelled.Status != database.BENCHMARK_CANCELLED || cancelled.Sent == 0 {
+		t.Errorf("the cancelled benchmark: %+v", cancelled)
+	}
+
+	if status, _ := request(t, server, "DELETE", fmt.Sprintf("/api/v1/benchmarks/%d", benchmarkId), "", nil); status != fiber.StatusOK {
+		t.Errorf("delete the benchmark: %d", status)
+	}
+	status, body = request(t, server, "GET", "/api/v1/benchmarks", "", nil)
+	if benchmarkList, _ := body["benchmarks"].([]any); status != fiber.StatusOK || len(benchmarkList) != 2 {
+		t.Errorf("the benchmarks: %d %v", status, body)
+	}
+
+	// Without a Broker, the broker of the MQTT-Client is loaded, until it disconnects.
+	if status, body := request(t, server, "POST", "/credentials", `{"Ip":"10.0.0.7","Port":"1884","ClientId":"explorer"}`, nil); status != fiber.StatusOK {
+		t.Fatalf("connect: %d %v", status, body)
+	}
+	status, body = request(t, server, "POST", "/api/v1/benchmarks", `{"Name":"of the client","DurationSeconds":1}`, nil)
+	if status != fiber.StatusAccepted {
+		t.Fatalf("a benchmark of the MQTT-Client: %d %v", status, body)
+	}
+	if ofClient := finished(int(body["Id"].(float64))); ofClient.BrokerIp != "10.0.0.7" || ofClient.BrokerPort != 1884 {
+		t.Errorf("the broker of the benchmark of the MQTT-Client: %s:%d", ofClient.BrokerIp, ofClient.BrokerPort)
+	}
+	if status, body := request(t, server, "POST", "/disconnect", "", nil); status != fiber.StatusOK {
+		t.Fatalf("disconnect: %d %v", status, body)
+	}
+	if status, body := request(t, server, "POST", "/api/v1/benchmarks", `{}`, nil); status != fiber.StatusUnauthorized {
+		t.Errorf("a benchmark of the MQTT-Client after the disconnect: %d %v", status, body)
+	}
+}
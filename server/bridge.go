@@ -0,0 +1,969 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gofiber/fiber/v2"
+)
+
+// How many received messages may wait to be forwarded, more are dropped so the source is never blocked.
+const BRIDGE_QUEUE_CAPACITY = 1000
+
+// How long a publish to the destination may take.
+const BRIDGE_PUBLISH_TIMEOUT = 10 * time.Second
+
+// How long to wait between two tries to connect to a broker.
+const BRIDGE_RETRY_INTERVAL = 5 * time.Second
+
+// How long a forwarded message is remembered to recognise it when it comes back, see BridgeEchoes.
+const BRIDGE_ECHO_TTL = 10 * time.Second
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - States in which a bridge can be.
+//   - A started bridge is `Connecting` until both of its brokers are connected, and `Reconnecting` while one of them is lost.
+const (
+	BRIDGE_STOPPED = "Stopped"
+	BRIDGE_CONNECTING = "Connecting"
+	BRIDGE_RUNNING = "Running"
+	BRIDGE_RECONNECTING = "Reconnecting"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Name":"<N>","Source":<MqttCredentials>,"TopicFilters":["<F>"],"Destination":<MqttCredentials>,"Rewrites":[{"From":"<RF>","To":"<RT>"}],"QoS":<Q>,"RetainMode":"<R>"}
+//   - <F>  : MQTT topic filters that are subscribed at the source
+//   - <RF> : Topic prefix that is replaced with <RT>, the first rule that matches is used. Topics without a matching rule are forwarded as they are.
+//   - <Q>  : The QoS of the forwarded messages, the QoS they were received with if it is missing or -1
+//   - <R>  : keep (default), never or always, see database.BRIDGE_RETAIN_KEEP
+//
+// # Used in
+// - PostBridgeHandler()
+//
+// # Author
+// - Polariusz
+type BridgeWrapper struct {
+	Name string
+	Source MqttCredentials
+	TopicFilters []string
+	Destination MqttCredentials
+	Rewrites []database.BridgeRewrite
+	QoS *int
+	RetainMode string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - What a bridge has done since it was started. The counters start at 0 with every start.
+//   - `Received` counts the messages of the source, `Forwarded` the ones published to the destination.
+//   - `Looped` counts the messages that were not forwarded because they had already been at the destination, see BridgeEchoes.
+//   - `Dropped` counts the messages that did not fit into the queue, `Failed` the ones the destination did not take.
+//
+// # Author
+// - Polariusz
+type BridgeStatus struct {
+	State string
+	Error string
+	StartedAt time.Time
+	Received int64
+	Forwarded int64
+	Looped int64
+	Dropped int64
+	Failed int64
+	Bytes int64
+	LastMessageAt time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A bridge as it is returned to the client, without its passwords.
+//
+// # Author
+// - Polariusz
+type BridgeView struct {
+	Bridge database.SelectBridge
+	Status BridgeStatus
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - It remembers the messages the bridges have published, with the brokers each of them has already been at.
+// - A bridge that receives such a message does not forward it to a broker it has been at. So a message can go A -> B -> C, but not A -> B -> A, and not A -> A.
+// - MQTT 3.1.1 can not tell who published a message, so it is recognised by its broker, topic and payload for BRIDGE_ECHO_TTL.
+//   - The same payload published again on the same topic by someone else within that time is taken as the echo too.
+//
+// # Author
+// - Polariusz
+type BridgeEchoes struct {
+	mutex sync.Mutex
+	paths map[string][]string
+	expires map[string]time.Time
+	lastSweep time.Time
+}
+
+// # Author
+// - Polariusz
+func NewBridgeEchoes() *BridgeEchoes {
+	return &BridgeEchoes{
+		paths: make(map[string][]string),
+		expires: make(map[string]time.Time),
+	}
+}
+
+// # Author
+// - Polariusz
+func echoKey(broker string, topic string, payload []byte) string {
+	hash := sha256.Sum256(payload)
+	return broker + "\x00" + topic + "\x00" + string(hash[:])
+}
+
+// # Description
+// - The method shall return the brokers the message has been at before it was published to `broker`, nil if no bridge published it.
+//
+// # Author
+// - Polariusz
+func (be *BridgeEchoes) path(broker string, topic string, payload []byte) []string {
+	be.mutex.Lock()
+	defer be.mutex.Unlock()
+
+	key := echoKey(broker, topic, payload)
+	if expires, ok := be.expires[key]; !ok || time.Now().After(expires) {
+		return nil
+	}
+	return be.paths[key]
+}
+
+// # Description
+// - The method shall remember that a bridge publishes the message to `broker`, after it has been at the brokers of `path`.
+//
+// # Author
+// - Polariusz
+func (be *BridgeEchoes) remember(broker string, topic string, payload []byte, path []string) {
+	be.mutex.Lock()
+	defer be.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(be.lastSweep) > BRIDGE_ECHO_TTL {
+		for key, expires := range be.expires {
+			if now.After(expires) {
+				delete(be.expires, key)
+				delete(be.paths, key)
+			}
+		}
+		be.lastSweep = now
+	}
+
+	key := echoKey(broker, topic, payload)
+	be.paths[key] = append(append([]string{}, path...), broker)
+	be.expires[key] = now.Add(BRIDGE_ECHO_TTL)
+}
+
+// # Description
+// - The function shall return how the brokers of the credentials are told apart by BridgeEchoes.
+//
+// # Author
+// - Polariusz
+func brokerAddress(credentials MqttCredentials) string {
+	return strings.ToLower(credentials.Ip) + ":" + credentials.Port
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall remap the topic with the first rewrite rule whose `From` it starts with.
+//
+// # Author
+// - Polariusz
+func rewriteTopic(rewrites []database.BridgeRewrite, topic string) string {
+	for _, rewrite := range rewrites {
+		if strings.HasPrefix(topic, rewrite.From) {
+			return rewrite.To + strings.TrimPrefix(topic, rewrite.From)
+		}
+	}
+	return topic
+}
+
+// # Author
+// - Polariusz
+func bridgeSource(bridge database.SelectBridge) MqttCredentials {
+	return MqttCredentials{Ip: bridge.SourceIp, Port: strconv.Itoa(bridge.SourcePort), ClientId: bridge.SourceClientId, Username: bridge.SourceUsername, Password: bridge.SourcePassword}
+}
+
+// # Author
+// - Polariusz
+func bridgeDestination(bridge database.SelectBridge) MqttCredentials {
+	return MqttCredentials{Ip: bridge.DestinationIp, Port: strconv.Itoa(bridge.DestinationPort), ClientId: bridge.DestinationClientId, Username: bridge.DestinationUsername, Password: bridge.DestinationPassword}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A received message that waits to be forwarded.
+//
+// # Author
+// - Polariusz
+type bridgeMessage struct {
+	topic string
+	payload []byte
+	qos byte
+	retained bool
+	path []string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A started bridge. It has a MQTT-Client for each broker, and a goroutine that publishes the received messages to the destination.
+// - Both MQTT-Clients reconnect on their own, the source subscribes the topic filters again on every connect.
+// - `done` is closed to stop the bridge, `stopped` is closed by forward() when it has stopped.
+//
+// # Author
+// - Polariusz
+type bridgeWorker struct {
+	mutex sync.Mutex
+	bridge database.SelectBridge
+	echoes *BridgeEchoes
+	status BridgeStatus
+	sourceUp bool
+	destinationUp bool
+	source mqtt.Client
+	destination mqtt.Client
+	queue chan bridgeMessage
+	done chan struct{}
+	stopped chan struct{}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall connect to both brokers and start forwarding. It does not wait for the connections, the status tells how far they are.
+//
+// # Author
+// - Polariusz
+func (bw *bridgeWorker) start() {
+	bw.status = BridgeStatus{State: BRIDGE_CONNECTING, StartedAt: time.Now()}
+	bw.queue = make(chan bridgeMessage, BRIDGE_QUEUE_CAPACITY)
+	bw.done = make(chan struct{})
+	bw.stopped = make(chan struct{})
+
+	subscribeQoS := byte(2)
+	if bw.bridge.QoS >= 0 {
+		subscribeQoS = byte(bw.bridge.QoS)
+	}
+	filters := make(map[string]byte)
+	for _, filter := range bw.bridge.TopicFilters {
+		filters[filter] = subscribeQoS
+	}
+
+	destinationOpts := mqttClientOptions(bridgeDestination(bw.bridge))
+	destinationOpts.SetAutoReconnect(true).SetConnectTimeout(BRIDGE_PUBLISH_TIMEOUT)
+	destinationOpts.SetOnConnectHandler(func(mqtt.Client) {
+		bw.connected(&bw.destinationUp, true, "")
+	})
+	destinationOpts.SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+		bw.connected(&bw.destinationUp, false, fmt.Sprintf("Destination lost: %s", err))
+	})
+
+	sourceOpts := mqttClientOptions(bridgeSource(bw.bridge))
+	sourceOpts.SetAutoReconnect(true).SetConnectTimeout(BRIDGE_PUBLISH_TIMEOUT)
+	sourceOpts.SetOnConnectHandler(func(client mqtt.Client) {
+		if token := client.SubscribeMultiple(filters, nil); !token.WaitTimeout(BRIDGE_PUBLISH_TIMEOUT) || token.Error() != nil {
+			bw.connected(&bw.sourceUp, false, fmt.Sprintf("Subscribing at the source failed: %v", token.Error()))
+			return
+		}
+		bw.connected(&bw.sourceUp, true, "")
+	})
+	sourceOpts.SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+		bw.connected(&bw.sourceUp, false, fmt.Sprintf("Source lost: %s", err))
+	})
+	sourceOpts.SetDefaultPublishHandler(bw.receive)
+
+	bw.destination = mqtt.NewClient(destinationOpts)
+	bw.source = mqtt.NewClient(sourceOpts)
+	go bw.connect(bw.destination, "destination")
+	go bw.connect(bw.source, "source")
+	go bw.forward()
+}
+
+// # Description
+// - The method shall connect the MQTT-Client, and try again every BRIDGE_RETRY_INTERVAL until it works or the bridge is stopped.
+// - Once connected, paho reconnects on its own.
+//
+// # Author
+// - Polariusz
+func (bw *bridgeWorker) connect(client mqtt.Client, what string) {
+	for {
+		token := client.Connect()
+		token.Wait()
+		if token.Error() == nil {
+			// The bridge may have been stopped while it was connecting.
+			select {
+			case <-bw.done:
+				client.Disconnect(250)
+			default:
+			}
+			return
+		}
+
+		bw.mutex.Lock()
+		bw.status.Error = fmt.Sprintf("Connecting to the %s failed: %s", what, token.Error())
+		bw.mutex.Unlock()
+
+		select {
+		case <-bw.done:
+			return
+		case <-time.After(BRIDGE_RETRY_INTERVAL):
+		}
+	}
+}
+
+// # Description
+// - The method shall disconnect from both brokers and wait until the forwarding has stopped. The messages that still wait are dropped.
+//
+// # Author
+// - Polariusz
+func (bw *bridgeWorker) stop() {
+	close(bw.done)
+	<-bw.stopped
+	bw.source.Disconnect(250)
+	bw.destination.Disconnect(250)
+
+	bw.mutex.Lock()
+	defer bw.mutex.Unlock()
+
+	bw.status.State = BRIDGE_STOPPED
+	bw.status.Dropped += int64(len(bw.queue))
+}
+
+// # Description
+// - The method shall update the state of the bridge after one of its MQTT-Clients has connected or was lost.
+//
+// # Author
+// - Polariusz
+func (bw *bridgeWorker) connected(up *bool, isUp bool, errorMessage string) {
+	bw.mutex.Lock()
+	defer bw.mutex.Unlock()
+
+	wasRunning := bw.status.State == BRIDGE_RUNNING
+	*up = isUp
+	if errorMessage != "" {
+		bw.status.Error = errorMessage
+	}
+	switch {
+	case bw.sourceUp && bw.destinationUp:
+		bw.status.State = BRIDGE_RUNNING
+	case wasRunning || bw.status.State == BRIDGE_RECONNECTING:
+		bw.status.State = BRIDGE_RECONNECTING
+	default:
+		bw.status.State = BRIDGE_CONNECTING
+	}
+}
+
+// # Description
+// - The method shall return a copy of the status of the bridge.
+//
+// # Author
+// - Polariusz
+func (bw *bridgeWorker) snapshot() BridgeStatus {
+	bw.mutex.Lock()
+	defer bw.mutex.Unlock()
+
+	return bw.status
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method is the message handler of the source. It only queues the message, so paho's router is not blocked by the destination.
+// - A message that a bridge has published at the source, and that has already been at the destination, is not forwarded.
+//
+// # Author
+// - Polariusz
+func (bw *bridgeWorker) receive(_ mqtt.Client, msg mqtt.Message) {
+	source := brokerAddress(bridgeSource(bw.bridge))
+	destination := brokerAddress(bridgeDestination(bw.bridge))
+
+	looped := false
+	path := bw.echoes.path(source, msg.Topic(), msg.Payload())
+	if path == nil {
+		path = []string{source}
+		// Forwarding to the same topic of the same broker would only publish the message again.
+		looped = source == destination && rewriteTopic(bw.bridge.Rewrites, msg.Topic()) == msg.Topic()
+	} else {
+		for _, broker := range path {
+			looped = looped || broker == destination
+		}
+	}
+
+	bw.mutex.Lock()
+	defer bw.mutex.Unlock()
+
+	bw.status.Received++
+	bw.status.LastMessageAt = time.Now()
+	if looped {
+		bw.status.Looped++
+		return
+	}
+
+	select {
+	case bw.queue <- bridgeMessage{topic: msg.Topic(), payload: msg.Payload(), qos: msg.Qos(), retained: msg.Retained(), path: path}:
+	default:
+		bw.status.Dropped++
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall publish the queued messages to the destination, with the topic rewritten and the QoS and retain flag of the bridge.
+//
+// # Author
+// - Polariusz
+func (bw *bridgeWorker) forward() {
+	defer close(bw.stopped)
+
+	destination := brokerAddress(bridgeDestination(bw.bridge))
+	for {
+		select {
+		case <-bw.done:
+			return
+		case message := <-bw.queue:
+			topic := rewriteTopic(bw.bridge.Rewrites, message.topic)
+			qos := message.qos
+			if bw.bridge.QoS >= 0 {
+				qos = byte(bw.bridge.QoS)
+			}
+			retained := message.retained
+			switch bw.bridge.RetainMode {
+			case database.BRIDGE_RETAIN_NEVER:
+				retained = false
+			case database.BRIDGE_RETAIN_ALWAYS:
+				retained = true
+			}
+
+			// It is remembered before the publish, as the echo can arrive before the publish is acknowledged.
+			bw.echoes.remember(destination, topic, message.payload, message.path)
+			token := bw.destination.Publish(topic, qos, retained, message.payload)
+			ok := token.WaitTimeout(BRIDGE_PUBLISH_TIMEOUT) && token.Error() == nil
+
+			bw.mutex.Lock()
+			if ok {
+				bw.status.Forwarded++
+				bw.status.Bytes += int64(len(message.payload))
+			} else {
+				bw.status.Failed++
+				if token.Error() != nil {
+					bw.status.Error = fmt.Sprintf("Publishing at the destination failed: %s", token.Error())
+				}
+			}
+			bw.mutex.Unlock()
+		}
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - It runs the bridges of table Bridge of one database in the background, like the webhooks it is replaced with the database.
+// - A bridge that is started stays Enabled in the database, so it is started again with the server.
+// - The status of a stopped bridge is kept until the bridge is started again.
+//
+// # Used in
+// - ServerState
+//
+// # Author
+// - Polariusz
+type BridgeManager struct {
+	con *sql.DB
+	mutex sync.Mutex
+	workers map[int]*bridgeWorker
+	echoes *BridgeEchoes
+}
+
+// # Author
+// - Polariusz
+func NewBridgeManager(con *sql.DB) *BridgeManager {
+	return &BridgeManager{
+		con: con,
+		workers: make(map[int]*bridgeWorker),
+		echoes: NewBridgeEchoes(),
+	}
+}
+
+// # Description
+// - The method shall start every Enabled bridge of the database.
+//
+// # Author
+// - Polariusz
+func (bm *BridgeManager) start() {
+	bridgeList, err := database.SelectBridges(bm.con)
+	if err != nil {
+		fmt.Printf("WARN: Running without bridges\nErr:%s\n", err)
+		return
+	}
+	for _, bridge := range bridgeList {
+		if bridge.Enabled {
+			bm.run(bridge)
+		}
+	}
+}
+
+// # Description
+// - The method shall stop every bridge, they stay Enabled in the database.
+//
+// # Author
+// - Polariusz
+func (bm *BridgeManager) close() {
+	bm.mutex.Lock()
+	defer bm.mutex.Unlock()
+
+	for _, worker := range bm.workers {
+		if worker.snapshot().State != BRIDGE_STOPPED {
+			worker.stop()
+		}
+	}
+}
+
+// # Description
+// - The method shall start the bridge, a running one is restarted.
+//
+// # Author
+// - Polariusz
+func (bm *BridgeManager) run(bridge database.SelectBridge) {
+	bm.mutex.Lock()
+	defer bm.mutex.Unlock()
+
+	if worker, ok := bm.workers[bridge.Id]; ok && worker.snapshot().State != BRIDGE_STOPPED {
+		worker.stop()
+	}
+	worker := &bridgeWorker{bridge: bridge, echoes: bm.echoes}
+	worker.start()
+	bm.workers[bridge.Id] = worker
+}
+
+// # Description
+// - The method shall stop the bridge, if it runs. With `forget` its status is dropped too.
+//
+// # Author
+// - Polariusz
+func (bm *BridgeManager) stop(bridgeId int, forget bool) {
+	bm.mutex.Lock()
+	defer bm.mutex.Unlock()
+
+	worker, ok := bm.workers[bridgeId]
+	if !ok {
+		return
+	}
+	if worker.snapshot().State != BRIDGE_STOPPED {
+		worker.stop()
+	}
+	if forget {
+		delete(bm.workers, bridgeId)
+	}
+}
+
+// # Description
+// - The method shall return the bridge with its status.
+//
+// # Author
+// - Polariusz
+func (bm *BridgeManager) view(bridge database.SelectBridge) BridgeView {
+	bm.mutex.Lock()
+	defer bm.mutex.Unlock()
+
+	status := BridgeStatus{State: BRIDGE_STOPPED}
+	if worker, ok := bm.workers[bridge.Id]; ok {
+		status = worker.snapshot()
+	}
+	return BridgeView{Bridge: bridge, Status: status}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Validator
+//
+// # Description
+// - The function shall fill in the defaults of the bridge and check it.
+// - A bridge from a broker back to the same broker must rewrite the topics, otherwise it would only republish every message where it came from.
+//
+// # Returns
+// - error when the bridge is not valid, the message can be shown to the client
+//
+// # Author
+// - Polariusz
+func validateBridge(bridgeWrapper *BridgeWrapper) error {
+	if bridgeWrapper.QoS == nil {
+		keep := -1
+		bridgeWrapper.QoS = &keep
+	}
+	if bridgeWrapper.RetainMode == "" {
+		bridgeWrapper.RetainMode = database.BRIDGE_RETAIN_KEEP
+	}
+
+	if bridgeWrapper.Name == "" {
+		return fmt.Errorf("Name is required")
+	}
+	errorMessage := ""
+	if validateCredentials(&errorMessage, &bridgeWrapper.Source) != 0 {
+		return fmt.Errorf("Source: %s", errorMessage)
+	}
+	if validateCredentials(&errorMessage, &bridgeWrapper.Destination) != 0 {
+		return fmt.Errorf("Destination: %s", errorMessage)
+	}
+	if len(bridgeWrapper.TopicFilters) == 0 {
+		return fmt.Errorf("TopicFilters must have at least one topic filter")
+	}
+	for _, filter := range bridgeWrapper.TopicFilters {
+		if !validTopicFilter(filter) {
+			return fmt.Errorf("The topic filter %q is not valid", filter)
+		}
+	}
+	for _, rewrite := range bridgeWrapper.Rewrites {
+		if strings.ContainsAny(rewrite.To, "+#") {
+			return fmt.Errorf("The rewrite to %q must not have wildcards", rewrite.To)
+		}
+	}
+	if *bridgeWrapper.QoS < -1 || *bridgeWrapper.QoS > 2 {
+		return fmt.Errorf("QoS must be -1, 0, 1 or 2")
+	}
+	switch bridgeWrapper.RetainMode {
+	case database.BRIDGE_RETAIN_KEEP, database.BRIDGE_RETAIN_NEVER, database.BRIDGE_RETAIN_ALWAYS:
+	default:
+		return fmt.Errorf("RetainMode must be keep, never or always")
+	}
+
+	if brokerAddress(bridgeWrapper.Source) == brokerAddress(bridgeWrapper.Destination) {
+		if bridgeWrapper.Source.ClientId == bridgeWrapper.Destination.ClientId {
+			return fmt.Errorf("The source and the destination are the same broker, they need different ClientIds")
+		}
+		if len(bridgeWrapper.Rewrites) == 0 {
+			return fmt.Errorf("The source and the destination are the same broker, the topics must be rewritten")
+		}
+	}
+
+	return nil
+}
+
+// # Description
+// - The function shall return the bridge of the path parameter `id`, or write the response if there is none.
+//
+// # Returns
+// - bool: false if the response was written
+//
+// # Author
+// - Polariusz
+func bridgeOfPath(c *fiber.Ctx, serverState *ServerState) (database.SelectBridge, bool, error) {
+	bridgeId, err := paramId(c, "id")
+	if err != nil {
+		return database.SelectBridge{}, false, writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+	}
+	bridgeList, err := database.SelectBridges(serverState.con)
+	if err != nil {
+		return database.SelectBridge{}, false, writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the bridges", map[string]any{"Error": err.Error()})
+	}
+	for _, bridge := range bridgeList {
+		if bridge.Id == bridgeId {
+			return bridge, true, nil
+		}
+	}
+	return database.SelectBridge{}, false, writeAPIError(c, fiber.StatusNotFound, API_ERROR_NOT_FOUND, "There is no bridge with this Id", nil)
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return all bridges of the selected project with their status.
+// - Bridges reach brokers the server may not know, so all bridge routes need the role admin on all brokers.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"bridges":[<BridgeView>]}
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 500 (Internal Server Error): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func GetBridgesHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+
+		bridgeList, err := database.SelectBridges(serverState.con)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the bridges", map[string]any{"Error": err.Error()})
+		}
+		viewList := []BridgeView{}
+		for _, bridge := range bridgeList {
+			viewList = append(viewList, serverState.bridges.view(bridge))
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"bridges": viewList,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall create a stopped bridge.
+// - The method shall accept a jsonified structure that follows the struct BridgeWrapper.
+//
+// # Returns
+// - 201 (Created): JSON
+//   - {"Id":<BRIDGE-ID>}
+// - 400 (Bad Request): `APIErrorEnvelope`
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 500 (Internal Server Error): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func PostBridgeHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+
+		var bridgeWrapper BridgeWrapper
+		if err := c.BodyParser(&bridgeWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+		if err := validateBridge(&bridgeWrapper); err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+
+		// The ports were checked by validateBridge().
+		sourcePort, _ := strconv.Atoi(bridgeWrapper.Source.Port)
+		destinationPort, _ := strconv.Atoi(bridgeWrapper.Destination.Port)
+		bridgeId, err := database.InsertNewBridge(serverState.con, database.InsertBridge{
+			Name: bridgeWrapper.Name,
+			SourceIp: bridgeWrapper.Source.Ip,
+			SourcePort: sourcePort,
+			SourceClientId: bridgeWrapper.Source.ClientId,
+			SourceUsername: bridgeWrapper.Source.Username,
+			SourcePassword: bridgeWrapper.Source.Password,
+			TopicFilters: bridgeWrapper.TopicFilters,
+			DestinationIp: bridgeWrapper.Destination.Ip,
+			DestinationPort: destinationPort,
+			DestinationClientId: bridgeWrapper.Destination.ClientId,
+			DestinationUsername: bridgeWrapper.Destination.Username,
+			DestinationPassword: bridgeWrapper.Destination.Password,
+			Rewrites: bridgeWrapper.Rewrites,
+			QoS: *bridgeWrapper.QoS,
+			RetainMode: bridgeWrapper.RetainMode,
+		})
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while inserting in the Bridge table", map[string]any{"Error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"Id": bridgeId,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return a bridge with its status.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - <BridgeView>
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func GetBridgeHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		bridge, ok, err := bridgeOfPath(c, serverState)
+		if !ok {
+			return err
+		}
+
+		return c.Status(fiber.StatusOK).JSON(serverState.bridges.view(bridge))
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Audited |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall stop and delete a bridge.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"Id":<BRIDGE-ID>}
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func DeleteBridgeHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_DELETE_BRIDGE}
+		defer serverState.auditLog.record(c, &record)
+
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		bridge, ok, err := bridgeOfPath(c, serverState)
+		if !ok {
+			return err
+		}
+		record.about(BrokerUser{}, bridge.TopicFilters...)
+
+		serverState.bridges.stop(bridge.Id, true)
+		if _, err := database.DeleteBridge(serverState.con, bridge.Id); err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while deleting the bridge", map[string]any{"Error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"Id": bridge.Id,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall start a bridge, or restart it with its counters at 0. It stays started after a restart of the server.
+// - The method does not wait for the brokers, the status is `Connecting` until both are connected.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - <BridgeView>
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func PostBridgeStartHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		bridge, ok, err := bridgeOfPath(c, serverState)
+		if !ok {
+			return err
+		}
+
+		if _, err := database.UpdateBridgeEnabled(serverState.con, bridge.Id, true); err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while updating the bridge", map[string]any{"Error": err.Error()})
+		}
+		bridge.Enabled = true
+		serverState.bridges.run(bridge)
+
+		return c.Status(fiber.StatusOK).JSON(serverState.bridges.view(bridge))
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall stop a bridge, its status stays until it is started again.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - <BridgeView>
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func PostBridgeStopHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		bridge, ok, err := bridgeOfPath(c, serverState)
+		if !ok {
+			return err
+		}
+
+		if _, err := database.UpdateBridgeEnabled(serverState.con, bridge.Id, false); err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while updating the bridge", map[string]any{"Error": err.Error()})
+		}
+		bridge.Enabled = false
+		serverState.bridges.stop(bridge.Id, false)
+
+		return c.Status(fiber.StatusOK).JSON(serverState.bridges.view(bridge))
+	}
+}
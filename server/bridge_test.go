@@ -0,0 +1,153 @@
+package main
+
+import (
+	"database"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// A received message, as paho hands it to a message handler.
+type fakeMessage struct {
+	topic string
+	payload string
+}
+
+func (m fakeMessage) Duplicate() bool { return false }
+func (m fakeMessage) Qos() byte { return 1 }
+func (m fakeMessage) Retained() bool { return false }
+func (m fakeMessage) Topic() string { return m.topic }
+func (m fakeMessage) MessageID() uint16 { return 0 }
+func (m fakeMessage) Payload() []byte { return []byte(m.payload) }
+func (m fakeMessage) Ack() {}
+
+func TestRewriteTopic(t *testing.T) {
+	rewrites := []database.BridgeRewrite{{From: "prod/plant/", To: "test/plant/"}, {From: "prod/", To: "test/other/"}}
+	for topic, want := range map[string]string{
+		"prod/plant/1/temp": "test/plant/1/temp",
+		"prod/line/2": "test/other/line/2",
+		"dev/line/2": "dev/line/2",
+	} {
+		if got := rewriteTopic(rewrites, topic); got != want {
+			t.Errorf("rewriteTopic(%s) = %s, want %s", topic, got, want)
+		}
+	}
+}
+
+func TestBridgeLoopPrevention(t *testing.T) {
+	echoes := NewBridgeEchoes()
+	newWorker := func(source string, destination string, rewrites ...database.BridgeRewrite) *bridgeWorker {
+		return &bridgeWorker{
+			bridge: database.SelectBridge{SourceIp: source, SourcePort: 1883, DestinationIp: destination, DestinationPort: 1883, Rewrites: rewrites, QoS: -1},
+			echoes: echoes,
+			queue: make(chan bridgeMessage, 10),
+		}
+	}
+	// What forward() does, without a destination to publish to.
+	forward := func(bw *bridgeWorker) bridgeMessage {
+		message := <-bw.queue
+		echoes.remember(brokerAddress(bridgeDestination(bw.bridge)), rewriteTopic(bw.bridge.Rewrites, message.topic), message.payload, message.path)
+		return message
+	}
+
+	aToB := newWorker("a", "b")
+	bToA := newWorker("b", "a")
+	bToC := newWorker("b", "c")
+
+	aToB.receive(nil, fakeMessage{"plant/1", "21.5"})
+	forward(aToB)
+	// The message is back at B, it may go on to C but not back to A.
+	bToA.receive(nil, fakeMessage{"plant/1", "21.5"})
+	bToC.receive(nil, fakeMessage{"plant/1", "21.5"})
+	if bToA.status.Looped != 1 || len(bToA.queue) != 0 {
+		t.Errorf("the message went back to A: %+v", bToA.status)
+	}
+	if message := forward(bToC); strings.Join(message.path, ",") != "a:1883,b:1883" {
+		t.Errorf("the path to C: %v", message.path)
+	}
+
+	// A message of its own at B is forwarded to A.
+	bToA.receive(nil, fakeMessage{"plant/1", "22"})
+	if len(bToA.queue) != 1 {
+		t.Errorf("a new message at B was not forwarded: %+v", bToA.status)
+	}
+
+	// A bridge from A to A with a rewrite forwards once, its own copy is not forwarded again.
+	mirror := newWorker("a", "a", database.BridgeRewrite{From: "prod/", To: "test/"})
+	mirror.receive(nil, fakeMessage{"prod/x", "1"})
+	forward(mirror)
+	mirror.receive(nil, fakeMessage{"test/x", "1"})
+	mirror.receive(nil, fakeMessage{"dev/x", "1"})
+	if mirror.status.Received != 3 || mirror.status.Looped != 2 || len(mirror.queue) != 0 {
+		t.Errorf("the mirror: %+v", mirror.status)
+	}
+}
+
+func TestBridgeRoutes(t *testing.T) {
+	con, err := database.OpenDatabase(database.InMemoryDatabase)
+	if err != nil {
+		t.Fatalf("OpenDatabase: %s", err)
+	}
+	defer con.Close()
+	if err := database.SetupDatabase(con); err != nil {
+		t.Fatalf("SetupDatabase: %s", err)
+	}
+
+	accounts, err := NewAccountManager(con)
+	if err != nil {
+		t.Fatalf("NewAccountManager: %s", err)
+	}
+	serverState := &ServerState{con: con, store: database.NewSqliteStore(con), accounts: accounts, bridges: NewBridgeManager(con)}
+	defer serverState.bridges.close()
+	server := fiber.New()
+	addRoutes(server, serverState)
+
+	bridge := func(source string, destination string, rest string) string {
+		return fmt.Sprintf(`{"Name":"mirror","Source":{"Ip":"prod.local","Port":"1883","ClientId":%q,"Username":"u","Password":"p"},"TopicFilters":["plant/#"],"Destination":{"Ip":%q,"Port":"1883","ClientId":"bridge-dst"}%s}`, source, destination, rest)
+	}
+	for name, body := range map[string]string{
+		"no filters": `{"Name":"x","Source":{"Ip":"a","Port":"1883","ClientId":"s"},"Destination":{"Ip":"b","Port":"1883","ClientId":"d"}}`,
+		"bad port": strings.Replace(bridge("bridge-src", "test.local", ""), `"Port":"1883"`, `"Port":"x"`, 1),
+		"bad qos": bridge("bridge-src", "test.local", `,"QoS":3`),
+		"bad retain": bridge("bridge-src", "test.local", `,"RetainMode":"sometimes"`),
+		"same broker, no rewrite": bridge("bridge-src", "prod.local", ""),
+		"same broker, same client": bridge("bridge-dst", "prod.local", `,"Rewrites":[{"From":"plant/","To":"copy/"}]`),
+	} {
+		if status, body := request(t, server, "POST", "/api/v1/bridges", body, nil); status != fiber.StatusBadRequest {
+			t.Errorf("%s: %d %v", name, status, body)
+		}
+	}
+
+	status, body := request(t, server, "POST", "/api/v1/bridges", bridge("bridge-src", "test.local", `,"Rewrites":[{"From":"plant/","To":"copy/"}]`), nil)
+	if status != fiber.StatusCreated {
+		t.Fatalf("create the bridge: %d %v", status, body)
+	}
+	bridgeId := int(body["Id"].(float64))
+
+	status, body = request(t, server, "GET", fmt.Sprintf("/api/v1/bridges/%d", bridgeId), "", nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("the bridge: %d %v", status, body)
+	}
+	saved := body["Bridge"].(map[string]any)
+	if saved["QoS"] != float64(-1) || saved["RetainMode"] != database.BRIDGE_RETAIN_KEEP || saved["Enabled"] != false || body["Status"].(map[string]any)["State"] != BRIDGE_STOPPED {
+		t.Errorf("the defaults: %v", body)
+	}
+	if _, ok := saved["SourcePassword"]; ok {
+		t.Errorf("the password was returned: %v", saved)
+	}
+
+	if status, body := request(t, server, "POST", fmt.Sprintf("/api/v1/bridges/%d/start", bridgeId), "", nil); status != fiber.StatusOK || body["Bridge"].(map[string]any)["Enabled"] != true {
+		t.Errorf("start the bridge: %d %v", status, body)
+	}
+	if status, body := request(t, server, "POST", fmt.Sprintf("/api/v1/bridges/%d/stop", bridgeId), "", nil); status != fiber.StatusOK || body["Status"].(map[string]any)["State"] != BRIDGE_STOPPED {
+		t.Errorf("stop the bridge: %d %v", status, body)
+	}
+	if status, _ := request(t, server, "DELETE", fmt.Sprintf("/api/v1/bridges/%d", bridgeId), "", nil); status != fiber.StatusOK {
+		t.Errorf("delete the bridge: %d", status)
+	}
+	if status, _ := request(t, server, "GET", fmt.Sprintf("/api/v1/bridges/%d", bridgeId), "", nil); status != fiber.StatusNotFound {
+		t.Errorf("a deleted bridge: %d", status)
+	}
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"bufio"
+	"database"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// | Date of change | By        | Comment          |
+// +----------------+-----------+------------------+
+// | 2026-10-19     | Polariusz | Created          |
+// | 2026-10-19     | Polariusz | Account commands |
+// | 2026-10-19     | Polariusz | Role command     |
+// | 2026-10-19     | Polariusz | Benchmarks       |
+// | 2026-10-19     | Polariusz | Config command   |
+//
+// # Method-Type
+// - Command
+//
+// # Description
+// - The method shall run the command given in the arguments of the program instead of starting the server.
+// - Known commands:
+//   - migrate status  : Lists all migrations and whether they are applied.
+//   - migrate dry-run : Lists the migrations that `migrate up` would apply, without applying them.
+//   - migrate up      : Applies all pending migrations.
+//   - backup create <FILE>   : Writes a backup of the database into <FILE>, compressed if it ends with ".gz".
+//   - backup validate <FILE> : Checks whether <FILE> could be restored.
+//   - backup restore <FILE>  : Overwrites the database with <FILE>. The server must not be running.
+//   - account list                    : Lists all accounts.
+//   - account add <USERNAME>          : Creates an account, the password is read from MQTT_EXPLORER_PASSWORD or asked for.
+//   - account remove <USERNAME>       : Deletes the account with its API keys and roles.
+//   - account key <USERNAME> <NAME>   : Creates an API key for the account and prints it once.
+//   - account grant <USERNAME> <ROLE> : Gives the account a role on all brokers and topics, `<BROKER-ID> [<FILTER>]` after <ROLE> narrow it.
+//   - benchmark run -ip <IP> [<FLAGS>] : Loads a broker with simulated clients, prints and stores the result. Ctrl+C cancels it.
+//   - benchmark list                   : Lists the stored benchmarks with their main results, to compare them.
+//   - benchmark show <ID>              : Prints all of a stored benchmark.
+//   - config print : Prints the effective configuration, main() runs it before the database is opened, see runConfigCommand().
+//
+// # Returns
+// - The exit code of the program.
+//
+// # Author
+// - Polariusz
+func runCommand(con *sql.DB, args []string) int {
+	switch {
+	case len(args) == 2 && args[0] == "migrate":
+		return runMigrateCommand(con, args[1])
+	case len(args) == 3 && args[0] == "backup":
+		return runBackupCommand(con, args[1], args[2])
+	case len(args) >= 2 && args[0] == "account":
+		return runAccountCommand(con, args[1], args[2:])
+	case len(args) >= 2 && args[0] == "benchmark":
+		return runBenchmarkCommand(con, args[1], args[2:])
+	}
+
+	fmt.Printf("Unknown command: %v\n", args)
+	fmt.Printf("Usage:\n")
+	fmt.Printf("  main                                 : Starts the server\n")
+	fmt.Printf("  main migrate status                  : Lists all migrations and whether they are applied\n")
+	fmt.Printf("  main migrate dry-run                 : Lists the migrations that would be applied\n")
+	fmt.Printf("  main migrate up                      : Applies all pending migrations\n")
+	fmt.Printf("  main backup create <FILE>            : Writes a backup into <FILE>, compressed if it ends with .gz\n")
+	fmt.Printf("  main backup validate <FILE>          : Checks whether <FILE> could be restored\n")
+	fmt.Printf("  main backup restore <FILE>           : Overwrites the database with <FILE>, stop the server first\n")
+	fmt.Printf("  main account list                    : Lists all accounts\n")
+	fmt.Printf("  main account add <USERNAME>          : Creates an account, the password comes from MQTT_EXPLORER_PASSWORD or the prompt\n")
+	fmt.Printf("  main account remove <USERNAME>       : Deletes the account with its API keys and roles\n")
+	fmt.Printf("  main account key <USERNAME> <NAME>   : Creates an API key for the account\n")
+	fmt.Printf("  main account grant <USERNAME> <ROLE> : Gives viewer, operator or admin on everything, add <BROKER-ID> [<FILTER>] to narrow it\n")
+	fmt.Printf("  main benchmark run -ip <IP> [...]    : Loads a broker with simulated clients and stores the result, -h lists the flags\n")
+	fmt.Printf("  main benchmark list                  : Lists the stored benchmarks to compare them\n")
+	fmt.Printf("  main benchmark show <ID>             : Prints all of a stored benchmark\n")
+	fmt.Printf("  main config print                    : Prints the effective configuration and where every setting comes from\n")
+	fmt.Printf("Flags, given before the command, they win over the environment and the configuration file:\n")
+	flag.PrintDefaults()
+	return 2
+}
+
+// # Author
+// - Polariusz
+func runMigrateCommand(con *sql.DB, action string) int {
+	switch action {
+	case "status":
+		stateList, err := database.MigrationStatus(con)
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			return 1
+		}
+		for _, state := range stateList {
+			switch {
+			case state.Baselined:
+				fmt.Printf("%4d %-30s baselined\n", state.Version, state.Name)
+			case state.Applied:
+				fmt.Printf("%4d %-30s applied %s\n", state.Version, state.Name, state.AppliedDate.Format("2006-01-02 15:04:05"))
+			default:
+				fmt.Printf("%4d %-30s pending\n", state.Version, state.Name)
+			}
+		}
+		return 0
+	case "dry-run", "up":
+		dryRun := action == "dry-run"
+		stateList, err := database.Migrate(con, dryRun)
+		for _, state := range stateList {
+			if dryRun {
+				fmt.Printf("would apply %4d %s\n", state.Version, state.Name)
+			} else {
+				fmt.Printf("applied %4d %s\n", state.Version, state.Name)
+			}
+		}
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			return 1
+		}
+		if len(stateList) == 0 {
+			fmt.Printf("The database is up to date at version %d\n", database.LatestSchemaVersion())
+		}
+		return 0
+	}
+
+	fmt.Printf("Unknown migrate action '%s', expected status, dry-run or up\n", action)
+	return 2
+}
+
+// # Author
+// - Polariusz
+func runBackupCommand(con *sql.DB, action string, path string) int {
+	switch action {
+	case "create":
+		size, err := database.BackupDatabase(con, path)
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			return 1
+		}
+		fmt.Printf("Wrote %s (%d bytes)\n", path, size)
+		return 0
+	case "validate", "restore":
+		var info database.BackupInfo
+		var err error
+		if action == "validate" {
+			info, err = database.ValidateBackup(path)
+		} else {
+			info, err = database.RestoreDatabase(con, path)
+		}
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			return 1
+		}
+		fmt.Printf("%s: schema version %d, %d brokers, %d messages\n", path, info.SchemaVersion, info.Brokers, info.Messages)
+		if action == "restore" {
+			fmt.Printf("Restored, the database is at schema version %d\n", database.LatestSchemaVersion())
+		}
+		return 0
+	}
+
+	fmt.Printf("Unknown backup action '%s', expected create, validate or restore\n", action)
+	return 2
+}
+
+// # Description
+// - The password of `account add` is taken from this environment variable, so it can be scripted without landing in the shell history.
+const PASSWORD_ENV = "MQTT_EXPLORER_PASSWORD"
+
+// # Author
+// - Polariusz
+func runAccountCommand(con *sql.DB, action string, args []string) int {
+	if con == nil {
+		fmt.Printf("ERROR: There is no database\n")
+		return 1
+	}
+	if err := database.SetupDatabase(con); err != nil {
+		fmt.Printf("ERROR: %s\n", err)
+		return 1
+	}
+	accounts, err := NewAccountManager(con)
+	if err != nil {
+		fmt.Printf("ERROR: %s\n", err)
+		return 1
+	}
+
+	switch {
+	case action == "list" && len(args) == 0:
+		accountList, err := database.SelectAccounts(con)
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			return 1
+		}
+		assignmentList, err := database.SelectRoleAssignments(con, 0)
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			return 1
+		}
+		for _, account := range accountList {
+			fmt.Printf("%4d %-30s created %s\n", account.Id, account.Username, account.CreationDate.Format("2006-01-02 15:04:05"))
+			for _, assignment := range assignmentList {
+				if assignment.AccountId == account.Id {
+					fmt.Printf("       %-8s on broker %d for %s\n", assignment.Role, assignment.BrokerId, assignment.TopicFilter)
+				}
+			}
+		}
+		if len(accountList) == 0 {
+			fmt.Printf("There are no accounts, the API is open to anyone who can reach the server\n")
+		}
+		return 0
+	case action == "add" && len(args) == 1:
+		password, ok := os.LookupEnv(PASSWORD_ENV)
+		if !ok {
+			fmt.Printf("Password for %s: ", args[0])
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				fmt.Printf("\nERROR: Could not read the password\nErr: %s\n", err)
+				return 1
+			}
+			password = strings.TrimRight(line, "\r\n")
+		}
+		accountId, err := accounts.createAccount(args[0], password)
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			return 1
+		}
+		fmt.Printf("Created the account %s with the ID %d\n", args[0], accountId)
+		return 0
+	case (action == "remove" && len(args) == 1) || (action == "key" && len(args) == 2) || (action == "grant" && len(args) >= 2 && len(args) <= 4):
+		account, exists, err := database.SelectAccountByUsername(con, args[0])
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			return 1
+		}
+		if !exists {
+			fmt.Printf("ERROR: There is no account %s\n", args[0])
+			return 1
+		}
+		if action == "remove" {
+			if _, err := accounts.deleteAccount(account.Id); err != nil {
+				fmt.Printf("ERROR: %s\n", err)
+				return 1
+			}
+			fmt.Printf("Removed the account %s\n", args[0])
+			return 0
+		}
+		if action == "grant" {
+			assignment := database.InsertRoleAssignment{AccountId: account.Id, Role: args[1], TopicFilter: "#"}
+			if len(args) >= 3 {
+				if assignment.BrokerId, err = strconv.Atoi(args[2]); err != nil {
+					fmt.Printf("ERROR: <BROKER-ID> must be a number, 0 for all brokers\n")
+					return 2
+				}
+			}
+			if len(args) == 4 {
+				assignment.TopicFilter = args[3]
+			}
+			if _, err := grantRole(con, assignment); err != nil {
+				fmt.Printf("ERROR: %s\n", err)
+				return 1
+			}
+			fmt.Printf("Gave %s the role %s on broker %d for %s\n", args[0], assignment.Role, assignment.BrokerId, assignment.TopicFilter)
+			return 0
+		}
+		token, apiKey, err := accounts.createApiKey(account.Id, args[1])
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			return 1
+		}
+		fmt.Printf("Created the API key %s (%s...) for %s, it is shown only once:\n%s\n", apiKey.Name, apiKey.Prefix, args[0], token)
+		return 0
+	}
+
+	fmt.Printf("Unknown account action '%s %s', expected list, add <USERNAME>, remove <USERNAME>, key <USERNAME> <NAME> or grant <USERNAME> <ROLE> [<BROKER-ID> [<FILTER>]]\n", action, strings.Join(args, " "))
+	return 2
+}
+
+// # Description
+// - The broker password of `benchmark run` is taken from this environment variable, for the same reason as PASSWORD_ENV.
+const BROKER_PASSWORD_ENV = "MQTT_EXPLORER_BROKER_PASSWORD"
+
+// # Author
+// - Polariusz
+func runBenchmarkCommand(con *sql.DB, action string, args []string) int {
+	if con == nil {
+		fmt.Printf("ERROR: There is no database\n")
+		return 1
+	}
+	if err := database.SetupDatabase(con); err != nil {
+		fmt.Printf("ERROR: %s\n", err)
+		return 1
+	}
+
+	switch {
+	case action == "run":
+		var benchmarkWrapper BenchmarkWrapper
+		flags := flag.NewFlagSet("benchmark run", flag.ContinueOnError)
+		flags.StringVar(&benchmarkWrapper.Broker.Ip, "ip", "", "IP of the broker to load")
+		flags.StringVar(&benchmarkWrapper.Broker.Port, "port", "1883", "Port of the broker")
+		flags.StringVar(&benchmarkWrapper.Broker.ClientId, "clientid", "bench", "Prefix of the ClientIds of the simulated clients")
+		flags.StringVar(&benchmarkWrapper.Broker.Username, "username", "", "Username for the broker, the password comes from "+BROKER_PASSWORD_ENV)
+		flags.StringVar(&benchmarkWrapper.Name, "name", "", "Name of the benchmark, to tell it apart in the list")
+		flags.IntVar(&benchmarkWrapper.Publishers, "publishers", 1, "How many clients publish")
+		flags.IntVar(&benchmarkWrapper.Subscribers, "subscribers", 1, "How many clients subscribe and measure")
+		flags.Float64Var(&benchmarkWrapper.RatePerSecond, "rate", 10, "Messages per second of every publisher")
+		flags.IntVar(&benchmarkWrapper.PayloadBytes, "size", 64, "Size of a payload in bytes")
+		flags.IntVar(&benchmarkWrapper.QoS, "qos", 0, "QoS of the publishes and subscriptions")
+		flags.StringVar(&benchmarkWrapper.TopicPattern, "topic", "bench/"+BENCHMARK_CLIENT_PLACEHOLDER, BENCHMARK_CLIENT_PLACEHOLDER+" is the number of the publisher")
+		flags.IntVar(&benchmarkWrapper.DurationSeconds, "duration", 10, "Seconds that the publishers publish")
+		if err := flags.Parse(args); err != nil {
+			return 2
+		}
+		benchmarkWrapper.Broker.Password = os.Getenv(BROKER_PASSWORD_ENV)
+		if err := validateBenchmark(&benchmarkWrapper); err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			return 2
+		}
+
+		benchmarkId, err := database.InsertNewBenchmark(con, benchmarkRow(benchmarkWrapper))
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			return 1
+		}
+		fmt.Printf("Benchmark %d: %s against %s:%s for %d s, Ctrl+C cancels it\n", benchmarkId, benchmarkWrapper.Name, benchmarkWrapper.Broker.Ip, benchmarkWrapper.Broker.Port, benchmarkWrapper.DurationSeconds)
+
+		run := newBenchmarkRun(benchmarkWrapper)
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(signals)
+		go func() {
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-signals:
+					fmt.Printf("Cancelling...\n")
+					run.cancel()
+				case <-ticker.C:
+					fmt.Printf("  sent %d, received %d\n", run.sent.Load(), run.received.Load())
+				case <-run.done:
+					return
+				}
+			}
+		}()
+		result := run.execute()
+		close(run.done)
+
+		if err := database.UpdateBenchmarkResult(con, benchmarkId, result); err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			return 1
+		}
+		benchmark, _, err := database.SelectBenchmarkById(con, benchmarkId)
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			return 1
+		}
+		printBenchmark(benchmark)
+		if result.Status == database.BENCHMARK_FAILED {
+			return 1
+		}
+		return 0
+	case action == "list" && len(args) == 0:
+		benchmarkList, err := database.SelectBenchmarks(con)
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			return 1
+		}
+		fmt.Printf("%4s %-9s %-30s %9s %9s %7s %10s %9s %9s\n", "ID", "STATUS", "NAME", "SENT", "RECEIVED", "LOSS%", "MSG/S", "P50 MS", "P99 MS")
+		for _, benchmark := range benchmarkList {
+			result := benchmark.Result
+			fmt.Printf("%4d %-9s %-30s %9d %9d %7.2f %10.1f %9.2f %9.2f\n", benchmark.Id, result.Status, benchmark.Name, result.Sent, result.Received, result.LossPercent, result.ThroughputPerSecond, result.LatencyP50Ms, result.LatencyP99Ms)
+		}
+		return 0
+	case action == "show" && len(args) == 1:
+		benchmarkId, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Printf("ERROR: <ID> must be a number\n")
+			return 2
+		}
+		benchmark, exists, err := database.SelectBenchmarkById(con, benchmarkId)
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err)
+			return 1
+		}
+		if !exists {
+			fmt.Printf("ERROR: There is no benchmark %d\n", benchmarkId)
+			return 1
+		}
+		printBenchmark(benchmark)
+		return 0
+	}
+
+	fmt.Printf("Unknown benchmark action '%s %s', expected run -ip <IP> [<FLAGS>], list or show <ID>\n", action, strings.Join(args, " "))
+	return 2
+}
+
+// # Author
+// - Polariusz
+func printBenchmark(benchmark database.SelectBenchmark) {
+	result := benchmark.Result
+	fmt.Printf("Benchmark %d: %s (%s)\n", benchmark.Id, benchmark.Name, result.Status)
+	if result.Error != "" {
+		fmt.Printf("  error       : %s\n", result.Error)
+	}
+	fmt.Printf("  broker      : %s:%d\n", benchmark.BrokerIp, benchmark.BrokerPort)
+	fmt.Printf("  load        : %d publishers x %g msg/s, %d subscribers, %d B, QoS %d, %s, %d s\n", benchmark.Publishers, benchmark.RatePerSecond, benchmark.Subscribers, benchmark.PayloadBytes, benchmark.QoS, benchmark.TopicPattern, benchmark.DurationSeconds)
+	fmt.Printf("  messages    : %d sent, %d received, %d lost (%.2f %%)\n", result.Sent, result.Received, result.Lost, result.LossPercent)
+	fmt.Printf("  throughput  : %.1f msg/s received\n", result.ThroughputPerSecond)
+	fmt.Printf("  latency (ms): min %.2f, mean %.2f, p50 %.2f, p90 %.2f, p95 %.2f, p99 %.2f, max %.2f\n", result.LatencyMinMs, result.LatencyMeanMs, result.LatencyP50Ms, result.LatencyP90Ms, result.LatencyP95Ms, result.LatencyP99Ms, result.LatencyMaxMs)
+}
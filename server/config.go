@@ -0,0 +1,606 @@
+package main
+
+import (
+	"database"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// The environment variable that names the configuration file, if -config does not.
+const CONFIG_FILE_ENV = "MQTT_EXPLORER_CONFIG"
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - What readConfigFile() understands of TOML and YAML, there is no full parser of either without more dependencies.
+// - `config print` starts with it, the README says the same.
+//
+// # Author
+// - Polariusz
+var configFileSubset = []string{
+	"The file is read by the server itself, which only understands this part of TOML and YAML:",
+	"  TOML: key = value, and [section] with key = value below it.",
+	"  YAML: key: value, and section: with indented key: value below it.",
+	"  Values: strings with or without quotes, numbers, true and false, and lists on one line like [\"a\", \"b\"].",
+	"  A # outside of quotes starts a comment. Indent with spaces, not tabs.",
+	"  Not understood: nested sections, inline tables, multi-line strings and lists, YAML lists with -, anchors.",
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - Everything about the server that can be configured, see configSettings for the flags, environment variables and keys of the file.
+// - A setting is taken from, the later winning: its default, the configuration file, the environment, the flags.
+//
+// # Used in
+// - main()
+// - mqttClientOptions(), through serverConfig
+//
+// # Author
+// - Polariusz
+type ServerConfig struct {
+	Listen string
+	StaticDir string
+	Database DatabaseConfig
+	CorsOrigins string
+	KeepAlive time.Duration
+	PingTimeout time.Duration
+}
+
+// # Description
+// - The function shall return the configuration that the server had before it could be configured.
+//
+// # Author
+// - Polariusz
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		Listen: ":3000",
+		StaticDir: "dist",
+		Database: DatabaseConfig{Path: database.DefaultDatabasePath, ProjectDir: "projects", BackupDir: "backups"},
+		CorsOrigins: "*",
+		KeepAlive: 2 * time.Second,
+		PingTimeout: 1 * time.Second,
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The effective configuration, main() sets it once before anything connects.
+// - It is a package variable as the MQTT-Clients are created in places that do not see the ServerState, like the bridges.
+//
+// # Author
+// - Polariusz
+var serverConfig = DefaultServerConfig()
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - One setting of ServerConfig with its key in the configuration file, its flag and its environment variable.
+//
+// # Author
+// - Polariusz
+type configSetting struct {
+	Key string
+	Flag string
+	Env string
+	Description string
+	IsBool bool
+	get func(config *ServerConfig) string
+	set func(config *ServerConfig, value string) error
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - All settings, in the order of `config print`. A key with a dot is in a section of the file, `database.path` is `path` in `[database]`.
+//
+// # Author
+// - Polariusz
+var configSettings = []configSetting{
+	{
+		Key: "listen", Flag: "listen", Env: "MQTT_EXPLORER_LISTEN",
+		Description: "Address that the HTTP server listens on, [host]:port",
+		get: func(config *ServerConfig) string { return config.Listen },
+		set: func(config *ServerConfig, value string) error { config.Listen = value; return nil },
+	},
+	{
+		Key: "static_dir", Flag: "static", Env: "MQTT_EXPLORER_STATIC_DIR",
+		Description: "Directory of the built client that is served on /",
+		get: func(config *ServerConfig) string { return config.StaticDir },
+		set: func(config *ServerConfig, value string) error { config.StaticDir = value; return nil },
+	},
+	{
+		Key: "cors", Flag: "cors", Env: "MQTT_EXPLORER_CORS",
+		Description: "Comma separated origins that may call the API from a browser, * for all",
+		get: func(config *ServerConfig) string { return config.CorsOrigins },
+		set: func(config *ServerConfig, value string) error { config.CorsOrigins = value; return nil },
+	},
+	{
+		Key: "database.path", Flag: "db", Env: "MQTT_EXPLORER_DB",
+		Description: "Path to the database file of the default project",
+		get: func(config *ServerConfig) string { return config.Database.Path },
+		set: func(config *ServerConfig, value string) error { config.Database.Path = value; return nil },
+	},
+	{
+		Key: "database.memory", Flag: "memory", Env: "MQTT_EXPLORER_MEMORY", IsBool: true,
+		Description: "Keep all databases in memory only, nothing is written to disk",
+		get: func(config *ServerConfig) string { return strconv.FormatBool(config.Database.InMemory) },
+		set: func(config *ServerConfig, value string) error { return setConfigBool(&config.Database.InMemory, value) },
+	},
+	{
+		Key: "database.projects", Flag: "projects", Env: "MQTT_EXPLORER_PROJECTS",
+		Description: "Directory for the databases of the other projects",
+		get: func(config *ServerConfig) string { return config.Database.ProjectDir },
+		set: func(config *ServerConfig, value string) error { config.Database.ProjectDir = value; return nil },
+	},
+	{
+		Key: "database.backups", Flag: "backups", Env: "MQTT_EXPLORER_BACKUPS",
+		Description: "Directory for the backups made through the API",
+		get: func(config *ServerConfig) string { return config.Database.BackupDir },
+		set: func(config *ServerConfig, value string) error { config.Database.BackupDir = value; return nil },
+	},
+	{
+		Key: "mqtt.keepalive", Flag: "mqtt-keepalive", Env: "MQTT_EXPLORER_MQTT_KEEPALIVE",
+		Description: "Keepalive of the MQTT-Clients, like 2s, a number is seconds",
+		get: func(config *ServerConfig) string { return config.KeepAlive.String() },
+		set: func(config *ServerConfig, value string) error { return setConfigDuration(&config.KeepAlive, value) },
+	},
+	{
+		Key: "mqtt.ping_timeout", Flag: "mqtt-ping-timeout", Env: "MQTT_EXPLORER_MQTT_PING_TIMEOUT",
+		Description: "How long the MQTT-Clients wait for the answer to a ping, like 1s, a number is seconds",
+		get: func(config *ServerConfig) string { return config.PingTimeout.String() },
+		set: func(config *ServerConfig, value string) error { return setConfigDuration(&config.PingTimeout, value) },
+	},
+}
+
+// # Author
+// - Polariusz
+func setConfigBool(target *bool, value string) error {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("%q is not true or false", value)
+	}
+	*target = parsed
+	return nil
+}
+
+// # Description
+// - The function shall accept a duration of Go, like `1500ms`, or a number of seconds.
+//
+// # Author
+// - Polariusz
+func setConfigDuration(target *time.Duration, value string) error {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		*target = time.Duration(seconds * float64(time.Second))
+		return nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%q is not a duration like 2s", value)
+	}
+	*target = parsed
+	return nil
+}
+
+// # Description
+// - The flag.Value of a setting, it keeps the text until the settings are applied in their order.
+//
+// # Author
+// - Polariusz
+type configFlagValue struct {
+	value string
+	isBool bool
+}
+
+func (cfv *configFlagValue) String() string { return cfv.value }
+func (cfv *configFlagValue) Set(value string) error { cfv.value = value; return nil }
+func (cfv *configFlagValue) IsBoolFlag() bool { return cfv.isBool }
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - Where every setting of the effective configuration comes from, by its key: `default`, `file <PATH>`, `env <NAME>` or `flag -<NAME>`.
+//
+// # Author
+// - Polariusz
+type ConfigSources map[string]string
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall register the flags of all settings and -config on `flags`, parse `args` and return the effective configuration.
+// - The settings are applied in this order, a later one wins:
+//   1. The defaults of DefaultServerConfig().
+//   2. The configuration file of -config or CONFIG_FILE_ENV, `.toml`, `.yaml` or `.yml`.
+//   3. The environment variables.
+//   4. The flags.
+// - The arguments after the flags stay in `flags.Args()`, they are the command.
+//
+// # Returns
+// - ConfigSources of every setting
+// - error when a value can not be read or the configuration is not valid, with all problems at once
+//
+// # Author
+// - Polariusz
+func loadConfig(flags *flag.FlagSet, args []string) (ServerConfig, ConfigSources, error) {
+	config := DefaultServerConfig()
+	sources := make(ConfigSources)
+
+	flagValues := make(map[string]*configFlagValue)
+	for _, setting := range configSettings {
+		value := &configFlagValue{isBool: setting.IsBool}
+		flagValues[setting.Flag] = value
+		flags.Var(value, setting.Flag, fmt.Sprintf("%s (default %q, env %s)", setting.Description, setting.get(&config), setting.Env))
+		sources[setting.Key] = "default"
+	}
+	configPath := flags.String("config", "", "Configuration file, .toml, .yaml or .yml (env "+CONFIG_FILE_ENV+")")
+	if err := flags.Parse(args); err != nil {
+		return config, sources, err
+	}
+
+	var problems []error
+	apply := func(setting configSetting, value string, source string) {
+		if err := setting.set(&config, value); err != nil {
+			problems = append(problems, fmt.Errorf("%s from %s: %s", setting.Key, source, err))
+			return
+		}
+		sources[setting.Key] = source
+	}
+
+	if *configPath == "" {
+		*configPath = os.Getenv(CONFIG_FILE_ENV)
+	}
+	if *configPath != "" {
+		values, err := readConfigFile(*configPath)
+		if err != nil {
+			return config, sources, err
+		}
+		for _, setting := range configSettings {
+			if value, ok := values[setting.Key]; ok {
+				apply(setting, value, "file "+*configPath)
+				delete(values, setting.Key)
+			}
+		}
+		for key := range values {
+			problems = append(problems, fmt.Errorf("%s: unknown key %q", *configPath, key))
+		}
+	}
+
+	for _, setting := range configSettings {
+		if value, ok := os.LookupEnv(setting.Env); ok {
+			apply(setting, value, "env "+setting.Env)
+		}
+	}
+
+	flags.Visit(func(visited *flag.Flag) {
+		for _, setting := range configSettings {
+			if setting.Flag == visited.Name {
+				apply(setting, flagValues[setting.Flag].value, "flag -"+setting.Flag)
+			}
+		}
+	})
+
+	if len(problems) == 0 {
+		problems = validateConfig(config)
+	}
+	return config, sources, errors.Join(problems...)
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall check the effective configuration.
+//
+// # Returns
+// - All problems, nil if there are none
+//
+// # Author
+// - Polariusz
+func validateConfig(config ServerConfig) []error {
+	var problems []error
+
+	if _, port, err := net.SplitHostPort(config.Listen); err != nil {
+		problems = append(problems, fmt.Errorf("listen must be [host]:port, like :3000"))
+	} else if number, err := strconv.Atoi(port); err != nil || number < 1 || number > 65535 {
+		problems = append(problems, fmt.Errorf("listen must have a port between 1 and 65535"))
+	}
+	if config.StaticDir == "" {
+		problems = append(problems, fmt.Errorf("static_dir must not be empty"))
+	}
+	if config.CorsOrigins != "*" {
+		for _, origin := range strings.Split(config.CorsOrigins, ",") {
+			origin = strings.TrimSpace(origin)
+			if !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+				problems = append(problems, fmt.Errorf("cors must be * or origins like https://example.com, not %q", origin))
+			}
+		}
+	}
+	if config.Database.Path == "" && !config.Database.InMemory {
+		problems = append(problems, fmt.Errorf("database.path must not be empty"))
+	}
+	if config.Database.ProjectDir == "" || config.Database.BackupDir == "" {
+		problems = append(problems, fmt.Errorf("database.projects and database.backups must not be empty"))
+	}
+	if config.KeepAlive < time.Second {
+		problems = append(problems, fmt.Errorf("mqtt.keepalive must be at least 1s, MQTT counts it in seconds"))
+	}
+	if config.PingTimeout <= 0 || config.PingTimeout > config.KeepAlive {
+		problems = append(problems, fmt.Errorf("mqtt.ping_timeout must be above 0 and at most mqtt.keepalive"))
+	}
+
+	return problems
+}
+
+// | Date of change | By        | Comment          |
+// +----------------+-----------+------------------+
+// | 2026-10-19     | Polariusz | Created          |
+// | 2026-10-19     | Polariusz | Refuses the rest |
+//
+// # Description
+// - The function shall read the values of a configuration file by their keys, `[database]` with `path = "x.db"` is `database.path`.
+// - Only what a configuration of the server needs is understood, not all of TOML or YAML, see configFileSubset:
+//   - TOML: `key = value` and `[section]`.
+//   - YAML: `key: value`, and `section:` with indented `key: value` below it.
+//   - Values are strings, with or without quotes, numbers, true and false, and lists like `["a", "b"]` that become `a,b`.
+//   - `#` starts a comment.
+// - What is not understood is refused with an error, it is never read as something else.
+//
+// # Returns
+// - error when the file can not be read, or a line is not understood, as `<path>:<line>: <message>`
+//
+// # Author
+// - Polariusz
+func readConfigFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		var pathError *fs.PathError
+		if errors.As(err, &pathError) {
+			err = pathError.Err
+		}
+		return nil, fmt.Errorf("%s: can not be read, %s", path, err)
+	}
+
+	var yaml bool
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+	case ".yaml", ".yml":
+		yaml = true
+	default:
+		return nil, fmt.Errorf("%s: a configuration file must end with .toml, .yaml or .yml", path)
+	}
+
+	values := make(map[string]string)
+	section := ""
+	for number, line := range strings.Split(string(content), "\n") {
+		fail := func(message string) error {
+			return fmt.Errorf("%s:%d: %s", path, number+1, message)
+		}
+
+		line = stripConfigComment(strings.TrimRight(line, "\r"))
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || (yaml && trimmed == "---") {
+			continue
+		}
+		if strings.Contains(line, "\t") {
+			return nil, fail("tabs are not allowed, indent with spaces")
+		}
+
+		var key, rawValue string
+		if yaml {
+			if trimmed == "-" || strings.HasPrefix(trimmed, "- ") {
+				return nil, fail("lists with - are not supported, write them on one line like [a, b]")
+			}
+			indented := strings.HasPrefix(line, " ")
+			name, value, ok := strings.Cut(trimmed, ":")
+			if !ok {
+				return nil, fail("expected key: value")
+			}
+			key, rawValue = strings.TrimSpace(name), strings.TrimSpace(value)
+			switch {
+			case !indented && rawValue == "":
+				section = key
+				continue
+			case !indented:
+				section = ""
+			case section == "":
+				return nil, fail("an indented key needs a section above it")
+			}
+		} else {
+			if strings.HasPrefix(trimmed, "[") {
+				if !strings.HasSuffix(trimmed, "]") {
+					return nil, fail("expected [section]")
+				}
+				section = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+				if strings.HasPrefix(section, "[") || strings.Contains(section, ".") {
+					return nil, fail("nested sections and arrays of tables are not supported")
+				}
+				continue
+			}
+			name, value, ok := strings.Cut(trimmed, "=")
+			if !ok {
+				return nil, fail("expected key = value")
+			}
+			key, rawValue = strings.TrimSpace(name), strings.TrimSpace(value)
+		}
+
+		if key == "" {
+			return nil, fail("the key is missing")
+		}
+		if section != "" {
+			key = section + "." + key
+		}
+		if _, seen := values[key]; seen {
+			return nil, fail(fmt.Sprintf("%s is set twice", key))
+		}
+		value, err := parseConfigValue(rawValue)
+		if err != nil {
+			return nil, fail(err.Error())
+		}
+		values[key] = value
+	}
+
+	return values, nil
+}
+
+// # Description
+// - The function shall cut off a comment that starts with `#` outside of quotes.
+//
+// # Author
+// - Polariusz
+func stripConfigComment(line string) string {
+	var quote rune
+	for index, char := range line {
+		switch {
+		case quote != 0 && char == quote:
+			quote = 0
+		case quote == 0 && (char == '"' || char == '\''):
+			quote = char
+		case quote == 0 && char == '#':
+			return line[:index]
+		}
+	}
+	return line
+}
+
+// # Description
+// - The function shall return the text of a value of a configuration file, a list becomes its items separated by commas.
+// - The values that configFileSubset does not have are refused, so they are not read as a plain string.
+//
+// # Author
+// - Polariusz
+func parseConfigValue(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, "{"):
+		return "", fmt.Errorf("inline tables are not supported, use a section")
+	case strings.HasPrefix(raw, `"""`) || strings.HasPrefix(raw, "'''"):
+		return "", fmt.Errorf("multi-line strings are not supported")
+	case strings.HasPrefix(raw, "|") || strings.HasPrefix(raw, ">"):
+		return "", fmt.Errorf("multi-line strings are not supported")
+	case len(raw) > 1 && (raw[0] == '&' || raw[0] == '*') && unicode.IsLetter(rune(raw[1])):
+		// A lone * stays a value, like the cors origin.
+		return "", fmt.Errorf("anchors and aliases are not supported")
+	}
+
+	if strings.HasPrefix(raw, "[") {
+		if !strings.HasSuffix(raw, "]") {
+			return "", fmt.Errorf("a list must end with ]")
+		}
+		var items []string
+		for _, item := range strings.Split(raw[1:len(raw)-1], ",") {
+			if strings.TrimSpace(item) == "" {
+				continue
+			}
+			value, err := parseConfigValue(strings.TrimSpace(item))
+			if err != nil {
+				return "", err
+			}
+			items = append(items, value)
+		}
+		return strings.Join(items, ","), nil
+	}
+
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		value, err := strconv.Unquote(raw)
+		if err != nil {
+			return "", fmt.Errorf("%s is not a string with double quotes", raw)
+		}
+		return value, nil
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return "", fmt.Errorf("%s is not a string with single quotes", raw)
+		}
+		return raw[1 : len(raw)-1], nil
+	}
+	return raw, nil
+}
+
+// | Date of change | By        | Comment           |
+// +----------------+-----------+-------------------+
+// | 2026-10-19     | Polariusz | Created           |
+// | 2026-10-19     | Polariusz | Prints the subset |
+//
+// # Method-Type
+// - Command
+//
+// # Description
+// - config print : Prints the effective configuration as TOML, every setting with where it comes from. It can be saved as a configuration file.
+//   - It starts with what the server understands of a configuration file, see configFileSubset.
+//
+// # Returns
+// - The exit code of the program.
+//
+// # Author
+// - Polariusz
+func runConfigCommand(config ServerConfig, sources ConfigSources, args []string) int {
+	if len(args) != 1 || args[0] != "print" {
+		fmt.Printf("Unknown config action '%s', expected print\n", strings.Join(args, " "))
+		return 2
+	}
+
+	fmt.Printf("# The effective configuration of the server, every setting with where it comes from.\n")
+	fmt.Printf("#\n")
+	for _, line := range configFileSubset {
+		fmt.Printf("# %s\n", line)
+	}
+	fmt.Printf("\n")
+	section := ""
+	for _, setting := range configSettings {
+		name := setting.Key
+		if dot := strings.Index(setting.Key, "."); dot >= 0 {
+			if setting.Key[:dot] != section {
+				section = setting.Key[:dot]
+				fmt.Printf("\n[%s]\n", section)
+			}
+			name = setting.Key[dot+1:]
+		}
+		value := strconv.Quote(setting.get(&config))
+		if setting.IsBool {
+			value = setting.get(&config)
+		}
+		fmt.Printf("%-30s # %s\n", fmt.Sprintf("%s = %s", name, value), sources[setting.Key])
+	}
+	return 0
+}
+
+// # Description
+// - The function shall return the address of `listen` that a browser on this machine can open, `:3000` is `localhost:3000`.
+//
+// # Author
+// - Polariusz
+func browserAddress(listen string) string {
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return listen
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, port)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+	return path
+}
+
+func TestReadConfigFile(t *testing.T) {
+	toml := writeConfigFile(t, "server.toml", `
+# The server
+listen = "127.0.0.1:8080"   # only local
+cors = ["https://a.example", 'https://b.example']
+
+[database]
+path = "data/explorer.db"
+memory = false
+
+[mqtt]
+keepalive = 5
+`)
+	yaml := writeConfigFile(t, "server.yaml", `---
+listen: "127.0.0.1:8080"  # only local
+cors: [https://a.example, "https://b.example"]
+database:
+  path: data/explorer.db
+  memory: false
+mqtt:
+  keepalive: 5
+`)
+	want := map[string]string{
+		"listen": "127.0.0.1:8080",
+		"cors": "https://a.example,https://b.example",
+		"database.path": "data/explorer.db",
+		"database.memory": "false",
+		"mqtt.keepalive": "5",
+	}
+	for _, path := range []string{toml, yaml} {
+		values, err := readConfigFile(path)
+		if err != nil {
+			t.Fatalf("readConfigFile(%s): %s", filepath.Base(path), err)
+		}
+		if len(values) != len(want) {
+			t.Errorf("%s: %v", filepath.Base(path), values)
+		}
+		for key, value := range want {
+			if values[key] != value {
+				t.Errorf("%s: %s = %q, want %q", filepath.Base(path), key, values[key], value)
+			}
+		}
+	}
+
+	for name, content := range map[string]string{
+		"bad.toml": "listen\n",
+		"twice.toml": "listen = \":1\"\nlisten = \":2\"\n",
+		"orphan.yaml": "  path: x.db\n",
+		"tabs.yaml": "database:\n\tpath: x.db\n",
+		"server.json": "{}",
+		"nested.toml": "[database.extra]\npath = \"x.db\"\n",
+		"inline.toml": "database = { path = \"x.db\" }\n",
+		"multiline.toml": "cors = \"\"\"\nx\n\"\"\"\n",
+		"block.yaml": "listen: |\n  :1\n",
+		"dashes.yaml": "cors:\n  - https://a.example\n",
+		"alias.yaml": "listen: *default\n",
+	} {
+		path := writeConfigFile(t, name, content)
+		if _, err := readConfigFile(path); err == nil || !strings.HasPrefix(err.Error(), path+":") {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	missing := filepath.Join(t.TempDir(), "missing.toml")
+	if _, err := readConfigFile(missing); err == nil || !strings.HasPrefix(err.Error(), missing+": can not be read") || strings.Contains(err.Error(), "\n") {
+		t.Errorf("a missing file: %v", err)
+	}
+	if values, err := readConfigFile(writeConfigFile(t, "any.yaml", "cors: *\n")); err != nil || values["cors"] != "*" {
+		t.Errorf("a lone *: %v %s", values, err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := writeConfigFile(t, "server.toml", "listen = \":4000\"\nstatic_dir = \"web\"\n[mqtt]\nkeepalive = \"10s\"\nping_timeout = \"3s\"\n")
+	t.Setenv(CONFIG_FILE_ENV, path)
+	t.Setenv("MQTT_EXPLORER_LISTEN", ":5000")
+	t.Setenv("MQTT_EXPLORER_MQTT_PING_TIMEOUT", "4")
+
+	flags := flag.NewFlagSet("main", flag.ContinueOnError)
+	config, sources, err := loadConfig(flags, []string{"-listen", "127.0.0.1:6000", "-memory", "config", "print"})
+	if err != nil {
+		t.Fatalf("loadConfig: %s", err)
+	}
+	if config.Listen != "127.0.0.1:6000" || sources["listen"] != "flag -listen" {
+		t.Errorf("the flag does not win: %s from %s", config.Listen, sources["listen"])
+	}
+	if config.PingTimeout != 4*time.Second || sources["mqtt.ping_timeout"] != "env MQTT_EXPLORER_MQTT_PING_TIMEOUT" {
+		t.Errorf("the environment does not win over the file: %s from %s", config.PingTimeout, sources["mqtt.ping_timeout"])
+	}
+	if config.StaticDir != "web" || config.KeepAlive != 10*time.Second || sources["static_dir"] != "file "+path {
+		t.Errorf("the file is not used: %+v", config)
+	}
+	if !config.Database.InMemory || config.CorsOrigins != "*" || sources["cors"] != "default" {
+		t.Errorf("the defaults or a bool flag are wrong: %+v", config)
+	}
+	if args := flags.Args(); len(args) != 2 || args[0] != "config" {
+		t.Errorf("the command is lost: %v", args)
+	}
+
+	for name, args := range map[string][]string{
+		"bad listen": {"-listen", "3000"},
+		"bad port": {"-listen", ":70000"},
+		"bad cors": {"-cors", "example.com"},
+		"bad duration": {"-mqtt-keepalive", "soon"},
+		"short keepalive": {"-mqtt-keepalive", "500ms", "-mqtt-ping-timeout", "100ms"},
+		"ping above keepalive": {"-mqtt-keepalive", "2s", "-mqtt-ping-timeout", "5s"},
+		"bad bool": {"-memory=maybe"},
+	} {
+		if _, _, err := loadConfig(flag.NewFlagSet("main", flag.ContinueOnError), args); err == nil {
+			t.Errorf("%s was accepted", name)
+		}
+	}
+
+	t.Setenv(CONFIG_FILE_ENV, writeConfigFile(t, "unknown.toml", "port = 3000\n"))
+	if _, _, err := loadConfig(flag.NewFlagSet("main", flag.ContinueOnError), nil); err == nil || !strings.Contains(err.Error(), "unknown key") {
+		t.Errorf("an unknown key was accepted: %v", err)
+	}
+}
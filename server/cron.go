@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// How far ahead the next time of a cron expression is searched, so `0 0 30 2 *` does not search forever.
+const CRON_SEARCH_LIMIT = 5 * 366 * 24 * time.Hour
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A parsed cron expression, every field is the set of the values it allows.
+// - `anyDay` and `anyWeekday` remember whether the fields were `*`, as a day is due when either of them matches if both are restricted.
+//
+// # Used in
+// - compiledSchedule
+//
+// # Author
+// - Polariusz
+type cronExpression struct {
+	minutes [60]bool
+	hours [24]bool
+	days [32]bool
+	months [13]bool
+	weekdays [7]bool
+	anyDay bool
+	anyWeekday bool
+}
+
+// The macros that stand for a whole expression.
+var cronMacros = map[string]string{
+	"@yearly": "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly": "0 0 1 * *",
+	"@weekly": "0 0 * * 0",
+	"@daily": "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly": "0 * * * *",
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Parser
+//
+// # Description
+// - The function shall parse a cron expression of five fields: minute, hour, day of the month, month and day of the week.
+// - A field is `*`, a number, a range `a-b`, a step `*/n` or `a-b/n`, or a list of them separated by commas. Sunday is 0 or 7.
+// - The macros @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are accepted.
+//
+// # Returns
+// - error when the expression is not valid, the message can be shown to the client
+//
+// # Author
+// - Polariusz
+func parseCron(expression string) (*cronExpression, error) {
+	if macro, ok := cronMacros[strings.TrimSpace(expression)]; ok {
+		expression = macro
+	}
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("A cron expression has 5 fields: minute, hour, day of the month, month and day of the week")
+	}
+
+	cron := &cronExpression{anyDay: fields[2] == "*", anyWeekday: fields[4] == "*"}
+	if err := parseCronField(fields[0], 0, 59, cron.minutes[:]); err != nil {
+		return nil, fmt.Errorf("The minute %s", err)
+	}
+	if err := parseCronField(fields[1], 0, 23, cron.hours[:]); err != nil {
+		return nil, fmt.Errorf("The hour %s", err)
+	}
+	if err := parseCronField(fields[2], 1, 31, cron.days[:]); err != nil {
+		return nil, fmt.Errorf("The day of the month %s", err)
+	}
+	if err := parseCronField(fields[3], 1, 12, cron.months[:]); err != nil {
+		return nil, fmt.Errorf("The month %s", err)
+	}
+	var weekdays [8]bool
+	if err := parseCronField(fields[4], 0, 7, weekdays[:]); err != nil {
+		return nil, fmt.Errorf("The day of the week %s", err)
+	}
+	copy(cron.weekdays[:], weekdays[:7])
+	cron.weekdays[0] = cron.weekdays[0] || weekdays[7]
+
+	return cron, nil
+}
+
+// # Description
+// - The function shall set the values of one field of a cron expression in `allowed`.
+//
+// # Author
+// - Polariusz
+func parseCronField(field string, min int, max int, allowed []bool) error {
+	for _, part := range strings.Split(field, ",") {
+		from, to, step := min, max, 1
+
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step < 1 {
+				return fmt.Errorf("has a step that is not a positive number: %q", part)
+			}
+		}
+
+		if rangePart != "*" {
+			first, last, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if from, err = strconv.Atoi(first); err != nil {
+				return fmt.Errorf("is not a number: %q", part)
+			}
+			to = from
+			if isRange {
+				if to, err = strconv.Atoi(last); err != nil {
+					return fmt.Errorf("is not a number: %q", part)
+				}
+			} else if hasStep {
+				to = max
+			}
+		}
+		if from < min || to > max || from > to {
+			return fmt.Errorf("must be between %d and %d: %q", min, max, part)
+		}
+
+		for value := from; value <= to; value += step {
+			allowed[value] = true
+		}
+	}
+
+	return nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall return the first minute after `after` that the expression allows, in the time zone of `after`.
+//
+// # Returns
+// - bool: false if there is none within CRON_SEARCH_LIMIT, like on the 30th of February
+//
+// # Author
+// - Polariusz
+func (cron *cronExpression) next(after time.Time) (time.Time, bool) {
+	moment := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(CRON_SEARCH_LIMIT)
+
+	for moment.Before(limit) {
+		if !cron.months[moment.Month()] {
+			moment = time.Date(moment.Year(), moment.Month()+1, 1, 0, 0, 0, 0, moment.Location())
+			continue
+		}
+		if !cron.dayAllowed(moment) {
+			moment = time.Date(moment.Year(), moment.Month(), moment.Day()+1, 0, 0, 0, 0, moment.Location())
+			continue
+		}
+		if !cron.hours[moment.Hour()] {
+			moment = time.Date(moment.Year(), moment.Month(), moment.Day(), moment.Hour()+1, 0, 0, 0, moment.Location())
+			continue
+		}
+		if !cron.minutes[moment.Minute()] {
+			moment = moment.Add(time.Minute)
+			continue
+		}
+		return moment, true
+	}
+
+	return time.Time{}, false
+}
+
+// # Description
+// - The method shall tell whether the day of `moment` is allowed. If both day fields are restricted, either of them is enough, like in every cron.
+//
+// # Author
+// - Polariusz
+func (cron *cronExpression) dayAllowed(moment time.Time) bool {
+	day := cron.days[moment.Day()]
+	weekday := cron.weekdays[moment.Weekday()]
+
+	switch {
+	case cron.anyDay && cron.anyWeekday:
+		return true
+	case cron.anyDay:
+		return weekday
+	case cron.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
@@ -0,0 +1,472 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A message that the deduplicator has seen within the window of its rule.
+//
+// # Author
+// - Polariusz
+type dedupEntry struct {
+	hash [sha256.Size]byte
+	packetId int
+	receivedAt time.Time
+}
+
+// # Author
+// - Polariusz
+type dedupKey struct {
+	brokerId int
+	topicId int
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The structure decides for every received message whether it is a copy of an earlier message, see database.InsertDedupRule for when it is.
+// - It keeps the payload hash and packet ID of the messages of the last window per topic. Only topics that match a rule are kept.
+// - The rules are read from table DedupRule by reload(), which is called when the rules change or another database is attached.
+//
+// # Used in
+// - ServerState
+//
+// # Author
+// - Polariusz
+type Deduplicator struct {
+	mutex sync.Mutex
+	rules []database.SelectDedupRule
+	recent map[dedupKey][]dedupEntry
+}
+
+// # Author
+// - Polariusz
+func NewDeduplicator() *Deduplicator {
+	return &Deduplicator{
+		recent: make(map[dedupKey][]dedupEntry),
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall read the rules from the database and forget the seen messages.
+//
+// # Returns
+// - error when the rules cannot be selected, the old rules stay in use
+//
+// # Author
+// - Polariusz
+func (dd *Deduplicator) reload(con *sql.DB) error {
+	ruleList, err := database.SelectDedupRules(con)
+	if err != nil {
+		return err
+	}
+
+	dd.mutex.Lock()
+	defer dd.mutex.Unlock()
+
+	dd.rules = ruleList
+	dd.recent = make(map[dedupKey][]dedupEntry)
+	return nil
+}
+
+// # Description
+// - The method shall return the first rule that matches the broker and topic.
+//
+// # Author
+// - Polariusz
+func (dd *Deduplicator) rule(brokerId int, topic string) (database.SelectDedupRule, bool) {
+	for _, rule := range dd.rules {
+		if (rule.BrokerId == 0 || rule.BrokerId == brokerId) && topicMatchesFilter(rule.TopicFilter, topic) {
+			return rule, true
+		}
+	}
+	return database.SelectDedupRule{}, false
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - brokerId int                   : [Broker].[ID]
+// - topic string                   : The topic the message was received on
+// - message database.InsertMessage : The message, its TopicId, Duplicate, PacketId and ReceivedAt are used
+// - payload []byte                 : The raw payload of the message
+//
+// # Description
+// - The method shall check whether the message is a copy of a message received earlier within the window of the matching rule, and remember it.
+//
+// # Returns
+// - true if the message is a duplicate. Without a matching rule, it's always false.
+//
+// # Author
+// - Polariusz
+func (dd *Deduplicator) check(brokerId int, topic string, message database.InsertMessage, payload []byte) bool {
+	dd.mutex.Lock()
+	defer dd.mutex.Unlock()
+
+	rule, ok := dd.rule(brokerId, topic)
+	if !ok {
+		return false
+	}
+
+	key := dedupKey{brokerId, message.TopicId}
+	entry := dedupEntry{sha256.Sum256(payload), message.PacketId, message.ReceivedAt}
+	windowStart := entry.receivedAt.Add(-time.Duration(rule.WindowSeconds) * time.Second)
+
+	duplicate := false
+	kept := dd.recent[key][:0]
+	for _, seen := range dd.recent[key] {
+		if seen.receivedAt.Before(windowStart) {
+			continue
+		}
+		kept = append(kept, seen)
+		if seen.hash != entry.hash {
+			continue
+		}
+		if rule.MatchPayloadOnly || (message.Duplicate && message.PacketId != 0 && seen.packetId == message.PacketId) {
+			duplicate = true
+		}
+	}
+	dd.recent[key] = append(kept, entry)
+
+	return duplicate
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"BrokerId":<B>,"TopicFilter":"<F>","WindowSeconds":<W>,"MatchPayloadOnly":<P>}
+//   - <B> : The ID of the Broker ROW, 0 for all brokers
+//   - <F> : MQTT topic filter, wildcards are allowed
+//   - <W> : How many seconds back an earlier copy is looked for
+//   - <P> : If true, an equal payload is enough. Otherwise the message must also have the DUP flag and the packet ID of the earlier copy.
+//
+// # Used in
+// - PostDedupRuleCreateHandler()
+//
+// # Author
+// - Polariusz
+type DedupRuleWrapper struct {
+	BrokerId int
+	TopicFilter string
+	WindowSeconds int
+	MatchPayloadOnly bool
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Id":<I>}
+//   - <I> : The ID of the DedupRule ROW
+//
+// # Used in
+// - PostDedupRuleDeleteHandler()
+//
+// # Author
+// - Polariusz
+type DedupRuleIdWrapper struct {
+	Id int
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall create a deduplication rule. It is used for every message received afterwards.
+// - Where several rules match a topic, the oldest one is used.
+// - The method shall need the role admin for the broker and the topic filter of the rule.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data must have a json structure that matches the struct DedupRuleWrapper.
+//
+// # Returns
+// - 201 (Created): JSON
+//   - {"Id":<I>}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+//   - {"terribleJson":"<What is wrong>"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while inserting in the DedupRule table","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func PostDedupRuleCreateHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var ruleWrapper DedupRuleWrapper
+		if err := c.BodyParser(&ruleWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+
+		if ruleWrapper.TopicFilter == "" {
+			ruleWrapper.TopicFilter = "#"
+		}
+		if !validTopicFilter(ruleWrapper.TopicFilter) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"terribleJson": "TopicFilter is not a valid MQTT topic filter",
+			})
+		}
+		if ruleWrapper.BrokerId < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"terribleJson": "BrokerId must not be negative",
+			})
+		}
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, ruleWrapper.BrokerId, ruleWrapper.TopicFilter); !ok {
+			return err
+		}
+		if ruleWrapper.WindowSeconds <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"terribleJson": "WindowSeconds must be greater than 0",
+			})
+		}
+
+		ruleId, err := database.InsertNewDedupRule(serverState.con, database.InsertDedupRule{
+			BrokerId: ruleWrapper.BrokerId,
+			TopicFilter: ruleWrapper.TopicFilter,
+			WindowSeconds: ruleWrapper.WindowSeconds,
+			MatchPayloadOnly: ruleWrapper.MatchPayloadOnly,
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while inserting in the DedupRule table",
+				"Error": err.Error(),
+			})
+		}
+		if err := serverState.deduplicator.reload(serverState.con); err != nil {
+			fmt.Printf("WARN: Deduplication rules were not reloaded!\nErr: %s\n", err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"Id": ruleId,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return all deduplication rules.
+// - The method shall leave out the rules whose broker and TopicFilter the account may not view, a rule of all brokers needs the role viewer for all of them.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the GET-Method.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"rules":[<database.SelectDedupRule>]}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while selecting deduplication rules","Error":"<err>"}
+//   - {"InternalServerError":"Error while selecting the roles","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func GetDedupRulesHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		permissions, err := permissionsOf(c, serverState)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while selecting the roles",
+				"Error": err.Error(),
+			})
+		}
+
+		ruleList, err := database.SelectDedupRules(serverState.con)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while selecting deduplication rules",
+				"Error": err.Error(),
+			})
+		}
+		visibleRules := []database.SelectDedupRule{}
+		for _, rule := range ruleList {
+			if permissions.allows(ROLE_VIEWER, rule.BrokerId, rule.TopicFilter) {
+				visibleRules = append(visibleRules, rule)
+			}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"rules": visibleRules,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+// | 2026-10-19     | Polariusz | Audited |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall delete a deduplication rule. Messages that were already marked as duplicates stay marked.
+// - The method shall need the role admin for the broker and the topic filter of the rule.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data must have a json structure that matches the struct DedupRuleIdWrapper.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"Id":<I>}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 404 (Not Found): JSON
+//   - {"badDedupRule":"There is no deduplication rule with this Id"}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while deleting the deduplication rule","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func PostDedupRuleDeleteHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_DELETE_DEDUP_RULE}
+		defer serverState.auditLog.record(c, &record)
+
+		var idWrapper DedupRuleIdWrapper
+		if err := c.BodyParser(&idWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+
+		// The rule decides who may delete it, a rule that does not exist needs an admin of all brokers.
+		ruleList, err := database.SelectDedupRules(serverState.con)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while selecting the deduplication rules",
+				"Error": err.Error(),
+			})
+		}
+		brokerId, topicFilter := 0, "#"
+		for _, rule := range ruleList {
+			if rule.Id == idWrapper.Id {
+				brokerId, topicFilter = rule.BrokerId, rule.TopicFilter
+			}
+		}
+		record.about(BrokerUser{BrokerId: brokerId}, topicFilter)
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, brokerId, topicFilter); !ok {
+			return err
+		}
+
+		found, err := database.DeleteDedupRule(serverState.con, idWrapper.Id)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while deleting the deduplication rule",
+				"Error": err.Error(),
+			})
+		}
+		if !found {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"badDedupRule": "There is no deduplication rule with this Id",
+			})
+		}
+		if err := serverState.deduplicator.reload(serverState.con); err != nil {
+			fmt.Printf("WARN: Deduplication rules were not reloaded!\nErr: %s\n", err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"Id": idWrapper.Id,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Store   |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return how many messages are marked as duplicates, per broker and topic.
+// - The method shall leave out the topics that the account may not view, the total only counts the others.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the GET-Method.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"duplicates":[<database.SelectDuplicateCount>],"total":<N>}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while counting the duplicates","Error":"<err>"}
+//   - {"InternalServerError":"Error while selecting the roles","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func GetDedupReportHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		permissions, err := permissionsOf(c, serverState)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while selecting the roles",
+				"Error": err.Error(),
+			})
+		}
+
+		countList, err := serverState.store.SelectDuplicateCounts(0)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while counting the duplicates",
+				"Error": err.Error(),
+			})
+		}
+
+		visibleCounts := []database.SelectDuplicateCount{}
+		total := 0
+		for _, count := range countList {
+			if permissions.allows(ROLE_VIEWER, count.BrokerId, count.Topic) {
+				visibleCounts = append(visibleCounts, count)
+				total += count.Duplicates
+			}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"duplicates": visibleCounts,
+			"total": total,
+		})
+	}
+}
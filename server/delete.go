@@ -0,0 +1,586 @@
+package main
+
+import (
+	"database"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall report whether the MQTT-Client is connected as the broker and user of `brokerUser`. A field that is 0 is not compared.
+//
+// # Author
+// - Polariusz
+func (serverState *ServerState) isConnectedAs(brokerUser BrokerUser) bool {
+	if serverState.mqttClient == nil || !serverState.mqttClient.IsConnected() {
+		return false
+	}
+	if brokerUser.BrokerId != 0 && brokerUser.BrokerId != serverState.connected.BrokerId {
+		return false
+	}
+	if brokerUser.UserId != 0 && brokerUser.UserId != serverState.connected.UserId {
+		return false
+	}
+	return true
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall run a cascading delete the way all delete handlers do:
+//   - The ingest queue writes what it holds first, so that no queued message references a deleted row.
+//   - After a real delete, the ID cache is cleared, as it may hold IDs of deleted topics and users.
+//
+// # Author
+// - Polariusz
+func runDelete(serverState *ServerState, dryRun bool, cascade func() (database.DeleteCounts, error)) (database.DeleteCounts, error) {
+	serverState.ingestQueue.sync()
+
+	counts, err := cascade()
+	if err == nil && !dryRun {
+		serverState.idCache.Clear()
+	}
+
+	return counts, err
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall delete the broker from the store of the project and its rules from the database.
+// - A SqliteStore on the database of the project deletes both in one transaction. A MemoryStore only keeps the data, so the rules are deleted from the database afterwards.
+//
+// # Author
+// - Polariusz
+func deleteBrokerCascade(serverState *ServerState, brokerId int, dryRun bool) (database.DeleteCounts, error) {
+	counts, err := serverState.store.DeleteBrokerCascade(brokerId, dryRun)
+	if err != nil {
+		return counts, err
+	}
+	if sqliteStore, ok := serverState.store.(*database.SqliteStore); ok && sqliteStore.Con() == serverState.con {
+		return counts, nil
+	}
+
+	ruleCounts, err := database.DeleteBrokerRulesCascade(serverState.con, brokerId, dryRun)
+	return counts.Plus(ruleCounts), err
+}
+
+// # Author
+// - Polariusz
+func deleteFailed(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"InternalServerError": "Error while deleting",
+		"Error": err.Error(),
+	})
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall return the IDs of the topics that match the MQTT topic filter, of one broker or of all brokers if `brokerId` is 0.
+//
+// # Author
+// - Polariusz
+func matchingTopicIds(serverState *ServerState, brokerId int, filter string) ([]int, error) {
+	brokerIds := []int{brokerId}
+	if brokerId == 0 {
+		brokerList, err := serverState.store.SelectBrokerList()
+		if err != nil {
+			return nil, err
+		}
+		brokerIds = nil
+		for _, broker := range brokerList {
+			brokerIds = append(brokerIds, broker.Id)
+		}
+	}
+
+	var topicIds []int
+	for _, id := range brokerIds {
+		topicList, err := serverState.store.SelectTopicsByBrokerId(id)
+		if err != nil {
+			return nil, err
+		}
+		for _, topic := range topicList {
+			if topicMatchesFilter(filter, topic.Topic) {
+				topicIds = append(topicIds, topic.Id)
+			}
+		}
+	}
+
+	return topicIds, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"BrokerId":<B>,"DryRun":<D>}
+//   - <B> : The ID of the Broker ROW
+//   - <D> : If true, nothing is deleted, the response only tells what would be deleted
+//
+// # Used in
+// - PostBrokerDeleteHandler()
+//
+// # Author
+// - Polariusz
+type BrokerDeleteWrapper struct {
+	BrokerId int
+	DryRun bool
+}
+
+// | Date of change | By        | Comment   |
+// +----------------+-----------+-----------+
+// | 2026-10-19     | Polariusz | Created   |
+// | 2026-10-19     | Polariusz | Roles     |
+// | 2026-10-19     | Polariusz | Audit log |
+// | 2026-10-19     | Polariusz | Webhooks  |
+// | 2026-10-19     | Polariusz | Alerts    |
+// | 2026-10-19     | Polariusz | Schedules |
+// | 2026-10-19     | Polariusz | Store     |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall delete a broker with its users, topics, messages, subscriptions, favourites the retention, deduplication and alert rules and the webhooks that only apply to it, and its schedules.
+// - The broker the MQTT-Client is connected to cannot be deleted, disconnect first.
+// - The method shall need the role admin for the whole broker.
+// - The method shall write the action to the audit log, dry runs too, see AuditLog.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data must have a json structure that matches the struct BrokerDeleteWrapper.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"dryRun":<D>,"deleted":<database.DeleteCounts>}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 404 (Not Found): JSON
+//   - {"badBroker":"There is no broker with this Id"}
+// - 409 (Conflict): JSON
+//   - {"Conflict":"The MQTT-Client is connected to this broker"}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while deleting","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func PostBrokerDeleteHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_DELETE_BROKER}
+		defer serverState.auditLog.record(c, &record)
+
+		var deleteWrapper BrokerDeleteWrapper
+		if err := c.BodyParser(&deleteWrapper); err != nil || deleteWrapper.BrokerId <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+
+		record.about(BrokerUser{BrokerId: deleteWrapper.BrokerId})
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, deleteWrapper.BrokerId); !ok {
+			return err
+		}
+
+		if serverState.isConnectedAs(BrokerUser{BrokerId: deleteWrapper.BrokerId}) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"Conflict": "The MQTT-Client is connected to this broker",
+			})
+		}
+
+		counts, err := runDelete(serverState, deleteWrapper.DryRun, func() (database.DeleteCounts, error) {
+			return deleteBrokerCascade(serverState, deleteWrapper.BrokerId, deleteWrapper.DryRun)
+		})
+		if err != nil {
+			return deleteFailed(c, err)
+		}
+		if counts.Brokers == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"badBroker": "There is no broker with this Id",
+			})
+		}
+		if !deleteWrapper.DryRun && counts.DedupRules > 0 {
+			serverState.deduplicator.reload(serverState.con)
+		}
+		if !deleteWrapper.DryRun && counts.Webhooks > 0 {
+			serverState.webhooks.reload()
+		}
+		if !deleteWrapper.DryRun && counts.AlertRules > 0 {
+			serverState.alerts.reload()
+		}
+		if !deleteWrapper.DryRun && counts.Schedules > 0 {
+			serverState.scheduler.reload()
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"dryRun": deleteWrapper.DryRun,
+			"deleted": counts,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"UserId":<U>,"DryRun":<D>}
+//   - <U> : The ID of the User ROW
+//   - <D> : If true, nothing is deleted, the response only tells what would be deleted
+//
+// # Used in
+// - PostUserDeleteHandler()
+//
+// # Author
+// - Polariusz
+type UserDeleteWrapper struct {
+	UserId int
+	DryRun bool
+}
+
+// | Date of change | By        | Comment   |
+// +----------------+-----------+-----------+
+// | 2026-10-19     | Polariusz | Created   |
+// | 2026-10-19     | Polariusz | Roles     |
+// | 2026-10-19     | Polariusz | Audit log |
+// | 2026-10-19     | Polariusz | Store     |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall delete a user, for example an outsider that published messages, with its messages, subscriptions and favourites.
+// - The user the MQTT-Client is connected as cannot be deleted, disconnect first.
+// - The method shall need the role admin for the whole broker of the user.
+// - The method shall write the action to the audit log, dry runs too, see AuditLog.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data must have a json structure that matches the struct UserDeleteWrapper.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"dryRun":<D>,"deleted":<database.DeleteCounts>}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 404 (Not Found): JSON
+//   - {"badUser":"There is no user with this Id"}
+// - 409 (Conflict): JSON
+//   - {"Conflict":"The MQTT-Client is connected as this user"}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while deleting","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func PostUserDeleteHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_DELETE_USER}
+		defer serverState.auditLog.record(c, &record)
+
+		var deleteWrapper UserDeleteWrapper
+		if err := c.BodyParser(&deleteWrapper); err != nil || deleteWrapper.UserId <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+
+		// A user that is not known needs an admin of all brokers, who then learns that it does not exist.
+		brokerId := 0
+		if user, err := serverState.store.SelectUserById(deleteWrapper.UserId); err == nil {
+			brokerId = user.BrokerId
+		}
+		record.about(BrokerUser{BrokerId: brokerId, UserId: deleteWrapper.UserId})
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, brokerId); !ok {
+			return err
+		}
+
+		if serverState.isConnectedAs(BrokerUser{UserId: deleteWrapper.UserId}) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"Conflict": "The MQTT-Client is connected as this user",
+			})
+		}
+
+		counts, err := runDelete(serverState, deleteWrapper.DryRun, func() (database.DeleteCounts, error) {
+			return serverState.store.DeleteUserCascade(deleteWrapper.UserId, deleteWrapper.DryRun)
+		})
+		if err != nil {
+			return deleteFailed(c, err)
+		}
+		if counts.Users == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"badUser": "There is no user with this Id",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"dryRun": deleteWrapper.DryRun,
+			"deleted": counts,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"BrokerId":<B>,"Topics":["<T>"],"TopicFilter":"<F>","DryRun":<D>}
+//   - <B> : The ID of the Broker ROW
+//   - <T> : Names of the topics to delete
+//   - <F> : Optional, MQTT topic filter, every topic of the broker that matches it is deleted too
+//   - <D> : If true, nothing is deleted, the response only tells what would be deleted
+//
+// # Used in
+// - PostTopicDeleteHandler()
+//
+// # Author
+// - Polariusz
+type TopicDeleteWrapper struct {
+	BrokerId int
+	Topics []string
+	TopicFilter string
+	DryRun bool
+}
+
+// | Date of change | By        | Comment   |
+// +----------------+-----------+-----------+
+// | 2026-10-19     | Polariusz | Created   |
+// | 2026-10-19     | Polariusz | Roles     |
+// | 2026-10-19     | Polariusz | Audit log |
+// | 2026-10-19     | Polariusz | Store     |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall delete topics of a broker with their messages, subscriptions and favourites.
+// - The MQTT-Client stays subscribed. A topic that still receives messages is created again with the next message, unsubscribe first to avoid that.
+// - The method shall need the role admin for every topic and for the TopicFilter.
+// - The method shall write the action to the audit log, dry runs too, see AuditLog.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data must have a json structure that matches the struct TopicDeleteWrapper. At least one of Topics and TopicFilter must be given.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"dryRun":<D>,"topics":[<TOPIC-ID>],"deleted":<database.DeleteCounts>}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+//   - {"terribleJson":"<What is wrong>"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while deleting","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func PostTopicDeleteHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_DELETE_TOPICS}
+		defer serverState.auditLog.record(c, &record)
+
+		var deleteWrapper TopicDeleteWrapper
+		if err := c.BodyParser(&deleteWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+		if deleteWrapper.BrokerId <= 0 || (len(deleteWrapper.Topics) == 0 && deleteWrapper.TopicFilter == "") {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"terribleJson": "BrokerId and Topics or TopicFilter are required",
+			})
+		}
+		if deleteWrapper.TopicFilter != "" && !validTopicFilter(deleteWrapper.TopicFilter) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"terribleJson": "TopicFilter is not a valid MQTT topic filter",
+			})
+		}
+
+		filters := deleteWrapper.Topics
+		if deleteWrapper.TopicFilter != "" {
+			filters = append(filters, deleteWrapper.TopicFilter)
+		}
+		record.about(BrokerUser{BrokerId: deleteWrapper.BrokerId}, filters...)
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, deleteWrapper.BrokerId, filters...); !ok {
+			return err
+		}
+
+		topicList, err := serverState.store.SelectTopicsByBrokerId(deleteWrapper.BrokerId)
+		if err != nil {
+			return deleteFailed(c, err)
+		}
+		named := make(map[string]bool)
+		for _, topic := range deleteWrapper.Topics {
+			named[topic] = true
+		}
+		topicIds := []int{}
+		for _, topic := range topicList {
+			if named[topic.Topic] || (deleteWrapper.TopicFilter != "" && topicMatchesFilter(deleteWrapper.TopicFilter, topic.Topic)) {
+				topicIds = append(topicIds, topic.Id)
+			}
+		}
+
+		counts, err := runDelete(serverState, deleteWrapper.DryRun, func() (database.DeleteCounts, error) {
+			return serverState.store.DeleteTopicsCascade(topicIds, deleteWrapper.DryRun)
+		})
+		if err != nil {
+			return deleteFailed(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"dryRun": deleteWrapper.DryRun,
+			"topics": topicIds,
+			"deleted": counts,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Ids":[<I>],"BrokerId":<B>,"TopicFilter":"<F>","UserId":<U>,"After":"<A>","Before":"<E>","OnlyDuplicates":<O>,"DryRun":<D>}
+//   - <I> : IDs of Message ROWs
+//   - <B> : The ID of the Broker ROW
+//   - <F> : MQTT topic filter, of the broker <B> or of all brokers if <B> is 0
+//   - <U> : The ID of the User ROW that published the messages
+//   - <A> : DateTime, messages received at or after it
+//   - <E> : DateTime, messages received before it
+//   - <O> : Only messages that are marked as duplicates
+//   - <D> : If true, nothing is deleted, the response only tells what would be deleted
+// - Every field is optional, but at least one besides DryRun must be given. The given fields are combined with AND.
+//
+// # Used in
+// - PostMessageDeleteHandler()
+//
+// # Author
+// - Polariusz
+type MessageDeleteWrapper struct {
+	Ids []int
+	BrokerId int
+	TopicFilter string
+	UserId int
+	After time.Time
+	Before time.Time
+	OnlyDuplicates bool
+	DryRun bool
+}
+
+// | Date of change | By        | Comment   |
+// +----------------+-----------+-----------+
+// | 2026-10-19     | Polariusz | Created   |
+// | 2026-10-19     | Polariusz | Roles     |
+// | 2026-10-19     | Polariusz | Audit log |
+// | 2026-10-19     | Polariusz | Store     |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall delete messages by their IDs or by a filter.
+// - The method shall need the role admin for the TopicFilter (or all topics) on the broker, or on all brokers without BrokerId.
+// - The method shall write the action to the audit log, dry runs too, see AuditLog.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data must have a json structure that matches the struct MessageDeleteWrapper.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"dryRun":<D>,"deleted":<database.DeleteCounts>}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+//   - {"terribleJson":"<What is wrong>"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while deleting","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func PostMessageDeleteHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_DELETE_MESSAGES}
+		defer serverState.auditLog.record(c, &record)
+
+		var deleteWrapper MessageDeleteWrapper
+		if err := c.BodyParser(&deleteWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+
+		// Without a broker, the messages of all brokers may match. Without a topic filter, the ones of all topics.
+		topicFilter := deleteWrapper.TopicFilter
+		if topicFilter == "" {
+			topicFilter = "#"
+		}
+		record.about(BrokerUser{BrokerId: deleteWrapper.BrokerId, UserId: deleteWrapper.UserId}, topicFilter)
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, deleteWrapper.BrokerId, topicFilter); !ok {
+			return err
+		}
+
+		filter := database.MessageFilter{
+			Ids: deleteWrapper.Ids,
+			BrokerId: deleteWrapper.BrokerId,
+			UserId: deleteWrapper.UserId,
+			After: deleteWrapper.After,
+			Before: deleteWrapper.Before,
+			OnlyDuplicates: deleteWrapper.OnlyDuplicates,
+		}
+		if deleteWrapper.TopicFilter != "" {
+			if !validTopicFilter(deleteWrapper.TopicFilter) {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"terribleJson": "TopicFilter is not a valid MQTT topic filter",
+				})
+			}
+			topicIds, err := matchingTopicIds(serverState, deleteWrapper.BrokerId, deleteWrapper.TopicFilter)
+			if err != nil {
+				return deleteFailed(c, err)
+			}
+			// An empty list would not narrow the filter, but no topic matches, so no message does.
+			if len(topicIds) == 0 {
+				return c.Status(fiber.StatusOK).JSON(fiber.Map{
+					"dryRun": deleteWrapper.DryRun,
+					"deleted": database.DeleteCounts{},
+				})
+			}
+			filter.TopicIds = topicIds
+		}
+		if filter.IsEmpty() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"terribleJson": "At least one of Ids, BrokerId, TopicFilter, UserId, After, Before and OnlyDuplicates must be given",
+			})
+		}
+
+		counts, err := runDelete(serverState, deleteWrapper.DryRun, func() (database.DeleteCounts, error) {
+			return serverState.store.DeleteMessagesByFilter(filter, deleteWrapper.DryRun)
+		})
+		if err != nil {
+			return deleteFailed(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"dryRun": deleteWrapper.DryRun,
+			"deleted": counts,
+		})
+	}
+}
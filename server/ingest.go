@@ -0,0 +1,505 @@
+package main
+
+import (
+	"database"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - What the ingest queue does with a new message when it is full.
+//   - INGEST_BLOCK       : The MQTT handler waits until the writer makes room. Nothing is lost, but paho's router is blocked meanwhile.
+//   - INGEST_DROP_OLDEST : The oldest queued message is dropped to make room.
+//   - INGEST_SAMPLE      : Only every `SampleRate`-th message is kept (by dropping the oldest), the others are dropped.
+//
+// # Author
+// - Polariusz
+const (
+	INGEST_BLOCK       = "block"
+	INGEST_DROP_OLDEST = "drop-oldest"
+	INGEST_SAMPLE      = "sample"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Capacity":<C>,"BatchSize":<B>,"FlushInterval":<F>,"Overflow":"<O>","SampleRate":<S>}
+//   - <C> : How many messages can wait in the queue
+//   - <B> : The writer commits when it has this many messages...
+//   - <F> : ...or when this much time (in nanoseconds) has passed since the first message of the batch
+//   - <O> : One of INGEST_BLOCK, INGEST_DROP_OLDEST, INGEST_SAMPLE
+//   - <S> : Used by INGEST_SAMPLE
+//
+// # Author
+// - Polariusz
+type IngestConfig struct {
+	Capacity int
+	BatchSize int
+	FlushInterval time.Duration
+	Overflow string
+	SampleRate int
+}
+
+// # Author
+// - Polariusz
+func DefaultIngestConfig() IngestConfig {
+	return IngestConfig{
+		Capacity: 10000,
+		BatchSize: 500,
+		FlushInterval: 200 * time.Millisecond,
+		Overflow: INGEST_BLOCK,
+		SampleRate: 10,
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Returns
+// - An error message if the overflow behaviour is not valid, otherwise ""
+//
+// # Author
+// - Polariusz
+func validateIngestOverflow(overflow string, sampleRate int) string {
+	switch overflow {
+	case INGEST_BLOCK, INGEST_DROP_OLDEST:
+		return ""
+	case INGEST_SAMPLE:
+		if sampleRate < 1 {
+			return "SampleRate must be at least 1"
+		}
+		return ""
+	}
+	return fmt.Sprintf("Overflow must be '%s', '%s' or '%s'", INGEST_BLOCK, INGEST_DROP_OLDEST, INGEST_SAMPLE)
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # JSON-Structure:
+// - {"Depth":<D>,"Capacity":<C>,"Overflow":"<O>","SampleRate":<S>,"Enqueued":<E>,"Written":<W>,"Dropped":<DR>,"Failed":<F>,"Batches":<B>,"LastFlush":"<T>","LastError":"<LE>"}
+//
+// # Used in
+// - GetIngestMetricsHandler()
+//
+// # Author
+// - Polariusz
+type IngestMetrics struct {
+	Depth int
+	Capacity int
+	Overflow string
+	SampleRate int
+	Enqueued int64
+	Written int64
+	Dropped int64
+	Failed int64
+	Batches int64
+	LastFlush time.Time
+	LastError string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The structure decouples receiving MQTT messages from writing them into the database.
+// - The MQTT handler calls `enqueue()`, a dedicated writer goroutine commits the messages in transactions of up to `BatchSize` messages or every `FlushInterval`.
+// - `close()` stops accepting messages and waits until everything queued is written.
+//
+// # Used in
+// - struct ServerState
+//
+// # Author
+// - Polariusz
+type IngestQueue struct {
+	store database.Store
+	config IngestConfig
+	queue chan database.InsertMessage
+	done chan struct{}
+	syncRequests chan chan struct{}
+
+	// enqueueMutex serialises producers, so that dropping the oldest message and queueing the new one happen together.
+	enqueueMutex sync.Mutex
+	closed bool
+
+	// configMutex guards the overflow behaviour, which can be changed while producers are blocked.
+	configMutex sync.Mutex
+	sampleCounter int
+
+	metricsMutex sync.Mutex
+	metrics IngestMetrics
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - Creates the queue and starts its writer.
+//
+// # Author
+// - Polariusz
+func NewIngestQueue(store database.Store, config IngestConfig) *IngestQueue {
+	iq := &IngestQueue{
+		store: store,
+		config: config,
+		queue: make(chan database.InsertMessage, config.Capacity),
+		done: make(chan struct{}),
+		syncRequests: make(chan chan struct{}),
+	}
+	iq.metrics.Capacity = config.Capacity
+
+	go iq.write()
+
+	return iq
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall queue the `message` to be written, handling a full queue as the overflow behaviour demands.
+//
+// # Returns
+// - false if the message was dropped
+//
+// # Author
+// - Polariusz
+func (iq *IngestQueue) enqueue(message database.InsertMessage) bool {
+	iq.configMutex.Lock()
+	overflow := iq.config.Overflow
+	keepSample := false
+	if overflow == INGEST_SAMPLE {
+		iq.sampleCounter++
+		keepSample = iq.sampleCounter % iq.config.SampleRate == 0
+	}
+	iq.configMutex.Unlock()
+
+	iq.enqueueMutex.Lock()
+	defer iq.enqueueMutex.Unlock()
+
+	if iq.closed {
+		iq.countDropped(1)
+		return false
+	}
+
+	select {
+	case iq.queue <- message:
+		iq.countEnqueued()
+		return true
+	default:
+	}
+
+	switch overflow {
+	case INGEST_SAMPLE:
+		if !keepSample {
+			iq.countDropped(1)
+			return false
+		}
+		fallthrough
+	case INGEST_DROP_OLDEST:
+		select {
+		case <-iq.queue:
+			iq.countDropped(1)
+		default:
+		}
+		select {
+		case iq.queue <- message:
+			iq.countEnqueued()
+			return true
+		default:
+			iq.countDropped(1)
+			return false
+		}
+	}
+
+	// INGEST_BLOCK
+	iq.queue <- message
+	iq.countEnqueued()
+	return true
+}
+
+// # Author
+// - Polariusz
+func (iq *IngestQueue) countEnqueued() {
+	iq.metricsMutex.Lock()
+	iq.metrics.Enqueued++
+	iq.metricsMutex.Unlock()
+}
+
+// # Author
+// - Polariusz
+func (iq *IngestQueue) countDropped(count int64) {
+	iq.metricsMutex.Lock()
+	iq.metrics.Dropped += count
+	iq.metricsMutex.Unlock()
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Worker
+//
+// # Description
+// - The method shall collect queued messages and commit them in batches until the queue is closed and empty.
+//
+// # Author
+// - Polariusz
+func (iq *IngestQueue) write() {
+	defer close(iq.done)
+
+	var batch []database.InsertMessage
+	timer := time.NewTimer(iq.config.FlushInterval)
+	timer.Stop()
+
+	for {
+		select {
+		case message, ok := <-iq.queue:
+			if !ok {
+				iq.flush(batch)
+				return
+			}
+			if len(batch) == 0 {
+				timer.Reset(iq.config.FlushInterval)
+			}
+			batch = append(batch, message)
+			if len(batch) >= iq.config.BatchSize {
+				timer.Stop()
+				iq.flush(batch)
+				batch = nil
+			}
+		case <-timer.C:
+			iq.flush(batch)
+			batch = nil
+		case synced := <-iq.syncRequests:
+			timer.Stop()
+			for len(iq.queue) > 0 {
+				message, ok := <-iq.queue
+				if !ok {
+					break
+				}
+				batch = append(batch, message)
+			}
+			iq.flush(batch)
+			batch = nil
+			close(synced)
+		}
+	}
+}
+
+// # Author
+// - Polariusz
+func (iq *IngestQueue) flush(batch []database.InsertMessage) {
+	if len(batch) == 0 {
+		return
+	}
+
+	err := iq.store.InsertNewMessages(batch)
+
+	iq.metricsMutex.Lock()
+	defer iq.metricsMutex.Unlock()
+
+	iq.metrics.Batches++
+	iq.metrics.LastFlush = time.Now()
+	if err != nil {
+		fmt.Printf("Error while inserting %d new messages\nError: %s\n", len(batch), err)
+		iq.metrics.Failed += int64(len(batch))
+		iq.metrics.LastError = err.Error()
+		return
+	}
+	iq.metrics.Written += int64(len(batch))
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall stop accepting messages and block until every queued message is written.
+//
+// # Used in
+// - main()
+//
+// # Author
+// - Polariusz
+func (iq *IngestQueue) close() {
+	iq.enqueueMutex.Lock()
+	if !iq.closed {
+		iq.closed = true
+		close(iq.queue)
+	}
+	iq.enqueueMutex.Unlock()
+
+	<-iq.done
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall block until every message that was queued before the call is written. The queue keeps accepting messages.
+//
+// # Used in
+// - The delete handlers, so that no queued message references a deleted row.
+//
+// # Author
+// - Polariusz
+func (iq *IngestQueue) sync() {
+	synced := make(chan struct{})
+	select {
+	case iq.syncRequests <- synced:
+		<-synced
+	case <-iq.done:
+	}
+}
+
+// # Author
+// - Polariusz
+func (iq *IngestQueue) setOverflow(overflow string, sampleRate int) {
+	iq.configMutex.Lock()
+	defer iq.configMutex.Unlock()
+
+	iq.config.Overflow = overflow
+	iq.config.SampleRate = sampleRate
+	iq.sampleCounter = 0
+}
+
+// # Author
+// - Polariusz
+func (iq *IngestQueue) snapshot() IngestMetrics {
+	iq.configMutex.Lock()
+	overflow := iq.config.Overflow
+	sampleRate := iq.config.SampleRate
+	iq.configMutex.Unlock()
+
+	iq.metricsMutex.Lock()
+	defer iq.metricsMutex.Unlock()
+
+	metrics := iq.metrics
+	metrics.Depth = len(iq.queue)
+	metrics.Overflow = overflow
+	metrics.SampleRate = sampleRate
+
+	return metrics
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return the depth of the ingest queue and how many messages were written, dropped and failed.
+// - The method shall need the role viewer for all brokers, the metrics count the messages of all of them.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the GET-Method.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"ingest":<IngestMetrics>}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+//
+// # Author
+// - Polariusz
+func GetIngestMetricsHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_VIEWER, 0); !ok {
+			return err
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"ingest": serverState.ingestQueue.snapshot(),
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Overflow":"<O>","SampleRate":<S>}
+//
+// # Used in
+// - PostIngestOverflowHandler()
+//
+// # Author
+// - Polariusz
+type IngestOverflowWrapper struct {
+	Overflow string
+	SampleRate int
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall change what the ingest queue does when it is full.
+// - The method shall need the role admin on all brokers.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data must have a json structure that matches the struct IngestOverflowWrapper.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"ingest":<IngestMetrics>}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+//   - {"terribleJson":"<What is wrong>"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+//
+// # Author
+// - Polariusz
+func PostIngestOverflowHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		var overflowWrapper IngestOverflowWrapper
+		if err := c.BodyParser(&overflowWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+
+		if errorMessage := validateIngestOverflow(overflowWrapper.Overflow, overflowWrapper.SampleRate); errorMessage != "" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"terribleJson": errorMessage,
+			})
+		}
+
+		serverState.ingestQueue.setOverflow(overflowWrapper.Overflow, overflowWrapper.SampleRate)
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"ingest": serverState.ingestQueue.snapshot(),
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"database"
 	"database/sql"
 	"encoding/json"
+	"flag"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -11,7 +12,11 @@ import (
 	"github.com/eclipse/paho.mqtt.golang"
 	"time"
 	"strconv"
+	"os"
 	"os/exec"
+	"os/signal"
+	"sync"
+	"syscall"
 )
 
 // | Date of change | By        | Comment |
@@ -33,44 +38,78 @@ type BrokerUser struct {
 	UserId int
 }
 
-// | Date of change | By        | Comment |
-// +----------------+-----------+---------+
-// | 2025-06-06     | Polariusz | Created |
+// | Date of change | By        | Comment         |
+// +----------------+-----------+-----------------+
+// | 2025-06-06     | Polariusz | Created         |
+// | 2026-10-19     | Polariusz | Added Timestamp |
 //
 // # Structure:
-// - {"BrokerId":<B>,"UserId":<U>,"Message":<Message>}
+// - {"BrokerId":<B>,"UserId":<U>,"Message":<Message>,"Timestamp":"<T>"}
 //   - <B> : The ID of the Broker ROW matched from the BrokerId from PostCredentialsHandler()'s brokerId
 //   - <U> : The ID of the User ROW matched from the BrokerId from PostCredentialsHandler()'s brokerId
 //   - <M> : Published Message
+//   - <T> : When the message was published, in RFC 3339 with nanoseconds. It's optional, messages of older clients don't have it.
 //
 // # Used in
 // - messageBuilder()
+// - createMessageHandler()
 //
 // # Author
 // - Polariusz
 type JsonPublishMessage struct {
 	ClientId string
 	Message string
+	Timestamp string `json:",omitempty"`
+}
+
+// # Description
+// - The method shall return the Timestamp of the envelope, or the zero time if there is none or it cannot be parsed.
+//
+// # Author
+// - Polariusz
+func (jsonPublishMessage JsonPublishMessage) timestamp() time.Time {
+	if jsonPublishMessage.Timestamp == "" {
+		return time.Time{}
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, jsonPublishMessage.Timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return timestamp
 }
 
 // # Author
 // - Polariusz
 const BADJSON = "I am nowt sowwy >:3. An expected! ewwow has happened. Youw weak json! iws of the wwongest fowmat thawt does nowt cowwespond tuwu the stwong awnd independent stwuct! >:P"
 
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - How long the open requests may take after Ctrl+C before the server stops without them.
+//
+// # Author
+// - Polariusz
+const SHUTDOWN_TIMEOUT = 10 * time.Second
+
 // | Date of change | By        | Comment              |
 // +----------------+-----------+----------------------+
 // | 2025-05-13     | Polariusz | Created              |
 // | 2025-06-06     | Polariusz | Actually implemented |
+// | 2026-10-19     | Polariusz | Added Timestamp      |
 //
 // # Description
 // - This method shall build a message containing the BrokerId, UserId and the Message that the messagePubHandler will be able to then differentiate and write into the database accordingly.
+// - The envelope is stamped with the current time, so the receiver can measure the latency.
 //
 // # Author
 // - Polariusz
 func messageBuilder(clientId string, message string) []byte {
 	fullMessage := JsonPublishMessage {
-		clientId,
-		message,
+		ClientId: clientId,
+		Message: message,
+		Timestamp: time.Now().Format(time.RFC3339Nano),
 	}
 	
 	// ignoring error as the structure above cannot fail to marshal
@@ -79,12 +118,30 @@ func messageBuilder(clientId string, message string) []byte {
 	return jsonMessage
 }
 
-// | Date of change | By        | Comment               |
-// +----------------+-----------+-----------------------+
-// |                | Polariusz | Created               |
-// | 2025-05-13     | Polariusz | Documentation         |
-// | 2025-05-16     | Polariusz | added AllKnownTopics  |
-// | 2025-05-18     | Polariusz | added favouriteTopics |
+// | Date of change | By        | Comment                |
+// +----------------+-----------+------------------------+
+// |                | Polariusz | Created                |
+// | 2025-05-13     | Polariusz | Documentation          |
+// | 2025-05-16     | Polariusz | added AllKnownTopics   |
+// | 2025-05-18     | Polariusz | added favouriteTopics  |
+// | 2026-10-19     | Polariusz | added replayManager    |
+// | 2026-10-19     | Polariusz | added stats            |
+// | 2026-10-19     | Polariusz | added idCache          |
+// | 2026-10-19     | Polariusz | added ingestQueue      |
+// | 2026-10-19     | Polariusz | added retentionJanitor |
+// | 2026-10-19     | Polariusz | added projects         |
+// | 2026-10-19     | Polariusz | added store            |
+// | 2026-10-19     | Polariusz | added deduplicator     |
+// | 2026-10-19     | Polariusz | added connected        |
+// | 2026-10-19     | Polariusz | added accounts         |
+// | 2026-10-19     | Polariusz | added auditLog         |
+// | 2026-10-19     | Polariusz | added webhooks         |
+// | 2026-10-19     | Polariusz | added bridges          |
+// | 2026-10-19     | Polariusz | added alerts           |
+// | 2026-10-19     | Polariusz | added scheduler        |
+// | 2026-10-19     | Polariusz | added benchmarks       |
+// | 2026-10-19     | Polariusz | added databaseLock     |
+// | 2026-10-19     | Polariusz | added probeTraffic     |
 //
 // # Description
 //
@@ -98,9 +155,30 @@ func messageBuilder(clientId string, message string) []byte {
 // # Author
 // - Polariusz
 type ServerState struct {
+	// The credentials of the last successful PostCredentialsHandler(), empty after a disconnect.
 	userCreds MqttCredentials
 	mqttClient mqtt.Client
 	con *sql.DB
+	store database.Store
+	replayManager *ReplayManager
+	stats *MessageStats
+	idCache *database.IdCache
+	ingestQueue *IngestQueue
+	retentionJanitor *RetentionJanitor
+	projects *ProjectManager
+	deduplicator *Deduplicator
+	// The broker and user of the last successful PostCredentialsHandler(), only valid while mqttClient is connected.
+	connected BrokerUser
+	accounts *AccountManager
+	auditLog *AuditLog
+	webhooks *WebhookDispatcher
+	bridges *BridgeManager
+	alerts *AlertEngine
+	scheduler *Scheduler
+	benchmarks *BenchmarkManager
+	// Held for reading by every request, see lockDatabase(), and for writing while the database is swapped, see switchTo().
+	databaseLock sync.RWMutex
+	probeTraffic *ProbeTraffic
 }
 
 // | Date of change | By        | Comment                     |
@@ -141,44 +219,208 @@ func (mc MqttCredentials) dump() {
 	fmt.Printf("clientId : %s", mc.ClientId)
 }
 
+// | Date of change | By        | Comment                                |
+// +----------------+-----------+----------------------------------------+
+// | 2026-10-19     | Polariusz | Created                                |
+// | 2026-10-19     | Polariusz | Keepalive and ping timeout from config |
+//
+// # Description
+// - The function shall return the options of a MQTT-Client that connects with the credentials, so every connection of the server behaves the same.
+// - The Username and the Password are only sent if a Username is given.
+// - The keepalive and the ping timeout are those of serverConfig.
+//
+// # Used in
+// - PostCredentialsHandler()
+// - (*bridgeWorker).start()
+//
+// # Author
+// - Polariusz
+func mqttClientOptions(credentials MqttCredentials) *mqtt.ClientOptions {
+	mqttOpts := mqtt.NewClientOptions().AddBroker(fmt.Sprintf("tcp://%s:%s", credentials.Ip, credentials.Port)).SetClientID(credentials.ClientId)
+	mqttOpts.SetKeepAlive(serverConfig.KeepAlive)
+	mqttOpts.SetPingTimeout(serverConfig.PingTimeout)
+	if credentials.Username != "" {
+		mqttOpts.SetUsername(credentials.Username)
+		mqttOpts.SetPassword(credentials.Password)
+	}
+	return mqttOpts
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - Creates the MQTT-Clients of the server, the tests replace it with a broker in memory.
+//
+// # Used in
+// - PostCredentialsHandler()
+// - PostRequestHandler()
+// - The probes and benchmarks
+//
+// # Author
+// - Polariusz
+var newMqttClient = mqtt.NewClient
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall stop the HTTP server after Ctrl+C.
+// - The alert streams never end on their own and the server waits for every open request, so they are ended first. A request that still hangs is cut off after SHUTDOWN_TIMEOUT.
+//
+// # Returns
+// - error when the requests did not end in time
+//
+// # Author
+// - Polariusz
+func shutdown(server *fiber.App, serverState *ServerState) error {
+	serverState.databaseLock.RLock()
+	serverState.alerts.endStreams()
+	serverState.databaseLock.RUnlock()
+
+	return server.ShutdownWithTimeout(SHUTDOWN_TIMEOUT)
+}
+
+// | Date of change | By        | Comment        |
+// +----------------+-----------+----------------+
+// |                | Polariusz | Created        |
+// | 2026-10-19     | Polariusz | Added commands |
+// | 2026-10-19     | Polariusz | Clean shutdown |
+// | 2026-10-19     | Polariusz | Accounts, CORS |
+// | 2026-10-19     | Polariusz | Configuration  |
+//
+// # Description
+// - Starts the server. If the program is called with arguments, it runs the command from `runCommand()` instead.
+// - The configuration comes from the flags, the environment and a configuration file, see `loadConfig()`. If it is not valid, the program exits with 2.
+// - On SIGINT or SIGTERM the server shuts down and flushes the ingest queue before exiting.
+//
 // # Author
 // - Polariusz
 func main() {
-	con, err := database.OpenDatabase()
+	config, sources, err := loadConfig(flag.CommandLine, os.Args[1:])
 	if err != nil {
-		fmt.Printf("WARN: Running without database\nErr:%s\n", err)
+		fmt.Printf("ERROR: The configuration is not valid\n%s\n", err)
+		os.Exit(2)
+	}
+	serverConfig = config
+
+	if flag.NArg() > 0 && flag.Arg(0) == "config" {
+		os.Exit(runConfigCommand(config, sources, flag.Args()[1:]))
+	}
+
+	projects := NewProjectManager(config.Database)
+
+	if flag.NArg() > 0 {
+		con, err := database.OpenDatabase(projects.path(DEFAULT_PROJECT))
+		if err != nil {
+			fmt.Printf("WARN: Running without database\nErr:%s\n", err)
+		}
+		os.Exit(runCommand(con, flag.Args()))
 	}
-	if err := database.SetupDatabase(con); err != nil {
-		fmt.Printf("WARN: Issue with setting db up!\nErr:%s\n", err)
+
+	con, store, err := projects.open(DEFAULT_PROJECT)
+	if err != nil {
+		fmt.Printf("WARN: Running without database\nErr:%s\n", err)
 	}
 
 	// Browser automatisch öffnen (nur Windows)
 	go func() {
-		url := "http://localhost:3000"
+		url := "http://" + browserAddress(config.Listen)
 		exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
 	}()
 
 	server := fiber.New()
 
+	// The session cookie is only sent along to origins that are named, "*" can not be combined with credentials.
 	server.Use(cors.New(cors.Config{
-        AllowOrigins: "*",
-        AllowHeaders: "Origin, Content-Type, Accept, Authorization",
+        AllowOrigins: config.CorsOrigins,
+        AllowHeaders: "Origin, Content-Type, Accept, Authorization, X-API-Key",
+        AllowCredentials: config.CorsOrigins != "*",
     }))
 
 	var serverState ServerState
 	serverState.con = con
+	serverState.store = store
+	serverState.projects = projects
+	serverState.replayManager = NewReplayManager()
+	serverState.probeTraffic = NewProbeTraffic()
+	serverState.stats = NewMessageStats()
+	serverState.idCache = database.NewIdCache()
+	serverState.ingestQueue = NewIngestQueue(serverState.store, DefaultIngestConfig())
+	serverState.retentionJanitor = NewRetentionJanitor(con, store)
+	serverState.retentionJanitor.start()
+	serverState.deduplicator = NewDeduplicator()
+	if err := serverState.deduplicator.reload(con); err != nil {
+		fmt.Printf("WARN: Running without deduplication rules\nErr:%s\n", err)
+	}
+	serverState.accounts, err = NewAccountManager(con)
+	if err != nil {
+		fmt.Printf("WARN: Running without accounts\nErr:%s\n", err)
+	}
+	if !serverState.accounts.enabled() {
+		fmt.Printf("WARN: There are no accounts, anyone who can reach the server can use its API. Create one with `main account add <USERNAME>`\n")
+	}
+	serverState.auditLog = NewAuditLog(con)
+	serverState.webhooks = NewWebhookDispatcher(con)
+	serverState.webhooks.start()
+	serverState.bridges = NewBridgeManager(con)
+	serverState.bridges.start()
+	serverState.alerts = NewAlertEngine(con, serverState.webhooks)
+	serverState.alerts.start()
+	serverState.scheduler = NewScheduler(con, &serverState)
+	serverState.scheduler.start()
+	serverState.benchmarks = NewBenchmarkManager(con)
+	serverState.benchmarks.start()
 
 	addRoutes(server, &serverState)
 
 	// need to build ui via 'npm run build' in client first
-	server.Static("/", "dist")
-	server.Listen(":3000")
+	server.Static("/", config.StaticDir)
+
+	// On Ctrl+C the server stops listening, the MQTT-Client disconnects and the ingest queue writes everything it still holds.
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		if err := shutdown(server, &serverState); err != nil {
+			fmt.Printf("WARN: The server was stopped with requests still open\nErr: %s\n", err)
+		}
+	}()
+
+	if err := server.Listen(config.Listen); err != nil {
+		fmt.Printf("ERROR: %s\n", err)
+	}
+
+	if serverState.mqttClient != nil && serverState.mqttClient.IsConnected() {
+		serverState.mqttClient.Disconnect(250)
+	}
+	serverState.retentionJanitor.close()
+	serverState.benchmarks.close()
+	serverState.scheduler.close()
+	serverState.alerts.close()
+	serverState.webhooks.close()
+	serverState.bridges.close()
+	serverState.ingestQueue.close()
+	serverState.projects.closeAll()
 }
 
-// | Date of change | By        | Comment       |
-// +----------------+-----------+---------------+
-// |                | Polariusz | Created       |
-// | 2025-05-13     | Polariusz | Documentation |
+// | Date of change | By        | Comment                 |
+// +----------------+-----------+-------------------------+
+// |                | Polariusz | Created                 |
+// | 2025-05-13     | Polariusz | Documentation           |
+// | 2026-10-19     | Polariusz | Added replay routes     |
+// | 2026-10-19     | Polariusz | Added stats route       |
+// | 2026-10-19     | Polariusz | Added ingest routes     |
+// | 2026-10-19     | Polariusz | Added retention routes  |
+// | 2026-10-19     | Polariusz | Added project routes    |
+// | 2026-10-19     | Polariusz | Added backup routes     |
+// | 2026-10-19     | Polariusz | Added dedup routes      |
+// | 2026-10-19     | Polariusz | Added delete routes     |
+// | 2026-10-19     | Polariusz | Added /api/v1 routes    |
+// | 2026-10-19     | Polariusz | Added authentication    |
+// | 2026-10-19     | Polariusz | Added the database lock |
 //
 // # Method-Type
 // - Routing
@@ -189,6 +431,9 @@ func main() {
 // # Author
 // - Polariusz
 func addRoutes(server *fiber.App, serverState *ServerState) {
+	server.Use(deprecatedRoutes())
+	server.Use(lockDatabase(serverState))
+	server.Use(authenticate(serverState))
 	server.Post("/credentials", PostCredentialsHandler(serverState))
 	server.Post("/disconnect", PostDisconnectFromBrokerHandler(serverState))
 	server.Post("/topic/subscribe", PostTopicSubscribeHandler(serverState))
@@ -203,15 +448,51 @@ func addRoutes(server *fiber.App, serverState *ServerState) {
 	server.Post("/topic/favourites/mark", PostTopicFavouritesMark(serverState))
 	server.Post("/topic/favourites/unmark", PostTopicFavouritesUnmark(serverState))
 	server.Get("/topic/favourites", GetTopicFavourites(serverState))
+	server.Post("/replay/start", PostReplayStartHandler(serverState))
+	server.Get("/replay/jobs", GetReplayJobsHandler(serverState))
+	server.Post("/replay/status", PostReplayStatusHandler(serverState))
+	server.Post("/replay/pause", PostReplayPauseHandler(serverState))
+	server.Post("/replay/resume", PostReplayResumeHandler(serverState))
+	server.Post("/replay/cancel", PostReplayCancelHandler(serverState))
+	server.Get("/stats", GetStatsHandler(serverState))
+	server.Get("/ingest/metrics", GetIngestMetricsHandler(serverState))
+	server.Post("/ingest/overflow", PostIngestOverflowHandler(serverState))
+	server.Post("/retention/rules", PostRetentionRuleCreateHandler(serverState))
+	server.Get("/retention/rules", GetRetentionRulesHandler(serverState))
+	server.Post("/retention/rules/delete", PostRetentionRuleDeleteHandler(serverState))
+	server.Get("/retention/report", GetRetentionReportHandler(serverState))
+	server.Post("/retention/run", PostRetentionRunHandler(serverState))
+	server.Get("/projects", GetProjectsHandler(serverState))
+	server.Post("/projects/select", PostProjectSelectHandler(serverState))
+	server.Get("/backup", GetBackupsHandler(serverState))
+	server.Post("/backup/create", PostBackupCreateHandler(serverState))
+	server.Post("/backup/validate", PostBackupValidateHandler(serverState))
+	server.Post("/backup/restore", PostBackupRestoreHandler(serverState))
+	server.Post("/dedup/rules", PostDedupRuleCreateHandler(serverState))
+	server.Get("/dedup/rules", GetDedupRulesHandler(serverState))
+	server.Post("/dedup/rules/delete", PostDedupRuleDeleteHandler(serverState))
+	server.Get("/dedup/report", GetDedupReportHandler(serverState))
+	server.Post("/broker/delete", PostBrokerDeleteHandler(serverState))
+	server.Post("/user/delete", PostUserDeleteHandler(serverState))
+	server.Post("/topic/delete", PostTopicDeleteHandler(serverState))
+	server.Post("/message/delete", PostMessageDeleteHandler(serverState))
+
+	addAPIV1Routes(server, serverState)
 }
 
-// | Date of change | By        | Comment               |
-// +----------------+-----------+-----------------------+
-// |                | Polariusz | Created               |
-// | 2025-05-13     | Polariusz | Documentation         |
-// | 2025-06-04     | Polariusz | Integrated DB         |
-// | 2025-06-05     | Polariusz | Updated documentation |
-// | 2025-06-06     | Polariusz | Added auto subs       |
+// | Date of change | By        | Comment                 |
+// +----------------+-----------+-------------------------+
+// |                | Polariusz | Created                 |
+// | 2025-05-13     | Polariusz | Documentation           |
+// | 2025-06-04     | Polariusz | Integrated DB           |
+// | 2025-06-05     | Polariusz | Updated documentation   |
+// | 2025-06-06     | Polariusz | Added auto subs         |
+// | 2026-10-19     | Polariusz | Connects the session    |
+// | 2026-10-19     | Polariusz | Roles                   |
+// | 2026-10-19     | Polariusz | Audit log               |
+// | 2026-10-19     | Polariusz | Shared client options   |
+// | 2026-10-19     | Polariusz | Keeps userCreds         |
+// | 2026-10-19     | Polariusz | cancels the replay jobs |
 //
 // # Method-Type
 // - Handler
@@ -222,6 +503,11 @@ func addRoutes(server *fiber.App, serverState *ServerState) {
 // - The method shall return a 200 (Ok) if credentials are valid and the connection with the MQTT-Broker was estabilished.
 // - The method shall return a 400 (Bad Request) if the data from the client does not match that one of the struct MqttCredentials.
 // - The method shall return a 404 (Service Unavailable) if the connection to the MQTT-Broker failed.
+// - The method shall remember the broker and user in the session of the request, the other handlers act as them, see resolveBrokerUser().
+// - The method shall need a role on a known broker, and the role admin on all brokers for a new one, see authorizeConnection().
+// - The method shall write the action to the audit log, see AuditLog.
+// - The method shall cancel the replay jobs before the client is replaced, as they publish through the old one, see ReplayJob.
+// - The options of the MQTT-Client come from mqttClientOptions(), the Username and Password are sent to the broker.
 //
 // # Usage
 // - Call declared by the routing method addRoutes() URL with the POST-Method.
@@ -236,6 +522,8 @@ func addRoutes(server *fiber.App, serverState *ServerState) {
 // - 400 (Bad Request): JSON
 //   - {"badJson":`const BADJSON`}
 //   - {"badJson":`errorMessage`}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
 // - 404 (Not Found): JSON
 //   - {"badMqtt":"Connecting to `Ip`:`Port` failed"}
 // - 500 (Internal Server Error): JSON
@@ -247,6 +535,9 @@ func addRoutes(server *fiber.App, serverState *ServerState) {
 // - Polariusz
 func PostCredentialsHandler(serverState *ServerState) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_CONNECT}
+		defer serverState.auditLog.record(c, &record)
+
 
 		var userCreds MqttCredentials
 
@@ -265,15 +556,23 @@ func PostCredentialsHandler(serverState *ServerState) fiber.Handler {
 			}
 		}
 
+		// Skipping err, as this should be validated in the validation function.
+		port, _ := strconv.Atoi(userCreds.Port)
+		if ok, err := authorizeConnection(c, serverState, userCreds.Ip, port); !ok {
+			return err
+		}
+
+		// The replay jobs publish through the client that is replaced here.
+		serverState.replayManager.cancelAll()
+
 		// It it is connected, disconnect first!
 		if serverState.mqttClient != nil && serverState.mqttClient.IsConnected() {
 			serverState.mqttClient.Disconnect(250)
 		}
+		serverState.userCreds = MqttCredentials{}
 
 		// NOTE: I do this before to get the brokerId for the createMessageHandler.
-		// Skipping err, as this should be validated in the validation function.
-		port, _ := strconv.Atoi(userCreds.Port)
-		brokerId, err := database.InsertNewBroker(serverState.con, database.InsertBroker{userCreds.Ip, port})
+		brokerId, err := serverState.store.InsertNewBroker(database.InsertBroker{Ip: userCreds.Ip, Port: port})
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"InternalServerError" : "Error while inserting in the Broker table",
@@ -281,14 +580,14 @@ func PostCredentialsHandler(serverState *ServerState) fiber.Handler {
 			})
 		}
 
+		record.BrokerId = brokerId
+
 		// test.mosquitto.org
-		mqttOpts := mqtt.NewClientOptions().AddBroker(fmt.Sprintf("tcp://%s:%s", userCreds.Ip, userCreds.Port)).SetClientID(userCreds.ClientId)
-		mqttOpts.SetKeepAlive(2 * time.Second)
-		mqttOpts.SetPingTimeout(1 * time.Second)
+		mqttOpts := mqttClientOptions(userCreds)
 
 		mqttOpts.SetDefaultPublishHandler(createMessageHandler(serverState, brokerId))
 
-		mqttClient := mqtt.NewClient(mqttOpts)
+		mqttClient := newMqttClient(mqttOpts)
 		serverState.mqttClient = mqttClient
 
 		if token := mqttClient.Connect(); token.Wait() && token.Error() != nil {
@@ -296,16 +595,23 @@ func PostCredentialsHandler(serverState *ServerState) fiber.Handler {
 				"badJson": fmt.Sprintf("Connecting to %s:%s failed\n%s", userCreds.Ip, userCreds.Port, token.Error()),
 			})
 		}
+		// The requests, probes and benchmarks connect with them too.
+		serverState.userCreds = userCreds
 
-		userId, err := database.InsertNewUser(serverState.con, database.InsertUser{brokerId, userCreds.ClientId, userCreds.Username, userCreds.Password, false})
+		userId, err := serverState.store.InsertNewUser(database.InsertUser{BrokerId: brokerId, ClientId: userCreds.ClientId, Username: userCreds.Username, Password: userCreds.Password, Outsider: false})
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"InternalServerError" : "Error while inserting in the User table",
 				"Error" : err.Error(),
 			})
 		}
+		serverState.connected = BrokerUser{brokerId, userId}
+		record.about(serverState.connected)
+		if session := sessionOf(c); session != nil {
+			serverState.accounts.setConnection(session, serverState.connected)
+		}
 
-		topicList, err := database.SelectSubscribedTopics(serverState.con, brokerId, userId)
+		topicList, err := serverState.store.SelectSubscribedTopics(brokerId, userId)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"InternalServerError" : "Error while selecting subscribed topics",
@@ -315,7 +621,7 @@ func PostCredentialsHandler(serverState *ServerState) fiber.Handler {
 		
 		for _, topicToSub := range topicList {
 			if token := serverState.mqttClient.Subscribe(topicToSub.Topic, 0, nil); token.Wait() && token.Error() != nil {
-				fmt.Printf("ERROR: Subscribtion to topic %s failed!\n", topicToSub)
+				fmt.Printf("ERROR: Subscribtion to topic %s failed!\n", topicToSub.Topic)
 			}
 		}
 
@@ -445,12 +751,15 @@ type TopicResult struct {
 	Message string
 }
 
-// | Date of change | By        | Comment                |
-// +----------------+-----------+------------------------+
-// |                | Polariusz | Created                |
-// | 2025-05-13     | Polariusz | Documentation          |
-// | 2025-05-16     | Polariusz | Changed one 400 to 207 |
-// | 2025-06-06     | Polariusz | Integrated Database    |
+// | Date of change | By        | Comment                   |
+// +----------------+-----------+---------------------------+
+// |                | Polariusz | Created                   |
+// | 2025-05-13     | Polariusz | Documentation             |
+// | 2025-05-16     | Polariusz | Changed one 400 to 207    |
+// | 2025-06-06     | Polariusz | Integrated Database       |
+// | 2026-10-19     | Polariusz | Identity from the session |
+// | 2026-10-19     | Polariusz | Roles                     |
+// | 2026-10-19     | Polariusz | Audit log                 |
 //
 // # Method-Type
 // - Handler
@@ -461,6 +770,9 @@ type TopicResult struct {
 // - The method shall return a 200 (Ok) if all requested topics were subscribed.
 // - The method shall return a 207 (Multi Status) if at least one topic was not subscribed.
 // - The method shall return a 400 (Bad Request) if the data from the client does not match that one fo the struct TopicsWrapper.
+// - The method shall take the broker and user from the session when the request has one.
+// - The method shall need the role operator for every topic.
+// - The method shall write the action to the audit log, see AuditLog.
 //
 // # Usage
 //
@@ -477,6 +789,8 @@ type TopicResult struct {
 //   - {"terribleJson":"<Arguments are not valid>"}
 // - 401 (Unauthorized): JSON
 //   - {"Unauthorized":"The MQTT-Client is not connected to any brokers."}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
 // - 500 (Internal Server Error): JSON
 //   - {"InternalServerError":"Error while selecting topics from the database","Error":"<E>"}
 //     - <E> : SQL-Error message
@@ -485,6 +799,9 @@ type TopicResult struct {
 // - Polariusz
 func PostTopicSubscribeHandler(serverState *ServerState) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_SUBSCRIBE}
+		defer serverState.auditLog.record(c, &record)
+
 		if serverState.mqttClient == nil || !serverState.mqttClient.IsConnected() {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"Unauthorized": "The MQTT-Client is not connected to any brokers.",
@@ -499,13 +816,24 @@ func PostTopicSubscribeHandler(serverState *ServerState) fiber.Handler {
 			})
 		}
 
+		resolved, ok, err := resolveBrokerUser(c, serverState, subscribeTopics.BrokerUserIDs, true)
+		if !ok {
+			return err
+		}
+		subscribeTopics.BrokerUserIDs = resolved
+		record.about(subscribeTopics.BrokerUserIDs, subscribeTopics.Topics...)
+
 		if subscribeTopics.BrokerUserIDs.BrokerId <= 0 || subscribeTopics.BrokerUserIDs.UserId <= 0 {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"terribleJson": "Arguments are not valid",
 			})
 		}
 
-		dbTopicList, err := database.SelectTopicsByBrokerId(serverState.con, subscribeTopics.BrokerUserIDs.BrokerId)
+		if ok, err := authorize(c, serverState, ROLE_OPERATOR, subscribeTopics.BrokerUserIDs.BrokerId, subscribeTopics.Topics...); !ok {
+			return err
+		}
+
+		dbTopicList, err := serverState.store.SelectTopicsByBrokerId(subscribeTopics.BrokerUserIDs.BrokerId)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"InternalServerError": "Error while selecting topics from the database",
@@ -513,7 +841,7 @@ func PostTopicSubscribeHandler(serverState *ServerState) fiber.Handler {
 			})
 		}
 
-		dbSubscribedTopicList, err := database.SelectSubscribedTopics(serverState.con, subscribeTopics.BrokerUserIDs.BrokerId, subscribeTopics.BrokerUserIDs.UserId)
+		dbSubscribedTopicList, err := serverState.store.SelectSubscribedTopics(subscribeTopics.BrokerUserIDs.BrokerId, subscribeTopics.BrokerUserIDs.UserId)
 
 		topicResult := make(map[string]TopicResult)
 		atLeastOneBadTopic := false
@@ -545,7 +873,7 @@ func PostTopicSubscribeHandler(serverState *ServerState) fiber.Handler {
 					continue
 				}
 				// INSERT TO TOPIC
-				topicId, err := database.InsertNewTopic(serverState.con, database.InsertTopic{subscribeTopics.BrokerUserIDs.BrokerId, toSubTopic})
+				topicId, err := serverState.store.InsertNewTopic(database.InsertTopic{BrokerId: subscribeTopics.BrokerUserIDs.BrokerId, Topic: toSubTopic})
 				if err != nil {
 					fmt.Printf("Error in InsertNewTopic\n")
 					atLeastOneBadTopic = true
@@ -553,7 +881,7 @@ func PostTopicSubscribeHandler(serverState *ServerState) fiber.Handler {
 					continue
 				}
 				// INSERT TO USERTOPICSUBSCRIBED
-				if err := database.SubscribeTopic(serverState.con, subscribeTopics.BrokerUserIDs.BrokerId, subscribeTopics.BrokerUserIDs.UserId, topicId); err != nil {
+				if err := serverState.store.SubscribeTopic(subscribeTopics.BrokerUserIDs.BrokerId, subscribeTopics.BrokerUserIDs.UserId, topicId); err != nil {
 					fmt.Printf("Error in SubscribeTopic\n")
 					atLeastOneBadTopic = true
 					topicResult[toSubTopic] = TopicResult{"BigError", err.Error()}
@@ -568,7 +896,7 @@ func PostTopicSubscribeHandler(serverState *ServerState) fiber.Handler {
 					continue
 				}
 				// INSERT TO USERTOPICSUBSCRIBED
-				err := database.SubscribeTopic(serverState.con, subscribeTopics.BrokerUserIDs.BrokerId, subscribeTopics.BrokerUserIDs.UserId, knownTopicId)
+				err := serverState.store.SubscribeTopic(subscribeTopics.BrokerUserIDs.BrokerId, subscribeTopics.BrokerUserIDs.UserId, knownTopicId)
 				if err != nil {
 					fmt.Printf("Error in SubscribeTopic\n")
 					atLeastOneBadTopic = true
@@ -595,13 +923,16 @@ func PostTopicSubscribeHandler(serverState *ServerState) fiber.Handler {
 	}
 }
 
-// | Date of change | By        | Comment                |
-// +----------------+-----------+------------------------+
-// |                | Polariusz | Created                |
-// | 2025-05-13     | Polariusz | Documentation          |
-// | 2025-05-16     | Polariusz | Changed one 400 to 207 |
-// | 2025-06-05     | Polariusz | Integrated database    |
-// | 2025-06-07     | Polariusz | UserTopicSubscribed    |
+// | Date of change | By        | Comment                   |
+// +----------------+-----------+---------------------------+
+// |                | Polariusz | Created                   |
+// | 2025-05-13     | Polariusz | Documentation             |
+// | 2025-05-16     | Polariusz | Changed one 400 to 207    |
+// | 2025-06-05     | Polariusz | Integrated database       |
+// | 2025-06-07     | Polariusz | UserTopicSubscribed       |
+// | 2026-10-19     | Polariusz | Identity from the session |
+// | 2026-10-19     | Polariusz | Roles                     |
+// | 2026-10-19     | Polariusz | Audit log                 |
 //
 // # Method-Type
 // - Handler
@@ -612,6 +943,9 @@ func PostTopicSubscribeHandler(serverState *ServerState) fiber.Handler {
 // - The method shall return a 200 (Ok) if all the topics from the converted to type TopicsWrapper have been successfully unsubscribed.
 // - The method shall return a 207 (Multi Status) if at least one topic could not be unsubscribed from.
 // - The method shall return a 400 (Bad Request) if the data from the client does not match that one fo the struct TopicsWrapper.
+// - The method shall take the broker and user from the session when the request has one.
+// - The method shall need the role operator for every topic.
+// - The method shall write the action to the audit log, see AuditLog.
 //
 // # Usage
 //
@@ -627,6 +961,8 @@ func PostTopicSubscribeHandler(serverState *ServerState) fiber.Handler {
 //   - {"badJson":`const BADJSON`}
 // - 401 (Unauthorized): JSON
 //   - {"401":"You fool!"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
 // - 500 (Internal Server Error): JSON
 //   - {"InternalServerError":"Error while selecting topics from database","Error":"<SQL-ERROR-MESSAGE>"}
 //
@@ -634,6 +970,9 @@ func PostTopicSubscribeHandler(serverState *ServerState) fiber.Handler {
 // - Polariusz
 func PostTopicUnsubscribeHandler(serverState *ServerState) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_UNSUBSCRIBE}
+		defer serverState.auditLog.record(c, &record)
+
 		if serverState.mqttClient == nil || !serverState.mqttClient.IsConnected() {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"Unauthorized": "The MQTT-Client is not connected with the Broker.",
@@ -648,16 +987,27 @@ func PostTopicUnsubscribeHandler(serverState *ServerState) fiber.Handler {
 			})
 		}
 
+		resolved, ok, err := resolveBrokerUser(c, serverState, unsubscribeTopics.BrokerUserIDs, true)
+		if !ok {
+			return err
+		}
+		unsubscribeTopics.BrokerUserIDs = resolved
+		record.about(unsubscribeTopics.BrokerUserIDs, unsubscribeTopics.Topics...)
+
 		if unsubscribeTopics.BrokerUserIDs.BrokerId <= 0 || unsubscribeTopics.BrokerUserIDs.UserId <= 0 {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"terribleJson": "Arguments are not valid",
 			})
 		}
 
+		if ok, err := authorize(c, serverState, ROLE_OPERATOR, unsubscribeTopics.BrokerUserIDs.BrokerId, unsubscribeTopics.Topics...); !ok {
+			return err
+		}
+
 		topicResult := make(map[string]TopicResult)
 		atLeastOneBadTopic := false
 
-		dbSubscribedTopicList, err := database.SelectSubscribedTopics(serverState.con, unsubscribeTopics.BrokerUserIDs.BrokerId, unsubscribeTopics.BrokerUserIDs.UserId)
+		dbSubscribedTopicList, err := serverState.store.SelectSubscribedTopics(unsubscribeTopics.BrokerUserIDs.BrokerId, unsubscribeTopics.BrokerUserIDs.UserId)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"InternalServerError" : "Error while selecting topics from database",
@@ -670,7 +1020,7 @@ func PostTopicUnsubscribeHandler(serverState *ServerState) fiber.Handler {
 			for _, subTopic := range dbSubscribedTopicList {
 				if toUnsubTopic == subTopic.Topic {
 					isSubscribed = true
-					if err := database.UnsubscribeTopic(serverState.con, unsubscribeTopics.BrokerUserIDs.BrokerId, unsubscribeTopics.BrokerUserIDs.UserId, subTopic.TopicId); err != nil {
+					if err := serverState.store.UnsubscribeTopic(unsubscribeTopics.BrokerUserIDs.BrokerId, unsubscribeTopics.BrokerUserIDs.UserId, subTopic.TopicId); err != nil {
 						atLeastOneBadTopic = true
 						topicResult[toUnsubTopic] = TopicResult{"BigError", err.Error()}
 						continue
@@ -700,11 +1050,13 @@ func PostTopicUnsubscribeHandler(serverState *ServerState) fiber.Handler {
 	}
 }
 
-// | Date of change | By        | Comment       |
-// +----------------+-----------+---------------+
-// |                | Polariusz | Created       |
-// | 2025-05-13     | Polariusz | Documentation |
-// | 2025-06-06     | Polariusz | Integrated DB |
+// | Date of change | By        | Comment                   |
+// +----------------+-----------+---------------------------+
+// |                | Polariusz | Created                   |
+// | 2025-05-13     | Polariusz | Documentation             |
+// | 2025-06-06     | Polariusz | Integrated DB             |
+// | 2026-10-19     | Polariusz | Identity from the session |
+// | 2026-10-19     | Polariusz | Roles                     |
 //
 // # Method-Type
 // - Handler
@@ -713,6 +1065,8 @@ func PostTopicUnsubscribeHandler(serverState *ServerState) fiber.Handler {
 // - The method shall be a handler that allows to get a list of subscribed topics. These topics will be strings or simply string array.
 // - The method shall return a 200 (Ok) with the subscribed topics.
 // - The method shall accept a jsonified structure that follows the struct BrokerUser.
+// - The method shall take the broker and user from the session when the request has one.
+// - The method shall leave out the topics that the role viewer is not given for.
 //
 // # Usage
 // - Call declared by the routing method addRoutes() URL with the GET-Method.
@@ -725,6 +1079,8 @@ func PostTopicUnsubscribeHandler(serverState *ServerState) fiber.Handler {
 // - 401 (Unauthorized): JSON
 //   - The go server was never connected to the MQTT-Broker.
 //   - {"Unauthorized":"The MQTT-Client is not connected to any brokers."}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
 // - 500 (Internal Server Error): JSON
 //   - {"InternalServerError":"Error while selecting subscribed topics from database","Error":"<SQL-ERROR-MESSAGE>"}
 //
@@ -746,13 +1102,24 @@ func GetTopicSubscribedHandler(serverState *ServerState) fiber.Handler {
 			})
 		}
 
+		resolved, ok, err := resolveBrokerUser(c, serverState, brokerUser, false)
+		if !ok {
+			return err
+		}
+		brokerUser = resolved
+
 		if brokerUser.BrokerId <= 0 || brokerUser.UserId <= 0 {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"terribleJson": "Arguments are not valid",
 			})
 		}
 
-		topicList, err := database.SelectSubscribedTopics(serverState.con, brokerUser.BrokerId, brokerUser.UserId)
+		permissions, ok, err := authorizeAny(c, serverState, ROLE_VIEWER, brokerUser.BrokerId)
+		if !ok {
+			return err
+		}
+
+		topicList, err := serverState.store.SelectSubscribedTopics(brokerUser.BrokerId, brokerUser.UserId)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"InternalServerError" : "Error while selecting subscribed topics from database",
@@ -760,8 +1127,15 @@ func GetTopicSubscribedHandler(serverState *ServerState) fiber.Handler {
 			})
 		}
 
+		visibleTopicList := topicList[:0]
+		for _, topic := range topicList {
+			if permissions.allows(ROLE_VIEWER, brokerUser.BrokerId, topic.Topic) {
+				visibleTopicList = append(visibleTopicList, topic)
+			}
+		}
+
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"topics": topicList,
+			"topics": visibleTopicList,
 		})
 	}
 }
@@ -788,10 +1162,13 @@ type MessageWrapper struct {
 	Message string
 }
 
-// | Date of change | By        | Comment       |
-// +----------------+-----------+---------------+
-// |                | Polariusz | Created       |
-// | 2025-05-13     | Polariusz | Documentation |
+// | Date of change | By        | Comment                   |
+// +----------------+-----------+---------------------------+
+// |                | Polariusz | Created                   |
+// | 2025-05-13     | Polariusz | Documentation             |
+// | 2026-10-19     | Polariusz | Identity from the session |
+// | 2026-10-19     | Polariusz | Roles                     |
+// | 2026-10-19     | Polariusz | Audit log                 |
 //
 // # Method-Type
 // - Handler
@@ -801,6 +1178,9 @@ type MessageWrapper struct {
 // - The method shall accept a jsonified structure that follows the struct MessageWrapper.
 // - The method shall return a 200 (Ok) if the go-server publishes a message.
 // - The method shall return a 400 (Bad Request) if the data from the client does not match that one fo the struct MessageWrapper.
+// - The method shall take the broker and user from the session when the request has one.
+// - The method shall need the role operator for the topic.
+// - The method shall write the action to the audit log with the SHA-256 of the message instead of the message, see AuditLog.
 //
 // # Usage
 // - Call declared by the routing method addRoutes() URL with the POST-Method.
@@ -813,11 +1193,16 @@ type MessageWrapper struct {
 //   - {"badJson":`const BADJSON`}
 // - 401 (Unauthorised): JSON
 //   - {"401":"You fool!"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
 //
 // # Author
 // - Polariusz
 func PostTopicSendMessageHandler(serverState *ServerState) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_PUBLISH}
+		defer serverState.auditLog.record(c, &record)
+
 		if serverState.mqttClient == nil || !serverState.mqttClient.IsConnected() {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"Unauthorized": "The MQTT-Client is not connected to any brokers.",
@@ -832,13 +1217,25 @@ func PostTopicSendMessageHandler(serverState *ServerState) fiber.Handler {
 			})
 		}
 
+		resolved, ok, err := resolveBrokerUser(c, serverState, messageWrapper.BrokerUserIDs, true)
+		if !ok {
+			return err
+		}
+		messageWrapper.BrokerUserIDs = resolved
+		record.about(messageWrapper.BrokerUserIDs, messageWrapper.Topic)
+		record.PayloadHash = hashPayload(messageWrapper.Message)
+
 		if messageWrapper.BrokerUserIDs.BrokerId <= 0 || messageWrapper.BrokerUserIDs.UserId <= 0 {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"terribleJson": "Arguments are not valid",
 			})
 		}
 
-		user, err := database.SelectUserById(serverState.con, messageWrapper.BrokerUserIDs.UserId)
+		if ok, err := authorize(c, serverState, ROLE_OPERATOR, messageWrapper.BrokerUserIDs.BrokerId, messageWrapper.Topic); !ok {
+			return err
+		}
+
+		user, err := serverState.store.SelectUserById(messageWrapper.BrokerUserIDs.UserId)
 		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"Internal Server Error": "Error while selecting User by ID",
@@ -862,6 +1259,7 @@ func PostTopicSendMessageHandler(serverState *ServerState) fiber.Handler {
 // |                | Polariusz | Created                |
 // | 2025-05-13     | Polariusz | Documentation          |
 // | 2025-05-19     | Polariusz | Updated ping behaviour |
+// | 2026-10-19     | Polariusz | 401 before the login   |
 //
 // # Method-Type
 // - Handler
@@ -894,8 +1292,14 @@ func PostTopicSendMessageHandler(serverState *ServerState) fiber.Handler {
 // - Polariusz
 func GetPingHandler(serverState *ServerState) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if serverState.mqttClient == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"Unauthorized": "Authenticate yourself first!",
+			})
+		}
+
 		// Is it connected?
-		if serverState.mqttClient == nil || serverState.mqttClient.IsConnected() {
+		if serverState.mqttClient.IsConnected() {
 			// Is it really connected? (i.e not reconnecting)
 			if serverState.mqttClient.IsConnectionOpen() {
 				return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -919,6 +1323,15 @@ func GetPingHandler(serverState *ServerState) fiber.Handler {
 // +----------------+-----------+-------------------------+
 // | 2025-05-14     | Tibbyx    | Created & Documentation |
 // | 2025-06-06     | Polariusz | Integrated with DB      |
+// | 2026-10-19     | Polariusz | Added stats             |
+// | 2026-10-19     | Polariusz | Cached Topic/User IDs   |
+// | 2026-10-19     | Polariusz | Batched ingest queue    |
+// | 2026-10-19     | Polariusz | Added the MQTT metadata |
+// | 2026-10-19     | Polariusz | Marked duplicates       |
+// | 2026-10-19     | Polariusz | Webhooks                |
+// | 2026-10-19     | Polariusz | Alerts                  |
+// | 2026-10-19     | Polariusz | Skipped while switching |
+// | 2026-10-19     | Polariusz | Probe messages skipped  |
 //
 // # Method-Type
 // - MQTT Handler Factory
@@ -927,6 +1340,16 @@ func GetPingHandler(serverState *ServerState) fiber.Handler {
 // - The method shall create and return an MQTT message handler.
 // - The handler processes incoming MQTT messages from subscribed topics.
 // - The handler uses the JsonPublishString structure for messages
+// - The handler counts the message in the ServerState's stats.
+// - The Topic and User IDs are resolved through the ServerState's idCache, so the database is only queried the first time a topic or publisher is seen.
+//   - Topics received through wildcard subscriptions are inserted into table Topic.
+// - The message is not written directly, but queued in the ServerState's ingestQueue.
+// - The retained and duplicate flags, the packet ID, the size of the payload, the time of receipt and the Timestamp of the envelope are stored with the message.
+// - If a deduplication rule matches the topic, the ServerState's deduplicator decides whether the message is marked as a duplicate. Duplicates are stored too.
+// - The message is handed to the ServerState's webhooks, which send it to every webhook that matches it.
+// - The message is checked against the alert rules by the ServerState's alerts.
+// - While the database is swapped, the message is dropped. The swap disconnects the client and paho waits for this handler, so it must not wait for the lock.
+// - The test messages of a running ACL probe are dropped, see ProbeTraffic.
 //
 // # Usage
 // - Used in PostCredentialsHandler() to assign the MQTT client’s default message handler.
@@ -939,10 +1362,19 @@ func GetPingHandler(serverState *ServerState) fiber.Handler {
 // - Tibbyx
 func createMessageHandler(serverState *ServerState, brokerId int) mqtt.MessageHandler {
 	return func(client mqtt.Client, msg mqtt.Message) {
+		if !serverState.databaseLock.TryRLock() {
+			return
+		}
+		defer serverState.databaseLock.RUnlock()
+
+		receivedAt := time.Now()
 		topic := msg.Topic()
 		payload := msg.Payload()
 		qos := msg.Qos()
-		topicId := -1
+
+		if serverState.probeTraffic.isProbe(topic, payload) {
+			return
+		}
 
 		var jsonPublishMessage JsonPublishMessage
 		if err := json.Unmarshal(payload, &jsonPublishMessage); err != nil {
@@ -950,54 +1382,67 @@ func createMessageHandler(serverState *ServerState, brokerId int) mqtt.MessageHa
 			jsonPublishMessage.Message = string(payload)
 		}
 
-		topicList, err := database.SelectTopicsByBrokerId(serverState.con, brokerId)
+		topicId, err := serverState.idCache.ResolveTopicId(serverState.store, brokerId, topic)
 		if err != nil {
-			fmt.Printf("Error while selecting topics by broker id and user id\nError: %s\n", err)
+			fmt.Printf("Error while resolving the topic id\nError: %s\n", err)
 			return
 		}
-		for _, dbTopic := range topicList {
-			if dbTopic.Topic == topic {
-				topicId = dbTopic.Id
-			}
-		}
-		// NOTE: The TopicId shouldn't be -1 still, because we only get messages with targetted topic when we are subscribed to these topics, so we know these topics!
 
-		var userId int
-		user, err := database.SelectUserByClientIdAndBrokerId(serverState.con, jsonPublishMessage.ClientId, brokerId)
+		user, err := serverState.idCache.ResolveUser(serverState.store, brokerId, jsonPublishMessage.ClientId)
 		if err != nil {
-			// No user found! Outsider!
-			outsiderUserId, err := database.InsertNewUser(serverState.con, database.InsertUser{brokerId, jsonPublishMessage.ClientId, "", "", true})
-			if err != nil {
-				fmt.Printf("Error while inserting outsider.\nError: %s\n", err)
-				return
-			}
-			userId = outsiderUserId
-		} else {
-			userId = user.Id
-		}
-
-		insertNewMessage := database.InsertMessage{userId, topicId, brokerId, qos, jsonPublishMessage.Message}
-
-		fmt.Printf("Inserting into Message with arguments: %s", insertNewMessage)
-
-		if err := database.InsertNewMessage(serverState.con, insertNewMessage); err != nil {
-			// db error
-			fmt.Printf("Error while inserting new message\nError: %s\n", err)
+			fmt.Printf("Error while resolving the user id\nError: %s\n", err)
 			return
 		}
+		userId := user.Id
+		outsider := user.Outsider
+
+		serverState.stats.record(brokerId, topic, userId, jsonPublishMessage.ClientId, outsider, len(payload), receivedAt)
+
+		insertNewMessage := database.InsertMessage{
+			UserId: userId,
+			TopicId: topicId,
+			BrokerId: brokerId,
+			QoS: qos,
+			Message: jsonPublishMessage.Message,
+			CreationDate: receivedAt,
+			Retained: msg.Retained(),
+			Duplicate: msg.Duplicate(),
+			PacketId: int(msg.MessageID()),
+			PayloadSize: len(payload),
+			ReceivedAt: receivedAt,
+			EnvelopeDate: jsonPublishMessage.timestamp(),
+		}
+		insertNewMessage.IsDuplicate = serverState.deduplicator.check(brokerId, topic, insertNewMessage, payload)
+
+		// The ingest queue writes the message in a batch, so paho's router isn't blocked by the database.
+		serverState.ingestQueue.enqueue(insertNewMessage)
+
+		receivedMessage := ReceivedMessage{
+			BrokerId: brokerId,
+			Topic: topic,
+			ClientId: jsonPublishMessage.ClientId,
+			Message: jsonPublishMessage.Message,
+			QoS: qos,
+			Retained: msg.Retained(),
+			ReceivedAt: receivedAt,
+		}
+		serverState.webhooks.dispatch(receivedMessage)
+		serverState.alerts.observe(receivedMessage)
 	}
 }
 
-// | Date of change | By        | Comment |
-// +----------------+-----------+---------+
-// | 2025-06-06     | Polariusz | Created |
+// | Date of change | By        | Comment              |
+// +----------------+-----------+----------------------+
+// | 2025-06-06     | Polariusz | Created              |
+// | 2026-10-19     | Polariusz | Added HideDuplicates |
 //
 // # Structure:
-// - {"BrokerUserIDs":{"BrokerId":<B>, "UserId":<U>},"Topics":"<T>","Index":<I>}
+// - {"BrokerUserIDs":{"BrokerId":<B>, "UserId":<U>},"Topics":"<T>","Index":<I>,"HideDuplicates":<H>}
 //   - <B> : The ID of the Broker ROW matched from the BrokerId from PostCredentialsHandler()'s brokerId
 //   - <U> : The ID of the User ROW matched from the BrokerId from PostCredentialsHandler()'s brokerId
 //   - <T> : The Topic
 //   - <I> : The query index, Select from `database.LIMIT_MESSAGES*index` to `database.LIMIT_MESSAGES*(1+index)` messages. If is less than 0, it will query all messages.
+//   - <H> : Optional, if true the messages that are marked as duplicates are left out. They are left out before the page is cut, so the pages stay full.
 //
 // # Used in
 // - GetTopicMessagesHandler()
@@ -1008,12 +1453,15 @@ type TopicWrapper struct {
 	BrokerUserIDs BrokerUser
 	Topic string
 	Index int
+	HideDuplicates bool
 }
 
-// | Date of change | By        | Comment                 |
-// +----------------+-----------+-------------------------+
-// | 2025-05-14     | Tibbyx    | Created & Documentation |
-// | 2025-06-06     | Polariusz | Integrated with DB      |
+// | Date of change | By        | Comment                   |
+// +----------------+-----------+---------------------------+
+// | 2025-05-14     | Tibbyx    | Created & Documentation   |
+// | 2025-06-06     | Polariusz | Integrated with DB        |
+// | 2026-10-19     | Polariusz | Identity from the session |
+// | 2026-10-19     | Polariusz | Roles                     |
 //
 // # Method-Type
 // - Handler
@@ -1022,6 +1470,8 @@ type TopicWrapper struct {
 // - The method shall be a handler that returns all stored messages for a specific topic.
 // - The messages must have previously been received through an active MQTT subscription.
 // - The topic is provided in the --data JSON
+// - The method shall take the broker and user from the session when the request has one.
+// - The method shall need the role viewer for the topic.
 //
 // # Usage
 // - Call declared by the routing method addRoutes() URL with the GET-Method.
@@ -1036,6 +1486,8 @@ type TopicWrapper struct {
 //   - {"terribleJson":"The argument `Topic` does not match the database."}
 // - 401 (Unauthorized): JSON
 //   - {"Unauthorized": "The MQTT-Client is not connected to any brokers."}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
 // - 500 (Internal Server Error): JSON
 //   - {"InternalServerError":"Error while <Where> <Arguments>", "Error":"<SQL-Error>"}
 //
@@ -1054,14 +1506,24 @@ func GetTopicMessagesHandler(serverState *ServerState) fiber.Handler {
 				"badJson": BADJSON,
 			})
 		}
+
+		resolved, ok, err := resolveBrokerUser(c, serverState, topicWrapper.BrokerUserIDs, false)
+		if !ok {
+			return err
+		}
+		topicWrapper.BrokerUserIDs = resolved
 		if topicWrapper.BrokerUserIDs.BrokerId <= 0 || topicWrapper.BrokerUserIDs.UserId <= 0 || topicWrapper.Topic == "" {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"terribleJson": "The arguments in the json structure are missing",
 			})
 		}
 
+		if ok, err := authorize(c, serverState, ROLE_VIEWER, topicWrapper.BrokerUserIDs.BrokerId, topicWrapper.Topic); !ok {
+			return err
+		}
+
 		topicId := -1
-		topicList, err := database.SelectTopicsByBrokerId(serverState.con, topicWrapper.BrokerUserIDs.BrokerId)
+		topicList, err := serverState.store.SelectTopicsByBrokerId(topicWrapper.BrokerUserIDs.BrokerId)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"InternalServerError" : "Error while selecting topics matched with broker and user id",
@@ -1082,7 +1544,7 @@ func GetTopicMessagesHandler(serverState *ServerState) fiber.Handler {
 
 		var messageList []database.SelectMessage
 		if topicWrapper.Index < 0 {
-			messageList, err = database.SelectMessagesByTopicIdAndBrokerId(serverState.con, topicId, topicWrapper.BrokerUserIDs.BrokerId)
+			messageList, err = serverState.store.SelectMessagesByTopicIdAndBrokerId(topicId, topicWrapper.BrokerUserIDs.BrokerId, topicWrapper.HideDuplicates)
 			if err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 					"InternalServerError" : "Error while selecting messages matched with topic and broker",
@@ -1090,7 +1552,7 @@ func GetTopicMessagesHandler(serverState *ServerState) fiber.Handler {
 				})
 			}
 		} else {
-			messageList, err = database.SelectMessagesByTopicIdBrokerIdAndIndex(serverState.con, topicId, topicWrapper.BrokerUserIDs.BrokerId, topicWrapper.Index)
+			messageList, err = serverState.store.SelectMessagesByTopicIdBrokerIdAndIndex(topicId, topicWrapper.BrokerUserIDs.BrokerId, topicWrapper.Index, topicWrapper.HideDuplicates)
 			if err != nil {
 				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 					"InternalServerError" : "Error while selecting messages matched with topic, broker and index",
@@ -1110,16 +1572,18 @@ func GetTopicMessagesHandler(serverState *ServerState) fiber.Handler {
 	}
 }
 
-// | Date of change | By        | Comment |
-// +----------------+-----------+---------+
-// | 2025-06-07     | Polariusz | Created |
+// | Date of change | By        | Comment              |
+// +----------------+-----------+----------------------+
+// | 2025-06-07     | Polariusz | Created              |
+// | 2026-10-19     | Polariusz | Added HideDuplicates |
 //
 // # Structure:
-// - {"BrokerUserIDs":{"BrokerId":<B>,"UserId":<U>},"Topic":"<T>","TimeFrom":"<D>"}
+// - {"BrokerUserIDs":{"BrokerId":<B>,"UserId":<U>},"Topic":"<T>","TimeFrom":"<D>","HideDuplicates":<H>}
 //   - <B> : The ID of the Broker ROW matched from the BrokerId from PostCredentialsHandler()'s brokerId
 //   - <U> : The ID of the User ROW matched from the BrokerId from PostCredentialsHandler()'s brokerId
 //   - <T> : Topic name
 //   - <D> : DateTime
+//   - <H> : Optional, if true the messages that are marked as duplicates are left out
 //
 // # Used in
 // - type TopicsWrapper struct
@@ -1130,17 +1594,22 @@ type GetNewMessages struct {
 	BrokerUserIDs BrokerUser
 	Topic string
 	TimeFrom time.Time
+	HideDuplicates bool
 }
 
-// | Date of change | By        | Comment |
-// +----------------+-----------+---------+
-// | 2025-06-07     | Polariusz | Created |
+// | Date of change | By        | Comment                   |
+// +----------------+-----------+---------------------------+
+// | 2025-06-07     | Polariusz | Created                   |
+// | 2026-10-19     | Polariusz | Identity from the session |
+// | 2026-10-19     | Polariusz | Roles                     |
 //
 // # Method-Type
 // - Handler
 //
 // # Description
 // - The method shall be a handler that returns all stored messages for a specific topic since the argument given TimeFrom.
+// - The method shall take the broker and user from the session when the request has one.
+// - The method shall need the role viewer for the topic.
 //
 // # Usage
 // - Call declared by the routing method addRoutes() URL with the GET-Method.
@@ -1155,6 +1624,8 @@ type GetNewMessages struct {
 //   - {"badTopic":"Topic '<TOPIC>' is not known"}
 // - 401 (Unauthorized): JSON
 //   - {"Unauthorized": "The MQTT-Client is not connected to any brokers"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
 // - 500 (Internal Server Error): JSON
 //   - {"InternalServerError":"Error while selecting topics matched with broker id","Error":"<SQL-ERROR>"}
 //   - {"InternalServerError":"Error while selecting messages matched with broker id, topic id and datetime","Error":"<SQL-ERROR>"}
@@ -1175,13 +1646,23 @@ func GetTopicNewMessagesHandler(serverState *ServerState) fiber.Handler {
 				"badJson": BADJSON,
 			})
 		}
+
+		resolved, ok, err := resolveBrokerUser(c, serverState, getNewMessages.BrokerUserIDs, false)
+		if !ok {
+			return err
+		}
+		getNewMessages.BrokerUserIDs = resolved
 		if getNewMessages.BrokerUserIDs.BrokerId <= 0 || getNewMessages.BrokerUserIDs.UserId <= 0 || getNewMessages.Topic == "" {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"terribleJson": "The arguments in the json structure are missing",
 			})
 		}
 
-		dbTopicList, err := database.SelectTopicsByBrokerId(serverState.con, getNewMessages.BrokerUserIDs.BrokerId)
+		if ok, err := authorize(c, serverState, ROLE_VIEWER, getNewMessages.BrokerUserIDs.BrokerId, getNewMessages.Topic); !ok {
+			return err
+		}
+
+		dbTopicList, err := serverState.store.SelectTopicsByBrokerId(getNewMessages.BrokerUserIDs.BrokerId)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"InternalServerError" : "Error while selecting topics matched with broker id",
@@ -1203,7 +1684,7 @@ func GetTopicNewMessagesHandler(serverState *ServerState) fiber.Handler {
 			})
 		}
 
-		newMessageList, err := database.SelectMessagesByBrokerIdTopicIdAndDatetime(serverState.con, getNewMessages.BrokerUserIDs.BrokerId, topicId, getNewMessages.TimeFrom)
+		newMessageList, err := serverState.store.SelectMessagesByBrokerIdTopicIdAndDatetime(getNewMessages.BrokerUserIDs.BrokerId, topicId, getNewMessages.TimeFrom, getNewMessages.HideDuplicates)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"InternalServerError" : "Error while selecting messages matched with broker id, topic id and datetime",
@@ -1218,10 +1699,12 @@ func GetTopicNewMessagesHandler(serverState *ServerState) fiber.Handler {
 	}
 }
 
-// | Date of change | By        | Comment       |
-// +----------------+-----------+---------------|
-// | 2025-05-16     | Polariusz | Created       |
-// | 2025-06-07     | Polariusz | Integrated DB |
+// | Date of change | By        | Comment                   |
+// +----------------+-----------+---------------------------+
+// | 2025-05-16     | Polariusz | Created                   |
+// | 2025-06-07     | Polariusz | Integrated DB             |
+// | 2026-10-19     | Polariusz | Identity from the session |
+// | 2026-10-19     | Polariusz | Roles                     |
 //
 // # Method-Type
 // - Handler
@@ -1230,6 +1713,8 @@ func GetTopicNewMessagesHandler(serverState *ServerState) fiber.Handler {
 // - The method shall return a list known topics.
 // - The method shall return a 200 (Ok) with the list if user is authenticated
 // - The method shall return a 401 (Unauthorized) if the user is not authenticated
+// - The method shall take the broker and user from the session when the request has one.
+// - The method shall leave out the topics that the role viewer is not given for.
 //
 // # Usage
 // - Call declared by the routing method addRoutes() URL with the GET-Method.
@@ -1242,6 +1727,8 @@ func GetTopicNewMessagesHandler(serverState *ServerState) fiber.Handler {
 //   - {"badJson":`const BADJSON`}
 // - 401 (Unauthorized): JSON
 //   - {"Unauthorized":"The MQTT-Client is not connected to any brokers"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
 // - 500 (Internal Server Error): JSON
 //   - {"InternalServerError":"<SQL-ERROR>"}
 //
@@ -1264,28 +1751,48 @@ func GetTopicAllKnownHandler(serverState *ServerState) fiber.Handler {
 			})
 		}
 
+		resolved, ok, err := resolveBrokerUser(c, serverState, brokerUser, false)
+		if !ok {
+			return err
+		}
+		brokerUser = resolved
+
 		if brokerUser.BrokerId <= 0 || brokerUser.UserId <= 0 {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"terribleJson": "Arguments are not valid",
 			})
 		}
 
-		topicList, err := database.SelectTopicsByBrokerId(serverState.con, brokerUser.BrokerId)
+		permissions, ok, err := authorizeAny(c, serverState, ROLE_VIEWER, brokerUser.BrokerId)
+		if !ok {
+			return err
+		}
+
+		topicList, err := serverState.store.SelectTopicsByBrokerId(brokerUser.BrokerId)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"InternalServerError": err.Error(),
 			})
 		}
 
+		visibleTopicList := topicList[:0]
+		for _, topic := range topicList {
+			if permissions.allows(ROLE_VIEWER, brokerUser.BrokerId, topic.Topic) {
+				visibleTopicList = append(visibleTopicList, topic)
+			}
+		}
+
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"Topics": topicList,
+			"Topics": visibleTopicList,
 		})
 	}
 }
 
-// | Date of change | By        | Comment |
-// +----------------+-----------+---------+
-// | 2025-06-08     | Polariusz | Created |
+// | Date of change | By        | Comment                   |
+// +----------------+-----------+---------------------------+
+// | 2025-06-08     | Polariusz | Created                   |
+// | 2026-10-19     | Polariusz | Identity from the session |
+// | 2026-10-19     | Polariusz | Roles                     |
 //
 // # Method-Type
 // - Handler
@@ -1294,6 +1801,8 @@ func GetTopicAllKnownHandler(serverState *ServerState) fiber.Handler {
 // - The method shall return a list of all known topics and if these are subscribed or not
 // - The method shall return a 200 (Ok) with the list if user is authenticated
 // - The method shall return a 401 (Unauthorized) if the user is not authenticated
+// - The method shall take the broker and user from the session when the request has one.
+// - The method shall leave out the topics that the role viewer is not given for.
 //
 // # Usage
 // - Call declared by the routing method addRoutes() URL with the GET-Method.
@@ -1306,6 +1815,8 @@ func GetTopicAllKnownHandler(serverState *ServerState) fiber.Handler {
 //   - {"badJson":`const BADJSON`}
 // - 401 (Unauthorized): JSON
 //   - {"Unauthorized":"The MQTT-Client is not connected to any brokers"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
 // - 500 (Internal Server Error): JSON
 //   - {"InternalServerError":"<SQL-ERROR>"}
 //
@@ -1328,21 +1839,39 @@ func PostTopicAllKnownSubscribedHandler(serverState *ServerState) fiber.Handler
 			})
 		}
 
+		resolved, ok, err := resolveBrokerUser(c, serverState, brokerUser, false)
+		if !ok {
+			return err
+		}
+		brokerUser = resolved
+
 		if brokerUser.BrokerId <= 0 || brokerUser.UserId <= 0 {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"terribleJson": "Arguments are not valid",
 			})
 		}
 
-		topicList, err := database.SelectTopicsByBrokerIdAndUserId(serverState.con, brokerUser.BrokerId, brokerUser.UserId)
+		permissions, ok, err := authorizeAny(c, serverState, ROLE_VIEWER, brokerUser.BrokerId)
+		if !ok {
+			return err
+		}
+
+		topicList, err := serverState.store.SelectTopicsByBrokerIdAndUserId(brokerUser.BrokerId, brokerUser.UserId)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"InternalServerError": err.Error(),
 			})
 		}
 
+		visibleTopicList := topicList[:0]
+		for _, topic := range topicList {
+			if permissions.allows(ROLE_VIEWER, brokerUser.BrokerId, topic.Topic) {
+				visibleTopicList = append(visibleTopicList, topic)
+			}
+		}
+
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"Topics": topicList,
+			"Topics": visibleTopicList,
 		})
 	}
 }
@@ -1351,6 +1880,10 @@ func PostTopicAllKnownSubscribedHandler(serverState *ServerState) fiber.Handler
 // +----------------+-----------+--------------------------------+
 // | 2025-05-16     | Polariusz | Created                        |
 // | 2025-06-07     | Polariusz | Changed the connection checker |
+// | 2026-10-19     | Polariusz | Roles                          |
+// | 2026-10-19     | Polariusz | Audit log                      |
+// | 2026-10-19     | Polariusz | Clears userCreds               |
+// | 2026-10-19     | Polariusz | cancels the replay jobs        |
 //
 // # Method-Type
 // - Handler
@@ -1359,6 +1892,9 @@ func PostTopicAllKnownSubscribedHandler(serverState *ServerState) fiber.Handler
 // - The method shall disconnect the from the argument serverstate mqttClient from the MQTT-Broker
 // - The method shall return a 200 (Ok) if the user is authenticated
 // - The method shall return a 401 (Unauthorized) if the user is not authenticated
+// - The method shall need the role operator on the connected broker.
+// - The method shall write the action to the audit log, see AuditLog.
+// - The method shall cancel the replay jobs, as they publish through the disconnected client.
 //
 // # Usage
 // - Call declared by the routing method addRoutes() URL with the GET-Method.
@@ -1368,18 +1904,30 @@ func PostTopicAllKnownSubscribedHandler(serverState *ServerState) fiber.Handler
 //   - {"Fine":"The MQTT-Client disconnected from <IP>:<PORT> Broker"}
 // - 400 (BadRequest): JSON
 //   - {"BadRequest":"The server isn't even connected to any MQTT-Brokers"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
 //
 // # Author
 // - Polariusz
 func PostDisconnectFromBrokerHandler(serverState *ServerState) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_DISCONNECT}
+		defer serverState.auditLog.record(c, &record)
+
 		if serverState.mqttClient == nil || !serverState.mqttClient.IsConnected() {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"BadRequest": "The server isn't even connected to any MQTT-Brokers",
 			})
 		}
 
+		record.about(serverState.connected)
+		if _, ok, err := authorizeAny(c, serverState, ROLE_OPERATOR, serverState.connected.BrokerId); !ok {
+			return err
+		}
+
+		serverState.replayManager.cancelAll()
 		serverState.mqttClient.Disconnect(250)
+		serverState.userCreds = MqttCredentials{}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
 			"Fine": "The MQTT-Client disconnected from the broker.",
@@ -1387,10 +1935,13 @@ func PostDisconnectFromBrokerHandler(serverState *ServerState) fiber.Handler {
 	}
 }
 
-// | Date of change | By        | Comment            |
-// +----------------+-----------+--------------------+
-// | 2025-05-18     | Polariusz | Created            |
-// | 2025-06-07     | Polariusz | Integrated with DB |
+// | Date of change | By        | Comment                   |
+// +----------------+-----------+---------------------------+
+// | 2025-05-18     | Polariusz | Created                   |
+// | 2025-06-07     | Polariusz | Integrated with DB        |
+// | 2026-10-19     | Polariusz | Identity from the session |
+// | 2026-10-19     | Polariusz | Roles                     |
+// | 2026-10-19     | Polariusz | Audit log                 |
 //
 // # Method-Type
 // - Handler
@@ -1398,6 +1949,9 @@ func PostDisconnectFromBrokerHandler(serverState *ServerState) fiber.Handler {
 // # Description
 // - The fiber.Handler shall append a topic to the favourite list.
 // - The function shall accept a json data which contains a list of Topics that the user wishes to mark as favourites.
+// - The method shall take the broker and user from the session when the request has one.
+// - The method shall need the role operator for every topic.
+// - The method shall write the action to the audit log, see AuditLog.
 //
 // # Usage
 // - Call declared by the routing method addRoutes() URL with the GET-Method.
@@ -1413,11 +1967,16 @@ func PostDisconnectFromBrokerHandler(serverState *ServerState) fiber.Handler {
 //   - {"terribleJson": "Arguments are not valid"}
 // - 401 (Unauthorized): JSON
 //   - {"Message": "Authenticate yourself first!"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
 //
 // # Author
 // - Polariusz
 func PostTopicFavouritesMark(serverState *ServerState) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_FAVOURITE_MARK}
+		defer serverState.auditLog.record(c, &record)
+
 		if serverState.mqttClient == nil || !serverState.mqttClient.IsConnected() {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"Message": "Authenticate yourself first!",
@@ -1432,20 +1991,31 @@ func PostTopicFavouritesMark(serverState *ServerState) fiber.Handler {
 			})
 		}
 
+		resolved, ok, err := resolveBrokerUser(c, serverState, markTopics.BrokerUserIDs, false)
+		if !ok {
+			return err
+		}
+		markTopics.BrokerUserIDs = resolved
+		record.about(markTopics.BrokerUserIDs, markTopics.Topics...)
+
 		if markTopics.BrokerUserIDs.BrokerId <= 0 || markTopics.BrokerUserIDs.UserId <= 0 {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"terribleJson": "Arguments are not valid",
 			})
 		}
 
-		favTopicList, err := database.SelectFavouriteTopicsByBrokerIdAndUserId(serverState.con, markTopics.BrokerUserIDs.BrokerId, markTopics.BrokerUserIDs.UserId)
+		if ok, err := authorize(c, serverState, ROLE_OPERATOR, markTopics.BrokerUserIDs.BrokerId, markTopics.Topics...); !ok {
+			return err
+		}
+
+		favTopicList, err := serverState.store.SelectFavouriteTopicsByBrokerIdAndUserId(markTopics.BrokerUserIDs.BrokerId, markTopics.BrokerUserIDs.UserId)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"InternalServerError": err.Error(),
 			})
 		}
 
-		dbTopicList, err := database.SelectTopicsByBrokerId(serverState.con, markTopics.BrokerUserIDs.BrokerId)
+		dbTopicList, err := serverState.store.SelectTopicsByBrokerId(markTopics.BrokerUserIDs.BrokerId)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"InternalServerError": err.Error(),
@@ -1474,7 +2044,7 @@ func PostTopicFavouritesMark(serverState *ServerState) fiber.Handler {
 			for _, dbTopic := range dbTopicList {
 				if markTopic == dbTopic.Topic {
 					isKnown = true
-					if err := database.InsertFavouriteTopic(serverState.con, markTopics.BrokerUserIDs.BrokerId, markTopics.BrokerUserIDs.UserId, dbTopic.Id); err != nil {
+					if err := serverState.store.InsertFavouriteTopic(markTopics.BrokerUserIDs.BrokerId, markTopics.BrokerUserIDs.UserId, dbTopic.Id); err != nil {
 						atLeastOneBadTopic = true
 						topicResult[markTopic] = TopicResult{"ServerError", err.Error()}
 					} else {
@@ -1502,9 +2072,12 @@ func PostTopicFavouritesMark(serverState *ServerState) fiber.Handler {
 	}
 }
 
-// | Date of change | By        | Comment |
-// +----------------+-----------+---------|
-// | 2025-05-18     | Polariusz | Created |
+// | Date of change | By        | Comment                   |
+// +----------------+-----------+---------------------------+
+// | 2025-05-18     | Polariusz | Created                   |
+// | 2026-10-19     | Polariusz | Identity from the session |
+// | 2026-10-19     | Polariusz | Roles                     |
+// | 2026-10-19     | Polariusz | Audit log                 |
 //
 // # Method-Type
 // - Handler
@@ -1512,6 +2085,9 @@ func PostTopicFavouritesMark(serverState *ServerState) fiber.Handler {
 // # Description
 // - The fiber.Handler shall delete a topic from the favourite list.
 // - The function shall accept a json data which contains a list of Topics that the user wishes to unmark from favourites.
+// - The method shall take the broker and user from the session when the request has one.
+// - The method shall need the role operator for every topic.
+// - The method shall write the action to the audit log, see AuditLog.
 //
 // # Usage
 // - Call declared by the routing method addRoutes() URL with the GET-Method.
@@ -1527,6 +2103,8 @@ func PostTopicFavouritesMark(serverState *ServerState) fiber.Handler {
 //   - {"terribleJSON":"Arguments are not valid"}
 // - 401 (Unauthorized): JSON
 //   - {"Message": "Authenticate yourself first!"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
 // - 500 (Internal Server Error): JSON
 //   - {"InternalServerError":"<SQL-ERROR>"}
 //
@@ -1534,6 +2112,9 @@ func PostTopicFavouritesMark(serverState *ServerState) fiber.Handler {
 // - Polariusz
 func PostTopicFavouritesUnmark(serverState *ServerState) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_FAVOURITE_UNMARK}
+		defer serverState.auditLog.record(c, &record)
+
 		if serverState.mqttClient == nil || !serverState.mqttClient.IsConnected() {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"Message": "Authenticate yourself first!",
@@ -1548,13 +2129,24 @@ func PostTopicFavouritesUnmark(serverState *ServerState) fiber.Handler {
 			})
 		}
 
+		resolved, ok, err := resolveBrokerUser(c, serverState, unmarkTopics.BrokerUserIDs, false)
+		if !ok {
+			return err
+		}
+		unmarkTopics.BrokerUserIDs = resolved
+		record.about(unmarkTopics.BrokerUserIDs, unmarkTopics.Topics...)
+
 		if unmarkTopics.BrokerUserIDs.BrokerId <= 0 || unmarkTopics.BrokerUserIDs.UserId <= 0 {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"terribleJson": "Arguments are not valid",
 			})
 		}
 
-		favTopicList, err := database.SelectFavouriteTopicsByBrokerIdAndUserId(serverState.con, unmarkTopics.BrokerUserIDs.BrokerId, unmarkTopics.BrokerUserIDs.UserId)
+		if ok, err := authorize(c, serverState, ROLE_OPERATOR, unmarkTopics.BrokerUserIDs.BrokerId, unmarkTopics.Topics...); !ok {
+			return err
+		}
+
+		favTopicList, err := serverState.store.SelectFavouriteTopicsByBrokerIdAndUserId(unmarkTopics.BrokerUserIDs.BrokerId, unmarkTopics.BrokerUserIDs.UserId)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"InternalServerError": err.Error(),
@@ -1569,7 +2161,7 @@ func PostTopicFavouritesUnmark(serverState *ServerState) fiber.Handler {
 			for _, dbFavTopic := range favTopicList {
 				if unmarkTopic == dbFavTopic.Topic {
 					topicFound = true
-					if err := database.DeleteFavouriteTopic(serverState.con, dbFavTopic.Id); err != nil {
+					if err := serverState.store.DeleteFavouriteTopic(dbFavTopic.Id); err != nil {
 						atLeastOneBadTopic = true
 						topicResult[unmarkTopic] = TopicResult{"ServerError", err.Error()}
 					} else {
@@ -1596,16 +2188,20 @@ func PostTopicFavouritesUnmark(serverState *ServerState) fiber.Handler {
 	}
 }
 
-// | Date of change | By        | Comment            |
-// +----------------+-----------+--------------------+
-// | 2025-05-18     | Polariusz | Created            |
-// | 2025-06-07     | Polariusz | Integrated with DB |
+// | Date of change | By        | Comment                   |
+// +----------------+-----------+---------------------------+
+// | 2025-05-18     | Polariusz | Created                   |
+// | 2025-06-07     | Polariusz | Integrated with DB        |
+// | 2026-10-19     | Polariusz | Identity from the session |
+// | 2026-10-19     | Polariusz | Roles                     |
 //
 // # Method-Type
 // - Handler
 //
 // # Description
 // - The fiber.Handler shall return favourite Topics.
+// - The method shall take the broker and user from the session when the request has one.
+// - The method shall leave out the topics that the role viewer is not given for.
 //
 // # Usage
 // - Call declared by the routing method addRoutes() URL with the GET-Method.
@@ -1619,6 +2215,8 @@ func PostTopicFavouritesUnmark(serverState *ServerState) fiber.Handler {
 //   - {"terribleJSON":"Arguments are not valid"}
 // - 401 (Unauthorized): JSON
 //   - {"Message": "Authenticate yourself first!"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
 // - 500 (Internal Server Error): JSON
 //   - {"InternalServerError":"<SQL-ERROR>"}
 //
@@ -1640,21 +2238,39 @@ func GetTopicFavourites(serverState *ServerState) fiber.Handler {
 			})
 		}
 
+		resolved, ok, err := resolveBrokerUser(c, serverState, brokerUser, false)
+		if !ok {
+			return err
+		}
+		brokerUser = resolved
+
 		if brokerUser.BrokerId <= 0 || brokerUser.UserId <= 0 {
 			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 				"terribleJson": "Arguments are not valid",
 			})
 		}
 
-		favTopicList, err := database.SelectFavouriteTopicsByBrokerIdAndUserId(serverState.con, brokerUser.BrokerId, brokerUser.UserId)
+		permissions, ok, err := authorizeAny(c, serverState, ROLE_VIEWER, brokerUser.BrokerId)
+		if !ok {
+			return err
+		}
+
+		favTopicList, err := serverState.store.SelectFavouriteTopicsByBrokerIdAndUserId(brokerUser.BrokerId, brokerUser.UserId)
 		if err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"InternalServerError": err.Error(),
 			})
 		}
 
+		visibleFavTopicList := favTopicList[:0]
+		for _, topic := range favTopicList {
+			if permissions.allows(ROLE_VIEWER, brokerUser.BrokerId, topic.Topic) {
+				visibleFavTopicList = append(visibleFavTopicList, topic)
+			}
+		}
+
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"Topics": favTopicList,
+			"Topics": visibleFavTopicList,
 		})
 	}
 }
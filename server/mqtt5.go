@@ -0,0 +1,670 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The types of the MQTT 5 control packets, the upper four bits of their first byte.
+//
+// # Author
+// - Polariusz
+const (
+	MQTT5_CONNECT    = 1
+	MQTT5_CONNACK    = 2
+	MQTT5_PUBLISH    = 3
+	MQTT5_PUBACK     = 4
+	MQTT5_PUBREC     = 5
+	MQTT5_PUBREL     = 6
+	MQTT5_PUBCOMP    = 7
+	MQTT5_SUBSCRIBE  = 8
+	MQTT5_SUBACK     = 9
+	MQTT5_PINGREQ    = 12
+	MQTT5_PINGRESP   = 13
+	MQTT5_DISCONNECT = 14
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The identifiers of the MQTT 5 properties that the server reads or writes, the others are skipped.
+//
+// # Author
+// - Polariusz
+const (
+	MQTT5_PROPERTY_RESPONSE_TOPIC    = 0x08
+	MQTT5_PROPERTY_CORRELATION_DATA  = 0x09
+	MQTT5_PROPERTY_SERVER_KEEP_ALIVE = 0x13
+	MQTT5_PROPERTY_REASON_STRING     = 0x1F
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - MQTT5_MAX_PACKET_SIZE : The largest packet that is read, a larger one closes the connection.
+//
+// # Author
+// - Polariusz
+const MQTT5_MAX_PACKET_SIZE = 16 * 1024 * 1024
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - One MQTT 5 control packet, the `body` is everything after the remaining length.
+//
+// # Author
+// - Polariusz
+type mqtt5Packet struct {
+	kind byte
+	flags byte
+	body []byte
+}
+
+// # Description
+// - The function shall read one packet from the argument reader.
+//
+// # Author
+// - Polariusz
+func readMqtt5Packet(reader *bufio.Reader) (mqtt5Packet, error) {
+	first, err := reader.ReadByte()
+	if err != nil {
+		return mqtt5Packet{}, err
+	}
+	length, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return mqtt5Packet{}, err
+	}
+	if length > MQTT5_MAX_PACKET_SIZE {
+		return mqtt5Packet{}, fmt.Errorf("The packet of %d bytes is too large", length)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return mqtt5Packet{}, err
+	}
+	return mqtt5Packet{kind: first >> 4, flags: first & 0x0F, body: body}, nil
+}
+
+// # Description
+// - The function shall write one packet to the argument writer, the remaining length is that of the body.
+//
+// # Author
+// - Polariusz
+func writeMqtt5Packet(writer io.Writer, kind byte, flags byte, body []byte) error {
+	packet := []byte{kind<<4 | flags&0x0F}
+	packet = binary.AppendUvarint(packet, uint64(len(body)))
+	_, err := writer.Write(append(packet, body...))
+	return err
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The structure writes the fields of a packet body in the encoding of MQTT 5.
+// - The variable byte integer of MQTT is the same as an unsigned varint of encoding/binary.
+//
+// # Author
+// - Polariusz
+type mqtt5Writer struct {
+	bytes.Buffer
+}
+
+// # Author
+// - Polariusz
+func (mw *mqtt5Writer) uint16(value uint16) {
+	mw.Write(binary.BigEndian.AppendUint16(nil, value))
+}
+
+// # Author
+// - Polariusz
+func (mw *mqtt5Writer) varint(value int) {
+	mw.Write(binary.AppendUvarint(nil, uint64(value)))
+}
+
+// # Description
+// - The method shall write binary data, or a UTF-8 string, with its length in front.
+//
+// # Author
+// - Polariusz
+func (mw *mqtt5Writer) binary(value []byte) {
+	mw.uint16(uint16(len(value)))
+	mw.Write(value)
+}
+
+// # Description
+// - The method shall write the properties with their length in front, an empty argument writes the length 0.
+//
+// # Author
+// - Polariusz
+func (mw *mqtt5Writer) properties(properties mqtt5Properties) {
+	var encoded mqtt5Writer
+	if properties.ResponseTopic != "" {
+		encoded.WriteByte(MQTT5_PROPERTY_RESPONSE_TOPIC)
+		encoded.binary([]byte(properties.ResponseTopic))
+	}
+	if properties.CorrelationData != nil {
+		encoded.WriteByte(MQTT5_PROPERTY_CORRELATION_DATA)
+		encoded.binary(properties.CorrelationData)
+	}
+	mw.varint(encoded.Len())
+	mw.Write(encoded.Bytes())
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The structure reads the fields of a packet body in the encoding of MQTT 5.
+// - After the first field that is cut off every read returns zero, and `err` tells so.
+//
+// # Author
+// - Polariusz
+type mqtt5Reader struct {
+	data []byte
+	err error
+}
+
+// # Author
+// - Polariusz
+func (mr *mqtt5Reader) take(count int) []byte {
+	if mr.err != nil || count > len(mr.data) {
+		mr.err = fmt.Errorf("The packet is cut off")
+		return nil
+	}
+	taken := mr.data[:count]
+	mr.data = mr.data[count:]
+	return taken
+}
+
+// # Author
+// - Polariusz
+func (mr *mqtt5Reader) byte() byte {
+	if taken := mr.take(1); taken != nil {
+		return taken[0]
+	}
+	return 0
+}
+
+// # Author
+// - Polariusz
+func (mr *mqtt5Reader) uint16() uint16 {
+	if taken := mr.take(2); taken != nil {
+		return binary.BigEndian.Uint16(taken)
+	}
+	return 0
+}
+
+// # Author
+// - Polariusz
+func (mr *mqtt5Reader) varint() int {
+	if mr.err != nil {
+		return 0
+	}
+	value, size := binary.Uvarint(mr.data)
+	if size <= 0 || size > 4 {
+		mr.err = fmt.Errorf("The packet has a malformed variable byte integer")
+		return 0
+	}
+	mr.data = mr.data[size:]
+	return int(value)
+}
+
+// # Description
+// - The method shall read binary data, or a UTF-8 string, with its length in front.
+//
+// # Author
+// - Polariusz
+func (mr *mqtt5Reader) binary() []byte {
+	return mr.take(int(mr.uint16()))
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The properties of a packet that the server reads or writes.
+//   - `CorrelationData` is nil if the packet has none.
+//
+// # Author
+// - Polariusz
+type mqtt5Properties struct {
+	ResponseTopic string
+	CorrelationData []byte
+	ServerKeepAlive uint16
+	ReasonString string
+}
+
+// # Description
+// - The method shall read the properties with their length in front. The properties that are not in mqtt5Properties are skipped, by the size of their type.
+//
+// # Author
+// - Polariusz
+func (mr *mqtt5Reader) properties() mqtt5Properties {
+	var properties mqtt5Properties
+	section := mqtt5Reader{data: mr.take(mr.varint()), err: mr.err}
+	for section.err == nil && len(section.data) > 0 {
+		switch id := section.varint(); id {
+		case MQTT5_PROPERTY_RESPONSE_TOPIC:
+			properties.ResponseTopic = string(section.binary())
+		case MQTT5_PROPERTY_CORRELATION_DATA:
+			properties.CorrelationData = append([]byte{}, section.binary()...)
+		case MQTT5_PROPERTY_SERVER_KEEP_ALIVE:
+			properties.ServerKeepAlive = section.uint16()
+		case MQTT5_PROPERTY_REASON_STRING:
+			properties.ReasonString = string(section.binary())
+		case 0x01, 0x17, 0x19, 0x24, 0x25, 0x28, 0x29, 0x2A:
+			section.byte()
+		case 0x02, 0x11, 0x18, 0x27:
+			section.take(4)
+		case 0x21, 0x22, 0x23:
+			section.uint16()
+		case 0x0B:
+			section.varint()
+		case 0x03, 0x12, 0x15, 0x16, 0x1A, 0x1C:
+			section.binary()
+		case 0x26:
+			section.binary()
+			section.binary()
+		default:
+			section.err = fmt.Errorf("The packet has the unknown property 0x%02X", id)
+		}
+	}
+	if mr.err == nil {
+		mr.err = section.err
+	}
+	return properties
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A PUBLISH that the MQTT 5 client received.
+//
+// # Author
+// - Polariusz
+type mqtt5Message struct {
+	Topic string
+	Payload []byte
+	QoS byte
+	Retained bool
+	Properties mqtt5Properties
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A MQTT-Client that speaks MQTT 5, for the requests that send their response topic and correlation data as properties. paho.mqtt.golang only speaks MQTT 3.1.1.
+// - It knows just enough of MQTT 5 for one request: connect, subscribe, publish with the properties, receive and disconnect. There is no reconnect and no session.
+// - The packets are read by readLoop(), the acknowledgements are handed to the method that waits for them through `acks`.
+//
+// # Used in
+// - PostRequestHandler()
+//
+// # Author
+// - Polariusz
+type mqtt5Client struct {
+	conn net.Conn
+	writeMutex sync.Mutex
+	acks chan mqtt5Packet
+	onMessage func(mqtt5Message)
+	nextId uint16
+	done chan struct{}
+	err error
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall connect to the broker of the credentials with MQTT 5, with a clean start.
+// - The keepalive is that of serverConfig, unless the broker tells another one in the CONNACK.
+// - Every PUBLISH that comes in is acknowledged and handed to the argument `onMessage`, from the goroutine that reads the connection.
+//
+// # Returns
+// - error when the broker can not be reached, does not answer in time or refuses the connection
+//
+// # Author
+// - Polariusz
+func dialMqtt5(credentials MqttCredentials, timeout time.Duration, onMessage func(mqtt5Message)) (*mqtt5Client, error) {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(credentials.Ip, credentials.Port), timeout)
+	if err != nil {
+		return nil, err
+	}
+	client := &mqtt5Client{
+		conn: conn,
+		acks: make(chan mqtt5Packet, 8),
+		onMessage: onMessage,
+		done: make(chan struct{}),
+	}
+	go client.readLoop()
+
+	keepAlive := uint16(max(serverConfig.KeepAlive/time.Second, 1))
+	var connect mqtt5Writer
+	connect.binary([]byte("MQTT"))
+	connect.WriteByte(5)
+	flags := byte(0x02)
+	if credentials.Username != "" {
+		flags |= 0xC0
+	}
+	connect.WriteByte(flags)
+	connect.uint16(keepAlive)
+	connect.properties(mqtt5Properties{})
+	connect.binary([]byte(credentials.ClientId))
+	if credentials.Username != "" {
+		connect.binary([]byte(credentials.Username))
+		connect.binary([]byte(credentials.Password))
+	}
+	if err := client.write(MQTT5_CONNECT, 0, connect.Bytes()); err != nil {
+		client.close()
+		return nil, err
+	}
+
+	connack, err := client.await(MQTT5_CONNACK, 0, timeout)
+	if err != nil {
+		client.close()
+		return nil, err
+	}
+	reader := mqtt5Reader{data: connack.body}
+	reader.byte()
+	reason := reader.byte()
+	properties := reader.properties()
+	if reader.err != nil {
+		client.close()
+		return nil, reader.err
+	}
+	if reason >= 0x80 {
+		client.close()
+		return nil, fmt.Errorf("The broker refused the connection with the reason code 0x%02X %s", reason, properties.ReasonString)
+	}
+	if properties.ServerKeepAlive != 0 {
+		keepAlive = properties.ServerKeepAlive
+	}
+	go client.ping(time.Duration(keepAlive) * time.Second / 2)
+
+	return client, nil
+}
+
+// # Description
+// - The method shall write one packet, the packets of the goroutines must not be mixed.
+//
+// # Author
+// - Polariusz
+func (mc *mqtt5Client) write(kind byte, flags byte, body []byte) error {
+	mc.writeMutex.Lock()
+	defer mc.writeMutex.Unlock()
+
+	return writeMqtt5Packet(mc.conn, kind, flags, body)
+}
+
+// # Description
+// - The method shall return a packet identifier that is not 0.
+//
+// # Author
+// - Polariusz
+func (mc *mqtt5Client) packetId() uint16 {
+	mc.nextId++
+	if mc.nextId == 0 {
+		mc.nextId = 1
+	}
+	return mc.nextId
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Worker
+//
+// # Description
+// - The method shall read the packets until the connection is closed.
+//   - A PUBLISH is acknowledged and handed to `onMessage`, a PUBREL is answered with a PUBCOMP.
+//   - The other acknowledgements are handed to await().
+//   - A DISCONNECT of the broker ends the connection, its reason is kept in `err`.
+//
+// # Author
+// - Polariusz
+func (mc *mqtt5Client) readLoop() {
+	defer close(mc.done)
+	reader := bufio.NewReader(mc.conn)
+
+	for {
+		packet, err := readMqtt5Packet(reader)
+		if err != nil {
+			mc.err = fmt.Errorf("The connection to the broker was closed: %s", err)
+			return
+		}
+
+		switch packet.kind {
+		case MQTT5_PUBLISH:
+			message, packetId, err := parseMqtt5Publish(packet)
+			if err != nil {
+				mc.err = err
+				mc.conn.Close()
+				return
+			}
+			switch message.QoS {
+			case 1:
+				mc.write(MQTT5_PUBACK, 0, binary.BigEndian.AppendUint16(nil, packetId))
+			case 2:
+				mc.write(MQTT5_PUBREC, 0, binary.BigEndian.AppendUint16(nil, packetId))
+			}
+			mc.onMessage(message)
+		case MQTT5_PUBREL:
+			mc.write(MQTT5_PUBCOMP, 0, packet.body[:min(len(packet.body), 2)])
+		case MQTT5_PINGRESP:
+		case MQTT5_DISCONNECT:
+			reader := mqtt5Reader{data: packet.body}
+			reason := reader.byte()
+			mc.err = fmt.Errorf("The broker disconnected with the reason code 0x%02X %s", reason, reader.properties().ReasonString)
+			mc.conn.Close()
+			return
+		default:
+			select {
+			case mc.acks <- packet:
+			case <-time.After(time.Second):
+			}
+		}
+	}
+}
+
+// # Description
+// - The function shall read the topic, the packet identifier, the properties and the payload of a PUBLISH.
+//
+// # Author
+// - Polariusz
+func parseMqtt5Publish(packet mqtt5Packet) (mqtt5Message, uint16, error) {
+	message := mqtt5Message{QoS: (packet.flags >> 1) & 0x03, Retained: packet.flags&0x01 != 0}
+	reader := mqtt5Reader{data: packet.body}
+	message.Topic = string(reader.binary())
+	var packetId uint16
+	if message.QoS > 0 {
+		packetId = reader.uint16()
+	}
+	message.Properties = reader.properties()
+	message.Payload = reader.data
+	return message, packetId, reader.err
+}
+
+// # Method-Type
+// - Worker
+//
+// # Description
+// - The method shall send a PINGREQ every argument interval, until the connection is closed.
+//
+// # Author
+// - Polariusz
+func (mc *mqtt5Client) ping(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-mc.done:
+			return
+		case <-ticker.C:
+			mc.write(MQTT5_PINGREQ, 0, nil)
+		}
+	}
+}
+
+// # Description
+// - The method shall wait for the acknowledgement of the argument kind and packet identifier, 0 matches every identifier.
+//
+// # Returns
+// - error when the connection was closed or the acknowledgement did not come in time
+//
+// # Author
+// - Polariusz
+func (mc *mqtt5Client) await(kind byte, packetId uint16, timeout time.Duration) (mqtt5Packet, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case packet := <-mc.acks:
+			if packet.kind != kind {
+				continue
+			}
+			if packetId != 0 && (len(packet.body) < 2 || binary.BigEndian.Uint16(packet.body) != packetId) {
+				continue
+			}
+			return packet, nil
+		case <-mc.done:
+			return mqtt5Packet{}, mc.err
+		case <-timer.C:
+			return mqtt5Packet{}, fmt.Errorf("The broker did not answer in time")
+		}
+	}
+}
+
+// # Description
+// - The method shall subscribe to the argument filter.
+//
+// # Returns
+// - error when the broker refused the subscription, with its reason code
+//
+// # Author
+// - Polariusz
+func (mc *mqtt5Client) subscribe(filter string, qos byte, timeout time.Duration) error {
+	packetId := mc.packetId()
+	var subscribe mqtt5Writer
+	subscribe.uint16(packetId)
+	subscribe.properties(mqtt5Properties{})
+	subscribe.binary([]byte(filter))
+	subscribe.WriteByte(qos)
+	if err := mc.write(MQTT5_SUBSCRIBE, 0x02, subscribe.Bytes()); err != nil {
+		return err
+	}
+
+	suback, err := mc.await(MQTT5_SUBACK, packetId, timeout)
+	if err != nil {
+		return err
+	}
+	reader := mqtt5Reader{data: suback.body}
+	reader.uint16()
+	properties := reader.properties()
+	reason := reader.byte()
+	if reader.err != nil {
+		return reader.err
+	}
+	if reason >= 0x80 {
+		return fmt.Errorf("The broker refused the subscription with the reason code 0x%02X %s", reason, properties.ReasonString)
+	}
+	return nil
+}
+
+// # Description
+// - The method shall publish the payload with the argument properties, and wait for the PUBACK of QoS 1 or the PUBREC and PUBCOMP of QoS 2.
+//
+// # Returns
+// - error when the broker did not acknowledge the publish or refused it, with its reason code
+//
+// # Author
+// - Polariusz
+func (mc *mqtt5Client) publish(topic string, qos byte, payload []byte, properties mqtt5Properties, timeout time.Duration) error {
+	var packetId uint16
+	var publish mqtt5Writer
+	publish.binary([]byte(topic))
+	if qos > 0 {
+		packetId = mc.packetId()
+		publish.uint16(packetId)
+	}
+	publish.properties(properties)
+	publish.Write(payload)
+	if err := mc.write(MQTT5_PUBLISH, qos<<1, publish.Bytes()); err != nil {
+		return err
+	}
+
+	switch qos {
+	case 1:
+		puback, err := mc.await(MQTT5_PUBACK, packetId, timeout)
+		if err != nil {
+			return err
+		}
+		return mqtt5Refused(puback, "publish")
+	case 2:
+		pubrec, err := mc.await(MQTT5_PUBREC, packetId, timeout)
+		if err != nil {
+			return err
+		}
+		if err := mqtt5Refused(pubrec, "publish"); err != nil {
+			return err
+		}
+		if err := mc.write(MQTT5_PUBREL, 0x02, binary.BigEndian.AppendUint16(nil, packetId)); err != nil {
+			return err
+		}
+		_, err = mc.await(MQTT5_PUBCOMP, packetId, timeout)
+		return err
+	}
+	return nil
+}
+
+// # Description
+// - The function shall return an error if the argument PUBACK or PUBREC has a reason code of a failure. Without a reason code it is a success.
+//
+// # Author
+// - Polariusz
+func mqtt5Refused(ack mqtt5Packet, what string) error {
+	if len(ack.body) < 3 || ack.body[2] < 0x80 {
+		return nil
+	}
+	reader := mqtt5Reader{data: ack.body[3:]}
+	return fmt.Errorf("The broker refused the %s with the reason code 0x%02X %s", what, ack.body[2], reader.properties().ReasonString)
+}
+
+// # Description
+// - The method shall disconnect from the broker and close the connection.
+//
+// # Author
+// - Polariusz
+func (mc *mqtt5Client) close() {
+	mc.write(MQTT5_DISCONNECT, 0, []byte{0x00, 0x00})
+	mc.conn.Close()
+	<-mc.done
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+)
+
+func TestMqtt5Properties(t *testing.T) {
+	// A User Property, a Message Expiry Interval and a Server Keep Alive, which are skipped or read by their type, around the properties of a request.
+	var section mqtt5Writer
+	section.WriteByte(0x26)
+	section.binary([]byte("key"))
+	section.binary([]byte("value"))
+	section.WriteByte(0x02)
+	section.Write([]byte{0, 0, 0, 60})
+	section.WriteByte(MQTT5_PROPERTY_SERVER_KEEP_ALIVE)
+	section.uint16(30)
+	var request mqtt5Writer
+	request.properties(mqtt5Properties{ResponseTopic: "cmd/x/reply", CorrelationData: []byte{0, 1, 2}})
+	reader := mqtt5Reader{data: request.Bytes()}
+	written := reader.take(reader.varint())
+	section.Write(written)
+
+	var body mqtt5Writer
+	body.varint(section.Len())
+	body.Write(section.Bytes())
+	body.WriteString("payload")
+
+	reader = mqtt5Reader{data: body.Bytes()}
+	properties := reader.properties()
+	if reader.err != nil || properties.ServerKeepAlive != 30 || properties.ResponseTopic != "cmd/x/reply" || !bytes.Equal(properties.CorrelationData, []byte{0, 1, 2}) || string(reader.data) != "payload" {
+		t.Errorf("properties() = %+v, %v, rest %q", properties, reader.err, reader.data)
+	}
+
+	reader = mqtt5Reader{data: []byte{0x03, MQTT5_PROPERTY_RESPONSE_TOPIC, 0x00, 0x05}}
+	if reader.properties(); reader.err == nil {
+		t.Errorf("a cut off property was read")
+	}
+}
+
+func TestMqtt5Packet(t *testing.T) {
+	var buffer bytes.Buffer
+	payload := bytes.Repeat([]byte("x"), 300)
+	if err := writeMqtt5Packet(&buffer, MQTT5_PUBLISH, 0x03, payload); err != nil {
+		t.Fatal(err)
+	}
+	// 300 bytes need two bytes of remaining length.
+	if buffer.Len() != 1+2+300 {
+		t.Errorf("the packet has %d bytes", buffer.Len())
+	}
+	packet, err := readMqtt5Packet(bufio.NewReader(&buffer))
+	if err != nil || packet.kind != MQTT5_PUBLISH || packet.flags != 0x03 || !bytes.Equal(packet.body, payload) {
+		t.Errorf("readMqtt5Packet() = %d %d %d bytes, %v", packet.kind, packet.flags, len(packet.body), err)
+	}
+}
@@ -0,0 +1,422 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The page of `GET /api/v1/docs`. It loads `openapi.json` next to it and has no other dependencies, so it also works without internet.
+//
+// # Author
+// - Polariusz
+//
+//go:embed openapi.html
+var openAPIDocsPage []byte
+
+const OPENAPI_VERSION = "3.1.0"
+const OPENAPI_TITLE = "MQTT-Explorer server"
+const OPENAPI_API_VERSION = "1.0.0"
+
+var timeType = reflect.TypeOf(time.Time{})
+var fiberMapType = reflect.TypeOf(fiber.Map{})
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - It collects the JSON schemas of the Go types under `components/schemas` while the OpenAPI document is built.
+// - A struct is added once by its name and referenced with `$ref` everywhere else.
+//
+// # Used in
+// - buildOpenAPI()
+//
+// # Author
+// - Polariusz
+type schemaRegistry struct {
+	schemas map[string]any
+}
+
+// # Description
+// - The method shall return the schema of the value. A `fiber.Map` is described by its keys and the types of its values, everything else by its type.
+//
+// # Author
+// - Polariusz
+func (registry *schemaRegistry) schemaOfValue(value any) map[string]any {
+	if fields, ok := value.(fiber.Map); ok {
+		properties := map[string]any{}
+		for key, field := range fields {
+			properties[key] = registry.schemaOfValue(field)
+		}
+		return map[string]any{"type": "object", "properties": properties}
+	}
+	return registry.schemaOf(reflect.TypeOf(value))
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall return the JSON schema of the type, as `encoding/json` would marshal it.
+// - time.Time is a "date-time" string and a pointer may be null.
+//
+// # Author
+// - Polariusz
+func (registry *schemaRegistry) schemaOf(t reflect.Type) map[string]any {
+	if t == nil || t == fiberMapType {
+		return map[string]any{"type": "object"}
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := registry.schemaOf(t.Elem())
+		if kind, ok := schema["type"].(string); ok {
+			schema["type"] = []string{kind, "null"}
+			return schema
+		}
+		return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": registry.schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": registry.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return registry.reference(t)
+	}
+
+	return map[string]any{}
+}
+
+// # Description
+// - The method shall add the struct to the registry, if it is not there yet, and return a `$ref` to it.
+//
+// # Author
+// - Polariusz
+func (registry *schemaRegistry) reference(t reflect.Type) map[string]any {
+	name := t.Name()
+	ref := map[string]any{"$ref": "#/components/schemas/" + name}
+	if _, ok := registry.schemas[name]; ok {
+		return ref
+	}
+
+	// Reserved before the fields, a struct may contain itself.
+	registry.schemas[name] = map[string]any{}
+
+	properties := map[string]any{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+		properties[name] = registry.schemaOf(field.Type)
+	}
+
+	registry.schemas[t.Name()] = map[string]any{"type": "object", "properties": properties}
+	return ref
+}
+
+// # Description
+// - The function shall return the name that `encoding/json` uses for the field, false if it is left out.
+//
+// # Author
+// - Polariusz
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name, true
+	}
+	return field.Name, true
+}
+
+// # Description
+// - The function shall turn the fiber path `/brokers/:brokerId` into the OpenAPI path `/brokers/{brokerId}` and return its parameters.
+// - The parameter `name` is a string, every other parameter is an ID.
+//
+// # Author
+// - Polariusz
+func openAPIPath(path string) (string, []any) {
+	parameterList := []any{}
+	segmentList := strings.Split(path, "/")
+	for i, segment := range segmentList {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := strings.TrimPrefix(segment, ":")
+		segmentList[i] = "{" + name + "}"
+
+		schema := map[string]any{"type": "integer", "minimum": 1}
+		if name == "name" {
+			schema = map[string]any{"type": "string"}
+		}
+		parameterList = append(parameterList, map[string]any{"name": name, "in": "path", "required": true, "schema": schema})
+	}
+	return strings.Join(segmentList, "/"), parameterList
+}
+
+// # Author
+// - Polariusz
+func openAPIQueryParameter(parameter APIParameter) map[string]any {
+	schema := map[string]any{"type": parameter.Type}
+	if parameter.Type == "date-time" {
+		schema = map[string]any{"type": "string", "format": "date-time"}
+	}
+	if parameter.Repeated {
+		schema = map[string]any{"type": "array", "items": schema}
+	}
+	return map[string]any{
+		"name": parameter.Name,
+		"in": "query",
+		"description": parameter.Description,
+		"schema": schema,
+	}
+}
+
+// # Author
+// - Polariusz
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{fiber.MIMEApplicationJSON: map[string]any{"schema": schema}}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall return the successful responses of a route, `response` overrides `route.Response`.
+//
+// # Author
+// - Polariusz
+func openAPIResponses(registry *schemaRegistry, route APIRoute, response any) map[string]any {
+	status := route.Status
+	if status == 0 {
+		status = fiber.StatusOK
+	}
+
+	success := map[string]any{"description": utils.StatusMessage(status)}
+	if route.Produces != "" {
+		success["content"] = map[string]any{route.Produces: map[string]any{"schema": map[string]any{"type": "string"}}}
+	} else {
+		success["content"] = jsonContent(registry.schemaOfValue(response))
+	}
+
+	responses := map[string]any{strconv.Itoa(status): success}
+	if route.Partial {
+		responses[strconv.Itoa(fiber.StatusMultiStatus)] = map[string]any{
+			"description": "Some topics failed, see TopicResult",
+			"content": success["content"],
+		}
+	}
+	return responses
+}
+
+// | Date of change | By        | Comment            |
+// +----------------+-----------+--------------------+
+// | 2026-10-19     | Polariusz | Created            |
+// | 2026-10-19     | Polariusz | Added the security |
+//
+// # Description
+// - The function shall build the OpenAPI document of the routes and of the deprecated routes they replace.
+// - The routes are described from the Go types in `APIRoute`, so the document follows the code. openapi_test.go checks that the server has exactly these routes.
+// - The deprecated routes are tagged "deprecated", take a JSON body and answer errors with the keys of their handler, not with `APIErrorEnvelope`.
+// - All routes but the `Public` ones need a session, by cookie, bearer token or API key, once there is an account.
+//
+// # Returns
+// - The document, ready for `c.JSON()`
+//
+// # Author
+// - Polariusz
+func buildOpenAPI(routeList []APIRoute) map[string]any {
+	registry := &schemaRegistry{schemas: map[string]any{}}
+	paths := map[string]map[string]any{}
+
+	addOperation := func(path string, method string, operation map[string]any) {
+		if paths[path] == nil {
+			paths[path] = map[string]any{}
+		}
+		paths[path][strings.ToLower(method)] = operation
+	}
+
+	errorResponse := map[string]any{
+		"description": "Error, see `code`",
+		"content": jsonContent(registry.schemaOf(reflect.TypeOf(APIErrorEnvelope{}))),
+	}
+
+	for _, route := range routeList {
+		path, parameterList := openAPIPath(API_V1_PREFIX + route.Path)
+		for _, parameter := range route.Query {
+			parameterList = append(parameterList, openAPIQueryParameter(parameter))
+		}
+
+		responses := openAPIResponses(registry, route, route.Response)
+		responses["default"] = errorResponse
+
+		operation := map[string]any{
+			"summary": route.Summary,
+			"operationId": openAPIOperationId(route.Method, API_V1_PREFIX+route.Path),
+			"tags": []string{openAPITag(route.Path)},
+			"parameters": parameterList,
+			"responses": responses,
+		}
+		if route.Public {
+			operation["security"] = []any{}
+		}
+		if route.Request != nil {
+			operation["requestBody"] = map[string]any{"required": true, "content": jsonContent(registry.schemaOfValue(route.Request))}
+		}
+		addOperation(path, route.Method, operation)
+
+		for _, legacy := range route.Replaces {
+			method, legacyPath, _ := strings.Cut(legacy.Route, " ")
+			response := route.Response
+			if legacy.Response != nil {
+				response = legacy.Response
+			}
+
+			legacyOperation := map[string]any{
+				"summary": route.Summary,
+				"description": fmt.Sprintf("Replaced by `%s %s%s`.", route.Method, API_V1_PREFIX, route.Path),
+				"operationId": openAPIOperationId(method, legacyPath),
+				"tags": []string{"deprecated"},
+				"deprecated": true,
+				"responses": openAPIResponses(registry, route, response),
+			}
+			if legacy.Request != nil {
+				legacyOperation["requestBody"] = map[string]any{"required": true, "content": jsonContent(registry.schemaOfValue(legacy.Request))}
+			}
+			addOperation(legacyPath, method, legacyOperation)
+		}
+	}
+
+	return map[string]any{
+		"openapi": OPENAPI_VERSION,
+		"info": map[string]any{
+			"title": OPENAPI_TITLE,
+			"version": OPENAPI_API_VERSION,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": registry.schemas,
+			"securitySchemes": map[string]any{
+				"session": map[string]any{"type": "apiKey", "in": "cookie", "name": SESSION_COOKIE},
+				"bearer": map[string]any{"type": "http", "scheme": "bearer"},
+				"apiKey": map[string]any{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+			},
+		},
+		"security": []any{
+			map[string]any{"session": []string{}},
+			map[string]any{"bearer": []string{}},
+			map[string]any{"apiKey": []string{}},
+		},
+	}
+}
+
+// # Description
+// - The function shall return the tag of a route, its first segment, like "brokers" or "replays".
+//
+// # Author
+// - Polariusz
+func openAPITag(path string) string {
+	return strings.Split(strings.TrimPrefix(path, "/"), "/")[0]
+}
+
+// # Description
+// - The function shall return an operationId from the method and path, like "get_api_v1_replays_id".
+//
+// # Author
+// - Polariusz
+func openAPIOperationId(method string, path string) string {
+	id := strings.ToLower(method)
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.Trim(segment, ":.")
+		if segment == "" {
+			continue
+		}
+		id += "_" + strings.NewReplacer("-", "_", ".", "_").Replace(segment)
+	}
+	return id
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return the OpenAPI document of the server, built once from apiV1Routes().
+//
+// # Usage
+// - Call declared by addAPIV1Routes() URL with the GET-Method.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - The OpenAPI 3.1 document
+//
+// # Author
+// - Polariusz
+func GetOpenAPIHandler(serverState *ServerState) fiber.Handler {
+	document := buildOpenAPI(apiV1Routes())
+
+	return func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(document)
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return a page that shows the OpenAPI document.
+//
+// # Usage
+// - Call declared by addAPIV1Routes() URL with the GET-Method.
+//
+// # Returns
+// - 200 (Ok): HTML
+//
+// # Author
+// - Polariusz
+func GetOpenAPIDocsHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.Status(fiber.StatusOK).Send(openAPIDocsPage)
+	}
+}
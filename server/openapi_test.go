@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// The "<METHOD> <PATH>" of every operation in the document, sorted.
+func openAPIOperations(document map[string]any) []string {
+	operationList := []string{}
+	for path, operations := range document["paths"].(map[string]map[string]any) {
+		for method := range operations {
+			operationList = append(operationList, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(operationList)
+	return operationList
+}
+
+// The "<METHOD> <PATH>" of every route of the server, in the notation of OpenAPI, sorted.
+// HEAD is left out as fiber adds it to every GET, and so is the static frontend.
+func serverOperations(server *fiber.App) []string {
+	parameter := regexp.MustCompile(`:(\w+)`)
+
+	seen := map[string]bool{}
+	operationList := []string{}
+	for _, route := range server.GetRoutes(true) {
+		if route.Method == fiber.MethodHead || route.Path == "/" || strings.Contains(route.Path, "*") {
+			continue
+		}
+		operation := route.Method + " " + parameter.ReplaceAllString(route.Path, "{$1}")
+		if !seen[operation] {
+			seen[operation] = true
+			operationList = append(operationList, operation)
+		}
+	}
+	sort.Strings(operationList)
+	return operationList
+}
+
+func difference(a []string, b []string) []string {
+	inB := map[string]bool{}
+	for _, item := range b {
+		inB[item] = true
+	}
+	missing := []string{}
+	for _, item := range a {
+		if !inB[item] {
+			missing = append(missing, item)
+		}
+	}
+	return missing
+}
+
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	server := fiber.New()
+	addRoutes(server, &ServerState{})
+
+	documented := openAPIOperations(buildOpenAPI(apiV1Routes()))
+	registered := serverOperations(server)
+
+	for _, operation := range difference(registered, documented) {
+		t.Errorf("route %s is not in the OpenAPI document, add it to apiV1Routes()", operation)
+	}
+	for _, operation := range difference(documented, registered) {
+		t.Errorf("the OpenAPI document has %s, but the server does not", operation)
+	}
+}
+
+func TestOpenAPIReferencesResolve(t *testing.T) {
+	document := buildOpenAPI(apiV1Routes())
+
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		t.Fatalf("marshal: %s", err)
+	}
+
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+	for _, match := range regexp.MustCompile(`"#/components/schemas/(\w+)"`).FindAllSubmatch(encoded, -1) {
+		if _, ok := schemas[string(match[1])]; !ok {
+			t.Errorf("$ref to the unknown schema %s", match[1])
+		}
+	}
+
+	for _, name := range []string{"TopicsWrapper", "MessageWrapper", "TopicWrapper", "GetNewMessages", "SelectMessage", "MqttCredentials"} {
+		if _, ok := schemas[name]; !ok {
+			t.Errorf("schema %s is missing", name)
+		}
+	}
+}
+
+func TestOpenAPISchemaFollowsJSONTags(t *testing.T) {
+	document := buildOpenAPI(apiV1Routes())
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+
+	properties := schemas["APIError"].(map[string]any)["properties"].(map[string]any)
+	for _, name := range []string{"code", "message", "details"} {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("APIError has no property %s, got %v", name, properties)
+		}
+	}
+
+	message := schemas["SelectMessage"].(map[string]any)["properties"].(map[string]any)
+	envelopeDate, _ := json.Marshal(message["EnvelopeDate"])
+	if string(envelopeDate) != `{"format":"date-time","type":["string","null"]}` {
+		t.Errorf("SelectMessage.EnvelopeDate is %s, want a nullable date-time", envelopeDate)
+	}
+}
@@ -0,0 +1,507 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gofiber/fiber/v2"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - PROBE_MAX_PATTERNS    : How many topic patterns one probe may try.
+// - PROBE_DEFAULT_TIMEOUT : How long a probe waits for its own publish to come back, if it does not say otherwise.
+// - PROBE_MAX_TIMEOUT     : How long a probe may wait for its own publish to come back at most.
+// - PROBE_MAX_DURATION    : How long one probe may take at most. The patterns that are left when it is over are not tried, they get PROBE_ERROR.
+// - PROBE_LINGER          : How long the topics of a probe are still kept out of the ingest after it, the MQTT-Client of the server may get the test messages later than the probe.
+// - PROBE_TOPIC_LEVEL     : The level that stands in for the wildcards of a pattern in the topic that is published to.
+// - SUBACK_FAILURE        : The SUBACK return code of MQTT 3.1.1 for a refused subscription.
+// - PROBE_NO_PUBACK_CODES : The `PubackReasonCodes` of the response of a probe. The PUBACK reason codes of MQTT 5 can not be read, as the probe only speaks MQTT 3.1.1.
+//
+// # Author
+// - Polariusz
+const PROBE_MAX_PATTERNS = 50
+const PROBE_DEFAULT_TIMEOUT = time.Second
+const PROBE_MAX_TIMEOUT = 10 * time.Second
+const PROBE_MAX_DURATION = 30 * time.Second
+const PROBE_LINGER = time.Second
+const PROBE_TOPIC_LEVEL = "acl-probe"
+const SUBACK_FAILURE = 0x80
+const PROBE_NO_PUBACK_CODES = "Not available: the probe only speaks MQTT 3.1.1, which has no PUBACK reason codes. Publish.Code is always null, a publish is judged by whether the test message comes back."
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - What a probe found out about a subscribe or a publish.
+//   - `allowed`   : The broker granted the subscription, or delivered the publish back to the probe.
+//   - `denied`    : The broker refused the subscription, dropped the publish silently or closed the connection.
+//   - `unverified`: The broker took the publish, but the probe could not subscribe to see whether it was delivered.
+//   - `error`     : The broker did not answer in time, or the connection failed.
+const (
+	PROBE_ALLOWED = "allowed"
+	PROBE_DENIED = "denied"
+	PROBE_UNVERIFIED = "unverified"
+	PROBE_ERROR = "error"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Patterns":["<P>"],"QoS":<Q>,"Retain":<R>,"TimeoutMs":<T>}
+//   - <P> : The topic filters to try, wildcards are allowed
+//   - <Q> : The QoS of the subscriptions and publishes
+//   - <R> : If true, the test messages are retained, to find out whether retaining is allowed. They are cleared afterwards.
+//   - <T> : How long to wait for a publish to come back, 1000 by default, at most 10000
+//
+// # Used in
+// - PostAclProbeHandler()
+//
+// # Author
+// - Polariusz
+type ProbeWrapper struct {
+	Patterns []string
+	QoS int
+	Retain bool
+	TimeoutMs int
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Result":"<R>","Code":<C>,"Detail":"<D>"}
+//   - <R> : One of PROBE_*
+//   - <C> : The SUBACK return code of a subscribe, 0, 1 or 2 for the granted QoS and 128 for a failure. Always null for a publish, the PUBACK reason codes of MQTT 5 are not available, see PROBE_NO_PUBACK_CODES.
+//   - <D> : Why, for a person to read
+//
+// # Author
+// - Polariusz
+type ProbeOutcome struct {
+	Result string
+	Code *int
+	Detail string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Pattern":"<P>","Topic":"<T>","Subscribe":<ProbeOutcome>,"Publish":<ProbeOutcome>,"RetainedCleared":<C>}
+//   - <T> : The topic that was published to, the wildcards of the pattern are replaced by `acl-probe`
+//   - <C> : Whether a retained test message was cleared with an empty retained message
+//
+// # Author
+// - Polariusz
+type ProbeRow struct {
+	Pattern string
+	Topic string
+	Subscribe ProbeOutcome
+	Publish ProbeOutcome
+	RetainedCleared bool
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The structure holds the topics that running probes publish to, so that their test messages are not taken for messages of the broker.
+// - The MQTT-Client of the server may be subscribed to the topics of a probe, the test messages would be stored and sent to the webhooks and alerts.
+//
+// # Used in
+// - struct ServerState
+// - createMessageHandler()
+//
+// # Author
+// - Polariusz
+type ProbeTraffic struct {
+	mutex sync.Mutex
+	topics map[string]int
+}
+
+// # Author
+// - Polariusz
+func NewProbeTraffic() *ProbeTraffic {
+	return &ProbeTraffic{
+		topics: make(map[string]int),
+	}
+}
+
+// # Description
+// - The method shall keep the argument topics out of the ingest, until they are removed as often as they were added.
+//
+// # Author
+// - Polariusz
+func (pt *ProbeTraffic) add(topics []string) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	for _, topic := range topics {
+		pt.topics[topic]++
+	}
+}
+
+// # Description
+// - The method shall let the argument topics into the ingest again after PROBE_LINGER.
+//
+// # Author
+// - Polariusz
+func (pt *ProbeTraffic) remove(topics []string) {
+	time.AfterFunc(PROBE_LINGER, func() {
+		pt.mutex.Lock()
+		defer pt.mutex.Unlock()
+
+		for _, topic := range topics {
+			if pt.topics[topic]--; pt.topics[topic] <= 0 {
+				delete(pt.topics, topic)
+			}
+		}
+	})
+}
+
+// # Description
+// - The method shall tell whether a message is a test message of a running probe: a `{"aclProbe":...}` message, or the empty message that clears a retained one, on a topic of the probe.
+//
+// # Author
+// - Polariusz
+func (pt *ProbeTraffic) isProbe(topic string, payload []byte) bool {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	return pt.topics[topic] > 0 && (len(payload) == 0 || bytes.HasPrefix(payload, []byte(`{"aclProbe":`)))
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall fill in the defaults of the probe and check it.
+//
+// # Returns
+// - error when the probe is not valid, the message can be shown to the client
+//
+// # Author
+// - Polariusz
+func validateProbe(probeWrapper *ProbeWrapper) error {
+	if len(probeWrapper.Patterns) == 0 || len(probeWrapper.Patterns) > PROBE_MAX_PATTERNS {
+		return fmt.Errorf("Patterns must have between 1 and %d topic filters", PROBE_MAX_PATTERNS)
+	}
+	for _, pattern := range probeWrapper.Patterns {
+		if !validTopicFilter(pattern) {
+			return fmt.Errorf("Pattern %q is not a valid topic filter", pattern)
+		}
+	}
+	if probeWrapper.QoS < 0 || probeWrapper.QoS > 2 {
+		return fmt.Errorf("QoS must be 0, 1 or 2")
+	}
+	if probeWrapper.TimeoutMs == 0 {
+		probeWrapper.TimeoutMs = int(PROBE_DEFAULT_TIMEOUT / time.Millisecond)
+	}
+	if probeWrapper.TimeoutMs < 0 || time.Duration(probeWrapper.TimeoutMs)*time.Millisecond > PROBE_MAX_TIMEOUT {
+		return fmt.Errorf("TimeoutMs must be between 1 and %d", PROBE_MAX_TIMEOUT/time.Millisecond)
+	}
+	return nil
+}
+
+// # Description
+// - The function shall return the topic that a probe publishes to for a pattern, every wildcard level becomes PROBE_TOPIC_LEVEL so the topic matches the pattern.
+//
+// # Author
+// - Polariusz
+func probeTopic(pattern string) string {
+	levels := strings.Split(pattern, "/")
+	for index, level := range levels {
+		if level == "+" || level == "#" {
+			levels[index] = PROBE_TOPIC_LEVEL
+		}
+	}
+	return strings.Join(levels, "/")
+}
+
+// # Description
+// - The function shall return the SUBACK return code of a subscription, paho keeps them in the token of a subscribe.
+//
+// # Author
+// - Polariusz
+func subackCode(token mqtt.Token, filter string) (int, bool) {
+	subscribeToken, ok := token.(interface{ Result() map[string]byte })
+	if !ok {
+		return 0, false
+	}
+	code, ok := subscribeToken.Result()[filter]
+	return int(code), ok
+}
+
+// | Date of change | By        | Comment        |
+// +----------------+-----------+----------------+
+// | 2026-10-19     | Polariusz | Created        |
+// | 2026-10-19     | Polariusz | added deadline |
+//
+// # Description
+// - A probe of the ACL of the broker for the identity of the MQTT-Client, with a MQTT-Client of its own.
+// - No wait of the probe lasts past the `deadline`, see PROBE_MAX_DURATION.
+//
+// # Author
+// - Polariusz
+type aclProbe struct {
+	client mqtt.Client
+	options *mqtt.ClientOptions
+	config ProbeWrapper
+	marker string
+	deadline time.Time
+}
+
+// # Description
+// - The method shall return how long the probe may wait for the broker, the timeout of the probe or the time left until the deadline, whichever is shorter.
+//
+// # Author
+// - Polariusz
+func (ap *aclProbe) timeout() time.Duration {
+	return min(time.Duration(ap.config.TimeoutMs)*time.Millisecond, time.Until(ap.deadline))
+}
+
+// # Description
+// - The method shall connect the MQTT-Client of the probe, also again after the broker has closed the connection.
+//
+// # Author
+// - Polariusz
+func (ap *aclProbe) connect() error {
+	if ap.client != nil && ap.client.IsConnected() {
+		return nil
+	}
+	ap.client = newMqttClient(ap.options)
+	token := ap.client.Connect()
+	if !token.WaitTimeout(REQUEST_CONNECT_TIMEOUT) {
+		return fmt.Errorf("The broker did not answer the connect in time")
+	}
+	return token.Error()
+}
+
+// # Description
+// - The method shall close the connection of the probe.
+//
+// # Author
+// - Polariusz
+func (ap *aclProbe) close() {
+	if ap.client != nil && ap.client.IsConnected() {
+		ap.client.Disconnect(250)
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall try one pattern:
+//   - It subscribes to the pattern and keeps the SUBACK return code.
+//   - It publishes a test message to a topic of the pattern. If the subscription was granted, the message must come back within the timeout, otherwise the broker dropped it.
+//   - A retained test message is cleared with an empty retained message, whether it was seen or not.
+//   - It unsubscribes again.
+//
+// # Author
+// - Polariusz
+func (ap *aclProbe) try(pattern string) ProbeRow {
+	row := ProbeRow{Pattern: pattern, Topic: probeTopic(pattern)}
+	if err := ap.connect(); err != nil {
+		row.Subscribe = ProbeOutcome{Result: PROBE_ERROR, Detail: fmt.Sprintf("Could not connect: %s", err)}
+		row.Publish = row.Subscribe
+		return row
+	}
+
+	payload := fmt.Sprintf(`{"aclProbe":"%s","pattern":%q}`, ap.marker, pattern)
+	delivered := make(chan struct{}, 1)
+	qos := byte(ap.config.QoS)
+
+	subscribed := false
+	token := ap.client.Subscribe(pattern, qos, func(_ mqtt.Client, msg mqtt.Message) {
+		if msg.Topic() == row.Topic && string(msg.Payload()) == payload {
+			select {
+			case delivered <- struct{}{}:
+			default:
+			}
+		}
+	})
+	switch {
+	case !token.WaitTimeout(ap.timeout()):
+		row.Subscribe = ProbeOutcome{Result: PROBE_ERROR, Detail: "The broker did not answer the subscribe in time"}
+	case token.Error() != nil:
+		row.Subscribe = ProbeOutcome{Result: PROBE_ERROR, Detail: token.Error().Error()}
+	default:
+		code, ok := subackCode(token, pattern)
+		switch {
+		case !ok:
+			row.Subscribe = ProbeOutcome{Result: PROBE_ERROR, Detail: "The SUBACK has no return code for the pattern"}
+		case code == SUBACK_FAILURE:
+			row.Subscribe = ProbeOutcome{Result: PROBE_DENIED, Code: &code, Detail: "The broker refused the subscription"}
+		default:
+			subscribed = true
+			row.Subscribe = ProbeOutcome{Result: PROBE_ALLOWED, Code: &code, Detail: fmt.Sprintf("Granted with QoS %d", code)}
+		}
+	}
+	if !ap.client.IsConnected() {
+		row.Subscribe = ProbeOutcome{Result: PROBE_DENIED, Detail: "The broker closed the connection on the subscribe"}
+		if err := ap.connect(); err != nil {
+			row.Publish = ProbeOutcome{Result: PROBE_ERROR, Detail: fmt.Sprintf("Could not connect again: %s", err)}
+			return row
+		}
+	}
+
+	token = ap.client.Publish(row.Topic, qos, ap.config.Retain, payload)
+	switch {
+	case !token.WaitTimeout(ap.timeout()):
+		row.Publish = ProbeOutcome{Result: PROBE_ERROR, Detail: "The broker did not acknowledge the publish in time"}
+	case token.Error() != nil:
+		row.Publish = ProbeOutcome{Result: PROBE_ERROR, Detail: token.Error().Error()}
+	case !subscribed:
+		row.Publish = ProbeOutcome{Result: PROBE_UNVERIFIED, Detail: "The broker took the publish, without the subscription it can not be seen whether it was delivered"}
+	default:
+		select {
+		case <-delivered:
+			row.Publish = ProbeOutcome{Result: PROBE_ALLOWED, Detail: "The test message came back"}
+		case <-time.After(ap.timeout()):
+			if time.Now().Before(ap.deadline) {
+				row.Publish = ProbeOutcome{Result: PROBE_DENIED, Detail: fmt.Sprintf("The test message did not come back within %d ms, the broker dropped it", ap.config.TimeoutMs)}
+			} else {
+				row.Publish = ProbeOutcome{Result: PROBE_ERROR, Detail: "The probe ran out of time before the test message came back"}
+			}
+		}
+	}
+	if !ap.client.IsConnected() {
+		row.Publish = ProbeOutcome{Result: PROBE_DENIED, Detail: "The broker closed the connection on the publish"}
+		if err := ap.connect(); err != nil {
+			return row
+		}
+	}
+
+	// The retained test message is cleared also when the probe ran out of time, it waits at least a moment for it.
+	if ap.config.Retain {
+		token = ap.client.Publish(row.Topic, qos, true, "")
+		row.RetainedCleared = token.WaitTimeout(max(ap.timeout(), PROBE_DEFAULT_TIMEOUT)) && token.Error() == nil
+	}
+	if subscribed {
+		ap.client.Unsubscribe(pattern).WaitTimeout(ap.timeout())
+	}
+	return row
+}
+
+// | Date of change | By        | Comment                |
+// +----------------+-----------+------------------------+
+// | 2026-10-19     | Polariusz | Created                |
+// | 2026-10-19     | Polariusz | PUBACK codes said      |
+// | 2026-10-19     | Polariusz | Unlocked while waiting |
+// | 2026-10-19     | Polariusz | Time limit, no ingest  |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall find out which patterns the identity of the MQTT-Client may subscribe and publish to, as an allow/deny matrix.
+// - The method shall accept a jsonified structure that follows the struct ProbeWrapper.
+// - The method shall need the role operator for every pattern, as the probe publishes test messages to them.
+// - The probe connects with the credentials of the MQTT-Client and the ClientId `<ClientId>-probe-<random>`, a broker with an ACL by ClientId may treat it differently.
+// - The test message is `{"aclProbe":"<ID>","pattern":"<P>"}`, so subscribers can tell it apart.
+// - The MQTT-Client only speaks MQTT 3.1.1, so the PUBACK reason codes of MQTT 5 are not available, also when the broker supports MQTT 5. The response says so in `PubackReasonCodes`.
+//   - A publish is allowed if it comes back through the subscription of the probe.
+// - The probe takes PROBE_MAX_DURATION at most. The patterns that were not tried by then get PROBE_ERROR, the response is still a 200 (Ok).
+// - The test messages are kept out of the ingest, the webhooks and the alerts while the probe runs, see ProbeTraffic.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"ClientId":"<C>","Protocol":"MQTT 3.1.1","PubackReasonCodes":"`const PROBE_NO_PUBACK_CODES`","results":[<ProbeRow>]}
+// - 400 (Bad Request): `APIErrorEnvelope`
+// - 401 (Unauthorized): `APIErrorEnvelope`, the MQTT-Client is not connected, or disconnected since
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 503 (Service Unavailable): `APIErrorEnvelope`, the probe could not connect
+//
+// # Author
+// - Polariusz
+func PostAclProbeHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_PROBE}
+		// The lock of the database is given up while the broker is waited for, the audit log is the one of the database that is selected at the end.
+		defer func() {
+			relockDatabase(c, serverState)
+			serverState.auditLog.record(c, &record)
+		}()
+
+		if serverState.mqttClient == nil || !serverState.mqttClient.IsConnected() || serverState.userCreds.Ip == "" {
+			return writeAPIError(c, fiber.StatusUnauthorized, API_ERROR_NOT_CONNECTED, "The MQTT-Client is not connected to any brokers.", nil)
+		}
+
+		var probeWrapper ProbeWrapper
+		if err := c.BodyParser(&probeWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+		if err := validateProbe(&probeWrapper); err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+
+		connected := serverState.connected
+		record.about(connected, probeWrapper.Patterns...)
+		if ok, err := authorize(c, serverState, ROLE_OPERATOR, connected.BrokerId, probeWrapper.Patterns...); !ok {
+			return err
+		}
+
+		random := make([]byte, 4)
+		if _, err := rand.Read(random); err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while creating the ClientId", map[string]any{"Error": err.Error()})
+		}
+		credentials := serverState.userCreds
+		credentials.ClientId = fmt.Sprintf("%s-probe-%s", credentials.ClientId, hex.EncodeToString(random))
+		releaseDatabase(c, serverState)
+		probe := &aclProbe{
+			options: mqttClientOptions(credentials).SetAutoReconnect(false).SetConnectTimeout(REQUEST_CONNECT_TIMEOUT),
+			config: probeWrapper,
+			marker: hex.EncodeToString(random),
+			deadline: time.Now().Add(PROBE_MAX_DURATION),
+		}
+
+		topics := make([]string, 0, len(probeWrapper.Patterns))
+		for _, pattern := range probeWrapper.Patterns {
+			topics = append(topics, probeTopic(pattern))
+		}
+		serverState.probeTraffic.add(topics)
+		defer serverState.probeTraffic.remove(topics)
+
+		if err := probe.connect(); err != nil {
+			return writeAPIError(c, fiber.StatusServiceUnavailable, API_ERROR_UNAVAILABLE, "The probe could not connect to the broker", map[string]any{"Error": err.Error()})
+		}
+		defer probe.close()
+
+		results := make([]ProbeRow, 0, len(probeWrapper.Patterns))
+		for _, pattern := range probeWrapper.Patterns {
+			if !time.Now().Before(probe.deadline) {
+				outOfTime := ProbeOutcome{Result: PROBE_ERROR, Detail: fmt.Sprintf("Not tried, the probe ran out of its %s", PROBE_MAX_DURATION)}
+				results = append(results, ProbeRow{Pattern: pattern, Topic: probeTopic(pattern), Subscribe: outOfTime, Publish: outOfTime})
+				continue
+			}
+			results = append(results, probe.try(pattern))
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"ClientId": credentials.ClientId,
+			"Protocol": "MQTT 3.1.1",
+			"PubackReasonCodes": PROBE_NO_PUBACK_CODES,
+			"results": results,
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"database"
+	"strings"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestProbeTopic(t *testing.T) {
+	for pattern, want := range map[string]string{
+		"plant/line1/temp": "plant/line1/temp",
+		"plant/+/temp": "plant/acl-probe/temp",
+		"plant/#": "plant/acl-probe",
+		"#": "acl-probe",
+		"+/+": "acl-probe/acl-probe",
+	} {
+		if got := probeTopic(pattern); got != want || !topicMatchesFilter(pattern, got) {
+			t.Errorf("probeTopic(%s) = %s, want %s", pattern, got, want)
+		}
+	}
+}
+
+func TestProbeTraffic(t *testing.T) {
+	traffic := NewProbeTraffic()
+	topics := []string{"plant/acl-probe/temp"}
+	traffic.add(topics)
+
+	for _, message := range []struct {
+		topic string
+		payload string
+		probe bool
+	}{
+		{"plant/acl-probe/temp", `{"aclProbe":"00ff00ff","pattern":"plant/+/temp"}`, true},
+		{"plant/acl-probe/temp", "", true},
+		{"plant/acl-probe/temp", "21.5", false},
+		{"plant/line1/temp", `{"aclProbe":"00ff00ff","pattern":"plant/+/temp"}`, false},
+	} {
+		if got := traffic.isProbe(message.topic, []byte(message.payload)); got != message.probe {
+			t.Errorf("isProbe(%s, %q) = %t, want %t", message.topic, message.payload, got, message.probe)
+		}
+	}
+
+	traffic.remove(topics)
+	if !traffic.isProbe("plant/acl-probe/temp", nil) {
+		t.Errorf("the topic was let in before PROBE_LINGER")
+	}
+	time.Sleep(PROBE_LINGER + 100*time.Millisecond)
+	if traffic.isProbe("plant/acl-probe/temp", nil) {
+		t.Errorf("the topic is still kept out after PROBE_LINGER")
+	}
+}
+
+func TestProbeDeadline(t *testing.T) {
+	probe := &aclProbe{config: ProbeWrapper{TimeoutMs: 1000}, deadline: time.Now().Add(time.Hour)}
+	if timeout := probe.timeout(); timeout != time.Second {
+		t.Errorf("the timeout long before the deadline: %s", timeout)
+	}
+	probe.deadline = time.Now().Add(100 * time.Millisecond)
+	if timeout := probe.timeout(); timeout > 100*time.Millisecond {
+		t.Errorf("the timeout just before the deadline: %s", timeout)
+	}
+}
+
+func TestAclProbe(t *testing.T) {
+	con, err := database.OpenDatabase(database.InMemoryDatabase)
+	if err != nil {
+		t.Fatalf("OpenDatabase: %s", err)
+	}
+	defer con.Close()
+	if err := database.SetupDatabase(con); err != nil {
+		t.Fatalf("SetupDatabase: %s", err)
+	}
+	accounts, err := NewAccountManager(con)
+	if err != nil {
+		t.Fatalf("NewAccountManager: %s", err)
+	}
+
+	broker := newMemoryBroker()
+	broker.denySubscribe["secret/#"] = true
+	broker.denySubscribe["readonly/+/cmd"] = true
+	broker.denyPublish["sensors/acl-probe/temp"] = true
+	defer func(original func(*mqtt.ClientOptions) mqtt.Client) { newMqttClient = original }(newMqttClient)
+	newMqttClient = broker.newClient
+
+	serverState := &ServerState{con: con, store: database.NewSqliteStore(con), accounts: accounts, replayManager: NewReplayManager(), probeTraffic: NewProbeTraffic()}
+	server := fiber.New()
+	addRoutes(server, serverState)
+
+	if status, _ := request(t, server, "POST", "/api/v1/probes/acl", `{"Patterns":["a"]}`, nil); status != fiber.StatusUnauthorized {
+		t.Errorf("a probe without a connection: %d", status)
+	}
+	if status, body := request(t, server, "POST", "/credentials", `{"Ip":"127.0.0.1","Port":"1883","ClientId":"explorer"}`, nil); status != fiber.StatusOK {
+		t.Fatalf("connect: %d %v", status, body)
+	}
+
+	for name, body := range map[string]string{
+		"no patterns": `{"Patterns":[]}`,
+		"bad pattern": `{"Patterns":["a/#/b"]}`,
+		"bad qos": `{"Patterns":["a"],"QoS":3}`,
+		"long wait": `{"Patterns":["a"],"TimeoutMs":10001}`,
+	} {
+		if status, response := request(t, server, "POST", "/api/v1/probes/acl", body, nil); status != fiber.StatusBadRequest {
+			t.Errorf("%s: %d %v", name, status, response)
+		}
+	}
+
+	status, body := request(t, server, "POST", "/api/v1/probes/acl", `{"Patterns":["devices/1/state","sensors/+/temp","secret/#","readonly/+/cmd"],"QoS":1,"Retain":true,"TimeoutMs":100}`, nil)
+	if status != fiber.StatusOK || body["PubackReasonCodes"] != PROBE_NO_PUBACK_CODES {
+		t.Fatalf("the probe: %d %v", status, body)
+	}
+	want := map[string][2]string{
+		"devices/1/state": {PROBE_ALLOWED, PROBE_ALLOWED},
+		"sensors/+/temp": {PROBE_ALLOWED, PROBE_DENIED},
+		"secret/#": {PROBE_DENIED, PROBE_UNVERIFIED},
+		"readonly/+/cmd": {PROBE_DENIED, PROBE_UNVERIFIED},
+	}
+	results := body["results"].([]any)
+	if len(results) != len(want) {
+		t.Fatalf("the results: %v", results)
+	}
+	for _, result := range results {
+		row := result.(map[string]any)
+		subscribe := row["Subscribe"].(map[string]any)
+		publish := row["Publish"].(map[string]any)
+		expected := want[row["Pattern"].(string)]
+		if subscribe["Result"] != expected[0] || publish["Result"] != expected[1] || publish["Code"] != nil || row["RetainedCleared"] != true {
+			t.Errorf("the row of %s: %v", row["Pattern"], row)
+		}
+		if expected[0] == PROBE_DENIED && subscribe["Code"] != float64(SUBACK_FAILURE) || expected[0] == PROBE_ALLOWED && subscribe["Code"] != float64(1) {
+			t.Errorf("the SUBACK code of %s: %v", row["Pattern"], subscribe)
+		}
+	}
+
+	if !serverState.probeTraffic.isProbe("sensors/acl-probe/temp", []byte(`{"aclProbe":"x"}`)) {
+		t.Errorf("the test messages of the probe are let into the ingest right after it")
+	}
+
+	broker.mutex.Lock()
+	if len(broker.retained) != 0 || len(broker.subscriptions) != 0 {
+		t.Errorf("the probe left retained messages %v or subscriptions %v", broker.retained, broker.subscriptions)
+	}
+	if clientId := broker.clientIds[len(broker.clientIds)-1]; !strings.HasPrefix(clientId, "explorer-probe-") || len(clientId) != len("explorer-probe-")+8 {
+		t.Errorf("the probe connected as %s", clientId)
+	}
+	broker.mutex.Unlock()
+
+	if status, body := request(t, server, "POST", "/disconnect", "", nil); status != fiber.StatusOK {
+		t.Fatalf("disconnect: %d %v", status, body)
+	}
+	if status, _ := request(t, server, "POST", "/api/v1/probes/acl", `{"Patterns":["a"]}`, nil); status != fiber.StatusUnauthorized {
+		t.Errorf("a probe after the disconnect: %d", status)
+	}
+}
@@ -0,0 +1,532 @@
+package main
+
+import (
+	"database"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The project that is selected when the server starts. Its database is `DatabaseConfig.Path`.
+// - Every other project has its own database `<ProjectDir>/<name>.db`.
+//
+// # Author
+// - Polariusz
+const DEFAULT_PROJECT = "default"
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - Names of projects end up in file names, so only letters, digits, '-' and '_' are allowed.
+//
+// # Author
+// - Polariusz
+var projectNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// | Date of change | By        | Comment     |
+// +----------------+-----------+-------------+
+// | 2026-10-19     | Polariusz | Created     |
+// | 2026-10-19     | Polariusz | MemoryStore |
+//
+// # Description
+// - Where the databases are stored.
+// - If `InMemory` is set, no file is written and every project is lost when the server stops.
+//   The brokers, users, topics and messages of a project are kept in a MemoryStore, the rules, accounts and the audit log in an SQLite database that only lives in memory.
+// - Backups made through the API are written into `BackupDir`.
+//
+// # Used in
+// - NewProjectManager()
+//
+// # Author
+// - Polariusz
+type DatabaseConfig struct {
+	Path string
+	InMemory bool
+	ProjectDir string
+	BackupDir string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Stores  |
+//
+// # Description
+// - The structure keeps the database and the store of every project that was opened since the server started, so that switching back and forth does not lose in-memory databases.
+// - `switchMutex` makes sure that only one switch happens at a time.
+//
+// # Used in
+// - struct ServerState
+//
+// # Author
+// - Polariusz
+type ProjectManager struct {
+	switchMutex sync.Mutex
+	mutex sync.Mutex
+	config DatabaseConfig
+	current string
+	opened map[string]*sql.DB
+	stores map[string]database.Store
+}
+
+// # Author
+// - Polariusz
+func NewProjectManager(config DatabaseConfig) *ProjectManager {
+	return &ProjectManager{
+		config: config,
+		current: DEFAULT_PROJECT,
+		opened: make(map[string]*sql.DB),
+		stores: make(map[string]database.Store),
+	}
+}
+
+// # Author
+// - Polariusz
+func (pm *ProjectManager) path(name string) string {
+	if pm.config.InMemory {
+		return database.InMemoryDatabase
+	}
+	if name == DEFAULT_PROJECT {
+		return pm.config.Path
+	}
+	return filepath.Join(pm.config.ProjectDir, name+".db")
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Store   |
+//
+// # Description
+// - The method shall return the connection to the database of the project, opening and migrating it if it isn't open yet.
+// - The store of the project is a MemoryStore if `InMemory` is set, a SqliteStore on the database otherwise.
+//
+// # Returns
+// - *sql.DB of the project
+// - database.Store with the data of the project
+// - error when:
+//   - The project directory cannot be created
+//   - The database cannot be opened or migrated
+//
+// # Author
+// - Polariusz
+func (pm *ProjectManager) open(name string) (*sql.DB, database.Store, error) {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	if con, ok := pm.opened[name]; ok {
+		return con, pm.stores[name], nil
+	}
+
+	path := pm.path(name)
+	if name != DEFAULT_PROJECT && !pm.config.InMemory {
+		if err := os.MkdirAll(pm.config.ProjectDir, 0755); err != nil {
+			return nil, nil, fmt.Errorf("Error while creating the project directory!\nErr: %s\n", err)
+		}
+	}
+
+	con, err := database.OpenDatabase(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := database.SetupDatabase(con); err != nil {
+		con.Close()
+		return nil, nil, err
+	}
+
+	var store database.Store = database.NewSqliteStore(con)
+	if pm.config.InMemory {
+		store = database.NewMemoryStore()
+	}
+
+	pm.opened[name] = con
+	pm.stores[name] = store
+	return con, store, nil
+}
+
+// # Author
+// - Polariusz
+func (pm *ProjectManager) currentName() string {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	return pm.current
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall return the names of all projects: the opened ones and the ones that have a database file in the project directory.
+//
+// # Author
+// - Polariusz
+func (pm *ProjectManager) list() []string {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	known := map[string]bool{DEFAULT_PROJECT: true}
+	for name := range pm.opened {
+		known[name] = true
+	}
+	if !pm.config.InMemory {
+		fileList, _ := filepath.Glob(filepath.Join(pm.config.ProjectDir, "*.db"))
+		for _, file := range fileList {
+			name := strings.TrimSuffix(filepath.Base(file), ".db")
+			if projectNamePattern.MatchString(name) {
+				known[name] = true
+			}
+		}
+	}
+
+	nameList := []string{}
+	for name := range known {
+		nameList = append(nameList, name)
+	}
+	sort.Strings(nameList)
+
+	return nameList
+}
+
+// # Author
+// - Polariusz
+func (pm *ProjectManager) closeAll() {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	for name, con := range pm.opened {
+		con.Close()
+		delete(pm.opened, name)
+		delete(pm.stores, name)
+	}
+}
+
+// | Date of change | By        | Comment        |
+// +----------------+-----------+----------------+
+// | 2026-10-19     | Polariusz | Created        |
+// | 2026-10-19     | Polariusz | Database lock  |
+// | 2026-10-19     | Polariusz | Seeds accounts |
+//
+// # Description
+// - The method shall make the project `name` the one that all handlers work with.
+// - The IDs of brokers, users and topics belong to a database, so everything that holds them is reset, see detachDatabase().
+// - The swap holds `databaseLock` of the ServerState, it waits for the requests that are running. The caller must not hold it, see releaseDatabase().
+// - If the project has no accounts yet but the server requires a login, the account of `session` is copied into it as an admin, see seedAccount().
+//
+// # Returns
+// - error when the database of the project cannot be opened or seeded
+//
+// # Author
+// - Polariusz
+func (pm *ProjectManager) switchTo(serverState *ServerState, name string, session *Session) error {
+	pm.switchMutex.Lock()
+	defer pm.switchMutex.Unlock()
+
+	if name == pm.currentName() {
+		return nil
+	}
+
+	con, store, err := pm.open(name)
+	if err != nil {
+		return err
+	}
+
+	serverState.databaseLock.Lock()
+	defer serverState.databaseLock.Unlock()
+
+	account, err := serverState.accounts.carryAccount(session)
+	if err != nil {
+		return err
+	}
+	if err := seedAccount(con, account); err != nil {
+		return err
+	}
+
+	detachDatabase(serverState)
+	attachDatabase(serverState, con, store)
+
+	pm.mutex.Lock()
+	pm.current = name
+	pm.mutex.Unlock()
+
+	return nil
+}
+
+// | Date of change | By        | Comment                        |
+// +----------------+-----------+--------------------------------+
+// | 2026-10-19     | Polariusz | Created                        |
+// | 2026-10-19     | Polariusz | Webhooks                       |
+// | 2026-10-19     | Polariusz | Bridges                        |
+// | 2026-10-19     | Polariusz | Alerts                         |
+// | 2026-10-19     | Polariusz | Schedules                      |
+// | 2026-10-19     | Polariusz | Benchmarks                     |
+// | 2026-10-19     | Polariusz | Workers stop before the client |
+//
+// # Description
+// - The function shall stop everything that writes into the database or remembers IDs of its rows:
+//   - Running replay jobs are cancelled.
+//   - The retention janitor is stopped.
+//   - A running benchmark is cancelled, its result is written into its database.
+//   - The schedules are stopped, they go on when their database is attached.
+//   - The alert rules are no longer checked and their streams end. What was firing is forgotten.
+//   - The webhooks are stopped, their waiting deliveries stay in the database.
+//   - The bridges are stopped, the enabled ones start again when their database is attached.
+//   - The MQTT-Client is disconnected, the client has to send the credentials again.
+//   - The ingest queue writes what it still holds and is closed.
+// - The caller must hold `databaseLock` of the ServerState, and attachDatabase() must be called afterwards.
+//
+// # Author
+// - Polariusz
+func detachDatabase(serverState *ServerState) {
+	serverState.replayManager.cancelAll()
+	serverState.retentionJanitor.close()
+	serverState.benchmarks.close()
+	serverState.scheduler.close()
+	serverState.alerts.close()
+	serverState.webhooks.close()
+	serverState.bridges.close()
+	if serverState.mqttClient != nil && serverState.mqttClient.IsConnected() {
+		serverState.mqttClient.Disconnect(250)
+	}
+	serverState.mqttClient = nil
+	serverState.userCreds = MqttCredentials{}
+	serverState.connected = BrokerUser{}
+	serverState.ingestQueue.close()
+}
+
+// | Date of change | By        | Comment                |
+// +----------------+-----------+------------------------+
+// | 2026-10-19     | Polariusz | Created                |
+// | 2026-10-19     | Polariusz | Webhooks               |
+// | 2026-10-19     | Polariusz | Bridges                |
+// | 2026-10-19     | Polariusz | Alerts                 |
+// | 2026-10-19     | Polariusz | Schedules              |
+// | 2026-10-19     | Polariusz | Benchmarks             |
+// | 2026-10-19     | Polariusz | Accounts and audit log |
+// | 2026-10-19     | Polariusz | Store                  |
+//
+// # Description
+// - The function shall make all handlers use the database `con` with the data in `store` and start what detachDatabase() has stopped.
+// - The caller must hold `databaseLock` of the ServerState.
+// - The accounts and the audit log of the database are used, the sessions stay logged in as far as their account is in it, see (*AccountManager).attach().
+// - The ID cache and the statistics are reset, as they belong to the previous content of the database.
+// - The deduplication rules of the database are loaded.
+// - The webhooks of the database are started, with the retries of their waiting deliveries.
+// - The bridges that are enabled in the database are started.
+// - The alert rules of the database are checked.
+// - The enabled schedules of the database are published.
+// - The benchmarks of the database that were left running are failed.
+//
+// # Author
+// - Polariusz
+func attachDatabase(serverState *ServerState, con *sql.DB, store database.Store) {
+	serverState.con = con
+	serverState.store = store
+	serverState.idCache.Clear()
+	serverState.stats = NewMessageStats()
+	serverState.ingestQueue = NewIngestQueue(serverState.store, serverState.ingestQueue.config)
+	serverState.retentionJanitor = NewRetentionJanitor(con, store)
+	serverState.retentionJanitor.start()
+	if err := serverState.deduplicator.reload(con); err != nil {
+		fmt.Printf("WARN: Running without deduplication rules\nErr:%s\n", err)
+	}
+	serverState.webhooks = NewWebhookDispatcher(con)
+	serverState.webhooks.start()
+	serverState.bridges = NewBridgeManager(con)
+	serverState.bridges.start()
+	serverState.alerts = NewAlertEngine(con, serverState.webhooks)
+	serverState.alerts.start()
+	serverState.scheduler = NewScheduler(con, serverState)
+	serverState.scheduler.start()
+	serverState.benchmarks = NewBenchmarkManager(con)
+	serverState.benchmarks.start()
+	if err := serverState.accounts.attach(con); err != nil {
+		fmt.Printf("WARN: The sessions were logged out, the accounts could not be read\nErr:%s\n", err)
+	}
+	serverState.auditLog = NewAuditLog(con)
+}
+
+// | Date of change | By        | Comment           |
+// +----------------+-----------+-------------------+
+// | 2026-10-19     | Polariusz | Created           |
+// | 2026-10-19     | Polariusz | Not while waiting |
+//
+// # Method-Type
+// - Middleware
+//
+// # Description
+// - The middleware shall hold `databaseLock` of the ServerState for reading while the request is handled, so that the database is not swapped under a handler.
+// - A handler that swaps the database gives it up first with releaseDatabase().
+// - A handler that waits on the broker gives it up too, so a switch of the project does not wait for the broker and hold back every other request meanwhile, see relockDatabase().
+// - Streams write after the handler has returned, they do not hold it.
+//
+// # Author
+// - Polariusz
+func lockDatabase(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		serverState.databaseLock.RLock()
+		c.Locals("databaseLock", true)
+		defer releaseDatabase(c, serverState)
+
+		return c.Next()
+	}
+}
+
+// # Description
+// - The function shall give up the lock that lockDatabase() holds for the request, once.
+//
+// # Author
+// - Polariusz
+func releaseDatabase(c *fiber.Ctx, serverState *ServerState) {
+	if held, _ := c.Locals("databaseLock").(bool); held {
+		c.Locals("databaseLock", false)
+		serverState.databaseLock.RUnlock()
+	}
+}
+
+// # Description
+// - The function shall take back the lock that releaseDatabase() gave up, for the rest of the request, like to write the audit log after a wait on the broker.
+// - A request that never held the lock of lockDatabase() is left as it is.
+// - The database may have been swapped meanwhile, the fields of the ServerState are read again after it.
+//
+// # Author
+// - Polariusz
+func relockDatabase(c *fiber.Ctx, serverState *ServerState) {
+	if held, ok := c.Locals("databaseLock").(bool); ok && !held {
+		serverState.databaseLock.RLock()
+		c.Locals("databaseLock", true)
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return the selected project and all known projects.
+// - The method shall need the role viewer for all brokers, a project holds all of them.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the GET-Method.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"current":"<NAME>","inMemory":<BOOL>,"projects":["<NAME>"]}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+//
+// # Author
+// - Polariusz
+func GetProjectsHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_VIEWER, 0); !ok {
+			return err
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"current": serverState.projects.currentName(),
+			"inMemory": serverState.projects.config.InMemory,
+			"projects": serverState.projects.list(),
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Name":"<N>"}
+//   - <N> : Name of the project, it is created if it does not exist yet
+//
+// # Used in
+// - PostProjectSelectHandler()
+//
+// # Author
+// - Polariusz
+type ProjectWrapper struct {
+	Name string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+// | 2026-10-19     | Polariusz | Locking |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall switch all handlers to the database of another project. The project is created if it does not exist yet.
+// - The MQTT-Client is disconnected by the switch.
+// - Every project has its own accounts. A project without accounts gets the account of the request as an admin, so it is not open to everyone.
+// - The method shall need the role admin on all brokers.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data must have a json structure that matches the struct ProjectWrapper.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"current":"<NAME>"}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+//   - {"terribleJson":"Name may only contain letters, digits, '-' and '_'"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while opening the database of the project","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func PostProjectSelectHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		var projectWrapper ProjectWrapper
+		if err := c.BodyParser(&projectWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+
+		if !projectNamePattern.MatchString(projectWrapper.Name) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"terribleJson": "Name may only contain letters, digits, '-' and '_'",
+			})
+		}
+
+		releaseDatabase(c, serverState)
+		if err := serverState.projects.switchTo(serverState, projectWrapper.Name, sessionOf(c)); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while opening the database of the project",
+				"Error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"current": serverState.projects.currentName(),
+		})
+	}
+}
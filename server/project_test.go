@@ -0,0 +1,212 @@
+package main
+
+import (
+	"database"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Builds the ServerState the way main() does, on the default project of `projects`.
+func newTestServerState(t *testing.T, projects *ProjectManager) (*ServerState, *fiber.App) {
+	t.Helper()
+
+	con, store, err := projects.open(DEFAULT_PROJECT)
+	if err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	accounts, err := NewAccountManager(con)
+	if err != nil {
+		t.Fatalf("NewAccountManager: %s", err)
+	}
+
+	serverState := &ServerState{con: con, store: store, projects: projects, accounts: accounts}
+	serverState.replayManager = NewReplayManager()
+	serverState.stats = NewMessageStats()
+	serverState.idCache = database.NewIdCache()
+	serverState.ingestQueue = NewIngestQueue(serverState.store, DefaultIngestConfig())
+	serverState.retentionJanitor = NewRetentionJanitor(con, store)
+	serverState.retentionJanitor.start()
+	serverState.deduplicator = NewDeduplicator()
+	serverState.auditLog = NewAuditLog(con)
+	serverState.webhooks = NewWebhookDispatcher(con)
+	serverState.webhooks.start()
+	serverState.bridges = NewBridgeManager(con)
+	serverState.bridges.start()
+	serverState.alerts = NewAlertEngine(con, serverState.webhooks)
+	serverState.alerts.start()
+	serverState.scheduler = NewScheduler(con, serverState)
+	serverState.scheduler.start()
+	serverState.benchmarks = NewBenchmarkManager(con)
+	serverState.benchmarks.start()
+	t.Cleanup(func() {
+		serverState.databaseLock.Lock()
+		detachDatabase(serverState)
+		serverState.databaseLock.Unlock()
+		projects.closeAll()
+	})
+
+	server := fiber.New()
+	addRoutes(server, serverState)
+	return serverState, server
+}
+
+func TestSwitchProject(t *testing.T) {
+	serverState, server := newTestServerState(t, NewProjectManager(DatabaseConfig{InMemory: true}))
+	defaultCon := serverState.con
+
+	if _, err := serverState.accounts.createAccount("admin", "correct horse"); err != nil {
+		t.Fatalf("createAccount: %s", err)
+	}
+	status, body := request(t, server, "POST", "/api/v1/sessions", `{"Username":"admin","Password":"correct horse"}`, nil)
+	if status != fiber.StatusCreated {
+		t.Fatalf("login: %d %v", status, body)
+	}
+	bearer := map[string]string{"Authorization": "Bearer " + body["token"].(string)}
+
+	// Requests that read the database go on while the project is switched back and forth.
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				req := httptest.NewRequest("GET", "/api/v1/retention/rules", nil)
+				req.Header.Set("Authorization", bearer["Authorization"])
+				if resp, err := server.Test(req, -1); err == nil {
+					resp.Body.Close()
+				}
+			}
+		}()
+	}
+	for _, name := range []string{"other", DEFAULT_PROJECT, "other"} {
+		if status, body := request(t, server, "PUT", "/api/v1/projects/current", `{"Name":"`+name+`"}`, bearer); status != fiber.StatusOK {
+			t.Errorf("select %s: %d %v", name, status, body)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if serverState.con == defaultCon || serverState.accounts.con != serverState.con || serverState.auditLog.con != serverState.con {
+		t.Errorf("the accounts and the audit log are not those of the selected project")
+	}
+	// The new project got the account that selected it, so it is not open to everyone and the session goes on.
+	if status, _ := request(t, server, "GET", "/api/v1/accounts", "", nil); status != fiber.StatusUnauthorized {
+		t.Errorf("the new project is open: %d", status)
+	}
+	if status, body := request(t, server, "GET", "/api/v1/accounts", "", bearer); status != fiber.StatusOK || len(body["accounts"].([]any)) != 1 {
+		t.Errorf("the session in the new project: %d %v", status, body)
+	}
+}
+
+func TestMemoryMode(t *testing.T) {
+	serverState, server := newTestServerState(t, NewProjectManager(DatabaseConfig{InMemory: true}))
+	store, ok := serverState.store.(*database.MemoryStore)
+	if !ok {
+		t.Fatalf("the data is not kept in a MemoryStore: %T", serverState.store)
+	}
+
+	brokerId, _ := store.InsertNewBroker(database.InsertBroker{Ip: "localhost", Port: 1883})
+	userId, _ := store.InsertNewUser(database.InsertUser{BrokerId: brokerId, ClientId: "sensor", Outsider: true})
+	topicId, _ := store.InsertNewTopic(database.InsertTopic{BrokerId: brokerId, Topic: "plant/temperature"})
+	old := time.Now().Add(-time.Hour)
+	for i := 0; i < 3; i++ {
+		store.InsertNewMessage(database.InsertMessage{UserId: userId, TopicId: topicId, BrokerId: brokerId, Message: "21", CreationDate: old, IsDuplicate: i == 0})
+	}
+	store.InsertNewMessage(database.InsertMessage{UserId: userId, TopicId: topicId, BrokerId: brokerId, Message: "22"})
+
+	status, body := request(t, server, "GET", "/api/v1/dedup/report", "", nil)
+	if status != fiber.StatusOK || body["total"] != float64(1) {
+		t.Errorf("the duplicates are not counted from the store: %d %v", status, body)
+	}
+
+	if _, err := database.InsertNewRetentionRule(serverState.con, database.InsertRetentionRule{TopicFilter: "#", MaxAgeSeconds: 60}); err != nil {
+		t.Fatalf("InsertNewRetentionRule: %s", err)
+	}
+	if run := serverState.retentionJanitor.run(); run.Deleted != 3 || run.Error != "" {
+		t.Errorf("the janitor did not delete the old messages from the store: %+v", run)
+	}
+
+	if _, err := database.InsertNewDedupRule(serverState.con, database.InsertDedupRule{BrokerId: brokerId, TopicFilter: "#", WindowSeconds: 5}); err != nil {
+		t.Fatalf("InsertNewDedupRule: %s", err)
+	}
+	status, body = request(t, server, "DELETE", "/api/v1/brokers/"+strconv.Itoa(brokerId), "", nil)
+	deleted, _ := body["deleted"].(map[string]any)
+	if status != fiber.StatusOK || deleted["Brokers"] != float64(1) || deleted["Messages"] != float64(1) || deleted["DedupRules"] != float64(1) {
+		t.Errorf("the broker is not deleted from the store and the database: %d %v", status, body)
+	}
+	if brokerList, _ := store.SelectBrokerList(); len(brokerList) != 0 {
+		t.Errorf("the broker is still in the store: %v", brokerList)
+	}
+
+	if status, body := request(t, server, "POST", "/api/v1/backups", "", nil); status != fiber.StatusConflict {
+		t.Errorf("a backup without the messages was written: %d %v", status, body)
+	}
+}
+
+// TestSwitchProjectWhileWaiting makes sure that a request that waits on the
+// broker does not hold the database, so the project can be switched meanwhile.
+func TestSwitchProjectWhileWaiting(t *testing.T) {
+	broker := newMemoryBroker()
+	defer func(original func(*mqtt.ClientOptions) mqtt.Client) { newMqttClient = original }(newMqttClient)
+	newMqttClient = broker.newClient
+
+	serverState, server := newTestServerState(t, NewProjectManager(DatabaseConfig{InMemory: true}))
+	if status, body := request(t, server, "POST", "/credentials", `{"Ip":"127.0.0.1","Port":"1883","ClientId":"explorer"}`, nil); status != fiber.StatusOK {
+		t.Fatalf("connect: %d %v", status, body)
+	}
+
+	// Nobody answers, so the request waits for its whole timeout.
+	waited := make(chan int, 1)
+	go func() {
+		req := httptest.NewRequest("POST", "/api/v1/requests", strings.NewReader(`{"Topic":"nobody/listens","TimeoutMs":3000}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := server.Test(req, -1)
+		if err != nil {
+			waited <- 0
+			return
+		}
+		resp.Body.Close()
+		waited <- resp.StatusCode
+	}()
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		broker.mutex.Lock()
+		subscribed := len(broker.subscriptions) > 0
+		broker.mutex.Unlock()
+		if subscribed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the request did not subscribe")
+		}
+	}
+
+	started := time.Now()
+	if status, body := request(t, server, "PUT", "/api/v1/projects/current", `{"Name":"other"}`, nil); status != fiber.StatusOK {
+		t.Fatalf("select other: %d %v", status, body)
+	}
+	if took := time.Since(started); took > time.Second {
+		t.Errorf("the switch waited %s for the request", took)
+	}
+
+	if status := <-waited; status != http.StatusGatewayTimeout {
+		t.Errorf("the request: %d", status)
+	}
+	entryList, _, err := serverState.auditLog.query(database.AuditFilter{Action: AUDIT_PUBLISH}, "", 10)
+	if err != nil || len(entryList) != 1 {
+		t.Errorf("the request in the audit log of the selected project: %v %v", entryList, err)
+	}
+}
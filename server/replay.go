@@ -0,0 +1,795 @@
+package main
+
+import (
+	"database"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gofiber/fiber/v2"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - States in which a replay job can be.
+//
+// # Author
+// - Polariusz
+const (
+	REPLAY_RUNNING   = "Running"
+	REPLAY_PAUSED    = "Paused"
+	REPLAY_CANCELLED = "Cancelled"
+	REPLAY_FINISHED  = "Finished"
+	REPLAY_FAILED    = "Failed"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"BrokerUserIDs":{"BrokerId":<B>,"UserId":<U>},"TopicFilter":"<F>","TimeFrom":"<DF>","TimeTo":"<DT>","PrefixFrom":"<PF>","PrefixTo":"<PT>","Speed":<S>}
+//   - <B>  : The ID of the Broker ROW whose stored messages shall be replayed
+//   - <U>  : The ID of the User ROW matched from the BrokerId from PostCredentialsHandler()'s brokerId
+//   - <F>  : MQTT topic filter, wildcards `+` and `#` are allowed
+//   - <DF> : DateTime, messages received before it are skipped
+//   - <DT> : DateTime, messages received after it are skipped. It's optional.
+//   - <PF> : Topic prefix that shall be replaced with <PT>. It's optional.
+//   - <PT> : Replacement for <PF>.
+//   - <S>  : Speed multiplier of the original timing. 1 is the original timing, 2 is twice as fast. 0 means 1.
+//
+// # Used in
+// - PostReplayStartHandler()
+//
+// # Author
+// - Polariusz
+type ReplayWrapper struct {
+	BrokerUserIDs BrokerUser
+	TopicFilter string
+	TimeFrom time.Time
+	TimeTo time.Time
+	PrefixFrom string
+	PrefixTo string
+	Speed float64
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Id":<I>}
+//   - <I> : The ID of the replay job returned by PostReplayStartHandler()
+//
+// # Used in
+// - PostReplayStatusHandler()
+// - PostReplayPauseHandler()
+// - PostReplayResumeHandler()
+// - PostReplayCancelHandler()
+//
+// # Author
+// - Polariusz
+type ReplayJobWrapper struct {
+	Id int
+}
+
+// | Date of change | By        | Comment        |
+// +----------------+-----------+----------------+
+// | 2026-10-19     | Polariusz | Created        |
+// | 2026-10-19     | Polariusz | added BrokerId |
+//
+// # Description
+// - A snapshot of a replay job that is returned to the client.
+//
+// # Author
+// - Polariusz
+type ReplayStatus struct {
+	Id int
+	BrokerId int
+	State string
+	TopicFilter string
+	Speed float64
+	Total int
+	Published int
+	StartedAt time.Time
+	FinishedAt time.Time
+	Error string
+}
+
+// | Date of change | By        | Comment      |
+// +----------------+-----------+--------------+
+// | 2026-10-19     | Polariusz | Created      |
+// | 2026-10-19     | Polariusz | added client |
+//
+// # Description
+// - The structure holds a running replay job.
+// - The `wake` channel is used to interrupt the waiting between the messages when the job is paused, resumed or cancelled.
+// - The `client` is the MQTT-Client that was connected when the job was started. The job publishes through it only, and it is cancelled when the client is replaced or dropped.
+//
+// # Author
+// - Polariusz
+type ReplayJob struct {
+	mutex sync.Mutex
+	status ReplayStatus
+	request ReplayWrapper
+	messages []database.SelectTopicMessage
+	wake chan struct{}
+	client mqtt.Client
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The structure keeps track of all replay jobs started since the server started.
+//
+// # Used in
+// - struct ServerState
+//
+// # Author
+// - Polariusz
+type ReplayManager struct {
+	mutex sync.Mutex
+	nextId int
+	jobs map[int]*ReplayJob
+}
+
+// # Author
+// - Polariusz
+func NewReplayManager() *ReplayManager {
+	return &ReplayManager{
+		nextId: 1,
+		jobs: make(map[int]*ReplayJob),
+	}
+}
+
+// # Author
+// - Polariusz
+func (rm *ReplayManager) add(job *ReplayJob) int {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	job.status.Id = rm.nextId
+	rm.jobs[rm.nextId] = job
+	rm.nextId++
+
+	return job.status.Id
+}
+
+// # Author
+// - Polariusz
+func (rm *ReplayManager) get(id int) *ReplayJob {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	return rm.jobs[id]
+}
+
+// | Date of change | By        | Comment                          |
+// +----------------+-----------+----------------------------------+
+// | 2026-10-19     | Polariusz | Created                          |
+// | 2026-10-19     | Polariusz | used when the client is replaced |
+//
+// # Description
+// - The method shall cancel all jobs that have not ended yet. It is used when the database is switched, as the jobs would replay messages of the old one, and when the MQTT-Client is replaced or disconnected, as the jobs would publish through a dead client.
+//
+// # Author
+// - Polariusz
+func (rm *ReplayManager) cancelAll() {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	for _, job := range rm.jobs {
+		job.cancel()
+	}
+}
+
+// # Author
+// - Polariusz
+func (rm *ReplayManager) list() []ReplayStatus {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	statusList := []ReplayStatus{}
+	for id := 1; id < rm.nextId; id++ {
+		if job, ok := rm.jobs[id]; ok {
+			statusList = append(statusList, job.snapshot())
+		}
+	}
+
+	return statusList
+}
+
+// # Author
+// - Polariusz
+func (job *ReplayJob) snapshot() ReplayStatus {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	return job.status
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall change the state of the job from `from` to `to` and wake the job up.
+//
+// # Returns
+// - false if the job was not in the state `from`
+//
+// # Author
+// - Polariusz
+func (job *ReplayJob) transition(from string, to string) bool {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	if job.status.State != from {
+		return false
+	}
+	job.status.State = to
+
+	select {
+	case job.wake <- struct{}{}:
+	default:
+	}
+
+	return true
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall cancel the job if it is running or paused.
+//
+// # Returns
+// - false if the job has already ended
+//
+// # Author
+// - Polariusz
+func (job *ReplayJob) cancel() bool {
+	return job.transition(REPLAY_RUNNING, REPLAY_CANCELLED) || job.transition(REPLAY_PAUSED, REPLAY_CANCELLED)
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall block for the argument `delay`. The time spent while paused does not count into the delay.
+//
+// # Returns
+// - false if the job was cancelled while waiting
+//
+// # Author
+// - Polariusz
+func (job *ReplayJob) wait(delay time.Duration) bool {
+	for {
+		state := job.snapshot().State
+		if state == REPLAY_CANCELLED {
+			return false
+		}
+		if state == REPLAY_PAUSED {
+			<-job.wake
+			continue
+		}
+		if delay <= 0 {
+			return true
+		}
+
+		started := time.Now()
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+			delay = 0
+		case <-job.wake:
+			timer.Stop()
+			delay -= time.Since(started)
+		}
+	}
+}
+
+// # Author
+// - Polariusz
+func (job *ReplayJob) finish(state string, err string) {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	if job.status.State == REPLAY_CANCELLED {
+		state = REPLAY_CANCELLED
+	}
+	job.status.State = state
+	job.status.Error = err
+	job.status.FinishedAt = time.Now()
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall remap the argument `topic` if it starts with the job's `PrefixFrom`.
+//
+// # Author
+// - Polariusz
+func (job *ReplayJob) remap(topic string) string {
+	if job.request.PrefixFrom == "" || !strings.HasPrefix(topic, job.request.PrefixFrom) {
+		return topic
+	}
+
+	return job.request.PrefixTo + strings.TrimPrefix(topic, job.request.PrefixFrom)
+}
+
+// | Date of change | By        | Comment                      |
+// +----------------+-----------+------------------------------+
+// | 2026-10-19     | Polariusz | Created                      |
+// | 2026-10-19     | Polariusz | publishes through job.client |
+//
+// # Method-Type
+// - Worker
+//
+// # Description
+// - The method shall publish the messages of the job through the MQTT-Client of the job, never through the current one of the ServerState, which can be replaced meanwhile.
+// - The delay between two messages shall be the same as the delay between them when they were received, divided by the speed multiplier.
+// - Messages sent by the explorer are published again in the `JsonPublishMessage` format, outsider messages are published as they were received.
+//
+// # Author
+// - Polariusz
+func (job *ReplayJob) run() {
+	var previous time.Time
+
+	for i, message := range job.messages {
+		delay := time.Duration(0)
+		if i > 0 {
+			delay = time.Duration(float64(message.CreationDate.Sub(previous)) / job.request.Speed)
+		}
+		previous = message.CreationDate
+
+		if !job.wait(delay) {
+			job.finish(REPLAY_CANCELLED, "")
+			return
+		}
+
+		if !job.client.IsConnected() {
+			job.finish(REPLAY_FAILED, "The MQTT-Client is not connected to any brokers.")
+			return
+		}
+
+		var payload []byte
+		if message.ClientId == "Unknown" {
+			payload = []byte(message.Message)
+		} else {
+			payload = messageBuilder(message.ClientId, message.Message)
+		}
+
+		if token := job.client.Publish(job.remap(message.Topic), byte(message.QoS), false, payload); token.Wait() && token.Error() != nil {
+			job.finish(REPLAY_FAILED, token.Error().Error())
+			return
+		}
+
+		job.mutex.Lock()
+		job.status.Published++
+		job.mutex.Unlock()
+	}
+
+	job.finish(REPLAY_FINISHED, "")
+}
+
+// | Date of change | By        | Comment                   |
+// +----------------+-----------+---------------------------+
+// | 2026-10-19     | Polariusz | Created                   |
+// | 2026-10-19     | Polariusz | Identity from the session |
+// | 2026-10-19     | Polariusz | Roles                     |
+// | 2026-10-19     | Polariusz | Audit log                 |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall select stored messages matched to the topic filter and time range and start publishing them in the background.
+// - The method shall return a 200 (Ok) with the ID of the replay job.
+// - The method shall take the broker and user from the session when the request has one.
+// - The method shall need the role viewer for the TopicFilter and the role operator for every topic that it publishes to.
+// - The method shall write the action to the audit log with the topic filter, see AuditLog.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data must have a json structure that matches the struct ReplayWrapper.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"replay":<ReplayStatus>}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+//   - {"terribleJson":"Arguments are not valid"}
+// - 401 (Unauthorized): JSON
+//   - {"Unauthorized":"The MQTT-Client is not connected to any brokers."}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while selecting messages matched with broker id and time range","Error":"<SQL-ERROR>"}
+//
+// # Author
+// - Polariusz
+func PostReplayStartHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_REPLAY}
+		defer serverState.auditLog.record(c, &record)
+
+		if serverState.mqttClient == nil || !serverState.mqttClient.IsConnected() {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"Unauthorized": "The MQTT-Client is not connected to any brokers.",
+			})
+		}
+
+		var replayWrapper ReplayWrapper
+		if err := c.BodyParser(&replayWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+
+		resolved, ok, err := resolveBrokerUser(c, serverState, replayWrapper.BrokerUserIDs, true)
+		if !ok {
+			return err
+		}
+		replayWrapper.BrokerUserIDs = resolved
+		record.about(replayWrapper.BrokerUserIDs, replayWrapper.TopicFilter)
+
+		if replayWrapper.Speed == 0 {
+			replayWrapper.Speed = 1
+		}
+		if replayWrapper.BrokerUserIDs.BrokerId <= 0 || replayWrapper.BrokerUserIDs.UserId <= 0 || !validTopicFilter(replayWrapper.TopicFilter) || replayWrapper.Speed < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"terribleJson": "Arguments are not valid",
+			})
+		}
+		if !replayWrapper.TimeTo.IsZero() && replayWrapper.TimeTo.Before(replayWrapper.TimeFrom) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"terribleJson": "TimeTo is before TimeFrom",
+			})
+		}
+
+		messageList, err := serverState.store.SelectMessagesByBrokerIdAndTimeRange(replayWrapper.BrokerUserIDs.BrokerId, replayWrapper.TimeFrom, replayWrapper.TimeTo)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while selecting messages matched with broker id and time range",
+				"Error": err.Error(),
+			})
+		}
+
+		job := &ReplayJob{
+			request: replayWrapper,
+			wake: make(chan struct{}, 1),
+			client: serverState.mqttClient,
+		}
+		for _, message := range messageList {
+			if topicMatchesFilter(replayWrapper.TopicFilter, message.Topic) {
+				job.messages = append(job.messages, message)
+			}
+		}
+
+		// The messages are read from the filter, but published to the remapped topics.
+		if ok, err := authorize(c, serverState, ROLE_VIEWER, replayWrapper.BrokerUserIDs.BrokerId, replayWrapper.TopicFilter); !ok {
+			return err
+		}
+		seen := make(map[string]bool)
+		var targetList []string
+		for _, message := range job.messages {
+			if target := job.remap(message.Topic); !seen[target] {
+				seen[target] = true
+				targetList = append(targetList, target)
+			}
+		}
+		if len(targetList) > 0 {
+			if ok, err := authorize(c, serverState, ROLE_OPERATOR, replayWrapper.BrokerUserIDs.BrokerId, targetList...); !ok {
+				return err
+			}
+		}
+
+		job.status = ReplayStatus{
+			BrokerId: replayWrapper.BrokerUserIDs.BrokerId,
+			State: REPLAY_RUNNING,
+			TopicFilter: replayWrapper.TopicFilter,
+			Speed: replayWrapper.Speed,
+			Total: len(job.messages),
+			StartedAt: time.Now(),
+		}
+
+		serverState.replayManager.add(job)
+		go job.run()
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"replay": job.snapshot(),
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return the progress of all replay jobs.
+// - The method shall leave out the jobs whose broker and TopicFilter the account may not view.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the GET-Method.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"replays":[<ReplayStatus-N>]}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while selecting the roles","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func GetReplayJobsHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		permissions, err := permissionsOf(c, serverState)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while selecting the roles",
+				"Error": err.Error(),
+			})
+		}
+
+		visibleReplays := []ReplayStatus{}
+		for _, status := range serverState.replayManager.list() {
+			if permissions.allows(ROLE_VIEWER, status.BrokerId, status.TopicFilter) {
+				visibleReplays = append(visibleReplays, status)
+			}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"replays": visibleReplays,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Helper
+//
+// # Description
+// - The method shall parse the `ReplayJobWrapper` from the request and find the matching job.
+// - If it fails, it writes the response itself and returns nil.
+//
+// # Author
+// - Polariusz
+func findReplayJob(c *fiber.Ctx, serverState *ServerState) (*ReplayJob, error) {
+	var jobWrapper ReplayJobWrapper
+	if err := c.BodyParser(&jobWrapper); err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"badJson": BADJSON,
+		})
+	}
+
+	job := serverState.replayManager.get(jobWrapper.Id)
+	if job == nil {
+		return nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"badReplay": fmt.Sprintf("Replay job %d is not known", jobWrapper.Id),
+		})
+	}
+
+	return job, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return the progress of one replay job.
+// - The method shall need the role viewer for the TopicFilter of the replay.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data must have a json structure that matches the struct ReplayJobWrapper.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"replay":<ReplayStatus>}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 404 (Not Found): JSON
+//   - {"badReplay":"Replay job <I> is not known"}
+//
+// # Author
+// - Polariusz
+func PostReplayStatusHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		job, err := findReplayJob(c, serverState)
+		if job == nil {
+			return err
+		}
+		if ok, err := authorize(c, serverState, ROLE_VIEWER, job.request.BrokerUserIDs.BrokerId, job.request.TopicFilter); !ok {
+			return err
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"replay": job.snapshot(),
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall pause a running replay job. The remaining delay to the next message is kept.
+// - The method shall need the role operator for the TopicFilter of the replay.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data must have a json structure that matches the struct ReplayJobWrapper.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"replay":<ReplayStatus>}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 404 (Not Found): JSON
+//   - {"badReplay":"Replay job <I> is not known"}
+// - 409 (Conflict): JSON
+//   - {"What":"The replay job is not running","replay":<ReplayStatus>}
+//
+// # Author
+// - Polariusz
+func PostReplayPauseHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		job, err := findReplayJob(c, serverState)
+		if job == nil {
+			return err
+		}
+		if ok, err := authorize(c, serverState, ROLE_OPERATOR, job.request.BrokerUserIDs.BrokerId, job.request.TopicFilter); !ok {
+			return err
+		}
+
+		if !job.transition(REPLAY_RUNNING, REPLAY_PAUSED) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"What": "The replay job is not running",
+				"replay": job.snapshot(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"replay": job.snapshot(),
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall resume a paused replay job.
+// - The method shall need the role operator for the TopicFilter of the replay.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data must have a json structure that matches the struct ReplayJobWrapper.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"replay":<ReplayStatus>}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 404 (Not Found): JSON
+//   - {"badReplay":"Replay job <I> is not known"}
+// - 409 (Conflict): JSON
+//   - {"What":"The replay job is not paused","replay":<ReplayStatus>}
+//
+// # Author
+// - Polariusz
+func PostReplayResumeHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		job, err := findReplayJob(c, serverState)
+		if job == nil {
+			return err
+		}
+		if ok, err := authorize(c, serverState, ROLE_OPERATOR, job.request.BrokerUserIDs.BrokerId, job.request.TopicFilter); !ok {
+			return err
+		}
+
+		if !job.transition(REPLAY_PAUSED, REPLAY_RUNNING) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"What": "The replay job is not paused",
+				"replay": job.snapshot(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"replay": job.snapshot(),
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall cancel a running or paused replay job. Already published messages are not taken back.
+// - The method shall need the role operator for the TopicFilter of the replay.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data must have a json structure that matches the struct ReplayJobWrapper.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"replay":<ReplayStatus>}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 404 (Not Found): JSON
+//   - {"badReplay":"Replay job <I> is not known"}
+// - 409 (Conflict): JSON
+//   - {"What":"The replay job has already ended","replay":<ReplayStatus>}
+//
+// # Author
+// - Polariusz
+func PostReplayCancelHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		job, err := findReplayJob(c, serverState)
+		if job == nil {
+			return err
+		}
+		if ok, err := authorize(c, serverState, ROLE_OPERATOR, job.request.BrokerUserIDs.BrokerId, job.request.TopicFilter); !ok {
+			return err
+		}
+
+		if !job.cancel() {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"What": "The replay job has already ended",
+				"replay": job.snapshot(),
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"replay": job.snapshot(),
+		})
+	}
+}
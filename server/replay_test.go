@@ -0,0 +1,78 @@
+package main
+
+import (
+	"database"
+	"fmt"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gofiber/fiber/v2"
+)
+
+// replayState returns the state and the published count of the only replay job.
+func replayState(t *testing.T, server *fiber.App) (string, float64) {
+	t.Helper()
+	status, body := request(t, server, "GET", "/replay/jobs", "", nil)
+	replays, _ := body["replays"].([]any)
+	if status != fiber.StatusOK || len(replays) != 1 {
+		t.Fatalf("the replay jobs: %d %v", status, body)
+	}
+	job := replays[0].(map[string]any)
+	return job["State"].(string), job["Published"].(float64)
+}
+
+func TestReplayEndsWithItsClient(t *testing.T) {
+	con, err := database.OpenDatabase(database.InMemoryDatabase)
+	if err != nil {
+		t.Fatalf("OpenDatabase: %s", err)
+	}
+	defer con.Close()
+	if err := database.SetupDatabase(con); err != nil {
+		t.Fatalf("SetupDatabase: %s", err)
+	}
+	accounts, err := NewAccountManager(con)
+	if err != nil {
+		t.Fatalf("NewAccountManager: %s", err)
+	}
+
+	broker := newMemoryBroker()
+	defer func(original func(*mqtt.ClientOptions) mqtt.Client) { newMqttClient = original }(newMqttClient)
+	newMqttClient = broker.newClient
+
+	serverState := &ServerState{con: con, store: database.NewSqliteStore(con), accounts: accounts, replayManager: NewReplayManager()}
+	server := fiber.New()
+	addRoutes(server, serverState)
+
+	if status, body := request(t, server, "POST", "/credentials", `{"Ip":"127.0.0.1","Port":"1883","ClientId":"explorer"}`, nil); status != fiber.StatusOK {
+		t.Fatalf("connect: %d %v", status, body)
+	}
+
+	// Two messages an hour apart, the job waits for the second one after it published the first.
+	brokerId := serverState.connected.BrokerId
+	userId, _ := serverState.store.InsertNewUser(database.InsertUser{BrokerId: brokerId, ClientId: "sensor", Outsider: true})
+	topicId, _ := serverState.store.InsertNewTopic(database.InsertTopic{BrokerId: brokerId, Topic: "plant/temperature"})
+	old := time.Now().Add(-2 * time.Hour)
+	serverState.store.InsertNewMessage(database.InsertMessage{UserId: userId, TopicId: topicId, BrokerId: brokerId, Message: "21", CreationDate: old})
+	serverState.store.InsertNewMessage(database.InsertMessage{UserId: userId, TopicId: topicId, BrokerId: brokerId, Message: "22", CreationDate: old.Add(time.Hour)})
+
+	start := fmt.Sprintf(`{"BrokerUserIDs":{"BrokerId":%d,"UserId":%d},"TopicFilter":"plant/#"}`, brokerId, serverState.connected.UserId)
+	if status, body := request(t, server, "POST", "/replay/start", start, nil); status != fiber.StatusOK {
+		t.Fatalf("the replay: %d %v", status, body)
+	}
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, published := replayState(t, server); published == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the replay did not publish the first message")
+		}
+	}
+
+	if status, body := request(t, server, "POST", "/credentials", `{"Ip":"127.0.0.1","Port":"1883","ClientId":"explorer"}`, nil); status != fiber.StatusOK {
+		t.Fatalf("reconnect: %d %v", status, body)
+	}
+	if state, published := replayState(t, server); state != REPLAY_CANCELLED || published != 1 {
+		t.Errorf("the replay after the client was replaced: %s, %v published", state, published)
+	}
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gofiber/fiber/v2"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - REQUEST_DEFAULT_TIMEOUT          : How long a request waits for its response if it does not say otherwise.
+// - REQUEST_MAX_TIMEOUT              : How long a request may wait for its response at most.
+// - REQUEST_CONNECT_TIMEOUT          : How long connecting, subscribing and publishing may take, before the wait for the response starts.
+// - REQUEST_DEFAULT_PAYLOAD          : The payload of a request that does not bring its own.
+// - REQUEST_DEFAULT_CORRELATION_PATH : Where the correlation ID of a response is if the request does not say otherwise.
+// - REQUEST_PROTOCOL_MQTT311         : A request sent with MQTT 3.1.1, which has no properties, the response topic and the correlation ID can only be put in the payload.
+// - REQUEST_PROTOCOL_MQTT5           : A request sent with MQTT 5, the response topic and the correlation ID are also sent as the Response Topic and Correlation Data properties.
+//
+// # Author
+// - Polariusz
+const REQUEST_DEFAULT_TIMEOUT = 5 * time.Second
+const REQUEST_MAX_TIMEOUT = 60 * time.Second
+const REQUEST_CONNECT_TIMEOUT = 5 * time.Second
+const REQUEST_DEFAULT_PAYLOAD = `{"correlationId":"{{.CorrelationId}}","responseTopic":"{{.ResponseTopic}}"}`
+const REQUEST_DEFAULT_CORRELATION_PATH = "correlationId"
+const REQUEST_PROTOCOL_MQTT311 = "3.1.1"
+const REQUEST_PROTOCOL_MQTT5 = "5"
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Topic":"<T>","ResponseTopic":"<R>","Payload":"<P>","CorrelationId":"<C>","CorrelationPath":"<CP>","MatchAny":<M>,"QoS":<Q>,"TimeoutMs":<TM>,"Protocol":"<PR>"}
+//   - <T> : The topic of the request, without wildcards
+//   - <R> : The topic or filter of the response, `<T>/reply` by default. With MQTT 5 it must be a topic without wildcards.
+//   - <P> : A Go text/template, see RequestTemplateData, REQUEST_DEFAULT_PAYLOAD by default
+//   - <C> : The correlation ID, a random one by default
+//   - <CP>: Where the correlation ID is in a JSON response, dots separate the keys, `correlationId` by default
+//   - <M> : If true, the first response is taken whatever its correlation ID
+//   - <Q> : The QoS of the request and of the subscription to the response
+//   - <TM>: How long to wait for the response, 5000 by default, at most 60000
+//   - <PR>: REQUEST_PROTOCOL_MQTT311 by default, or REQUEST_PROTOCOL_MQTT5
+//
+// # Used in
+// - PostRequestHandler()
+//
+// # Author
+// - Polariusz
+type RequestWrapper struct {
+	Topic string
+	ResponseTopic string
+	Payload string
+	CorrelationId string
+	CorrelationPath string
+	MatchAny bool
+	QoS int
+	TimeoutMs int
+	Protocol string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - What the payload template of a request can use, like `{"id":"{{.CorrelationId}}","replyTo":"{{.ResponseTopic}}","cmd":"reboot"}`.
+//
+// # Used in
+// - PostRequestHandler()
+//
+// # Author
+// - Polariusz
+type RequestTemplateData struct {
+	CorrelationId string
+	ResponseTopic string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Topic":"<T>","Payload":"<P>","QoS":<Q>,"Retained":<R>,"CorrelationData":"<C>"}
+//   - <C> : The Correlation Data property of a MQTT 5 response, empty if it has none
+//
+// # Used in
+// - RequestResult
+//
+// # Author
+// - Polariusz
+type RequestResponse struct {
+	Topic string
+	Payload string
+	QoS byte
+	Retained bool
+	CorrelationData string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"CorrelationId":"<C>","Topic":"<T>","ResponseTopic":"<R>","Protocol":"<PR>","Payload":"<P>","Response":<RequestResponse>,"RoundTripMs":<MS>,"SentAt":"<S>","ReceivedAt":"<RA>"}
+//   - <P> : The payload that was published
+//   - <MS>: The milliseconds from the publish to the matched response
+//
+// # Used in
+// - PostRequestHandler()
+//
+// # Author
+// - Polariusz
+type RequestResult struct {
+	CorrelationId string
+	Topic string
+	ResponseTopic string
+	Protocol string
+	Payload string
+	Response RequestResponse
+	RoundTripMs float64
+	SentAt time.Time
+	ReceivedAt time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall fill in the defaults of the request and check it.
+//
+// # Returns
+// - *template.Template of the payload
+// - error when the request is not valid, the message can be shown to the client
+//
+// # Author
+// - Polariusz
+func validateRequest(requestWrapper *RequestWrapper) (*template.Template, error) {
+	if requestWrapper.Topic == "" || strings.ContainsAny(requestWrapper.Topic, "+#") {
+		return nil, fmt.Errorf("Topic is required and must not have wildcards")
+	}
+	if requestWrapper.ResponseTopic == "" {
+		requestWrapper.ResponseTopic = requestWrapper.Topic + "/reply"
+	}
+	if !validTopicFilter(requestWrapper.ResponseTopic) {
+		return nil, fmt.Errorf("ResponseTopic is not a valid topic filter")
+	}
+	if requestWrapper.ResponseTopic == requestWrapper.Topic {
+		return nil, fmt.Errorf("ResponseTopic must not be the Topic, the request would answer itself")
+	}
+	switch requestWrapper.Protocol {
+	case "":
+		requestWrapper.Protocol = REQUEST_PROTOCOL_MQTT311
+	case REQUEST_PROTOCOL_MQTT311:
+	case REQUEST_PROTOCOL_MQTT5:
+		if strings.ContainsAny(requestWrapper.ResponseTopic, "+#") {
+			return nil, fmt.Errorf("ResponseTopic must not have wildcards with MQTT 5, it is sent as the Response Topic property")
+		}
+	default:
+		return nil, fmt.Errorf("Protocol must be %s or %s", REQUEST_PROTOCOL_MQTT311, REQUEST_PROTOCOL_MQTT5)
+	}
+	if requestWrapper.QoS < 0 || requestWrapper.QoS > 2 {
+		return nil, fmt.Errorf("QoS must be 0, 1 or 2")
+	}
+	if requestWrapper.TimeoutMs == 0 {
+		requestWrapper.TimeoutMs = int(REQUEST_DEFAULT_TIMEOUT / time.Millisecond)
+	}
+	if requestWrapper.TimeoutMs < 0 || time.Duration(requestWrapper.TimeoutMs)*time.Millisecond > REQUEST_MAX_TIMEOUT {
+		return nil, fmt.Errorf("TimeoutMs must be between 1 and %d", REQUEST_MAX_TIMEOUT/time.Millisecond)
+	}
+	if requestWrapper.CorrelationPath == "" {
+		requestWrapper.CorrelationPath = REQUEST_DEFAULT_CORRELATION_PATH
+	}
+	if requestWrapper.CorrelationId == "" {
+		random := make([]byte, 16)
+		if _, err := rand.Read(random); err != nil {
+			return nil, err
+		}
+		requestWrapper.CorrelationId = hex.EncodeToString(random)
+	}
+	if requestWrapper.Payload == "" {
+		requestWrapper.Payload = REQUEST_DEFAULT_PAYLOAD
+	}
+
+	payload, err := template.New("payload").Option("missingkey=error").Parse(requestWrapper.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("Payload is not a valid template: %s", err)
+	}
+	return payload, nil
+}
+
+// # Description
+// - The function shall return the credentials of the MQTT-Client with a ClientId of its own, so a request does not disconnect the MQTT-Client.
+//
+// # Author
+// - Polariusz
+func requestCredentials(credentials MqttCredentials) (MqttCredentials, error) {
+	random := make([]byte, 4)
+	if _, err := rand.Read(random); err != nil {
+		return credentials, err
+	}
+	credentials.ClientId = fmt.Sprintf("%s-rr-%s", credentials.ClientId, hex.EncodeToString(random))
+	return credentials, nil
+}
+
+// # Description
+// - The function shall wait for the argument token of paho for as long as connecting may take.
+//
+// # Returns
+// - error when the broker did not answer in time, or the error of the token
+//
+// # Author
+// - Polariusz
+func waitForToken(token mqtt.Token) error {
+	if !token.WaitTimeout(REQUEST_CONNECT_TIMEOUT) {
+		return fmt.Errorf("The broker did not answer in time")
+	}
+	return token.Error()
+}
+
+// | Date of change | By        | Comment                |
+// +----------------+-----------+------------------------+
+// | 2026-10-19     | Polariusz | Created                |
+// | 2026-10-19     | Polariusz | Unlocked while waiting |
+// | 2026-10-19     | Polariusz | MQTT 5 properties      |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall publish a request and wait for the response with the same correlation ID, like a reply on `cmd/x/reply` to `cmd/x`.
+// - The method shall accept a jsonified structure that follows the struct RequestWrapper.
+// - The method shall need the role operator for the topic and the role viewer for the response topic, on the broker of the MQTT-Client.
+// - The request is sent by a MQTT-Client of its own, with the credentials of the MQTT-Client and the ClientId `<ClientId>-rr-<random>`.
+//   - It subscribes to the response topic before it publishes, so a fast response is not missed, and disconnects when it is done.
+//   - The subscriptions of the MQTT-Client stay as they are.
+// - Retained messages on the response topic are left out, they were there before the request.
+// - With MQTT 3.1.1 the response topic and the correlation ID travel in the payload, see REQUEST_DEFAULT_PAYLOAD, and the response is matched by the CorrelationPath of its payload.
+// - With MQTT 5 the request is sent by a mqtt5Client, paho.mqtt.golang only speaks MQTT 3.1.1.
+//   - The publish also has the Response Topic property and the correlation ID as its Correlation Data property.
+//   - A response with the Correlation Data property is matched by it, a response without it by the CorrelationPath of its payload.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - <RequestResult>
+// - 400 (Bad Request): `APIErrorEnvelope`
+// - 401 (Unauthorized): `APIErrorEnvelope`, the MQTT-Client is not connected
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 503 (Service Unavailable): `APIErrorEnvelope`, the broker could not be reached or did not take the request
+// - 504 (Gateway Timeout): `APIErrorEnvelope`, no response within the timeout
+//
+// # Author
+// - Polariusz
+func PostRequestHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_PUBLISH}
+		// The lock of the database is given up while the broker is waited for, the audit log is the one of the database that is selected at the end.
+		defer func() {
+			relockDatabase(c, serverState)
+			serverState.auditLog.record(c, &record)
+		}()
+
+		if serverState.mqttClient == nil || !serverState.mqttClient.IsConnected() {
+			return writeAPIError(c, fiber.StatusUnauthorized, API_ERROR_NOT_CONNECTED, "The MQTT-Client is not connected to any brokers.", nil)
+		}
+
+		var requestWrapper RequestWrapper
+		if err := c.BodyParser(&requestWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+		payloadTemplate, err := validateRequest(&requestWrapper)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+
+		connected := serverState.connected
+		record.about(connected, requestWrapper.Topic)
+		if ok, err := authorize(c, serverState, ROLE_OPERATOR, connected.BrokerId, requestWrapper.Topic); !ok {
+			return err
+		}
+		if ok, err := authorize(c, serverState, ROLE_VIEWER, connected.BrokerId, requestWrapper.ResponseTopic); !ok {
+			return err
+		}
+
+		var payload bytes.Buffer
+		if err := payloadTemplate.Execute(&payload, RequestTemplateData{requestWrapper.CorrelationId, requestWrapper.ResponseTopic}); err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, fmt.Sprintf("Payload could not be written: %s", err), nil)
+		}
+		record.PayloadHash = hashPayload(payload.String())
+
+		credentials, err := requestCredentials(serverState.userCreds)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while creating the ClientId", map[string]any{"Error": err.Error()})
+		}
+		releaseDatabase(c, serverState)
+
+		type received struct {
+			response RequestResponse
+			at time.Time
+		}
+		responses := make(chan received, 1)
+		// The correlation data is nil for a response of MQTT 3.1.1, or of MQTT 5 without the property.
+		onResponse := func(response RequestResponse, correlationData []byte) {
+			at := time.Now()
+			if response.Retained {
+				return
+			}
+			if !requestWrapper.MatchAny {
+				if correlationData != nil {
+					if string(correlationData) != requestWrapper.CorrelationId {
+						return
+					}
+				} else if subject, ok := conditionSubject(requestWrapper.CorrelationPath, response.Payload); !ok || subject != requestWrapper.CorrelationId {
+					return
+				}
+			}
+			select {
+			case responses <- received{response, at}:
+			default:
+			}
+		}
+
+		qos := byte(requestWrapper.QoS)
+		var subscribe, publish func() error
+		if requestWrapper.Protocol == REQUEST_PROTOCOL_MQTT5 {
+			client, err := dialMqtt5(credentials, REQUEST_CONNECT_TIMEOUT, func(msg mqtt5Message) {
+				onResponse(RequestResponse{msg.Topic, string(msg.Payload), msg.QoS, msg.Retained, string(msg.Properties.CorrelationData)}, msg.Properties.CorrelationData)
+			})
+			if err != nil {
+				return writeAPIError(c, fiber.StatusServiceUnavailable, API_ERROR_UNAVAILABLE, "Could not connect to the broker for the request", map[string]any{"Error": err.Error()})
+			}
+			defer client.close()
+
+			subscribe = func() error {
+				return client.subscribe(requestWrapper.ResponseTopic, qos, REQUEST_CONNECT_TIMEOUT)
+			}
+			properties := mqtt5Properties{ResponseTopic: requestWrapper.ResponseTopic, CorrelationData: []byte(requestWrapper.CorrelationId)}
+			publish = func() error {
+				return client.publish(requestWrapper.Topic, qos, payload.Bytes(), properties, REQUEST_CONNECT_TIMEOUT)
+			}
+		} else {
+			mqttOpts := mqttClientOptions(credentials)
+			mqttOpts.SetAutoReconnect(false).SetConnectTimeout(REQUEST_CONNECT_TIMEOUT)
+			client := newMqttClient(mqttOpts)
+			if token := client.Connect(); !token.WaitTimeout(REQUEST_CONNECT_TIMEOUT) || token.Error() != nil {
+				return writeAPIError(c, fiber.StatusServiceUnavailable, API_ERROR_UNAVAILABLE, "Could not connect to the broker for the request", map[string]any{"Error": fmt.Sprint(token.Error())})
+			}
+			defer client.Disconnect(250)
+
+			subscribe = func() error {
+				return waitForToken(client.Subscribe(requestWrapper.ResponseTopic, qos, func(_ mqtt.Client, msg mqtt.Message) {
+					onResponse(RequestResponse{msg.Topic(), string(msg.Payload()), msg.Qos(), msg.Retained(), ""}, nil)
+				}))
+			}
+			publish = func() error {
+				return waitForToken(client.Publish(requestWrapper.Topic, qos, false, payload.String()))
+			}
+		}
+
+		if err := subscribe(); err != nil {
+			return writeAPIError(c, fiber.StatusServiceUnavailable, API_ERROR_UNAVAILABLE, "Could not subscribe to the response topic", map[string]any{"Error": err.Error()})
+		}
+
+		timeout := time.Duration(requestWrapper.TimeoutMs) * time.Millisecond
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		sentAt := time.Now()
+		if err := publish(); err != nil {
+			return writeAPIError(c, fiber.StatusServiceUnavailable, API_ERROR_UNAVAILABLE, "Could not publish the request", map[string]any{"Error": err.Error()})
+		}
+
+		select {
+		case response := <-responses:
+			return c.Status(fiber.StatusOK).JSON(RequestResult{
+				CorrelationId: requestWrapper.CorrelationId,
+				Topic: requestWrapper.Topic,
+				ResponseTopic: requestWrapper.ResponseTopic,
+				Protocol: requestWrapper.Protocol,
+				Payload: payload.String(),
+				Response: response.response,
+				RoundTripMs: float64(response.at.Sub(sentAt)) / float64(time.Millisecond),
+				SentAt: sentAt,
+				ReceivedAt: response.at,
+			})
+		case <-timer.C:
+			return writeAPIError(c, fiber.StatusGatewayTimeout, API_ERROR_TIMEOUT, fmt.Sprintf("No response on %s within %d ms", requestWrapper.ResponseTopic, requestWrapper.TimeoutMs), map[string]any{
+				"CorrelationId": requestWrapper.CorrelationId,
+				"SentAt": sentAt,
+			})
+		}
+	}
+}
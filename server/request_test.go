@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bufio"
+	"database"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gofiber/fiber/v2"
+)
+
+// A broker in memory that hands every publish to the matching subscriptions of all its clients.
+// The topics and filters in its deny lists are refused like an ACL would: a subscription with the SUBACK code 0x80, a publish silently.
+type memoryBroker struct {
+	mutex sync.Mutex
+	subscriptions []memorySubscription
+	clientIds []string
+	denySubscribe map[string]bool
+	denyPublish map[string]bool
+	retained map[string]string
+}
+
+type memorySubscription struct {
+	client *memoryClient
+	filter string
+	callback mqtt.MessageHandler
+}
+
+type memoryClient struct {
+	mqtt.Client
+	broker *memoryBroker
+}
+
+// A token with the SUBACK codes of a subscription, like the one of paho.
+type subackToken struct {
+	mqtt.DummyToken
+	codes map[string]byte
+}
+
+func (st *subackToken) Result() map[string]byte { return st.codes }
+
+func newMemoryBroker() *memoryBroker {
+	return &memoryBroker{denySubscribe: make(map[string]bool), denyPublish: make(map[string]bool), retained: make(map[string]string)}
+}
+
+func (mb *memoryBroker) newClient(opts *mqtt.ClientOptions) mqtt.Client {
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+	mb.clientIds = append(mb.clientIds, opts.ClientID)
+	return &memoryClient{broker: mb}
+}
+
+func (mc *memoryClient) IsConnected() bool { return true }
+func (mc *memoryClient) Connect() mqtt.Token { return &mqtt.DummyToken{} }
+func (mc *memoryClient) Disconnect(quiesce uint) {}
+
+func (mc *memoryClient) Subscribe(filter string, qos byte, callback mqtt.MessageHandler) mqtt.Token {
+	mc.broker.mutex.Lock()
+	defer mc.broker.mutex.Unlock()
+	if mc.broker.denySubscribe[filter] {
+		return &subackToken{codes: map[string]byte{filter: 0x80}}
+	}
+	mc.broker.subscriptions = append(mc.broker.subscriptions, memorySubscription{mc, filter, callback})
+	return &subackToken{codes: map[string]byte{filter: qos}}
+}
+
+func (mc *memoryClient) Unsubscribe(filters ...string) mqtt.Token {
+	mc.broker.mutex.Lock()
+	defer mc.broker.mutex.Unlock()
+	kept := mc.broker.subscriptions[:0]
+	for _, subscription := range mc.broker.subscriptions {
+		if subscription.client != mc || !slices.Contains(filters, subscription.filter) {
+			kept = append(kept, subscription)
+		}
+	}
+	mc.broker.subscriptions = kept
+	return &mqtt.DummyToken{}
+}
+
+func (mc *memoryClient) Publish(topic string, qos byte, retained bool, payload interface{}) mqtt.Token {
+	message := fakeMessage{topic, fmt.Sprint(payload)}
+	if raw, ok := payload.([]byte); ok {
+		message.payload = string(raw)
+	}
+	mc.broker.mutex.Lock()
+	defer mc.broker.mutex.Unlock()
+	if mc.broker.denyPublish[topic] {
+		return &mqtt.DummyToken{}
+	}
+	if retained && message.payload == "" {
+		delete(mc.broker.retained, topic)
+	} else if retained {
+		mc.broker.retained[topic] = message.payload
+	}
+	for _, subscription := range mc.broker.subscriptions {
+		if topicMatchesFilter(subscription.filter, topic) {
+			go subscription.callback(mc, message)
+		}
+	}
+	return &mqtt.DummyToken{}
+}
+
+func TestRequest(t *testing.T) {
+	con, err := database.OpenDatabase(database.InMemoryDatabase)
+	if err != nil {
+		t.Fatalf("OpenDatabase: %s", err)
+	}
+	defer con.Close()
+	if err := database.SetupDatabase(con); err != nil {
+		t.Fatalf("SetupDatabase: %s", err)
+	}
+	accounts, err := NewAccountManager(con)
+	if err != nil {
+		t.Fatalf("NewAccountManager: %s", err)
+	}
+
+	broker := newMemoryBroker()
+	defer func(original func(*mqtt.ClientOptions) mqtt.Client) { newMqttClient = original }(newMqttClient)
+	newMqttClient = broker.newClient
+
+	// A device that answers every command on cmd/<x>/reply, first to someone else and then to the request.
+	device := broker.newClient(mqtt.NewClientOptions().SetClientID("device"))
+	device.Subscribe("cmd/+", 1, func(client mqtt.Client, msg mqtt.Message) {
+		var command map[string]string
+		json.Unmarshal(msg.Payload(), &command)
+		device.Publish(command["responseTopic"], 1, false, `{"correlationId":"someone else"}`)
+		device.Publish(command["responseTopic"], 1, false, `{"correlationId":"`+command["correlationId"]+`","status":"ok"}`)
+	})
+
+	serverState := &ServerState{con: con, store: database.NewSqliteStore(con), accounts: accounts, replayManager: NewReplayManager()}
+	server := fiber.New()
+	addRoutes(server, serverState)
+
+	if status, body := request(t, server, "POST", "/api/v1/requests", `{"Topic":"cmd/reboot"}`, nil); status != fiber.StatusUnauthorized || body["error"].(map[string]any)["code"] != API_ERROR_NOT_CONNECTED {
+		t.Errorf("a request without a connection: %d %v", status, body)
+	}
+
+	if status, body := request(t, server, "POST", "/credentials", `{"Ip":"127.0.0.1","Port":"1883","ClientId":"explorer"}`, nil); status != fiber.StatusOK {
+		t.Fatalf("connect: %d %v", status, body)
+	}
+
+	for name, body := range map[string]string{
+		"no topic": `{}`,
+		"wildcard": `{"Topic":"cmd/#"}`,
+		"loop": `{"Topic":"cmd/reboot","ResponseTopic":"cmd/reboot"}`,
+		"bad filter": `{"Topic":"cmd/reboot","ResponseTopic":"cmd/#/reply"}`,
+		"long wait": `{"Topic":"cmd/reboot","TimeoutMs":60001}`,
+		"bad qos": `{"Topic":"cmd/reboot","QoS":3}`,
+		"bad template": `{"Topic":"cmd/reboot","Payload":"{{.Nope}}"}`,
+	} {
+		if status, response := request(t, server, "POST", "/api/v1/requests", body, nil); status != fiber.StatusBadRequest {
+			t.Errorf("%s: %d %v", name, status, response)
+		}
+	}
+
+	status, body := request(t, server, "POST", "/api/v1/requests", `{"Topic":"cmd/reboot","QoS":1,"TimeoutMs":500}`, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("the request: %d %v", status, body)
+	}
+	response := body["Response"].(map[string]any)
+	if body["ResponseTopic"] != "cmd/reboot/reply" || response["Topic"] != "cmd/reboot/reply" || !strings.Contains(response["Payload"].(string), `"correlationId":"`+body["CorrelationId"].(string)+`","status":"ok"`) {
+		t.Errorf("the response: %v", body)
+	}
+	if roundTrip, _ := body["RoundTripMs"].(float64); roundTrip < 0 || roundTrip > 500 {
+		t.Errorf("the round trip: %v", body["RoundTripMs"])
+	}
+	broker.mutex.Lock()
+	if clientId := broker.clientIds[len(broker.clientIds)-1]; !strings.HasPrefix(clientId, "explorer-rr-") {
+		t.Errorf("the request connected as %s", clientId)
+	}
+	broker.mutex.Unlock()
+
+	status, body = request(t, server, "POST", "/api/v1/requests", `{"Topic":"cmd/ping","CorrelationId":"abc","Payload":"{\"correlationId\":\"{{.CorrelationId}}\",\"responseTopic\":\"replies/ping\"}","ResponseTopic":"replies/+","TimeoutMs":500}`, nil)
+	if status != fiber.StatusOK || body["CorrelationId"] != "abc" || body["Response"].(map[string]any)["Payload"] != `{"correlationId":"abc","status":"ok"}` {
+		t.Errorf("a request with its own correlation ID: %d %v", status, body)
+	}
+
+	status, body = request(t, server, "POST", "/api/v1/requests", `{"Topic":"cmd/ping","MatchAny":true,"TimeoutMs":500}`, nil)
+	if status != fiber.StatusOK || !strings.HasPrefix(body["Response"].(map[string]any)["Payload"].(string), `{"correlationId":`) {
+		t.Errorf("a request that takes any response: %d %v", status, body)
+	}
+
+	status, body = request(t, server, "POST", "/api/v1/requests", `{"Topic":"nobody/listens","TimeoutMs":100}`, nil)
+	if status != fiber.StatusGatewayTimeout || body["error"].(map[string]any)["code"] != API_ERROR_TIMEOUT {
+		t.Errorf("a request without a response: %d %v", status, body)
+	}
+
+	if status, body := request(t, server, "POST", "/disconnect", "", nil); status != fiber.StatusOK {
+		t.Fatalf("disconnect: %d %v", status, body)
+	}
+	if serverState.userCreds != (MqttCredentials{}) {
+		t.Errorf("the credentials were kept after the disconnect: %+v", serverState.userCreds)
+	}
+}
+
+// A broker that speaks MQTT 5 to one client: it takes the subscription and answers a request on its Response Topic property, first with the Correlation Data of someone else and then with that of the request.
+func serveMqtt5Responder(t *testing.T, listener net.Listener, requests chan<- mqtt5Message) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	for {
+		packet, err := readMqtt5Packet(reader)
+		if err != nil {
+			return
+		}
+		switch packet.kind {
+		case MQTT5_CONNECT:
+			if packet.body[6] != 5 {
+				t.Errorf("the client connected with the protocol level %d", packet.body[6])
+			}
+			writeMqtt5Packet(conn, MQTT5_CONNACK, 0, []byte{0x00, 0x00, 0x00})
+		case MQTT5_SUBSCRIBE:
+			writeMqtt5Packet(conn, MQTT5_SUBACK, 0, []byte{packet.body[0], packet.body[1], 0x00, 0x01})
+		case MQTT5_PUBLISH:
+			request, packetId, err := parseMqtt5Publish(packet)
+			if err != nil {
+				t.Errorf("parseMqtt5Publish: %s", err)
+				return
+			}
+			writeMqtt5Packet(conn, MQTT5_PUBACK, 0, binary.BigEndian.AppendUint16(nil, packetId))
+			requests <- request
+			for _, correlationData := range [][]byte{[]byte("someone else"), request.Properties.CorrelationData} {
+				var response mqtt5Writer
+				response.binary([]byte(request.Properties.ResponseTopic))
+				response.properties(mqtt5Properties{CorrelationData: correlationData})
+				response.WriteString(`{"status":"ok"}`)
+				writeMqtt5Packet(conn, MQTT5_PUBLISH, 0, response.Bytes())
+			}
+		case MQTT5_DISCONNECT:
+			return
+		}
+	}
+}
+
+func TestRequestMqtt5(t *testing.T) {
+	con, err := database.OpenDatabase(database.InMemoryDatabase)
+	if err != nil {
+		t.Fatalf("OpenDatabase: %s", err)
+	}
+	defer con.Close()
+	if err := database.SetupDatabase(con); err != nil {
+		t.Fatalf("SetupDatabase: %s", err)
+	}
+	accounts, err := NewAccountManager(con)
+	if err != nil {
+		t.Fatalf("NewAccountManager: %s", err)
+	}
+
+	broker := newMemoryBroker()
+	defer func(original func(*mqtt.ClientOptions) mqtt.Client) { newMqttClient = original }(newMqttClient)
+	newMqttClient = broker.newClient
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	requests := make(chan mqtt5Message, 1)
+	go serveMqtt5Responder(t, listener, requests)
+
+	serverState := &ServerState{con: con, store: database.NewSqliteStore(con), accounts: accounts, replayManager: NewReplayManager()}
+	server := fiber.New()
+	addRoutes(server, serverState)
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	if status, body := request(t, server, "POST", "/credentials", fmt.Sprintf(`{"Ip":"127.0.0.1","Port":"%d","ClientId":"explorer"}`, port), nil); status != fiber.StatusOK {
+		t.Fatalf("connect: %d %v", status, body)
+	}
+
+	for name, body := range map[string]string{
+		"wildcard response": `{"Topic":"cmd/reboot","ResponseTopic":"cmd/+/reply","Protocol":"5"}`,
+		"unknown protocol": `{"Topic":"cmd/reboot","Protocol":"4"}`,
+	} {
+		if status, response := request(t, server, "POST", "/api/v1/requests", body, nil); status != fiber.StatusBadRequest {
+			t.Errorf("%s: %d %v", name, status, response)
+		}
+	}
+
+	status, body := request(t, server, "POST", "/api/v1/requests", `{"Topic":"cmd/reboot","CorrelationId":"abc","Payload":"reboot","QoS":1,"TimeoutMs":1000,"Protocol":"5"}`, nil)
+	if status != fiber.StatusOK || body["Protocol"] != REQUEST_PROTOCOL_MQTT5 {
+		t.Fatalf("the request: %d %v", status, body)
+	}
+	if response := body["Response"].(map[string]any); response["CorrelationData"] != "abc" || response["Payload"] != `{"status":"ok"}` || response["Topic"] != "cmd/reboot/reply" {
+		t.Errorf("the response: %v", response)
+	}
+
+	request := <-requests
+	if request.Topic != "cmd/reboot" || string(request.Payload) != "reboot" || request.QoS != 1 || request.Properties.ResponseTopic != "cmd/reboot/reply" || string(request.Properties.CorrelationData) != "abc" {
+		t.Errorf("the published request: %+v", request)
+	}
+}
@@ -0,0 +1,713 @@
+package main
+
+import (
+	"database"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - Reasons that are written to [RetentionDeletion].[Reason], one for each limit of a rule.
+//
+// # Author
+// - Polariusz
+const RETENTION_MAX_AGE = "max-age"
+const RETENTION_MAX_MESSAGES = "max-messages-per-topic"
+const RETENTION_MAX_SIZE = "max-database-size"
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The janitor runs every `RETENTION_INTERVAL` and deletes at most `RETENTION_BATCH` messages per statement, so that the ingest queue can write between the batches.
+// - A single limit of a rule deletes at most `RETENTION_MAX_BATCHES` batches per run, the rest is deleted on the next run.
+//
+// # Author
+// - Polariusz
+const RETENTION_INTERVAL = 1 * time.Minute
+const RETENTION_BATCH = 500
+const RETENTION_MAX_BATCHES = 20
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The outcome of the last run of the janitor.
+//
+// # Used in
+// - GetRetentionReportHandler()
+// - PostRetentionRunHandler()
+//
+// # Author
+// - Polariusz
+type RetentionRun struct {
+	Started time.Time
+	Finished time.Time
+	Deleted int64
+	Error string
+}
+
+// | Date of change | By        | Comment     |
+// +----------------+-----------+-------------+
+// | 2026-10-19     | Polariusz | Created     |
+// | 2026-10-19     | Polariusz | Added store |
+//
+// # Description
+// - The structure runs the retention rules in the background.
+// - The rules are read from and the deletions written to the database `con`, the messages are deleted through `store`, which is a MemoryStore in memory mode.
+// - `runMutex` makes sure that a run started by PostRetentionRunHandler() does not overlap with a timed run.
+//
+// # Used in
+// - ServerState
+//
+// # Author
+// - Polariusz
+type RetentionJanitor struct {
+	con *sql.DB
+	store database.Store
+	runMutex sync.Mutex
+	lastRunMutex sync.Mutex
+	lastRun RetentionRun
+	done chan struct{}
+	stopped chan struct{}
+}
+
+// # Author
+// - Polariusz
+func NewRetentionJanitor(con *sql.DB, store database.Store) *RetentionJanitor {
+	return &RetentionJanitor{
+		con: con,
+		store: store,
+		done: make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall run the rules every `RETENTION_INTERVAL` until close() is called.
+//
+// # Author
+// - Polariusz
+func (rj *RetentionJanitor) start() {
+	go func() {
+		defer close(rj.stopped)
+
+		ticker := time.NewTicker(RETENTION_INTERVAL)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-rj.done:
+				return
+			case <-ticker.C:
+				rj.run()
+			}
+		}
+	}()
+}
+
+// # Author
+// - Polariusz
+func (rj *RetentionJanitor) close() {
+	close(rj.done)
+	<-rj.stopped
+}
+
+// # Author
+// - Polariusz
+func (rj *RetentionJanitor) snapshot() RetentionRun {
+	rj.lastRunMutex.Lock()
+	defer rj.lastRunMutex.Unlock()
+
+	return rj.lastRun
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall apply every retention rule once and write what each rule deleted into table RetentionDeletion.
+//
+// # Returns
+// - RetentionRun of this run
+//
+// # Author
+// - Polariusz
+func (rj *RetentionJanitor) run() RetentionRun {
+	rj.runMutex.Lock()
+	defer rj.runMutex.Unlock()
+
+	retentionRun := RetentionRun{Started: time.Now()}
+	deleted, err := rj.applyRules()
+	retentionRun.Deleted = deleted
+	if err != nil {
+		retentionRun.Error = err.Error()
+		fmt.Printf("WARN: Retention run failed!\nErr: %s\n", err)
+	}
+	retentionRun.Finished = time.Now()
+
+	rj.lastRunMutex.Lock()
+	rj.lastRun = retentionRun
+	rj.lastRunMutex.Unlock()
+
+	return retentionRun
+}
+
+// # Author
+// - Polariusz
+func (rj *RetentionJanitor) applyRules() (int64, error) {
+	ruleList, err := database.SelectRetentionRules(rj.con)
+	if err != nil {
+		return 0, err
+	}
+	brokerList, err := rj.store.SelectBrokerList()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, rule := range ruleList {
+		deletedByReason := make(map[string]int64)
+
+		for _, broker := range brokerList {
+			if rule.BrokerId != 0 && rule.BrokerId != broker.Id {
+				continue
+			}
+
+			topicIds, err := rj.matchingTopicIds(rule, broker.Id)
+			if err != nil {
+				return total, err
+			}
+
+			if err := rj.applyRule(rule, broker.Id, topicIds, deletedByReason); err != nil {
+				return total, err
+			}
+		}
+
+		for _, reason := range []string{RETENTION_MAX_AGE, RETENTION_MAX_MESSAGES, RETENTION_MAX_SIZE} {
+			if deletedByReason[reason] == 0 {
+				continue
+			}
+			total += deletedByReason[reason]
+			if err := database.InsertRetentionDeletion(rj.con, rule.Id, reason, deletedByReason[reason]); err != nil {
+				return total, err
+			}
+		}
+	}
+
+	return total, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall return the IDs of the topics of the broker that match the filter of the rule.
+// - Topics that are marked as favourite are left out if the rule keeps favourites.
+//
+// # Author
+// - Polariusz
+func (rj *RetentionJanitor) matchingTopicIds(rule database.SelectRetentionRule, brokerId int) ([]int, error) {
+	topicList, err := rj.store.SelectTopicsByBrokerId(brokerId)
+	if err != nil {
+		return nil, err
+	}
+
+	favouriteTopicIds := make(map[int]bool)
+	if rule.KeepFavourites {
+		favouriteTopicIds, err = rj.store.SelectFavouriteTopicIdsByBrokerId(brokerId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var topicIds []int
+	for _, topic := range topicList {
+		if favouriteTopicIds[topic.Id] || !topicMatchesFilter(rule.TopicFilter, topic.Topic) {
+			continue
+		}
+		topicIds = append(topicIds, topic.Id)
+	}
+
+	return topicIds, nil
+}
+
+// # Author
+// - Polariusz
+func (rj *RetentionJanitor) applyRule(rule database.SelectRetentionRule, brokerId int, topicIds []int, deletedByReason map[string]int64) error {
+	if rule.MaxAgeSeconds > 0 {
+		before := time.Now().Add(-time.Duration(rule.MaxAgeSeconds) * time.Second)
+		for _, topicId := range topicIds {
+			deleted, err := deleteInBatches(func() (int64, error) {
+				return rj.store.DeleteMessagesOlderThan(brokerId, topicId, before, RETENTION_BATCH)
+			})
+			deletedByReason[RETENTION_MAX_AGE] += deleted
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if rule.MaxMessagesPerTopic > 0 {
+		for _, topicId := range topicIds {
+			deleted, err := deleteInBatches(func() (int64, error) {
+				return rj.store.DeleteMessagesBeyondCount(brokerId, topicId, rule.MaxMessagesPerTopic, RETENTION_BATCH)
+			})
+			deletedByReason[RETENTION_MAX_MESSAGES] += deleted
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if rule.MaxDatabaseBytes > 0 {
+		deleted, err := deleteInBatches(func() (int64, error) {
+			size, err := rj.store.SelectDatabaseSize()
+			if err != nil || size <= rule.MaxDatabaseBytes {
+				return 0, err
+			}
+			return rj.store.DeleteOldestMessages(brokerId, topicIds, RETENTION_BATCH)
+		})
+		deletedByReason[RETENTION_MAX_SIZE] += deleted
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall call `deleteBatch` until it deletes less than a full batch or `RETENTION_MAX_BATCHES` were deleted.
+//
+// # Returns
+// - int64: How many messages were deleted
+//
+// # Author
+// - Polariusz
+func deleteInBatches(deleteBatch func() (int64, error)) (int64, error) {
+	var total int64
+	for batch := 0; batch < RETENTION_MAX_BATCHES; batch++ {
+		deleted, err := deleteBatch()
+		total += deleted
+		if err != nil || deleted < RETENTION_BATCH {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"BrokerId":<B>,"TopicFilter":"<F>","MaxAgeSeconds":<A>,"MaxMessagesPerTopic":<M>,"MaxDatabaseBytes":<S>,"KeepFavourites":<K>}
+//   - <B> : The ID of the Broker ROW, 0 for all brokers
+//   - <F> : MQTT topic filter, wildcards are allowed
+//   - <A> : Messages older than <A> seconds are deleted, 0 to not limit the age
+//   - <M> : Only the newest <M> messages of each topic are kept, 0 to not limit the count
+//   - <S> : The oldest messages are deleted while the database uses more than <S> bytes, 0 to not limit the size
+//   - <K> : Topics that are marked as favourite are not touched
+//
+// # Used in
+// - PostRetentionRuleCreateHandler()
+//
+// # Author
+// - Polariusz
+type RetentionRuleWrapper struct {
+	BrokerId int
+	TopicFilter string
+	MaxAgeSeconds int
+	MaxMessagesPerTopic int
+	MaxDatabaseBytes int64
+	KeepFavourites bool
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Id":<I>}
+//   - <I> : The ID of the RetentionRule ROW
+//
+// # Used in
+// - PostRetentionRuleDeleteHandler()
+//
+// # Author
+// - Polariusz
+type RetentionRuleIdWrapper struct {
+	Id int
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall create a retention rule. It is applied on the next run of the janitor.
+// - The method shall need the role admin for the broker and the topic filter of the rule.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data must have a json structure that matches the struct RetentionRuleWrapper.
+//
+// # Returns
+// - 201 (Created): JSON
+//   - {"Id":<I>}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+//   - {"terribleJson":"<What is wrong>"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while inserting in the RetentionRule table","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func PostRetentionRuleCreateHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var ruleWrapper RetentionRuleWrapper
+		if err := c.BodyParser(&ruleWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+
+		if ruleWrapper.TopicFilter == "" {
+			ruleWrapper.TopicFilter = "#"
+		}
+		if !validTopicFilter(ruleWrapper.TopicFilter) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"terribleJson": "TopicFilter is not a valid MQTT topic filter",
+			})
+		}
+		if ruleWrapper.BrokerId < 0 || ruleWrapper.MaxAgeSeconds < 0 || ruleWrapper.MaxMessagesPerTopic < 0 || ruleWrapper.MaxDatabaseBytes < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"terribleJson": "BrokerId and the limits must not be negative",
+			})
+		}
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, ruleWrapper.BrokerId, ruleWrapper.TopicFilter); !ok {
+			return err
+		}
+		if ruleWrapper.MaxAgeSeconds == 0 && ruleWrapper.MaxMessagesPerTopic == 0 && ruleWrapper.MaxDatabaseBytes == 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"terribleJson": "At least one of MaxAgeSeconds, MaxMessagesPerTopic and MaxDatabaseBytes must be set",
+			})
+		}
+
+		ruleId, err := database.InsertNewRetentionRule(serverState.con, database.InsertRetentionRule{
+			BrokerId: ruleWrapper.BrokerId,
+			TopicFilter: ruleWrapper.TopicFilter,
+			MaxAgeSeconds: ruleWrapper.MaxAgeSeconds,
+			MaxMessagesPerTopic: ruleWrapper.MaxMessagesPerTopic,
+			MaxDatabaseBytes: ruleWrapper.MaxDatabaseBytes,
+			KeepFavourites: ruleWrapper.KeepFavourites,
+		})
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while inserting in the RetentionRule table",
+				"Error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"Id": ruleId,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return all retention rules.
+// - The method shall leave out the rules whose broker and TopicFilter the account may not view, a rule of all brokers needs the role viewer for all of them.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the GET-Method.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"rules":[<database.SelectRetentionRule>]}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while selecting retention rules","Error":"<err>"}
+//   - {"InternalServerError":"Error while selecting the roles","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func GetRetentionRulesHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		permissions, err := permissionsOf(c, serverState)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while selecting the roles",
+				"Error": err.Error(),
+			})
+		}
+
+		ruleList, err := database.SelectRetentionRules(serverState.con)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while selecting retention rules",
+				"Error": err.Error(),
+			})
+		}
+		visibleRules := []database.SelectRetentionRule{}
+		for _, rule := range ruleList {
+			if permissions.allows(ROLE_VIEWER, rule.BrokerId, rule.TopicFilter) {
+				visibleRules = append(visibleRules, rule)
+			}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"rules": visibleRules,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+// | 2026-10-19     | Polariusz | Audited |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall delete a retention rule together with the record of what it has deleted.
+// - The method shall need the role admin for the broker and the topic filter of the rule.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+// - The data must have a json structure that matches the struct RetentionRuleIdWrapper.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"Id":<I>}
+// - 400 (Bad Request): JSON
+//   - {"badJson":`const BADJSON`}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 404 (Not Found): JSON
+//   - {"badRetentionRule":"There is no retention rule with this Id"}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while deleting the retention rule","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func PostRetentionRuleDeleteHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_DELETE_RETENTION_RULE}
+		defer serverState.auditLog.record(c, &record)
+
+		var idWrapper RetentionRuleIdWrapper
+		if err := c.BodyParser(&idWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+
+		// The rule decides who may delete it, a rule that does not exist needs an admin of all brokers.
+		ruleList, err := database.SelectRetentionRules(serverState.con)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while selecting the retention rules",
+				"Error": err.Error(),
+			})
+		}
+		brokerId, topicFilter := 0, "#"
+		for _, rule := range ruleList {
+			if rule.Id == idWrapper.Id {
+				brokerId, topicFilter = rule.BrokerId, rule.TopicFilter
+			}
+		}
+		record.about(BrokerUser{BrokerId: brokerId}, topicFilter)
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, brokerId, topicFilter); !ok {
+			return err
+		}
+
+		found, err := database.DeleteRetentionRule(serverState.con, idWrapper.Id)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while deleting the retention rule",
+				"Error": err.Error(),
+			})
+		}
+		if !found {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"badRetentionRule": "There is no retention rule with this Id",
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"Id": idWrapper.Id,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Rule":<database.SelectRetentionRule>,"Deleted":<D>,"Deletions":[<database.SelectRetentionReport>]}
+//   - <D> : How many messages the rule deleted in total
+//
+// # Used in
+// - GetRetentionReportHandler()
+//
+// # Author
+// - Polariusz
+type RetentionRuleReport struct {
+	Rule database.SelectRetentionRule
+	Deleted int64
+	Deletions []database.SelectRetentionReport
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return what each retention rule has deleted, split by the limit that deleted it, and the outcome of the last run.
+// - The method shall leave out the rules whose broker and TopicFilter the account may not view, like GetRetentionRulesHandler().
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the GET-Method.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"rules":[<RetentionRuleReport>],"lastRun":<RetentionRun>}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while selecting the retention report","Error":"<err>"}
+//   - {"InternalServerError":"Error while selecting the roles","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func GetRetentionReportHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		permissions, err := permissionsOf(c, serverState)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while selecting the roles",
+				"Error": err.Error(),
+			})
+		}
+
+		ruleList, err := database.SelectRetentionRules(serverState.con)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while selecting the retention report",
+				"Error": err.Error(),
+			})
+		}
+		reportList, err := database.SelectRetentionReports(serverState.con)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while selecting the retention report",
+				"Error": err.Error(),
+			})
+		}
+
+		ruleReports := []RetentionRuleReport{}
+		for _, rule := range ruleList {
+			if !permissions.allows(ROLE_VIEWER, rule.BrokerId, rule.TopicFilter) {
+				continue
+			}
+			ruleReport := RetentionRuleReport{Rule: rule, Deletions: []database.SelectRetentionReport{}}
+			for _, report := range reportList {
+				if report.RuleId == rule.Id {
+					ruleReport.Deleted += report.Deleted
+					ruleReport.Deletions = append(ruleReport.Deletions, report)
+				}
+			}
+			ruleReports = append(ruleReports, ruleReport)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"rules": ruleReports,
+			"lastRun": serverState.retentionJanitor.snapshot(),
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Roles   |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall apply the retention rules right away instead of waiting for the next timed run.
+// - The method shall need the role admin on all brokers.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the POST-Method.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"run":<RetentionRun>}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while applying the retention rules","Error":"<err>","run":<RetentionRun>}
+//
+// # Author
+// - Polariusz
+func PostRetentionRunHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		retentionRun := serverState.retentionJanitor.run()
+		if retentionRun.Error != "" {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"InternalServerError": "Error while applying the retention rules",
+				"Error": retentionRun.Error,
+				"run": retentionRun,
+			})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"run": retentionRun,
+		})
+	}
+}
@@ -0,0 +1,557 @@
+package main
+
+import (
+	"database"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The roles of an account, each one may do everything that the ones before it may:
+//   - ROLE_VIEWER   : Reads topics, messages and statistics.
+//   - ROLE_OPERATOR : Publishes, subscribes, unsubscribes, marks favourites and replays.
+//   - ROLE_ADMIN    : Deletes data and manages brokers, rules, projects, backups, accounts and roles.
+// - A role is given for a broker, or for all brokers with BrokerId 0, and for the topics of a MQTT topic filter.
+//
+// # Author
+// - Polariusz
+const (
+	ROLE_VIEWER = "viewer"
+	ROLE_OPERATOR = "operator"
+	ROLE_ADMIN = "admin"
+)
+
+// # Author
+// - Polariusz
+var roleRank = map[string]int{
+	ROLE_VIEWER: 1,
+	ROLE_OPERATOR: 2,
+	ROLE_ADMIN: 3,
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Matcher
+//
+// # Description
+// - The method shall check if every topic that matches the filter `inner` also matches the filter `outer`.
+// - A topic is a filter without wildcards, so it is also used to check single topics.
+//
+// # Returns
+// - true if `outer` covers `inner`
+//
+// # Author
+// - Polariusz
+func filterCovers(outer string, inner string) bool {
+	if strings.HasPrefix(inner, "$") && (strings.HasPrefix(outer, "+") || strings.HasPrefix(outer, "#")) {
+		return false
+	}
+
+	outerLevels := strings.Split(outer, "/")
+	innerLevels := strings.Split(inner, "/")
+
+	for i, outerLevel := range outerLevels {
+		if outerLevel == "#" {
+			return true
+		}
+		if i >= len(innerLevels) || innerLevels[i] == "#" {
+			return false
+		}
+		if outerLevel != "+" && outerLevel != innerLevels[i] {
+			return false
+		}
+	}
+
+	return len(outerLevels) == len(innerLevels)
+}
+
+// | Date of change | By        | Comment                   |
+// +----------------+-----------+---------------------------+
+// | 2026-10-19     | Polariusz | Created                   |
+// | 2026-10-19     | Polariusz | Nil only without accounts |
+//
+// # Description
+// - The roles of the account of a request. A nil `*Permissions` allows everything, it is what requests get while there are no accounts.
+// - A request without a session while there are accounts gets a `*Permissions` without assignments, which allows nothing, see permissionsOf().
+//
+// # Author
+// - Polariusz
+type Permissions struct {
+	assignments []database.SelectRoleAssignment
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall tell whether the account has at least `role` for all topics of `filter` on the broker.
+// - With `brokerId` 0, the role must be given for all brokers.
+//
+// # Author
+// - Polariusz
+func (p *Permissions) allows(role string, brokerId int, filter string) bool {
+	if p == nil {
+		return true
+	}
+	for _, assignment := range p.assignments {
+		if roleRank[assignment.Role] >= roleRank[role] && (assignment.BrokerId == 0 || assignment.BrokerId == brokerId) && filterCovers(assignment.TopicFilter, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall tell whether the account has at least `role` for any topic of the broker, the handlers that list topics then leave out the others.
+//
+// # Author
+// - Polariusz
+func (p *Permissions) allowsAny(role string, brokerId int) bool {
+	if p == nil {
+		return true
+	}
+	for _, assignment := range p.assignments {
+		if roleRank[assignment.Role] >= roleRank[role] && (assignment.BrokerId == 0 || assignment.BrokerId == brokerId) {
+			return true
+		}
+	}
+	return false
+}
+
+// | Date of change | By        | Comment                |
+// +----------------+-----------+------------------------+
+// | 2026-10-19     | Polariusz | Created                |
+// | 2026-10-19     | Polariusz | Denies without session |
+//
+// # Method-Type
+// - Helper
+//
+// # Description
+// - The function shall return the permissions of the session of the request, loaded once per request.
+// - The roles are read from the database each time, so that a change by an admin applies to the next request.
+// - Only while there are no accounts everything is allowed. A request without a session is denied everything as soon as there is an account.
+//
+// # Returns
+// - *Permissions, nil while there are no accounts
+// - error when Skill Issues
+//
+// # Author
+// - Polariusz
+func permissionsOf(c *fiber.Ctx, serverState *ServerState) (*Permissions, error) {
+	if !serverState.accounts.enabled() {
+		return nil, nil
+	}
+	session := sessionOf(c)
+	if session == nil {
+		return &Permissions{}, nil
+	}
+	if permissions, ok := c.Locals("permissions").(*Permissions); ok {
+		return permissions, nil
+	}
+
+	assignmentList, err := database.SelectRoleAssignments(serverState.accounts.con, session.AccountId)
+	if err != nil {
+		return nil, err
+	}
+	permissions := &Permissions{assignments: assignmentList}
+	c.Locals("permissions", permissions)
+	return permissions, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Helper
+//
+// # Description
+// - The function shall check that the account of the request has at least `role` on the broker for every filter of `filters`.
+// - Without filters, the role is needed for the whole broker, as if the filter was "#".
+// - With `brokerId` 0, the role is needed for all brokers.
+//
+// # Usage
+// - `if ok, err := authorize(c, serverState, ROLE_OPERATOR, brokerId, topic); !ok { return err }`
+//
+// # Returns
+// - bool: false if the response was written
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"<What is missing>"}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while selecting the roles","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func authorize(c *fiber.Ctx, serverState *ServerState, role string, brokerId int, filters ...string) (bool, error) {
+	permissions, err := permissionsOf(c, serverState)
+	if err != nil {
+		return false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"InternalServerError": "Error while selecting the roles",
+			"Error": err.Error(),
+		})
+	}
+	if len(filters) == 0 {
+		filters = []string{"#"}
+	}
+
+	for _, filter := range filters {
+		if !permissions.allows(role, brokerId, filter) {
+			return false, forbidden(c, role, brokerId, "for "+filter)
+		}
+	}
+
+	return true, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Helper
+//
+// # Description
+// - The function shall check that the account of the request has at least `role` for any topic of the broker.
+// - It is for the handlers that list topics, they leave out the topics that `Permissions.allows()` denies.
+//
+// # Returns
+// - *Permissions of the request, to filter the topics with
+// - bool: false if the response was written
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"<What is missing>"}
+// - 500 (Internal Server Error): JSON
+//   - {"InternalServerError":"Error while selecting the roles","Error":"<err>"}
+//
+// # Author
+// - Polariusz
+func authorizeAny(c *fiber.Ctx, serverState *ServerState, role string, brokerId int) (*Permissions, bool, error) {
+	permissions, err := permissionsOf(c, serverState)
+	if err != nil {
+		return nil, false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"InternalServerError": "Error while selecting the roles",
+			"Error": err.Error(),
+		})
+	}
+	if !permissions.allowsAny(role, brokerId) {
+		return nil, false, forbidden(c, role, brokerId, "for any topic")
+	}
+
+	return permissions, true, nil
+}
+
+// | Date of change | By        | Comment                |
+// +----------------+-----------+------------------------+
+// | 2026-10-19     | Polariusz | Created                |
+// | 2026-10-19     | Polariusz | Denies without session |
+//
+// # Method-Type
+// - Helper
+//
+// # Description
+// - The function shall check that the account of the request may connect to the broker at `ip`:`port`.
+// - A known broker needs any role on it. A broker that is not known yet is added by the connection, which only an admin of all brokers may do.
+//
+// # Returns
+// - bool: false if the response was written, see authorizeAny()
+//
+// # Author
+// - Polariusz
+func authorizeConnection(c *fiber.Ctx, serverState *ServerState, ip string, port int) (bool, error) {
+	if !serverState.accounts.enabled() {
+		return true, nil
+	}
+
+	brokerList, err := serverState.store.SelectBrokerList()
+	if err != nil {
+		return false, c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"InternalServerError": "Error while selecting the brokers",
+			"Error": err.Error(),
+		})
+	}
+	for _, broker := range brokerList {
+		if broker.Ip == ip && broker.Port == port {
+			_, ok, err := authorizeAny(c, serverState, ROLE_VIEWER, broker.Id)
+			return ok, err
+		}
+	}
+
+	return authorize(c, serverState, ROLE_ADMIN, 0)
+}
+
+// # Author
+// - Polariusz
+func forbidden(c *fiber.Ctx, role string, brokerId int, what string) error {
+	where := fmt.Sprintf("on the broker %d", brokerId)
+	if brokerId == 0 {
+		where = "on all brokers"
+	}
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"Forbidden": fmt.Sprintf("The role %s is needed %s %s", role, where, what),
+	})
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall tell whether the assignment makes its account an admin on all topics of all brokers.
+//
+// # Author
+// - Polariusz
+func isGlobalAdmin(assignment database.SelectRoleAssignment) bool {
+	return assignment.Role == ROLE_ADMIN && assignment.BrokerId == 0 && assignment.TopicFilter == "#"
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall count the accounts that stay admins on everything if `without` is taken away.
+// - `without` is called with each assignment, it returns true for the ones that are about to be deleted.
+// - The last of these admins can not be taken away, nobody could give out roles then. Only `main account grant` could.
+//
+// # Author
+// - Polariusz
+func countGlobalAdmins(serverState *ServerState, without func(database.SelectRoleAssignment) bool) (int, error) {
+	assignmentList, err := database.SelectRoleAssignments(serverState.accounts.con, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	admins := make(map[int]bool)
+	for _, assignment := range assignmentList {
+		if isGlobalAdmin(assignment) && !without(assignment) {
+			admins[assignment.AccountId] = true
+		}
+	}
+	return len(admins), nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall validate and insert a role assignment.
+//
+// # Returns
+// - int: [RoleAssignment].[ID]
+// - error when:
+//   - The assignment is not valid, the message can be shown to the client
+//   - Skill Issues
+//
+// # Author
+// - Polariusz
+func grantRole(con *sql.DB, assignment database.InsertRoleAssignment) (int, error) {
+	if _, ok := roleRank[assignment.Role]; !ok {
+		return -1, fmt.Errorf("Role must be %s, %s or %s", ROLE_VIEWER, ROLE_OPERATOR, ROLE_ADMIN)
+	}
+	if assignment.BrokerId < 0 {
+		return -1, fmt.Errorf("BrokerId must not be negative")
+	}
+	if assignment.TopicFilter == "" {
+		assignment.TopicFilter = "#"
+	}
+	if !validTopicFilter(assignment.TopicFilter) {
+		return -1, fmt.Errorf("TopicFilter is not a valid MQTT topic filter")
+	}
+	if _, exists, err := database.SelectAccountById(con, assignment.AccountId); err != nil {
+		return -1, err
+	} else if !exists {
+		return -1, fmt.Errorf("There is no account with this Id")
+	}
+
+	return database.InsertNewRoleAssignment(con, assignment)
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"AccountId":<A>,"Role":"<R>","BrokerId":<B>,"TopicFilter":"<F>"}
+//   - <A> : The ID of the Account ROW
+//   - <R> : viewer, operator or admin
+//   - <B> : The ID of the Broker ROW, 0 for all brokers
+//   - <F> : MQTT topic filter, "#" if left out
+//
+// # Used in
+// - PostRoleHandler()
+//
+// # Author
+// - Polariusz
+type RoleWrapper struct {
+	AccountId int
+	Role string
+	BrokerId int
+	TopicFilter string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall list the role assignments, of all accounts or of the one in the query `accountId`.
+// - Only an admin of all brokers may call it.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"roles":[<database.SelectRoleAssignment>]}
+// - 403 (Forbidden): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func GetRolesHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		accountId, err := queryInt(c, "accountId", 0)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+
+		assignmentList, err := database.SelectRoleAssignments(serverState.accounts.con, accountId)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the roles", map[string]any{"Error": err.Error()})
+		}
+		if assignmentList == nil {
+			assignmentList = []database.SelectRoleAssignment{}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"roles": assignmentList,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Audited |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall give an account a role. Only an admin of all brokers may call it.
+// - The method shall accept a jsonified structure that follows the struct RoleWrapper.
+//
+// # Returns
+// - 201 (Created): JSON
+//   - {"Id":<ROLE-ASSIGNMENT-ID>}
+// - 400 (Bad Request): `APIErrorEnvelope`
+// - 403 (Forbidden): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func PostRoleHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_ROLE_GRANT}
+		defer serverState.auditLog.record(c, &record)
+
+		var roleWrapper RoleWrapper
+		if err := c.BodyParser(&roleWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+		record.about(BrokerUser{BrokerId: roleWrapper.BrokerId}, roleWrapper.TopicFilter)
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+
+		assignmentId, err := grantRole(serverState.accounts.con, database.InsertRoleAssignment{
+			AccountId: roleWrapper.AccountId,
+			Role: roleWrapper.Role,
+			BrokerId: roleWrapper.BrokerId,
+			TopicFilter: roleWrapper.TopicFilter,
+		})
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"Id": assignmentId,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Audited |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall take a role away. Only an admin of all brokers may call it.
+// - The last admin of all brokers can not be taken away.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"Id":<ROLE-ASSIGNMENT-ID>}
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+// - 409 (Conflict): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func DeleteRoleHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_ROLE_REVOKE}
+		defer serverState.auditLog.record(c, &record)
+
+		assignmentId, err := paramId(c, "id")
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+
+		admins, err := countGlobalAdmins(serverState, func(assignment database.SelectRoleAssignment) bool {
+			return assignment.Id == assignmentId
+		})
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the roles", map[string]any{"Error": err.Error()})
+		}
+		if admins == 0 && serverState.accounts.enabled() {
+			return writeAPIError(c, fiber.StatusConflict, API_ERROR_CONFLICT, "This is the last admin of all brokers", nil)
+		}
+
+		deleted, err := database.DeleteRoleAssignment(serverState.accounts.con, assignmentId)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while deleting the role", map[string]any{"Error": err.Error()})
+		}
+		if !deleted {
+			return writeAPIError(c, fiber.StatusNotFound, API_ERROR_NOT_FOUND, "There is no role assignment with this Id", nil)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"Id": assignmentId,
+		})
+	}
+}
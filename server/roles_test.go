@@ -0,0 +1,245 @@
+package main
+
+import (
+	"database"
+	"fmt"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestFilterCovers(t *testing.T) {
+	cases := []struct {
+		outer string
+		inner string
+		want bool
+	}{
+		{"#", "a/b", true},
+		{"#", "a/+/#", true},
+		{"#", "$SYS/load", false},
+		{"a/#", "a", true},
+		{"a/#", "a/b/c", true},
+		{"a/#", "b/c", false},
+		{"a/+", "a/b", true},
+		{"a/+", "a/+", true},
+		{"a/+", "a/#", false},
+		{"a/+", "a/b/c", false},
+		{"a/b", "a/+", false},
+		{"a/b", "a/b", true},
+		{"+/b/#", "x/b/c/d", true},
+		{"$SYS/#", "$SYS/load", true},
+	}
+
+	for _, tc := range cases {
+		if got := filterCovers(tc.outer, tc.inner); got != tc.want {
+			t.Errorf("filterCovers(%q, %q) = %v, want %v", tc.outer, tc.inner, got, tc.want)
+		}
+	}
+}
+
+// Logs the account in and returns the header that carries its session.
+func login(t *testing.T, server *fiber.App, username string, password string) map[string]string {
+	t.Helper()
+
+	status, body := request(t, server, "POST", "/api/v1/sessions", fmt.Sprintf(`{"Username":%q,"Password":%q}`, username, password), nil)
+	if status != fiber.StatusCreated {
+		t.Fatalf("login as %s: %d %v", username, status, body)
+	}
+	return map[string]string{"Authorization": "Bearer " + body["token"].(string)}
+}
+
+func TestRoles(t *testing.T) {
+	con, err := database.OpenDatabase(database.InMemoryDatabase)
+	if err != nil {
+		t.Fatalf("OpenDatabase: %s", err)
+	}
+	defer con.Close()
+	if err := database.SetupDatabase(con); err != nil {
+		t.Fatalf("SetupDatabase: %s", err)
+	}
+
+	accounts, err := NewAccountManager(con)
+	if err != nil {
+		t.Fatalf("NewAccountManager: %s", err)
+	}
+	serverState := &ServerState{con: con, accounts: accounts}
+	server := fiber.New()
+	addRoutes(server, serverState)
+
+	if _, err := accounts.createAccount("admin", "correct horse"); err != nil {
+		t.Fatalf("createAccount: %s", err)
+	}
+	admin := login(t, server, "admin", "correct horse")
+
+	status, body := request(t, server, "POST", "/api/v1/accounts", `{"Username":"viewer","Password":"battery staple"}`, admin)
+	if status != fiber.StatusCreated {
+		t.Fatalf("create the viewer: %d %v", status, body)
+	}
+	viewerId := int(body["Id"].(float64))
+	viewer := login(t, server, "viewer", "battery staple")
+
+	// Without a role, the viewer may do nothing yet.
+	if status, _ := request(t, server, "DELETE", "/api/v1/brokers/1?dryRun=true", "", viewer); status != fiber.StatusForbidden {
+		t.Errorf("delete a broker without a role: %d", status)
+	}
+
+	if status, body := request(t, server, "POST", "/api/v1/roles", fmt.Sprintf(`{"AccountId":%d,"Role":"viewer","BrokerId":1,"TopicFilter":"sensors/#"}`, viewerId), admin); status != fiber.StatusCreated {
+		t.Fatalf("grant the viewer: %d %v", status, body)
+	}
+	if status, _ := request(t, server, "POST", "/api/v1/roles", fmt.Sprintf(`{"AccountId":%d,"Role":"owner"}`, viewerId), admin); status != fiber.StatusBadRequest {
+		t.Errorf("grant an unknown role: %d", status)
+	}
+
+	// Only admins manage accounts and roles, and delete data.
+	for _, call := range []struct {
+		method string
+		path string
+		body string
+	}{
+		{"GET", "/api/v1/roles", ""},
+		{"POST", "/api/v1/roles", fmt.Sprintf(`{"AccountId":%d,"Role":"admin"}`, viewerId)},
+		{"GET", "/api/v1/accounts", ""},
+		{"POST", "/api/v1/accounts", `{"Username":"intruder","Password":"battery staple"}`},
+		{"DELETE", "/api/v1/brokers/1?dryRun=true", ""},
+		{"DELETE", "/api/v1/messages?brokerId=1&topicFilter=sensors/%23&dryRun=true", ""},
+	} {
+		status, body := request(t, server, call.method, call.path, call.body, viewer)
+		if status != fiber.StatusForbidden || body["error"].(map[string]any)["code"] != API_ERROR_FORBIDDEN {
+			t.Errorf("%s %s as the viewer: %d %v", call.method, call.path, status, body)
+		}
+	}
+
+	status, body = request(t, server, "GET", "/api/v1/sessions/current", "", viewer)
+	if roles := body["roles"].([]any); status != fiber.StatusOK || len(roles) != 1 || roles[0].(map[string]any)["TopicFilter"] != "sensors/#" {
+		t.Errorf("the roles of the viewer: %d %v", status, body)
+	}
+
+	// The first account is the only admin of all brokers, it can not be taken away.
+	status, body = request(t, server, "GET", "/api/v1/roles?accountId=1", "", admin)
+	if status != fiber.StatusOK {
+		t.Fatalf("the roles of the admin: %d %v", status, body)
+	}
+	adminRoleId := int(body["roles"].([]any)[0].(map[string]any)["Id"].(float64))
+	if status, _ := request(t, server, "DELETE", fmt.Sprintf("/api/v1/roles/%d", adminRoleId), "", admin); status != fiber.StatusConflict {
+		t.Errorf("take the last admin away: %d", status)
+	}
+	if status, _ := request(t, server, "DELETE", "/api/v1/accounts/1", "", admin); status != fiber.StatusConflict {
+		t.Errorf("delete the last admin: %d", status)
+	}
+	if status, _ := request(t, server, "DELETE", fmt.Sprintf("/api/v1/accounts/%d", viewerId), "", admin); status != fiber.StatusOK {
+		t.Errorf("delete the viewer: %d", status)
+	}
+}
+
+// A handler that is reached without a session, for example because it is public, must not get the rights of an admin.
+func TestAuthorizeWithoutSession(t *testing.T) {
+	con, err := database.OpenDatabase(database.InMemoryDatabase)
+	if err != nil {
+		t.Fatalf("OpenDatabase: %s", err)
+	}
+	defer con.Close()
+	if err := database.SetupDatabase(con); err != nil {
+		t.Fatalf("SetupDatabase: %s", err)
+	}
+
+	accounts, err := NewAccountManager(con)
+	if err != nil {
+		t.Fatalf("NewAccountManager: %s", err)
+	}
+	serverState := &ServerState{con: con, store: database.NewSqliteStore(con), accounts: accounts}
+	server := fiber.New()
+	server.Get("/admin", func(c *fiber.Ctx) error {
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, 0); !ok {
+			return err
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	server.Get("/connect", func(c *fiber.Ctx) error {
+		if ok, err := authorizeConnection(c, serverState, "localhost", 1883); !ok {
+			return err
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	for _, path := range []string{"/admin", "/connect"} {
+		if status, _ := request(t, server, "GET", path, "", nil); status != fiber.StatusOK {
+			t.Errorf("%s without accounts: %d", path, status)
+		}
+	}
+
+	if _, err := accounts.createAccount("admin", "correct horse"); err != nil {
+		t.Fatalf("createAccount: %s", err)
+	}
+	for _, path := range []string{"/admin", "/connect"} {
+		if status, body := request(t, server, "GET", path, "", nil); status != fiber.StatusForbidden {
+			t.Errorf("%s without a session: %d %v", path, status, body)
+		}
+	}
+}
+
+func TestViewerListsOnlyItsBrokers(t *testing.T) {
+	serverState, server := newTestServerState(t, NewProjectManager(DatabaseConfig{InMemory: true}))
+	if _, err := serverState.accounts.createAccount("admin", "correct horse"); err != nil {
+		t.Fatalf("createAccount: %s", err)
+	}
+	admin := login(t, server, "admin", "correct horse")
+	status, body := request(t, server, "POST", "/api/v1/accounts", `{"Username":"viewer","Password":"battery staple"}`, admin)
+	if status != fiber.StatusCreated {
+		t.Fatalf("create the viewer: %d %v", status, body)
+	}
+	if status, body := request(t, server, "POST", "/api/v1/roles", fmt.Sprintf(`{"AccountId":%d,"Role":"viewer","BrokerId":1,"TopicFilter":"sensors/#"}`, int(body["Id"].(float64))), admin); status != fiber.StatusCreated {
+		t.Fatalf("grant the viewer: %d %v", status, body)
+	}
+	viewer := login(t, server, "viewer", "battery staple")
+
+	// One of each on the topics of the viewer, on another broker and on all brokers, the replays get the Ids 1, 2 and 3.
+	store := serverState.store
+	for _, rule := range []struct {
+		brokerId int
+		filter string
+	}{{1, "sensors/#"}, {2, "sensors/#"}, {0, "secret/#"}} {
+		database.InsertNewDedupRule(serverState.con, database.InsertDedupRule{BrokerId: rule.brokerId, TopicFilter: rule.filter, WindowSeconds: 60})
+		database.InsertNewRetentionRule(serverState.con, database.InsertRetentionRule{BrokerId: rule.brokerId, TopicFilter: rule.filter, MaxAgeSeconds: 60})
+		serverState.replayManager.add(&ReplayJob{status: ReplayStatus{BrokerId: rule.brokerId, TopicFilter: rule.filter}, request: ReplayWrapper{BrokerUserIDs: BrokerUser{BrokerId: rule.brokerId}, TopicFilter: rule.filter}})
+	}
+	for brokerId := 1; brokerId <= 2; brokerId++ {
+		store.InsertNewBroker(database.InsertBroker{Ip: fmt.Sprintf("10.0.0.%d", brokerId), Port: 1883})
+	}
+	for _, topic := range []string{"sensors/temperature", "secret/key"} {
+		for brokerId := 1; brokerId <= 2; brokerId++ {
+			userId, _ := store.InsertNewUser(database.InsertUser{BrokerId: brokerId, ClientId: "sensor", Outsider: true})
+			topicId, _ := store.InsertNewTopic(database.InsertTopic{BrokerId: brokerId, Topic: topic})
+			store.InsertNewMessage(database.InsertMessage{UserId: userId, TopicId: topicId, BrokerId: brokerId, Message: "1", IsDuplicate: true})
+		}
+	}
+
+	for path, key := range map[string]string{
+		"/api/v1/dedup/rules": "rules",
+		"/api/v1/dedup/report": "duplicates",
+		"/api/v1/retention/rules": "rules",
+		"/api/v1/retention/report": "rules",
+		"/api/v1/replays": "replays",
+	} {
+		if status, body := request(t, server, "GET", path, "", admin); status != fiber.StatusOK || len(body[key].([]any)) < 3 {
+			t.Errorf("%s as the admin: %d %v", path, status, body)
+		}
+		if status, body := request(t, server, "GET", path, "", viewer); status != fiber.StatusOK || len(body[key].([]any)) != 1 {
+			t.Errorf("%s as the viewer: %d %v", path, status, body)
+		}
+	}
+	if _, body := request(t, server, "GET", "/api/v1/dedup/report", "", viewer); body["total"] != float64(1) {
+		t.Errorf("the duplicates of the viewer: %v", body)
+	}
+
+	for _, path := range []string{"/api/v1/backups", "/api/v1/projects", "/api/v1/ingest/metrics", "/api/v1/replays/2"} {
+		if status, body := request(t, server, "GET", path, "", viewer); status != fiber.StatusForbidden {
+			t.Errorf("%s as the viewer: %d %v", path, status, body)
+		}
+		if status, body := request(t, server, "GET", path, "", admin); status != fiber.StatusOK {
+			t.Errorf("%s as the admin: %d %v", path, status, body)
+		}
+	}
+	if status, body := request(t, server, "GET", "/api/v1/replays/1", "", viewer); status != fiber.StatusOK {
+		t.Errorf("the replay of the viewer: %d %v", status, body)
+	}
+}
@@ -0,0 +1,911 @@
+package main
+
+import (
+	"bytes"
+	"database"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gofiber/fiber/v2"
+)
+
+// How long a run waits for the broker to acknowledge its publish.
+const SCHEDULE_PUBLISH_TIMEOUT = 10 * time.Second
+
+// How long the scheduler sleeps when nothing is due, it is woken up earlier when a schedule changes.
+const SCHEDULE_IDLE = time.Hour
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - What the payload template of a schedule can use, like `{"seq":{{.Run}},"temp":{{printf "%.1f" (randFloat 20 25)}}}`.
+//   - `Run` counts the runs of the schedule from 1.
+//   - `Now` is the time of the run.
+// - The template can also call `json`, `randInt min max` and `randFloat min max`.
+//
+// # Used in
+// - compiledSchedule
+//
+// # Author
+// - Polariusz
+type ScheduleTemplateData struct {
+	Name string
+	BrokerId int
+	Topic string
+	Run int
+	Now time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A schedule with its parsed template and cron expression.
+//
+// # Author
+// - Polariusz
+type compiledSchedule struct {
+	schedule database.SelectSchedule
+	payload *template.Template
+	cron *cronExpression
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall parse the payload template and the cron expression of the schedule.
+//
+// # Returns
+// - error when one of them is not valid, the message can be shown to the client
+//
+// # Author
+// - Polariusz
+func compileSchedule(schedule database.SelectSchedule) (*compiledSchedule, error) {
+	compiled := &compiledSchedule{schedule: schedule}
+
+	payload, err := template.New("payload").Funcs(template.FuncMap{
+		"json": func(value any) (string, error) {
+			encoded, err := json.Marshal(value)
+			return string(encoded), err
+		},
+		"randInt": func(min int, max int) int {
+			if max <= min {
+				return min
+			}
+			return min + rand.Intn(max-min+1)
+		},
+		"randFloat": func(min float64, max float64) float64 {
+			return min + rand.Float64()*(max-min)
+		},
+	}).Option("missingkey=error").Parse(schedule.PayloadTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("PayloadTemplate is not valid: %s", err)
+	}
+	compiled.payload = payload
+
+	if schedule.Kind == database.SCHEDULE_CRON {
+		if compiled.cron, err = parseCron(schedule.CronExpression); err != nil {
+			return nil, err
+		}
+	}
+
+	return compiled, nil
+}
+
+// # Description
+// - The method shall write the payload of a run.
+//
+// # Author
+// - Polariusz
+func (cs *compiledSchedule) render(run int, now time.Time) (string, error) {
+	var payload bytes.Buffer
+	err := cs.payload.Execute(&payload, ScheduleTemplateData{
+		Name: cs.schedule.Name,
+		BrokerId: cs.schedule.BrokerId,
+		Topic: cs.schedule.Topic,
+		Run: run,
+		Now: now,
+	})
+	return payload.String(), err
+}
+
+// # Description
+// - The method shall return when a schedule that is enabled at `now` runs first.
+//   - A one-shot runs at RunAt, even if it has passed.
+//   - An interval runs at RunAt if it is still ahead, otherwise one interval from now.
+//   - A cron expression runs at its next time.
+//
+// # Returns
+// - nil if it never runs
+//
+// # Author
+// - Polariusz
+func (cs *compiledSchedule) firstRun(now time.Time) *time.Time {
+	var first time.Time
+	switch cs.schedule.Kind {
+	case database.SCHEDULE_ONCE:
+		first = *cs.schedule.RunAt
+	case database.SCHEDULE_INTERVAL:
+		if cs.schedule.RunAt != nil && cs.schedule.RunAt.After(now) {
+			first = *cs.schedule.RunAt
+		} else {
+			first = now.Add(time.Duration(cs.schedule.IntervalSeconds) * time.Second)
+		}
+	case database.SCHEDULE_CRON:
+		var ok bool
+		if first, ok = cs.cron.next(now); !ok {
+			return nil
+		}
+	}
+	return &first
+}
+
+// # Description
+// - The method shall return when the schedule runs after the run that was due at `scheduled` and happened at `now`.
+// - Runs that were missed, because the publish took long or the server was down, are not made up.
+//
+// # Returns
+// - nil if it is done, like a one-shot
+//
+// # Author
+// - Polariusz
+func (cs *compiledSchedule) nextRun(scheduled time.Time, now time.Time) *time.Time {
+	var next time.Time
+	switch cs.schedule.Kind {
+	case database.SCHEDULE_INTERVAL:
+		interval := time.Duration(cs.schedule.IntervalSeconds) * time.Second
+		if next = scheduled.Add(interval); !next.After(now) {
+			next = now.Add(interval)
+		}
+	case database.SCHEDULE_CRON:
+		var ok bool
+		if next, ok = cs.cron.next(now); !ok {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return &next
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - It publishes the enabled schedules of table Schedule when they are due, through the MQTT-Client, and records every run in table ScheduleRun.
+// - NextRunAt and Enabled are kept in the database, so the schedules go on after a restart.
+//   - A one-shot that was due while the server was down runs when it is up again, the other schedules skip what they have missed.
+// - A run is skipped when the MQTT-Client is not connected to the broker of the schedule.
+// - Like the webhooks, it belongs to one database and is replaced with it.
+//
+// # Used in
+// - ServerState
+//
+// # Author
+// - Polariusz
+type Scheduler struct {
+	con *sql.DB
+	// The MQTT-Client that is connected to the broker, nil if there is none.
+	client func(brokerId int) mqtt.Client
+	mutex sync.Mutex
+	schedules map[int]*compiledSchedule
+	wake chan struct{}
+	done chan struct{}
+	stopped chan struct{}
+}
+
+// # Author
+// - Polariusz
+func NewScheduler(con *sql.DB, serverState *ServerState) *Scheduler {
+	return &Scheduler{
+		con: con,
+		client: func(brokerId int) mqtt.Client {
+			if serverState.mqttClient == nil || !serverState.mqttClient.IsConnected() || serverState.connected.BrokerId != brokerId {
+				return nil
+			}
+			return serverState.mqttClient
+		},
+		schedules: make(map[int]*compiledSchedule),
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// # Description
+// - The method shall load the schedules and start publishing them.
+// - The schedules that have missed runs while the server was down get their next run from now, except the one-shots.
+//
+// # Author
+// - Polariusz
+func (s *Scheduler) start() {
+	if err := s.reload(); err != nil {
+		fmt.Printf("WARN: Running without schedules\nErr:%s\n", err)
+	}
+
+	now := time.Now()
+	s.mutex.Lock()
+	for _, compiled := range s.schedules {
+		schedule := &compiled.schedule
+		if !schedule.Enabled || schedule.Kind == database.SCHEDULE_ONCE || (schedule.NextRunAt != nil && schedule.NextRunAt.After(now)) {
+			continue
+		}
+		schedule.NextRunAt = compiled.firstRun(now)
+		schedule.Enabled = schedule.NextRunAt != nil
+		if _, err := database.UpdateScheduleEnabled(s.con, schedule.Id, schedule.Enabled, schedule.NextRunAt); err != nil {
+			fmt.Printf("WARN: The next run of the schedule %d was not written\nErr: %s\n", schedule.Id, err)
+		}
+	}
+	s.mutex.Unlock()
+
+	go func() {
+		defer close(s.stopped)
+
+		for {
+			timer := time.NewTimer(s.untilNext(time.Now()))
+			select {
+			case <-s.done:
+				timer.Stop()
+				return
+			case <-s.wake:
+				timer.Stop()
+			case now := <-timer.C:
+				s.runDue(now)
+			}
+		}
+	}()
+}
+
+// # Description
+// - The method shall stop publishing, after the run that is in progress.
+//
+// # Author
+// - Polariusz
+func (s *Scheduler) close() {
+	close(s.done)
+	<-s.stopped
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall read the schedules from the database, it is called when they are created or deleted.
+// - The scheduler knows best when the schedules that it already has run next, so that is kept.
+//
+// # Returns
+// - error when the schedules cannot be selected, the old ones stay in use
+//
+// # Author
+// - Polariusz
+func (s *Scheduler) reload() error {
+	scheduleList, err := database.SelectSchedules(s.con)
+	if err != nil {
+		return err
+	}
+
+	schedules := make(map[int]*compiledSchedule)
+	for _, schedule := range scheduleList {
+		compiled, err := compileSchedule(schedule)
+		if err != nil {
+			fmt.Printf("WARN: The schedule %d is skipped\nErr: %s\n", schedule.Id, err)
+			continue
+		}
+		schedules[schedule.Id] = compiled
+	}
+
+	s.mutex.Lock()
+	for scheduleId, compiled := range schedules {
+		if known := s.schedules[scheduleId]; known != nil {
+			compiled.schedule.Enabled = known.schedule.Enabled
+			compiled.schedule.NextRunAt = known.schedule.NextRunAt
+			compiled.schedule.RunCount = known.schedule.RunCount
+		}
+	}
+	s.schedules = schedules
+	s.mutex.Unlock()
+
+	s.poke()
+	return nil
+}
+
+// # Description
+// - The method shall make the scheduler look at the schedules again, without waiting.
+//
+// # Author
+// - Polariusz
+func (s *Scheduler) poke() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// # Description
+// - The method shall return how long to wait for the next run that is due.
+//
+// # Author
+// - Polariusz
+func (s *Scheduler) untilNext(now time.Time) time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	wait := SCHEDULE_IDLE
+	for _, compiled := range s.schedules {
+		if compiled.schedule.Enabled && compiled.schedule.NextRunAt != nil {
+			wait = min(wait, compiled.schedule.NextRunAt.Sub(now))
+		}
+	}
+	return max(wait, 0)
+}
+
+// # Description
+// - The method shall run every schedule that is due at `now`, one after the other.
+//
+// # Author
+// - Polariusz
+func (s *Scheduler) runDue(now time.Time) {
+	var dueList []int
+
+	s.mutex.Lock()
+	for scheduleId, compiled := range s.schedules {
+		if compiled.schedule.Enabled && compiled.schedule.NextRunAt != nil && !compiled.schedule.NextRunAt.After(now) {
+			dueList = append(dueList, scheduleId)
+		}
+	}
+	s.mutex.Unlock()
+
+	for _, scheduleId := range dueList {
+		s.run(scheduleId, now)
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall publish the payload of the schedule, record the run and move the schedule on to its next run.
+// - If the schedule was disabled while it ran, it stays disabled.
+//
+// # Author
+// - Polariusz
+func (s *Scheduler) run(scheduleId int, now time.Time) {
+	s.mutex.Lock()
+	compiled := s.schedules[scheduleId]
+	if compiled == nil || !compiled.schedule.Enabled || compiled.schedule.NextRunAt == nil {
+		s.mutex.Unlock()
+		return
+	}
+	schedule := compiled.schedule
+	s.mutex.Unlock()
+
+	run := database.InsertScheduleRun{ScheduleId: schedule.Id, ScheduledAt: *schedule.NextRunAt, Status: database.SCHEDULE_RUN_PUBLISHED}
+	payload, err := compiled.render(schedule.RunCount+1, now)
+	run.Payload = payload
+	if err != nil {
+		run.Status = database.SCHEDULE_RUN_FAILED
+		run.Error = err.Error()
+	} else if client := s.client(schedule.BrokerId); client == nil {
+		run.Status = database.SCHEDULE_RUN_SKIPPED
+		run.Error = fmt.Sprintf("The MQTT-Client is not connected to the broker %d.", schedule.BrokerId)
+	} else if token := client.Publish(schedule.Topic, byte(schedule.QoS), schedule.Retain, payload); !token.WaitTimeout(SCHEDULE_PUBLISH_TIMEOUT) {
+		run.Status = database.SCHEDULE_RUN_FAILED
+		run.Error = "The broker did not acknowledge the publish in time."
+	} else if token.Error() != nil {
+		run.Status = database.SCHEDULE_RUN_FAILED
+		run.Error = token.Error().Error()
+	}
+
+	next := compiled.nextRun(run.ScheduledAt, time.Now())
+	if _, err := database.InsertNewScheduleRun(s.con, run, next); err != nil {
+		fmt.Printf("ERROR: A run of the schedule %d was not written!\nErr: %s\n", schedule.Id, err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// The schedule may have been reloaded or deleted while it ran.
+	current := s.schedules[scheduleId]
+	if current == nil {
+		return
+	}
+	current.schedule.RunCount++
+	if current.schedule.Enabled {
+		current.schedule.NextRunAt = next
+		current.schedule.Enabled = next != nil
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall enable or disable a schedule, in the database and in the scheduler.
+//
+// # Returns
+// - database.SelectSchedule: The schedule as it is now
+// - error when it is not known, or a one-shot whose time has passed is enabled. The message can be shown to the client.
+//
+// # Author
+// - Polariusz
+func (s *Scheduler) setEnabled(scheduleId int, enabled bool) (database.SelectSchedule, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	compiled := s.schedules[scheduleId]
+	if compiled == nil {
+		return database.SelectSchedule{}, fmt.Errorf("The schedule %d is not loaded", scheduleId)
+	}
+
+	var next *time.Time
+	if enabled {
+		now := time.Now()
+		if compiled.schedule.Kind == database.SCHEDULE_ONCE && !compiled.schedule.RunAt.After(now) {
+			return compiled.schedule, fmt.Errorf("RunAt has passed, create a new schedule")
+		}
+		if next = compiled.firstRun(now); next == nil {
+			return compiled.schedule, fmt.Errorf("The cron expression has no next time")
+		}
+	}
+	if _, err := database.UpdateScheduleEnabled(s.con, scheduleId, enabled, next); err != nil {
+		return compiled.schedule, err
+	}
+	compiled.schedule.Enabled = enabled
+	compiled.schedule.NextRunAt = next
+
+	s.poke()
+	return compiled.schedule, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Name":"<N>","BrokerId":<B>,"Topic":"<T>","PayloadTemplate":"<P>","QoS":<Q>,"Retain":<R>,"Kind":"<K>","RunAt":"<A>","IntervalSeconds":<I>,"CronExpression":"<C>"}
+//   - <B> : The ID of the Broker ROW, the MQTT-Client must be connected to it for the runs
+//   - <T> : The topic, without wildcards
+//   - <P> : A Go text/template, see ScheduleTemplateData
+//   - <K> : once, interval or cron
+//   - <A> : RFC 3339, when a one-shot runs, or when an interval starts
+//   - <I> : The seconds between the runs of an interval
+//   - <C> : A cron expression of five fields, see parseCron()
+//
+// # Used in
+// - PostScheduleHandler()
+//
+// # Author
+// - Polariusz
+type ScheduleWrapper struct {
+	Name string
+	BrokerId int
+	Topic string
+	PayloadTemplate string
+	QoS int
+	Retain bool
+	Kind string
+	RunAt *time.Time
+	IntervalSeconds int
+	CronExpression string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Validator
+//
+// # Description
+// - The function shall check the schedule and compile it.
+//
+// # Returns
+// - error when the schedule is not valid, the message can be shown to the client
+//
+// # Author
+// - Polariusz
+func validateSchedule(scheduleWrapper ScheduleWrapper) (*compiledSchedule, error) {
+	if scheduleWrapper.Name == "" {
+		return nil, fmt.Errorf("Name is required")
+	}
+	if scheduleWrapper.BrokerId <= 0 {
+		return nil, fmt.Errorf("BrokerId is required")
+	}
+	if scheduleWrapper.Topic == "" || strings.ContainsAny(scheduleWrapper.Topic, "+#") {
+		return nil, fmt.Errorf("Topic is required and must not have wildcards")
+	}
+	if scheduleWrapper.QoS < 0 || scheduleWrapper.QoS > 2 {
+		return nil, fmt.Errorf("QoS must be 0, 1 or 2")
+	}
+
+	switch scheduleWrapper.Kind {
+	case database.SCHEDULE_ONCE:
+		if scheduleWrapper.RunAt == nil || !scheduleWrapper.RunAt.After(time.Now()) {
+			return nil, fmt.Errorf("RunAt is required for once and must be ahead")
+		}
+	case database.SCHEDULE_INTERVAL:
+		if scheduleWrapper.IntervalSeconds < 1 {
+			return nil, fmt.Errorf("IntervalSeconds must be at least 1 for interval")
+		}
+	case database.SCHEDULE_CRON:
+		if scheduleWrapper.CronExpression == "" {
+			return nil, fmt.Errorf("CronExpression is required for cron")
+		}
+	default:
+		return nil, fmt.Errorf("Kind must be once, interval or cron")
+	}
+
+	compiled, err := compileSchedule(database.SelectSchedule{
+		Name: scheduleWrapper.Name,
+		BrokerId: scheduleWrapper.BrokerId,
+		Topic: scheduleWrapper.Topic,
+		PayloadTemplate: scheduleWrapper.PayloadTemplate,
+		QoS: scheduleWrapper.QoS,
+		Retain: scheduleWrapper.Retain,
+		Kind: scheduleWrapper.Kind,
+		RunAt: scheduleWrapper.RunAt,
+		IntervalSeconds: scheduleWrapper.IntervalSeconds,
+		CronExpression: scheduleWrapper.CronExpression,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := compiled.render(1, time.Now()); err != nil {
+		return nil, fmt.Errorf("PayloadTemplate can not be rendered: %s", err)
+	}
+	return compiled, nil
+}
+
+// # Description
+// - The function shall return the schedule of the path parameter `id`, or write the response if there is none.
+//
+// # Returns
+// - bool: false if the response was written
+//
+// # Author
+// - Polariusz
+func scheduleOfPath(c *fiber.Ctx, serverState *ServerState) (database.SelectSchedule, bool, error) {
+	scheduleId, err := paramId(c, "id")
+	if err != nil {
+		return database.SelectSchedule{}, false, writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+	}
+	scheduleList, err := database.SelectSchedules(serverState.con)
+	if err != nil {
+		return database.SelectSchedule{}, false, writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the schedules", map[string]any{"Error": err.Error()})
+	}
+	for _, schedule := range scheduleList {
+		if schedule.Id == scheduleId {
+			return schedule, true, nil
+		}
+	}
+	return database.SelectSchedule{}, false, writeAPIError(c, fiber.StatusNotFound, API_ERROR_NOT_FOUND, "There is no schedule with this Id", nil)
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return the schedules of the selected project that the account may see, for their broker and topic.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"schedules":[<database.SelectSchedule>]}
+// - 500 (Internal Server Error): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func GetSchedulesHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		permissions, err := permissionsOf(c, serverState)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the roles", map[string]any{"Error": err.Error()})
+		}
+		scheduleList, err := database.SelectSchedules(serverState.con)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the schedules", map[string]any{"Error": err.Error()})
+		}
+
+		visibleSchedules := []database.SelectSchedule{}
+		for _, schedule := range scheduleList {
+			if permissions.allows(ROLE_VIEWER, schedule.BrokerId, schedule.Topic) {
+				visibleSchedules = append(visibleSchedules, schedule)
+			}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"schedules": visibleSchedules,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall create an enabled schedule.
+// - The method shall accept a jsonified structure that follows the struct ScheduleWrapper.
+// - The method shall need the role operator for the broker and the topic of the schedule, as for a publish.
+//
+// # Returns
+// - 201 (Created): JSON
+//   - {"Id":<SCHEDULE-ID>,"NextRunAt":"<DATE>"}
+// - 400 (Bad Request): `APIErrorEnvelope`
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 500 (Internal Server Error): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func PostScheduleHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var scheduleWrapper ScheduleWrapper
+		if err := c.BodyParser(&scheduleWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+		compiled, err := validateSchedule(scheduleWrapper)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+		if ok, err := authorize(c, serverState, ROLE_OPERATOR, scheduleWrapper.BrokerId, scheduleWrapper.Topic); !ok {
+			return err
+		}
+		nextRunAt := compiled.firstRun(time.Now())
+		if nextRunAt == nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, "The cron expression has no next time", nil)
+		}
+
+		scheduleId, err := database.InsertNewSchedule(serverState.con, database.InsertSchedule{
+			Name: scheduleWrapper.Name,
+			BrokerId: scheduleWrapper.BrokerId,
+			Topic: scheduleWrapper.Topic,
+			PayloadTemplate: scheduleWrapper.PayloadTemplate,
+			QoS: scheduleWrapper.QoS,
+			Retain: scheduleWrapper.Retain,
+			Kind: scheduleWrapper.Kind,
+			RunAt: scheduleWrapper.RunAt,
+			IntervalSeconds: scheduleWrapper.IntervalSeconds,
+			CronExpression: scheduleWrapper.CronExpression,
+			NextRunAt: nextRunAt,
+		})
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while inserting in the Schedule table", map[string]any{"Error": err.Error()})
+		}
+		if err := serverState.scheduler.reload(); err != nil {
+			fmt.Printf("WARN: Schedules were not reloaded!\nErr: %s\n", err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"Id": scheduleId,
+			"NextRunAt": nextRunAt,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return a schedule, with its next run and how often it has run.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - <database.SelectSchedule>
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func GetScheduleHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		schedule, ok, err := scheduleOfPath(c, serverState)
+		if !ok {
+			return err
+		}
+		if ok, err := authorize(c, serverState, ROLE_VIEWER, schedule.BrokerId, schedule.Topic); !ok {
+			return err
+		}
+
+		return c.Status(fiber.StatusOK).JSON(schedule)
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Audited |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall delete a schedule with its runs.
+// - The method shall need the role operator for the broker and the topic of the schedule.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"Id":<SCHEDULE-ID>}
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func DeleteScheduleHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_DELETE_SCHEDULE}
+		defer serverState.auditLog.record(c, &record)
+
+		schedule, ok, err := scheduleOfPath(c, serverState)
+		if !ok {
+			return err
+		}
+		record.about(BrokerUser{BrokerId: schedule.BrokerId}, schedule.Topic)
+		if ok, err := authorize(c, serverState, ROLE_OPERATOR, schedule.BrokerId, schedule.Topic); !ok {
+			return err
+		}
+
+		if _, err := database.DeleteSchedule(serverState.con, schedule.Id); err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while deleting the schedule", map[string]any{"Error": err.Error()})
+		}
+		if err := serverState.scheduler.reload(); err != nil {
+			fmt.Printf("WARN: Schedules were not reloaded!\nErr: %s\n", err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"Id": schedule.Id,
+		})
+	}
+}
+
+// # Description
+// - The function shall enable or disable the schedule of the path parameter `id` and write the response.
+// - The role operator is needed for the broker and the topic of the schedule.
+//
+// # Author
+// - Polariusz
+func setScheduleEnabled(c *fiber.Ctx, serverState *ServerState, enabled bool) error {
+	schedule, ok, err := scheduleOfPath(c, serverState)
+	if !ok {
+		return err
+	}
+	if ok, err := authorize(c, serverState, ROLE_OPERATOR, schedule.BrokerId, schedule.Topic); !ok {
+		return err
+	}
+
+	schedule, err = serverState.scheduler.setEnabled(schedule.Id, enabled)
+	if err != nil {
+		return writeAPIError(c, fiber.StatusConflict, API_ERROR_CONFLICT, err.Error(), nil)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(schedule)
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall enable a schedule, it stays enabled after a restart of the server.
+// - It runs next from now on, see (*compiledSchedule).firstRun(). A one-shot whose time has passed can not be enabled.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - <database.SelectSchedule>
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+// - 409 (Conflict): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func PostScheduleEnableHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return setScheduleEnabled(c, serverState, true)
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall disable a schedule, a run in progress is finished.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - <database.SelectSchedule>
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func PostScheduleDisableHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return setScheduleEnabled(c, serverState, false)
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return a page of the runs of a schedule, the newest first, optionally only those with the `status` published, failed or skipped.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"runs":[<database.SelectScheduleRun>],"nextBeforeId":<N>}
+// - 400 (Bad Request): `APIErrorEnvelope`
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func GetScheduleRunsHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		limit, beforeId, err := pageFromQuery(c)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+		schedule, ok, err := scheduleOfPath(c, serverState)
+		if !ok {
+			return err
+		}
+		if ok, err := authorize(c, serverState, ROLE_VIEWER, schedule.BrokerId, schedule.Topic); !ok {
+			return err
+		}
+
+		runList, err := database.SelectScheduleRuns(serverState.con, schedule.Id, c.Query("status"), beforeId, limit)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the runs", map[string]any{"Error": err.Error()})
+		}
+		if runList == nil {
+			runList = []database.SelectScheduleRun{}
+		}
+
+		nextBeforeId := 0
+		if len(runList) == limit {
+			nextBeforeId = runList[len(runList)-1].Id
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"runs": runList,
+			"nextBeforeId": nextBeforeId,
+		})
+	}
+}
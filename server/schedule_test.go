@@ -0,0 +1,205 @@
+package main
+
+import (
+	"database"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gofiber/fiber/v2"
+)
+
+// An MQTT-Client that is always connected and hands every publish to the test.
+type fakeClient struct {
+	mqtt.Client
+	published chan string
+}
+
+func (fc *fakeClient) IsConnected() bool { return true }
+
+func (fc *fakeClient) Publish(topic string, qos byte, retained bool, payload interface{}) mqtt.Token {
+	fc.published <- fmt.Sprintf("%s %d %t %s", topic, qos, retained, payload)
+	return &mqtt.DummyToken{}
+}
+
+func TestParseCron(t *testing.T) {
+	at := func(value string) time.Time {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", value, time.UTC)
+		if err != nil {
+			t.Fatalf("%s: %s", value, err)
+		}
+		return parsed
+	}
+
+	for _, test := range []struct {
+		expression string
+		after string
+		want string
+	}{
+		{"* * * * *", "2026-10-19 09:12", "2026-10-19 09:13"},
+		{"*/15 * * * *", "2026-10-19 09:12", "2026-10-19 09:15"},
+		{"0 9-17/4 * * *", "2026-10-19 09:00", "2026-10-19 13:00"},
+		{"30 2 * * 1-5", "2026-10-23 03:00", "2026-10-26 02:30"},
+		{"0 0 1,15 * *", "2026-10-02 00:00", "2026-10-15 00:00"},
+		{"0 0 13 * 5", "2026-10-02 00:00", "2026-10-09 00:00"},
+		{"0 12 * * 7", "2026-10-19 12:00", "2026-10-25 12:00"},
+		{"@monthly", "2026-12-19 09:00", "2027-01-01 00:00"},
+		{"0 0 29 2 *", "2026-03-01 00:00", "2028-02-29 00:00"},
+	} {
+		cron, err := parseCron(test.expression)
+		if err != nil {
+			t.Errorf("%s: %s", test.expression, err)
+			continue
+		}
+		if got, ok := cron.next(at(test.after)); !ok || !got.Equal(at(test.want)) {
+			t.Errorf("%s after %s = %s, want %s", test.expression, test.after, got, test.want)
+		}
+	}
+
+	for _, expression := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "* * * 13 *"} {
+		if _, err := parseCron(expression); err == nil {
+			t.Errorf("%q was accepted", expression)
+		}
+	}
+
+	cron, _ := parseCron("0 0 30 2 *")
+	if _, ok := cron.next(at("2026-10-19 09:00")); ok {
+		t.Errorf("the 30th of February has a next time")
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	con, err := database.OpenDatabase(database.InMemoryDatabase)
+	if err != nil {
+		t.Fatalf("OpenDatabase: %s", err)
+	}
+	defer con.Close()
+	if err := database.SetupDatabase(con); err != nil {
+		t.Fatalf("SetupDatabase: %s", err)
+	}
+
+	accounts, err := NewAccountManager(con)
+	if err != nil {
+		t.Fatalf("NewAccountManager: %s", err)
+	}
+	client := &fakeClient{published: make(chan string, 100)}
+	// The MQTT-Client is connected to the broker 1 only.
+	newScheduler := func() *Scheduler {
+		scheduler := NewScheduler(con, nil)
+		scheduler.client = func(brokerId int) mqtt.Client {
+			if brokerId != 1 {
+				return nil
+			}
+			return client
+		}
+		scheduler.start()
+		return scheduler
+	}
+	serverState := &ServerState{con: con, accounts: accounts, scheduler: newScheduler()}
+	server := fiber.New()
+	addRoutes(server, serverState)
+
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	for name, schedule := range map[string]string{
+		"no kind": `{"Name":"x","BrokerId":1,"Topic":"a"}`,
+		"wildcard": `{"Name":"x","BrokerId":1,"Topic":"a/#","Kind":"interval","IntervalSeconds":1}`,
+		"once in the past": `{"Name":"x","BrokerId":1,"Topic":"a","Kind":"once","RunAt":"2020-01-01T00:00:00Z"}`,
+		"no interval": `{"Name":"x","BrokerId":1,"Topic":"a","Kind":"interval"}`,
+		"bad cron": `{"Name":"x","BrokerId":1,"Topic":"a","Kind":"cron","CronExpression":"* * *"}`,
+		"never": `{"Name":"x","BrokerId":1,"Topic":"a","Kind":"cron","CronExpression":"0 0 31 4 *"}`,
+		"bad template": `{"Name":"x","BrokerId":1,"Topic":"a","Kind":"interval","IntervalSeconds":1,"PayloadTemplate":"{{.Nope}}"}`,
+		"bad qos": `{"Name":"x","BrokerId":1,"Topic":"a","Kind":"once","RunAt":"` + future + `","QoS":3}`,
+	} {
+		if status, body := request(t, server, "POST", "/api/v1/schedules", schedule, nil); status != fiber.StatusBadRequest {
+			t.Errorf("%s: %d %v", name, status, body)
+		}
+	}
+
+	create := func(schedule string) int {
+		t.Helper()
+		status, body := request(t, server, "POST", "/api/v1/schedules", schedule, nil)
+		if status != fiber.StatusCreated {
+			t.Fatalf("create %s: %d %v", schedule, status, body)
+		}
+		return int(body["Id"].(float64))
+	}
+	soon := time.Now().Add(200 * time.Millisecond).Format(time.RFC3339Nano)
+	onceId := create(`{"Name":"boot","BrokerId":1,"Topic":"devices/1/boot","Kind":"once","RunAt":"` + soon + `","QoS":1,"Retain":true,"PayloadTemplate":"{{.Name}} {{.Run}}"}`)
+	heartbeatId := create(`{"Name":"heartbeat","BrokerId":1,"Topic":"devices/1/heartbeat","Kind":"interval","IntervalSeconds":1,"PayloadTemplate":"{\"seq\":{{.Run}},\"temp\":{{printf \"%.1f\" (randFloat 20 25)}}}"}`)
+	elsewhereId := create(`{"Name":"elsewhere","BrokerId":2,"Topic":"devices/2/heartbeat","Kind":"interval","IntervalSeconds":1}`)
+	cronId := create(`{"Name":"nightly","BrokerId":1,"Topic":"devices/1/report","Kind":"cron","CronExpression":"0 3 * * *"}`)
+
+	next := func() string {
+		t.Helper()
+		select {
+		case published := <-client.published:
+			return published
+		case <-time.After(5 * time.Second):
+			t.Fatalf("nothing was published")
+		}
+		return ""
+	}
+	if got := next(); got != "devices/1/boot 1 true boot 1" {
+		t.Errorf("the one-shot: %s", got)
+	}
+	if got := next(); !strings.HasPrefix(got, `devices/1/heartbeat 0 false {"seq":1,"temp":2`) {
+		t.Errorf("the first heartbeat: %s", got)
+	}
+	if got := next(); !strings.HasPrefix(got, `devices/1/heartbeat 0 false {"seq":2,"temp":2`) {
+		t.Errorf("the second heartbeat: %s", got)
+	}
+
+	schedule := func(scheduleId int) map[string]any {
+		t.Helper()
+		status, body := request(t, server, "GET", fmt.Sprintf("/api/v1/schedules/%d", scheduleId), "", nil)
+		if status != fiber.StatusOK {
+			t.Fatalf("the schedule %d: %d %v", scheduleId, status, body)
+		}
+		return body
+	}
+	if once := schedule(onceId); once["Enabled"] != false || once["NextRunAt"] != nil || once["RunCount"] != float64(1) {
+		t.Errorf("the one-shot after its run: %v", once)
+	}
+	if status, _ := request(t, server, "POST", fmt.Sprintf("/api/v1/schedules/%d/enable", onceId), "", nil); status != fiber.StatusConflict {
+		t.Errorf("enable a one-shot that has run: %d", status)
+	}
+	if status, body := request(t, server, "GET", fmt.Sprintf("/api/v1/schedules/%d/runs?status=skipped", elsewhereId), "", nil); status != fiber.StatusOK || len(body["runs"].([]any)) == 0 {
+		t.Errorf("the runs without a connection to the broker: %d %v", status, body)
+	}
+	if nightly := schedule(cronId); nightly["RunCount"] != float64(0) || !strings.Contains(nightly["NextRunAt"].(string), "T03:00:00") {
+		t.Errorf("the cron schedule: %v", nightly)
+	}
+
+	// A disabled schedule stays disabled after a restart.
+	status, body := request(t, server, "POST", fmt.Sprintf("/api/v1/schedules/%d/disable", heartbeatId), "", nil)
+	if status != fiber.StatusOK || body["Enabled"] != false {
+		t.Fatalf("disable the heartbeat: %d %v", status, body)
+	}
+	request(t, server, "DELETE", fmt.Sprintf("/api/v1/schedules/%d", elsewhereId), "", nil)
+	serverState.scheduler.close()
+	for len(client.published) > 0 {
+		<-client.published
+	}
+	serverState.scheduler = newScheduler()
+	defer serverState.scheduler.close()
+
+	status, body = request(t, server, "GET", fmt.Sprintf("/api/v1/schedules/%d/runs?limit=1", heartbeatId), "", nil)
+	runs, _ := body["runs"].([]any)
+	if status != fiber.StatusOK || len(runs) != 1 || runs[0].(map[string]any)["Status"] != database.SCHEDULE_RUN_PUBLISHED || body["nextBeforeId"] == float64(0) {
+		t.Errorf("the runs of the heartbeat: %d %v", status, body)
+	}
+	runCount := schedule(heartbeatId)["RunCount"].(float64)
+
+	if status, body := request(t, server, "POST", fmt.Sprintf("/api/v1/schedules/%d/enable", heartbeatId), "", nil); status != fiber.StatusOK || body["Enabled"] != true {
+		t.Fatalf("enable the heartbeat: %d %v", status, body)
+	}
+	if got := next(); !strings.HasPrefix(got, fmt.Sprintf(`devices/1/heartbeat 0 false {"seq":%d,`, int(runCount)+1)) {
+		t.Errorf("the heartbeat after the restart: %s", got)
+	}
+	status, body = request(t, server, "GET", "/api/v1/schedules", "", nil)
+	if scheduleList, _ := body["schedules"].([]any); status != fiber.StatusOK || len(scheduleList) != 3 {
+		t.Errorf("the schedules: %d %v", status, body)
+	}
+}
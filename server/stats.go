@@ -0,0 +1,356 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The sliding windows are made of buckets of `STATS_BUCKET` length.
+// - `STATS_BUCKETS` buckets are kept, so the longest window is 15 minutes long.
+//
+// # Author
+// - Polariusz
+const STATS_BUCKET = 10 * time.Second
+const STATS_BUCKETS = 90
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - Windows that are reported in `MessagesPerMinute`. Each must be a multiple of `STATS_BUCKET` and not longer than `STATS_BUCKET*STATS_BUCKETS`.
+//
+// # Author
+// - Polariusz
+var statsWindows = []time.Duration{1 * time.Minute, 5 * time.Minute, 15 * time.Minute}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The structure counts the messages and bytes of one topic or one publisher.
+// - `buckets` is a ring of message counts, `bucketStart` holds the start time of each bucket so that stale buckets can be recognised.
+//
+// # Author
+// - Polariusz
+type statsCounter struct {
+	messages int64
+	bytes int64
+	firstSeen time.Time
+	lastSeen time.Time
+	buckets [STATS_BUCKETS]int64
+	bucketStart [STATS_BUCKETS]int64
+}
+
+// # Author
+// - Polariusz
+func (sc *statsCounter) record(size int, now time.Time) {
+	if sc.messages == 0 {
+		sc.firstSeen = now
+	}
+	sc.messages++
+	sc.bytes += int64(size)
+	sc.lastSeen = now
+
+	bucket := now.UnixNano() / int64(STATS_BUCKET)
+	index := bucket % STATS_BUCKETS
+	if sc.bucketStart[index] != bucket {
+		sc.bucketStart[index] = bucket
+		sc.buckets[index] = 0
+	}
+	sc.buckets[index]++
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall return the average amount of messages per minute over each of the `statsWindows`.
+// - The returned map is keyed by the window, for example "1m0s".
+//
+// # Author
+// - Polariusz
+func (sc *statsCounter) ratePerMinute(now time.Time) map[string]float64 {
+	current := now.UnixNano() / int64(STATS_BUCKET)
+	rates := make(map[string]float64)
+
+	for _, window := range statsWindows {
+		oldest := current - int64(window/STATS_BUCKET) + 1
+		var count int64
+		for i := 0; i < STATS_BUCKETS; i++ {
+			if sc.bucketStart[i] >= oldest && sc.bucketStart[i] <= current {
+				count += sc.buckets[i]
+			}
+		}
+		rates[window.String()] = float64(count) / window.Minutes()
+	}
+
+	return rates
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # JSON-Structure:
+// - {"BrokerId":<B>,"Topic":"<T>","Messages":<M>,"Bytes":<BY>,"MessagesPerMinute":{"1m0s":<R>,"5m0s":<R>,"15m0s":<R>},"FirstSeen":"<D>","LastSeen":"<D>"}
+//
+// # Used in
+// - GetStatsHandler()
+//
+// # Author
+// - Polariusz
+type TopicStats struct {
+	BrokerId int
+	Topic string
+	Messages int64
+	Bytes int64
+	MessagesPerMinute map[string]float64
+	FirstSeen time.Time
+	LastSeen time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # JSON-Structure:
+// - {"BrokerId":<B>,"UserId":<U>,"ClientId":"<C>","Outsider":<O>,"Messages":<M>,"Bytes":<BY>,"MessagesPerMinute":{...},"FirstSeen":"<D>","LastSeen":"<D>"}
+//
+// # Used in
+// - GetStatsHandler()
+//
+// # Author
+// - Polariusz
+type ClientStats struct {
+	BrokerId int
+	UserId int
+	ClientId string
+	Outsider bool
+	Messages int64
+	Bytes int64
+	MessagesPerMinute map[string]float64
+	FirstSeen time.Time
+	LastSeen time.Time
+}
+
+// # Author
+// - Polariusz
+type topicStatsKey struct {
+	brokerId int
+	topic string
+}
+
+// # Author
+// - Polariusz
+type clientStatsEntry struct {
+	brokerId int
+	clientId string
+	outsider bool
+	counter statsCounter
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The structure holds message statistics per topic and per publisher (User row).
+// - The statistics are kept in memory and updated by the MQTT message handler, so the Message table never needs to be scanned.
+// - They start counting when the server starts.
+//
+// # Used in
+// - struct ServerState
+//
+// # Author
+// - Polariusz
+type MessageStats struct {
+	mutex sync.Mutex
+	topics map[topicStatsKey]*statsCounter
+	clients map[int]*clientStatsEntry
+}
+
+// # Author
+// - Polariusz
+func NewMessageStats() *MessageStats {
+	return &MessageStats{
+		topics: make(map[topicStatsKey]*statsCounter),
+		clients: make(map[int]*clientStatsEntry),
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall count one received message of `size` bytes for the topic and for the publisher.
+//
+// # Used in
+// - createMessageHandler()
+//
+// # Author
+// - Polariusz
+func (ms *MessageStats) record(brokerId int, topic string, userId int, clientId string, outsider bool, size int, now time.Time) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	key := topicStatsKey{brokerId, topic}
+	topicCounter, ok := ms.topics[key]
+	if !ok {
+		topicCounter = &statsCounter{}
+		ms.topics[key] = topicCounter
+	}
+	topicCounter.record(size, now)
+
+	client, ok := ms.clients[userId]
+	if !ok {
+		client = &clientStatsEntry{brokerId: brokerId, clientId: clientId, outsider: outsider}
+		ms.clients[userId] = client
+	}
+	client.counter.record(size, now)
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall return the statistics of all topics and publishers of the broker `brokerId`.
+// - Both lists are sorted from the most to the least messages.
+//
+// # Author
+// - Polariusz
+func (ms *MessageStats) snapshot(brokerId int, now time.Time) ([]TopicStats, []ClientStats) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	topicList := []TopicStats{}
+	for key, counter := range ms.topics {
+		if key.brokerId != brokerId {
+			continue
+		}
+		topicList = append(topicList, TopicStats{
+			BrokerId: key.brokerId,
+			Topic: key.topic,
+			Messages: counter.messages,
+			Bytes: counter.bytes,
+			MessagesPerMinute: counter.ratePerMinute(now),
+			FirstSeen: counter.firstSeen,
+			LastSeen: counter.lastSeen,
+		})
+	}
+
+	clientList := []ClientStats{}
+	for userId, client := range ms.clients {
+		if client.brokerId != brokerId {
+			continue
+		}
+		clientList = append(clientList, ClientStats{
+			BrokerId: client.brokerId,
+			UserId: userId,
+			ClientId: client.clientId,
+			Outsider: client.outsider,
+			Messages: client.counter.messages,
+			Bytes: client.counter.bytes,
+			MessagesPerMinute: client.counter.ratePerMinute(now),
+			FirstSeen: client.counter.firstSeen,
+			LastSeen: client.counter.lastSeen,
+		})
+	}
+
+	sort.Slice(topicList, func(i, j int) bool {
+		if topicList[i].Messages != topicList[j].Messages {
+			return topicList[i].Messages > topicList[j].Messages
+		}
+		return topicList[i].Topic < topicList[j].Topic
+	})
+	sort.Slice(clientList, func(i, j int) bool {
+		if clientList[i].Messages != clientList[j].Messages {
+			return clientList[i].Messages > clientList[j].Messages
+		}
+		return clientList[i].UserId < clientList[j].UserId
+	})
+
+	return topicList, clientList
+}
+
+// | Date of change | By        | Comment                   |
+// +----------------+-----------+---------------------------+
+// | 2026-10-19     | Polariusz | Created                   |
+// | 2026-10-19     | Polariusz | Identity from the session |
+// | 2026-10-19     | Polariusz | Roles                     |
+// | 2026-10-19     | Polariusz | Query parameters          |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return message counts, bytes, messages per minute over sliding windows and first/last seen timestamps per topic and per publisher.
+// - The statistics are counted since the server started.
+// - The method shall take the broker and user from the session when the request has one.
+// - The method shall leave out the topics that the role viewer is not given for.
+//
+// # Usage
+// - Call declared by the routing method addRoutes() URL with the GET-Method.
+// - The client shall give the broker and user as the query parameters `BrokerId` and `UserId`.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"topics":[<TopicStats-N>],"clients":[<ClientStats-N>]}
+// - 400 (Bad Request): JSON
+//   - {"terribleJson":"Arguments are not valid"}
+// - 403 (Forbidden): JSON
+//   - {"Forbidden":"The role <ROLE> is needed <WHERE> <WHAT>"}
+//
+// # Author
+// - Polariusz
+func GetStatsHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		brokerUser := BrokerUser{
+			BrokerId: c.QueryInt("BrokerId"),
+			UserId: c.QueryInt("UserId"),
+		}
+
+		resolved, ok, err := resolveBrokerUser(c, serverState, brokerUser, false)
+		if !ok {
+			return err
+		}
+		brokerUser = resolved
+
+		if brokerUser.BrokerId <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"terribleJson": "Arguments are not valid",
+			})
+		}
+
+		permissions, ok, err := authorizeAny(c, serverState, ROLE_VIEWER, brokerUser.BrokerId)
+		if !ok {
+			return err
+		}
+
+		topicList, clientList := serverState.stats.snapshot(brokerUser.BrokerId, time.Now())
+
+		visibleTopicList := topicList[:0]
+		for _, topic := range topicList {
+			if permissions.allows(ROLE_VIEWER, brokerUser.BrokerId, topic.Topic) {
+				visibleTopicList = append(visibleTopicList, topic)
+			}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"topics": visibleTopicList,
+			"clients": clientList,
+		})
+	}
+}
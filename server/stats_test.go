@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestStatsFromTheQuery(t *testing.T) {
+	serverState, server := newTestServerState(t, NewProjectManager(DatabaseConfig{InMemory: true}))
+	serverState.stats.record(1, "plant/temperature", 7, "sensor", true, 2, time.Now())
+	serverState.stats.record(2, "plant/pressure", 8, "sensor", true, 2, time.Now())
+
+	for _, path := range []string{"/stats?BrokerId=1", "/stats?BrokerId=1&UserId=3", "/api/v1/brokers/1/stats?BrokerId=2"} {
+		status, body := request(t, server, "GET", path, "", nil)
+		topics, _ := body["topics"].([]any)
+		if status != fiber.StatusOK || len(topics) != 1 || topics[0].(map[string]any)["Topic"] != "plant/temperature" {
+			t.Errorf("%s: %d %v", path, status, body)
+		}
+	}
+	for _, path := range []string{"/stats", "/stats?BrokerId=sensors"} {
+		if status, body := request(t, server, "GET", path, "", nil); status != fiber.StatusBadRequest {
+			t.Errorf("%s: %d %v", path, status, body)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+)
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Validator
+//
+// # Description
+// - The method shall check if the argument `filter` is a valid MQTT topic filter.
+// - The `+` wildcard must occupy a whole level, the `#` wildcard must occupy the last level.
+//
+// # Returns
+// - true if the filter is valid
+//
+// # Author
+// - Polariusz
+func validTopicFilter(filter string) bool {
+	if filter == "" {
+		return false
+	}
+
+	levels := strings.Split(filter, "/")
+	for i, level := range levels {
+		if strings.Contains(level, "#") && (level != "#" || i != len(levels)-1) {
+			return false
+		}
+		if strings.Contains(level, "+") && level != "+" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Matcher
+//
+// # Description
+// - The method shall check if the argument `topic` matches the MQTT topic filter `filter`.
+// - `+` matches exactly one level, `#` matches the parent level and any number of levels below it.
+// - Topics starting with `$` are not matched by filters starting with a wildcard, as the MQTT specification demands.
+//
+// # Returns
+// - true if the topic matches the filter
+//
+// # Author
+// - Polariusz
+func topicMatchesFilter(filter string, topic string) bool {
+	if strings.HasPrefix(topic, "$") && (strings.HasPrefix(filter, "+") || strings.HasPrefix(filter, "#")) {
+		return false
+	}
+
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+
+	for i, filterLevel := range filterLevels {
+		if filterLevel == "#" {
+			return true
+		}
+		if i >= len(topicLevels) {
+			return false
+		}
+		if filterLevel != "+" && filterLevel != topicLevels[i] {
+			return false
+		}
+	}
+
+	return len(filterLevels) == len(topicLevels)
+}
@@ -0,0 +1,1224 @@
+package main
+
+import (
+	"bytes"
+	"database"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// How many deliveries are sent at the same time.
+const WEBHOOK_WORKERS = 4
+
+// How many deliveries may wait for a worker, more wait in the database for retryDue() so the MQTT-Client is never blocked.
+const WEBHOOK_QUEUE_CAPACITY = 1000
+
+// How long one attempt may take.
+const WEBHOOK_TIMEOUT = 10 * time.Second
+
+// How long a delivery that is being sent is not picked up again, so a crash only delays it.
+const WEBHOOK_LEASE = 5 * time.Minute
+
+// How often the deliveries that wait for a retry are looked for.
+const WEBHOOK_RETRY_INTERVAL = time.Second
+
+// The wait after the first failed attempt, it doubles with every further attempt up to WEBHOOK_BACKOFF_MAX.
+const WEBHOOK_BACKOFF_BASE = 2 * time.Second
+const WEBHOOK_BACKOFF_MAX = 10 * time.Minute
+
+// The attempts of a webhook without MaxAttempts, and the most it may have.
+const WEBHOOK_DEFAULT_ATTEMPTS = 5
+const WEBHOOK_MAX_ATTEMPTS = 20
+
+// The most bytes of a failed response that are kept in LastError.
+const WEBHOOK_ERROR_LIMIT = 512
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The operators of the payload condition of a webhook, see evaluateCondition().
+// - `gt`, `ge`, `lt` and `le` compare numbers, `eq` and `ne` compare numbers if both sides are one and text otherwise.
+// - `matches` is a regular expression, `exists` ignores the value.
+const (
+	CONDITION_EQ = "eq"
+	CONDITION_NE = "ne"
+	CONDITION_GT = "gt"
+	CONDITION_GE = "ge"
+	CONDITION_LT = "lt"
+	CONDITION_LE = "le"
+	CONDITION_CONTAINS = "contains"
+	CONDITION_MATCHES = "matches"
+	CONDITION_EXISTS = "exists"
+)
+
+// The body of a webhook without BodyTemplate.
+const WEBHOOK_DEFAULT_TEMPLATE = `{"brokerId":{{.BrokerId}},"topic":{{json .Topic}},"clientId":{{json .ClientId}},"message":{{json .Message}},"qos":{{.QoS}},"retained":{{.Retained}},"receivedAt":{{json .ReceivedAt}}}`
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A received message as the webhooks and the alert rules see it.
+// - The body template of a webhook sees its fields, like `{{.Topic}}`. It also has the function `json`, which writes a value as JSON, like `{"text":{{json .Message}}}`.
+//
+// # Used in
+// - createMessageHandler()
+// - (*WebhookDispatcher).dispatch()
+// - (*AlertEngine).observe()
+//
+// # Author
+// - Polariusz
+type ReceivedMessage struct {
+	BrokerId int
+	Topic string
+	ClientId string
+	Message string
+	QoS byte
+	Retained bool
+	ReceivedAt time.Time
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A webhook with its parsed template and regular expression, so a message is matched and rendered without parsing them again.
+//
+// # Author
+// - Polariusz
+type compiledWebhook struct {
+	webhook database.SelectWebhook
+	body *template.Template
+	pattern *regexp.Regexp
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The function shall parse the body template and the regular expression of the condition of the webhook.
+//
+// # Returns
+// - error when one of them is not valid, the message can be shown to the client
+//
+// # Author
+// - Polariusz
+func compileWebhook(webhook database.SelectWebhook) (*compiledWebhook, error) {
+	compiled := &compiledWebhook{webhook: webhook}
+
+	source := webhook.BodyTemplate
+	if source == "" {
+		source = WEBHOOK_DEFAULT_TEMPLATE
+	}
+	body, err := template.New("body").Funcs(template.FuncMap{
+		"json": func(value any) (string, error) {
+			encoded, err := json.Marshal(value)
+			return string(encoded), err
+		},
+	}).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("BodyTemplate is not valid: %s", err)
+	}
+	compiled.body = body
+
+	if webhook.ConditionOperator == CONDITION_MATCHES {
+		if compiled.pattern, err = regexp.Compile(webhook.ConditionValue); err != nil {
+			return nil, fmt.Errorf("ConditionValue is not a valid regular expression: %s", err)
+		}
+	}
+
+	return compiled, nil
+}
+
+// # Description
+// - The method shall tell whether the webhook is for the message, by its broker, its topic and its condition.
+//
+// # Author
+// - Polariusz
+func (cw *compiledWebhook) matches(message ReceivedMessage) bool {
+	if cw.webhook.BrokerId != 0 && cw.webhook.BrokerId != message.BrokerId {
+		return false
+	}
+	if !topicMatchesFilter(cw.webhook.TopicFilter, message.Topic) {
+		return false
+	}
+	return evaluateCondition(cw.webhook.ConditionPath, cw.webhook.ConditionOperator, cw.webhook.ConditionValue, cw.pattern, message.Message)
+}
+
+// # Description
+// - The method shall write the body of the request for the message.
+//
+// # Author
+// - Polariusz
+func (cw *compiledWebhook) render(message ReceivedMessage) (string, error) {
+	var body bytes.Buffer
+	if err := cw.body.Execute(&body, message); err != nil {
+		return "", err
+	}
+	return body.String(), nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - path string    : Dotted path into the message as JSON, like `sensor.values.0`. Empty for the whole message.
+// - message string : The message as it is stored.
+//
+// # Description
+// - The function shall return the value at the path of the message as text, numbers without a trailing zero.
+//
+// # Returns
+// - bool: false if the path does not exist, or if the message is no JSON while a path is given
+//
+// # Author
+// - Polariusz
+func conditionSubject(path string, message string) (string, bool) {
+	if path == "" {
+		return message, true
+	}
+
+	var decoded any
+	if err := json.Unmarshal([]byte(message), &decoded); err != nil {
+		return "", false
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := decoded.(type) {
+		case map[string]any:
+			child, ok := node[key]
+			if !ok {
+				return "", false
+			}
+			decoded = child
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return "", false
+			}
+			decoded = node[index]
+		default:
+			return "", false
+		}
+	}
+
+	switch leaf := decoded.(type) {
+	case string:
+		return leaf, true
+	case float64:
+		return strconv.FormatFloat(leaf, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(leaf), true
+	}
+	encoded, _ := json.Marshal(decoded)
+	return string(encoded), true
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - subject string          : The value of the message, see conditionSubject().
+// - operator string         : One of the CONDITION_* constants.
+// - value string            : What the operator compares with.
+// - pattern *regexp.Regexp  : The compiled `value` for CONDITION_MATCHES.
+//
+// # Description
+// - The function shall compare the subject with the value.
+//
+// # Author
+// - Polariusz
+func compareCondition(subject string, operator string, value string, pattern *regexp.Regexp) bool {
+	subjectNumber, subjectErr := strconv.ParseFloat(subject, 64)
+	valueNumber, valueErr := strconv.ParseFloat(value, 64)
+	numbers := subjectErr == nil && valueErr == nil
+
+	switch operator {
+	case CONDITION_EXISTS:
+		return true
+	case CONDITION_EQ:
+		return (numbers && subjectNumber == valueNumber) || (!numbers && subject == value)
+	case CONDITION_NE:
+		return (numbers && subjectNumber != valueNumber) || (!numbers && subject != value)
+	case CONDITION_GT:
+		return numbers && subjectNumber > valueNumber
+	case CONDITION_GE:
+		return numbers && subjectNumber >= valueNumber
+	case CONDITION_LT:
+		return numbers && subjectNumber < valueNumber
+	case CONDITION_LE:
+		return numbers && subjectNumber <= valueNumber
+	case CONDITION_CONTAINS:
+		return strings.Contains(subject, value)
+	case CONDITION_MATCHES:
+		return pattern != nil && pattern.MatchString(subject)
+	}
+	return false
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Arguments
+// - path string             : Dotted path into the message as JSON, see conditionSubject().
+// - operator string         : One of the CONDITION_* constants, empty for no condition.
+// - value string            : What the operator compares with.
+// - pattern *regexp.Regexp  : The compiled `value` for CONDITION_MATCHES.
+// - message string          : The message as it is stored.
+//
+// # Description
+// - The function shall check the condition against the message. A path that does not exist only fulfils no condition.
+//
+// # Author
+// - Polariusz
+func evaluateCondition(path string, operator string, value string, pattern *regexp.Regexp, message string) bool {
+	if operator == "" {
+		return true
+	}
+	subject, ok := conditionSubject(path, message)
+	return ok && compareCondition(subject, operator, value, pattern)
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - A delivery that waits for a worker. It is in table WebhookDelivery already, see enqueue().
+// - `renderError` is set if the body could not be written, the delivery is then dead right away.
+//
+// # Author
+// - Polariusz
+type webhookJob struct {
+	hook *compiledWebhook
+	delivery database.SelectWebhookDelivery
+	renderError string
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - It forwards the received messages to the webhooks of table Webhook that match them, and records each delivery in table WebhookDelivery.
+// - dispatch() is called by the MQTT-Client for every message. It only matches and queues, the workers send the requests.
+// - A failed delivery is retried with exponential backoff, see backoff(). After MaxAttempts of its webhook, it is dead and waits on the dead-letter list.
+// - The deliveries are in the database, so the retries go on after a restart. Like the retention janitor, it belongs to one database and is replaced with it.
+//
+// # Used in
+// - ServerState
+//
+// # Author
+// - Polariusz
+type WebhookDispatcher struct {
+	con *sql.DB
+	client *http.Client
+	mutex sync.Mutex
+	hooks []*compiledWebhook
+	jobs chan webhookJob
+	overflowed atomic.Int64
+	backoffBase time.Duration
+	retryInterval time.Duration
+	done chan struct{}
+	stopped sync.WaitGroup
+}
+
+// # Author
+// - Polariusz
+func NewWebhookDispatcher(con *sql.DB) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		con: con,
+		client: &http.Client{Timeout: WEBHOOK_TIMEOUT},
+		jobs: make(chan webhookJob, WEBHOOK_QUEUE_CAPACITY),
+		backoffBase: WEBHOOK_BACKOFF_BASE,
+		retryInterval: WEBHOOK_RETRY_INTERVAL,
+		done: make(chan struct{}),
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall load the webhooks and start the workers and the retries.
+//
+// # Author
+// - Polariusz
+func (wd *WebhookDispatcher) start() {
+	if err := wd.reload(); err != nil {
+		fmt.Printf("WARN: Running without webhooks\nErr:%s\n", err)
+	}
+
+	for i := 0; i < WEBHOOK_WORKERS; i++ {
+		wd.stopped.Add(1)
+		go func() {
+			defer wd.stopped.Done()
+			for {
+				select {
+				case <-wd.done:
+					return
+				case job := <-wd.jobs:
+					wd.deliver(job)
+				}
+			}
+		}()
+	}
+
+	wd.stopped.Add(1)
+	go func() {
+		defer wd.stopped.Done()
+
+		ticker := time.NewTicker(wd.retryInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-wd.done:
+				return
+			case <-ticker.C:
+				wd.retryDue()
+			}
+		}
+	}()
+}
+
+// # Description
+// - The method shall stop the workers and the retries. The deliveries that still wait are picked up by the next dispatcher of the database.
+//
+// # Author
+// - Polariusz
+func (wd *WebhookDispatcher) close() {
+	close(wd.done)
+	wd.stopped.Wait()
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall read the webhooks from the database. It is called when they change.
+//
+// # Returns
+// - error when the webhooks cannot be selected, the old ones stay in use
+//
+// # Author
+// - Polariusz
+func (wd *WebhookDispatcher) reload() error {
+	webhookList, err := database.SelectWebhooks(wd.con)
+	if err != nil {
+		return err
+	}
+
+	var hooks []*compiledWebhook
+	for _, webhook := range webhookList {
+		compiled, err := compileWebhook(webhook)
+		if err != nil {
+			fmt.Printf("WARN: The webhook %d is skipped\nErr: %s\n", webhook.Id, err)
+			continue
+		}
+		hooks = append(hooks, compiled)
+	}
+
+	wd.mutex.Lock()
+	defer wd.mutex.Unlock()
+
+	wd.hooks = hooks
+	return nil
+}
+
+// # Description
+// - The method shall return the loaded webhook with the ID, nil if there is none.
+//
+// # Author
+// - Polariusz
+func (wd *WebhookDispatcher) hook(webhookId int) *compiledWebhook {
+	wd.mutex.Lock()
+	defer wd.mutex.Unlock()
+
+	for _, hook := range wd.hooks {
+		if hook.webhook.Id == webhookId {
+			return hook
+		}
+	}
+	return nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Description
+// - The method shall queue a delivery for every webhook that matches the message. It never waits, see enqueue().
+//
+// # Author
+// - Polariusz
+func (wd *WebhookDispatcher) dispatch(message ReceivedMessage) {
+	if wd == nil {
+		return
+	}
+	wd.mutex.Lock()
+	hooks := wd.hooks
+	wd.mutex.Unlock()
+
+	for _, hook := range hooks {
+		if !hook.matches(message) {
+			continue
+		}
+
+		job := webhookJob{
+			hook: hook,
+			delivery: database.SelectWebhookDelivery{WebhookId: hook.webhook.Id, BrokerId: message.BrokerId, Topic: message.Topic},
+		}
+		body, err := hook.render(message)
+		if err != nil {
+			job.renderError = err.Error()
+		}
+		job.delivery.RequestBody = body
+		wd.enqueue(job)
+	}
+}
+
+// # Description
+// - The method shall queue a delivery of `body` to the webhook with the ID, without its topic filter, condition and template, like an alert event. An unknown webhook is ignored.
+//
+// # Author
+// - Polariusz
+func (wd *WebhookDispatcher) notify(webhookId int, brokerId int, topic string, body string) {
+	if wd == nil {
+		return
+	}
+	hook := wd.hook(webhookId)
+	if hook == nil {
+		return
+	}
+
+	wd.enqueue(webhookJob{
+		hook: hook,
+		delivery: database.SelectWebhookDelivery{WebhookId: webhookId, BrokerId: brokerId, Topic: topic, RequestBody: body},
+	})
+}
+
+// | Date of change | By        | Comment                |
+// +----------------+-----------+------------------------+
+// | 2026-10-19     | Polariusz | Created                |
+// | 2026-10-19     | Polariusz | Inserts before queuing |
+//
+// # Description
+// - The method shall insert the delivery as pending and then queue it without waiting.
+// - The delivery is leased while it is queued, so retryDue() does not pick it up twice.
+// - If the queue is full, the lease is taken back and the delivery waits in the database until retryDue() has room for it, nothing is dropped.
+//
+// # Author
+// - Polariusz
+func (wd *WebhookDispatcher) enqueue(job webhookJob) {
+	delivery := &job.delivery
+	now := time.Now()
+	deliveryId, err := database.InsertNewWebhookDelivery(wd.con, database.InsertWebhookDelivery{
+		WebhookId: delivery.WebhookId,
+		BrokerId: delivery.BrokerId,
+		Topic: delivery.Topic,
+		RequestBody: delivery.RequestBody,
+		NextAttemptDate: now.Add(WEBHOOK_LEASE),
+	})
+	if err != nil {
+		fmt.Printf("ERROR: A delivery of the webhook %d was lost!\nErr: %s\n", delivery.WebhookId, err)
+		return
+	}
+	delivery.Id = deliveryId
+	delivery.Status = database.WEBHOOK_DELIVERY_PENDING
+	delivery.LastAttemptDate = now
+
+	select {
+	case wd.jobs <- job:
+		return
+	default:
+	}
+
+	// A body that could not be written has nothing to retry.
+	if job.renderError != "" {
+		delivery.Status = database.WEBHOOK_DELIVERY_DEAD
+		delivery.LastError = job.renderError
+	} else {
+		delivery.NextAttemptDate = now
+	}
+	if err := database.UpdateWebhookDelivery(wd.con, *delivery); err != nil {
+		fmt.Printf("ERROR: The delivery %d of the webhook %d was not updated!\nErr: %s\n", delivery.Id, delivery.WebhookId, err)
+	}
+	if wd.overflowed.Add(1) % 100 == 1 {
+		fmt.Printf("WARN: The webhook queue is full, %d deliveries waited for the retries\n", wd.overflowed.Load())
+	}
+}
+
+// # Description
+// - The method shall return the wait before the next attempt, after `attempts` failed ones.
+//
+// # Author
+// - Polariusz
+func (wd *WebhookDispatcher) backoff(attempts int) time.Duration {
+	wait := wd.backoffBase
+	for i := 1; i < attempts && wait < WEBHOOK_BACKOFF_MAX; i++ {
+		wait *= 2
+	}
+	if wait > WEBHOOK_BACKOFF_MAX {
+		wait = WEBHOOK_BACKOFF_MAX
+	}
+	return wait
+}
+
+// | Date of change | By        | Comment               |
+// +----------------+-----------+-----------------------+
+// | 2026-10-19     | Polariusz | Created               |
+// | 2026-10-19     | Polariusz | Inserted by enqueue() |
+//
+// # Description
+// - The method shall send the delivery once and write how it went.
+// - A 2xx response delivers it. Otherwise it is retried after backoff(), or is dead after MaxAttempts of its webhook.
+//
+// # Author
+// - Polariusz
+func (wd *WebhookDispatcher) deliver(job webhookJob) {
+	delivery := job.delivery
+
+	delivery.LastAttemptDate = time.Now()
+	if job.renderError != "" {
+		delivery.Status = database.WEBHOOK_DELIVERY_DEAD
+		delivery.LastError = job.renderError
+	} else {
+		delivery.Attempts++
+		delivery.LastStatusCode, delivery.LastError = wd.send(job.hook.webhook, delivery)
+
+		switch {
+		case delivery.LastError == "":
+			delivery.Status = database.WEBHOOK_DELIVERY_DELIVERED
+		case delivery.Attempts >= job.hook.webhook.MaxAttempts:
+			delivery.Status = database.WEBHOOK_DELIVERY_DEAD
+		default:
+			delivery.Status = database.WEBHOOK_DELIVERY_RETRYING
+			delivery.NextAttemptDate = delivery.LastAttemptDate.Add(wd.backoff(delivery.Attempts))
+		}
+	}
+
+	if err := database.UpdateWebhookDelivery(wd.con, delivery); err != nil {
+		fmt.Printf("ERROR: The delivery %d of the webhook %d was not updated!\nErr: %s\n", delivery.Id, delivery.WebhookId, err)
+	}
+}
+
+// # Description
+// - The method shall send the request of the delivery.
+//
+// # Returns
+// - int: The HTTP status of the response, 0 without a response
+// - string: What went wrong, empty for a 2xx response
+//
+// # Author
+// - Polariusz
+func (wd *WebhookDispatcher) send(webhook database.SelectWebhook, delivery database.SelectWebhookDelivery) (int, string) {
+	request, err := http.NewRequest(webhook.Method, webhook.Url, strings.NewReader(delivery.RequestBody))
+	if err != nil {
+		return 0, err.Error()
+	}
+	request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	for key, value := range webhook.Headers {
+		request.Header.Set(key, value)
+	}
+	// The receiver can tell the retries of a delivery apart from a new one.
+	request.Header.Set("X-Webhook-Delivery", strconv.Itoa(delivery.Id))
+	request.Header.Set("X-Webhook-Attempt", strconv.Itoa(delivery.Attempts))
+
+	response, err := wd.client.Do(request)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		io.Copy(io.Discard, response.Body)
+		return response.StatusCode, ""
+	}
+	body, _ := io.ReadAll(io.LimitReader(response.Body, WEBHOOK_ERROR_LIMIT))
+	return response.StatusCode, strings.TrimSpace(fmt.Sprintf("%s %s", response.Status, body))
+}
+
+// # Description
+// - The method shall queue the deliveries whose retry is due, the new ones that did not fit into the queue and the ones whose first attempt never finished.
+//
+// # Author
+// - Polariusz
+func (wd *WebhookDispatcher) retryDue() {
+	now := time.Now()
+	deliveryList, err := database.ClaimDueWebhookDeliveries(wd.con, now, now.Add(WEBHOOK_LEASE), WEBHOOK_QUEUE_CAPACITY/10)
+	if err != nil {
+		fmt.Printf("ERROR: The webhook retries were not selected!\nErr: %s\n", err)
+		return
+	}
+
+	for _, delivery := range deliveryList {
+		hook := wd.hook(delivery.WebhookId)
+		if hook == nil {
+			delivery.Status = database.WEBHOOK_DELIVERY_DEAD
+			delivery.LastError = "The webhook can not be loaded"
+			database.UpdateWebhookDelivery(wd.con, delivery)
+			continue
+		}
+
+		select {
+		case wd.jobs <- webhookJob{hook: hook, delivery: delivery}:
+		case <-wd.done:
+			return
+		}
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Structure:
+// - {"Name":"<N>","BrokerId":<B>,"TopicFilter":"<F>","ConditionPath":"<CP>","ConditionOperator":"<CO>","ConditionValue":"<CV>","Url":"<U>","Method":"<M>","Headers":{<H>},"BodyTemplate":"<T>","MaxAttempts":<A>}
+//   - <B>  : The ID of the Broker ROW, 0 for all brokers
+//   - <F>  : MQTT topic filter, "#" if empty
+//   - <CP> : Optional, a dotted path into the message as JSON, like `sensor.temp`
+//   - <CO> : Optional, one of eq, ne, gt, ge, lt, le, contains, matches and exists
+//   - <U>  : An absolute http or https URL
+//   - <M>  : POST if empty
+//   - <T>  : Optional, a Go template of the body, see ReceivedMessage
+//   - <A>  : How often a delivery is tried, 5 if 0
+//
+// # Used in
+// - PostWebhookHandler()
+//
+// # Author
+// - Polariusz
+type WebhookWrapper struct {
+	Name string
+	BrokerId int
+	TopicFilter string
+	ConditionPath string
+	ConditionOperator string
+	ConditionValue string
+	Url string
+	Method string
+	Headers map[string]string
+	BodyTemplate string
+	MaxAttempts int
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Validator
+//
+// # Description
+// - The function shall fill in the defaults of the webhook and check it.
+//
+// # Returns
+// - error when the webhook is not valid, the message can be shown to the client
+//
+// # Author
+// - Polariusz
+func validateWebhook(webhookWrapper *WebhookWrapper) error {
+	if webhookWrapper.TopicFilter == "" {
+		webhookWrapper.TopicFilter = "#"
+	}
+	if webhookWrapper.Method == "" {
+		webhookWrapper.Method = fiber.MethodPost
+	}
+	webhookWrapper.Method = strings.ToUpper(webhookWrapper.Method)
+	if webhookWrapper.MaxAttempts == 0 {
+		webhookWrapper.MaxAttempts = WEBHOOK_DEFAULT_ATTEMPTS
+	}
+
+	if webhookWrapper.Name == "" {
+		return fmt.Errorf("Name is required")
+	}
+	if webhookWrapper.BrokerId < 0 {
+		return fmt.Errorf("BrokerId must not be negative")
+	}
+	if !validTopicFilter(webhookWrapper.TopicFilter) {
+		return fmt.Errorf("TopicFilter is not a valid MQTT topic filter")
+	}
+	target, err := url.Parse(webhookWrapper.Url)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("Url must be an absolute http or https URL")
+	}
+	switch webhookWrapper.Method {
+	case fiber.MethodGet, fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+	default:
+		return fmt.Errorf("Method must be GET, POST, PUT, PATCH or DELETE")
+	}
+	if !validConditionOperator(webhookWrapper.ConditionOperator) {
+		return fmt.Errorf("ConditionOperator must be one of eq, ne, gt, ge, lt, le, contains, matches and exists")
+	}
+	if webhookWrapper.ConditionOperator == "" && (webhookWrapper.ConditionPath != "" || webhookWrapper.ConditionValue != "") {
+		return fmt.Errorf("ConditionOperator is required for a condition")
+	}
+	if webhookWrapper.MaxAttempts < 1 || webhookWrapper.MaxAttempts > WEBHOOK_MAX_ATTEMPTS {
+		return fmt.Errorf("MaxAttempts must be between 1 and %d", WEBHOOK_MAX_ATTEMPTS)
+	}
+
+	_, err = compileWebhook(database.SelectWebhook{
+		BodyTemplate: webhookWrapper.BodyTemplate,
+		ConditionOperator: webhookWrapper.ConditionOperator,
+		ConditionValue: webhookWrapper.ConditionValue,
+	})
+	return err
+}
+
+// # Description
+// - The function shall tell whether the operator is one of the CONDITION_* constants, or empty for none.
+//
+// # Used in
+// - validateWebhook()
+// - validateAlertRule()
+//
+// # Author
+// - Polariusz
+func validConditionOperator(operator string) bool {
+	switch operator {
+	case "", CONDITION_EQ, CONDITION_NE, CONDITION_GT, CONDITION_GE, CONDITION_LT, CONDITION_LE, CONDITION_CONTAINS, CONDITION_MATCHES, CONDITION_EXISTS:
+		return true
+	}
+	return false
+}
+
+// # Description
+// - The function shall return the webhook with the ID, or write a 404 if there is none.
+//
+// # Returns
+// - bool: false if the response was written
+//
+// # Author
+// - Polariusz
+func webhookOfPath(c *fiber.Ctx, serverState *ServerState, webhookId int) (database.SelectWebhook, bool, error) {
+	webhookList, err := database.SelectWebhooks(serverState.con)
+	if err != nil {
+		return database.SelectWebhook{}, false, writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the webhooks", map[string]any{"Error": err.Error()})
+	}
+	for _, webhook := range webhookList {
+		if webhook.Id == webhookId {
+			return webhook, true, nil
+		}
+	}
+	return database.SelectWebhook{}, false, writeAPIError(c, fiber.StatusNotFound, API_ERROR_NOT_FOUND, "There is no webhook with this Id", nil)
+}
+
+// | Date of change | By        | Comment    |
+// +----------------+-----------+------------+
+// | 2026-10-19     | Polariusz | Created    |
+// | 2026-10-19     | Polariusz | overflowed |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall list the webhooks of the selected project that the account is an admin of, for their broker and topic filter.
+// - The others are left out, as their headers may hold secrets.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"webhooks":[<database.SelectWebhook>],"overflowed":<O>}
+//     - <O> : How many deliveries did not fit into the queue since the start, they were sent by the retries
+// - 500 (Internal Server Error): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func GetWebhooksHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		permissions, err := permissionsOf(c, serverState)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the roles", map[string]any{"Error": err.Error()})
+		}
+
+		webhookList, err := database.SelectWebhooks(serverState.con)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the webhooks", map[string]any{"Error": err.Error()})
+		}
+		visibleWebhooks := []database.SelectWebhook{}
+		for _, webhook := range webhookList {
+			if permissions.allows(ROLE_ADMIN, webhook.BrokerId, webhook.TopicFilter) {
+				visibleWebhooks = append(visibleWebhooks, webhook)
+			}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"webhooks": visibleWebhooks,
+			"overflowed": serverState.webhooks.overflowed.Load(),
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall create a webhook, it is used for every message received afterwards.
+// - The method shall accept a jsonified structure that follows the struct WebhookWrapper.
+// - The method shall need the role admin for the broker and the topic filter of the webhook.
+//
+// # Returns
+// - 201 (Created): JSON
+//   - {"Id":<WEBHOOK-ID>}
+// - 400 (Bad Request): `APIErrorEnvelope`
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 500 (Internal Server Error): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func PostWebhookHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var webhookWrapper WebhookWrapper
+		if err := c.BodyParser(&webhookWrapper); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"badJson": BADJSON,
+			})
+		}
+		if err := validateWebhook(&webhookWrapper); err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, webhookWrapper.BrokerId, webhookWrapper.TopicFilter); !ok {
+			return err
+		}
+
+		webhookId, err := database.InsertNewWebhook(serverState.con, database.InsertWebhook{
+			Name: webhookWrapper.Name,
+			BrokerId: webhookWrapper.BrokerId,
+			TopicFilter: webhookWrapper.TopicFilter,
+			ConditionPath: webhookWrapper.ConditionPath,
+			ConditionOperator: webhookWrapper.ConditionOperator,
+			ConditionValue: webhookWrapper.ConditionValue,
+			Url: webhookWrapper.Url,
+			Method: webhookWrapper.Method,
+			Headers: webhookWrapper.Headers,
+			BodyTemplate: webhookWrapper.BodyTemplate,
+			MaxAttempts: webhookWrapper.MaxAttempts,
+		})
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while inserting in the Webhook table", map[string]any{"Error": err.Error()})
+		}
+		if err := serverState.webhooks.reload(); err != nil {
+			fmt.Printf("WARN: Webhooks were not reloaded!\nErr: %s\n", err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"Id": webhookId,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+// | 2026-10-19     | Polariusz | Audited |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall delete a webhook with its delivery history, its deliveries that wait for a retry are not sent anymore.
+// - The method shall need the role admin for the broker and the topic filter of the webhook.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"Id":<WEBHOOK-ID>}
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func DeleteWebhookHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		record := AuditRecord{Action: AUDIT_DELETE_WEBHOOK}
+		defer serverState.auditLog.record(c, &record)
+
+		webhookId, err := paramId(c, "id")
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+		webhook, ok, err := webhookOfPath(c, serverState, webhookId)
+		if !ok {
+			return err
+		}
+		record.about(BrokerUser{BrokerId: webhook.BrokerId}, webhook.TopicFilter)
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, webhook.BrokerId, webhook.TopicFilter); !ok {
+			return err
+		}
+
+		if _, err := database.DeleteWebhook(serverState.con, webhookId); err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while deleting the webhook", map[string]any{"Error": err.Error()})
+		}
+		if err := serverState.webhooks.reload(); err != nil {
+			fmt.Printf("WARN: Webhooks were not reloaded!\nErr: %s\n", err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"Id": webhookId,
+		})
+	}
+}
+
+// # Description
+// - The function shall read `limit` and `beforeId` of a paged list, like the deliveries, from the query of the request.
+//
+// # Author
+// - Polariusz
+func pageFromQuery(c *fiber.Ctx) (int, int, error) {
+	limit, err := queryInt(c, "limit", 100)
+	if err != nil {
+		return 0, 0, err
+	}
+	if limit <= 0 || limit > AUDIT_PAGE_LIMIT {
+		return 0, 0, fmt.Errorf("Query parameter 'limit' must be between 1 and %d", AUDIT_PAGE_LIMIT)
+	}
+	beforeId, err := queryInt(c, "beforeId", 0)
+	return limit, beforeId, err
+}
+
+// # Description
+// - The function shall return the `beforeId` of the page after `deliveryList`, 0 if it was the last one.
+//
+// # Author
+// - Polariusz
+func nextDeliveryPage(deliveryList []database.SelectWebhookDelivery, limit int) int {
+	if len(deliveryList) < limit {
+		return 0
+	}
+	return deliveryList[len(deliveryList)-1].Id
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return a page of the delivery history of a webhook, the newest first, optionally only those with the query `status`.
+// - The next page is requested with `beforeId` set to the `nextBeforeId` of the response.
+// - The method shall need the role admin for the broker and the topic filter of the webhook.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"deliveries":[<database.SelectWebhookDelivery>],"nextBeforeId":<N>}
+// - 400 (Bad Request): `APIErrorEnvelope`
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func GetWebhookDeliveriesHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		webhookId, err := paramId(c, "id")
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+		limit, beforeId, err := pageFromQuery(c)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+		webhook, ok, err := webhookOfPath(c, serverState, webhookId)
+		if !ok {
+			return err
+		}
+		if ok, err := authorize(c, serverState, ROLE_ADMIN, webhook.BrokerId, webhook.TopicFilter); !ok {
+			return err
+		}
+
+		deliveryList, err := database.SelectWebhookDeliveries(serverState.con, database.WebhookDeliveryFilter{WebhookId: webhookId, Status: c.Query("status"), BeforeId: beforeId}, limit)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the deliveries", map[string]any{"Error": err.Error()})
+		}
+		if deliveryList == nil {
+			deliveryList = []database.SelectWebhookDelivery{}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"deliveries": deliveryList,
+			"nextBeforeId": nextDeliveryPage(deliveryList, limit),
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall return a page of the dead-letter list, the dead deliveries of all webhooks that the account is an admin of, the newest first.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"deliveries":[<database.SelectWebhookDelivery>],"nextBeforeId":<N>}
+// - 400 (Bad Request): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func GetDeadLettersHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		limit, beforeId, err := pageFromQuery(c)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+		}
+		permissions, err := permissionsOf(c, serverState)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the roles", map[string]any{"Error": err.Error()})
+		}
+		webhookList, err := database.SelectWebhooks(serverState.con)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the webhooks", map[string]any{"Error": err.Error()})
+		}
+		administered := make(map[int]bool)
+		for _, webhook := range webhookList {
+			administered[webhook.Id] = permissions.allows(ROLE_ADMIN, webhook.BrokerId, webhook.TopicFilter)
+		}
+
+		deliveryList, err := database.SelectWebhookDeliveries(serverState.con, database.WebhookDeliveryFilter{Status: database.WEBHOOK_DELIVERY_DEAD, BeforeId: beforeId}, limit)
+		if err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the deliveries", map[string]any{"Error": err.Error()})
+		}
+		visibleDeliveries := []database.SelectWebhookDelivery{}
+		for _, delivery := range deliveryList {
+			if administered[delivery.WebhookId] {
+				visibleDeliveries = append(visibleDeliveries, delivery)
+			}
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"deliveries": visibleDeliveries,
+			"nextBeforeId": nextDeliveryPage(deliveryList, limit),
+		})
+	}
+}
+
+// # Description
+// - The function shall return the dead delivery of the path parameter `id` after checking that the account is an admin of its webhook.
+//
+// # Returns
+// - bool: false if the response was written
+//
+// # Author
+// - Polariusz
+func deadLetterOfPath(c *fiber.Ctx, serverState *ServerState) (database.SelectWebhookDelivery, bool, error) {
+	deliveryId, err := paramId(c, "id")
+	if err != nil {
+		return database.SelectWebhookDelivery{}, false, writeAPIError(c, fiber.StatusBadRequest, API_ERROR_INVALID_ARGUMENT, err.Error(), nil)
+	}
+	delivery, found, err := database.SelectWebhookDeliveryById(serverState.con, deliveryId)
+	if err != nil {
+		return delivery, false, writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while selecting the delivery", map[string]any{"Error": err.Error()})
+	}
+	if !found || delivery.Status != database.WEBHOOK_DELIVERY_DEAD {
+		return delivery, false, writeAPIError(c, fiber.StatusNotFound, API_ERROR_NOT_FOUND, "There is no dead delivery with this Id", nil)
+	}
+
+	webhook, ok, err := webhookOfPath(c, serverState, delivery.WebhookId)
+	if !ok {
+		return delivery, false, err
+	}
+	if ok, err := authorize(c, serverState, ROLE_ADMIN, webhook.BrokerId, webhook.TopicFilter); !ok {
+		return delivery, false, err
+	}
+
+	return delivery, true, nil
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall take a dead delivery off the dead-letter list, it is tried again right away with all attempts of its webhook.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"Id":<DELIVERY-ID>}
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func PostDeadLetterRetryHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		delivery, ok, err := deadLetterOfPath(c, serverState)
+		if !ok {
+			return err
+		}
+
+		if _, err := database.RetryDeadWebhookDelivery(serverState.con, delivery.Id); err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while updating the delivery", map[string]any{"Error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"Id": delivery.Id,
+		})
+	}
+}
+
+// | Date of change | By        | Comment |
+// +----------------+-----------+---------+
+// | 2026-10-19     | Polariusz | Created |
+//
+// # Method-Type
+// - Handler
+//
+// # Description
+// - The method shall delete a dead delivery from the dead-letter list, it is not tried again.
+//
+// # Returns
+// - 200 (Ok): JSON
+//   - {"Id":<DELIVERY-ID>}
+// - 403 (Forbidden): `APIErrorEnvelope`
+// - 404 (Not Found): `APIErrorEnvelope`
+//
+// # Author
+// - Polariusz
+func DeleteDeadLetterHandler(serverState *ServerState) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		delivery, ok, err := deadLetterOfPath(c, serverState)
+		if !ok {
+			return err
+		}
+
+		if _, err := database.DeleteDeadWebhookDelivery(serverState.con, delivery.Id); err != nil {
+			return writeAPIError(c, fiber.StatusInternalServerError, API_ERROR_INTERNAL, "Error while deleting the delivery", map[string]any{"Error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"Id": delivery.Id,
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"database"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestEvaluateCondition(t *testing.T) {
+	message := `{"sensor":{"temp":21.5,"name":"kitchen"},"values":[1,2,3]}`
+	for _, test := range []struct {
+		path string
+		operator string
+		value string
+		want bool
+	}{
+		{"", "", "", true},
+		{"sensor.temp", CONDITION_GT, "20", true},
+		{"sensor.temp", CONDITION_LE, "20", false},
+		{"sensor.temp", CONDITION_EQ, "21.50", true},
+		{"sensor.name", CONDITION_EQ, "kitchen", true},
+		{"sensor.name", CONDITION_NE, "kitchen", false},
+		{"sensor.name", CONDITION_GT, "a", false},
+		{"values.2", CONDITION_EQ, "3", true},
+		{"values.0", CONDITION_EXISTS, "", true},
+		{"sensor.humidity", CONDITION_EXISTS, "", false},
+		{"sensor", CONDITION_CONTAINS, "kitchen", true},
+		{"", CONDITION_CONTAINS, "kitchen", true},
+	} {
+		if got := evaluateCondition(test.path, test.operator, test.value, nil, message); got != test.want {
+			t.Errorf("%s %s %s = %v, want %v", test.path, test.operator, test.value, got, test.want)
+		}
+	}
+
+	hook, err := compileWebhook(database.SelectWebhook{TopicFilter: "#", ConditionOperator: CONDITION_MATCHES, ConditionValue: "^on|off$"})
+	if err != nil {
+		t.Fatalf("compileWebhook: %s", err)
+	}
+	if !hook.matches(ReceivedMessage{Topic: "lights/a", Message: "off"}) || hook.matches(ReceivedMessage{Topic: "lights/a", Message: "dim"}) {
+		t.Errorf("the regular expression does not match")
+	}
+	if _, err := compileWebhook(database.SelectWebhook{BodyTemplate: "{{.Nope"}); err == nil {
+		t.Errorf("a broken template was compiled")
+	}
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	con, err := database.OpenDatabase(database.InMemoryDatabase)
+	if err != nil {
+		t.Fatalf("OpenDatabase: %s", err)
+	}
+	defer con.Close()
+	if err := database.SetupDatabase(con); err != nil {
+		t.Fatalf("SetupDatabase: %s", err)
+	}
+
+	// The receiver fails every request until it is told otherwise.
+	var healthy atomic.Bool
+	bodies := make(chan string, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies <- r.Header.Get("X-Token") + " " + string(body)
+	}))
+	defer receiver.Close()
+
+	accounts, err := NewAccountManager(con)
+	if err != nil {
+		t.Fatalf("NewAccountManager: %s", err)
+	}
+	webhooks := NewWebhookDispatcher(con)
+	webhooks.backoffBase = 10 * time.Millisecond
+	webhooks.retryInterval = 10 * time.Millisecond
+	webhooks.start()
+	defer webhooks.close()
+	serverState := &ServerState{con: con, accounts: accounts, webhooks: webhooks}
+	server := fiber.New()
+	addRoutes(server, serverState)
+
+	if status, _ := request(t, server, "POST", "/api/v1/webhooks", `{"Name":"ftp","Url":"ftp://example.org"}`, nil); status != fiber.StatusBadRequest {
+		t.Errorf("a webhook without a http URL: %d", status)
+	}
+	hook := fmt.Sprintf(`{"Name":"hot","TopicFilter":"sensors/+","ConditionPath":"temp","ConditionOperator":"gt","ConditionValue":"20","Url":%q,"Headers":{"X-Token":"secret"},"BodyTemplate":"{{.Topic}}={{.Message}}","MaxAttempts":2}`, receiver.URL)
+	status, body := request(t, server, "POST", "/api/v1/webhooks", hook, nil)
+	if status != fiber.StatusCreated {
+		t.Fatalf("create the webhook: %d %v", status, body)
+	}
+	webhookId := int(body["Id"].(float64))
+
+	waitFor := func(what string, done func() bool) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); !done(); time.Sleep(10 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+		}
+	}
+	deliveries := func(query string) []any {
+		status, body := request(t, server, "GET", fmt.Sprintf("/api/v1/webhooks/%d/deliveries%s", webhookId, query), "", nil)
+		if status != fiber.StatusOK {
+			t.Fatalf("the deliveries: %d %v", status, body)
+		}
+		deliveryList, _ := body["deliveries"].([]any)
+		return deliveryList
+	}
+
+	webhooks.dispatch(ReceivedMessage{BrokerId: 1, Topic: "sensors/a", Message: `{"temp":10}`})
+	webhooks.dispatch(ReceivedMessage{BrokerId: 1, Topic: "lights/a", Message: `{"temp":30}`})
+	webhooks.dispatch(ReceivedMessage{BrokerId: 1, Topic: "sensors/a", Message: `{"temp":30}`})
+
+	// Both attempts fail, so the delivery ends up on the dead-letter list.
+	waitFor("the dead letter", func() bool { return len(deliveries("?status=dead")) == 1 })
+	if all := deliveries(""); len(all) != 1 {
+		t.Fatalf("only one message matches: %v", all)
+	}
+	dead := deliveries("")[0].(map[string]any)
+	if dead["Attempts"] != float64(2) || dead["LastStatusCode"] != float64(http.StatusServiceUnavailable) {
+		t.Errorf("the dead delivery: %v", dead)
+	}
+	status, body = request(t, server, "GET", "/api/v1/webhooks/dead-letters", "", nil)
+	if deadLetters, _ := body["deliveries"].([]any); status != fiber.StatusOK || len(deadLetters) != 1 {
+		t.Fatalf("the dead letters: %d %v", status, body)
+	}
+
+	healthy.Store(true)
+	if status, body := request(t, server, "POST", fmt.Sprintf("/api/v1/webhooks/dead-letters/%v/retry", dead["Id"]), "", nil); status != fiber.StatusOK {
+		t.Fatalf("retry the dead letter: %d %v", status, body)
+	}
+	select {
+	case got := <-bodies:
+		if got != `secret sensors/a={"temp":30}` {
+			t.Errorf("the request: %q", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the retry was not sent")
+	}
+	waitFor("the delivery", func() bool { return len(deliveries("?status=delivered")) == 1 })
+
+	if status, _ := request(t, server, "POST", fmt.Sprintf("/api/v1/webhooks/dead-letters/%v/retry", dead["Id"]), "", nil); status != fiber.StatusNotFound {
+		t.Errorf("retry a delivered delivery: %d", status)
+	}
+	if status, _ := request(t, server, "DELETE", fmt.Sprintf("/api/v1/webhooks/%d", webhookId), "", nil); status != fiber.StatusOK {
+		t.Errorf("delete the webhook: %d", status)
+	}
+	if status, _ := request(t, server, "GET", fmt.Sprintf("/api/v1/webhooks/%d/deliveries", webhookId), "", nil); status != fiber.StatusNotFound {
+		t.Errorf("the deliveries of a deleted webhook: %d", status)
+	}
+}
+
+// TestWebhookQueueOverflow makes sure that the deliveries that do not fit
+// into the queue wait in the database and are sent by the retries.
+func TestWebhookQueueOverflow(t *testing.T) {
+	con, err := database.OpenDatabase(database.InMemoryDatabase)
+	if err != nil {
+		t.Fatalf("OpenDatabase: %s", err)
+	}
+	defer con.Close()
+	if err := database.SetupDatabase(con); err != nil {
+		t.Fatalf("SetupDatabase: %s", err)
+	}
+
+	var received atomic.Int64
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+	}))
+	defer receiver.Close()
+
+	webhookId, err := database.InsertNewWebhook(con, database.InsertWebhook{Name: "all", TopicFilter: "#", Url: receiver.URL, Method: "POST", MaxAttempts: 1})
+	if err != nil {
+		t.Fatalf("InsertNewWebhook: %s", err)
+	}
+
+	// Nothing takes from the queue yet, and it has room for one delivery.
+	webhooks := NewWebhookDispatcher(con)
+	webhooks.jobs = make(chan webhookJob, 1)
+	webhooks.retryInterval = 10 * time.Millisecond
+	if err := webhooks.reload(); err != nil {
+		t.Fatalf("reload: %s", err)
+	}
+	for i := 0; i < 3; i++ {
+		webhooks.dispatch(ReceivedMessage{BrokerId: 1, Topic: "sensors/a", Message: fmt.Sprint(i)})
+	}
+	if overflowed := webhooks.overflowed.Load(); overflowed != 2 {
+		t.Errorf("%d deliveries overflowed, want 2", overflowed)
+	}
+	pending, err := database.SelectWebhookDeliveries(con, database.WebhookDeliveryFilter{WebhookId: webhookId, Status: database.WEBHOOK_DELIVERY_PENDING}, 10)
+	if err != nil || len(pending) != 3 {
+		t.Fatalf("the pending deliveries: %v %s", pending, err)
+	}
+
+	webhooks.start()
+	defer webhooks.close()
+	for deadline := time.Now().Add(5 * time.Second); received.Load() < 3; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d of 3 deliveries were sent", received.Load())
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	if got := received.Load(); got != 3 {
+		t.Errorf("%d requests, want every delivery once", got)
+	}
+}